@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var accessUserFlag string
+
+var accessCmd = &cobra.Command{
+	Use:   "access [PATH...]",
+	Short: "Show what the access policy lets a user do",
+	Long: `Show the access level a user has to workspace files under the policy in
+.jot/access.yaml. Without paths, every markdown file is listed.
+
+The policy maps users and groups to directories and files with a level of
+none, read or write. Commands that write notes refuse files the user may not
+write, or only warn when the policy's mode is "warn". 'jot serve' requires
+each client to authenticate with a token and enforces read and write levels
+for the token's user. The user is JOT_USER, or the operating system user.
+
+Examples:
+  jot access                        # Your level for every file
+  jot access lib/hr --user dana     # Dana's level for lib/hr
+  jot access token dana             # Issue a serve token for dana`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		policy, err := access.Load(ws.JotDir)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		userName := accessUserFlag
+		if userName == "" {
+			userName = access.CurrentUser()
+		}
+
+		paths := args
+		if len(paths) == 0 {
+			files, err := scanWorkspaceMarkdownFiles(ws)
+			if err != nil {
+				return ctx.HandleOperationError("list files", err)
+			}
+			for _, file := range files {
+				paths = append(paths, filepath.ToSlash(file))
+			}
+		}
+
+		response := AccessResponse{User: userName, Mode: policy.Mode, Paths: []AccessPath{}}
+		for _, p := range paths {
+			rel := filepath.ToSlash(ws.RelativePath(cmdutil.ResolveWorkspaceRelativePath(ws, p)))
+			response.Paths = append(response.Paths, AccessPath{Path: rel, Level: policy.Level(userName, rel).String()})
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		fmt.Printf("User: %s (%s mode)\n\n", userName, policy.Mode)
+		for _, p := range response.Paths {
+			fmt.Printf("  %-6s %s\n", p.Level, p.Path)
+		}
+		return nil
+	},
+}
+
+var accessTokenCmd = &cobra.Command{
+	Use:   "token USER",
+	Short: "Issue a token that authenticates USER to jot serve",
+	Long: `Issue a random token for USER and record its hash in .jot/access-tokens.
+The token is printed once; give it to the client, which sends it in an
+"authenticate" request. Delete the user's line from .jot/access-tokens to
+revoke it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		userName := strings.TrimSpace(args[0])
+		if userName == "" || strings.ContainsAny(userName, " \t\n") {
+			return ctx.HandleValidation("user", args[0], fmt.Errorf("user names cannot be empty or contain spaces"))
+		}
+
+		token, hash, err := access.NewToken()
+		if err != nil {
+			return ctx.HandleOperationError("generate token", err)
+		}
+		tokensFile := filepath.Join(ws.JotDir, access.TokensFileName)
+		if err := dryrun.AppendFile(tokensFile, []byte(userName+" "+hash+"\n"), 0600); err != nil {
+			return ctx.HandleFileOperation("write", tokensFile, err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(AccessTokenResponse{
+				User:     userName,
+				Token:    token,
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+		cmdutil.ShowSuccess("✓ Token for %s (shown once):", userName)
+		fmt.Println(token)
+		return nil
+	},
+}
+
+// accessState holds the access policy and the user whose writes are
+// checked against it
+type accessState struct {
+	mu      sync.Mutex
+	ws      *workspace.Workspace
+	policy  *access.Policy
+	err     error  // A policy that failed to load refuses every write
+	user    string // The user whose writes are checked
+	enforce bool   // Refuse even in warn mode, as serve does
+	warned  map[string]bool
+}
+
+var accessControl accessState
+
+// configureAccess checks every write against the workspace's access policy
+func configureAccess(cmd *cobra.Command) {
+	dryrun.SetGuard(nil)
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return
+	}
+	policy, err := access.Load(ws.JotDir)
+	if errors.Is(err, access.ErrNoPolicy) {
+		return
+	}
+
+	accessControl.mu.Lock()
+	accessControl.ws = ws
+	accessControl.policy = policy
+	accessControl.err = err
+	accessControl.user = access.CurrentUser()
+	accessControl.warned = make(map[string]bool)
+	accessControl.mu.Unlock()
+	dryrun.SetGuard(accessControl.checkWrite)
+}
+
+// checkWrite refuses, or warns about, a write the user may not make. Files
+// outside the workspace and under .jot are not covered by the policy.
+func (a *accessState) checkWrite(path string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rel := filepath.ToSlash(a.ws.RelativePath(path))
+	if filepath.IsAbs(rel) || rel == ".jot" || strings.HasPrefix(rel, ".jot/") {
+		return nil
+	}
+	if a.err != nil {
+		return fmt.Errorf("refusing to write %s: %w", rel, a.err)
+	}
+	err := a.policy.Check(a.user, rel, access.Write)
+	if err == nil || (a.policy.Warn() && !a.enforce) {
+		if err != nil && !a.warned[rel] {
+			a.warned[rel] = true
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return nil
+	}
+	return err
+}
+
+// actAs checks writes as another user until the returned function is called
+func (a *accessState) actAs(userName string, enforce bool) func() {
+	a.mu.Lock()
+	user, wasEnforcing := a.user, a.enforce
+	a.user, a.enforce = userName, enforce
+	a.mu.Unlock()
+	return func() {
+		a.mu.Lock()
+		a.user, a.enforce = user, wasEnforcing
+		a.mu.Unlock()
+	}
+}
+
+// AccessResponse represents the JSON response for access
+type AccessResponse struct {
+	User     string               `json:"user"`
+	Mode     string               `json:"mode"`
+	Paths    []AccessPath         `json:"paths"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// AccessPath is one path's access level
+type AccessPath struct {
+	Path  string `json:"path"`
+	Level string `json:"level"`
+}
+
+// AccessTokenResponse represents the JSON response for access token
+type AccessTokenResponse struct {
+	User     string               `json:"user"`
+	Token    string               `json:"token"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func init() {
+	accessCmd.Flags().StringVar(&accessUserFlag, "user", "", "Show the levels of this user instead of the current one")
+	accessCmd.AddCommand(accessTokenCmd)
+}
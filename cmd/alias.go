@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// selectorFlags are flags whose values are selectors and may reference aliases
+var selectorFlags = []string{"to", "set-location", "children-of"}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage selector aliases",
+	Long: `Manage short names for frequently used selectors.
+
+Aliases are stored in .jot/config.json under "aliases" and can be used as
+"@name" anywhere a selector is accepted. Add "/path" after the name to go
+deeper into the aliased subtree.
+
+Examples:
+  jot alias add inboxmeet "inbox.md#meetings"
+  jot alias add proj work.md
+  jot alias list
+  jot peek @inboxmeet
+  jot refile @inboxmeet/standup --to @proj/frontend
+  jot alias remove inboxmeet`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return aliasList(cmd)
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List selector aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return aliasList(cmd)
+	},
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add NAME SELECTOR",
+	Short: "Add or replace a selector alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		name := strings.TrimPrefix(args[0], workspace.AliasPrefix)
+		selector := args[1]
+		if err := workspace.ValidateAliasName(name); err != nil {
+			return ctx.HandleValidation("name", args[0], err)
+		}
+		if strings.Contains(selector, "#") {
+			if _, err := markdown.ParsePath(selector); err != nil {
+				return ctx.HandleValidation("selector", selector, err)
+			}
+		}
+
+		previous, replaced := ws.Aliases()[name]
+		if err := ws.SetAlias(name, selector); err != nil {
+			return ctx.HandleOperationError("save alias", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(AliasResponse{
+				Operation: "add_alias",
+				Aliases:   []AliasEntry{{Name: name, Selector: selector}},
+				Replaced:  previous,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		if replaced {
+			cmdutil.ShowSuccess("✓ Updated alias @%s: %s (was %s)", name, selector, previous)
+		} else {
+			cmdutil.ShowSuccess("✓ Added alias @%s: %s", name, selector)
+		}
+		return nil
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a selector alias",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		name := strings.TrimPrefix(args[0], workspace.AliasPrefix)
+		selector := ws.Aliases()[name]
+		if err := ws.RemoveAlias(name); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(AliasResponse{
+				Operation: "remove_alias",
+				Aliases:   []AliasEntry{{Name: name, Selector: selector}},
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Removed alias @%s", name)
+		return nil
+	},
+}
+
+// AliasResponse represents the JSON response for alias commands
+type AliasResponse struct {
+	Operation string               `json:"operation"`
+	Aliases   []AliasEntry         `json:"aliases"`
+	Replaced  string               `json:"replaced,omitempty"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// AliasEntry is a single alias and the selector it expands to
+type AliasEntry struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+}
+
+// aliasList shows all configured aliases
+func aliasList(cmd *cobra.Command) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	entries := make([]AliasEntry, 0, len(ws.Aliases()))
+	for _, name := range ws.AliasNames() {
+		entries = append(entries, AliasEntry{Name: name, Selector: ws.Aliases()[name]})
+	}
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(AliasResponse{
+			Operation: "list_aliases",
+			Aliases:   entries,
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No aliases defined.")
+		fmt.Println("\nUse 'jot alias add <name> <selector>' to create one")
+		return nil
+	}
+
+	for _, entry := range entries {
+		fmt.Printf("@%-15s %s\n", entry.Name, entry.Selector)
+	}
+	return nil
+}
+
+// expandSelectorAliases rewrites "@name" arguments and selector flag values to
+// the selectors they alias, so every command accepts aliases transparently
+func expandSelectorAliases(cmd *cobra.Command, args []string) error {
+	if cmd == aliasCmd || cmd.Parent() == aliasCmd {
+		return nil
+	}
+
+	hasAlias := false
+	for _, arg := range args {
+		hasAlias = hasAlias || strings.HasPrefix(arg, workspace.AliasPrefix)
+	}
+	for _, name := range selectorFlags {
+		if flag := cmd.Flags().Lookup(name); flag != nil {
+			hasAlias = hasAlias || strings.HasPrefix(flag.Value.String(), workspace.AliasPrefix)
+		}
+	}
+	if !hasAlias {
+		return nil
+	}
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		// Commands report missing workspaces themselves
+		return nil
+	}
+
+	for i, arg := range args {
+		if expanded, ok := ws.ExpandAlias(arg); ok {
+			args[i] = expanded
+		}
+	}
+	for _, name := range selectorFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if expanded, ok := ws.ExpandAlias(flag.Value.String()); ok {
+			if err := flag.Value.Set(expanded); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// annotationTypes are the kinds of annotation: GitHub's alerts, a todo
+// callout as Obsidian writes them, and a plain quote
+var annotationTypes = []string{"note", "tip", "important", "warning", "caution", "todo", "quote"}
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate SELECTOR [TEXT]",
+	Short: "Attach a note, warning or todo block to a subtree",
+	Long: `Attach an admonition, such as a note or warning, to a subtree, so review
+feedback and caveats can be added from scripts, CI jobs and hooks.
+
+The block is written as a GitHub alert, which Obsidian reads as a callout:
+
+  > [!WARNING] Review
+  > The numbers in this table are from 2023.
+
+It goes at the end of the heading's own text, before any nested headings,
+or with --top right under the heading and any name: value lines there.
+Without TEXT, the text is read from stdin.
+
+Types: note (default), tip, important, warning, caution, todo, and quote
+for a plain blockquote.
+
+Examples:
+  jot annotate "work.md#budget" --type warning "Numbers are from 2023"
+  jot annotate "design.md#api" --type todo --top "Document the error codes"
+  jot annotate "runbook.md#deploy" --title "CI" < lint-report.txt`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		kind, _ := cmd.Flags().GetString("type")
+		title, _ := cmd.Flags().GetString("title")
+		top, _ := cmd.Flags().GetBool("top")
+		kind = strings.ToLower(kind)
+		if !isAnnotationType(kind) {
+			return ctx.HandleValidation("type", kind, fmt.Errorf("must be one of %s", strings.Join(annotationTypes, ", ")))
+		}
+		if kind == "quote" && title != "" {
+			return ctx.HandleValidation("title", title, fmt.Errorf("a quote has no title"))
+		}
+
+		selector := args[0]
+		var text string
+		if len(args) > 1 {
+			text = args[1]
+		} else {
+			if isTerminal(os.Stdin) {
+				return ctx.HandleUsage(fmt.Errorf("give the annotation text as an argument or on stdin"))
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return ctx.HandleOperationError("read stdin", fmt.Errorf("failed to read from stdin: %w", err))
+			}
+			text = string(data)
+		}
+		text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+		if text == "" {
+			return ctx.HandleValidation("text", text, fmt.Errorf("nothing to annotate with"))
+		}
+		if err := checkContent([]byte(text)); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		path, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleValidation("selector", selector, err)
+		}
+		if len(path.Segments) == 0 {
+			return ctx.HandleValidation("selector", selector, fmt.Errorf("selector must name a heading, such as file.md#budget"))
+		}
+
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, path.File)
+		if err := checkDestinationContent(ws, filePath); err != nil {
+			return ctx.HandleError(err)
+		}
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", path.File, err))
+		}
+		doc := markdown.ParseDocument(content)
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		end := subtree.EndOffset
+		if children := markdown.ChildSubtrees(doc, content, subtree); len(children) > 0 {
+			end = children[0].MarkerStart()
+		}
+		if err := checkProtectedRange(ws, path.File, subtree.StartOffset, end); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		block := admonition(kind, title, text)
+		var updated []byte
+		var line int
+		if top {
+			updated, line = insertAtSectionTop(content, subtree.StartOffset, end, block)
+		} else {
+			updated, line = appendToSection(content, subtree.StartOffset, end, block, false)
+		}
+		if err := cmdutil.WriteFileContent(filePath, updated); err != nil {
+			return ctx.HandleOperationError("annotate", err)
+		}
+
+		position := "bottom"
+		if top {
+			position = "top"
+		}
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(AnnotateResponse{
+				Operation: "annotate",
+				Selector:  selector,
+				File:      path.File,
+				Heading:   subtree.Heading,
+				Type:      kind,
+				Position:  position,
+				Line:      line,
+				Lines:     strings.Count(string(updated), "\n") - strings.Count(string(content), "\n"),
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Added %s to '%s' at %s:%d", kind, subtree.Heading, path.File, line)
+		return nil
+	},
+}
+
+// AnnotateResponse is the JSON response for annotate
+type AnnotateResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"`
+	File      string               `json:"file"`
+	Heading   string               `json:"heading"`
+	Type      string               `json:"type"`
+	Position  string               `json:"position"` // top or bottom
+	Line      int                  `json:"line"`     // Line the block starts on
+	Lines     int                  `json:"lines"`    // Lines the file grew by
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func isAnnotationType(kind string) bool {
+	for _, valid := range annotationTypes {
+		if kind == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// admonition writes text as a blockquote, opened with a [!TYPE] marker and
+// an optional title unless kind is quote
+func admonition(kind, title, text string) string {
+	var lines []string
+	if kind != "quote" {
+		marker := "> [!" + strings.ToUpper(kind) + "]"
+		if title != "" {
+			marker += " " + title
+		}
+		lines = append(lines, marker)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			lines = append(lines, ">")
+		} else {
+			lines = append(lines, "> "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// insertAtSectionTop returns content with text inserted in [start, end)
+// right after the heading at start and any name: value lines under it, set
+// apart by blank lines, and the line the text starts on
+func insertAtSectionTop(content []byte, start, end int, text string) ([]byte, int) {
+	at := end
+	if newline := bytes.IndexByte(content[start:end], '\n'); newline >= 0 {
+		at = start + newline + 1
+	}
+	_, properties := markdown.LeadingProperties(content[at:end])
+	at += properties
+	rest := bytes.TrimLeft(content[at:], "\n")
+
+	updated := make([]byte, 0, len(content)+len(text)+4)
+	updated = append(updated, content[:at]...)
+	if at > 0 && content[at-1] != '\n' {
+		updated = append(updated, '\n')
+	}
+	updated = append(updated, '\n')
+	begin := len(updated)
+	updated = append(updated, text...)
+	updated = append(updated, '\n')
+	if len(rest) > 0 {
+		updated = append(updated, '\n')
+	}
+	updated = append(updated, rest...)
+	return updated, markdown.CalculateLineNumber(updated, begin)
+}
+
+func init() {
+	annotateCmd.Flags().String("type", "note", "Kind of block: "+strings.Join(annotationTypes, ", "))
+	annotateCmd.Flags().String("title", "", "Title after the type marker")
+	annotateCmd.Flags().Bool("top", false, "Insert right under the heading instead of at the end of its text")
+	annotateCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected subtrees, and binary or very large files")
+}
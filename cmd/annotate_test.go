@@ -0,0 +1,63 @@
+package cmd
+
+import "testing"
+
+func TestAdmonition(t *testing.T) {
+	if got := admonition("warning", "Review", "Numbers are old\n\nCheck them  "); got != "> [!WARNING] Review\n> Numbers are old\n>\n> Check them" {
+		t.Errorf("got %q", got)
+	}
+	if got := admonition("quote", "", "Said once"); got != "> Said once" {
+		t.Errorf("quote: got %q", got)
+	}
+}
+
+func TestInsertAtSectionTop(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		end      int // Offset of the section end; -1 for the end of content
+		want     string
+		wantLine int
+	}{
+		{
+			name:     "heading only",
+			content:  "# Log",
+			end:      -1,
+			want:     "# Log\n\n> [!NOTE]\n> x\n",
+			wantLine: 3,
+		},
+		{
+			name:     "before body",
+			content:  "# Log\n\nold\n",
+			end:      -1,
+			want:     "# Log\n\n> [!NOTE]\n> x\n\nold\n",
+			wantLine: 3,
+		},
+		{
+			name:     "after properties",
+			content:  "# Log\nstatus: open\nowner: sam\nold\n",
+			end:      -1,
+			want:     "# Log\nstatus: open\nowner: sam\n\n> [!NOTE]\n> x\n\nold\n",
+			wantLine: 5,
+		},
+		{
+			name:     "before nested heading",
+			content:  "# Log\n## Child\n",
+			end:      6,
+			want:     "# Log\n\n> [!NOTE]\n> x\n\n## Child\n",
+			wantLine: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := tt.end
+			if end < 0 {
+				end = len(tt.content)
+			}
+			got, line := insertAtSectionTop([]byte(tt.content), 0, end, "> [!NOTE]\n> x")
+			if string(got) != tt.want || line != tt.wantLine {
+				t.Errorf("got %q at line %d, want %q at line %d", got, line, tt.want, tt.wantLine)
+			}
+		})
+	}
+}
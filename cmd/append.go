@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var appendItem bool
+
+// listItemPattern matches a list item line, capturing its indent, marker
+// and, for ordered lists, the number and delimiter
+var listItemPattern = regexp.MustCompile(`^(\s*)(?:([-*+])|(\d+)([.)]))\s+`)
+
+var appendCmd = &cobra.Command{
+	Use:   "append SELECTOR [TEXT]",
+	Short: "Append a paragraph or list item to a subtree",
+	Long: `Append a paragraph or list item to the end of a heading's own text,
+without opening an editor or going through capture and its templates.
+
+The text goes after the heading's last paragraph or list, before any nested
+headings, so it stays in the section the selector names. Without TEXT, the
+text is read from stdin.
+
+With --item, each line becomes a list item. When the section ends with a
+list, the items continue it with the same marker, or the next number.
+
+Examples:
+  jot append "work.md#log" "Deployed 2.4 to staging"
+  jot append "work.md#todo" --item "Renew certificates"
+  make test 2>&1 | tail -1 | jot append "ci.md#results"`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		selector := args[0]
+		var text string
+		if len(args) > 1 {
+			text = args[1]
+		} else {
+			if isTerminal(os.Stdin) {
+				return ctx.HandleUsage(fmt.Errorf("give the text to append as an argument or on stdin"))
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return ctx.HandleOperationError("read stdin", fmt.Errorf("failed to read from stdin: %w", err))
+			}
+			text = string(data)
+		}
+		text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+		if text == "" {
+			return ctx.HandleValidation("text", text, fmt.Errorf("nothing to append"))
+		}
+		if err := checkContent([]byte(text)); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		path, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleValidation("selector", selector, err)
+		}
+		if len(path.Segments) == 0 {
+			return ctx.HandleValidation("selector", selector, fmt.Errorf("selector must name a heading, such as file.md#log"))
+		}
+
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, path.File)
+		if err := checkDestinationContent(ws, filePath); err != nil {
+			return ctx.HandleError(err)
+		}
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", path.File, err))
+		}
+		doc := markdown.ParseDocument(content)
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		end := subtree.EndOffset
+		if children := markdown.ChildSubtrees(doc, content, subtree); len(children) > 0 {
+			end = children[0].MarkerStart()
+		}
+		if err := checkProtectedRange(ws, path.File, subtree.StartOffset, end); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		updated, line := appendToSection(content, subtree.StartOffset, end, text, appendItem)
+		if err := cmdutil.WriteFileContent(filePath, updated); err != nil {
+			return ctx.HandleOperationError("append", err)
+		}
+		lines := strings.Count(string(updated), "\n") - strings.Count(string(content), "\n")
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(AppendResponse{
+				Operation: "append",
+				Selector:  selector,
+				File:      path.File,
+				Heading:   subtree.Heading,
+				Line:      line,
+				Lines:     lines,
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Appended to '%s' at %s:%d", subtree.Heading, path.File, line)
+		return nil
+	},
+}
+
+// AppendResponse is the JSON response for append
+type AppendResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"`
+	File      string               `json:"file"`
+	Heading   string               `json:"heading"`
+	Line      int                  `json:"line"`  // Line the appended text starts on
+	Lines     int                  `json:"lines"` // Lines the file grew by
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// appendToSection returns content with text appended after the last text in
+// [start, end), the heading at start and its own body, and the line the
+// text starts on. As items, each line of text becomes a list item that
+// continues a list ending the section.
+func appendToSection(content []byte, start, end int, text string, item bool) ([]byte, int) {
+	body := bytes.TrimRight(content[start:end], "\n")
+	at := start + len(body)
+	gap := content[at:end]
+	if !bytes.Contains(gap, []byte("\n")) {
+		gap = append([]byte("\n"), gap...)
+	}
+
+	separator := "\n\n"
+	if item {
+		marker, number := continuedList(body)
+		if marker != "" {
+			separator = "\n"
+		} else {
+			marker = "-"
+		}
+		var items []string
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			line = strings.TrimSpace(listItemPattern.ReplaceAllString(line, ""))
+			prefix := marker
+			if number > 0 {
+				prefix = strconv.Itoa(number) + marker
+				number++
+			}
+			items = append(items, prefix+" "+line)
+		}
+		text = strings.Join(items, "\n")
+	}
+
+	updated := make([]byte, 0, len(content)+len(separator)+len(text)+1)
+	updated = append(updated, content[:at]...)
+	updated = append(updated, separator...)
+	updated = append(updated, text...)
+	updated = append(updated, gap...)
+	updated = append(updated, content[end:]...)
+	return updated, markdown.CalculateLineNumber(updated, at+len(separator))
+}
+
+// continuedList finds a list at the end of body: the last unindented line,
+// when it is a list item. It returns the marker to continue the list with,
+// and for ordered lists the next number; marker is empty without a list.
+func continuedList(body []byte) (marker string, number int) {
+	lines := strings.Split(string(body), "\n")
+	for i := len(lines) - 1; i > 0; i-- {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		match := listItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			return "", 0
+		}
+		if match[2] != "" {
+			return match[2], 0
+		}
+		n, _ := strconv.Atoi(match[3])
+		return match[4], n + 1
+	}
+	return "", 0
+}
+
+func init() {
+	appendCmd.Flags().BoolVar(&appendItem, "item", false, "Append each line as a list item")
+	appendCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected subtrees, and binary or very large files")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import "testing"
+
+func TestAppendToSection(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		end      int // Offset of the section end; -1 for the end of content
+		text     string
+		item     bool
+		want     string
+		wantLine int
+	}{
+		{
+			name:     "paragraph after heading only",
+			content:  "# Log\n",
+			end:      -1,
+			text:     "first",
+			want:     "# Log\n\nfirst\n",
+			wantLine: 3,
+		},
+		{
+			name:     "paragraph before nested heading",
+			content:  "# Log\n\nold\n\n## Child\n",
+			end:      12,
+			text:     "new",
+			want:     "# Log\n\nold\n\nnew\n\n## Child\n",
+			wantLine: 5,
+		},
+		{
+			name:     "item continues bullet list",
+			content:  "# Todo\n\n* a\n  more\n* b\n",
+			end:      -1,
+			text:     "c\nd",
+			item:     true,
+			want:     "# Todo\n\n* a\n  more\n* b\n* c\n* d\n",
+			wantLine: 6,
+		},
+		{
+			name:     "item continues numbered list",
+			content:  "# Steps\n\n1. one\n2. two",
+			end:      -1,
+			text:     "three",
+			item:     true,
+			want:     "# Steps\n\n1. one\n2. two\n3. three\n",
+			wantLine: 5,
+		},
+		{
+			name:     "item starts list after paragraph",
+			content:  "# Todo\n\nSome text.\n",
+			end:      -1,
+			text:     "- a",
+			item:     true,
+			want:     "# Todo\n\nSome text.\n\n- a\n",
+			wantLine: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := tt.end
+			if end < 0 {
+				end = len(tt.content)
+			}
+			got, line := appendToSection([]byte(tt.content), 0, end, tt.text, tt.item)
+			if string(got) != tt.want {
+				t.Errorf("appendToSection() = %q, want %q", got, tt.want)
+			}
+			if line != tt.wantLine {
+				t.Errorf("line = %d, want %d", line, tt.wantLine)
+			}
+		})
+	}
+}
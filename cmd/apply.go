@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply PLAN_FILE",
+	Short: "Apply a plan written by --plan",
+	Long: `Apply the file changes recorded in a plan.
+
+Any command that writes files accepts --plan FILE. Instead of writing, it
+records the final content of every file it would change, together with a
+diff for review, in FILE. 'jot apply' then writes those files.
+
+Before writing anything, apply checks that every file still matches the
+content the plan was made against. If any file has changed, nothing is
+written; make a new plan, or use --force to overwrite the changes. A plan
+whose paths leave the workspace, or whose diffs do not match the content it
+would write, is always refused.
+
+Hooks do not run while planning or applying.
+
+Examples:
+  jot refile "inbox.md#meeting" --to "work.md#meetings" --plan move.json
+  jq -r '.files[].diff' move.json      # Review the changes
+  jot apply move.json
+  jot apply move.json --dry-run        # Check the plan still applies`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		force, _ := cmd.Flags().GetBool("force")
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		p, err := plan.Load(args[0])
+		if err != nil {
+			return ctx.HandleOperationError("load plan", err)
+		}
+		if err := p.Validate(); err != nil {
+			return ctx.HandleOperationError("apply plan", fmt.Errorf("invalid plan: %w", err))
+		}
+
+		conflicts := p.Check(ws.Root)
+		if len(conflicts) > 0 && !force {
+			if ctx.IsJSONOutput() {
+				return ctx.HandleOperationError("apply plan", fmt.Errorf("%d file(s) changed since the plan was made: %s", len(conflicts), conflicts[0].Path))
+			}
+			cmdutil.ShowError("✗ Plan no longer matches the workspace:")
+			for _, conflict := range conflicts {
+				cmdutil.ShowError("  %s: %s", conflict.Path, conflict.Reason)
+			}
+			return ctx.HandleOperationError("apply plan", fmt.Errorf("%d file(s) changed since the plan was made; re-run the command with --plan or use --force", len(conflicts)))
+		}
+
+		if err := p.Apply(ws.Root); err != nil {
+			return ctx.HandleOperationError("apply plan", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response := ApplyResponse{
+				Operation: "apply",
+				Plan:      args[0],
+				Command:   p.Command,
+				Files:     make([]ApplyFile, 0, len(p.Files)),
+				Overrode:  conflicts,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			for _, file := range p.Files {
+				response.Files = append(response.Files, ApplyFile{Path: file.Path, Action: file.Action})
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		for _, conflict := range conflicts {
+			cmdutil.ShowWarning("⚠ Overwrote %s (%s)", conflict.Path, conflict.Reason)
+		}
+		cmdutil.ShowSuccess("✓ Applied plan for: %s", p.Command)
+		for _, file := range p.Files {
+			cmdutil.ShowInfo("  %-6s  %s", file.Action, file.Path)
+		}
+		return nil
+	},
+}
+
+// ApplyResponse represents the JSON response for the apply command
+type ApplyResponse struct {
+	Operation string               `json:"operation"`
+	Plan      string               `json:"plan"`
+	Command   string               `json:"command"`
+	Files     []ApplyFile          `json:"files"`
+	Overrode  []plan.Conflict      `json:"overrode,omitempty"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type ApplyFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+func init() {
+	applyCmd.Flags().Bool("force", false, "Apply even if files changed since the plan was made")
+}
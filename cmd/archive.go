@@ -1,19 +1,26 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
-	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
-var archiveNoVerify bool
+var (
+	archiveNoVerify bool
+	archiveNoStamp  bool
+)
 
 var archiveCmd = &cobra.Command{
 	Use:   "archive [SOURCE]",
@@ -23,11 +30,18 @@ var archiveCmd = &cobra.Command{
 This command is a smart alias for 'jot refile' that automatically uses the 
 workspace's configured archive location as the destination.
 
+The location may contain date placeholders, such as {{year}} or {{month}},
+which are filled in when a subtree is archived, so each year can have an
+archive file of its own. Archived subtrees are stamped with when they were
+archived and where from, as "archived" and "archived-from" lines under the
+heading; --no-stamp leaves them as they are.
+
 Examples:
   jot archive                              # Set up archive structure
   jot archive "inbox.md#old-project"       # Archive specific subtree
   jot archive --config                     # Show current archive configuration
-  jot archive --set-location "archive/2025.md#Archived"  # Set archive location`,
+  jot archive --set-location "archive/{{year}}.md#Archive"  # An archive file per year
+  jot expand "inbox.md#2024-*" | jot archive --stdin      # Archive each selector piped in`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmdutil.StartCommand(cmd)
@@ -49,6 +63,10 @@ Examples:
 			return setArchiveLocation(ctx, ws, setLocation)
 		}
 
+		if fromStdin, _ := cmd.Flags().GetBool("stdin"); fromStdin {
+			return archiveBatch(ctx, ws, args)
+		}
+
 		// If no source provided, initialize archive structure
 		if len(args) == 0 {
 			return initializeArchiveStructure(ctx, ws)
@@ -61,7 +79,7 @@ Examples:
 
 // showArchiveConfig displays the current archive configuration
 func showArchiveConfig(ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
-	archiveLocation := ws.GetArchiveLocation()
+	archiveLocation := currentArchiveLocation(ws)
 
 	if ctx.IsJSONOutput() {
 		response := ArchiveConfigResponse{
@@ -104,9 +122,70 @@ func setArchiveLocation(ctx *cmdutil.CommandContext, ws *workspace.Workspace, lo
 	return nil
 }
 
+// archiveSetup is what ensureArchiveStructure found or created
+type archiveSetup struct {
+	dir         string
+	file        string
+	items       []ArchiveItem
+	operations  []string
+	fileCreated bool
+}
+
 // initializeArchiveStructure creates the archive directory and file structure
 func initializeArchiveStructure(ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
-	archiveLocation := ws.GetArchiveLocation()
+	archiveLocation := currentArchiveLocation(ws)
+	setup, err := ensureArchiveStructure(ws)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	archiveDir, archiveFile := setup.dir, setup.file
+	createdItems, operations, fileCreated := setup.items, setup.operations, setup.fileCreated
+
+	// Output results
+	if ctx.IsJSONOutput() {
+		var totalCreated, totalExisting int
+		for _, item := range createdItems {
+			if item.Created {
+				totalCreated++
+			} else {
+				totalExisting++
+			}
+		}
+
+		response := ArchiveResponse{
+			Operation:    "initialize",
+			ArchiveDir:   archiveDir,
+			CreatedItems: createdItems,
+			Operations:   operations,
+			Summary: ArchiveSummary{
+				TotalItems:     len(createdItems),
+				ItemsCreated:   totalCreated,
+				ItemsExisting:  totalExisting,
+				DirectoryReady: true,
+			},
+			Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		}
+		return cmdutil.OutputJSON(response)
+	}
+
+	if !fileCreated && len(createdItems) == 0 {
+		fmt.Println("Archive structure already exists!")
+	} else {
+		fmt.Println("Archive structure ready!")
+	}
+
+	fmt.Printf("Archive location: %s\n", archiveLocation)
+	fmt.Printf("Full path: %s\n", archiveFile)
+	fmt.Println()
+	fmt.Println("Use 'jot archive \"source.md#section\"' to archive specific content.")
+
+	return nil
+}
+
+// ensureArchiveStructure creates the archive directory and file when they
+// are missing, reporting what it created
+func ensureArchiveStructure(ws *workspace.Workspace) (*archiveSetup, error) {
+	archiveLocation := currentArchiveLocation(ws)
 	pathUtil := cmdutil.NewPathUtil(ws)
 
 	// Parse the archive location to extract file path and section
@@ -119,9 +198,9 @@ func initializeArchiveStructure(ctx *cmdutil.CommandContext, ws *workspace.Works
 	var operations []string
 
 	// Create archive directory if it doesn't exist
-	if _, err := os.Stat(archiveDir); os.IsNotExist(err) {
+	if _, err := storage.Stat(archiveDir); os.IsNotExist(err) {
 		if err := pathUtil.EnsureDir(archiveDir); err != nil {
-			return ctx.HandleOperationError("create archive directory", err)
+			return nil, fmt.Errorf("failed to create archive directory: %w", err)
 		}
 
 		relativeDir, _ := filepath.Rel(ws.Root, archiveDir)
@@ -136,7 +215,7 @@ func initializeArchiveStructure(ctx *cmdutil.CommandContext, ws *workspace.Works
 
 	// Create archive file if it doesn't exist
 	fileCreated := false
-	if _, err := os.Stat(archiveFile); os.IsNotExist(err) {
+	if _, err := storage.Stat(archiveFile); os.IsNotExist(err) {
 		sectionName := "Archive"
 		if len(parts) > 1 {
 			sectionName = parts[1]
@@ -145,7 +224,7 @@ func initializeArchiveStructure(ctx *cmdutil.CommandContext, ws *workspace.Works
 		archiveContent := fmt.Sprintf("# %s\n\nArchived notes.\n\n", sectionName)
 
 		if err := pathUtil.SafeWriteFile(archiveFile, []byte(archiveContent)); err != nil {
-			return ctx.HandleOperationError("create archive file", err)
+			return nil, fmt.Errorf("failed to create archive file: %w", err)
 		}
 
 		fileCreated = true
@@ -160,110 +239,162 @@ func initializeArchiveStructure(ctx *cmdutil.CommandContext, ws *workspace.Works
 		operations = append(operations, fmt.Sprintf("Created archive file: %s", relativeFile))
 	}
 
-	// Output results
-	if ctx.IsJSONOutput() {
-		var totalCreated, totalExisting int
-		for _, item := range createdItems {
-			if item.Created {
-				totalCreated++
-			} else {
-				totalExisting++
-			}
-		}
-
-		response := ArchiveResponse{
-			Operation:    "initialize",
-			ArchiveDir:   archiveDir,
-			CreatedItems: createdItems,
-			Operations:   operations,
-			Summary: ArchiveSummary{
-				TotalItems:     len(createdItems),
-				ItemsCreated:   totalCreated,
-				ItemsExisting:  totalExisting,
-				DirectoryReady: true,
-			},
-			Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
-		}
-		return cmdutil.OutputJSON(response)
-	}
-
-	if !fileCreated && len(createdItems) == 0 {
-		fmt.Println("Archive structure already exists!")
-	} else {
-		fmt.Println("Archive structure ready!")
-	}
-
-	fmt.Printf("Archive location: %s\n", archiveLocation)
-	fmt.Printf("Full path: %s\n", archiveFile)
-	fmt.Println()
-	fmt.Println("Use 'jot archive \"source.md#section\"' to archive specific content.")
-
-	return nil
+	return &archiveSetup{
+		dir:         archiveDir,
+		file:        archiveFile,
+		items:       createdItems,
+		operations:  operations,
+		fileCreated: fileCreated,
+	}, nil
 }
 
 // archiveWithRefile delegates to refile command with archive destination
 func archiveWithRefile(ctx *cmdutil.CommandContext, ws *workspace.Workspace, source string) error {
 	pathUtil := cmdutil.NewPathUtil(ws)
-	archiveLocation := ws.GetArchiveLocation()
+	archiveLocation := currentArchiveLocation(ws)
 
 	// Parse the archive location to extract file path
 	parts := strings.SplitN(archiveLocation, "#", 2)
 	archiveFile := pathUtil.WorkspaceJoin(parts[0])
 
 	// Ensure archive file exists first
-	if _, err := os.Stat(archiveFile); os.IsNotExist(err) {
+	if _, err := storage.Stat(archiveFile); os.IsNotExist(err) {
 		if err := initializeArchiveStructure(ctx, ws); err != nil {
 			return err
 		}
 	}
 
-	// Initialize hook manager and run pre-archive hook
-	hookManager := hooks.NewManager(ws)
-	if !archiveNoVerify {
-		hookCtx := &hooks.HookContext{
-			Type:        hooks.PreArchive,
-			Workspace:   ws,
-			SourceFile:  source,
-			DestPath:    archiveLocation,
-			Timeout:     30 * time.Second,
-			AllowBypass: archiveNoVerify,
-		}
+	return ctx.HandleError(archiveSource(ctx, ws, source))
+}
 
-		result, err := hookManager.Execute(hookCtx)
-		if err != nil {
-			return ctx.HandleErrorf("pre-archive hook failed: %s", err.Error())
+// archiveBatch archives every selector read from stdin, creating the archive
+// file first if needed
+func archiveBatch(ctx *cmdutil.CommandContext, ws *workspace.Workspace, args []string) error {
+	selectors, err := stdinSelectors(ws, args)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	setup, err := ensureArchiveStructure(ws)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	if !ctx.IsJSONOutput() {
+		for _, operation := range setup.operations {
+			fmt.Println(operation)
 		}
+	}
+	return runBatch(ctx, "archive", selectors, func(item *BatchItem) error {
+		return archiveSource(ctx, ws, item.Selector)
+	})
+}
 
-		if result.Aborted {
-			return ctx.HandleErrorf("pre-archive hook aborted operation")
+// archiveSource refiles one source to the archive location, running the
+// archive hooks around it
+func archiveSource(ctx *cmdutil.CommandContext, ws *workspace.Workspace, source string) error {
+	archiveLocation := currentArchiveLocation(ws)
+
+	var stamp refileStamp
+	if !archiveNoStamp {
+		now := time.Now()
+		stamp = func(content []byte, sourcePath *markdown.HeadingPath, subtree *markdown.Subtree) []byte {
+			return archiveStamp(content, subtreeOrigin(ws, sourcePath.File, subtree), now)
 		}
 	}
 
-	if !ctx.IsJSONOutput() {
-		fmt.Printf("Archiving '%s' to '%s'...\n", source, archiveLocation)
+	change := mutation{Hook: "archive", Source: source, Dest: archiveLocation, NoVerify: archiveNoVerify}
+	return change.run(ctx, ws, func() error {
+		if !ctx.IsJSONOutput() {
+			fmt.Printf("Archiving '%s' to '%s'...\n", source, archiveLocation)
+		}
+		// Call the internal refile function directly to avoid recursion
+		return executeRefileWith(source, archiveLocation, ctx, ws, stamp)
+	})
+}
+
+// currentArchiveLocation returns the configured archive location with its
+// date placeholders filled in for today
+func currentArchiveLocation(ws *workspace.Workspace) string {
+	return template.ExpandPlaceholders(ws.GetArchiveLocation(), time.Now(), nil)
+}
+
+// archivePlaceholder matches a placeholder in the archive location
+var archivePlaceholder = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// isArchiveFile reports whether a workspace-relative file is an archive
+// file: the archive location's file, for any date its placeholders take
+func isArchiveFile(ws *workspace.Workspace, file string) bool {
+	location, _, _ := strings.Cut(ws.GetArchiveLocation(), "#")
+	parts := archivePlaceholder.Split(filepath.ToSlash(filepath.Clean(location)), -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
 	}
+	pattern := "^" + strings.Join(parts, "[^/]*") + "$"
+	matched, err := regexp.MatchString(pattern, filepath.ToSlash(filepath.Clean(file)))
+	return err == nil && matched
+}
 
-	// Call the internal refile function directly to avoid recursion
-	err := executeRefile(source, archiveLocation, ctx, ws)
-
-	// Run post-archive hook (informational only)
-	if !archiveNoVerify && err == nil {
-		hookCtx := &hooks.HookContext{
-			Type:        hooks.PostArchive,
-			Workspace:   ws,
-			SourceFile:  source,
-			DestPath:    archiveLocation,
-			Timeout:     30 * time.Second,
-			AllowBypass: archiveNoVerify,
+// subtreeOrigin returns a full-path selector for a subtree where it is now
+func subtreeOrigin(ws *workspace.Workspace, file string, subtree *markdown.Subtree) string {
+	origin := filepath.ToSlash(file) + "#" + subtree.Heading
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		return origin
+	}
+	// Heading offsets point into the heading's line, past the subtree's start
+	for _, heading := range markdown.FindAllHeadings(markdown.ParseDocument(content), content) {
+		if heading.Offset >= subtree.StartOffset {
+			return filepath.ToSlash(file) + "#" + strings.Join(heading.Path, "/")
 		}
+	}
+	return origin
+}
 
-		_, hookErr := hookManager.Execute(hookCtx)
-		if hookErr != nil && !ctx.IsJSONOutput() {
-			cmdutil.ShowWarning("Warning: post-archive hook failed: %s", hookErr.Error())
+// archiveStamp adds "archived" and "archived-from" lines under the heading
+// that opens content, after any name: value lines already there and written
+// as they are: as list items unless those are plain lines or comments
+func archiveStamp(content []byte, origin string, now time.Time) []byte {
+	at := len(content)
+	if newline := bytes.IndexByte(content, '\n'); newline >= 0 {
+		at = newline + 1
+	}
+	properties, length := markdown.LeadingProperties(content[at:])
+
+	format := "- %s: %s\n"
+	if len(properties) > 0 {
+		lines := strings.Split(strings.TrimSpace(string(content[at:at+length])), "\n")
+		last := strings.TrimSpace(lines[len(lines)-1])
+		switch {
+		case strings.HasPrefix(last, "<!--"):
+			format = "<!-- %s: %s -->\n"
+		case !strings.HasPrefix(last, "- ") && !strings.HasPrefix(last, "* ") && !strings.HasPrefix(last, "+ "):
+			format = "%s: %s\n"
 		}
 	}
+	stamp := fmt.Sprintf(format, "archived", now.Format(logTimeLayout)) + fmt.Sprintf(format, "archived-from", origin)
+
+	stamped := make([]byte, 0, len(content)+len(stamp)+2)
+	if len(properties) > 0 {
+		at += length
+		stamped = append(stamped, content[:at]...)
+		if content[at-1] != '\n' {
+			stamped = append(stamped, '\n')
+		}
+		stamped = append(stamped, stamp...)
+		return append(stamped, content[at:]...)
+	}
 
-	return err
+	rest := bytes.TrimLeft(content[at:], "\n")
+	stamped = append(stamped, content[:at]...)
+	if at > 0 && content[at-1] != '\n' {
+		stamped = append(stamped, '\n')
+	}
+	stamped = append(stamped, '\n')
+	stamped = append(stamped, stamp...)
+	if len(rest) > 0 {
+		stamped = append(stamped, '\n')
+		stamped = append(stamped, rest...)
+	}
+	return stamped
 }
 
 // JSON response structures for archive command
@@ -302,4 +433,6 @@ func init() {
 	archiveCmd.Flags().Bool("config", false, "Show current archive configuration")
 	archiveCmd.Flags().String("set-location", "", "Set archive location path")
 	archiveCmd.Flags().BoolVar(&archiveNoVerify, "no-verify", false, "Skip hooks verification")
+	archiveCmd.Flags().Bool("stdin", false, "Archive each selector read from stdin, one per line")
+	archiveCmd.Flags().BoolVar(&archiveNoStamp, "no-stamp", false, "Do not add archived and archived-from lines to the subtree")
 }
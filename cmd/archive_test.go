@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestArchiveStamp(t *testing.T) {
+	now := time.Date(2025, 7, 7, 14, 3, 0, 0, time.Local)
+	stamp := "- archived: 2025-07-07 14:03\n- archived-from: inbox.md#Projects/Old\n"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"body", "## Old\n\nDone.\n", "## Old\n\n" + stamp + "\nDone.\n"},
+		{"heading only", "## Old", "## Old\n\n" + stamp},
+		{"list properties", "## Old\n\n- status: done\n\nDone.\n", "## Old\n\n- status: done\n" + stamp + "\nDone.\n"},
+		{"plain properties", "## Old\nstatus: done\nowner: sam", "## Old\nstatus: done\nowner: sam\narchived: 2025-07-07 14:03\narchived-from: inbox.md#Projects/Old\n"},
+		{"comment properties", "## Old\n<!-- id: 42 -->\n", "## Old\n<!-- id: 42 -->\n<!-- archived: 2025-07-07 14:03 -->\n<!-- archived-from: inbox.md#Projects/Old -->\n"},
+		{"child heading", "## Old\n### Notes\n", "## Old\n\n" + stamp + "\n### Notes\n"},
+	}
+	for _, tt := range tests {
+		if got := string(archiveStamp([]byte(tt.content), "inbox.md#Projects/Old", now)); got != tt.want {
+			t.Errorf("%s: archiveStamp() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsArchiveFile(t *testing.T) {
+	ws := &workspace.Workspace{Config: &workspace.WorkspaceConfig{ArchiveLocation: "archive/{{year}}.md#Archive"}}
+	tests := map[string]bool{
+		"archive/2024.md":      true,
+		"archive/2025.md":      true,
+		"archive/old/2024.md":  false,
+		"archive/2025.md.bak":  false,
+		"inbox.md":             false,
+		"./archive/archive.md": true,
+	}
+	for file, want := range tests {
+		if got := isArchiveFile(ws, file); got != want {
+			t.Errorf("isArchiveFile(%q) = %v, want %v", file, got, want)
+		}
+	}
+
+	ws.Config.ArchiveLocation = "archive/archive.md#Archive"
+	if !isArchiveFile(ws, "archive/archive.md") || isArchiveFile(ws, "archive/2025.md") {
+		t.Error("a location without placeholders names only its own file")
+	}
+}
+
+func TestSubtreeOrigin(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot"), InboxPath: filepath.Join(root, "inbox.md")}
+	content := "# Projects\n\n## Old\n\nDone.\n"
+	if err := os.WriteFile(filepath.Join(root, "work.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := markdown.ParsePath("work.md#old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtree, err := ExtractSubtree(ws, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := subtreeOrigin(ws, "work.md", subtree); got != "work.md#Projects/Old" {
+		t.Errorf("subtreeOrigin() = %q, want the full heading path", got)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// BatchResponse is the JSON response for a command run with --stdin
+type BatchResponse struct {
+	Operation string               `json:"operation"`
+	Results   []BatchItem          `json:"results"`
+	Summary   BatchSummary         `json:"summary"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// BatchItem is what happened to one selector of a --stdin batch
+type BatchItem struct {
+	Selector string `json:"selector"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	File     string `json:"file,omitempty"`
+	Heading  string `json:"heading,omitempty"`
+	Content  string `json:"content,omitempty"` // Peeked content
+}
+
+// BatchSummary counts the results of a --stdin batch
+type BatchSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// readSelectorLines reads one selector per line, skipping blank lines and
+// expanding aliases
+func readSelectorLines(ws *workspace.Workspace, r io.Reader) ([]string, error) {
+	var selectors []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if expanded, ok := ws.ExpandAlias(line); ok {
+			line = expanded
+		}
+		selectors = append(selectors, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read selectors from stdin: %w", err)
+	}
+	return selectors, nil
+}
+
+// stdinSelectors returns the selectors piped to a command run with
+// --stdin, refusing selector arguments alongside them
+func stdinSelectors(ws *workspace.Workspace, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return nil, cmdutil.NewUsageError(fmt.Errorf("--stdin reads selectors from stdin and takes no selector arguments"))
+	}
+	if isTerminal(os.Stdin) {
+		return nil, cmdutil.NewUsageError(fmt.Errorf("--stdin reads one selector per line from a pipe, such as 'jot expand ... | jot peek --stdin'"))
+	}
+	selectors, err := readSelectorLines(ws, os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("no selectors on stdin")
+	}
+	return selectors, nil
+}
+
+// runBatch runs each for every selector in order, carrying on past
+// failures. Failures and the summary go to stderr, so stdout holds only
+// what each selector printed; with --json, one response lists them all.
+// The exit status is 1 if any selector failed.
+func runBatch(ctx *cmdutil.CommandContext, operation string, selectors []string, each func(item *BatchItem) error) error {
+	response := BatchResponse{Operation: operation, Results: make([]BatchItem, 0, len(selectors))}
+	for _, selector := range selectors {
+		item := BatchItem{Selector: selector}
+		if err := each(&item); err != nil {
+			item.Error = err.Error()
+			response.Summary.Failed++
+			if !ctx.IsJSONOutput() {
+				cmdutil.Fprintf(os.Stderr, "✗ %s: %v\n", selector, err)
+			}
+		} else {
+			item.Success = true
+			response.Summary.Succeeded++
+		}
+		response.Results = append(response.Results, item)
+	}
+	response.Summary.Total = len(selectors)
+
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, response.Summary.Failed == 0, ctx.StartTime)
+		if err := cmdutil.OutputJSON(response); err != nil {
+			return err
+		}
+	} else if response.Summary.Failed > 0 || response.Summary.Total > 1 {
+		fmt.Fprintf(os.Stderr, "%s: %d of %d selector%s succeeded, %d failed\n", operation,
+			response.Summary.Succeeded, response.Summary.Total, pluralize(response.Summary.Total), response.Summary.Failed)
+	}
+
+	if response.Summary.Failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
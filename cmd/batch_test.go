@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestReadSelectorLines(t *testing.T) {
+	ws := &workspace.Workspace{
+		Config: &workspace.WorkspaceConfig{Aliases: map[string]string{"proj": "work.md#projects"}},
+	}
+	input := "inbox.md#meeting\n\n  work.md#projects/frontend  \r\n@proj/backend\nnotes.md"
+
+	got, err := readSelectorLines(ws, strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"inbox.md#meeting", "work.md#projects/frontend", "work.md#projects/backend", "notes.md"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// benchmarkNote builds a note of about size bytes with nested sections,
+// projects and tasks
+func benchmarkNote(size int) []byte {
+	var b strings.Builder
+	body := "Notes about the work, with a [link](https://example.com) and some *emphasis*.\n\n- first item\n- second item\n\n"
+	for section := 0; b.Len() < size || section == 0; section++ {
+		fmt.Fprintf(&b, "# Section %d\n\n", section)
+		for project := 0; project < 10 && b.Len() < size; project++ {
+			fmt.Fprintf(&b, "## Project %d-%d\n\n%s", section, project, body)
+			for task := 0; task < 5; task++ {
+				fmt.Fprintf(&b, "### Task %d-%d-%d\n\n%s", section, project, task, body)
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+// rewriteBenchmarkNote writes content to path with a trailing marker for
+// iteration i, so each iteration parses the file afresh as a new command would
+func rewriteBenchmarkNote(b *testing.B, path string, content []byte, i int) {
+	b.Helper()
+	b.StopTimer()
+	marked := append(append([]byte(nil), content...), fmt.Sprintf("\n<!-- %d -->\n", i)...)
+	if err := os.WriteFile(path, marked, 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.StartTimer()
+}
+
+// benchmarkWorkspace creates a workspace of files notes spread over
+// directories of 100, plus lib/big.md of bigSize bytes
+func benchmarkWorkspace(b *testing.B, files, bigSize int) *workspace.Workspace {
+	b.Helper()
+	root := b.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "inbox.md"),
+		LibDir:    filepath.Join(root, "lib"),
+		Config:    &workspace.WorkspaceConfig{},
+	}
+	for _, dir := range []string{ws.JotDir, ws.LibDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	small := benchmarkNote(2048)
+	for i := 0; i < files; i++ {
+		dir := filepath.Join(ws.LibDir, fmt.Sprintf("d%03d", i/100))
+		if i%100 == 0 {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("note%05d.md", i)), small, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(ws.InboxPath, []byte("# Inbox\n"), 0644); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws.LibDir, "big.md"), benchmarkNote(bigSize), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return ws
+}
+
+func BenchmarkScanWorkspace(b *testing.B) {
+	ws := benchmarkWorkspace(b, 10000, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		files, err := scanWorkspaceMarkdownFiles(ws)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(files) != 10002 {
+			b.Fatalf("scanned %d files", len(files))
+		}
+	}
+}
+
+func BenchmarkResolveSelector(b *testing.B) {
+	ws := benchmarkWorkspace(b, 0, 1<<20)
+	big := filepath.Join(ws.LibDir, "big.md")
+	original := benchmarkNote(1 << 20)
+	path, err := markdown.ParsePath("lib/big.md#Section 40/Project 40-5/Task 40-5-3")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rewriteBenchmarkNote(b, big, original, i)
+		if _, err := ExtractSubtree(ws, path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRefile(b *testing.B) {
+	ws := benchmarkWorkspace(b, 0, 1<<20)
+	big := filepath.Join(ws.LibDir, "big.md")
+	original, err := os.ReadFile(big)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		source string
+		dest   string
+	}{
+		{"same-file", "lib/big.md#Section 10/Project 10-2", "lib/big.md#Section 90/Project 90-7"},
+		{"cross-file", "lib/big.md#Section 10/Project 10-2", "inbox.md#Inbox"},
+	}
+	for _, c := range cases {
+		sourcePath, err := markdown.ParsePath(c.source)
+		if err != nil {
+			b.Fatal(err)
+		}
+		destPath, err := markdown.ParsePath(c.dest)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rewriteBenchmarkNote(b, ws.InboxPath, []byte("# Inbox\n"), i)
+				rewriteBenchmarkNote(b, big, original, i)
+
+				subtree, err := ExtractSubtree(ws, sourcePath)
+				if err != nil {
+					b.Fatal(err)
+				}
+				dest, err := ResolveDestination(ws, destPath, false)
+				if err != nil {
+					b.Fatal(err)
+				}
+				transformed := TransformSubtreeLevel(subtree, dest.TargetLevel)
+				if err := performRefile(ws, sourcePath, subtree, dest, transformed); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFileTOC(b *testing.B) {
+	ws := benchmarkWorkspace(b, 0, 1<<20)
+	big := filepath.Join(ws.LibDir, "big.md")
+	original := benchmarkNote(1 << 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rewriteBenchmarkNote(b, big, original, i)
+		if _, err := fileTOC(ws, "lib/big.md"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
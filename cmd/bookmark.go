@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/editor"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var goEdit bool
+
+var bookmarkCmd = &cobra.Command{
+	Use:     "bookmark",
+	Aliases: []string{"bookmarks"},
+	Short:   "Manage bookmarks for quick reading and navigation",
+	Long: `Manage named bookmarks to files and subtrees you read often.
+
+Bookmarks are kept in .jot/bookmarks.json. Where aliases give short names to
+destinations used in selectors, bookmarks are places to go back to:
+'jot go NAME' prints the bookmarked content or opens it in your editor.
+
+Without a name, 'bookmark add' names the bookmark after the last heading in
+the selector, or the file name.
+
+Examples:
+  jot bookmark add "design.md#architecture"   # Bookmarked as "architecture"
+  jot bookmark add "work.md#projects/q3" goals
+  jot bookmark list
+  jot go goals                                  # Print the subtree
+  jot go goals --edit                           # Open it in your editor
+  jot bookmark remove goals`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bookmarkList(cmd)
+	},
+}
+
+var bookmarkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List bookmarks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bookmarkList(cmd)
+	},
+}
+
+var bookmarkAddCmd = &cobra.Command{
+	Use:   "add SELECTOR [NAME]",
+	Short: "Add or replace a bookmark",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		selector := args[0]
+		name := workspace.BookmarkName(selector)
+		if len(args) == 2 {
+			name = args[1]
+		}
+		if err := workspace.ValidateBookmarkName(name); err != nil {
+			return ctx.HandleValidation("name", name, err)
+		}
+		if _, _, err := readSelectorTarget(ws, selector); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		bookmark, previous, err := ws.SetBookmark(name, selector)
+		if err != nil {
+			return ctx.HandleOperationError("save bookmark", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(BookmarkResponse{
+				Operation: "add_bookmark",
+				Bookmarks: []workspace.Bookmark{bookmark},
+				Replaced:  previous,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		if previous != "" {
+			cmdutil.ShowSuccess("✓ Updated bookmark %s: %s (was %s)", name, selector, previous)
+		} else {
+			cmdutil.ShowSuccess("✓ Added bookmark %s: %s", name, selector)
+		}
+		return nil
+	},
+}
+
+var bookmarkRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a bookmark",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		removed, err := ws.RemoveBookmark(args[0])
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(BookmarkResponse{
+				Operation: "remove_bookmark",
+				Bookmarks: []workspace.Bookmark{removed},
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Removed bookmark %s", removed.Name)
+		return nil
+	},
+}
+
+var goCmd = &cobra.Command{
+	Use:   "go NAME",
+	Short: "Print or edit a bookmarked file or subtree",
+	Long: `Print the file or subtree a bookmark points to, or open it in your editor
+with --edit. Editors that accept "+LINE" open at the bookmarked heading.
+
+Examples:
+  jot go architecture          # Print the bookmarked subtree
+  jot go architecture --edit   # Open design.md at the heading`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		bookmark, err := ws.FindBookmark(args[0])
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		target, line, err := readSelectorTarget(ws, bookmark.Selector)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if goEdit {
+			filePath := cmdutil.ResolveWorkspaceRelativePath(ws, target.File)
+			if err := editor.OpenFile(filePath, line); err != nil {
+				return ctx.HandleOperationError("open editor", err)
+			}
+			return nil
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(GoResponse{
+				Bookmark: bookmark,
+				File:     target.File,
+				Heading:  target.Heading,
+				Line:     line,
+				Content:  target.Content,
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		fmt.Print(target.Content)
+		if !strings.HasSuffix(target.Content, "\n") {
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+// readSelectorTarget reads the file or subtree a selector points to, and the
+// line it starts on
+func readSelectorTarget(ws *workspace.Workspace, selector string) (rpcPeekResult, int, error) {
+	if !strings.Contains(selector, "#") {
+		content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, selector))
+		if err != nil {
+			return rpcPeekResult{}, 0, cmdutil.NewFileError("read", selector, err)
+		}
+		return rpcPeekResult{Selector: selector, File: selector, Content: string(content)}, 1, nil
+	}
+
+	path, err := markdown.ParsePath(selector)
+	if err != nil {
+		return rpcPeekResult{}, 0, err
+	}
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, path.File))
+	if err != nil {
+		return rpcPeekResult{}, 0, cmdutil.NewFileError("read", path.File, err)
+	}
+	subtree, err := markdown.FindSubtree(markdown.ParseDocument(content), content, path)
+	if err != nil {
+		return rpcPeekResult{}, 0, err
+	}
+	return rpcPeekResult{
+		Selector: selector,
+		File:     path.File,
+		Heading:  subtree.Heading,
+		Level:    subtree.Level,
+		Content:  string(subtree.Content),
+	}, markdown.CalculateLineNumber(content, subtree.StartOffset), nil
+}
+
+// bookmarkList shows all bookmarks
+func bookmarkList(cmd *cobra.Command) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(BookmarkResponse{
+			Operation: "list_bookmarks",
+			Bookmarks: bookmarks,
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	if len(bookmarks) == 0 {
+		fmt.Println("No bookmarks.")
+		fmt.Println("\nUse 'jot bookmark add <selector> [name]' to create one")
+		return nil
+	}
+
+	for _, bookmark := range bookmarks {
+		fmt.Printf("%-16s %s\n", bookmark.Name, bookmark.Selector)
+	}
+	return nil
+}
+
+// completeBookmarkNames completes the names of existing bookmarks
+func completeBookmarkNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	bookmarks, _ := ws.LoadBookmarks()
+	var names []string
+	for _, bookmark := range bookmarks {
+		if strings.HasPrefix(bookmark.Name, toComplete) {
+			names = append(names, bookmark.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBookmarkSelector completes the selector of 'jot bookmark add SELECTOR [NAME]'
+func completeBookmarkSelector(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSelectorArgs(cmd, args, toComplete)
+}
+
+// BookmarkResponse represents the JSON response for bookmark commands
+type BookmarkResponse struct {
+	Operation string               `json:"operation"`
+	Bookmarks []workspace.Bookmark `json:"bookmarks"`
+	Replaced  string               `json:"replaced,omitempty"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// GoResponse represents the JSON response for go
+type GoResponse struct {
+	Bookmark workspace.Bookmark   `json:"bookmark"`
+	File     string               `json:"file"`
+	Heading  string               `json:"heading,omitempty"`
+	Line     int                  `json:"line"`
+	Content  string               `json:"content"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func init() {
+	bookmarkCmd.AddCommand(bookmarkListCmd)
+	bookmarkCmd.AddCommand(bookmarkAddCmd)
+	bookmarkCmd.AddCommand(bookmarkRemoveCmd)
+	bookmarkAddCmd.ValidArgsFunction = completeBookmarkSelector
+	bookmarkRemoveCmd.ValidArgsFunction = completeBookmarkNames
+	goCmd.ValidArgsFunction = completeBookmarkNames
+	goCmd.Flags().BoolVarP(&goEdit, "edit", "e", false, "Open the bookmark in your editor instead of printing it")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -9,12 +10,15 @@ import (
 	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dirconfig"
 	"github.com/johncoder/jot/internal/editor"
 	"github.com/johncoder/jot/internal/hooks"
 	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/template"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark/ast"
 )
 
 var (
@@ -45,7 +49,12 @@ Examples:
   jot capture --template meeting           # Use meeting template in editor (same as above)
   jot capture standup --content "Completed API design"
   echo "Notes here" | jot capture meeting
-  jot capture --content "Quick note"       # Direct append to inbox`,
+  journalctl -u app | jot capture --stream # Stream a large log into the inbox
+  jot capture --content "Quick note"       # Direct append to inbox
+  jot capture --to lib/projects/x.md#log   # Capture with the project directory's template
+  jot capture meeting --preview            # Show what the template would write, and where
+  jot capture --show-last                  # Print the last captured note
+  jot capture --amend                      # Correct the last note in the editor`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmdutil.StartCommand(cmd)
@@ -54,18 +63,27 @@ Examples:
 		if err != nil {
 			return ctx.HandleError(err)
 		}
+		if capturePreview && (captureAmend || captureShowLast || captureStream) {
+			return ctx.HandleUsage(fmt.Errorf("--preview cannot be combined with --amend, --show-last or --stream"))
+		}
+		if captureAmend || captureShowLast {
+			return runCaptureLast(ctx, ws)
+		}
 
 		// Initialize hook manager
 		hookManager := hooks.NewManager(ws)
+		if captureStream {
+			return streamCapture(ctx, ws, hookManager)
+		}
 
-		// Run pre-capture hook unless --no-verify is set
-		if !captureNoVerify {
+		// Run pre-capture hook unless --no-verify is set. Hooks may act on
+		// the note, so a preview skips them.
+		if !captureNoVerify && !capturePreview {
 			hookCtx := &hooks.HookContext{
 				Type:         hooks.PreCapture,
 				Workspace:    ws,
 				Content:      captureContent,
 				TemplateName: captureTemplate,
-				Timeout:      30 * time.Second,
 				AllowBypass:  captureNoVerify,
 			}
 
@@ -89,6 +107,18 @@ Examples:
 			captureTemplate = args[0]
 		}
 
+		// Captures aimed with --to take defaults from the directory
+		target, err := resolveCaptureTo(ws, captureTo)
+		if err != nil {
+			return ctx.HandleValidation("to", captureTo, err)
+		}
+		if target != nil && target.Defaults != nil {
+			captureDefaults = target.Defaults
+			if captureTemplate == "" {
+				captureTemplate = target.Defaults.Template
+			}
+		}
+
 		// Determine content source
 		var appendContent string
 		var useEditor bool = true
@@ -134,7 +164,10 @@ Examples:
 				return ctx.HandleOperationError("template", err)
 			}
 
-			if useEditor {
+			// The cursor marker is reported in JSON output, never saved
+			renderedTemplate, cursor := template.ExtractCursor(renderedTemplate)
+
+			if useEditor && !capturePreview {
 				// Open rendered template in editor
 				tempFile, err := os.CreateTemp("", "jot-capture-*.md")
 				if err != nil {
@@ -151,150 +184,62 @@ Examples:
 				if !ctx.IsJSONOutput() {
 					fmt.Printf("Opening template '%s' in editor...\n", captureTemplate)
 				}
-				editedContent, err := editor.OpenEditor(renderedTemplate)
+				cursorLine := 0
+				if cursor != nil {
+					cursorLine = cursor.Line
+				}
+				editedContent, err := editor.OpenEditorAt(renderedTemplate, cursorLine)
 				if err != nil {
 					return ctx.HandleOperationError("editor", fmt.Errorf("failed to open editor: %w", err))
 				}
 				finalContent = strings.TrimSpace(editedContent)
+				// The cursor position no longer applies to edited content
+				cursor = nil
 			} else {
 				finalContent = renderedTemplate
 			}
 
-			// Use DestinationFile if specified - can be either a file or selector
-			destination := t.DestinationFile
-			if destination == "" {
-				destination = "inbox.md"
+			if err := checkContent([]byte(finalContent)); err != nil {
+				return ctx.HandleError(err)
 			}
-
-			// Check if destination is a selector (contains #) or just a file
-			if strings.Contains(destination, "#") {
-				// Use selector-based refile logic
-				if err := refileContentToDestination(ws, finalContent, destination, t.RefileMode); err != nil {
-					return ctx.HandleOperationError("refile", fmt.Errorf("failed to refile to destination '%s': %w", destination, err))
-				}
-
-				if ctx.IsJSONOutput() {
-					templateInfo := &CaptureTemplate{
-						Name:            captureTemplate,
-						RenderedContent: finalContent,
-						DestinationFile: destination,
-						RefileMode:      t.RefileMode,
-					}
-					lineCount := strings.Count(finalContent, "\n") + 1
-					if len(finalContent) == 0 {
-						lineCount = 0
-					}
-
-					response := CaptureResponse{
-						Operation: "capture_and_refile",
-						ContentInfo: CaptureContent{
-							Content:        finalContent,
-							CharacterCount: len(finalContent),
-							LineCount:      lineCount,
-							Source:         getContentSource(appendContent, useEditor),
-						},
-						FileInfo: CaptureFile{
-							FilePath:    destination,
-							IsInbox:     false,
-							IsSelector:  true,
-							Destination: destination,
-						},
-						Template: templateInfo,
-						Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
-					}
-					return cmdutil.OutputJSON(response)
-				}
-
-				// Run post-capture hook for refile case
-				if !captureNoVerify {
-					hookCtx := &hooks.HookContext{
-						Type:         hooks.PostCapture,
-						Workspace:    ws,
-						Content:      finalContent,
-						TemplateName: captureTemplate,
-						SourceFile:   destination,
-						Timeout:      30 * time.Second,
-						AllowBypass:  captureNoVerify,
-					}
-
-					_, err := hookManager.Execute(hookCtx)
-					if err != nil && !ctx.IsJSONOutput() {
-						cmdutil.ShowWarning("Warning: post-capture hook failed: %s", err.Error())
-					}
-				}
-
-				cmdutil.ShowSuccess("✓ Captured '%s' and refiled to '%s'", captureTemplate, destination)
-			} else {
-				// Simple file destination
-				destinationPath := destination
-				pathUtil := cmdutil.NewPathUtil(ws)
-				if destination == "inbox.md" {
-					destinationPath = ws.InboxPath
-				} else if !filepath.IsAbs(destination) {
-					// Use workspace root for relative paths, not lib/ directory
-					destinationPath = pathUtil.WorkspaceJoin(destination)
+			if !capturePreview {
+				unlock, err := lockCapture(ws)
+				if err != nil {
+					return ctx.HandleError(err)
 				}
+				defer unlock()
+			}
 
-				if err := ws.AppendToFile(destinationPath, finalContent); err != nil {
-					return ctx.HandleOperationError("save", fmt.Errorf("failed to save note: %w", err))
-				}
+			// Use DestinationFile if specified - can be either a file or selector
+			destination, newFile, err := templateDestination(ws, tm, t)
+			if err != nil {
+				return ctx.HandleOperationError("template", err)
+			}
 
-				if ctx.IsJSONOutput() {
-					templateInfo := &CaptureTemplate{
-						Name:            captureTemplate,
-						RenderedContent: finalContent,
-						DestinationFile: destination,
-						RefileMode:      t.RefileMode,
-					}
-					lineCount := strings.Count(finalContent, "\n") + 1
-					if len(finalContent) == 0 {
-						lineCount = 0
-					}
-
-					response := CaptureResponse{
-						Operation: "capture_to_file",
-						ContentInfo: CaptureContent{
-							Content:        finalContent,
-							CharacterCount: len(finalContent),
-							LineCount:      lineCount,
-							Source:         getContentSource(appendContent, useEditor),
-						},
-						FileInfo: CaptureFile{
-							FilePath:    destinationPath,
-							IsInbox:     destination == "inbox.md",
-							IsSelector:  false,
-							Destination: destination,
-						},
-						Template: templateInfo,
-						Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
-					}
-					return cmdutil.OutputJSON(response)
+			mode := t.RefileMode
+			if target != nil {
+				destination, newFile, err = target.destination(ws, t.FileTemplate)
+				if err != nil {
+					return ctx.HandleOperationError("capture", err)
 				}
-
-				// Run post-capture hook for file destination case
-				if !captureNoVerify {
-					hookCtx := &hooks.HookContext{
-						Type:         hooks.PostCapture,
-						Workspace:    ws,
-						Content:      finalContent,
-						TemplateName: captureTemplate,
-						SourceFile:   destinationPath,
-						Timeout:      30 * time.Second,
-						AllowBypass:  captureNoVerify,
-					}
-
-					_, err := hookManager.Execute(hookCtx)
-					if err != nil && !ctx.IsJSONOutput() {
-						cmdutil.ShowWarning("Warning: post-capture hook failed: %s", err.Error())
-					}
+				if target.Mode != "" {
+					mode = target.Mode
 				}
-
-				cmdutil.ShowSuccess("✓ Captured '%s' to '%s'", captureTemplate, destination)
 			}
 
-			return nil
+			// Split after editing, so the markers can be moved in the editor
+			if len(t.Splits) > 0 {
+				return captureSplit(ctx, ws, hookManager, tm, t, finalContent, destination, mode, newFile)
+			}
+			if capturePreview {
+				return previewCapture(ctx, ws, finalContent, destination, mode, newFile, false, cursor)
+			}
+			return captureToDestination(ctx, ws, hookManager, finalContent, destination, mode, newFile, getContentSource(appendContent, useEditor), cursor)
 		} else {
 			// No template - handle as before
+			if appendContent == "" && useEditor && capturePreview {
+				return ctx.HandleUsage(fmt.Errorf("nothing to preview; give a template, --content or piped input"))
+			}
 			if appendContent == "" && useEditor {
 				// Open editor for free-form capture
 				if !ctx.IsJSONOutput() {
@@ -333,10 +278,46 @@ Examples:
 			return nil
 		}
 
+		if err := checkContent([]byte(finalContent)); err != nil {
+			return ctx.HandleError(err)
+		}
+		if !capturePreview {
+			unlock, err := lockCapture(ws)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			defer unlock()
+		}
+
+		if target != nil {
+			destination, newFile, err := target.destination(ws, "")
+			if err != nil {
+				return ctx.HandleOperationError("capture", err)
+			}
+			mode := target.Mode
+			if mode == "" {
+				mode = "append"
+			}
+			if capturePreview {
+				return previewCapture(ctx, ws, finalContent, destination, mode, newFile, false, nil)
+			}
+			return captureToDestination(ctx, ws, hookManager, finalContent, destination, mode, newFile, getContentSource(appendContent, useEditor), nil)
+		}
+
+		if capturePreview {
+			return previewCapture(ctx, ws, finalContent, "inbox.md", "", "", true, nil)
+		}
+
 		// Append to inbox
+		before, _ := storage.ReadFile(ws.InboxPath)
+		var snapshot *hooks.Snapshot
+		if !captureNoVerify {
+			snapshot = postHookSnapshot(hookManager, hooks.PostCapture, ws, ws.InboxPath)
+		}
 		if err := ws.AppendToInbox(finalContent); err != nil {
 			return ctx.HandleOperationError("save", fmt.Errorf("failed to save note: %w", err))
 		}
+		recordCapture(ws, ws.InboxPath, before, finalContent, captureTemplate, "inbox.md")
 
 		// Run post-capture hook unless --no-verify is set
 		if !captureNoVerify {
@@ -346,7 +327,7 @@ Examples:
 				Content:      finalContent,
 				TemplateName: captureTemplate,
 				SourceFile:   ws.InboxPath,
-				Timeout:      30 * time.Second,
+				Diff:         snapshot.Diff(),
 				AllowBypass:  captureNoVerify,
 			}
 
@@ -401,6 +382,7 @@ Examples:
 			cmdutil.ShowSuccess("✓ Used template: %s", captureTemplate)
 		}
 		cmdutil.ShowSuccess("✓ Added to %s", ws.InboxPath)
+		showInboxAgingHint(ws)
 
 		return nil
 	},
@@ -410,11 +392,244 @@ func init() {
 	captureCmd.Flags().StringVar(&captureTemplate, "template", "", "Use a named template for structured capture")
 	captureCmd.Flags().StringVar(&captureContent, "content", "", "Note content to append (skips editor)")
 	captureCmd.Flags().StringVar(&captureNote, "note", "", "Note content to append (legacy alias for --content)")
+	captureCmd.Flags().StringVar(&captureTo, "to", "", "Capture to a file or selector instead of the inbox, with its directory's defaults")
 	captureCmd.Flags().BoolVar(&captureNoVerify, "no-verify", false, "Skip hooks verification")
+	captureCmd.Flags().BoolVar(&forceProtected, "force", false, "Write into protected files and subtrees, and write binary or very large content")
+	captureCmd.Flags().BoolVar(&captureStream, "stream", false, "Stream piped input into the inbox without holding it in memory")
+	captureCmd.Flags().BoolVar(&capturePreview, "preview", false, "Print what would be captured, and where, without writing anything")
+	captureCmd.Flags().Int64Var(&captureMaxSize, "max-size", 512, "With --stream, refuse input larger than this many MB")
+}
+
+// lockCapture takes the workspace lock for a capture's writes. Capture locks
+// once its content is final rather than for the whole command, so an editor
+// left open holds up no other command; the writes themselves read and
+// rewrite the destination, and must not interleave with a refile's.
+func lockCapture(ws *workspace.Workspace) (func(), error) {
+	return ws.Lock(planCommandLine(os.Args))
 }
 
-// refileContentToDestination performs refile operation for captured content
-func refileContentToDestination(ws *workspace.Workspace, content, destination, mode string) error {
+// templateDestination returns where a template captures to, with shell
+// commands in it expanded. When the file it names is missing and the
+// template gives a file_template or a dated destination, newFile holds the
+// content to create it with; otherwise newFile is empty.
+func templateDestination(ws *workspace.Workspace, tm *template.Manager, t *template.Template) (destination, newFile string, err error) {
+	destination, err = tm.ExpandDestination(t)
+	if err != nil {
+		return "", "", err
+	}
+	if destination == "" {
+		destination = "inbox.md"
+	}
+	if t.FileTemplate == "" && destination == t.DestinationFile {
+		return destination, "", nil
+	}
+
+	file := strings.SplitN(destination, "#", 2)[0]
+	if _, err := storage.Stat(captureFilePath(ws, file)); !os.IsNotExist(err) {
+		return destination, "", nil
+	}
+	newFile, err = newFileContent(ws, t.FileTemplate, titleFromFileName(file))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	return destination, newFile, nil
+}
+
+// captureFilePath resolves a capture destination file, which is relative to
+// the workspace root rather than lib/
+func captureFilePath(ws *workspace.Workspace, file string) string {
+	if file == "inbox.md" {
+		return ws.InboxPath
+	}
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return cmdutil.NewPathUtil(ws).WorkspaceJoin(file)
+}
+
+// captureToDestination saves captured content at a template's destination or
+// the one given with --to, which is a file or a selector. newFile, when not
+// empty, is the content to create a missing destination file with.
+func captureToDestination(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookManager *hooks.Manager, finalContent, destination, mode, newFile, source string, cursor *template.Position) error {
+	// Check if destination is a selector (contains #) or just a file
+	if strings.Contains(destination, "#") {
+		// Use selector-based refile logic
+		filePath := captureFilePath(ws, strings.SplitN(destination, "#", 2)[0])
+		before := []byte(newFile)
+		if newFile == "" {
+			before, _ = storage.ReadFile(filePath)
+		}
+		var snapshot *hooks.Snapshot
+		if !captureNoVerify {
+			snapshot = postHookSnapshot(hookManager, hooks.PostCapture, ws, filePath)
+		}
+		if err := refileContentToDestination(ws, finalContent, destination, mode, newFile); err != nil {
+			return ctx.HandleOperationError("refile", fmt.Errorf("failed to refile to destination '%s': %w", destination, err))
+		}
+		recordCapture(ws, filePath, before, finalContent, captureTemplate, destination)
+
+		if ctx.IsJSONOutput() {
+			templateInfo := captureTemplateInfo(finalContent, destination, mode)
+			lineCount := strings.Count(finalContent, "\n") + 1
+			if len(finalContent) == 0 {
+				lineCount = 0
+			}
+
+			response := CaptureResponse{
+				Operation: "capture_and_refile",
+				ContentInfo: CaptureContent{
+					Content:        finalContent,
+					CharacterCount: len(finalContent),
+					LineCount:      lineCount,
+					Source:         source,
+					Cursor:         cursor,
+				},
+				FileInfo: CaptureFile{
+					FilePath:    destination,
+					IsInbox:     false,
+					IsSelector:  true,
+					Destination: destination,
+					Defaults:    captureDefaults,
+				},
+				Template: templateInfo,
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		// Run post-capture hook for refile case
+		if !captureNoVerify {
+			hookCtx := &hooks.HookContext{
+				Type:         hooks.PostCapture,
+				Workspace:    ws,
+				Content:      finalContent,
+				TemplateName: captureTemplate,
+				SourceFile:   destination,
+				Diff:         snapshot.Diff(),
+				AllowBypass:  captureNoVerify,
+			}
+
+			_, err := hookManager.Execute(hookCtx)
+			if err != nil && !ctx.IsJSONOutput() {
+				cmdutil.ShowWarning("Warning: post-capture hook failed: %s", err.Error())
+			}
+		}
+
+		if captureTemplate == "" {
+			cmdutil.ShowSuccess("✓ Captured note and refiled to '%s'", destination)
+		} else {
+			cmdutil.ShowSuccess("✓ Captured '%s' and refiled to '%s'", captureTemplate, destination)
+		}
+	} else {
+		// Simple file destination
+		destinationPath := destination
+		pathUtil := cmdutil.NewPathUtil(ws)
+		if destination == "inbox.md" {
+			destinationPath = ws.InboxPath
+		} else if !filepath.IsAbs(destination) {
+			// Use workspace root for relative paths, not lib/ directory
+			destinationPath = pathUtil.WorkspaceJoin(destination)
+		}
+
+		if err := checkDestinationContent(ws, destinationPath); err != nil {
+			return ctx.HandleError(err)
+		}
+		if destinationPath != ws.InboxPath {
+			if err := checkProtectedFile(ws, destinationPath); err != nil {
+				return ctx.HandleError(err)
+			}
+		}
+		var snapshot *hooks.Snapshot
+		if !captureNoVerify {
+			snapshot = postHookSnapshot(hookManager, hooks.PostCapture, ws, destinationPath)
+		}
+		if newFile != "" {
+			if err := cmdutil.WriteFileContent(destinationPath, []byte(newFile)); err != nil {
+				return ctx.HandleOperationError("save", fmt.Errorf("failed to create %s: %w", destination, err))
+			}
+		}
+
+		existing, _ := storage.ReadFile(destinationPath)
+		if err := ws.AppendToFile(destinationPath, finalContent); err != nil {
+			return ctx.HandleOperationError("save", fmt.Errorf("failed to save note: %w", err))
+		}
+		recordCapture(ws, destinationPath, existing, finalContent, captureTemplate, destination)
+
+		if ctx.IsJSONOutput() {
+			templateInfo := captureTemplateInfo(finalContent, destination, mode)
+			lineCount := strings.Count(finalContent, "\n") + 1
+			if len(finalContent) == 0 {
+				lineCount = 0
+			}
+
+			response := CaptureResponse{
+				Operation: "capture_to_file",
+				ContentInfo: CaptureContent{
+					Content:        finalContent,
+					CharacterCount: len(finalContent),
+					LineCount:      lineCount,
+					Source:         source,
+					Cursor:         cursor,
+				},
+				FileInfo: CaptureFile{
+					FilePath:    destinationPath,
+					IsInbox:     destination == "inbox.md",
+					IsSelector:  false,
+					Destination: destination,
+					Cursor:      template.OffsetPosition(existing, cursor),
+					Defaults:    captureDefaults,
+				},
+				Template: templateInfo,
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		// Run post-capture hook for file destination case
+		if !captureNoVerify {
+			hookCtx := &hooks.HookContext{
+				Type:         hooks.PostCapture,
+				Workspace:    ws,
+				Content:      finalContent,
+				TemplateName: captureTemplate,
+				SourceFile:   destinationPath,
+				Diff:         snapshot.Diff(),
+				AllowBypass:  captureNoVerify,
+			}
+
+			_, err := hookManager.Execute(hookCtx)
+			if err != nil && !ctx.IsJSONOutput() {
+				cmdutil.ShowWarning("Warning: post-capture hook failed: %s", err.Error())
+			}
+		}
+
+		if captureTemplate == "" {
+			cmdutil.ShowSuccess("✓ Captured note to '%s'", destination)
+		} else {
+			cmdutil.ShowSuccess("✓ Captured '%s' to '%s'", captureTemplate, destination)
+		}
+	}
+
+	return nil
+}
+
+// captureTemplateInfo describes the template used, if any, for JSON output
+func captureTemplateInfo(content, destination, mode string) *CaptureTemplate {
+	if captureTemplate == "" {
+		return nil
+	}
+	return &CaptureTemplate{
+		Name:            captureTemplate,
+		RenderedContent: content,
+		DestinationFile: destination,
+		RefileMode:      mode,
+	}
+}
+
+// refileContentToDestination performs refile operation for captured content.
+// newFile, when not empty, is the content of a destination file that does
+// not exist yet; the file is created with the captured content in place.
+func refileContentToDestination(ws *workspace.Workspace, content, destination, mode, newFile string) error {
 	// Parse the destination
 	destPath, err := markdown.ParsePath(destination)
 	if err != nil {
@@ -432,6 +647,16 @@ func refileContentToDestination(ws *workspace.Workspace, content, destination, m
 		EndOffset:   len(tempContent),
 	}
 
+	if newFile != "" {
+		initial := []byte(newFile)
+		dest, err := resolveDestinationPath(markdown.ParseDocument(maskFrontmatter(initial)), initial, destPath, mode == "prepend")
+		if err != nil {
+			return fmt.Errorf("failed to resolve destination: %w", err)
+		}
+		transformedContent := TransformSubtreeLevel(capturedSubtree, dest.TargetLevel)
+		return cmdutil.WriteFileContent(captureFilePath(ws, destPath.File), insertCaptured(initial, dest, transformedContent))
+	}
+
 	// Use the existing refile functionality to resolve the destination
 	dest, err := ResolveDestination(ws, destPath, mode == "prepend")
 	if err != nil {
@@ -447,17 +672,16 @@ func refileContentToDestination(ws *workspace.Workspace, content, destination, m
 
 // performDirectInsertion inserts content directly into the destination file
 func performDirectInsertion(ws *workspace.Workspace, dest *DestinationTarget, transformedContent []byte) error {
+	if err := checkProtectedDestination(ws, dest); err != nil {
+		return err
+	}
+	if err := checkDestinationContent(ws, dest.File); err != nil {
+		return err
+	}
+
 	// Construct destination file path
 	pathUtil := cmdutil.NewPathUtil(ws)
-	var destFilePath string
-	if dest.File == "inbox.md" {
-		destFilePath = ws.InboxPath
-	} else if filepath.IsAbs(dest.File) {
-		destFilePath = dest.File
-	} else {
-		// Use workspace root for relative paths, not lib/ directory
-		destFilePath = pathUtil.WorkspaceJoin(dest.File)
-	}
+	destFilePath := captureFilePath(ws, dest.File)
 
 	// Read destination file using unified content utilities
 	destContent, err := cmdutil.ReadFileContent(destFilePath)
@@ -465,33 +689,49 @@ func performDirectInsertion(ws *workspace.Workspace, dest *DestinationTarget, tr
 		return err
 	}
 
+	// Write back to destination file
+	if err := pathUtil.SafeWriteFile(destFilePath, insertCaptured(destContent, dest, transformedContent)); err != nil {
+		return fmt.Errorf("failed to write destination file: %w", err)
+	}
+
+	return nil
+}
+
+// insertCaptured returns destContent with captured content inserted at
+// dest, under any headings that have to be created for it
+func insertCaptured(destContent []byte, dest *DestinationTarget, transformedContent []byte) []byte {
 	// Prepare content to insert
-	var insertContent []byte = transformedContent
+	insertContent := append(bytes.TrimRight(transformedContent, " \t\n"), '\n')
 
 	// Add missing headings if needed
 	if len(dest.CreatePath) > 0 {
 		// Calculate the base level for missing headings
 		baseLevel := dest.TargetLevel - len(dest.CreatePath)
 		pathContent := markdown.CreateHeadingStructure(dest.CreatePath, baseLevel)
-
-		// Ensure proper spacing
-		if dest.InsertOffset > 0 && destContent[dest.InsertOffset-1] != '\n' {
-			pathContent = append([]byte("\n"), pathContent...)
-		}
-
 		insertContent = append(pathContent, insertContent...)
 	}
 
-	// Insert at the specified offset
-	newDestContent := append(destContent[:dest.InsertOffset], insertContent...)
-	newDestContent = append(newDestContent, destContent[dest.InsertOffset:]...)
-
-	// Write back to destination file
-	if err := pathUtil.SafeWriteFile(destFilePath, newDestContent); err != nil {
-		return fmt.Errorf("failed to write destination file: %w", err)
+	// Keep a blank line on either side of the inserted content
+	offset := dest.InsertOffset
+	if offset > 0 && destContent[offset-1] != '\n' {
+		insertContent = append([]byte("\n\n"), insertContent...)
+	} else if offset > 1 && destContent[offset-2] != '\n' {
+		insertContent = append([]byte("\n"), insertContent...)
+	}
+	rest := bytes.TrimLeft(destContent[offset:], "\n")
+	if len(bytes.TrimSpace(rest)) == 0 {
+		rest = nil
+	} else {
+		insertContent = append(insertContent, '\n')
 	}
 
-	return nil
+	// Insert at the specified offset, into a fresh slice so the tail of
+	// destContent is not overwritten before it is copied
+	newDestContent := make([]byte, 0, len(destContent)+len(insertContent))
+	newDestContent = append(newDestContent, destContent[:offset]...)
+	newDestContent = append(newDestContent, insertContent...)
+	newDestContent = append(newDestContent, rest...)
+	return newDestContent
 }
 
 // JSON response structures for capture command
@@ -508,6 +748,8 @@ type CaptureContent struct {
 	CharacterCount int    `json:"character_count"`
 	LineCount      int    `json:"line_count"`
 	Source         string `json:"source"` // "editor", "stdin", "content_flag", "template"
+
+	Cursor *template.Position `json:"cursor,omitempty"` // Template cursor marker, within Content
 }
 
 type CaptureFile struct {
@@ -515,6 +757,10 @@ type CaptureFile struct {
 	IsInbox     bool   `json:"is_inbox"`
 	IsSelector  bool   `json:"is_selector"`
 	Destination string `json:"destination"`
+
+	Cursor *template.Position `json:"cursor,omitempty"` // Template cursor marker, within the destination file
+
+	Defaults *dirconfig.Capture `json:"directory_defaults,omitempty"` // Directory capture defaults applied with --to
 }
 
 type CaptureTemplate struct {
@@ -524,6 +770,62 @@ type CaptureTemplate struct {
 	RefileMode      string `json:"refile_mode,omitempty"`
 }
 
+// recordCapture adds a capture to the capture log. before is the content of
+// the file at filePath before the capture, which locates the inserted text
+// for 'capture --amend'. The log only feeds features such as template-based
+// refile rules and amend, so failures never fail the capture.
+func recordCapture(ws *workspace.Workspace, filePath string, before []byte, content, templateName, destination string) {
+	after, _ := storage.ReadFile(filePath)
+
+	_ = ws.RecordCapture(workspace.CaptureRecord{
+		Time:     time.Now(),
+		Heading:  firstHeading(content),
+		Template: templateName,
+		File:     destination,
+		Content:  insertedText(before, after),
+	})
+}
+
+// firstHeading returns the text of the first top-level heading in content
+func firstHeading(content string) string {
+	doc := markdown.ParseDocument([]byte(content))
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if h, ok := node.(*ast.Heading); ok {
+			return markdown.ExtractHeadingText(h, []byte(content))
+		}
+	}
+	return ""
+}
+
+// insertedText returns the whole lines that after has and before does not,
+// without surrounding blank lines. Captures insert one block, so everything
+// outside the common prefix and suffix is the capture as written.
+func insertedText(before, after []byte) string {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	// Widen to whole lines, so a heading that starts like the text around
+	// it is not cut short. Appends start where the old content ended.
+	start := prefix
+	if prefix < len(before) {
+		start = bytes.LastIndexByte(after[:prefix], '\n') + 1
+	}
+	suffix := 0
+	for suffix < len(before)-start && suffix < len(after)-start &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	end := len(after) - suffix
+	if i := bytes.IndexByte(after[end:], '\n'); i >= 0 && end > 0 && after[end-1] != '\n' {
+		end += i + 1
+	}
+	if start >= end {
+		return ""
+	}
+	return strings.TrimSpace(string(after[start:end]))
+}
+
 // getContentSource determines the source of content for JSON output
 func getContentSource(appendContent string, useEditor bool) string {
 	if appendContent != "" && !useEditor {
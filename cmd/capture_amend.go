@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/editor"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+var (
+	captureAmend    bool
+	captureShowLast bool
+)
+
+// lastCapture is the most recent capture and where it sits now
+type lastCapture struct {
+	record   *workspace.CaptureRecord
+	filePath string
+	content  []byte // The destination file
+	offset   int    // Where the captured text starts in content
+	text     string // The captured text, whole even when the record keeps an excerpt
+	line     int
+}
+
+// findLastCapture locates the most recent capture in its destination file.
+// It fails when the text was edited, refiled or archived since, as there is
+// then nothing certain to amend.
+func findLastCapture(ws *workspace.Workspace) (*lastCapture, error) {
+	record, err := ws.LastCapture()
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("nothing has been captured in this workspace yet")
+	}
+	if record.Content == "" {
+		return nil, fmt.Errorf("the last capture, to %s, was recorded without its text and cannot be found", record.File)
+	}
+
+	file := strings.SplitN(record.File, "#", 2)[0]
+	filePath := captureFilePath(ws, file)
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", file, err)
+	}
+	offset, size := record.LastIndex(content)
+	if offset < 0 {
+		return nil, fmt.Errorf("the last capture is no longer in %s as captured; it may have been edited, refiled or archived", file)
+	}
+	return &lastCapture{
+		record:   record,
+		filePath: filePath,
+		content:  content,
+		offset:   offset,
+		text:     string(content[offset : offset+size]),
+		line:     markdown.CalculateLineNumber(content, offset),
+	}, nil
+}
+
+// runCaptureLast handles --show-last and --amend
+func runCaptureLast(ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
+	last, err := findLastCapture(ws)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	file := strings.SplitN(last.record.File, "#", 2)[0]
+
+	if captureShowLast {
+		if ctx.IsJSONOutput() {
+			record := *last.record
+			record.Content, record.Size, record.Hash = last.text, 0, ""
+			return cmdutil.OutputJSON(CaptureLastResponse{
+				Operation: "show_last",
+				Capture:   record,
+				Line:      last.line,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+		fmt.Printf("Captured %s to %s, line %d\n\n", last.record.Time.Local().Format("2006-01-02 15:04"), last.record.File, last.line)
+		fmt.Println(last.text)
+		return nil
+	}
+
+	amended := strings.TrimSpace(captureContent)
+	if amended == "" {
+		if !ctx.IsJSONOutput() {
+			fmt.Println("Opening last capture in editor...")
+		}
+		edited, err := editor.OpenEditor(last.text + "\n")
+		if err != nil {
+			return ctx.HandleOperationError("editor", fmt.Errorf("failed to open editor: %w", err))
+		}
+		amended = strings.TrimSpace(edited)
+	}
+
+	changed := amended != "" && amended != last.text
+	if changed {
+		// The editor may have been open a while; hold the lock for the write
+		// and make sure nothing moved the capture in the meantime.
+		unlock, err := lockCapture(ws)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		defer unlock()
+		current, err := findLastCapture(ws)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if current.filePath != last.filePath || current.offset != last.offset || current.text != last.text {
+			return ctx.HandleError(fmt.Errorf("the last capture changed while it was being edited; run the amend again"))
+		}
+		last = current
+		end := last.offset + len(last.text)
+		if err := checkProtectedRange(ws, file, last.offset, end); err != nil {
+			return ctx.HandleError(err)
+		}
+		updated := make([]byte, 0, len(last.content)-len(last.text)+len(amended))
+		updated = append(updated, last.content[:last.offset]...)
+		updated = append(updated, amended...)
+		updated = append(updated, last.content[end:]...)
+		if err := cmdutil.WriteFileContent(last.filePath, updated); err != nil {
+			return ctx.HandleFileOperation("write", file, err)
+		}
+
+		record := *last.record
+		record.Heading = firstHeading(amended)
+		record.SetContent(amended)
+		record.Amended = true
+		_ = ws.RecordCapture(record)
+	}
+
+	if ctx.IsJSONOutput() {
+		record := *last.record
+		record.Content, record.Size, record.Hash = amended, 0, ""
+		return cmdutil.OutputJSON(CaptureLastResponse{
+			Operation: "amend",
+			Capture:   record,
+			Line:      last.line,
+			Changed:   changed,
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	switch {
+	case amended == "":
+		fmt.Println("Empty note. Last capture left unchanged.")
+	case !changed:
+		fmt.Println("No changes. Last capture left unchanged.")
+	default:
+		cmdutil.ShowSuccess("✓ Amended last capture in %s (line %d)", file, last.line)
+	}
+	return nil
+}
+
+// CaptureLastResponse represents the JSON response for capture --show-last and --amend
+type CaptureLastResponse struct {
+	Operation string                  `json:"operation"`
+	Capture   workspace.CaptureRecord `json:"capture"`
+	Line      int                     `json:"line"` // Where the capture starts in its file
+	Changed   bool                    `json:"changed"`
+	Metadata  cmdutil.JSONMetadata    `json:"metadata"`
+}
+
+func init() {
+	captureCmd.Flags().BoolVar(&captureAmend, "amend", false, "Reopen the last captured note in the editor to correct it")
+	captureCmd.Flags().BoolVar(&captureShowLast, "show-last", false, "Print the last captured note and where it went")
+	captureCmd.MarkFlagsMutuallyExclusive("amend", "show-last")
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// capturePreview holds the --preview flag
+var capturePreview bool
+
+// CapturePreviewResponse is the JSON response for 'capture --preview'
+type CapturePreviewResponse struct {
+	Operation       string               `json:"operation"`
+	Template        string               `json:"template,omitempty"`
+	Destination     string               `json:"destination"`
+	FilePath        string               `json:"file_path"`
+	Mode            string               `json:"mode,omitempty"`
+	CreatesFile     bool                 `json:"creates_file"`
+	CreatesHeadings []string             `json:"creates_headings,omitempty"`
+	Line            int                  `json:"line"`    // Where the written text would start
+	Content         string               `json:"content"` // The text as it would be written
+	Cursor          *template.Position   `json:"cursor,omitempty"`
+	Metadata        cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// previewCapture prints what capturing content at destination would write,
+// without writing it. The destination is resolved, and the protection and
+// content guards checked, as for a real capture; inbox is set for a plain
+// capture, which appends to the inbox as it is.
+func previewCapture(ctx *cmdutil.CommandContext, ws *workspace.Workspace, content, destination, mode, newFile string, inbox bool, cursor *template.Position) error {
+	preview, err := captureWrite(ws, content, destination, mode, newFile, inbox)
+	if err != nil {
+		return ctx.HandleOperationError("preview", err)
+	}
+	preview.Template = captureTemplate
+	preview.Cursor = cursor
+
+	if ctx.IsJSONOutput() {
+		preview.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(preview)
+	}
+
+	if captureTemplate != "" {
+		fmt.Printf("Preview of template '%s'; nothing was written.\n\n", captureTemplate)
+	} else {
+		fmt.Print("Preview of capture; nothing was written.\n\n")
+	}
+	fmt.Printf("Destination: %s\n", preview.Destination)
+	file := preview.FilePath
+	if preview.CreatesFile {
+		file += " (new file)"
+	}
+	fmt.Printf("File:        %s\n", file)
+	if preview.Mode != "" {
+		fmt.Printf("Mode:        %s\n", preview.Mode)
+	}
+	if len(preview.CreatesHeadings) > 0 {
+		fmt.Printf("Creates:     %s\n", strings.Join(preview.CreatesHeadings, " / "))
+	}
+	fmt.Printf("Line:        %d\n", preview.Line)
+	fmt.Println()
+	fmt.Println(preview.Content)
+	return nil
+}
+
+// captureWrite works out, in memory, what a capture of content at
+// destination would write, the way captureToDestination and the inbox
+// append write it
+func captureWrite(ws *workspace.Workspace, content, destination, mode, newFile string, inbox bool) (*CapturePreviewResponse, error) {
+	file, _, _ := strings.Cut(destination, "#")
+	filePath := captureFilePath(ws, file)
+	preview := &CapturePreviewResponse{
+		Operation:   "capture_preview",
+		Destination: destination,
+		FilePath:    filePath,
+		Mode:        mode,
+	}
+
+	before, creates, err := captureBefore(ws, destination, newFile)
+	if err != nil {
+		return nil, err
+	}
+	after, headings, err := insertCapture(ws, before, content, destination, mode, creates, inbox)
+	if err != nil {
+		return nil, err
+	}
+	preview.CreatesFile = creates
+	preview.CreatesHeadings = headings
+	preview.Content, preview.Line = insertedAt(before, after)
+	return preview, nil
+}
+
+// captureBefore returns the content of a capture's destination file before
+// the capture, and whether the capture creates it: from newFile, or empty
+// for a file that appending creates
+func captureBefore(ws *workspace.Workspace, destination, newFile string) ([]byte, bool, error) {
+	if newFile != "" {
+		return []byte(newFile), true, nil
+	}
+	file, _, isSelector := strings.Cut(destination, "#")
+	filePath := captureFilePath(ws, file)
+	content, err := storage.ReadFile(filePath)
+	switch {
+	case os.IsNotExist(err) && !isSelector:
+		return nil, true, nil
+	case os.IsNotExist(err):
+		return nil, false, fmt.Errorf("destination %w: %s", cmdutil.ErrFileNotFound, file)
+	case err != nil:
+		return nil, false, cmdutil.NewFileError("read", file, err)
+	}
+	if err := checkDestinationContent(ws, filePath); err != nil {
+		return nil, false, err
+	}
+	return content, false, nil
+}
+
+// insertCapture returns before with content captured at destination, and
+// the headings created for it. Protection is checked unless the capture
+// creates the file.
+func insertCapture(ws *workspace.Workspace, before []byte, content, destination, mode string, creates, inbox bool) ([]byte, []string, error) {
+	if !strings.Contains(destination, "#") {
+		after := append([]byte{}, before...)
+		if inbox {
+			return append(after, content...), nil, nil
+		}
+		if !creates {
+			if err := checkProtectedFile(ws, destination); err != nil {
+				return nil, nil, err
+			}
+		}
+		return append(after, content+"\n\n"...), nil, nil
+	}
+
+	destPath, err := markdown.ParsePath(destination)
+	if err != nil {
+		return nil, nil, cmdutil.NewValidationError("destination", destination, err)
+	}
+	dest, err := resolveDestinationPath(markdown.ParseDocument(maskFrontmatter(before)), before, destPath, mode == "prepend")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve destination: %w", err)
+	}
+	if !creates {
+		if err := checkProtectedDestination(ws, dest); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Captured content goes in as a subtree, as in refileContentToDestination
+	wrapped := "# Captured Content\n\n" + content
+	transformed := TransformSubtreeLevel(&markdown.Subtree{
+		Heading:   "Captured Content",
+		Level:     1,
+		Content:   []byte(wrapped),
+		EndOffset: len(wrapped),
+	}, dest.TargetLevel)
+	return insertCaptured(before, dest, transformed), dest.CreatePath, nil
+}
+
+// insertedAt returns the text a capture added to a file and the line it
+// starts on
+func insertedAt(before, after []byte) (string, int) {
+	text := insertedText(before, after)
+	// The written text starts after the content the file keeps in front of it
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	start := bytes.LastIndexByte(after[:prefix], '\n') + 1
+	if i := bytes.Index(after[start:], []byte(text)); i >= 0 {
+		start += i
+	}
+	return text, markdown.CalculateLineNumber(after, start)
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/journal"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// CaptureSplitResponse is the JSON response for a capture, or a preview of
+// one, with a split template
+type CaptureSplitResponse struct {
+	Operation string                `json:"operation"` // capture_split or capture_preview
+	Template  string                `json:"template"`
+	Sections  []CaptureSplitSection `json:"sections"`
+	Metadata  cmdutil.JSONMetadata  `json:"metadata"`
+}
+
+// CaptureSplitSection is where one section of a split capture was written.
+// The main section has no name.
+type CaptureSplitSection struct {
+	Section         string   `json:"section,omitempty"`
+	Destination     string   `json:"destination"`
+	FilePath        string   `json:"file_path"`
+	Mode            string   `json:"mode,omitempty"`
+	CreatesFile     bool     `json:"creates_file"`
+	CreatesHeadings []string `json:"creates_headings,omitempty"`
+	Line            int      `json:"line"`
+	Content         string   `json:"content"`
+}
+
+// captureSplit captures the sections of content split by a split template's
+// markers, each at its own destination. The main section goes where the
+// template, or --to, says; the others are appended at theirs. All of the
+// files are written together, or none of them.
+func captureSplit(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookManager *hooks.Manager, tm *template.Manager, t *template.Template, content, destination, mode, newFile string) error {
+	sections, err := tm.Split(t, content)
+	if err != nil {
+		return ctx.HandleOperationError("template", err)
+	}
+	if len(sections) == 0 {
+		return ctx.HandleError(fmt.Errorf("nothing to capture; every section of template '%s' is empty", t.Name))
+	}
+
+	planned, writes, err := planSplitCapture(ws, sections, destination, mode, newFile)
+	if err != nil {
+		if capturePreview {
+			return ctx.HandleOperationError("preview", err)
+		}
+		return ctx.HandleOperationError("capture", err)
+	}
+
+	response := CaptureSplitResponse{Operation: "capture_split", Template: t.Name, Sections: planned}
+	if capturePreview {
+		response.Operation = "capture_preview"
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+		fmt.Printf("Preview of template '%s'; nothing was written.\n", t.Name)
+		for _, section := range planned {
+			// The note itself is printed as written, symbols and all
+			cmdutil.Printf("\n%s → %s (line %d)\n\n", sectionName(section), section.Destination, section.Line)
+			fmt.Println(section.Content)
+		}
+		return nil
+	}
+
+	var snapshot *hooks.Snapshot
+	if !captureNoVerify {
+		paths := make([]string, 0, len(writes))
+		for _, write := range writes {
+			paths = append(paths, write.Path)
+		}
+		snapshot = postHookSnapshot(hookManager, hooks.PostCapture, ws, paths...)
+	}
+	if err := journal.Apply(writes, nil); err != nil {
+		return ctx.HandleOperationError("save", fmt.Errorf("failed to write split capture: %w", err))
+	}
+	for i, section := range planned {
+		_ = ws.RecordCapture(workspace.CaptureRecord{
+			Time:     time.Now(),
+			Heading:  firstHeading(sections[i].Content),
+			Template: t.Name,
+			File:     section.Destination,
+			Content:  section.Content,
+		})
+	}
+
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+
+	if !captureNoVerify {
+		hookCtx := &hooks.HookContext{
+			Type:         hooks.PostCapture,
+			Workspace:    ws,
+			Content:      content,
+			TemplateName: t.Name,
+			SourceFile:   destination,
+			Diff:         snapshot.Diff(),
+			AllowBypass:  captureNoVerify,
+		}
+		if _, err := hookManager.Execute(hookCtx); err != nil {
+			cmdutil.ShowWarning("Warning: post-capture hook failed: %s", err.Error())
+		}
+	}
+
+	cmdutil.ShowSuccess("✓ Captured '%s' to %d destination%s", t.Name, len(planned), pluralize(len(planned)))
+	for _, section := range planned {
+		cmdutil.Printf("  %s → %s (line %d)\n", sectionName(section), section.Destination, section.Line)
+	}
+	return nil
+}
+
+// planSplitCapture works out, in memory, what capturing each section writes,
+// and returns the file writes in the order the files were first named.
+// Sections for the same file are inserted one after another.
+func planSplitCapture(ws *workspace.Workspace, sections []template.Section, destination, mode, newFile string) ([]CaptureSplitSection, []journal.Write, error) {
+	contents := map[string][]byte{}
+	created := map[string]bool{}
+	var order []string
+	planned := make([]CaptureSplitSection, 0, len(sections))
+
+	for _, section := range sections {
+		sectionDestination, sectionMode, sectionFile := destination, mode, newFile
+		if section.Name != "" {
+			sectionDestination, sectionMode, sectionFile = section.Destination, "append", ""
+		}
+		file, _, _ := strings.Cut(sectionDestination, "#")
+		filePath := captureFilePath(ws, file)
+
+		before, seen := contents[filePath]
+		if !seen {
+			var creates bool
+			var err error
+			before, creates, err = captureBefore(ws, sectionDestination, sectionFile)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", sectionDestination, err)
+			}
+			created[filePath] = creates
+			order = append(order, filePath)
+		}
+
+		after, headings, err := insertCapture(ws, before, section.Content, sectionDestination, sectionMode, created[filePath], false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", sectionDestination, err)
+		}
+		contents[filePath] = after
+
+		text, line := insertedAt(before, after)
+		planned = append(planned, CaptureSplitSection{
+			Section:         section.Name,
+			Destination:     sectionDestination,
+			FilePath:        filePath,
+			Mode:            sectionMode,
+			CreatesFile:     created[filePath] && !seen,
+			CreatesHeadings: headings,
+			Line:            line,
+			Content:         text,
+		})
+	}
+
+	writes := make([]journal.Write, 0, len(order))
+	for _, filePath := range order {
+		writes = append(writes, journal.Write{Path: filePath, Content: contents[filePath]})
+	}
+	return planned, writes, nil
+}
+
+// sectionName names a split section for text output
+func sectionName(section CaptureSplitSection) string {
+	if section.Section == "" {
+		return "main"
+	}
+	return section.Section
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+var (
+	captureStream  bool
+	captureMaxSize int64
+)
+
+// streamProgressStep is how much input passes between progress reports
+const streamProgressStep = 1 << 20
+
+// streamCapture appends piped input to the inbox through a temporary file,
+// so capturing large logs or transcripts never holds them in memory
+func streamCapture(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookManager *hooks.Manager) error {
+	if isTerminal(os.Stdin) {
+		return ctx.HandleUsage(fmt.Errorf("--stream captures piped input; pipe the content to capture"))
+	}
+	if captureTemplate != "" || captureContent != "" || captureNote != "" || captureTo != "" {
+		return ctx.HandleUsage(fmt.Errorf("--stream cannot be combined with a template, --content, --note or --to"))
+	}
+	if captureMaxSize < 1 {
+		return ctx.HandleValidation("max-size", fmt.Sprint(captureMaxSize), fmt.Errorf("--max-size must be at least 1 MB"))
+	}
+	if !captureNoVerify {
+		if active, err := hookManager.Active(hooks.PreCapture); err == nil && len(active) > 0 {
+			return ctx.HandleError(fmt.Errorf("pre-capture hooks need the whole note, so they cannot run on a stream; pass --no-verify to skip them"))
+		}
+	}
+
+	tempFile, err := os.CreateTemp("", "jot-capture-*.md")
+	if err != nil {
+		return ctx.HandleOperationError("temp file", fmt.Errorf("failed to create temp file: %w", err))
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	limit := captureMaxSize << 20
+	counter := &streamCounter{w: tempFile, progress: !ctx.IsJSONOutput() && isTerminal(os.Stderr)}
+	n, err := io.Copy(counter, io.LimitReader(os.Stdin, limit+1))
+	counter.finish()
+	if err != nil {
+		return ctx.HandleOperationError("read stdin", fmt.Errorf("failed to read from stdin: %w", err))
+	}
+	if n > limit {
+		return ctx.HandleError(fmt.Errorf("input is larger than %d MB; nothing was captured (raise --max-size to capture it)", captureMaxSize))
+	}
+	if cmdutil.LooksBinary(counter.head) {
+		if err := allowContent(&cmdutil.ContentError{Kind: cmdutil.ContentBinary, Size: n}); err != nil {
+			return ctx.HandleError(err)
+		}
+	}
+	if len(bytes.TrimSpace(counter.head)) == 0 && n <= int64(len(counter.head)) {
+		if ctx.IsJSONOutput() {
+			return ctx.Response.RespondWithSuccess(map[string]interface{}{
+				"operation":    "capture_empty",
+				"content_info": CaptureContent{Source: "stdin_stream"},
+				"file_info":    CaptureFile{FilePath: ws.InboxPath, IsInbox: true, Destination: "inbox.md"},
+			})
+		}
+		fmt.Println("No content captured. Note not saved.")
+		return nil
+	}
+	if counter.last != '\n' {
+		if _, err := tempFile.WriteString("\n"); err != nil {
+			return ctx.HandleOperationError("temp file", err)
+		}
+		n++
+		counter.lines++
+	}
+
+	// The capture log keeps the text without its final newline, as it does
+	// for other captures; long text is kept as an excerpt and its hash
+	record := workspace.CaptureRecord{Time: time.Now(), Heading: firstHeading(string(counter.head)), File: "inbox.md"}
+	if n <= int64(len(counter.head)) {
+		record.SetContent(strings.TrimSpace(string(counter.head)))
+	} else {
+		if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+			return ctx.HandleOperationError("temp file", err)
+		}
+		sum := sha256.New()
+		if _, err := io.CopyN(sum, tempFile, n-1); err != nil {
+			return ctx.HandleOperationError("temp file", err)
+		}
+		record.SetExcerpt(counter.head, int(n-1), sum.Sum(nil))
+	}
+
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return ctx.HandleOperationError("temp file", err)
+	}
+	unlock, err := lockCapture(ws)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	defer unlock()
+	separator, err := streamSeparator(ws.InboxPath)
+	if err != nil {
+		return ctx.HandleOperationError("read inbox", err)
+	}
+	if _, err := dryrun.AppendFrom(ws.InboxPath, io.MultiReader(strings.NewReader(separator), tempFile), 0644); err != nil {
+		return ctx.HandleOperationError("save", fmt.Errorf("failed to save note: %w", err))
+	}
+	_ = ws.RecordCapture(record)
+
+	if !captureNoVerify {
+		hookCtx := &hooks.HookContext{
+			Type:       hooks.PostCapture,
+			Workspace:  ws,
+			SourceFile: ws.InboxPath,
+		}
+		if _, err := hookManager.Execute(hookCtx); err != nil && !ctx.IsJSONOutput() {
+			cmdutil.ShowWarning("Warning: post-capture hook failed: %s", err.Error())
+		}
+	}
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(CaptureResponse{
+			Operation: "capture_stream",
+			ContentInfo: CaptureContent{
+				CharacterCount: int(n),
+				LineCount:      counter.lines,
+				Source:         "stdin_stream",
+			},
+			FileInfo: CaptureFile{
+				FilePath:    ws.InboxPath,
+				IsInbox:     true,
+				Destination: "inbox.md",
+			},
+			Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	cmdutil.ShowSuccess("✓ Streamed %s (%d line%s) into %s", formatStreamSize(n), counter.lines, pluralize(counter.lines), ws.InboxPath)
+	showInboxAgingHint(ws)
+	return nil
+}
+
+// streamSeparator returns what keeps a blank line between the end of the
+// file at path and text appended to it
+func streamSeparator(path string) (string, error) {
+	content, err := storage.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read inbox: %w", err)
+	}
+	switch {
+	case len(content) == 0 || bytes.HasSuffix(content, []byte("\n\n")):
+		return "", nil
+	case content[len(content)-1] == '\n':
+		return "\n", nil
+	}
+	return "\n\n", nil
+}
+
+// streamCounter passes input through to w, counting lines and keeping the
+// first bytes, for binary detection and the capture log, and the last one; it reports progress
+// on stderr
+type streamCounter struct {
+	w        io.Writer
+	progress bool
+	total    int64
+	reported int64
+	lines    int
+	head     []byte
+	last     byte
+}
+
+func (c *streamCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	written := p[:n]
+	if len(written) > 0 {
+		c.lines += bytes.Count(written, []byte("\n"))
+		c.last = written[len(written)-1]
+		if room := workspace.CaptureExcerptSize - len(c.head); room > 0 {
+			c.head = append(c.head, written[:min(room, len(written))]...)
+		}
+	}
+	c.total += int64(n)
+	if c.progress && c.total-c.reported >= streamProgressStep {
+		c.reported = c.total
+		fmt.Fprintf(os.Stderr, "\rReading input: %s", formatStreamSize(c.total))
+	}
+	return n, err
+}
+
+// finish clears the progress line, if one was shown
+func (c *streamCounter) finish() {
+	if c.reported > 0 {
+		fmt.Fprintf(os.Stderr, "\r%s\r", strings.Repeat(" ", 40))
+	}
+}
+
+// formatStreamSize describes a byte count in the largest whole unit
+func formatStreamSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
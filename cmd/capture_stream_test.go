@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStreamSeparator(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		content, want string
+	}{
+		{"", ""},
+		{"# Inbox\n\n", ""},
+		{"# Inbox\n", "\n"},
+		{"# Inbox\n\nlast line", "\n\n"},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "inbox.md")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := streamSeparator(path)
+		if err != nil || got != tt.want {
+			t.Errorf("%d: streamSeparator(%q) = %q, %v; want %q", i, tt.content, got, err, tt.want)
+		}
+	}
+	if got, err := streamSeparator(filepath.Join(dir, "missing.md")); err != nil || got != "" {
+		t.Errorf("missing inbox gave %q, %v", got, err)
+	}
+}
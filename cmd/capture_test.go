@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/spf13/cobra"
+)
+
+func TestInsertedText(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"append", "# Inbox\n", "# Inbox\n\n## Note\ntext\n", "## Note\ntext"},
+		{"empty file", "", "## Note\n", "## Note"},
+		{"insert before similar heading", "# Work\n\n## B\n", "# Work\n\n## A\n\n## B\n", "## A"},
+		{"insert after similar line", "# Work\n\n## A\nx\n", "# Work\n\n## A\nx\n## A\nx\n", "## A\nx"},
+		{"unchanged", "# Inbox\n", "# Inbox\n", ""},
+		{"append without newline", "# Inbox\nlast", "# Inbox\nlast\n\n## Note\n", "## Note"},
+	}
+	for _, tt := range tests {
+		if got := insertedText([]byte(tt.before), []byte(tt.after)); got != tt.want {
+			t.Errorf("%s: insertedText = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCaptureWrite(t *testing.T) {
+	ws := fsckWorkspace(t)
+	if err := os.WriteFile(ws.InboxPath, []byte("# Inbox\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	work := filepath.Join(ws.Root, "work.md")
+	before, err := os.ReadFile(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		newFile     string
+		inbox       bool
+		content     string
+		line        int
+		creates     []string
+		createsFile bool
+	}{
+		{name: "inbox", destination: "inbox.md", inbox: true, content: "quick", line: 3},
+		{name: "file", destination: "work.md", content: "quick", line: 6},
+		{name: "heading", destination: "work.md#Work/Notes", content: "### Captured Content\n\nquick", line: 7},
+		{name: "new heading", destination: "work.md#Work/Meetings", content: "## Meetings\n### Captured Content\n\nquick", line: 7, creates: []string{"Meetings"}},
+		{name: "new file", destination: "lib/new.md", content: "quick", line: 1, createsFile: true},
+		{name: "new file from template", destination: "log.md#Log", newFile: "# Log\n", content: "## Captured Content\n\nquick", line: 3, createsFile: true},
+	}
+	for _, tt := range tests {
+		preview, err := captureWrite(ws, "quick", tt.destination, "append", tt.newFile, tt.inbox)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if preview.Content != tt.content || preview.Line != tt.line || preview.CreatesFile != tt.createsFile ||
+			len(preview.CreatesHeadings) != len(tt.creates) {
+			t.Errorf("%s: got content %q at line %d, creates %v (file %v)", tt.name, preview.Content, preview.Line, preview.CreatesHeadings, preview.CreatesFile)
+		}
+	}
+
+	if _, err := captureWrite(ws, "quick", "missing.md#Log", "append", "", false); err == nil {
+		t.Error("expected an error for a missing destination file")
+	}
+
+	// A preview writes nothing
+	after, err := os.ReadFile(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Error("preview changed work.md")
+	}
+	if _, err := os.Stat(filepath.Join(ws.Root, "lib", "new.md")); !os.IsNotExist(err) {
+		t.Error("preview created lib/new.md")
+	}
+}
+
+func TestPlanSplitCapture(t *testing.T) {
+	ws := fsckWorkspace(t)
+	sections := []template.Section{
+		{Content: "## Standup\n\nNotes"},
+		{Name: "actions", Destination: "work.md#Work/Notes", Content: "- [ ] Ship it"},
+		{Name: "decisions", Destination: "decisions.md", Content: "- Use Go"},
+	}
+	planned, writes, err := planSplitCapture(ws, sections, "work.md#Work/Design review", "append", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planned) != 3 {
+		t.Fatalf("got %d sections, want 3", len(planned))
+	}
+	// Both sections for work.md are in the one write
+	if len(writes) != 2 || writes[0].Path != filepath.Join(ws.Root, "work.md") {
+		t.Fatalf("got writes %+v", writes)
+	}
+	want := "# Work\n\n## Design review\n\n### Captured Content\n\n#### Standup\n\nNotes\n\n## Notes\n\n### Captured Content\n\n- [ ] Ship it\n"
+	if string(writes[0].Content) != want {
+		t.Errorf("got work.md\n%s", writes[0].Content)
+	}
+	if !planned[2].CreatesFile || planned[2].Line != 1 || string(writes[1].Content) != "- Use Go\n\n" {
+		t.Errorf("got decisions %+v, content %q", planned[2], writes[1].Content)
+	}
+
+	// A missing selector destination fails the whole capture
+	sections[2].Destination = "missing.md#Log"
+	if _, _, err := planSplitCapture(ws, sections, "work.md", "append", ""); err == nil {
+		t.Error("expected an error for a missing destination file")
+	}
+}
+
+func TestCaptureProtectedFile(t *testing.T) {
+	ws := fsckWorkspace(t)
+	ws.Config.Protected = []string{"decisions.md"}
+	decisions := filepath.Join(ws.Root, "decisions.md")
+	if err := os.WriteFile(decisions, []byte("# Decisions\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	capture := func(destination string) error {
+		ctx := cmdutil.StartCommand(&cobra.Command{})
+		return captureToDestination(ctx, ws, hooks.NewManager(ws), "x", destination, "append", "", "content", nil)
+	}
+
+	for _, destination := range []string{"decisions.md", "decisions.md#Decisions"} {
+		if err := capture(destination); err == nil {
+			t.Errorf("capture to %s: expected a protected file error", destination)
+		}
+		if _, err := captureWrite(ws, "x", destination, "append", "", false); err == nil {
+			t.Errorf("preview of %s: expected a protected file error", destination)
+		}
+	}
+	if _, _, err := planSplitCapture(ws, []template.Section{{Content: "x"}}, "decisions.md", "append", ""); err == nil {
+		t.Error("split capture: expected a protected file error")
+	}
+	if got, _ := os.ReadFile(decisions); string(got) != "# Decisions\n" {
+		t.Errorf("decisions.md = %q, want it unchanged", got)
+	}
+
+	forceProtected = true
+	defer func() { forceProtected = false }()
+	if err := capture("decisions.md"); err != nil {
+		t.Fatalf("capture with --force: %v", err)
+	}
+	if got, _ := os.ReadFile(decisions); string(got) == "# Decisions\n" {
+		t.Error("capture with --force left decisions.md unchanged")
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/dirconfig"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+var (
+	captureTo       string
+	captureDefaults *dirconfig.Capture // Directory defaults found for --to
+)
+
+// captureTarget is where 'capture --to' saves a note, with the defaults of
+// the directory it is in
+type captureTarget struct {
+	File     string             // Destination file, relative to the workspace root
+	Selector string             // Heading path within File, empty for the end of the file
+	Mode     string             // Refile mode from the directory, empty when unset
+	Defaults *dirconfig.Capture // Nil when no directory sets defaults
+}
+
+// resolveCaptureTo parses a --to destination and looks up its directory's
+// capture defaults. It returns nil when to is empty.
+func resolveCaptureTo(ws *workspace.Workspace, to string) (*captureTarget, error) {
+	if to == "" {
+		return nil, nil
+	}
+	file, selector, _ := strings.Cut(to, "#")
+	if file == "" {
+		return nil, fmt.Errorf("destination must name a file, such as lib/projects/x.md#log")
+	}
+	if selector != "" {
+		if _, err := markdown.ParsePath(to); err != nil {
+			return nil, err
+		}
+	}
+
+	defaults, err := dirconfig.Lookup(ws.Root, captureFilePath(ws, file))
+	if err != nil {
+		return nil, err
+	}
+	target := &captureTarget{File: file, Selector: selector, Defaults: defaults}
+	if defaults != nil {
+		target.Mode = defaults.RefileMode
+		if selector == "" {
+			target.Selector = strings.TrimLeft(defaults.Heading, "#")
+		}
+	}
+	return target, nil
+}
+
+// destination returns the destination for captureToDestination. When the
+// file is missing, newFile holds the content to create it with, from
+// fileTemplate or a single heading.
+func (t *captureTarget) destination(ws *workspace.Workspace, fileTemplate string) (destination, newFile string, err error) {
+	destination = t.File
+	if t.Selector != "" {
+		destination += "#" + t.Selector
+	}
+	if _, err := storage.Stat(captureFilePath(ws, t.File)); !os.IsNotExist(err) {
+		return destination, "", nil
+	}
+	newFile, err = newFileContent(ws, fileTemplate, titleFromFileName(t.File))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", t.File, err)
+	}
+	return destination, newFile, nil
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// completionBudget bounds how long selector completion spends refreshing
+// the heading cache; files it cannot get to are completed from stale entries
+const completionBudget = 50 * time.Millisecond
+
+// headingCacheVersion changes when the cache format does, discarding old caches
+const headingCacheVersion = 1
+
+// registerSelectorCompletion completes selectors for the arguments and
+// selector flags of commands that take them, through cobra's __complete
+// protocol and the completion scripts built on it
+func registerSelectorCompletion() {
+	selectorCommands := []*cobra.Command{
+		refileCmd, promoteCmd, demoteCmd, archiveCmd, peekCmd, exportCmd, proofCmd,
+		relatedCmd, diffCmd, selectorCheckCmd, suggestRefileCmd, aliasAddCmd, cpCmd,
+	}
+	for _, cmd := range selectorCommands {
+		if cmd == aliasAddCmd {
+			cmd.ValidArgsFunction = completeAliasSelector
+		} else {
+			cmd.ValidArgsFunction = completeSelectorArgs
+		}
+	}
+	for _, cmd := range append(selectorCommands, importCSVCmd) {
+		for _, name := range selectorFlags {
+			if cmd.Flags().Lookup(name) != nil {
+				_ = cmd.RegisterFlagCompletionFunc(name, completeSelectorArgs)
+			}
+		}
+	}
+}
+
+// completeAliasSelector completes the selector of 'jot alias add NAME SELECTOR'
+func completeAliasSelector(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSelectorArgs(cmd, args, toComplete)
+}
+
+// completeSelectorArgs completes aliases after "@", file names until the
+// word contains '#', then full-path heading selectors in that file
+func completeSelectorArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return completeSelector(ws, toComplete, time.Now().Add(completionBudget))
+}
+
+// completeSelector returns completions for a partial selector. Headings
+// whose selector starts with the word come first; when none do, headings
+// whose path contains the rest of the word are offered instead.
+func completeSelector(ws *workspace.Workspace, toComplete string, deadline time.Time) ([]string, cobra.ShellCompDirective) {
+	if strings.HasPrefix(toComplete, workspace.AliasPrefix) {
+		var completions []string
+		for _, name := range ws.AliasNames() {
+			alias := workspace.AliasPrefix + name
+			if strings.HasPrefix(alias, toComplete) {
+				completions = append(completions, alias+"\t"+ws.Aliases()[name])
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	file, query, hasHash := strings.Cut(toComplete, "#")
+	if !hasHash {
+		files, err := scanWorkspaceMarkdownFiles(ws)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		var completions []string
+		for _, f := range files {
+			f = filepath.ToSlash(f)
+			if markdown.HasPrefixFold(f, toComplete) {
+				completions = append(completions, f+"#")
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cache := loadHeadingCache(ws)
+	headings := cache.headings(ws, file, deadline)
+	cache.save()
+
+	var prefixed, containing []string
+	query = strings.TrimLeft(query, "/")
+	for _, heading := range headings {
+		completion := fmt.Sprintf("%s\tlevel %d, line %d", heading.Selector, heading.Level, heading.Line)
+		path := strings.TrimPrefix(heading.Selector, file+"#")
+		switch {
+		case markdown.HasPrefixFold(path, query):
+			prefixed = append(prefixed, completion)
+		case markdown.ContainsFold(path, query):
+			containing = append(containing, completion)
+		}
+	}
+	if len(prefixed) > 0 {
+		return prefixed, cobra.ShellCompDirectiveNoFileComp
+	}
+	return containing, cobra.ShellCompDirectiveNoFileComp
+}
+
+// headingCache keeps each file's headings in .jot/cache/headings.json, keyed
+// by modification time and size, so completion only parses changed files
+type headingCache struct {
+	Version int                          `json:"version"`
+	Files   map[string]*headingCacheFile `json:"files"`
+
+	path  string
+	dirty bool
+}
+
+type headingCacheFile struct {
+	ModTime  time.Time    `json:"mod_time"`
+	Size     int64        `json:"size"`
+	Headings []TOCHeading `json:"headings"`
+}
+
+// loadHeadingCache reads the workspace's heading cache, starting an empty
+// one when it is missing, unreadable or from another version
+func loadHeadingCache(ws *workspace.Workspace) *headingCache {
+	cache := &headingCache{path: filepath.Join(ws.JotDir, "cache", "headings.json")}
+	if data, err := os.ReadFile(cache.path); err == nil {
+		_ = json.Unmarshal(data, cache)
+	}
+	if cache.Version != headingCacheVersion || cache.Files == nil {
+		cache.Version = headingCacheVersion
+		cache.Files = make(map[string]*headingCacheFile)
+	}
+	return cache
+}
+
+// headings returns a file's headings, parsing the file again if it changed
+// since it was cached. After the deadline, a stale entry is returned as is.
+func (c *headingCache) headings(ws *workspace.Workspace, file string, deadline time.Time) []TOCHeading {
+	entry, cached := c.Files[file]
+	info, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		if cached {
+			delete(c.Files, file)
+			c.dirty = true
+		}
+		return nil
+	}
+	if cached && (entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() || time.Now().After(deadline)) {
+		return entry.Headings
+	}
+
+	headings, err := fileTOC(ws, file)
+	if err != nil {
+		return nil
+	}
+	c.Files[file] = &headingCacheFile{ModTime: info.ModTime(), Size: info.Size(), Headings: headings}
+	c.dirty = true
+	return headings
+}
+
+// forgetHeadings drops a file's cached headings, so completion parses it
+// again even if an old entry matches its modification time and size
+func forgetHeadings(ws *workspace.Workspace, file string) {
+	cache := loadHeadingCache(ws)
+	if _, cached := cache.Files[file]; cached {
+		delete(cache.Files, file)
+		cache.dirty = true
+		cache.save()
+	}
+}
+
+// save writes the cache if it changed. The cache is not part of the notes,
+// so it is never reported as a dry-run change, and failures are ignored.
+func (c *headingCache) save() {
+	if !c.dirty || dryrun.Enabled() {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	c.dirty = false
+}
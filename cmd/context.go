@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// daemonDialTimeout bounds the check for a running 'jot serve'
+const daemonDialTimeout = 200 * time.Millisecond
+
+var contextCmd = &cobra.Command{
+	Use:     "context",
+	Aliases: []string{"whoami"},
+	Short:   "Show the environment jot commands would run in",
+	Long: `Show the environment jot commands would run in: the effective workspace
+and how it was found, where configuration comes from, the hooks that would
+run, the state of the heading index, and whether 'jot serve' is reachable.
+
+Scripts and editor plugins can check this with one call before issuing
+operations. Nothing is written.
+
+Examples:
+  jot context              # Human-readable summary
+  jot context show --json  # The same as JSON
+  jot whoami               # Alias for jot context`,
+	Args: cobra.NoArgs,
+	RunE: runContextShow,
+}
+
+var contextShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the environment jot commands would run in",
+	Args:  cobra.NoArgs,
+	RunE:  runContextShow,
+}
+
+func runContextShow(cmd *cobra.Command, args []string) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	response := ContextResponse{
+		Version: rootCmd.Version,
+		User:    access.CurrentUser(),
+		Config:  contextConfig(),
+	}
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Workspace = contextWorkspace(ws)
+		response.Config.Workspace = filepath.Join(ws.JotDir, "config.json")
+		_, statErr := os.Stat(response.Config.Workspace)
+		response.Config.WorkspaceExists = statErr == nil
+		response.Hooks = contextHooks(ws)
+		response.Index = contextIndex(ws)
+		response.Daemon = contextDaemon(ws)
+	}
+
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+
+	fmt.Printf("User:       %s\n", response.User)
+	fmt.Printf("Version:    %s\n", response.Version)
+	if response.Workspace == nil {
+		fmt.Printf("Workspace:  none (%s)\n", response.Error)
+	} else {
+		w := response.Workspace
+		if w.Name != "" {
+			fmt.Printf("Workspace:  %s, %s (%s)\n", w.Name, w.Root, w.Source)
+		} else {
+			fmt.Printf("Workspace:  %s (%s)\n", w.Root, w.Source)
+		}
+		if w.Remote != "" {
+			fmt.Printf("Remote:     %s\n", w.Remote)
+		}
+		fmt.Printf("Storage:    %s\n", w.Storage)
+		if w.AccessPolicy {
+			fmt.Printf("Access:     %s\n", w.AccessLevel)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Global config:    %s\n", existence(response.Config.Global, response.Config.GlobalExists))
+	if response.Config.Workspace != "" {
+		fmt.Printf("Workspace config: %s\n", existence(response.Config.Workspace, response.Config.WorkspaceExists))
+	}
+	for _, name := range sortedKeys(response.Config.Env) {
+		fmt.Printf("  %s=%s\n", name, response.Config.Env[name])
+	}
+	if response.Workspace == nil {
+		return nil
+	}
+
+	fmt.Println()
+	if !response.Hooks.Enabled {
+		fmt.Println("Hooks:  disabled")
+	} else if len(response.Hooks.Active) == 0 {
+		fmt.Println("Hooks:  none")
+	} else {
+		fmt.Println("Hooks:")
+		for _, hookType := range hooks.AllTypes {
+			if names := response.Hooks.Active[string(hookType)]; len(names) > 0 {
+				fmt.Printf("  %-16s %s\n", hookType, strings.Join(names, ", "))
+			}
+		}
+	}
+
+	index := response.Index
+	fmt.Printf("Index:  %d of %d files cached, %d stale\n", index.Cached, index.Files, index.Stale)
+
+	if response.Daemon.Running {
+		fmt.Printf("Daemon: running on %s\n", response.Daemon.Socket)
+	} else {
+		fmt.Printf("Daemon: not running (%s)\n", response.Daemon.Socket)
+	}
+	return nil
+}
+
+// contextWorkspace describes the effective workspace and how it was found
+func contextWorkspace(ws *workspace.Workspace) *ContextWorkspace {
+	w := &ContextWorkspace{
+		Root:    ws.Root,
+		JotDir:  ws.JotDir,
+		Remote:  ws.Location,
+		Storage: storage.Current().Name(),
+		Source:  "default",
+	}
+	for name, path := range config.ListWorkspaces() {
+		if path == ws.Root || (ws.Location != "" && path == ws.Location) {
+			w.Name = name
+		}
+	}
+
+	switch {
+	case workspaceName != "":
+		w.Source = "flag"
+	case localWorkspaceRoot() == ws.Root:
+		w.Source = "directory"
+	}
+
+	if policy, err := access.Load(ws.JotDir); err == nil {
+		w.AccessPolicy = true
+		w.AccessLevel = policy.Level(access.CurrentUser(), "").String()
+	} else if !errors.Is(err, access.ErrNoPolicy) {
+		w.AccessPolicy = true
+		w.AccessLevel = "invalid policy: " + err.Error()
+	}
+	return w
+}
+
+// localWorkspaceRoot returns the nearest directory above the current one
+// holding a .jot directory, or ""
+func localWorkspaceRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".jot")); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// contextConfig reports the global configuration file and JOT_ variables
+func contextConfig() ContextConfig {
+	result := ContextConfig{Env: map[string]string{}}
+	if err := config.Initialize(cfgFile); err == nil {
+		result.Global = config.FilePath()
+	}
+	if result.Global == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			result.Global = filepath.Join(home, ".jotrc")
+		}
+	}
+	_, err := os.Stat(result.Global)
+	result.GlobalExists = err == nil
+
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, "JOT_") {
+			continue
+		}
+		upper := strings.ToUpper(name)
+		if strings.Contains(upper, "TOKEN") || strings.Contains(upper, "SECRET") ||
+			strings.Contains(upper, "PASSWORD") || strings.Contains(upper, "KEY") {
+			value = "(set)"
+		}
+		result.Env[name] = value
+	}
+	return result
+}
+
+// contextHooks lists the hooks each operation would run
+func contextHooks(ws *workspace.Workspace) ContextHooks {
+	manager := hooks.NewManager(ws)
+	result := ContextHooks{Enabled: manager.Enabled(), Active: map[string][]string{}}
+	for _, hookType := range hooks.AllTypes {
+		paths, err := manager.Active(hookType)
+		if err != nil || len(paths) == 0 {
+			continue
+		}
+		names := make([]string, len(paths))
+		for i, path := range paths {
+			names[i] = filepath.Base(path)
+		}
+		result.Active[string(hookType)] = names
+	}
+	return result
+}
+
+// contextIndex compares the heading cache with the workspace's files
+func contextIndex(ws *workspace.Workspace) ContextIndex {
+	cache := loadHeadingCache(ws)
+	result := ContextIndex{Path: cache.path}
+	if info, err := os.Stat(cache.path); err == nil {
+		updated := info.ModTime()
+		result.Exists = true
+		result.Updated = &updated
+	}
+
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return result
+	}
+	result.Files = len(files)
+	for _, file := range files {
+		entry := cache.Files[filepath.ToSlash(file)]
+		if entry == nil {
+			continue
+		}
+		result.Cached++
+		info, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+		if err != nil || !entry.ModTime.Equal(info.ModTime()) || entry.Size != info.Size() {
+			result.Stale++
+		}
+	}
+	return result
+}
+
+// contextDaemon checks whether 'jot serve' answers on the workspace socket
+func contextDaemon(ws *workspace.Workspace) ContextDaemon {
+	result := ContextDaemon{Socket: filepath.Join(ws.JotDir, "serve.sock")}
+	conn, err := net.DialTimeout("unix", result.Socket, daemonDialTimeout)
+	if err != nil {
+		if _, statErr := os.Stat(result.Socket); statErr == nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+	conn.Close()
+	result.Running = true
+	return result
+}
+
+func existence(path string, exists bool) string {
+	if exists {
+		return path
+	}
+	return path + " (not found)"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ContextResponse represents the JSON response for context
+type ContextResponse struct {
+	User      string               `json:"user"`
+	Version   string               `json:"version"`
+	Workspace *ContextWorkspace    `json:"workspace"`
+	Error     string               `json:"error,omitempty"` // Why no workspace was found
+	Config    ContextConfig        `json:"config"`
+	Hooks     ContextHooks         `json:"hooks"`
+	Index     ContextIndex         `json:"index"`
+	Daemon    ContextDaemon        `json:"daemon"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// ContextWorkspace describes the effective workspace
+type ContextWorkspace struct {
+	Name         string `json:"name,omitempty"` // Registry name, if registered
+	Root         string `json:"root"`
+	JotDir       string `json:"jot_dir"`
+	Source       string `json:"source"` // flag, directory or default
+	Remote       string `json:"remote,omitempty"`
+	Storage      string `json:"storage"`
+	AccessPolicy bool   `json:"access_policy"`
+	AccessLevel  string `json:"access_level,omitempty"` // The user's default level under the policy
+}
+
+// ContextConfig lists where configuration comes from
+type ContextConfig struct {
+	Global          string            `json:"global"`
+	GlobalExists    bool              `json:"global_exists"`
+	Workspace       string            `json:"workspace,omitempty"`
+	WorkspaceExists bool              `json:"workspace_exists"`
+	Env             map[string]string `json:"env"` // JOT_ variables, secrets masked
+}
+
+// ContextHooks lists the hooks that would run, by hook type
+type ContextHooks struct {
+	Enabled bool                `json:"enabled"`
+	Active  map[string][]string `json:"active"`
+}
+
+// ContextIndex reports the heading cache's coverage and freshness
+type ContextIndex struct {
+	Path    string     `json:"path"`
+	Exists  bool       `json:"exists"`
+	Updated *time.Time `json:"updated,omitempty"`
+	Files   int        `json:"files"`
+	Cached  int        `json:"cached"`
+	Stale   int        `json:"stale"`
+}
+
+// ContextDaemon reports whether 'jot serve' is reachable
+type ContextDaemon struct {
+	Socket  string `json:"socket"`
+	Running bool   `json:"running"`
+	Error   string `json:"error,omitempty"` // Why a socket that exists did not answer
+}
+
+func init() {
+	contextCmd.AddCommand(contextShowCmd)
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/counter"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var countCmd = &cobra.Command{
+	Use:   "count SELECTOR [NAME]",
+	Short: "Show or change counters kept under a heading",
+	Long: `Show or change counters kept in a table under a heading, for habit
+tracking and tallies such as interviews held:
+
+  | Counter  | Count | Updated    |
+  |----------|------:|------------|
+  | exercise |    12 | 2024-06-12 |
+
+NAME picks the counter; without it, the only counter in the table is used,
+or one named after the heading. Changing a counter that does not exist adds
+it, and the table too, at the end of the heading's own text. Updated records
+the day of the last change.
+
+Examples:
+  jot count "habits.md#habits" exercise --inc
+  jot count "work.md#interviews" --inc --by 2
+  jot count "habits.md#habits" reading --set 0
+  jot count "habits.md#habits"              # Show the counters
+  jot count report                          # Every counter in the workspace`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		inc, _ := cmd.Flags().GetBool("inc")
+		dec, _ := cmd.Flags().GetBool("dec")
+		by, _ := cmd.Flags().GetInt("by")
+		set := cmd.Flags().Changed("set")
+		value, _ := cmd.Flags().GetInt("set")
+		changes := 0
+		for _, change := range []bool{inc, dec, set} {
+			if change {
+				changes++
+			}
+		}
+		if changes > 1 {
+			return ctx.HandleUsage(fmt.Errorf("give only one of --inc, --dec and --set"))
+		}
+		if cmd.Flags().Changed("by") && !inc && !dec {
+			return ctx.HandleUsage(fmt.Errorf("--by applies to --inc and --dec"))
+		}
+		if by < 1 {
+			return ctx.HandleValidation("by", fmt.Sprint(by), fmt.Errorf("must be at least 1"))
+		}
+
+		selector := args[0]
+		path, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleValidation("selector", selector, err)
+		}
+		if len(path.Segments) == 0 {
+			return ctx.HandleValidation("selector", selector, fmt.Errorf("selector must name a heading, such as habits.md#habits"))
+		}
+
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, path.File)
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", path.File, err))
+		}
+		doc := markdown.ParseDocument(content)
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		end := sectionEnd(subtree, markdown.ChildSubtrees(doc, content, subtree))
+		table, err := counter.Find(content[subtree.StartOffset:end])
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("'%s': %w", selector, err))
+		}
+
+		name := subtree.Heading
+		if len(args) > 1 {
+			name = args[1]
+		} else if table != nil && len(table.Counters) == 1 {
+			name = table.Counters[0].Name
+		}
+
+		response := CountResponse{
+			Operation: "count",
+			Selector:  selector,
+			File:      path.File,
+			Heading:   subtree.Heading,
+		}
+
+		if changes == 0 {
+			if table == nil {
+				return ctx.HandleError(fmt.Errorf("no counters under '%s'; add one with --inc", subtree.Heading))
+			}
+			response.Counters = table.Counters
+			if len(args) > 1 {
+				response.Counter = table.Get(name)
+				if response.Counter == nil {
+					return ctx.HandleError(fmt.Errorf("no counter named '%s' under '%s'", name, subtree.Heading))
+				}
+				response.Counters = []counter.Counter{*response.Counter}
+			}
+			if ctx.IsJSONOutput() {
+				response.Metadata = cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime)
+				return cmdutil.OutputJSON(response)
+			}
+			printCounters(response.Counters)
+			return nil
+		}
+
+		if err := checkProtectedRange(ws, path.File, subtree.StartOffset, end); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		exists := table != nil
+		if !exists {
+			table = &counter.Table{}
+		}
+		previous := 0
+		if existing := table.Get(name); existing != nil {
+			previous = existing.Count
+		}
+		switch {
+		case inc:
+			value = previous + by
+		case dec:
+			value = previous - by
+		}
+		changed := table.Set(name, value, time.Now().Format("2006-01-02"))
+		rendered := counter.Render(table.Counters)
+
+		var updated []byte
+		if exists {
+			start := subtree.StartOffset + table.Start
+			updated = append(updated, content[:start]...)
+			updated = append(updated, rendered...)
+			updated = append(updated, content[subtree.StartOffset+table.End:]...)
+		} else {
+			updated, _ = appendToSection(content, subtree.StartOffset, end, strings.TrimSuffix(rendered, "\n"), false)
+		}
+		if err := cmdutil.WriteFileContent(filePath, updated); err != nil {
+			return ctx.HandleOperationError("count", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Counter = changed
+			response.Previous = &previous
+			response.Counters = table.Counters
+			response.Metadata = cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+		cmdutil.ShowSuccess("✓ %s: %d → %d", changed.Name, previous, changed.Count)
+		return nil
+	},
+}
+
+var countReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "List every counter in the workspace",
+	Long: `List the counters in every counter table of the workspace, with totals
+for names used under more than one heading.
+
+Examples:
+  jot count report
+  jot count report --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		tables, err := findCounterTables(ws)
+		if err != nil {
+			return ctx.HandleOperationError("find counters", err)
+		}
+		totals := counterTotals(tables)
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(CountReportResponse{
+				Tables:   tables,
+				Totals:   totals,
+				Metadata: cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(tables) == 0 {
+			fmt.Println("No counters found.")
+			return nil
+		}
+		for i, table := range tables {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s (line %d)\n", table.Selector, table.Line)
+			printCounters(table.Counters)
+		}
+		if len(totals) > 0 {
+			fmt.Println()
+			fmt.Println("Totals:")
+			printCounters(totals)
+		}
+		return nil
+	},
+}
+
+// CountResponse is the JSON response for count
+type CountResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"`
+	File      string               `json:"file"`
+	Heading   string               `json:"heading"`
+	Counter   *counter.Counter     `json:"counter,omitempty"`  // The counter named or changed
+	Previous  *int                 `json:"previous,omitempty"` // Its count before a change
+	Counters  []counter.Counter    `json:"counters"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// CountTable is a counter table found by count report
+type CountTable struct {
+	Selector string            `json:"selector"`
+	File     string            `json:"file"`
+	Heading  string            `json:"heading"`
+	Line     int               `json:"line"`
+	Counters []counter.Counter `json:"counters"`
+}
+
+// CountReportResponse is the JSON response for count report
+type CountReportResponse struct {
+	Tables   []CountTable         `json:"tables"`
+	Totals   []counter.Counter    `json:"totals"` // Names counted under more than one heading
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// findCounterTables returns the counter tables of every workspace file, in
+// file order. Tables that cannot be read are skipped.
+func findCounterTables(ws *workspace.Workspace) ([]CountTable, error) {
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	tables := []CountTable{}
+	for _, file := range files {
+		content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(strings.ToLower(string(content)), "counter") {
+			continue
+		}
+		rel := filepath.ToSlash(file)
+		for _, section := range markdown.Sections(content) {
+			table, err := counter.Find(section.Own)
+			if err != nil || table == nil || len(table.Counters) == 0 {
+				continue
+			}
+			tables = append(tables, CountTable{
+				Selector: rel + "#" + strings.Join(section.Path, "/"),
+				File:     rel,
+				Heading:  section.Text,
+				Line:     markdown.CalculateLineNumber(content, section.Subtree.StartOffset+table.Start),
+				Counters: table.Counters,
+			})
+		}
+	}
+	return tables, nil
+}
+
+// counterTotals sums the counters whose name appears in more than one
+// table, matching names without regard to case
+func counterTotals(tables []CountTable) []counter.Counter {
+	var totals []counter.Counter
+	seen := map[string]int{}
+	places := map[string]int{}
+	for _, table := range tables {
+		for _, c := range table.Counters {
+			key := strings.ToLower(c.Name)
+			places[key]++
+			i, ok := seen[key]
+			if !ok {
+				i = len(totals)
+				seen[key] = i
+				totals = append(totals, counter.Counter{Name: c.Name})
+			}
+			totals[i].Count += c.Count
+			if c.Updated > totals[i].Updated {
+				totals[i].Updated = c.Updated
+			}
+		}
+	}
+
+	shared := []counter.Counter{}
+	for _, total := range totals {
+		if places[strings.ToLower(total.Name)] > 1 {
+			shared = append(shared, total)
+		}
+	}
+	return shared
+}
+
+// printCounters prints counters one per line, aligned
+func printCounters(counters []counter.Counter) {
+	width := 0
+	for _, c := range counters {
+		width = max(width, len(c.Name))
+	}
+	for _, c := range counters {
+		line := fmt.Sprintf("  %-*s  %5d", width, c.Name, c.Count)
+		if c.Updated != "" {
+			line += "  " + c.Updated
+		}
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	countCmd.Flags().Bool("inc", false, "Add to the counter")
+	countCmd.Flags().Bool("dec", false, "Subtract from the counter")
+	countCmd.Flags().Int("set", 0, "Set the counter to a value")
+	countCmd.Flags().Int("by", 1, "Amount to add or subtract with --inc or --dec")
+	countCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected files and subtrees")
+	countCmd.AddCommand(countReportCmd)
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/spf13/cobra"
+)
+
+// checkedItemPattern matches a completed task list item
+var checkedItemPattern = regexp.MustCompile(`(?m)^(\s*(?:[-*+]|\d+[.)])\s+)\[[xX]\]`)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp SELECTOR --to DESTINATION",
+	Short: "Copy a subtree to another location, optionally filling in placeholders",
+	Long: `Copy a subtree to another location, leaving the original in place.
+
+Copies suit checklists, weekly plans and other skeletons kept in your notes.
+With --expand, date placeholders such as {{date}} in the copy are filled in,
+relative to today or to --date; --set fills in your own. --uncheck clears
+completed task items, and --heading renames the copy.
+
+Placeholders:
+  {{date}}     2025-07-07        {{date+7}}, {{date-1}}: days from the date
+  {{weekday}}  Monday            {{weekday+1}}
+  {{week}}     2025-W28          {{week+1}}: weeks from the date
+  {{month}}    2025-07           {{month+1}}
+  {{year}}     2025              {{year-1}}
+  {{time}}     09:30
+  {{NAME}}     the value of --set NAME=VALUE
+
+Examples:
+  jot cp "plans.md#weekly plan" --to "work.md#weeks" --expand
+  jot cp "plans.md#weekly plan" --to "work.md#weeks" --date 2025-07-14 --heading "Week of {{date}}"
+  jot cp "checklists.md#release" --to "work.md#v2.1" --uncheck --set version=2.1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		to, _ := cmd.Flags().GetString("to")
+		prepend, _ := cmd.Flags().GetBool("prepend")
+		expand, _ := cmd.Flags().GetBool("expand")
+		dateFlag, _ := cmd.Flags().GetString("date")
+		sets, _ := cmd.Flags().GetStringArray("set")
+		uncheck, _ := cmd.Flags().GetBool("uncheck")
+		heading, _ := cmd.Flags().GetString("heading")
+
+		if to == "" {
+			return ctx.HandleValidation("to", to, fmt.Errorf("a destination is required (--to)"))
+		}
+		sourcePath, err := markdown.ParsePath(args[0])
+		if err != nil {
+			return ctx.HandleValidation("source path", args[0], err)
+		}
+		destPath, err := markdown.ParsePath(to)
+		if err != nil {
+			return ctx.HandleValidation("destination path", to, err)
+		}
+
+		now := time.Now()
+		if dateFlag != "" {
+			now, err = time.ParseInLocation("2006-01-02", dateFlag, time.Local)
+			if err != nil {
+				return ctx.HandleValidation("date", dateFlag, fmt.Errorf("use YYYY-MM-DD"))
+			}
+			expand = true
+		}
+		vars := make(map[string]string, len(sets))
+		for _, set := range sets {
+			name, value, ok := strings.Cut(set, "=")
+			if !ok || name == "" {
+				return ctx.HandleValidation("set", set, fmt.Errorf("use NAME=VALUE"))
+			}
+			vars[name] = value
+			expand = true
+		}
+
+		subtree, err := ExtractSubtree(ws, sourcePath)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to extract subtree: %w", err))
+		}
+
+		// Transform the copy's text before its heading levels
+		copied := *subtree
+		content := string(subtree.Content)
+		if heading != "" {
+			content = renameTopHeading(content, heading)
+		}
+		if expand {
+			content = template.ExpandPlaceholders(content, now, vars)
+		}
+		if uncheck {
+			content = checkedItemPattern.ReplaceAllString(content, "${1}[ ]")
+		}
+		copied.Content = []byte(content)
+		copied.Heading = firstHeading(content)
+
+		dest, err := ResolveDestination(ws, destPath, prepend)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to resolve destination: %w", err))
+		}
+		level, err := refileLevel(cmd, dest.TargetLevel)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		transformedContent := TransformSubtreeLevel(&copied, level)
+		copiedTo := movedSelector(ws, destPath.File, dest, copied.Heading, level)
+
+		if err := performDirectInsertion(ws, dest, transformedContent); err != nil {
+			return ctx.HandleOperationError("copy", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(CopyResponse{
+				Operation:   "copy",
+				Source:      args[0],
+				Destination: to,
+				Selector:    copiedTo,
+				Heading:     copied.Heading,
+				Level:       level,
+				Content:     string(transformedContent),
+				Metadata:    cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Copied '%s' to %s", subtree.Heading, copiedTo)
+		return nil
+	},
+}
+
+// renameTopHeading replaces the text of the heading that starts a subtree
+func renameTopHeading(content, heading string) string {
+	line, rest, _ := strings.Cut(content, "\n")
+	hashes := strings.TrimRight(line[:len(line)-len(strings.TrimLeft(line, "#"))], " ")
+	if hashes == "" {
+		return content
+	}
+	renamed := hashes + " " + heading
+	if strings.Contains(content, "\n") {
+		renamed += "\n" + rest
+	}
+	return renamed
+}
+
+// CopyResponse represents the JSON response for cp
+type CopyResponse struct {
+	Operation   string               `json:"operation"`
+	Source      string               `json:"source"`
+	Destination string               `json:"destination"`
+	Selector    string               `json:"selector"` // Where the copy can be found
+	Heading     string               `json:"heading"`
+	Level       int                  `json:"level"`
+	Content     string               `json:"content"`
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func init() {
+	cpCmd.Flags().String("to", "", "Destination path (e.g., 'work.md#weeks')")
+	cpCmd.Flags().Bool("prepend", false, "Insert the copy at the beginning under the target heading")
+	cpCmd.Flags().Bool("expand", false, "Fill in date placeholders such as {{date}} in the copy")
+	cpCmd.Flags().String("date", "", "Date placeholders are relative to (YYYY-MM-DD); implies --expand")
+	cpCmd.Flags().StringArray("set", nil, "Fill in {{NAME}} with VALUE (NAME=VALUE, repeatable); implies --expand")
+	cpCmd.Flags().Bool("uncheck", false, "Clear completed task items ([x]) in the copy")
+	cpCmd.Flags().String("heading", "", "Rename the copied heading")
+	cpCmd.Flags().Int("level", 0, "Heading level for the copy, instead of the destination's")
+	cpCmd.Flags().Int("promote", 0, "Place the copy this many levels shallower than the destination's level")
+	cpCmd.Flags().Int("demote", 0, "Place the copy this many levels deeper than the destination's level")
+	cpCmd.Flags().BoolVar(&forceProtected, "force", false, "Write into protected files and subtrees")
+}
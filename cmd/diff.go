@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/diff"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff SELECTOR_A SELECTOR_B",
+	Short: "Show differences between two files or subtrees",
+	Long: `Compare two files or subtrees line by line.
+
+When both selectors are subtrees, the second subtree's headings are shifted
+to the level of the first before comparing, so a section that was refiled
+under a deeper heading still lines up with its original. Use --no-normalize
+to compare the content exactly as written.
+
+Examples:
+  jot diff "work.md#projects/api" "archive.md#api"
+  jot diff "inbox.md#meeting" "work.md#meetings/standup" --side-by-side
+  jot diff notes.md notes-old.md --context 1
+  jot diff "a.md#draft" "b.md#draft" --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		sideBySide, _ := cmd.Flags().GetBool("side-by-side")
+		context, _ := cmd.Flags().GetInt("context")
+		width, _ := cmd.Flags().GetInt("width")
+		noNormalize, _ := cmd.Flags().GetBool("no-normalize")
+
+		if context < 0 {
+			return ctx.HandleValidation("context", strconv.Itoa(context), fmt.Errorf("context must not be negative"))
+		}
+
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		a, err := loadDiffSide(ws, args[0], noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		b, err := loadDiffSide(ws, args[1], noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		normalized := false
+		if !noNormalize && a.subtree != nil && b.subtree != nil && a.subtree.Level != b.subtree.Level {
+			b.content = TransformSubtreeLevel(b.subtree, a.subtree.Level)
+			normalized = true
+		}
+
+		lines := diff.Lines(diff.SplitLines(string(a.content)), diff.SplitLines(string(b.content)))
+		hunks := diff.Hunks(lines, context)
+		deleted, inserted := diff.Count(lines)
+
+		if ctx.IsJSONOutput() {
+			response := DiffResponse{
+				Operation:  "diff",
+				A:          args[0],
+				B:          args[1],
+				Identical:  len(hunks) == 0,
+				Normalized: normalized,
+				Summary: DiffSummary{
+					Hunks:    len(hunks),
+					Deleted:  deleted,
+					Inserted: inserted,
+				},
+				Hunks:    make([]DiffHunk, 0, len(hunks)),
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			for _, h := range hunks {
+				response.Hunks = append(response.Hunks, newDiffHunk(h))
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		if len(hunks) == 0 {
+			cmdutil.ShowSuccess("✓ No differences between '%s' and '%s'", args[0], args[1])
+			return nil
+		}
+
+		if sideBySide {
+			if width <= 0 {
+				width = diffTerminalWidth()
+			}
+			fmt.Print(diff.SideBySide(lines, width))
+		} else {
+			fmt.Print(diff.Unified(args[0], args[1], hunks))
+		}
+		return nil
+	},
+}
+
+// diffSide is one side of a comparison; subtree is nil for whole files
+type diffSide struct {
+	content []byte
+	subtree *markdown.Subtree
+}
+
+// loadDiffSide reads a whole file or extracts a subtree for comparison
+func loadDiffSide(ws *workspace.Workspace, selector string, noWorkspace bool) (*diffSide, error) {
+	if !strings.Contains(selector, "#") {
+		content, err := storage.ReadFile(resolvePeekFilePath(ws, selector, noWorkspace))
+		if err != nil {
+			return nil, cmdutil.NewFileError("read", selector, err)
+		}
+		return &diffSide{content: content}, nil
+	}
+
+	path, err := markdown.ParsePath(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector '%s': %w", selector, err)
+	}
+	subtree, err := ExtractSubtreeWithOptions(ws, path, noWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract '%s': %w", selector, err)
+	}
+	return &diffSide{content: subtree.Content, subtree: subtree}, nil
+}
+
+// diffTerminalWidth uses $COLUMNS when set, falling back to a wide default
+func diffTerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 160
+}
+
+func newDiffHunk(h diff.Hunk) DiffHunk {
+	hunk := DiffHunk{
+		AStart: h.AStart,
+		ALines: h.ALines,
+		BStart: h.BStart,
+		BLines: h.BLines,
+		Lines:  make([]DiffLine, 0, len(h.Lines)),
+	}
+	for _, line := range h.Lines {
+		entry := DiffLine{Type: line.Kind.String(), Text: line.Text}
+		if line.Kind != diff.Insert {
+			entry.ALine = line.AIndex + 1
+		}
+		if line.Kind != diff.Delete {
+			entry.BLine = line.BIndex + 1
+		}
+		hunk.Lines = append(hunk.Lines, entry)
+	}
+	return hunk
+}
+
+// DiffResponse represents the JSON response for the diff command
+type DiffResponse struct {
+	Operation  string               `json:"operation"`
+	A          string               `json:"a"`
+	B          string               `json:"b"`
+	Identical  bool                 `json:"identical"`
+	Normalized bool                 `json:"normalized"`
+	Summary    DiffSummary          `json:"summary"`
+	Hunks      []DiffHunk           `json:"hunks"`
+	Metadata   cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type DiffSummary struct {
+	Hunks    int `json:"hunks"`
+	Deleted  int `json:"deleted"`
+	Inserted int `json:"inserted"`
+}
+
+type DiffHunk struct {
+	AStart int        `json:"a_start"`
+	ALines int        `json:"a_lines"`
+	BStart int        `json:"b_start"`
+	BLines int        `json:"b_lines"`
+	Lines  []DiffLine `json:"lines"`
+}
+
+type DiffLine struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	ALine int    `json:"a_line,omitempty"`
+	BLine int    `json:"b_line,omitempty"`
+}
+
+func init() {
+	diffCmd.Flags().BoolP("side-by-side", "y", false, "Show the two sides in columns")
+	diffCmd.Flags().IntP("context", "U", 3, "Number of unchanged lines to show around changes")
+	diffCmd.Flags().Int("width", 0, "Total width for side-by-side output (default $COLUMNS or 160)")
+	diffCmd.Flags().Bool("no-normalize", false, "Compare subtrees without aligning heading levels")
+	diffCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
+}
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/eval"
+	"github.com/johncoder/jot/internal/template"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -24,14 +28,30 @@ Checks for:
 - File permissions and accessibility
 - Database consistency
 - Configuration issues
+- Eval and template approvals that drifted from their files
 - External tool availability
 
+With --security, doctor instead lists everything in the workspace that can
+run code: approved eval blocks and documents, templates with shell
+commands, and hook scripts, with their hashes, when they were last
+modified, what a template would run, whether a hook runs, and a diff of
+whatever changed since it was approved.
+
 Examples:
   jot doctor                     # Diagnose issues
-  jot doctor --fix               # Diagnose and fix issues`,
+  jot doctor --fix               # Diagnose and fix issues
+  jot doctor --security          # Review everything that can run code`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmdutil.StartCommand(cmd)
 
+		if doctorSecurity {
+			ws, err := getWorkspace(cmd)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			return runSecurityReport(ctx, ws)
+		}
+
 		if !ctx.IsJSONOutput() {
 			fmt.Println("Running jot workspace diagnostics...")
 			fmt.Println()
@@ -58,10 +78,10 @@ Examples:
 			})
 
 			if !ctx.IsJSONOutput() {
-				fmt.Println("✗ Not in a jot workspace")
+				cmdutil.Println("✗ Not in a jot workspace")
 				fmt.Println("  Run 'jot init' to initialize a workspace")
 				fmt.Println()
-				fmt.Printf("Workspace health: ✗ Critical (%d issues)\n", len(issues))
+				cmdutil.Printf("Workspace health: ✗ Critical (%d issues)\n", len(issues))
 			} else {
 				response := DoctorResponse{
 					Operation:      "doctor",
@@ -111,7 +131,7 @@ Examples:
 				Message: "inbox.md is missing",
 			})
 			if !ctx.IsJSONOutput() {
-				fmt.Println("✗ inbox.md is missing")
+				cmdutil.Println("✗ inbox.md is missing")
 			}
 		} else {
 			checks = append(checks, DoctorCheck{
@@ -138,7 +158,7 @@ Examples:
 				Message: "lib/ directory is missing",
 			})
 			if !ctx.IsJSONOutput() {
-				fmt.Println("✗ lib/ directory is missing")
+				cmdutil.Println("✗ lib/ directory is missing")
 			}
 		} else {
 			checks = append(checks, DoctorCheck{
@@ -166,7 +186,7 @@ Examples:
 				Message: ".jot/ directory is missing",
 			})
 			if !ctx.IsJSONOutput() {
-				fmt.Println("✗ .jot/ directory is missing")
+				cmdutil.Println("✗ .jot/ directory is missing")
 			}
 		} else {
 			checks = append(checks, DoctorCheck{
@@ -175,7 +195,34 @@ Examples:
 				Message: ".jot/ directory exists",
 			})
 			if !ctx.IsJSONOutput() {
-				fmt.Println("✓ .jot/ directory exists")
+				cmdutil.Println("✓ .jot/ directory exists")
+			}
+		}
+
+		// Check what workspace scans leave out
+		if boundaries, err := ws.ScanBoundaries(); err == nil {
+			scanWarnings := scanBoundaryWarnings(ws, boundaries)
+			warnings = append(warnings, scanWarnings...)
+			if len(scanWarnings) == 0 {
+				checks = append(checks, DoctorCheck{
+					Name:    "scan_boundaries",
+					Status:  "passed",
+					Message: "No nested workspaces or symlinked directories",
+				})
+				if !ctx.IsJSONOutput() {
+					cmdutil.Println("✓ No nested workspaces or symlinked directories")
+				}
+			}
+			for _, w := range scanWarnings {
+				checks = append(checks, DoctorCheck{
+					Name:    "scan_boundaries",
+					Status:  "warning",
+					Message: w.Message,
+				})
+				if !ctx.IsJSONOutput() {
+					fmt.Printf("! %s\n", w.Message)
+					fmt.Printf("  %s\n", w.Description)
+				}
 			}
 		}
 
@@ -195,7 +242,7 @@ Examples:
 					Message: "inbox.md is not writable",
 				})
 				if !ctx.IsJSONOutput() {
-					fmt.Println("✗ inbox.md is not writable")
+					cmdutil.Println("✗ inbox.md is not writable")
 				}
 			} else {
 				file.Close()
@@ -205,11 +252,36 @@ Examples:
 					Message: "inbox.md is writable",
 				})
 				if !ctx.IsJSONOutput() {
-					fmt.Println("✓ inbox.md is writable")
+					cmdutil.Println("✓ inbox.md is writable")
 				}
 			}
 		}
 
+		// Check eval and template approvals against what they cover
+		securityWarnings, prunable := securityDriftWarnings(ws)
+		warnings = append(warnings, securityWarnings...)
+		if len(securityWarnings) == 0 {
+			checks = append(checks, DoctorCheck{
+				Name:    "security_drift",
+				Status:  "passed",
+				Message: "Eval and template approvals match the workspace",
+			})
+			if !ctx.IsJSONOutput() {
+				cmdutil.Println("✓ Eval and template approvals match the workspace")
+			}
+		}
+		for _, w := range securityWarnings {
+			checks = append(checks, DoctorCheck{
+				Name:    "security_drift",
+				Status:  "warning",
+				Message: w.Message,
+			})
+			if !ctx.IsJSONOutput() {
+				fmt.Printf("! %s\n", w.Message)
+				fmt.Printf("  %s\n", w.Description)
+			}
+		}
+
 		// Check external tools
 		editors := []string{"vim", "nvim", "nano", "emacs"}
 		editorFound := false
@@ -229,7 +301,7 @@ Examples:
 				Message: fmt.Sprintf("Editor '%s' is available", foundEditor),
 			})
 			if !ctx.IsJSONOutput() {
-				fmt.Printf("✓ Editor '%s' is available\n", foundEditor)
+				cmdutil.Printf("✓ Editor '%s' is available\n", foundEditor)
 			}
 		} else {
 			warnings = append(warnings, DoctorIssue{
@@ -264,7 +336,7 @@ Examples:
 				Message: fmt.Sprintf("Pager '%s' is available", foundPager),
 			})
 			if !ctx.IsJSONOutput() {
-				fmt.Printf("✓ Pager '%s' is available\n", foundPager)
+				cmdutil.Printf("✓ Pager '%s' is available\n", foundPager)
 			}
 		} else {
 			warnings = append(warnings, DoctorIssue{
@@ -289,7 +361,7 @@ Examples:
 		}
 
 		// Apply fixes if requested
-		if doctorFix && len(issues) > 0 {
+		if doctorFix && (len(issues) > 0 || prunable) {
 			var pathUtil *cmdutil.PathUtil
 			if !ctx.IsJSONOutput() {
 				fmt.Println("Applying fixes...")
@@ -305,14 +377,14 @@ This is your inbox for capturing new notes quickly. Use 'jot capture' to add new
 ---
 
 `
-					if err := os.WriteFile(ws.InboxPath, []byte(inboxContent), 0644); err == nil {
+					if err := dryrun.WriteFile(ws.InboxPath, []byte(inboxContent), 0644); err == nil {
 						fixes = append(fixes, DoctorFix{
 							Type:        "structure",
 							Description: "Created inbox.md",
 							Success:     true,
 						})
 						if !ctx.IsJSONOutput() {
-							fmt.Println("✓ Created inbox.md")
+							cmdutil.Println("✓ Created inbox.md")
 						}
 					} else {
 						fixes = append(fixes, DoctorFix{
@@ -322,7 +394,7 @@ This is your inbox for capturing new notes quickly. Use 'jot capture' to add new
 							Error:       err.Error(),
 						})
 						if !ctx.IsJSONOutput() {
-							fmt.Printf("✗ Failed to create inbox.md: %v\n", err)
+							cmdutil.Printf("✗ Failed to create inbox.md: %v\n", err)
 						}
 					}
 				}
@@ -351,7 +423,7 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 							Success:     true,
 						})
 						if !ctx.IsJSONOutput() {
-							fmt.Println("✓ Created lib/ directory")
+							cmdutil.Println("✓ Created lib/ directory")
 						}
 					} else {
 						fixes = append(fixes, DoctorFix{
@@ -361,7 +433,7 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 							Error:       err.Error(),
 						})
 						if !ctx.IsJSONOutput() {
-							fmt.Printf("✗ Failed to create lib/ directory: %v\n", err)
+							cmdutil.Printf("✗ Failed to create lib/ directory: %v\n", err)
 						}
 					}
 				}
@@ -378,7 +450,7 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 							Success:     true,
 						})
 						if !ctx.IsJSONOutput() {
-							fmt.Println("✓ Created .jot/ directory")
+							cmdutil.Println("✓ Created .jot/ directory")
 						}
 					} else {
 						fixes = append(fixes, DoctorFix{
@@ -388,11 +460,16 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 							Error:       err.Error(),
 						})
 						if !ctx.IsJSONOutput() {
-							fmt.Printf("✗ Failed to create .jot/ directory: %v\n", err)
+							cmdutil.Printf("✗ Failed to create .jot/ directory: %v\n", err)
 						}
 					}
 				}
 			}
+
+			// Prune approvals that can never apply again
+			if prunable {
+				fixes = append(fixes, pruneApprovals(ctx, ws)...)
+			}
 		}
 
 		// Calculate summary statistics
@@ -450,13 +527,13 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 		// Summary for non-JSON output
 		if len(issues) == 0 {
 			if len(warnings) == 0 {
-				fmt.Println("Workspace health: ✓ Excellent")
+				cmdutil.Println("Workspace health: ✓ Excellent")
 			} else {
-				fmt.Printf("Workspace health: ✓ Good (%d warning%s)\n",
+				cmdutil.Printf("Workspace health: ✓ Good (%d warning%s)\n",
 					len(warnings), pluralize(len(warnings)))
 			}
 		} else {
-			fmt.Printf("Workspace health: ✗ Issues found (%d issue%s",
+			cmdutil.Printf("Workspace health: ✗ Issues found (%d issue%s",
 				len(issues), pluralize(len(issues)))
 			if len(warnings) > 0 {
 				fmt.Printf(", %d warning%s", len(warnings), pluralize(len(warnings)))
@@ -467,12 +544,151 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 				fmt.Println("Run 'jot doctor --fix' to apply automatic fixes")
 			}
 		}
+		if len(issues) == 0 && prunable && !doctorFix {
+			fmt.Println("Run 'jot doctor --fix' to remove dead approvals")
+		}
 
 		return nil
 	},
 }
 
+// securityDriftWarnings reports eval approvals whose files or blocks are
+// gone or changed, "always" approvals, and template approvals that match
+// no current template. prunable is true when --fix can remove some.
+func securityDriftWarnings(ws *workspace.Workspace) (warnings []DoctorIssue, prunable bool) {
+	rel := func(path string) string {
+		if r, err := filepath.Rel(ws.Root, path); err == nil {
+			return filepath.ToSlash(r)
+		}
+		return path
+	}
+
+	if sm, err := eval.NewSecurityManager(); err == nil {
+		for _, d := range sm.Audit() {
+			subject := rel(d.FilePath)
+			if d.BlockName != "" {
+				subject += ":" + d.BlockName
+			}
+			w := DoctorIssue{Type: "security", Severity: "low", Fixable: d.Dead()}
+			switch d.Kind {
+			case eval.DriftMissingFile:
+				w.Message = fmt.Sprintf("Eval approval for %s points at a deleted file", subject)
+				w.Description = "The approval can never apply again; 'jot doctor --fix' removes it"
+			case eval.DriftMissingBlock:
+				w.Message = fmt.Sprintf("Eval approval for %s names a block that no longer exists", subject)
+				w.Description = "The approval can never apply again; 'jot doctor --fix' removes it"
+			case eval.DriftChanged:
+				w.Message = fmt.Sprintf("Eval approval for %s is for code that has since changed", subject)
+				w.Description = "The block will not run until you review it and approve it again with 'jot eval FILE BLOCK --approve'"
+			case eval.DriftAlways:
+				w.Message = fmt.Sprintf("Eval approval for %s is in always mode", subject)
+				w.Description = "It runs whatever the code becomes; prefer hash mode so changes need approval"
+				w.Severity = "medium"
+			}
+			warnings = append(warnings, w)
+			prunable = prunable || w.Fixable
+		}
+	}
+
+	if stale, err := template.NewManager(ws).StalePermissions(); err == nil && len(stale) > 0 {
+		warnings = append(warnings, DoctorIssue{
+			Type:        "security",
+			Message:     fmt.Sprintf("%d template approval%s match no current template", len(stale), pluralize(len(stale))),
+			Description: "They were granted to templates since edited or deleted; 'jot doctor --fix' removes them",
+			Severity:    "low",
+			Fixable:     true,
+		})
+		prunable = true
+	}
+
+	return warnings, prunable
+}
+
+// pruneApprovals removes eval and template approvals that can never apply
+// again
+func pruneApprovals(ctx *cmdutil.CommandContext, ws *workspace.Workspace) []DoctorFix {
+	var fixes []DoctorFix
+	record := func(what string, removed int, err error) {
+		if err != nil {
+			fixes = append(fixes, DoctorFix{
+				Type:        "security",
+				Description: fmt.Sprintf("Failed to remove dead %s approvals", what),
+				Success:     false,
+				Error:       err.Error(),
+			})
+			if !ctx.IsJSONOutput() {
+				cmdutil.Printf("✗ Failed to remove dead %s approvals: %v\n", what, err)
+			}
+			return
+		}
+		if removed == 0 {
+			return
+		}
+		description := fmt.Sprintf("Removed %d dead %s approval%s", removed, what, pluralize(removed))
+		fixes = append(fixes, DoctorFix{Type: "security", Description: description, Success: true})
+		if !ctx.IsJSONOutput() {
+			cmdutil.Printf("✓ %s\n", description)
+		}
+	}
+
+	if sm, err := eval.NewSecurityManager(); err == nil {
+		removed, err := sm.Prune()
+		record("eval", removed, err)
+	}
+	removed, err := template.NewManager(ws).PrunePermissions()
+	record("template", removed, err)
+	return fixes
+}
+
 // pluralize returns "s" if count != 1, empty string otherwise
+// scanBoundaryWarnings explains nested workspaces and symlinked directories
+// that scans skip, or symlinks that were followed but had to be skipped
+func scanBoundaryWarnings(ws *workspace.Workspace, b *workspace.ScanBoundaries) []DoctorIssue {
+	var warnings []DoctorIssue
+	rel := func(path string) string {
+		if r, err := filepath.Rel(ws.Root, path); err == nil {
+			return filepath.ToSlash(r)
+		}
+		return path
+	}
+
+	if len(b.NestedWorkspaces) > 0 && (ws.Config == nil || !ws.Config.IncludeNestedWorkspaces) {
+		for _, dir := range b.NestedWorkspaces {
+			warnings = append(warnings, DoctorIssue{
+				Type:        "workspace",
+				Message:     fmt.Sprintf("Nested workspace %s/ is not scanned", rel(dir)),
+				Description: "It has its own .jot directory; set include_nested_workspaces in .jot/config.json to scan it from here",
+				Severity:    "low",
+				Fixable:     false,
+			})
+		}
+	}
+
+	if ws.Config == nil || !ws.Config.FollowSymlinks {
+		for _, dir := range b.SymlinkedDirs {
+			warnings = append(warnings, DoctorIssue{
+				Type:        "workspace",
+				Message:     fmt.Sprintf("Symlinked directory %s/ is not scanned", rel(dir)),
+				Description: "Set follow_symlinks in .jot/config.json to scan symlinked directories",
+				Severity:    "low",
+				Fixable:     false,
+			})
+		}
+	}
+
+	for _, dir := range b.SkippedLinks {
+		warnings = append(warnings, DoctorIssue{
+			Type:        "workspace",
+			Message:     fmt.Sprintf("Symlinked directory %s/ is skipped", rel(dir)),
+			Description: "It loops back into the workspace or repeats a directory already scanned",
+			Severity:    "low",
+			Fixable:     false,
+		})
+	}
+
+	return warnings
+}
+
 func pluralize(count int) string {
 	if count == 1 {
 		return ""
@@ -482,6 +698,8 @@ func pluralize(count int) string {
 
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Automatically fix detected issues")
+	doctorCmd.Flags().BoolVar(&doctorSecurity, "security", false, "Report everything that can run code and what changed since it was approved")
+	doctorCmd.MarkFlagsMutuallyExclusive("fix", "security")
 }
 
 // JSON response structures for doctor command
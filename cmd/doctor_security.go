@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/diff"
+	"github.com/johncoder/jot/internal/eval"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+var doctorSecurity bool
+
+// Template states in the security report
+const (
+	templateApproved   = "approved"
+	templateChanged    = "changed" // Approved once, edited since
+	templateUnapproved = "unapproved"
+)
+
+// DoctorSecurityResponse is the JSON response for 'doctor --security'
+type DoctorSecurityResponse struct {
+	Operation     string               `json:"operation"`
+	WorkspaceRoot string               `json:"workspace_root"`
+	SafeMode      string               `json:"safe_mode,omitempty"` // Why safe mode is on, when it is
+	Evals         []SecurityEval       `json:"evals"`
+	Templates     []SecurityTemplate   `json:"templates"`
+	Hooks         []SecurityHook       `json:"hooks"`
+	Commands      []SecurityCommand    `json:"commands"`
+	Changed       int                  `json:"changed"` // Approvals whose code changed since
+	Metadata      cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// SecurityEval is an eval block or document approval
+type SecurityEval struct {
+	File        string `json:"file"`
+	Block       string `json:"block,omitempty"` // Empty for a whole document
+	Mode        string `json:"mode"`
+	State       string `json:"state"` // current, changed, missing_file or missing_block
+	ApprovedAt  string `json:"approved_at"`
+	Hash        string `json:"hash,omitempty"`
+	CurrentHash string `json:"current_hash,omitempty"`
+	Modified    string `json:"modified,omitempty"` // When the note was last written
+	Diff        string `json:"diff,omitempty"`     // Unified diff from the approved code
+
+	Interpreter        string `json:"interpreter,omitempty"`         // Configured interpreter approved
+	CurrentInterpreter string `json:"current_interpreter,omitempty"` // Configured interpreter now
+}
+
+// SecurityTemplate is a template that runs shell commands when rendered
+type SecurityTemplate struct {
+	Name     string   `json:"name"`
+	File     string   `json:"file"`
+	State    string   `json:"state"` // approved, changed or unapproved
+	Hash     string   `json:"hash"`
+	Modified string   `json:"modified"`
+	Commands []string `json:"commands"` // What rendering it would run
+	Diff     string   `json:"diff,omitempty"`
+}
+
+// SecurityHook is a hook script
+type SecurityHook struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Type     string `json:"type"`
+	Global   bool   `json:"global"` // From ~/.jot/hooks
+	Active   bool   `json:"active"` // Runs, given hooks settings and allow and deny lists
+	Hash     string `json:"hash"`
+	Modified string `json:"modified"`
+}
+
+// SecurityCommand is a command line the configuration has jot run
+type SecurityCommand struct {
+	Setting string `json:"setting"` // Where it is set, such as "issues.github.token_command"
+	Command string `json:"command"`
+	Source  string `json:"source"` // workspace, global, environment or default
+}
+
+// runSecurityReport lists everything in the workspace that can run code,
+// with what changed since it was approved, instead of the usual checks
+func runSecurityReport(ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
+	response := DoctorSecurityResponse{
+		Operation:     "doctor_security",
+		WorkspaceRoot: ws.Root,
+		SafeMode:      safemode.Reason(),
+		Evals:         []SecurityEval{},
+		Templates:     []SecurityTemplate{},
+		Hooks:         []SecurityHook{},
+	}
+	rel := func(path string) string {
+		if r, err := filepath.Rel(ws.Root, path); err == nil && !strings.HasPrefix(r, "..") {
+			return filepath.ToSlash(r)
+		}
+		return path
+	}
+
+	sm, err := eval.NewSecurityManager()
+	if err != nil {
+		return ctx.HandleError(fmt.Errorf("failed to initialize security manager: %w", err))
+	}
+	for _, approval := range sm.Inventory() {
+		item := SecurityEval{
+			File:        rel(approval.FilePath),
+			Block:       approval.BlockName,
+			Mode:        string(approval.Mode),
+			State:       approval.State,
+			ApprovedAt:  approval.ApprovedAt,
+			Hash:        approval.Hash,
+			CurrentHash: approval.CurrentHash,
+
+			Interpreter:        approval.Interpreter,
+			CurrentInterpreter: approval.CurrentInterpreter,
+		}
+		if info, err := os.Stat(approval.FilePath); err == nil {
+			item.Modified = info.ModTime().Format(time.RFC3339)
+		}
+		if approval.State == eval.DriftChanged {
+			response.Changed++
+			if approval.ApprovedCode != "" && approval.ApprovedCode != approval.CurrentCode {
+				item.Diff = unifiedDiff(approval.ApprovedCode, approval.CurrentCode)
+			}
+		}
+		response.Evals = append(response.Evals, item)
+	}
+
+	tm := template.NewManager(ws)
+	templates, err := tm.List()
+	if err != nil {
+		return ctx.HandleOperationError("list templates", err)
+	}
+	for _, t := range templates {
+		approved, kept := tm.ApprovedContent(t.Name)
+		commands := template.ShellCommands(t.Content)
+		if len(commands) == 0 && len(template.ShellCommands(approved)) == 0 {
+			continue // Renders without running anything
+		}
+		item := SecurityTemplate{
+			Name:     t.Name,
+			File:     rel(t.Path),
+			State:    templateUnapproved,
+			Hash:     t.Hash,
+			Commands: commands,
+		}
+		if item.Commands == nil {
+			item.Commands = []string{}
+		}
+		if info, err := os.Stat(t.Path); err == nil {
+			item.Modified = info.ModTime().Format(time.RFC3339)
+		}
+		switch {
+		case t.Approved:
+			item.State = templateApproved
+		case kept:
+			item.State = templateChanged
+			item.Diff = unifiedDiff(approved, t.Content)
+			response.Changed++
+		}
+		response.Templates = append(response.Templates, item)
+	}
+
+	scripts, err := hooks.NewManager(ws).Scripts()
+	if err != nil {
+		return ctx.HandleOperationError("list hooks", err)
+	}
+	for _, script := range scripts {
+		item := SecurityHook{
+			Name:   filepath.Base(script.Path),
+			File:   rel(script.Path),
+			Type:   string(script.Type),
+			Global: script.Global,
+			Active: script.Active,
+		}
+		if content, err := os.ReadFile(script.Path); err == nil {
+			item.Hash = fmt.Sprintf("%x", sha256.Sum256(content))
+		}
+		if info, err := os.Stat(script.Path); err == nil {
+			item.Modified = info.ModTime().Format(time.RFC3339)
+		}
+		response.Hooks = append(response.Hooks, item)
+	}
+	response.Commands = configuredCommands(ws)
+
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+	printSecurityReport(response)
+	return nil
+}
+
+// configuredCommands lists the command lines in the workspace and global
+// configuration, and the one a remote workspace reaches its host with
+func configuredCommands(ws *workspace.Workspace) []SecurityCommand {
+	commands := []SecurityCommand{}
+	add := func(setting, command, source string) {
+		if strings.TrimSpace(command) != "" {
+			commands = append(commands, SecurityCommand{Setting: setting, Command: command, Source: source})
+		}
+	}
+
+	cfg := ws.Config
+	if cfg == nil {
+		cfg = &workspace.WorkspaceConfig{}
+	}
+	add("proof_checker", cfg.ProofChecker, "workspace")
+	add("suggest_command", cfg.SuggestCommand, "workspace")
+	add("related_embed_command", cfg.RelatedEmbedCommand, "workspace")
+
+	interpreters := ws.GetInterpreters()
+	languages := make([]string, 0, len(interpreters))
+	for lang := range interpreters {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	for _, lang := range languages {
+		add("eval.interpreters."+lang, interpreters[lang].Command, interpreters[lang].Source)
+	}
+
+	trackers := make([]string, 0, len(cfg.Issues))
+	for name := range cfg.Issues {
+		trackers = append(trackers, name)
+	}
+	sort.Strings(trackers)
+	for _, name := range trackers {
+		if tracker := cfg.Issues[name]; tracker != nil {
+			add("issues."+name+".token_command", tracker.TokenCommand, "workspace")
+		}
+	}
+	if cfg.Links != nil {
+		for i, ticket := range cfg.Links.Tickets {
+			add(fmt.Sprintf("links.tickets[%d].token_command", i), ticket.TokenCommand, "workspace")
+		}
+	}
+
+	if remote, ok := ws.Backend.(*storage.SSH); ok {
+		source := "default"
+		if os.Getenv("JOT_SSH") != "" {
+			source = "environment" // JOT_SSH replaces the ssh command
+		}
+		add("ssh "+ws.Location, strings.Join(remote.Command, " "), source)
+	}
+	return commands
+}
+
+// unifiedDiff returns the diff from approved to current
+func unifiedDiff(approved, current string) string {
+	lines := diff.Lines(diff.SplitLines(approved), diff.SplitLines(current))
+	return diff.Unified("approved", "current", diff.Hunks(lines, 3))
+}
+
+func printSecurityReport(r DoctorSecurityResponse) {
+	fmt.Printf("Security report for %s\n", r.WorkspaceRoot)
+	if r.SafeMode != "" {
+		cmdutil.ShowWarning("Safe mode is on, so none of the code below runs: %s", r.SafeMode)
+	}
+
+	fmt.Printf("\nEval approvals (%d)\n", len(r.Evals))
+	if len(r.Evals) == 0 {
+		fmt.Println("  none")
+	}
+	for _, e := range r.Evals {
+		subject := e.File + ":" + e.Block
+		if e.Block == "" {
+			subject = e.File + " (whole document)"
+		}
+		details := []string{e.Mode + " mode", "approved " + reportTime(e.ApprovedAt)}
+		if e.Hash != "" {
+			details = append(details, "hash "+shortDigest(e.Hash))
+		}
+		if e.Modified != "" {
+			details = append(details, "note modified "+reportTime(e.Modified))
+		}
+		switch e.State {
+		case eval.DriftChanged:
+			cmdutil.Printf("  ✗ %s: changed since approval, now %s (%s)\n", subject, shortDigest(e.CurrentHash), strings.Join(details, ", "))
+			if e.Interpreter != e.CurrentInterpreter {
+				fmt.Printf("      Interpreter changed from %s to %s\n", interpreterName(e.Interpreter), interpreterName(e.CurrentInterpreter))
+			} else if e.Diff == "" {
+				fmt.Println("      The approved code was not kept; approvals made from now on keep it")
+			}
+		case eval.DriftMissingFile:
+			cmdutil.Printf("  ✗ %s: the note is gone (%s)\n", subject, strings.Join(details, ", "))
+		case eval.DriftMissingBlock:
+			cmdutil.Printf("  ✗ %s: the block is gone (%s)\n", subject, strings.Join(details, ", "))
+		default:
+			mark := "✓"
+			if e.Mode == string(eval.ApprovalModeAlways) {
+				mark = "⚠" // Runs whatever the code becomes
+			}
+			cmdutil.Printf("  %s %s (%s)\n", mark, subject, strings.Join(details, ", "))
+		}
+		printIndented(e.Diff)
+	}
+
+	fmt.Printf("\nTemplates with shell commands (%d)\n", len(r.Templates))
+	if len(r.Templates) == 0 {
+		fmt.Println("  none")
+	}
+	for _, t := range r.Templates {
+		details := fmt.Sprintf("hash %s, modified %s", shortDigest(t.Hash), reportTime(t.Modified))
+		switch t.State {
+		case templateApproved:
+			cmdutil.Printf("  ✓ %s: approved (%s)\n", t.Name, details)
+		case templateChanged:
+			cmdutil.Printf("  ✗ %s: changed since approval, will not run until approved again (%s)\n", t.Name, details)
+		default:
+			fmt.Printf("  - %s: not approved, will not run (%s)\n", t.Name, details)
+		}
+		for _, command := range t.Commands {
+			fmt.Printf("      runs: %s\n", command)
+		}
+		printIndented(t.Diff)
+	}
+
+	fmt.Printf("\nHooks (%d)\n", len(r.Hooks))
+	if len(r.Hooks) == 0 {
+		fmt.Println("  none")
+	}
+	for _, h := range r.Hooks {
+		details := fmt.Sprintf("%s, hash %s, modified %s", h.File, shortDigest(h.Hash), reportTime(h.Modified))
+		if h.Active {
+			cmdutil.Printf("  ✓ %s: runs for %s (%s)\n", h.Name, h.Type, details)
+		} else {
+			fmt.Printf("  - %s: does not run, hooks are disabled or it is not allowed for %s (%s)\n", h.Name, h.Type, details)
+		}
+	}
+
+	fmt.Printf("\nConfigured commands (%d)\n", len(r.Commands))
+	if len(r.Commands) == 0 {
+		fmt.Println("  none")
+	}
+	for _, c := range r.Commands {
+		fmt.Printf("  - %s (%s): %s\n", c.Setting, c.Source, c.Command)
+	}
+
+	fmt.Println()
+	if r.Changed > 0 {
+		cmdutil.ShowWarning("⚠️  %d approval%s changed since approved; review the diffs above", r.Changed, pluralize(r.Changed))
+	} else {
+		cmdutil.ShowSuccess("✓ Nothing changed since it was approved")
+	}
+}
+
+// interpreterName describes a configured interpreter for the report
+func interpreterName(command string) string {
+	if command == "" {
+		return "the default evaluator"
+	}
+	return "'" + command + "'"
+}
+
+// printIndented prints a diff under the item it belongs to
+func printIndented(text string) {
+	for _, line := range diff.SplitLines(text) {
+		fmt.Printf("      %s\n", line)
+	}
+}
+
+// shortDigest shortens a hash for display
+func shortDigest(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// reportTime shortens an RFC 3339 time for display
+func reportTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
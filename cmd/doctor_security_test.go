@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestConfiguredCommands(t *testing.T) {
+	t.Setenv("JOT_SSH", "")
+	ws := &workspace.Workspace{
+		Root: t.TempDir(),
+		Config: &workspace.WorkspaceConfig{
+			ProofChecker:        "aspell list",
+			SuggestCommand:      "llm -m local",
+			RelatedEmbedCommand: "embed --stdin",
+			Eval:                &config.EvalConfig{Interpreters: map[string]string{"ruby": "ruby -", "lua": "lua -"}},
+			Issues: map[string]*workspace.TrackerConfig{
+				"gitlab": {TokenEnv: "GL_TOKEN"},
+				"github": {TokenCommand: "gh auth token"},
+			},
+			Links: &workspace.LinksConfig{Tickets: []workspace.TicketConfig{
+				{Prefixes: []string{"ABC"}},
+				{Prefixes: []string{"OPS"}, TokenCommand: "pass jira"},
+			}},
+		},
+		Location: "ssh://host/notes",
+		Backend:  &storage.SSH{Command: []string{"ssh", "host"}},
+	}
+
+	var got []string
+	for _, c := range configuredCommands(ws) {
+		got = append(got, c.Setting+"="+c.Command+" ("+c.Source+")")
+	}
+	want := []string{
+		"proof_checker=aspell list (workspace)",
+		"suggest_command=llm -m local (workspace)",
+		"related_embed_command=embed --stdin (workspace)",
+		"eval.interpreters.lua=lua - (workspace)",
+		"eval.interpreters.ruby=ruby - (workspace)",
+		"issues.github.token_command=gh auth token (workspace)",
+		"links.tickets[1].token_command=pass jira (workspace)",
+		"ssh ssh://host/notes=ssh host (default)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("configuredCommands() =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := configuredCommands(&workspace.Workspace{Root: t.TempDir()}); len(got) != 0 {
+		t.Errorf("configuredCommands() with no configuration = %v, want none", got)
+	}
+}
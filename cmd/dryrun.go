@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/opstats"
+	"github.com/johncoder/jot/internal/plan"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// dryRunFlag holds the global --dry-run flag
+	dryRunFlag bool
+
+	// planFile holds the global --plan flag
+	planFile string
+)
+
+// prepareCommand runs before every command. Planning implies a dry run.
+func prepareCommand(cmd *cobra.Command, args []string) error {
+	StartTiming()
+	configureOutput(cmd)
+	if err := configureJSON(cmd); err != nil {
+		return err
+	}
+	dryrun.Enable(dryRunFlag || planFile != "")
+	opstats.Reset()
+	workspace.SetOverride(workspaceName)
+	configureSelectorMatching(cmd)
+	if err := configureStorage(cmd); err != nil {
+		return err
+	}
+	configureJournal(cmd)
+	configureAccess(cmd)
+	configureNetwork(cmd)
+	configureSafeMode(cmd)
+	return expandSelectorAliases(cmd, args)
+}
+
+// configureSelectorMatching applies the workspace's selector settings:
+// diacritic-insensitive matching (also set by JOT_FOLD_DIACRITICS) and the
+// slug selector fallback
+func configureSelectorMatching(cmd *cobra.Command) {
+	ws, err := getWorkspace(cmd)
+	if err == nil && ws.Config != nil {
+		markdown.SetFoldDiacritics(ws.Config.FoldDiacritics)
+		markdown.SetSlugSelectors(!ws.Config.DisableSlugSelectors)
+	}
+	if on, err := strconv.ParseBool(os.Getenv("JOT_FOLD_DIACRITICS")); err == nil {
+		markdown.SetFoldDiacritics(on)
+	}
+}
+
+// finishCommand runs after every successful command, writing the plan file
+// and reporting skipped writes. Otherwise it records the day's workspace
+// metrics if they have not been recorded yet.
+func finishCommand(cmd *cobra.Command, args []string) error {
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		// Shell completion has a latency budget to keep
+		return nil
+	}
+	if !dryrun.Enabled() {
+		if ws, err := getWorkspace(cmd); err == nil {
+			recordDailySnapshot(ws)
+		}
+		return nil
+	}
+
+	if planFile != "" {
+		root := ""
+		if ws, err := getWorkspace(cmd); err == nil {
+			root = ws.Root
+		}
+		p := plan.Build(root, planCommandLine(os.Args), dryrun.Pending(), dryrun.Directories())
+		if err := p.Save(planFile); err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+		if !jsonOutput {
+			reportDryRun()
+			cmdutil.ShowInfo("\nPlan written to %s (%d file(s)). Apply it with 'jot apply %s'", planFile, len(p.Files), planFile)
+		}
+		return nil
+	}
+
+	if !jsonOutput {
+		reportDryRun()
+	}
+	return nil
+}
+
+// planCommandLine reconstructs the command being planned, without the --plan flag
+func planCommandLine(argv []string) string {
+	parts := []string{"jot"}
+	for i := 1; i < len(argv); i++ {
+		arg := argv[i]
+		if arg == "--plan" {
+			i++
+			continue
+		}
+		if strings.HasPrefix(arg, "--plan=") {
+			continue
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// reportDryRun lists the changes a command would have made. JSON output
+// carries the same list in its metadata instead.
+func reportDryRun() {
+	changes := dryrun.Changes()
+	fmt.Println()
+	if len(changes) == 0 {
+		fmt.Println("Dry run: no files would be changed")
+		return
+	}
+
+	fmt.Println("Dry run: no changes were written. Planned changes:")
+	for _, change := range changes {
+		path := displayPath(change.Path)
+		switch change.Action {
+		case "mkdir":
+			fmt.Printf("  mkdir   %s/\n", path)
+			continue
+		case "modify":
+			fmt.Printf("  modify  %s (+%d -%d bytes)\n", path, change.BytesInserted, change.BytesRemoved)
+		case "remove":
+			fmt.Printf("  remove  %s (-%d bytes)\n", path, change.BytesRemoved)
+		default:
+			fmt.Printf("  %-7s %s (+%d bytes)\n", change.Action, path, change.BytesInserted)
+		}
+		for _, heading := range change.HeadingsCreated {
+			fmt.Printf("            + %s\n", heading)
+		}
+	}
+}
+
+// displayPath shortens a path relative to the current directory when it is inside it
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
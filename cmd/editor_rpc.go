@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/rpc"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var editorRPCCmd = &cobra.Command{
+	Use:    "editor-rpc",
+	Short:  "Serve editor plugin requests over stdin and stdout",
+	Hidden: true,
+	Long: `Serve requests from an editor plugin over a single long-lived process.
+
+Requests and responses are JSON, each preceded by a "Content-Length: N"
+header and a blank line, as in the Language Server Protocol. The process
+exits when stdin is closed.
+
+Methods: peek, toc, capture, refile, selector-complete.
+See docs/reference/editor-rpc.md for the request and response formats.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		return serveEditorRPC(ws, os.Stdin, os.Stdout)
+	},
+}
+
+// serveEditorRPC answers requests until the input ends
+func serveEditorRPC(ws *workspace.Workspace, in io.Reader, out io.Writer) error {
+	writer := bufio.NewWriter(out)
+	send := func(response rpc.Response) error {
+		if err := rpc.WriteResponse(writer, response); err != nil {
+			return err
+		}
+		return writer.Flush()
+	}
+	return serveRPC(in, send, func(request rpc.Request) rpc.Response {
+		return handleEditorRequest(ws, request)
+	})
+}
+
+// serveRPC reads requests from in and sends each one's response, until the
+// input ends or its framing breaks
+func serveRPC(in io.Reader, send func(rpc.Response) error, handle func(rpc.Request) rpc.Response) error {
+	reader := bufio.NewReader(in)
+
+	for {
+		body, err := rpc.Read(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// Framing errors leave the stream unusable
+			_ = send(rpc.Response{Error: &rpc.Error{Code: rpc.CodeParseError, Message: err.Error()}})
+			return err
+		}
+
+		var request rpc.Request
+		response := rpc.Response{}
+		if err := json.Unmarshal(body, &request); err != nil {
+			response.Error = &rpc.Error{Code: rpc.CodeParseError, Message: err.Error()}
+		} else {
+			response = handle(request)
+		}
+
+		if err := send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// editorHandler answers one request method
+type editorHandler func(*workspace.Workspace, json.RawMessage) (interface{}, error)
+
+// editorHandlers maps method names to their handlers
+var editorHandlers = map[string]editorHandler{
+	"peek":              rpcPeek,
+	"toc":               rpcTOC,
+	"capture":           locked("capture", rpcCapture),
+	"refile":            locked("refile", rpcRefile),
+	"selector-complete": rpcSelectorComplete,
+}
+
+// locked holds the workspace lock around a handler that changes notes, as
+// registerMutating does for commands
+func locked(method string, handler editorHandler) editorHandler {
+	return func(ws *workspace.Workspace, raw json.RawMessage) (interface{}, error) {
+		unlock, err := ws.Lock("jot rpc " + method)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		return handler(ws, raw)
+	}
+}
+
+// errInvalidParams marks errors caused by the request rather than the workspace
+var errInvalidParams = errors.New("invalid params")
+
+func handleEditorRequest(ws *workspace.Workspace, request rpc.Request) rpc.Response {
+	response := rpc.Response{ID: request.ID}
+
+	handler, ok := editorHandlers[request.Method]
+	if !ok {
+		response.Error = &rpc.Error{Code: rpc.CodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", request.Method)}
+		return response
+	}
+
+	result, err := handler(ws, request.Params)
+	if err != nil {
+		code := rpc.CodeFailed
+		switch {
+		case errors.Is(err, errInvalidParams):
+			code = rpc.CodeInvalidParams
+		case errors.As(err, new(*access.DeniedError)):
+			code = rpc.CodeForbidden
+		case strings.Contains(err.Error(), "not found"), strings.Contains(err.Error(), "no headings found"):
+			code = rpc.CodeNotFound
+		}
+		response.Error = &rpc.Error{Code: code, Message: err.Error()}
+		return response
+	}
+
+	response.Result = result
+	return response
+}
+
+// decodeParams unmarshals request params, reporting failures as invalid params
+func decodeParams(raw json.RawMessage, params interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+	return nil
+}
+
+func requireParam(name, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%w: %s is required", errInvalidParams, name)
+	}
+	return nil
+}
+
+type rpcPeekResult struct {
+	Selector string `json:"selector"`
+	File     string `json:"file"`
+	Heading  string `json:"heading,omitempty"`
+	Level    int    `json:"level,omitempty"`
+	Content  string `json:"content"`
+}
+
+func rpcPeek(ws *workspace.Workspace, raw json.RawMessage) (interface{}, error) {
+	var params struct {
+		Selector string `json:"selector"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if err := requireParam("selector", params.Selector); err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(params.Selector, "#") {
+		content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, params.Selector))
+		if err != nil {
+			return nil, err
+		}
+		return rpcPeekResult{Selector: params.Selector, File: params.Selector, Content: string(content)}, nil
+	}
+
+	path, err := markdown.ParsePath(params.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+	subtree, err := ExtractSubtree(ws, path)
+	if err != nil {
+		return nil, err
+	}
+	return rpcPeekResult{
+		Selector: params.Selector,
+		File:     path.File,
+		Heading:  subtree.Heading,
+		Level:    subtree.Level,
+		Content:  string(subtree.Content),
+	}, nil
+}
+
+type rpcTOCResult struct {
+	File     string       `json:"file"`
+	Headings []TOCHeading `json:"headings"`
+}
+
+func rpcTOC(ws *workspace.Workspace, raw json.RawMessage) (interface{}, error) {
+	var params struct {
+		File string `json:"file"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if err := requireParam("file", params.File); err != nil {
+		return nil, err
+	}
+
+	headings, err := fileTOC(ws, params.File)
+	if err != nil {
+		return nil, err
+	}
+	return rpcTOCResult{File: params.File, Headings: headings}, nil
+}
+
+type rpcCaptureResult struct {
+	Destination string             `json:"destination"`
+	Content     string             `json:"content"`
+	Cursor      *template.Position `json:"cursor,omitempty"`
+}
+
+func rpcCapture(ws *workspace.Workspace, raw json.RawMessage) (interface{}, error) {
+	var params struct {
+		Content     string `json:"content"`
+		Template    string `json:"template"`
+		Destination string `json:"destination"`
+		NoVerify    bool   `json:"no_verify"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(params.Content)
+	destination := params.Destination
+	refileMode := ""
+	newFile := ""
+	var cursor *template.Position
+
+	if params.Template != "" {
+		tm := template.NewManager(ws)
+		t, err := tm.Get(params.Template)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := tm.Render(t, content)
+		if err != nil {
+			return nil, err
+		}
+		content, cursor = template.ExtractCursor(rendered)
+		if destination == "" {
+			destination, newFile, err = templateDestination(ws, tm, t)
+			if err != nil {
+				return nil, err
+			}
+		}
+		refileMode = t.RefileMode
+	}
+	if err := requireParam("content", content); err != nil {
+		return nil, err
+	}
+	if destination == "" {
+		destination = "inbox.md"
+	}
+
+	hookManager := hooks.NewManager(ws)
+	if !params.NoVerify {
+		result, err := hookManager.Execute(&hooks.HookContext{
+			Type:         hooks.PreCapture,
+			Workspace:    ws,
+			Content:      content,
+			TemplateName: params.Template,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("pre-capture hook: %w", err)
+		}
+		if result.Aborted {
+			return nil, fmt.Errorf("pre-capture hook aborted operation")
+		}
+		content = result.Content
+	}
+
+	var destinationPath string
+	var before []byte
+	var snapshot *hooks.Snapshot
+	if strings.Contains(destination, "#") {
+		filePath := captureFilePath(ws, strings.SplitN(destination, "#", 2)[0])
+		before = []byte(newFile)
+		if newFile == "" {
+			before, _ = storage.ReadFile(filePath)
+		}
+		if !params.NoVerify {
+			snapshot = postHookSnapshot(hookManager, hooks.PostCapture, ws, filePath)
+		}
+		if err := refileContentToDestination(ws, content, destination, refileMode, newFile); err != nil {
+			return nil, err
+		}
+		recordCapture(ws, filePath, before, content, params.Template, destination)
+		destinationPath = destination
+	} else {
+		destinationPath = cmdutil.ResolveWorkspaceRelativePath(ws, destination)
+		if destination == "inbox.md" {
+			destinationPath = ws.InboxPath
+		}
+		if !params.NoVerify {
+			snapshot = postHookSnapshot(hookManager, hooks.PostCapture, ws, destinationPath)
+		}
+		if newFile != "" {
+			if err := cmdutil.WriteFileContent(destinationPath, []byte(newFile)); err != nil {
+				return nil, err
+			}
+		}
+		before, _ = storage.ReadFile(destinationPath)
+		if err := ws.AppendToFile(destinationPath, content); err != nil {
+			return nil, err
+		}
+		recordCapture(ws, destinationPath, before, content, params.Template, destination)
+	}
+
+	if !params.NoVerify {
+		_, _ = hookManager.Execute(&hooks.HookContext{
+			Type:         hooks.PostCapture,
+			Workspace:    ws,
+			Content:      content,
+			TemplateName: params.Template,
+			SourceFile:   destinationPath,
+			Diff:         snapshot.Diff(),
+		})
+	}
+
+	return rpcCaptureResult{Destination: destination, Content: content, Cursor: cursor}, nil
+}
+
+type rpcRefileResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Heading     string `json:"heading"`
+}
+
+func rpcRefile(ws *workspace.Workspace, raw json.RawMessage) (interface{}, error) {
+	var params struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		Prepend     bool   `json:"prepend"`
+		NoVerify    bool   `json:"no_verify"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if err := requireParam("source", params.Source); err != nil {
+		return nil, err
+	}
+	if err := requireParam("destination", params.Destination); err != nil {
+		return nil, err
+	}
+
+	sourcePath, err := markdown.ParsePath(params.Source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: source: %v", errInvalidParams, err)
+	}
+	destPath, err := markdown.ParsePath(params.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("%w: destination: %v", errInvalidParams, err)
+	}
+
+	var heading string
+	change := mutation{Hook: "refile", Source: params.Source, Dest: params.Destination, NoVerify: params.NoVerify}
+	err = change.run(nil, ws, func() error {
+		subtree, err := ExtractSubtree(ws, sourcePath)
+		if err != nil {
+			return err
+		}
+		dest, err := ResolveDestination(ws, destPath, params.Prepend)
+		if err != nil {
+			return err
+		}
+		heading = subtree.Heading
+		return performRefile(ws, sourcePath, subtree, dest, TransformSubtreeLevel(subtree, dest.TargetLevel))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rpcRefileResult{Source: params.Source, Destination: params.Destination, Heading: heading}, nil
+}
+
+type rpcCompletion struct {
+	Selector string `json:"selector"`
+	Heading  string `json:"heading,omitempty"`
+	Level    int    `json:"level,omitempty"`
+}
+
+type rpcCompleteResult struct {
+	Completions []rpcCompletion `json:"completions"`
+	Truncated   bool            `json:"truncated,omitempty"`
+}
+
+// rpcSelectorComplete completes file names until the prefix contains '#',
+// then headings within that file
+func rpcSelectorComplete(ws *workspace.Workspace, raw json.RawMessage) (interface{}, error) {
+	var params struct {
+		Prefix string `json:"prefix"`
+		Limit  int    `json:"limit"`
+	}
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	result := rpcCompleteResult{Completions: []rpcCompletion{}}
+	add := func(completion rpcCompletion) bool {
+		if len(result.Completions) == params.Limit {
+			result.Truncated = true
+			return false
+		}
+		result.Completions = append(result.Completions, completion)
+		return true
+	}
+
+	file, query, hasHash := strings.Cut(params.Prefix, "#")
+	if !hasHash {
+		files, err := scanWorkspaceMarkdownFiles(ws)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			f = filepath.ToSlash(f)
+			if markdown.HasPrefixFold(f, params.Prefix) && !add(rpcCompletion{Selector: f + "#"}) {
+				break
+			}
+		}
+		return result, nil
+	}
+
+	if _, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(ws, file)); err != nil {
+		return nil, fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, file)
+	}
+	cache := loadHeadingCache(ws)
+	headings := cache.headings(ws, file, time.Now().Add(completionBudget))
+	cache.save()
+	query = strings.TrimLeft(query, "/")
+	for _, heading := range headings {
+		path := strings.TrimPrefix(heading.Selector, file+"#")
+		if !markdown.ContainsFold(path, query) {
+			continue
+		}
+		if !add(rpcCompletion{Selector: heading.Selector, Heading: heading.Heading, Level: heading.Level}) {
+			break
+		}
+	}
+	return result, nil
+}
@@ -1,14 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
 	"github.com/johncoder/jot/internal/eval"
 	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/safemode"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -21,6 +23,9 @@ var evalListApproved bool
 var evalApproveDocument bool
 var evalRevokeDocument bool
 var evalNoVerify bool
+var evalParams []string
+var evalProvenance bool
+var evalJobs int
 
 var evalCmd = &cobra.Command{
 	Use:   "eval [file] [block_name]",
@@ -43,6 +48,11 @@ Core Parameters:
   cwd="/tmp"            Working directory for execution
   env="VAR=value"       Environment variables (comma-separated)
   args="--verbose"      Additional arguments to interpreter
+  depends="setup"       Blocks that must finish first when running with --jobs
+  session="db"          Blocks sharing a session run one at a time, in order
+  params="env,region=us-east-1"
+                        Parameters, prompted for (or given with --param) and
+                        passed to the block as environment variables
 
 Result Parameters:
   results="output"      Capture stdout/stderr (default)
@@ -63,8 +73,13 @@ Examples:
   jot eval example.md hello_python       # Execute specific block (if approved)
   jot eval example.md hello_python --approve --mode hash  # Approve block (doesn't execute)
   jot eval example.md --all              # Execute all approved blocks
+  jot eval example.md deploy --param env=prod   # Supply a block parameter
   jot eval example.md --approve-document --mode always    # Approve entire document
-  jot eval --list-approved               # List all approved blocks`,
+  jot eval --list-approved               # List all approved blocks
+  jot eval example.md --all --jobs 4     # Run up to 4 independent blocks at once
+  jot eval example.md --all --provenance # Record when and how results were produced
+  jot eval status example.md             # Blocks changed since their recorded results
+  jot eval doctor                        # Check configured interpreters are installed`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmdutil.StartCommand(cmd)
 
@@ -77,7 +92,7 @@ Examples:
 		}
 
 		if len(args) == 0 {
-			return ctx.HandleError(fmt.Errorf("please specify a markdown file"))
+			return ctx.HandleUsage(fmt.Errorf("please specify a markdown file"))
 		}
 
 		// Get workspace for file path resolution
@@ -101,7 +116,7 @@ Examples:
 
 		if evalRevoke {
 			if len(args) < 2 {
-				return ctx.HandleError(fmt.Errorf("please specify a block name to revoke"))
+				return ctx.HandleUsage(fmt.Errorf("please specify a block name to revoke"))
 			}
 			if ctx.IsJSONOutput() {
 				return revokeApprovalJSON(ctx, resolvedFilename, args[1])
@@ -133,7 +148,7 @@ Examples:
 		// Handle approval workflow
 		if evalApprove {
 			if blockName == "" {
-				return ctx.HandleError(fmt.Errorf("please specify a block name to approve"))
+				return ctx.HandleUsage(fmt.Errorf("please specify a block name to approve"))
 			}
 			if ctx.IsJSONOutput() {
 				return approveBlockJSON(ctx, resolvedFilename, blockName, evalMode)
@@ -141,6 +156,22 @@ Examples:
 			return approveBlock(resolvedFilename, blockName, evalMode)
 		}
 
+		if blockName == "" && !evalAll {
+			return ctx.HandleUsage(fmt.Errorf("please specify a block name or use --all to execute all blocks"))
+		}
+
+		if evalJobs < 1 {
+			return ctx.HandleValidation("jobs", fmt.Sprint(evalJobs), fmt.Errorf("must be at least 1"))
+		}
+		if err := safemode.Check("code blocks"); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		values, err := evalParamValues(ctx, resolvedFilename, blockName)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
 		// Execute blocks
 		var results []*eval.EvalResult
 
@@ -151,7 +182,6 @@ Examples:
 				Type:        hooks.PreEval,
 				Workspace:   ws,
 				SourceFile:  resolvedFilename,
-				Timeout:     30 * time.Second,
 				AllowBypass: evalNoVerify,
 			}
 
@@ -167,12 +197,10 @@ Examples:
 
 		if blockName != "" {
 			// Execute specific block by name
-			results, err = eval.ExecuteEvaluableBlockByName(resolvedFilename, blockName)
-		} else if evalAll {
-			// Execute all blocks
-			results, err = eval.ExecuteEvaluableBlocks(resolvedFilename)
+			results, err = eval.ExecuteEvaluableBlockByName(resolvedFilename, blockName, values)
 		} else {
-			return ctx.HandleError(fmt.Errorf("please specify a block name or use --all to execute all blocks"))
+			// Execute all blocks
+			results, err = eval.ExecuteEvaluableBlocks(resolvedFilename, values, evalJobs)
 		}
 
 		if err != nil {
@@ -186,7 +214,6 @@ Examples:
 				Type:        hooks.PostEval,
 				Workspace:   ws,
 				SourceFile:  resolvedFilename,
-				Timeout:     30 * time.Second,
 				AllowBypass: evalNoVerify,
 			}
 
@@ -207,7 +234,7 @@ Examples:
 		for _, result := range results {
 			if result.Err != nil && strings.Contains(result.Err.Error(), "requires approval") {
 				hasApprovalErrors = true
-				fmt.Printf("⚠ %s\n", result.Err.Error())
+				cmdutil.Printf("⚠ %s\n", result.Err.Error())
 			}
 		}
 
@@ -216,7 +243,7 @@ Examples:
 		}
 
 		// Update results in markdown
-		err = eval.UpdateMarkdownWithResults(resolvedFilename, results)
+		err = eval.UpdateMarkdownWithResults(resolvedFilename, results, evalProvenance)
 		if err != nil {
 			return fmt.Errorf("error updating results in %s: %w", filename, err)
 		}
@@ -241,6 +268,197 @@ Examples:
 	},
 }
 
+// evalParamValues gathers the parameters of the approved blocks about to
+// run: those given with --param, then prompts or defaults for the rest
+func evalParamValues(ctx *cmdutil.CommandContext, filename, blockName string) (map[string]string, error) {
+	values := make(map[string]string, len(evalParams))
+	for _, set := range evalParams {
+		name, value, ok := strings.Cut(set, "=")
+		if !ok || name == "" {
+			return nil, cmdutil.NewValidationError("param", set, fmt.Errorf("use NAME=VALUE"))
+		}
+		values[name] = value
+	}
+
+	blocks, err := eval.ParseMarkdownForEvalBlocks(filename)
+	if err != nil {
+		return nil, err
+	}
+	sm, err := eval.NewSecurityManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize security manager: %w", err)
+	}
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var running []*eval.CodeBlock
+	declared := map[string]bool{}
+	for _, b := range blocks {
+		if b.Eval == nil || (blockName != "" && b.Eval.GetName() != blockName) {
+			continue
+		}
+		// Blocks that will not run are not worth prompting for
+		if approved, err := sm.CheckApproval(absPath, b); err != nil || !approved {
+			continue
+		}
+		params, err := b.DeclaredParams()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range params {
+			declared[p.Name] = true
+		}
+		running = append(running, b)
+	}
+	for name := range values {
+		if !declared[name] {
+			return nil, cmdutil.NewValidationError("param", name, fmt.Errorf("no approved block to run declares parameter %s", name))
+		}
+	}
+
+	interactive := !ctx.IsJSONOutput() && isTerminal(os.Stdin)
+	reader := bufio.NewReader(os.Stdin)
+	for _, b := range running {
+		params, _ := b.DeclaredParams()
+		if err := fillParams(fmt.Sprintf("block '%s'", b.Eval.GetName()), params, "param", values, interactive, reader); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+var evalStatusCmd = &cobra.Command{
+	Use:   "status FILE",
+	Short: "List blocks whose code changed since their recorded results",
+	Long: `List blocks whose code changed since their recorded results.
+
+Results written with --provenance (or for blocks with provenance="true")
+record a hash of the code that produced them. Status compares each block
+with its recorded hash:
+
+  current     the result came from the block's current code
+  changed     the block changed since its result was produced
+  unrecorded  the block has no result with provenance
+
+Examples:
+  jot eval status example.md
+  jot eval status example.md --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		filename := cmdutil.ResolvePath(ws, args[0], noWorkspace)
+
+		statuses, err := eval.Status(filename)
+		if err != nil {
+			return ctx.HandleFileOperation("read", args[0], err)
+		}
+
+		changed := 0
+		for _, status := range statuses {
+			if status.State == eval.StatusChanged {
+				changed++
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			if statuses == nil {
+				statuses = []eval.BlockStatus{}
+			}
+			return cmdutil.OutputJSON(EvalStatusResponse{
+				File:     args[0],
+				Blocks:   statuses,
+				Changed:  changed,
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(statuses) == 0 {
+			fmt.Printf("No named eval blocks in %s.\n", args[0])
+			return nil
+		}
+		for _, status := range statuses {
+			switch status.State {
+			case eval.StatusCurrent:
+				cmdutil.Printf("  ✓ %-20s current, ran %s\n", status.Name, status.Provenance.Ran.Local().Format("2006-01-02 15:04"))
+			case eval.StatusChanged:
+				cmdutil.Printf("  ✗ %-20s changed since its result of %s\n", status.Name, status.Provenance.Ran.Local().Format("2006-01-02 15:04"))
+			default:
+				cmdutil.Printf("  - %-20s no recorded result\n", status.Name)
+			}
+		}
+		fmt.Println()
+		if changed > 0 {
+			cmdutil.ShowWarning("⚠ %d block%s changed since last run; rerun with --provenance to refresh", changed, pluralize(changed))
+		} else {
+			cmdutil.ShowSuccess("✓ No block changed since its recorded result")
+		}
+		return nil
+	},
+}
+
+var evalDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configured interpreters are installed",
+	Long: `Check the configured interpreters are installed.
+
+Interpreters are configured by language under "eval" in .jot/config.json,
+or in ~/.jotrc for every workspace, and are used instead of PATH
+evaluators and built-ins:
+
+  "eval": {"interpreters": {"python": "uv run python", "js": "deno run -"}}
+
+Examples:
+  jot eval doctor
+  jot eval doctor --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		checks := currentEvaluatorManager().CheckInterpreters()
+		missing := 0
+		for _, check := range checks {
+			if check.Error != "" {
+				missing++
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(EvalDoctorResponse{
+				Interpreters: checks,
+				Missing:      missing,
+				Metadata:     cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(checks) == 0 {
+			fmt.Println("No interpreters are configured; eval uses PATH evaluators and built-ins.")
+			return nil
+		}
+		for _, check := range checks {
+			if check.Error != "" {
+				cmdutil.Printf("  ✗ %-12s %s (%s): %s\n", check.Language, check.Command, check.Source, check.Error)
+			} else {
+				cmdutil.Printf("  ✓ %-12s %s (%s) → %s\n", check.Language, check.Command, check.Source, check.Path)
+			}
+		}
+		fmt.Println()
+		if missing > 0 {
+			cmdutil.ShowWarning("⚠ %d configured interpreter%s not installed", missing, pluralize(missing))
+		} else {
+			cmdutil.ShowSuccess("✓ All %d configured interpreter%s found", len(checks), pluralize(len(checks)))
+		}
+		return nil
+	},
+}
+
 func listBlocks(filename string) error {
 	return eval.ListEvalBlocks(filename)
 }
@@ -262,7 +480,7 @@ func listApprovedBlocks() error {
 	if len(docApprovals) > 0 {
 		fmt.Println("Approved documents:")
 		for _, approval := range docApprovals {
-			fmt.Printf("  ✓ %s (%s mode)\n",
+			cmdutil.Printf("  ✓ %s (%s mode)\n",
 				approval.FilePath, approval.Mode)
 		}
 		fmt.Println()
@@ -271,7 +489,7 @@ func listApprovedBlocks() error {
 	if len(approvals) > 0 {
 		fmt.Println("Approved individual blocks:")
 		for _, approval := range approvals {
-			fmt.Printf("  ✓ %s:%s (%s mode)\n",
+			cmdutil.Printf("  ✓ %s:%s (%s mode)\n",
 				approval.FilePath, approval.BlockName, approval.Mode)
 		}
 	}
@@ -333,11 +551,11 @@ func approveBlock(filename, blockName, mode string) error {
 
 	// Show the code block for approval
 	fmt.Printf("Approving code block '%s':\n", blockName)
-	fmt.Println("────────────────────────────────────────")
+	cmdutil.Println("────────────────────────────────────────")
 	for _, line := range targetBlock.Code {
 		fmt.Println(line)
 	}
-	fmt.Println("────────────────────────────────────────")
+	cmdutil.Println("────────────────────────────────────────")
 
 	// Confirm approval
 	confirmed, err := cmdutil.ConfirmOperation(fmt.Sprintf("Approve this block with %s mode?", approvalMode))
@@ -406,14 +624,14 @@ func approveDocument(filename, mode string) error {
 
 	// Show the blocks for approval
 	fmt.Printf("Approving entire document '%s' (%d blocks):\n", filename, evalBlocks)
-	fmt.Println("────────────────────────────────────────")
+	cmdutil.Println("────────────────────────────────────────")
 	for _, block := range blocks {
 		if block.Eval != nil && block.Eval.Params["name"] != "" {
 			blockName := block.Eval.Params["name"]
 			fmt.Printf("Block: %s (lines %d-%d) %s\n", blockName, block.StartLine, block.EndLine, block.Lang)
 		}
 	}
-	fmt.Println("────────────────────────────────────────")
+	cmdutil.Println("────────────────────────────────────────")
 
 	// Confirm approval
 	confirmed, err := cmdutil.ConfirmOperation(fmt.Sprintf("Approve entire document with %s mode?", approvalMode))
@@ -442,7 +660,7 @@ func approveDocument(filename, mode string) error {
 		return fmt.Errorf("failed to approve document: %w", err)
 	}
 
-	fmt.Printf("✓ Document '%s' approved with %s mode (%d blocks).\n", filename, approvalMode, evalBlocks)
+	cmdutil.Printf("✓ Document '%s' approved with %s mode (%d blocks).\n", filename, approvalMode, evalBlocks)
 	return nil
 }
 
@@ -462,7 +680,7 @@ func revokeDocumentApproval(filename string) error {
 		return fmt.Errorf("failed to revoke document approval: %w", err)
 	}
 
-	fmt.Printf("✓ Revoked document approval for %s\n", filename)
+	cmdutil.Printf("✓ Revoked document approval for %s\n", filename)
 	return nil
 }
 
@@ -477,14 +695,17 @@ type EvalResponse struct {
 }
 
 type EvalResult struct {
-	BlockName string `json:"block_name"`
-	Language  string `json:"language"`
-	Code      string `json:"code"`
-	Output    string `json:"output,omitempty"`
-	Error     string `json:"error,omitempty"`
-	Success   bool   `json:"success"`
-	StartLine int    `json:"start_line"`
-	EndLine   int    `json:"end_line"`
+	BlockName string            `json:"block_name"`
+	Language  string            `json:"language"`
+	Code      string            `json:"code"`
+	Output    string            `json:"output,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+	ExitCode  int               `json:"exit_code"`
+	Duration  int64             `json:"duration_ms"`
+	Success   bool              `json:"success"`
+	StartLine int               `json:"start_line"`
+	EndLine   int               `json:"end_line"`
 }
 
 type EvalBlock struct {
@@ -496,6 +717,21 @@ type EvalBlock struct {
 	ApprovalMode string `json:"approval_mode,omitempty"`
 }
 
+// EvalStatusResponse represents the JSON response for eval status
+type EvalStatusResponse struct {
+	File     string               `json:"file"`
+	Blocks   []eval.BlockStatus   `json:"blocks"`
+	Changed  int                  `json:"changed"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// EvalDoctorResponse represents the JSON response for eval doctor
+type EvalDoctorResponse struct {
+	Interpreters []eval.InterpreterCheck `json:"interpreters"`
+	Missing      int                     `json:"missing"`
+	Metadata     cmdutil.JSONMetadata    `json:"metadata"`
+}
+
 type EvalApproval struct {
 	Type      string `json:"type"` // "block" or "document"
 	FilePath  string `json:"file_path"`
@@ -520,6 +756,12 @@ func init() {
 	evalCmd.Flags().BoolVar(&evalRevokeDocument, "revoke-document", false, "Revoke document approval")
 	evalCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
 	evalCmd.Flags().BoolVar(&evalNoVerify, "no-verify", false, "Skip hooks verification")
+	evalCmd.Flags().StringArrayVar(&evalParams, "param", nil, "Set a block parameter, NAME=VALUE (repeatable)")
+	evalCmd.Flags().IntVarP(&evalJobs, "jobs", "j", 1, "Run up to N blocks at once with --all")
+	evalCmd.Flags().BoolVar(&evalProvenance, "provenance", false, "Record when and how each result was produced in a comment above it")
+	evalStatusCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
+	evalCmd.AddCommand(evalStatusCmd)
+	evalCmd.AddCommand(evalDoctorCmd)
 }
 
 // JSON output functions for eval command
@@ -677,6 +919,9 @@ func outputExecutionResultsJSON(ctx *cmdutil.CommandContext, filename, blockName
 			Code:      code,
 			Output:    output,
 			Error:     errorMsg,
+			Params:    result.Params,
+			ExitCode:  result.ExitCode,
+			Duration:  result.Duration.Milliseconds(),
 			Success:   success,
 			StartLine: startLine,
 			EndLine:   endLine,
@@ -54,19 +54,31 @@ func init() {
 	// No additional flags needed for now
 }
 
+// currentEvaluatorManager returns an evaluator manager with the current
+// workspace's context and configured interpreters, when there is a workspace
+func currentEvaluatorManager() *eval.EvaluatorManager {
+	if ws, err := workspace.GetWorkspaceContext(false); err == nil && ws != nil {
+		return eval.NewEvaluatorManagerWithWorkspace(ws)
+	}
+	return eval.NewEvaluatorManager()
+}
+
 func listEvaluators() error {
-	manager := eval.NewEvaluatorManager()
+	manager := currentEvaluatorManager()
 	evaluators, err := manager.ListEvaluators()
 	if err != nil {
 		return fmt.Errorf("failed to list evaluators: %w", err)
 	}
 
-	// Separate built-in and PATH evaluators
+	// Separate configured, built-in and PATH evaluators
+	var configured []*eval.EvaluatorInfo
 	var builtins []*eval.EvaluatorInfo
 	var pathEvaluators []*eval.EvaluatorInfo
 
 	for _, evaluator := range evaluators {
 		switch evaluator.Type {
+		case "configured":
+			configured = append(configured, evaluator)
 		case "built-in":
 			builtins = append(builtins, evaluator)
 		case "path":
@@ -74,6 +86,15 @@ func listEvaluators() error {
 		}
 	}
 
+	// Display configured interpreters, which take precedence
+	if len(configured) > 0 {
+		fmt.Println("Configured interpreters:")
+		for _, evaluator := range configured {
+			fmt.Printf("  %-12s (%s)\n", evaluator.Language, evaluator.Command)
+		}
+		fmt.Println()
+	}
+
 	// Display built-in evaluators
 	if len(builtins) > 0 {
 		fmt.Println("Built-in evaluators:")
@@ -103,7 +124,7 @@ func listEvaluators() error {
 }
 
 func listEvaluatorsJSON(ctx *cmdutil.CommandContext) error {
-	manager := eval.NewEvaluatorManager()
+	manager := currentEvaluatorManager()
 	evaluators, err := manager.ListEvaluators()
 	if err != nil {
 		return ctx.HandleOperationError("list evaluators", err)
@@ -113,8 +134,9 @@ func listEvaluatorsJSON(ctx *cmdutil.CommandContext) error {
 	response := map[string]interface{}{
 		"operation": "list_evaluators",
 		"evaluators": map[string]interface{}{
-			"built_in": []map[string]interface{}{},
-			"path":     []map[string]interface{}{},
+			"configured": []map[string]interface{}{},
+			"built_in":   []map[string]interface{}{},
+			"path":       []map[string]interface{}{},
 		},
 		"metadata": cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
 	}
@@ -126,11 +148,16 @@ func listEvaluatorsJSON(ctx *cmdutil.CommandContext) error {
 			"command":  evaluator.Command,
 		}
 
-		if evaluator.Type == "path" {
+		if evaluator.Type == "path" || evaluator.Type == "configured" {
 			evalInfo["path"] = evaluator.Path
 		}
 
 		switch evaluator.Type {
+		case "configured":
+			response["evaluators"].(map[string]interface{})["configured"] = append(
+				response["evaluators"].(map[string]interface{})["configured"].([]map[string]interface{}),
+				evalInfo,
+			)
 		case "built-in":
 			evalInfo["interpreter"] = getInterpreterName(evaluator.Language)
 			response["evaluators"].(map[string]interface{})["built_in"] = append(
@@ -149,13 +176,7 @@ func listEvaluatorsJSON(ctx *cmdutil.CommandContext) error {
 }
 
 func runBuiltinEvaluator(ctx *cmdutil.CommandContext, language string) error {
-	// Try to get workspace context for enhanced features
-	var manager *eval.EvaluatorManager
-	if ws, err := workspace.GetWorkspaceContext(false); err == nil && ws != nil {
-		manager = eval.NewEvaluatorManagerWithWorkspace(ws)
-	} else {
-		manager = eval.NewEvaluatorManager()
-	}
+	manager := currentEvaluatorManager()
 
 	// Check if this is a built-in evaluator
 	if !isBuiltinLanguage(language) {
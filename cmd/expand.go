@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var expandCmd = &cobra.Command{
+	Use:   "expand PATTERN...",
+	Short: "Expand file and heading patterns into selectors",
+	Long: `Expand selector patterns into the selectors they match, one per line,
+for scripts and batch operations.
+
+The file part may use glob patterns: '*' and '?' match within a directory,
+and '**' matches any number of directories. The heading part may end with a
+glob, as in refile, to match the headings under the path before it; without
+a glob, every heading the path matches is listed. A pattern without '#'
+lists the matching files.
+
+Selectors are printed in full, from the top-level heading, in file and
+document order, each once.
+
+Exits with status 1 when nothing matches.
+
+Examples:
+  jot expand "lib/**/*.md#Project*"
+  jot expand "journal/2024-*.md#standup"
+  jot expand "notes/*.md"
+  jot expand "lib/**/*.md#todo" | jot peek --stdin`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		var files []string
+		selectors := []ExpandedSelector{}
+		seen := map[string]bool{}
+		for _, pattern := range args {
+			expanded, err := expandPattern(ws, pattern, &files)
+			if err != nil {
+				return ctx.HandleValidation("pattern", pattern, err)
+			}
+			for _, selector := range expanded {
+				if !seen[selector.Selector] {
+					seen[selector.Selector] = true
+					selectors = append(selectors, selector)
+				}
+			}
+		}
+		if len(selectors) == 0 {
+			return ctx.HandleError(fmt.Errorf("nothing matches %s", strings.Join(args, " ")))
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(ExpandResponse{
+				Operation: "expand",
+				Patterns:  args,
+				Selectors: selectors,
+				Count:     len(selectors),
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+		for _, selector := range selectors {
+			fmt.Println(selector.Selector)
+		}
+		return nil
+	},
+}
+
+// ExpandResponse is the JSON response for expand
+type ExpandResponse struct {
+	Operation string               `json:"operation"`
+	Patterns  []string             `json:"patterns"`
+	Selectors []ExpandedSelector   `json:"selectors"`
+	Count     int                  `json:"count"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// ExpandedSelector is one selector a pattern expanded to. Heading, level and
+// line are empty for a whole file.
+type ExpandedSelector struct {
+	Selector string `json:"selector"`
+	File     string `json:"file"`
+	Heading  string `json:"heading,omitempty"`
+	Level    int    `json:"level,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// expandPattern returns the selectors matching pattern. files holds the
+// workspace's markdown files, scanned on first use and shared between
+// patterns. A file named without a glob must exist, but may have no match.
+func expandPattern(ws *workspace.Workspace, pattern string, files *[]string) ([]ExpandedSelector, error) {
+	pattern, _ = ws.ExpandAlias(pattern)
+	filePattern, headings, hasHeadings := strings.Cut(pattern, "#")
+	filePattern = filepath.ToSlash(strings.TrimSpace(filePattern))
+	if filePattern == "" {
+		return nil, fmt.Errorf("file pattern cannot be empty")
+	}
+
+	var matched []string
+	if markdown.IsGlobSegment(filePattern) {
+		if _, err := path.Match(filePattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern: %w", err)
+		}
+		if *files == nil {
+			scanned, err := scanWorkspaceMarkdownFiles(ws)
+			if err != nil {
+				return nil, err
+			}
+			for i := range scanned {
+				scanned[i] = filepath.ToSlash(scanned[i])
+			}
+			sort.Strings(scanned)
+			*files = scanned
+		}
+		for _, file := range *files {
+			if matchFilePattern(filePattern, file) {
+				matched = append(matched, file)
+			}
+		}
+	} else {
+		if _, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(ws, filePattern)); err != nil {
+			return nil, fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, filePattern)
+		}
+		matched = []string{filePattern}
+	}
+
+	var selectors []ExpandedSelector
+	for _, file := range matched {
+		if !hasHeadings || strings.Trim(headings, "/ ") == "" {
+			selectors = append(selectors, ExpandedSelector{Selector: file, File: file})
+			continue
+		}
+		found, err := expandHeadings(ws, file, headings)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, found...)
+	}
+	return selectors, nil
+}
+
+// expandHeadings returns a selector for each subtree of file that the
+// heading pattern matches
+func expandHeadings(ws *workspace.Workspace, file, headings string) ([]ExpandedSelector, error) {
+	headingPath, err := markdown.ParsePath(file + "#" + headings)
+	if err != nil {
+		return nil, err
+	}
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", file, err)
+	}
+	doc := markdown.ParseDocument(content)
+
+	var subtrees []*markdown.Subtree
+	if markdown.IsGlobSegment(headingPath.Segments[len(headingPath.Segments)-1]) {
+		// A file without the parent heading simply has no matches
+		subtrees, err = markdown.FindGlobMatches(doc, content, headingPath)
+		if err != nil && !errors.Is(err, markdown.ErrNoMatch) {
+			return nil, err
+		}
+	} else {
+		subtrees = markdown.FindSubtreeMatches(doc, content, headingPath)
+	}
+
+	all := markdown.FindAllHeadings(doc, content)
+	selectors := make([]ExpandedSelector, 0, len(subtrees))
+	for _, subtree := range subtrees {
+		line := markdown.CalculateLineNumber(content, subtree.StartOffset)
+		selector := ExpandedSelector{
+			Selector: file + "#" + subtree.Heading,
+			File:     file,
+			Heading:  subtree.Heading,
+			Level:    subtree.Level,
+			Line:     line,
+		}
+		for _, heading := range all {
+			if markdown.CalculateLineNumber(content, heading.Offset) == line {
+				selector.Selector = file + "#" + strings.Join(heading.Path, "/")
+				break
+			}
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// matchFilePattern reports whether a slash-separated file path matches a
+// glob pattern, where a "**" segment matches any number of directories
+func matchFilePattern(pattern, file string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], file[0]); !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
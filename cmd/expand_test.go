@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestMatchFilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"*.md", "work.md", true},
+		{"*.md", "lib/work.md", false},
+		{"lib/*.md", "lib/work.md", true},
+		{"lib/**/*.md", "lib/work.md", true},
+		{"lib/**/*.md", "lib/a/b/work.md", true},
+		{"lib/**/*.md", "other/work.md", false},
+		{"**/2024-*.md", "journal/2024-06-01.md", true},
+		{"**", "lib/a/work.md", true},
+		{"lib/**", "lib", true},
+		{"lib/?.md", "lib/ab.md", false},
+	}
+	for _, tt := range tests {
+		if got := matchFilePattern(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("matchFilePattern(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestExpandPattern(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "inbox.md"),
+		LibDir:    filepath.Join(root, "lib"),
+		Config:    &workspace.WorkspaceConfig{Aliases: map[string]string{"acme": "lib/a/acme.md#projects"}},
+	}
+	notes := map[string]string{
+		"lib/a/acme.md": "# Projects\n\n## Project Alpha\n\n## Project Beta\n\n## Other\n",
+		"lib/tools.md":  "# Tools\n\n## Project Gamma\n",
+		"work.md":       "# Work\n",
+	}
+	for file, content := range notes {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"lib/**/*.md", []string{"lib/a/acme.md", "lib/tools.md"}},
+		{"lib/**/*.md#projects/project*", []string{"lib/a/acme.md#Projects/Project Alpha", "lib/a/acme.md#Projects/Project Beta"}},
+		{"lib/**/*.md#project*", []string{"lib/a/acme.md#Projects", "lib/tools.md#Tools/Project Gamma"}},
+		{"lib/*.md#gamma", []string{"lib/tools.md#Tools/Project Gamma"}},
+		{"@acme/*beta", []string{"lib/a/acme.md#Projects/Project Beta"}},
+		{"work.md#nothing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			var files []string
+			selectors, err := expandPattern(ws, tt.pattern, &files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range selectors {
+				got = append(got, s.Selector)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %q, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	var files []string
+	if _, err := expandPattern(ws, "missing.md#x", &files); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/export"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/redact"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export SELECTOR --format FORMAT",
+	Short: "Export a file or subtree to another format",
+	Long: `Export a markdown file or subtree to a presentation or publishing format.
+
+The selector may be a whole file ("talk.md") or a subtree ("talk.md#outline").
+Output is written to stdout unless --output is given.
+
+Formats:
+  slides    Each child heading becomes a slide. The selected heading (or the
+            single top-level heading of a file) becomes the title slide.
+            Engines: marp (markdown, default), reveal (standalone HTML)
+  html      A standalone HTML page. Mermaid blocks are rendered in the browser
+            with mermaid.js and PlantUML blocks are rendered to inline SVG
+            when the plantuml command is installed. Use --no-diagrams to keep
+            diagram blocks as labelled source. Print the page to get a PDF.
+
+Subtrees tagged :private: or with a "private: true" line are left out, and
+text between <!-- private --> and <!-- /private --> is replaced with
+[redacted]. Use --include-private to keep them.
+
+Examples:
+  jot export "talks.md#kubecon" --format slides
+  jot export "talks.md#kubecon" --format slides -o kubecon.md
+  jot export "talks.md#kubecon" --format slides --engine reveal -o kubecon.html
+  jot export "design.md#architecture" --format html -o architecture.html
+  jot export "design.md" --format html --no-diagrams
+  jot export "team.md" --format html --include-private`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		engine, _ := cmd.Flags().GetString("engine")
+		output, _ := cmd.Flags().GetString("output")
+		noDiagrams, _ := cmd.Flags().GetBool("no-diagrams")
+		includePrivate, _ := cmd.Flags().GetBool("include-private")
+
+		doc, err := loadExportDocument(ws, args[0], noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		var redacted redact.Result
+		if !includePrivate {
+			redacted = redact.Redact(doc.Content)
+			if len(bytes.TrimSpace(redacted.Content)) == 0 && redacted.Subtrees > 0 {
+				return ctx.HandleError(fmt.Errorf("'%s' is marked private; use --include-private to export it", args[0]))
+			}
+			doc.Content = redacted.Content
+		}
+
+		result, err := export.Export(doc, export.Options{
+			Format:     format,
+			Engine:     engine,
+			NoDiagrams: noDiagrams,
+		})
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if output != "" {
+			if err := cmdutil.WriteFileContent(output, []byte(result.Content)); err != nil {
+				return ctx.HandleOperationError("write export", err)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			response := ExportResponse{
+				Operation:     "export",
+				Selector:      args[0],
+				Format:        result.Format,
+				Engine:        result.Engine,
+				OutputPath:    output,
+				SlideCount:    result.SlideCount,
+				DiagramCount:  result.DiagramCount,
+				Warnings:      result.Warnings,
+				Redacted:      redacted.Subtrees,
+				RedactedSpans: redacted.Spans,
+				Metadata:      cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			if output == "" {
+				response.Content = result.Content
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		if redacted.Removed() {
+			fmt.Fprintf(os.Stderr, "Left out %d private subtree%s and %d private span%s (use --include-private to keep them)\n",
+				redacted.Subtrees, pluralize(redacted.Subtrees), redacted.Spans, pluralize(redacted.Spans))
+		}
+
+		if output == "" {
+			fmt.Print(result.Content)
+			return nil
+		}
+
+		cmdutil.ShowSuccess("✓ Exported '%s' as %s to %s", args[0], describeExport(result), output)
+		return nil
+	},
+}
+
+// ExportResponse represents the JSON response for the export command
+type ExportResponse struct {
+	Operation     string               `json:"operation"`
+	Selector      string               `json:"selector"`
+	Format        string               `json:"format"`
+	Engine        string               `json:"engine,omitempty"`
+	OutputPath    string               `json:"output_path,omitempty"`
+	Content       string               `json:"content,omitempty"`
+	SlideCount    int                  `json:"slide_count,omitempty"`
+	DiagramCount  int                  `json:"diagram_count,omitempty"`
+	Warnings      []string             `json:"warnings,omitempty"`
+	Redacted      int                  `json:"redacted,omitempty"`       // Private subtrees left out
+	RedactedSpans int                  `json:"redacted_spans,omitempty"` // Private spans replaced
+	Metadata      cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// loadExportDocument reads a whole file or subtree selector into an export document
+func loadExportDocument(ws *workspace.Workspace, selector string, noWorkspace bool) (*export.Document, error) {
+	if !strings.Contains(selector, "#") {
+		content, err := storage.ReadFile(cmdutil.ResolvePath(ws, selector, noWorkspace))
+		if err != nil {
+			return nil, cmdutil.NewFileError("read", selector, err)
+		}
+		title := strings.TrimSuffix(filepath.Base(selector), filepath.Ext(selector))
+		return &export.Document{Title: title, Source: selector, Content: content}, nil
+	}
+
+	sourcePath, err := markdown.ParsePath(selector)
+	if err != nil {
+		return nil, cmdutil.NewValidationError("selector", selector, err)
+	}
+
+	subtree, err := ExtractSubtreeWithOptions(ws, sourcePath, noWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract subtree: %w", err)
+	}
+
+	return &export.Document{Title: subtree.Heading, Source: selector, Content: subtree.Content}, nil
+}
+
+// describeExport summarizes an export result for human-readable output
+func describeExport(result *export.Result) string {
+	description := result.Format
+	if result.Engine != "" {
+		description += " (" + result.Engine + ")"
+	}
+	if result.SlideCount > 0 {
+		description += fmt.Sprintf(", %d slides", result.SlideCount)
+	}
+	if result.DiagramCount > 0 {
+		description += fmt.Sprintf(", %d diagrams", result.DiagramCount)
+	}
+	return description
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", export.FormatSlides, "Output format ("+strings.Join(export.Formats(), ", ")+")")
+	exportCmd.Flags().String("engine", "", "Rendering engine for the format (slides: marp, reveal)")
+	exportCmd.Flags().StringP("output", "o", "", "Write output to a file instead of stdout")
+	exportCmd.Flags().Bool("no-diagrams", false, "Keep mermaid/plantuml blocks as source instead of rendering them")
+	exportCmd.Flags().Bool("include-private", false, "Keep subtrees and spans marked private")
+	exportCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/export"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/redact"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var exportSiteCmd = &cobra.Command{
+	Use:   "site [PATTERN...] --out DIR",
+	Short: "Export notes as the content of a Hugo or Zola site",
+	Long: `Export notes as the content/ tree of a Hugo or Zola site, to publish
+parts of the workspace as a website.
+
+Each pattern is a file, a selector or a glob, as for 'jot expand'. A file
+becomes one page; a subtree becomes a page of its own, under its file's
+path. Without patterns, every note in lib/ is exported. Paths are slugged,
+and lib/ is the site root.
+
+Every page gets frontmatter: the title from its first heading, the tags
+from that heading and the note's frontmatter, the note's other frontmatter,
+and any name: value lines directly under the heading, which are taken out
+of the text. Links between exported notes are rewritten to site links that
+the generator checks: relref for Hugo, @/ paths for Zola. Links to notes
+left out are kept as they are, with a warning.
+
+Files are written under DIR/content/, which is created; a site's config
+and theme are left alone. Private subtrees and spans are left out, as for
+'jot export', unless --include-private is given.
+
+Examples:
+  jot export site --out ./site
+  jot export site "lib/projects/**/*.md" "lib/blog.md#posts/*" --out ./site
+  jot export site "lib/**/*.md" --out ./blog --generator zola
+  jot export site --out ./site --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		out, _ := cmd.Flags().GetString("out")
+		generator, _ := cmd.Flags().GetString("generator")
+		includePrivate, _ := cmd.Flags().GetBool("include-private")
+		if out == "" {
+			return ctx.HandleUsage(fmt.Errorf("--out is required; give the site directory to write to, such as --out ./site"))
+		}
+
+		base := libBase(ws)
+		if len(args) == 0 {
+			args = []string{base + "/**/*.md"}
+		}
+		pages, redacted, err := sitePages(ws, args, includePrivate)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if len(pages) == 0 {
+			return ctx.HandleError(fmt.Errorf("nothing to export; %s matched no notes", strings.Join(args, " ")))
+		}
+
+		site, err := export.Site(pages, export.SiteOptions{
+			Generator: generator,
+			Title:     filepath.Base(ws.Root),
+			Base:      base,
+		})
+		if err != nil {
+			return ctx.HandleValidation("generator", generator, err)
+		}
+
+		response := ExportSiteResponse{
+			Operation:  "export_site",
+			Generator:  generator,
+			OutputPath: out,
+			Files:      make([]ExportSiteFile, 0, len(site.Files)),
+			Links:      site.Links,
+			Redacted:   redacted,
+			Warnings:   site.Warnings,
+		}
+		for _, file := range site.Files {
+			if err := cmdutil.WriteFileContent(filepath.Join(out, filepath.FromSlash(file.Path)), file.Content); err != nil {
+				return ctx.HandleOperationError("write site", err)
+			}
+			response.Files = append(response.Files, ExportSiteFile{Path: file.Path, Title: file.Title, Source: file.Source})
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		for _, warning := range site.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		if redacted > 0 {
+			fmt.Fprintf(os.Stderr, "Left out %d private subtree%s (use --include-private to keep them)\n", redacted, pluralize(redacted))
+		}
+		cmdutil.ShowSuccess("✓ Exported %d page%s for %s to %s (%d link%s rewritten)",
+			len(pages), pluralize(len(pages)), generator, filepath.Join(out, "content"), site.Links, pluralize(site.Links))
+		return nil
+	},
+}
+
+// ExportSiteResponse is the JSON response for export site
+type ExportSiteResponse struct {
+	Operation  string               `json:"operation"`
+	Generator  string               `json:"generator"`
+	OutputPath string               `json:"output_path"`
+	Files      []ExportSiteFile     `json:"files"`
+	Links      int                  `json:"links"`              // Links rewritten to site paths
+	Redacted   int                  `json:"redacted,omitempty"` // Private subtrees left out
+	Warnings   []string             `json:"warnings,omitempty"`
+	Metadata   cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// ExportSiteFile is one file written under the output directory. Section
+// indexes have no source.
+type ExportSiteFile struct {
+	Path   string `json:"path"`
+	Title  string `json:"title"`
+	Source string `json:"source,omitempty"`
+}
+
+// libBase returns the workspace's lib/ directory relative to its root, with
+// forward slashes
+func libBase(ws *workspace.Workspace) string {
+	base, err := filepath.Rel(ws.Root, ws.LibDir)
+	if err != nil || strings.HasPrefix(base, "..") {
+		return "lib"
+	}
+	return filepath.ToSlash(base)
+}
+
+// sitePages reads the notes and subtrees the patterns expand to, each once,
+// leaving out private content unless includePrivate is set. It returns the
+// pages and the number of private subtrees left out.
+func sitePages(ws *workspace.Workspace, patterns []string, includePrivate bool) ([]export.Page, int, error) {
+	var files []string
+	var selectors []ExpandedSelector
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		expanded, err := expandPattern(ws, pattern, &files)
+		if err != nil {
+			return nil, 0, cmdutil.NewValidationError("pattern", pattern, err)
+		}
+		for _, selector := range expanded {
+			if !seen[selector.Selector] {
+				seen[selector.Selector] = true
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+
+	var pages []export.Page
+	redacted := 0
+	for _, selector := range selectors {
+		path := cmdutil.ResolveWorkspaceRelativePath(ws, selector.File)
+		info, err := storage.Stat(path)
+		if err != nil {
+			return nil, 0, cmdutil.NewFileError("read", selector.File, err)
+		}
+		page := export.Page{Source: selector.File, Modified: info.ModTime()}
+		if selector.Heading == "" {
+			if page.Content, err = storage.ReadFile(path); err != nil {
+				return nil, 0, cmdutil.NewFileError("read", selector.File, err)
+			}
+		} else {
+			headingPath, err := markdown.ParsePath(selector.Selector)
+			if err != nil {
+				return nil, 0, cmdutil.NewValidationError("selector", selector.Selector, err)
+			}
+			subtree, err := ExtractSubtreeWithOptions(ws, headingPath, false)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to extract %s: %w", selector.Selector, err)
+			}
+			page.Heading = subtree.Heading
+			page.Content = subtree.Content
+		}
+
+		if !includePrivate {
+			result := redact.Redact(page.Content)
+			redacted += result.Subtrees
+			// A page that was all private is left out whole
+			if len(bytes.TrimSpace(result.Content)) == 0 && result.Subtrees > 0 {
+				continue
+			}
+			page.Content = result.Content
+		}
+		pages = append(pages, page)
+	}
+	return pages, redacted, nil
+}
+
+func init() {
+	exportSiteCmd.Flags().String("out", "", "Site directory to write content/ into")
+	exportSiteCmd.Flags().String("generator", export.GeneratorHugo, "Site generator ("+strings.Join(export.Generators(), ", ")+")")
+	exportSiteCmd.Flags().Bool("include-private", false, "Keep subtrees and spans marked private")
+	exportCmd.AddCommand(exportSiteCmd)
+}
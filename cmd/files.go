@@ -10,6 +10,7 @@ import (
 	"github.com/johncoder/jot/internal/cmdutil"
 	"github.com/johncoder/jot/internal/config"
 	"github.com/johncoder/jot/internal/fzf"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -38,11 +39,11 @@ Examples:
 		// Validate flag combinations
 		if selectMode && !interactive {
 			err := fmt.Errorf("--select flag requires --interactive mode")
-			return ctx.HandleError(err)
+			return ctx.HandleUsage(err)
 		}
 		if selectMode && edit {
 			err := fmt.Errorf("--select and --edit flags cannot be used together")
-			return ctx.HandleError(err)
+			return ctx.HandleUsage(err)
 		}
 
 		ws, err := getWorkspace(cmd)
@@ -51,7 +52,7 @@ Examples:
 		}
 
 		// Get all markdown files in workspace
-		files, err := findMarkdownFiles(ws.Root)
+		files, err := findMarkdownFiles(ws)
 		if err != nil {
 			err = fmt.Errorf("failed to find files: %w", err)
 			return ctx.HandleError(err)
@@ -89,10 +90,10 @@ Examples:
 }
 
 // findMarkdownFiles recursively finds all .md files in the workspace
-func findMarkdownFiles(root string) ([]string, error) {
+func findMarkdownFiles(ws *workspace.Workspace) ([]string, error) {
 	var files []string
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err := ws.Walk(ws.Root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -119,7 +120,7 @@ func outputFilesJSON(ctx *cmdutil.CommandContext, files []string, ws *workspace.
 	jsonFiles := make([]map[string]interface{}, len(files))
 	for i, file := range files {
 		// Get file info
-		info, err := os.Stat(file)
+		info, err := storage.Stat(file)
 		var fileSize int64
 		var modTime string
 		if err == nil {
@@ -166,26 +167,26 @@ func runInteractiveFilesBrowser(ws *workspace.Workspace, files []string, edit bo
 			DisplayLine: displayPath, // Show workspace-relative path in FZF
 			FilePath:    file,        // Full path available for preview
 			LineNumber:  1,
-			Context:     fmt.Sprintf("📄 %s", filepath.Base(file)),
+			Context:     cmdutil.Text(fmt.Sprintf("📄 %s", filepath.Base(file))),
 			Score:       100,
 		}
 	}
 
 	// Handle different modes
 	if selectMode {
-		return runInteractiveFilesWithSelection(results)
+		return runInteractiveFilesWithSelection(ws, results)
 	} else if edit {
-		return runInteractiveFilesWithEditor(results)
+		return runInteractiveFilesWithEditor(ws, results)
 	}
 
 	// Otherwise, run standard interactive browser
-	return fzf.RunInteractiveSearch(results, "files")
+	return fzf.RunInteractiveSearch(results, "files", daemonSocket(ws))
 }
 
 // runInteractiveFilesWithEditor runs FZF and opens selected file in editor
-func runInteractiveFilesWithEditor(results []fzf.SearchResult) error {
+func runInteractiveFilesWithEditor(ws *workspace.Workspace, results []fzf.SearchResult) error {
 	// Create a simpler FZF command for file selection
-	selectedFile, err := runSimpleFZFFileSelection(results, "ENTER: open in editor, ESC: cancel")
+	selectedFile, err := runSimpleFZFFileSelection(ws, results, "ENTER: open in editor, ESC: cancel")
 	if err != nil {
 		return err
 	}
@@ -207,8 +208,8 @@ func runInteractiveFilesWithEditor(results []fzf.SearchResult) error {
 	}
 
 	// Check if the file exists
-	if _, err := os.Stat(selectedFile); os.IsNotExist(err) {
-		return fmt.Errorf("file not found: %s", selectedFile)
+	if _, err := storage.Stat(selectedFile); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, selectedFile)
 	}
 
 	// Prepare editor command with file
@@ -223,9 +224,9 @@ func runInteractiveFilesWithEditor(results []fzf.SearchResult) error {
 }
 
 // runInteractiveFilesWithSelection runs FZF and outputs the selected file path for composition with other tools
-func runInteractiveFilesWithSelection(results []fzf.SearchResult) error {
+func runInteractiveFilesWithSelection(ws *workspace.Workspace, results []fzf.SearchResult) error {
 	// Create a simpler FZF command for file selection
-	selectedFile, err := runSimpleFZFFileSelection(results, "ENTER: select file path, ESC: cancel")
+	selectedFile, err := runSimpleFZFFileSelection(ws, results, "ENTER: select file path, ESC: cancel")
 	if err != nil {
 		return err
 	}
@@ -240,7 +241,7 @@ func runInteractiveFilesWithSelection(results []fzf.SearchResult) error {
 }
 
 // runSimpleFZFFileSelection runs a simple FZF selection and returns the chosen file path
-func runSimpleFZFFileSelection(results []fzf.SearchResult, headerText string) (string, error) {
+func runSimpleFZFFileSelection(ws *workspace.Workspace, results []fzf.SearchResult, headerText string) (string, error) {
 	// Create temporary file with file paths
 	tempFile, err := os.CreateTemp("", "jot-files-*.txt")
 	if err != nil {
@@ -263,7 +264,7 @@ func runSimpleFZFFileSelection(results []fzf.SearchResult, headerText string) (s
 
 	// Run FZF with preview
 	cmd := exec.Command("fzf",
-		"--preview", "jot peek {}",
+		"--preview", fzf.PreviewCommand(daemonSocket(ws), "{}"),
 		"--preview-window", "right:50%",
 		"--prompt", "Select file > ",
 		"--header", headerText,
@@ -11,6 +11,7 @@ import (
 	"github.com/johncoder/jot/internal/cmdutil"
 	"github.com/johncoder/jot/internal/fzf"
 	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -127,7 +128,7 @@ func runInteractiveFind(ws *workspace.Workspace, query string) error {
 	}
 
 	// Run interactive FZF search
-	return fzf.RunInteractiveSearch(fzfResults, query)
+	return fzf.RunInteractiveSearch(fzfResults, query, daemonSocket(ws))
 }
 
 // enhanceResultsWithHeadings adds heading information to search results
@@ -142,7 +143,7 @@ func enhanceResultsWithHeadings(results []SearchResult) []SearchResult {
 	// Process each file once
 	for filePath, resultIndices := range fileGroups {
 		// Read file content
-		content, err := os.ReadFile(filePath)
+		content, err := storage.ReadFile(filePath)
 		if err != nil {
 			continue // Skip files we can't read
 		}
@@ -186,7 +187,7 @@ func collectSearchResults(ws *workspace.Workspace, query string) []SearchResult
 	}
 
 	// Add lib files
-	err := filepath.Walk(ws.LibDir, func(path string, info os.FileInfo, err error) error {
+	err := ws.Walk(ws.LibDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip files we can't read
 		}
@@ -251,7 +252,7 @@ func outputFindJSON(ctx *cmdutil.CommandContext, results []SearchResult, query s
 
 // searchInFile searches for query in a file and returns matches
 func searchInFile(filePath, query, workspaceRoot string) []SearchResult {
-	file, err := os.Open(filePath)
+	file, err := storage.Open(filePath)
 	if err != nil {
 		return nil
 	}
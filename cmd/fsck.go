@@ -0,0 +1,434 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/eval"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Problems fsck reports, besides the approval drift kinds of package eval
+const (
+	fsckMissingFile    = "missing_file"    // The file referenced no longer exists
+	fsckMissingHeading = "missing_heading" // No heading matches the selector
+	fsckMissingAlias   = "missing_alias"   // The selector uses an alias that is not defined
+	fsckAmbiguous      = "ambiguous"       // The selector matches several headings
+	fsckInvalid        = "invalid"         // The selector cannot be parsed
+	fsckStale          = "stale"           // A cache entry is older than its file
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check that caches, bookmarks and approvals match the files",
+	Long: `Check that what jot keeps about your notes still matches them: the heading
+cache used for completion and search, bookmarks, aliases, the reading queue,
+and eval and template approvals. Where 'jot doctor' checks the workspace
+itself, fsck checks the references into it.
+
+Each entry that points at a deleted file or heading, or that is out of date,
+is reported. With --repair, fsck fixes what it can without guessing:
+
+  - Heading cache entries are refreshed, or dropped for deleted files
+  - Bookmarks, aliases and queued selectors for deleted files, or headings
+    with nothing similar left, are removed
+  - Approvals for deleted files, blocks and templates are removed
+
+Selectors matching several headings, or whose heading may have been renamed,
+and approvals for changed code are left to you.
+
+Exits with status 1 when problems remain.
+
+Examples:
+  jot fsck
+  jot fsck --repair
+  jot fsck --repair --dry-run
+  jot fsck --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		repair, _ := cmd.Flags().GetBool("repair")
+
+		response := FsckResponse{
+			Operation: "fsck",
+			Checks:    []FsckCheck{},
+			Issues:    []FsckIssue{},
+		}
+		checks := []struct {
+			name string
+			run  func(*workspace.Workspace, bool) (int, []FsckIssue, error)
+		}{
+			{"heading_cache", fsckHeadingCache},
+			{"bookmarks", fsckBookmarks},
+			{"aliases", fsckAliases},
+			{"queue", fsckQueue},
+			{"approvals", fsckApprovals},
+		}
+		for _, check := range checks {
+			checked, issues, err := check.run(ws, repair)
+			if err != nil {
+				return ctx.HandleOperationError("fsck "+check.name, err)
+			}
+			response.Checks = append(response.Checks, FsckCheck{Name: check.name, Checked: checked, Issues: len(issues)})
+			response.Issues = append(response.Issues, issues...)
+		}
+		for _, issue := range response.Issues {
+			switch {
+			case issue.Repaired:
+				response.Repaired++
+			case issue.Repairable:
+				response.Repairable++
+			}
+		}
+		remaining := len(response.Issues) - response.Repaired
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime)
+			if err := cmdutil.OutputJSON(response); err != nil {
+				return err
+			}
+		} else {
+			printFsck(response, repair)
+		}
+
+		if remaining > 0 {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// FsckResponse is the JSON response for fsck
+type FsckResponse struct {
+	Operation  string               `json:"operation"`
+	Checks     []FsckCheck          `json:"checks"`
+	Issues     []FsckIssue          `json:"issues"`
+	Repaired   int                  `json:"repaired"`
+	Repairable int                  `json:"repairable"` // Issues --repair would fix that are not fixed yet
+	Metadata   cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// FsckCheck counts the entries one check looked at
+type FsckCheck struct {
+	Name    string `json:"name"`
+	Checked int    `json:"checked"`
+	Issues  int    `json:"issues"`
+}
+
+// FsckIssue is an entry that no longer matches the files
+type FsckIssue struct {
+	Check       string   `json:"check"`
+	Problem     string   `json:"problem"`
+	Subject     string   `json:"subject"` // The cached file, bookmark, alias, selector or approval
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	Repairable  bool     `json:"repairable"`
+	Repaired    bool     `json:"repaired"`
+}
+
+// fsckHeadingCache compares the heading cache with the files it describes.
+// Repairing drops entries for deleted files and parses changed files again.
+func fsckHeadingCache(ws *workspace.Workspace, repair bool) (int, []FsckIssue, error) {
+	cache := loadHeadingCache(ws)
+	files := make([]string, 0, len(cache.Files))
+	for file := range cache.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var issues []FsckIssue
+	for _, file := range files {
+		entry := cache.Files[file]
+		issue := FsckIssue{Check: "heading_cache", Subject: file, Repairable: true}
+		info, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+		switch {
+		case os.IsNotExist(err):
+			issue.Problem = fsckMissingFile
+			issue.Message = "cached headings for a file that no longer exists"
+		case err != nil:
+			return 0, nil, err
+		case !entry.ModTime.Equal(info.ModTime()) || entry.Size != info.Size():
+			issue.Problem = fsckStale
+			issue.Message = "cached headings are older than the file"
+		default:
+			continue
+		}
+		if repair {
+			// A deadline in the past would return the stale entry as is
+			cache.headings(ws, file, time.Now().Add(time.Minute))
+			issue.Repaired = true
+		}
+		issues = append(issues, issue)
+	}
+	if repair {
+		cache.save()
+	}
+	return len(files), issues, nil
+}
+
+// fsckBookmarks resolves every bookmark, removing dead ones when repairing
+func fsckBookmarks(ws *workspace.Workspace, repair bool) (int, []FsckIssue, error) {
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		return 0, nil, err
+	}
+	var issues []FsckIssue
+	for _, bookmark := range bookmarks {
+		issue, err := fsckSelector(ws, "bookmarks", bookmark.Name, bookmark.Selector)
+		if err != nil {
+			return 0, nil, err
+		}
+		if issue == nil {
+			continue
+		}
+		if repair && issue.Repairable {
+			if _, err := ws.RemoveBookmark(bookmark.Name); err != nil {
+				return 0, nil, err
+			}
+			issue.Repaired = true
+		}
+		issues = append(issues, *issue)
+	}
+	return len(bookmarks), issues, nil
+}
+
+// fsckAliases resolves every alias, removing dead ones when repairing
+func fsckAliases(ws *workspace.Workspace, repair bool) (int, []FsckIssue, error) {
+	names := ws.AliasNames()
+	var issues []FsckIssue
+	for _, name := range names {
+		issue, err := fsckSelector(ws, "aliases", name, ws.Aliases()[name])
+		if err != nil {
+			return 0, nil, err
+		}
+		if issue == nil {
+			continue
+		}
+		if repair && issue.Repairable {
+			if err := ws.RemoveAlias(name); err != nil {
+				return 0, nil, err
+			}
+			issue.Repaired = true
+		}
+		issues = append(issues, *issue)
+	}
+	return len(names), issues, nil
+}
+
+// fsckQueue resolves every queued selector, dropping dead ones from the
+// queue when repairing
+func fsckQueue(ws *workspace.Workspace, repair bool) (int, []FsckIssue, error) {
+	items, err := ws.LoadQueue()
+	if err != nil {
+		return 0, nil, err
+	}
+	var issues []FsckIssue
+	kept := make([]workspace.QueueItem, 0, len(items))
+	for _, item := range items {
+		issue, err := fsckSelector(ws, "queue", item.Selector, item.Selector)
+		if err != nil {
+			return 0, nil, err
+		}
+		if issue == nil || !(repair && issue.Repairable) {
+			kept = append(kept, item)
+		}
+		if issue != nil {
+			issue.Repaired = repair && issue.Repairable
+			issues = append(issues, *issue)
+		}
+	}
+	if len(kept) < len(items) {
+		if err := ws.SaveQueue(kept); err != nil {
+			return 0, nil, err
+		}
+	}
+	return len(items), issues, nil
+}
+
+// fsckSelector describes what is wrong with the selector an entry keeps, or
+// returns nil when it resolves to exactly one subtree. Entries are only
+// repairable when nothing they could have meant is left.
+func fsckSelector(ws *workspace.Workspace, check, subject, selector string) (*FsckIssue, error) {
+	issue := &FsckIssue{Check: check, Subject: subject}
+	if subject != selector {
+		issue.Subject = subject + " → " + selector
+	}
+
+	expanded, _ := ws.ExpandAlias(selector)
+	if strings.HasPrefix(expanded, workspace.AliasPrefix) {
+		name, _, _ := strings.Cut(strings.TrimPrefix(expanded, workspace.AliasPrefix), "/")
+		issue.Problem = fsckMissingAlias
+		issue.Message = fmt.Sprintf("alias %q is not defined", name)
+		return issue, nil
+	}
+	if strings.Contains(expanded, "#") {
+		if _, err := markdown.ParsePath(expanded); err != nil {
+			issue.Problem = fsckInvalid
+			issue.Message = err.Error()
+			return issue, nil
+		}
+	}
+
+	result, err := checkSelector(ws, expanded, false)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case result.Valid:
+		return nil, nil
+	case !result.FileExists:
+		issue.Problem = fsckMissingFile
+		issue.Repairable = true
+	case result.MatchCount == 0:
+		issue.Problem = fsckMissingHeading
+		issue.Repairable = len(result.Suggestions) == 0
+	default:
+		issue.Problem = fsckAmbiguous
+	}
+	issue.Message = result.Reason
+	issue.Suggestions = result.Suggestions
+	return issue, nil
+}
+
+// fsckApprovals reports eval and template approvals that no longer match
+// a file, block or template, pruning the dead ones when repairing. Eval
+// approvals in always mode are left to 'jot doctor'.
+func fsckApprovals(ws *workspace.Workspace, repair bool) (int, []FsckIssue, error) {
+	checked := 0
+	var issues []FsckIssue
+
+	sm, err := eval.NewSecurityManager()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, d := range sm.Audit() {
+		if d.Kind == eval.DriftAlways {
+			continue
+		}
+		subject := d.FilePath
+		if rel, err := filepath.Rel(ws.Root, d.FilePath); err == nil {
+			subject = filepath.ToSlash(rel)
+		}
+		if d.BlockName != "" {
+			subject += ":" + d.BlockName
+		}
+		issue := FsckIssue{Check: "approvals", Problem: d.Kind, Subject: "eval " + subject, Repairable: d.Dead()}
+		switch d.Kind {
+		case eval.DriftMissingFile:
+			issue.Message = "approved file no longer exists"
+		case eval.DriftMissingBlock:
+			issue.Message = "approved block no longer exists"
+		case eval.DriftChanged:
+			issue.Message = "code changed since it was approved; review it and approve it again"
+		}
+		issues = append(issues, issue)
+	}
+	checked += len(sm.ListApprovals()) + len(sm.ListDocumentApprovals())
+
+	manager := template.NewManager(ws)
+	stale, err := manager.StalePermissions()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, hash := range stale {
+		issues = append(issues, FsckIssue{
+			Check:      "approvals",
+			Problem:    fsckMissingFile,
+			Subject:    "template " + hash[:min(len(hash), 12)],
+			Message:    "approval matches no current template",
+			Repairable: true,
+		})
+	}
+	if hashes, err := manager.ApprovedHashes(); err == nil {
+		checked += len(hashes)
+	}
+
+	if repair {
+		if _, err := sm.Prune(); err != nil {
+			return 0, nil, err
+		}
+		if _, err := manager.PrunePermissions(); err != nil {
+			return 0, nil, err
+		}
+		for i := range issues {
+			issues[i].Repaired = issues[i].Repairable
+		}
+	}
+	return checked, issues, nil
+}
+
+// printFsck prints each check with its issues, then a summary
+func printFsck(response FsckResponse, repair bool) {
+	titles := map[string]string{
+		"heading_cache": "Heading cache",
+		"bookmarks":     "Bookmarks",
+		"aliases":       "Aliases",
+		"queue":         "Reading queue",
+		"approvals":     "Approvals",
+	}
+	for _, check := range response.Checks {
+		status := "ok"
+		if check.Issues > 0 {
+			status = fmt.Sprintf("%d issue%s", check.Issues, pluralize(check.Issues))
+		}
+		fmt.Printf("%s: %d checked, %s\n", titles[check.Name], check.Checked, status)
+		for _, issue := range response.Issues {
+			if issue.Check != check.Name {
+				continue
+			}
+			mark := "✗"
+			if issue.Repaired {
+				mark = "✓"
+			}
+			line := fmt.Sprintf("  %s %s: %s", mark, issue.Subject, issue.Message)
+			if issue.Repaired {
+				line += " (repaired)"
+			}
+			fmt.Println(line)
+			if len(issue.Suggestions) > 0 {
+				fmt.Printf("      did you mean: %s\n", strings.Join(issue.Suggestions, ", "))
+			}
+		}
+	}
+
+	fmt.Println()
+	remaining := len(response.Issues) - response.Repaired
+	switch {
+	case len(response.Issues) == 0:
+		cmdutil.ShowSuccess("✓ Everything matches the files")
+	case remaining == 0:
+		cmdutil.ShowSuccess("✓ Repaired %d issue%s", response.Repaired, pluralize(response.Repaired))
+	case repair:
+		cmdutil.ShowWarning("Repaired %d issue%s; %d need%s your attention", response.Repaired, pluralize(response.Repaired), remaining, fsckVerb(remaining))
+	case response.Repairable > 0:
+		cmdutil.ShowWarning("%d issue%s found; run 'jot fsck --repair' to fix %d of them", remaining, pluralize(remaining), response.Repairable)
+	default:
+		cmdutil.ShowWarning("%d issue%s found; none can be repaired automatically", remaining, pluralize(remaining))
+	}
+}
+
+// fsckVerb returns "s" for a singular subject, to agree "need" with count
+func fsckVerb(count int) string {
+	if count == 1 {
+		return "s"
+	}
+	return ""
+}
+
+func init() {
+	fsckCmd.Flags().Bool("repair", false, "Fix the issues that can be fixed without guessing")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// fsckWorkspace creates a workspace with work.md and an alias to it
+func fsckWorkspace(t *testing.T) *workspace.Workspace {
+	t.Helper()
+	root := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "inbox.md"),
+		LibDir:    filepath.Join(root, "lib"),
+		Config:    &workspace.WorkspaceConfig{Aliases: map[string]string{"work": "work.md#work"}},
+	}
+	if err := os.MkdirAll(ws.JotDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "# Work\n\n## Design review\n\n## Notes\n"
+	if err := os.WriteFile(filepath.Join(root, "work.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func TestFsckSelector(t *testing.T) {
+	ws := fsckWorkspace(t)
+
+	tests := []struct {
+		selector   string
+		problem    string
+		repairable bool
+	}{
+		{"work.md#work/design review", "", false},
+		{"work.md", "", false},
+		{"@work/design review", "", false},
+		{"gone.md#gone", fsckMissingFile, true},
+		{"work.md#work/planning", fsckMissingHeading, true},
+		{"work.md#work/planning/design", fsckMissingHeading, false}, // "Design review" may be a rename
+		{"@nope/notes", fsckMissingAlias, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			issue, err := fsckSelector(ws, "bookmarks", "b", tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.problem == "" {
+				if issue != nil {
+					t.Fatalf("expected no issue, got %+v", issue)
+				}
+				return
+			}
+			if issue == nil {
+				t.Fatalf("expected %s, got no issue", tt.problem)
+			}
+			if issue.Problem != tt.problem || issue.Repairable != tt.repairable {
+				t.Errorf("got %s (repairable %v), want %s (repairable %v)", issue.Problem, issue.Repairable, tt.problem, tt.repairable)
+			}
+		})
+	}
+}
+
+func TestFsckQueueRepair(t *testing.T) {
+	ws := fsckWorkspace(t)
+	if _, err := ws.PushQueue("work.md#work/notes", "gone.md", "work.md#work/planning/design"); err != nil {
+		t.Fatal(err)
+	}
+
+	checked, issues, err := fsckQueue(ws, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 3 || len(issues) != 2 {
+		t.Fatalf("checked %d with %d issues, want 3 with 2", checked, len(issues))
+	}
+	if !issues[0].Repaired || issues[1].Repaired {
+		t.Errorf("want only the missing file repaired, got %+v", issues)
+	}
+
+	items, err := ws.LoadQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Selector != "work.md#work/notes" || items[1].Selector != "work.md#work/planning/design" {
+		t.Errorf("queue after repair = %+v", items)
+	}
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcExpired  bool
+	gcArchive  bool
+	gcDate     string
+	gcNoVerify bool
+)
+
+// expiresPattern matches the value of an expires: property, a date with an
+// optional time
+var expiresPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:[ T](\d{2}:\d{2}))?$`)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "List or archive expired notes",
+	Long: `List or archive notes that have expired.
+
+A subtree expires when its own text, before any child heading, has an
+expires: line with a date and an optional time:
+
+  ## Offsite logistics
+  expires: 2025-07-01
+
+  ## Temporary VPN account
+  <!-- expires: 2025-07-01 18:00 -->
+
+A date alone expires at the end of that day. Without --archive the expired
+subtrees are listed; with it they are moved to the archive location, as
+'jot archive' does. Subtrees inside an expired subtree move with it. The
+archive file itself is not searched.
+
+Examples:
+  jot gc --expired                     # List expired subtrees
+  jot gc --expired --archive           # Move them to the archive
+  jot gc --expired --date 2025-08-01   # What will have expired by then`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		now := time.Now()
+		if gcDate != "" {
+			day, err := time.ParseInLocation("2006-01-02", gcDate, time.Local)
+			if err != nil {
+				return ctx.HandleValidation("date", gcDate, fmt.Errorf("use YYYY-MM-DD"))
+			}
+			now = day.AddDate(0, 0, 1).Add(-time.Second)
+		}
+
+		expired, err := findExpired(ws, now)
+		if err != nil {
+			return ctx.HandleOperationError("find expired notes", err)
+		}
+
+		response := GcResponse{Expired: make([]GcItem, 0, len(expired))}
+		for _, item := range expired {
+			response.Expired = append(response.Expired, item.GcItem)
+		}
+
+		if gcArchive && len(expired) > 0 {
+			response.ArchiveLocation = currentArchiveLocation(ws)
+			if err := archiveExpired(ctx, ws, expired); err != nil {
+				return err
+			}
+			response.Archived = len(expired)
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		if len(expired) == 0 {
+			fmt.Println("No expired notes.")
+			return nil
+		}
+		if response.Archived > 0 {
+			cmdutil.ShowSuccess("✓ Archived %d expired subtree%s to %s", response.Archived, pluralize(response.Archived), response.ArchiveLocation)
+		} else {
+			fmt.Printf("Expired (%d):\n", len(expired))
+		}
+		for _, item := range response.Expired {
+			fmt.Printf("  %s  %s  (line %d)\n", item.Expires, item.Selector, item.Line)
+		}
+		return nil
+	},
+}
+
+// expiredSubtree is an expired subtree and where it was found
+type expiredSubtree struct {
+	GcItem
+	subtree *markdown.Subtree
+}
+
+// findExpired returns the outermost expired subtrees of every workspace file
+// but the archive, in file order
+func findExpired(ws *workspace.Workspace, now time.Time) ([]expiredSubtree, error) {
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var expired []expiredSubtree
+	for _, file := range files {
+		if isArchiveFile(ws, file) {
+			continue
+		}
+		path := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		content, err := storage.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rel := filepath.ToSlash(file)
+		for _, item := range expiredSubtrees(content, now) {
+			item.Selector = rel + "#" + item.Selector
+			item.File = rel
+			expired = append(expired, item)
+		}
+	}
+	return expired, nil
+}
+
+// expiredSubtrees returns the outermost subtrees of content whose expires:
+// line is before now. Selector holds only the heading path.
+func expiredSubtrees(content []byte, now time.Time) []expiredSubtree {
+	var expired []expiredSubtree
+	coveredUntil := -1
+	for _, section := range markdown.Sections(content) {
+		if section.Subtree.StartOffset < coveredUntil {
+			continue
+		}
+		expires, written, ok := sectionExpiry(section.Own)
+		if !ok || !expires.Before(now) {
+			continue
+		}
+		coveredUntil = section.Subtree.EndOffset
+		expired = append(expired, expiredSubtree{
+			GcItem: GcItem{
+				Selector: strings.Join(section.Path, "/"),
+				Heading:  section.Text,
+				Line:     markdown.CalculateLineNumber(content, section.Offset),
+				Expires:  written,
+			},
+			subtree: section.Subtree,
+		})
+	}
+	return expired
+}
+
+// sectionExpiry reads the first expires: line of a section's own text,
+// returning when it expires and the expiry as written. A date alone lasts
+// until the end of that day.
+func sectionExpiry(section []byte) (time.Time, string, bool) {
+	value, ok := markdown.SectionProperty(section, "expires")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	match := expiresPattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, "", false
+	}
+	if match[2] != "" {
+		written := match[1] + " " + match[2]
+		t, err := time.ParseInLocation("2006-01-02 15:04", written, time.Local)
+		return t, written, err == nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", match[1], time.Local)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return day.AddDate(0, 0, 1), match[1], true
+}
+
+// archiveExpired moves expired subtrees to the archive location, one file
+// at a time, running the archive hooks for each file
+func archiveExpired(ctx *cmdutil.CommandContext, ws *workspace.Workspace, expired []expiredSubtree) error {
+	archiveLocation := currentArchiveLocation(ws)
+	archiveFile, _, _ := strings.Cut(archiveLocation, "#")
+	if _, err := storage.Stat(cmdutil.NewPathUtil(ws).WorkspaceJoin(archiveFile)); os.IsNotExist(err) {
+		if err := initializeArchiveStructure(ctx, ws); err != nil {
+			return err
+		}
+		if dryrun.Enabled() {
+			return nil
+		}
+	}
+	destPath, err := markdown.ParsePath(archiveLocation)
+	if err != nil {
+		return ctx.HandleValidation("archive location", archiveLocation, err)
+	}
+
+	for start := 0; start < len(expired); {
+		end := start
+		var subtrees []*markdown.Subtree
+		for end < len(expired) && expired[end].File == expired[start].File {
+			subtrees = append(subtrees, expired[end].subtree)
+			end++
+		}
+		file := expired[start].File
+		start = end
+
+		dest, err := ResolveDestination(ws, destPath, false)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to resolve archive location: %w", err))
+		}
+		for _, subtree := range subtrees {
+			if err := checkProtectedRange(ws, file, subtree.StartOffset, subtree.EndOffset); err != nil {
+				return ctx.HandleError(err)
+			}
+		}
+		if err := checkProtectedDestination(ws, dest); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		sourceFile := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		destFile := cmdutil.ResolveWorkspaceRelativePath(ws, dest.File)
+		op := multiRefileOperation(sourceFile, destFile, subtrees, dest, dest.TargetLevel)
+		change := mutation{Hook: "archive", Source: file, Dest: archiveLocation, NoVerify: gcNoVerify}
+		err = change.run(ctx, ws, func() error {
+			if err := op.Execute(); err != nil {
+				return fmt.Errorf("archive of %s failed: %w", file, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+	}
+	return nil
+}
+
+// GcResponse represents the JSON response for gc
+type GcResponse struct {
+	Expired         []GcItem             `json:"expired"`
+	Archived        int                  `json:"archived"`
+	ArchiveLocation string               `json:"archive_location,omitempty"`
+	Metadata        cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// GcItem is one expired subtree
+type GcItem struct {
+	Selector string `json:"selector"`
+	File     string `json:"file"`
+	Heading  string `json:"heading"`
+	Line     int    `json:"line"`
+	Expires  string `json:"expires"`
+}
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcExpired, "expired", true, "Collect subtrees past their expires: date")
+	gcCmd.Flags().BoolVar(&gcArchive, "archive", false, "Move expired subtrees to the archive instead of listing them")
+	gcCmd.Flags().StringVar(&gcDate, "date", "", "Compare against the end of this date (YYYY-MM-DD) instead of now")
+	gcCmd.Flags().BoolVar(&gcNoVerify, "no-verify", false, "Skip the archive hooks")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiredSubtrees(t *testing.T) {
+	content := []byte(`# Notes
+
+## Offsite
+expires: 2025-07-01
+
+### Parking
+- expires: 2025-06-01
+
+## VPN
+<!-- expires: 2025-07-02 09:30 -->
+
+## Later
+expires: 2025-08-01
+
+## Child only
+
+### Ticket
+expires: 2025-06-15
+`)
+	now := time.Date(2025, 7, 2, 10, 0, 0, 0, time.Local)
+
+	expired := expiredSubtrees(content, now)
+	want := []struct{ selector, expires string }{
+		{"Notes/Offsite", "2025-07-01"},
+		{"Notes/VPN", "2025-07-02 09:30"},
+		{"Notes/Child only/Ticket", "2025-06-15"},
+	}
+	if len(expired) != len(want) {
+		t.Fatalf("got %d expired subtrees, want %d: %+v", len(expired), len(want), expired)
+	}
+	for i, w := range want {
+		if expired[i].Selector != w.selector || expired[i].Expires != w.expires {
+			t.Errorf("expired[%d] = %s (%s), want %s (%s)", i, expired[i].Selector, expired[i].Expires, w.selector, w.expires)
+		}
+	}
+
+	// A date alone lasts through the day
+	if got := expiredSubtrees(content, time.Date(2025, 7, 1, 23, 0, 0, 0, time.Local)); len(got) != 2 {
+		t.Errorf("on the expiry day got %d expired subtrees, want 2", len(got))
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -34,7 +35,8 @@ Available hook types:
 Examples:
   jot hooks list                    # List all hooks in workspace
   jot hooks install-samples         # Install sample hook scripts
-  jot hooks test pre-capture        # Test a specific hook type`,
+  jot hooks test pre-capture        # Test a specific hook type
+  jot hooks trace pre-capture       # Show content at each pipeline stage`,
 }
 
 var hooksListCmd = &cobra.Command{
@@ -92,6 +94,37 @@ debug and verify your hook scripts work as expected.`,
 	},
 }
 
+var hooksTraceContent string
+
+var hooksTraceCmd = &cobra.Command{
+	Use:   "trace <hook-type>",
+	Short: "Show content at each stage of a hook pipeline",
+	Long: `Run every hook of a type in order and show the content after each stage.
+
+Content hooks form a pipeline: each hook receives the previous hook's stdout
+on stdin, and the last hook's stdout replaces the content. Trace runs that
+pipeline on content from --content or stdin and reports each hook's output,
+exit code, timeout and duration, stopping at the first hook that fails.
+
+Hooks are run for real, so any side effects they have will happen.
+
+Examples:
+  jot hooks trace pre-capture --content "meeting notes #work"
+  echo "draft" | jot hooks trace pre-capture
+  jot hooks trace pre-refile --content "## Task" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := workspace.RequireWorkspace()
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		return traceHook(ctx, ws, args[0])
+	},
+}
+
 // listHooks lists all hooks in the workspace
 func listHooks(ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
 	hooksDir := filepath.Join(ws.JotDir, "hooks")
@@ -237,7 +270,7 @@ func installSampleHooks(ctx *cmdutil.CommandContext, ws *workspace.Workspace) er
 		return outputJSON(response)
 	}
 
-	fmt.Printf("✓ Sample hooks installed in %s\n", hooksDir)
+	cmdutil.Printf("✓ Sample hooks installed in %s\n", hooksDir)
 	fmt.Println("\nAvailable samples:")
 	fmt.Println("  pre-capture.sample  - Modify content before capture")
 	fmt.Println("  post-capture.sample - Notifications after capture")
@@ -255,22 +288,9 @@ func installSampleHooks(ctx *cmdutil.CommandContext, ws *workspace.Workspace) er
 // testHook tests a specific hook type with sample data
 func testHook(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookType string) error {
 	// Validate hook type
-	validTypes := []string{
-		"pre-capture", "post-capture", "pre-refile", "post-refile",
-		"pre-archive", "post-archive", "workspace-change",
-	}
-
-	valid := false
-	for _, validType := range validTypes {
-		if hookType == validType {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !isValidHookType(hookType) {
 		err := fmt.Errorf("invalid hook type '%s'. Valid types: %s",
-			hookType, strings.Join(validTypes, ", "))
+			hookType, strings.Join(hookTypes, ", "))
 		if ctx.IsJSONOutput() {
 			return ctx.HandleError(err)
 		}
@@ -282,7 +302,7 @@ func testHook(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookType str
 	hookPath := filepath.Join(hooksDir, hookType)
 
 	if _, err := os.Stat(hookPath); os.IsNotExist(err) {
-		err := fmt.Errorf("hook '%s' not found at %s", hookType, hookPath)
+		err := fmt.Errorf("hook '%s' %w at %s", hookType, cmdutil.ErrNotFound, hookPath)
 		if ctx.IsJSONOutput() {
 			return ctx.HandleError(err)
 		}
@@ -323,7 +343,6 @@ func testHook(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookType str
 		Type:        hooks.HookType(hookType),
 		Workspace:   ws,
 		Content:     testContent,
-		Timeout:     30 * time.Second,
 		AllowBypass: false,
 		ExtraEnv:    extraEnv,
 	}
@@ -365,14 +384,14 @@ func testHook(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookType str
 
 	result, err := manager.Execute(hookCtx)
 	if err != nil {
-		fmt.Printf("❌ Hook failed to execute: %v\n", err)
+		cmdutil.Printf("❌ Hook failed to execute: %v\n", err)
 		return nil
 	}
 
 	if result.ExitCode == 0 {
-		fmt.Printf("✅ Hook executed successfully\n")
+		cmdutil.Printf("✅ Hook executed successfully\n")
 	} else {
-		fmt.Printf("❌ Hook failed with exit code %d\n", result.ExitCode)
+		cmdutil.Printf("❌ Hook failed with exit code %d\n", result.ExitCode)
 	}
 
 	if result.Output != "" {
@@ -386,6 +405,126 @@ func testHook(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookType str
 	return nil
 }
 
+// traceHook runs the hook pipeline for a hook type and reports each stage
+func traceHook(ctx *cmdutil.CommandContext, ws *workspace.Workspace, hookType string) error {
+	if !isValidHookType(hookType) {
+		return ctx.HandleValidation("hook-type", hookType,
+			fmt.Errorf("invalid hook type '%s'. Valid types: %s", hookType, strings.Join(hookTypes, ", ")))
+	}
+
+	content := hooksTraceContent
+	if content == "" {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) == 0 {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return ctx.HandleOperationError("read stdin", err)
+			}
+			content = string(data)
+		}
+	}
+
+	manager := hooks.NewManager(ws)
+	result, err := manager.Trace(&hooks.HookContext{
+		Type:      hooks.HookType(hookType),
+		Workspace: ws,
+		Content:   content,
+	})
+	if result == nil {
+		return ctx.HandleOperationError("trace hooks", err)
+	}
+
+	final := result.Content
+	if len(result.Stages) > 0 {
+		final = result.Stages[len(result.Stages)-1].Content
+	}
+
+	if ctx.IsJSONOutput() {
+		response := HookTraceResponse{
+			Operation: "trace",
+			HookType:  hookType,
+			Input:     content,
+			Content:   final,
+			Stages:    make([]HookTraceStage, 0, len(result.Stages)),
+			Success:   err == nil,
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		}
+		for _, stage := range result.Stages {
+			response.Stages = append(response.Stages, HookTraceStage{
+				Hook:       stage.Hook,
+				Path:       stage.Path,
+				Content:    stage.Content,
+				Changed:    stage.Content != stage.Input,
+				Output:     stage.Output,
+				ExitCode:   stage.ExitCode,
+				TimeoutMs:  stage.Timeout.Milliseconds(),
+				DurationMs: stage.Duration.Milliseconds(),
+				TimedOut:   stage.TimedOut,
+			})
+		}
+		if err != nil {
+			response.Error = err.Error()
+		}
+		return outputJSON(response)
+	}
+
+	if len(result.Stages) == 0 {
+		fmt.Printf("No %s hooks found\n", hookType)
+		return nil
+	}
+
+	fmt.Printf("Input:\n%s\n", indentBlock(content))
+	for i, stage := range result.Stages {
+		status := "ok"
+		switch {
+		case stage.TimedOut:
+			status = fmt.Sprintf("timed out after %s", stage.Timeout)
+		case stage.ExitCode != 0:
+			status = fmt.Sprintf("exit %d", stage.ExitCode)
+		case stage.Content == stage.Input:
+			status = "ok, unchanged"
+		}
+		fmt.Printf("\n[%d] %s (%s, %s)\n", i+1, stage.Hook, status, stage.Duration.Round(time.Millisecond))
+		if stage.Output != "" {
+			fmt.Printf("stderr:\n%s\n", indentBlock(stage.Output))
+		}
+		if stage.Content != stage.Input {
+			fmt.Printf("content:\n%s\n", indentBlock(stage.Content))
+		}
+	}
+
+	if err != nil {
+		fmt.Println()
+		cmdutil.ShowError("✗ %v", err)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// indentBlock indents each line of text for display under a stage heading
+func indentBlock(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "  " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hookTypes lists the hook types that can be tested or traced
+var hookTypes = []string{
+	"pre-capture", "post-capture", "pre-refile", "post-refile",
+	"pre-archive", "post-archive", "pre-eval", "post-eval", "workspace-change",
+}
+
+func isValidHookType(hookType string) bool {
+	for _, valid := range hookTypes {
+		if hookType == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // JSON response structures
 type HooksResponse struct {
 	Operation string               `json:"operation"`
@@ -421,6 +560,29 @@ type HookTestResponse struct {
 	Metadata  cmdutil.JSONMetadata `json:"metadata"`
 }
 
+type HookTraceResponse struct {
+	Operation string               `json:"operation"`
+	HookType  string               `json:"hook_type"`
+	Input     string               `json:"input"`
+	Content   string               `json:"content"`
+	Stages    []HookTraceStage     `json:"stages"`
+	Success   bool                 `json:"success"`
+	Error     string               `json:"error,omitempty"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type HookTraceStage struct {
+	Hook       string `json:"hook"`
+	Path       string `json:"path"`
+	Content    string `json:"content"`
+	Changed    bool   `json:"changed"`
+	Output     string `json:"output,omitempty"`
+	ExitCode   int    `json:"exit_code"`
+	TimeoutMs  int64  `json:"timeout_ms"`
+	DurationMs int64  `json:"duration_ms"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
+}
+
 type HookTestData struct {
 	Content  string            `json:"content"`
 	ExtraEnv map[string]string `json:"extra_env"`
@@ -438,4 +600,7 @@ func init() {
 	hooksCmd.AddCommand(hooksListCmd)
 	hooksCmd.AddCommand(hooksInstallSamplesCmd)
 	hooksCmd.AddCommand(hooksTestCmd)
+	hooksCmd.AddCommand(hooksTraceCmd)
+
+	hooksTraceCmd.Flags().StringVar(&hooksTraceContent, "content", "", "Content to run through the pipeline (default: stdin)")
 }
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import external data into notes",
+	Long: `Import external data into notes as markdown.
+
+Imported content is inserted under a destination selector using the same
+rules as refile: missing headings are created and heading levels are adjusted
+to fit beneath the destination.
+
+Examples:
+  jot import csv data.csv --to "report.md#results"
+  jot import csv people.tsv --to "team.md#members" --as headings --title-column name`,
+}
+
+var importCSVCmd = &cobra.Command{
+	Use:   "csv FILE --to DESTINATION",
+	Short: "Import CSV or TSV data as a markdown table or headings",
+	Long: `Import delimited data (CSV or TSV) into a note.
+
+Two output shapes are supported:
+  table     A markdown table with the first row as the header (default)
+  headings  One subtree per row, titled by --title-column, with the remaining
+            columns rendered as a list beneath each heading
+
+The delimiter is inferred from the file extension (.tsv uses tabs) unless
+--delimiter is given. Use --max-rows to cap the number of imported rows; a
+notice is added when rows are truncated.
+
+Examples:
+  jot import csv data.csv --to "report.md#results"
+  jot import csv data.csv --to "report.md#results" --max-rows 20
+  jot import csv export.tsv --to "inbox.md#imported" --as headings
+  jot import csv people.csv --to "team.md#members" --as headings --title-column name`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		to, _ := cmd.Flags().GetString("to")
+		as, _ := cmd.Flags().GetString("as")
+		maxRows, _ := cmd.Flags().GetInt("max-rows")
+		titleColumn, _ := cmd.Flags().GetString("title-column")
+		delimiter, _ := cmd.Flags().GetString("delimiter")
+		prepend, _ := cmd.Flags().GetBool("prepend")
+
+		if to == "" {
+			return ctx.HandleUsage(fmt.Errorf("destination path required: use --to flag"))
+		}
+		if as != "table" && as != "headings" {
+			return ctx.HandleValidation("--as", as, fmt.Errorf("must be 'table' or 'headings'"))
+		}
+		if maxRows < 0 {
+			return ctx.HandleValidation("--max-rows", fmt.Sprint(maxRows), fmt.Errorf("must not be negative"))
+		}
+
+		destPath, err := markdown.ParsePath(to)
+		if err != nil {
+			return ctx.HandleValidation("destination path", to, err)
+		}
+
+		sourceFile := args[0]
+		comma, err := resolveImportDelimiter(sourceFile, delimiter)
+		if err != nil {
+			return ctx.HandleValidation("--delimiter", delimiter, err)
+		}
+
+		records, err := readDelimitedFile(sourceFile, comma)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if len(records) < 2 {
+			return ctx.HandleError(fmt.Errorf("%s has no data rows to import", sourceFile))
+		}
+
+		header, rows := records[0], records[1:]
+		totalRows := len(rows)
+		rows = limitImportRows(rows, maxRows)
+
+		dest, err := ResolveDestination(ws, destPath, prepend)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to resolve destination: %w", err))
+		}
+
+		var content string
+		switch as {
+		case "table":
+			content = buildMarkdownTable(header, rows)
+		case "headings":
+			titleIndex, err := findColumnIndex(header, titleColumn)
+			if err != nil {
+				return ctx.HandleValidation("--title-column", titleColumn, err)
+			}
+			content = buildRowHeadings(header, rows, titleIndex, dest.TargetLevel)
+		}
+
+		if len(rows) < totalRows {
+			content += "\n" + importTruncationNotice(len(rows), totalRows, filepath.Base(sourceFile))
+		}
+
+		if err := insertAtDestination(ws, dest, []byte(content)); err != nil {
+			return ctx.HandleOperationError("import", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response := ImportResponse{
+				Operation:       "import_csv",
+				Source:          sourceFile,
+				Destination:     to,
+				Format:          as,
+				Columns:         header,
+				RowsImported:    len(rows),
+				RowsTotal:       totalRows,
+				Truncated:       len(rows) < totalRows,
+				CreatedHeadings: dest.CreatePath,
+				Metadata:        cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		cmdutil.ShowSuccess("✓ Imported %d rows from %s to '%s'", len(rows), sourceFile, to)
+		if len(rows) < totalRows {
+			cmdutil.ShowWarning("Truncated %d of %d rows (use --max-rows to adjust)", totalRows-len(rows), totalRows)
+		}
+
+		return nil
+	},
+}
+
+// ImportResponse represents the JSON response for import commands
+type ImportResponse struct {
+	Operation       string               `json:"operation"`
+	Source          string               `json:"source"`
+	Destination     string               `json:"destination"`
+	Format          string               `json:"format"`
+	Columns         []string             `json:"columns"`
+	RowsImported    int                  `json:"rows_imported"`
+	RowsTotal       int                  `json:"rows_total"`
+	Truncated       bool                 `json:"truncated"`
+	CreatedHeadings []string             `json:"created_headings,omitempty"`
+	Metadata        cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// resolveImportDelimiter picks the field delimiter from the flag or file extension
+func resolveImportDelimiter(filename, delimiter string) (rune, error) {
+	switch delimiter {
+	case "":
+		if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+			return '\t', nil
+		}
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	runes := []rune(delimiter)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character or 'tab'")
+	}
+	return runes[0], nil
+}
+
+// readDelimitedFile reads all records from a CSV/TSV file, or stdin when filename is "-"
+func readDelimitedFile(filename string, comma rune) ([][]string, error) {
+	var reader io.Reader
+	if filename == "-" {
+		reader = os.Stdin
+	} else {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, cmdutil.NewFileError("read", filename, err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = comma
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return records, nil
+}
+
+// findColumnIndex locates a header column by name (case-insensitive), defaulting to the first column
+func findColumnIndex(header []string, name string) (int, error) {
+	if name == "" {
+		return 0, nil
+	}
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column not found (available: %s)", strings.Join(header, ", "))
+}
+
+// buildMarkdownTable renders a header and rows as a markdown table
+func buildMarkdownTable(header []string, rows [][]string) string {
+	var builder strings.Builder
+
+	writeRow := func(cells []string) {
+		builder.WriteString("|")
+		for i := range header {
+			cell := ""
+			if i < len(cells) {
+				cell = escapeTableCell(cells[i])
+			}
+			builder.WriteString(" " + cell + " |")
+		}
+		builder.WriteString("\n")
+	}
+
+	writeRow(header)
+	builder.WriteString("|")
+	for range header {
+		builder.WriteString(" --- |")
+	}
+	builder.WriteString("\n")
+
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	return builder.String()
+}
+
+// buildRowHeadings renders one heading per row, with remaining columns as a list
+func buildRowHeadings(header []string, rows [][]string, titleIndex, level int) string {
+	var builder strings.Builder
+	marker := strings.Repeat("#", level)
+
+	for i, row := range rows {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		title := ""
+		if titleIndex < len(row) {
+			title = strings.TrimSpace(row[titleIndex])
+		}
+		if title == "" {
+			title = fmt.Sprintf("Row %d", i+1)
+		}
+		builder.WriteString(fmt.Sprintf("%s %s\n", marker, title))
+
+		var fields []string
+		for col, name := range header {
+			if col == titleIndex || col >= len(row) || strings.TrimSpace(row[col]) == "" {
+				continue
+			}
+			value := strings.ReplaceAll(strings.TrimSpace(row[col]), "\n", " ")
+			fields = append(fields, fmt.Sprintf("- **%s**: %s\n", strings.TrimSpace(name), value))
+		}
+		if len(fields) > 0 {
+			builder.WriteString("\n")
+			builder.WriteString(strings.Join(fields, ""))
+		}
+	}
+
+	return builder.String()
+}
+
+// escapeTableCell makes a value safe to place in a single markdown table cell
+func escapeTableCell(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// limitImportRows keeps the first maxRows rows, or all of them when maxRows is 0
+func limitImportRows(rows [][]string, maxRows int) [][]string {
+	if maxRows > 0 && len(rows) > maxRows {
+		return rows[:maxRows]
+	}
+	return rows
+}
+
+// importTruncationNotice describes how many rows were left out of an import
+func importTruncationNotice(shown, total int, source string) string {
+	return fmt.Sprintf("_Showing %d of %d rows from %s (%d omitted)._\n", shown, total, source, total-shown)
+}
+
+func init() {
+	importCSVCmd.Flags().String("to", "", "Destination path (e.g., 'report.md#results')")
+	importCSVCmd.Flags().String("as", "table", "Output shape: 'table' or 'headings'")
+	importCSVCmd.Flags().Int("max-rows", 0, "Maximum number of rows to import (0 for all)")
+	importCSVCmd.Flags().String("title-column", "", "Column used as heading title with --as headings (default: first column)")
+	importCSVCmd.Flags().String("delimiter", "", "Field delimiter (default: inferred from extension, 'tab' for TSV)")
+	importCSVCmd.Flags().Bool("prepend", false, "Insert content at the beginning under target heading")
+	importCSVCmd.Flags().BoolVar(&forceProtected, "force", false, "Write into protected files and subtrees")
+
+	importCmd.AddCommand(importCSVCmd)
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestReadDelimitedFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    [][]string
+	}{
+		{
+			name:    "quoted fields",
+			file:    "data.csv",
+			content: "name,notes\n\"Smith, Jo\",\"said \"\"hi\"\"\"\n",
+			want:    [][]string{{"name", "notes"}, {"Smith, Jo", `said "hi"`}},
+		},
+		{
+			name:    "quoted newline",
+			file:    "data.csv",
+			content: "name,notes\nJo,\"line one\nline two\"\n",
+			want:    [][]string{{"name", "notes"}, {"Jo", "line one\nline two"}},
+		},
+		{
+			name:    "tsv by extension",
+			file:    "data.tsv",
+			content: "name\tcity\nJo\tOslo, NO\n",
+			want:    [][]string{{"name", "city"}, {"Jo", "Oslo, NO"}},
+		},
+		{
+			name:    "ragged rows",
+			file:    "data.csv",
+			content: "a,b,c\n1\n1,2,3,4\n",
+			want:    [][]string{{"a", "b", "c"}, {"1"}, {"1", "2", "3", "4"}},
+		},
+		{
+			name:    "stray quote",
+			file:    "data.csv",
+			content: "name,size\nboard,12\" x 8\"\n",
+			want:    [][]string{{"name", "size"}, {"board", `12" x 8"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			comma, err := resolveImportDelimiter(path, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := readDelimitedFile(path, comma)
+			if err != nil {
+				t.Fatalf("readDelimitedFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readDelimitedFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveImportDelimiter(t *testing.T) {
+	tests := []struct {
+		file      string
+		delimiter string
+		want      rune
+		wantErr   bool
+	}{
+		{"data.csv", "", ',', false},
+		{"data.TSV", "", '\t', false},
+		{"data.csv", "tab", '\t', false},
+		{"data.txt", `\t`, '\t', false},
+		{"data.csv", ";", ';', false},
+		{"data.csv", "||", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveImportDelimiter(tt.file, tt.delimiter)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveImportDelimiter(%q, %q) = %q, %v; want %q, error %v", tt.file, tt.delimiter, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildMarkdownTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		rows   [][]string
+		want   string
+	}{
+		{
+			name:   "simple",
+			header: []string{"name", "city"},
+			rows:   [][]string{{"Jo", "Oslo"}},
+			want:   "| name | city |\n| --- | --- |\n| Jo | Oslo |\n",
+		},
+		{
+			name:   "short row is padded",
+			header: []string{"a", "b", "c"},
+			rows:   [][]string{{"1"}},
+			want:   "| a | b | c |\n| --- | --- | --- |\n| 1 |  |  |\n",
+		},
+		{
+			name:   "long row is cut to the header",
+			header: []string{"a", "b"},
+			rows:   [][]string{{"1", "2", "3"}},
+			want:   "| a | b |\n| --- | --- |\n| 1 | 2 |\n",
+		},
+		{
+			name:   "pipes and newlines stay in their cell",
+			header: []string{"expr", "notes"},
+			rows:   [][]string{{"a|b", "one\r\ntwo\nthree"}},
+			want:   "| expr | notes |\n| --- | --- |\n| a\\|b | one<br>two<br>three |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildMarkdownTable(tt.header, tt.rows); got != tt.want {
+				t.Errorf("buildMarkdownTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRowHeadings(t *testing.T) {
+	header := []string{"title", "owner", "notes"}
+	tests := []struct {
+		name       string
+		rows       [][]string
+		titleIndex int
+		level      int
+		want       string
+	}{
+		{
+			name:       "first column titles",
+			rows:       [][]string{{"Launch", "Jo", "ship it"}, {"Retro", "", ""}},
+			titleIndex: 0,
+			level:      3,
+			want:       "### Launch\n\n- **owner**: Jo\n- **notes**: ship it\n\n### Retro\n",
+		},
+		{
+			name:       "title column",
+			rows:       [][]string{{"Launch", "Jo", "two\nlines"}},
+			titleIndex: 1,
+			level:      2,
+			want:       "## Jo\n\n- **title**: Launch\n- **notes**: two lines\n",
+		},
+		{
+			name:       "missing titles are numbered",
+			rows:       [][]string{{"", "Jo"}, {"Retro"}, {}},
+			titleIndex: 0,
+			level:      2,
+			want:       "## Row 1\n\n- **owner**: Jo\n\n## Retro\n\n## Row 3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildRowHeadings(header, tt.rows, tt.titleIndex, tt.level); got != tt.want {
+				t.Errorf("buildRowHeadings() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindColumnIndex(t *testing.T) {
+	header := []string{"Title", " Owner "}
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"title", 0, false},
+		{"OWNER", 1, false},
+		{"status", -1, true},
+	}
+
+	for _, tt := range tests {
+		got, err := findColumnIndex(header, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("findColumnIndex(%q) = %d, %v; want %d, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLimitImportRows(t *testing.T) {
+	rows := [][]string{{"1"}, {"2"}, {"3"}}
+	tests := []struct {
+		maxRows int
+		want    int
+		notice  string
+	}{
+		{0, 3, ""},
+		{5, 3, ""},
+		{3, 3, ""},
+		{2, 2, "_Showing 2 of 3 rows from data.csv (1 omitted)._\n"},
+	}
+
+	for _, tt := range tests {
+		kept := limitImportRows(rows, tt.maxRows)
+		if len(kept) != tt.want {
+			t.Errorf("limitImportRows(%d) kept %d rows, want %d", tt.maxRows, len(kept), tt.want)
+		}
+		notice := ""
+		if len(kept) < len(rows) {
+			notice = importTruncationNotice(len(kept), len(rows), "data.csv")
+		}
+		if notice != tt.notice {
+			t.Errorf("limitImportRows(%d) notice = %q, want %q", tt.maxRows, notice, tt.notice)
+		}
+	}
+}
+
+func TestImportHeadingsIntoNewDestination(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot"), InboxPath: filepath.Join(root, "inbox.md")}
+	report := filepath.Join(root, "report.md")
+	if err := os.WriteFile(report, []byte("# Report\n\nIntro.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest, err := ResolveDestination(ws, &markdown.HeadingPath{File: "report.md", Segments: []string{"Tasks"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, rows := []string{"title", "owner"}, limitImportRows([][]string{{"Launch", "Jo"}, {"Retro", "Sam"}}, 1)
+	content := buildRowHeadings(header, rows, 0, dest.TargetLevel) + "\n" + importTruncationNotice(len(rows), 2, "tasks.csv")
+	if err := insertAtDestination(ws, dest, []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rows go under the created heading, not beside it
+	for _, want := range []string{"\n# Tasks\n", "\n## Launch\n", "- **owner**: Jo\n", "_Showing 1 of 2 rows from tasks.csv (1 omitted)._\n"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("report.md =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(string(got), "Retro") {
+		t.Errorf("report.md =\n%s\nwant the row past --max-rows left out", got)
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// InboxAgingReport summarizes how many items sit in the inbox and how old they are
+type InboxAgingReport struct {
+	ItemCount  int              `json:"item_count"`
+	MaxItems   int              `json:"max_items"`
+	MaxAgeDays int              `json:"max_age_days"`
+	OverCount  bool             `json:"over_count"`
+	Oldest     *InboxAgingItem  `json:"oldest,omitempty"`
+	StaleItems []InboxAgingItem `json:"stale_items"`
+	Warnings   []string         `json:"warnings"`
+}
+
+// InboxAgingItem is an inbox item with its capture time
+type InboxAgingItem struct {
+	Heading    string    `json:"heading"`
+	CapturedAt time.Time `json:"captured_at"`
+	AgeDays    int       `json:"age_days"`
+}
+
+// buildInboxAgingReport checks inbox items against the configured thresholds.
+// Item ages come from the capture log; items with no capture record are
+// counted but have no age, and an unreadable log leaves a warning.
+func buildInboxAgingReport(ws *workspace.Workspace, now time.Time) (*InboxAgingReport, error) {
+	thresholds := ws.GetInboxAging()
+	report := &InboxAgingReport{
+		MaxItems:   thresholds.MaxItems,
+		MaxAgeDays: thresholds.MaxAgeDays,
+		StaleItems: []InboxAgingItem{},
+		Warnings:   []string{},
+	}
+
+	content, err := storage.ReadFile(ws.InboxPath)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inbox: %w", err)
+	}
+
+	// Without the capture log the items are still counted, just not aged
+	records, err := ws.LoadCaptures()
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("inbox item ages unavailable: %v", err))
+	}
+
+	items := inboxSubtrees(content)
+	report.ItemCount = len(items)
+	report.OverCount = report.ItemCount > report.MaxItems
+
+	// The earliest capture found in an item is when it entered the inbox.
+	// Records are matched to items by the text they inserted, so items that
+	// share a heading keep their own ages; repeats of the same text are
+	// matched in turn.
+	capturedAt := make(map[int]time.Time)
+	searchFrom := make(map[string]int)
+	for _, record := range records {
+		file := strings.SplitN(record.File, "#", 2)[0]
+		if record.Heading == "" || filepath.Clean(captureFilePath(ws, file)) != filepath.Clean(ws.InboxPath) {
+			continue
+		}
+		index := -1
+		if record.Content == "" {
+			// Older records lack the text, so only a unique heading places them
+			index = inboxItemWithHeading(items, record.Heading)
+		} else if offset, size := record.Index(content, searchFrom[record.Content]); offset >= 0 {
+			searchFrom[record.Content] = offset + size
+			index = inboxItemAt(items, offset)
+		}
+		if index < 0 {
+			continue
+		}
+		if existing, ok := capturedAt[index]; !ok || record.Time.Before(existing) {
+			capturedAt[index] = record.Time
+		}
+	}
+
+	maxAge := time.Duration(report.MaxAgeDays) * 24 * time.Hour
+	for i, subtree := range items {
+		captured, ok := capturedAt[i]
+		if !ok {
+			continue
+		}
+		item := InboxAgingItem{
+			Heading:    subtree.Heading,
+			CapturedAt: captured,
+			AgeDays:    int(now.Sub(captured).Hours() / 24),
+		}
+		if report.Oldest == nil || captured.Before(report.Oldest.CapturedAt) {
+			oldest := item
+			report.Oldest = &oldest
+		}
+		if now.Sub(captured) > maxAge {
+			report.StaleItems = append(report.StaleItems, item)
+		}
+	}
+
+	if report.OverCount {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("inbox has %d items (threshold %d)", report.ItemCount, report.MaxItems))
+	}
+	if len(report.StaleItems) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d inbox item(s) older than %d days", len(report.StaleItems), report.MaxAgeDays))
+	}
+
+	return report, nil
+}
+
+// inboxItemAt returns the index of the inbox item holding offset, or -1
+func inboxItemAt(items []*markdown.Subtree, offset int) int {
+	for i, item := range items {
+		if offset >= item.StartOffset && offset < item.EndOffset {
+			return i
+		}
+	}
+	return -1
+}
+
+// inboxItemWithHeading returns the index of the only inbox item with
+// heading, or -1 when none or several have it
+func inboxItemWithHeading(items []*markdown.Subtree, heading string) int {
+	index := -1
+	for i, item := range items {
+		if item.Heading == heading {
+			if index >= 0 {
+				return -1
+			}
+			index = i
+		}
+	}
+	return index
+}
+
+// showInboxAgingHint prints a triage reminder after a capture when the
+// workspace enables capture hints and the inbox exceeds its thresholds
+func showInboxAgingHint(ws *workspace.Workspace) {
+	if !ws.GetInboxAging().CaptureHint {
+		return
+	}
+	report, err := buildInboxAgingReport(ws, time.Now())
+	if err != nil || len(report.Warnings) == 0 {
+		return
+	}
+	for _, warning := range report.Warnings {
+		cmdutil.ShowWarning("Inbox: %s", warning)
+	}
+	cmdutil.ShowInfo("Run 'jot status' for details or 'jot refile' to triage")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestBuildInboxAgingReport(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "notes", "in.md"),
+		LibDir:    filepath.Join(root, "lib"),
+		Config:    &workspace.WorkspaceConfig{InboxAging: &workspace.InboxAgingConfig{MaxItems: 2, MaxAgeDays: 7}},
+	}
+	for _, dir := range []string{ws.JotDir, filepath.Dir(ws.InboxPath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inbox := "# Inbox\n\n## Call\nold\n\n## Call\nnew\n\n## Untracked\n\n## Legacy\n"
+	if err := os.WriteFile(ws.InboxPath, []byte(inbox), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	records := []workspace.CaptureRecord{
+		{Time: now.AddDate(0, 0, -30), Heading: "Call", File: "inbox.md", Content: "## Call\nold"},
+		{Time: now.AddDate(0, 0, -1), Heading: "Call", File: "notes/in.md", Content: "## Call\nnew"},
+		{Time: now.AddDate(0, 0, -20), Heading: "Legacy", File: "inbox.md"},
+		{Time: now.AddDate(0, 0, -40), Heading: "Call", File: "work.md", Content: "## Call\nold"},
+	}
+	for _, record := range records {
+		if err := ws.RecordCapture(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := buildInboxAgingReport(ws, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ItemCount != 4 || !report.OverCount {
+		t.Errorf("got %d items (over %v), want 4 over the threshold", report.ItemCount, report.OverCount)
+	}
+	if report.Oldest == nil || report.Oldest.AgeDays != 30 {
+		t.Errorf("oldest = %+v, want the first Call at 30 days", report.Oldest)
+	}
+	var ages []int
+	for _, item := range report.StaleItems {
+		ages = append(ages, item.AgeDays)
+	}
+	// The new Call is not stale though it shares the old one's heading, and
+	// the capture to work.md does not count
+	if len(ages) != 2 || ages[0] != 30 || ages[1] != 20 {
+		t.Errorf("stale ages = %v, want [30 20]", ages)
+	}
+	if len(report.Warnings) != 2 {
+		t.Errorf("warnings = %v, want count and age warnings", report.Warnings)
+	}
+
+	// An unreadable capture log leaves the items unaged rather than failing
+	logPath := filepath.Join(ws.JotDir, "captures.jsonl")
+	if err := os.Remove(logPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(logPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	report, err = buildInboxAgingReport(ws, now)
+	if err != nil {
+		t.Fatalf("unreadable capture log failed the report: %v", err)
+	}
+	if report.ItemCount != 4 || report.Oldest != nil || len(report.Warnings) != 2 {
+		t.Errorf("got %d items, oldest %+v, warnings %v; want 4 unaged items and a log warning", report.ItemCount, report.Oldest, report.Warnings)
+	}
+}
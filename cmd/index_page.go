@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/indexpage"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// defaultIndexPage is the index page jot new keeps up to date
+const defaultIndexPage = "index.md"
+
+var indexPageCmd = &cobra.Command{
+	Use:   "index-page",
+	Short: "Maintain an index page linking to library files",
+	Long: `Maintain an index page with links to every file in lib/.
+
+The links are written between managed markers:
+
+  <!-- jot:index:start group-by=directory -->
+  ...
+  <!-- jot:index:end -->
+
+Everything outside the markers is yours and survives regeneration.
+
+Examples:
+  jot index-page generate
+  jot index-page generate --group-by tag
+  jot index-page generate --file lib/README.md`,
+}
+
+var indexPageGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Build or refresh the index page",
+	Long: `Build or refresh the index page.
+
+Files are grouped by directory, or by the tags in their frontmatter with
+--group-by tag. A file with several tags is listed under each; files without
+tags are listed under "Untagged". Each link uses the file's first heading as
+its title.
+
+The grouping is recorded in the start marker and reused when --group-by is
+not given. If the page has no markers yet, the block is added at the end.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		file, _ := cmd.Flags().GetString("file")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if groupBy != "" && groupBy != indexpage.GroupByDirectory && groupBy != indexpage.GroupByTag {
+			return ctx.HandleValidation("group-by", groupBy, fmt.Errorf("must be directory or tag"))
+		}
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		result, err := generateIndexPage(ws, file, groupBy)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			result.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(result)
+		}
+
+		switch {
+		case result.Created:
+			cmdutil.ShowSuccess("✓ Created %s with %d file(s) grouped by %s", result.File, result.FileCount, result.GroupBy)
+		case result.Changed:
+			cmdutil.ShowSuccess("✓ Updated %s with %d file(s) grouped by %s", result.File, result.FileCount, result.GroupBy)
+		default:
+			cmdutil.ShowInfo("%s is up to date", result.File)
+		}
+		return nil
+	},
+}
+
+// generateIndexPage writes the managed block of an index page. An empty
+// groupBy reuses the grouping recorded in the page.
+func generateIndexPage(ws *workspace.Workspace, file, groupBy string) (*IndexPageResponse, error) {
+	file = filepath.ToSlash(filepath.Clean(file))
+	filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+
+	result := &IndexPageResponse{Operation: "index_page_generate", File: file}
+
+	existing, err := storage.ReadFile(filePath)
+	content := string(existing)
+	switch {
+	case os.IsNotExist(err):
+		result.Created = true
+		content = "# Index\n\n"
+	case err != nil:
+		return nil, cmdutil.NewFileError("read", file, err)
+	}
+
+	if groupBy == "" {
+		groupBy = indexpage.GroupByDirectory
+		if block, ok := indexpage.Find(content); ok {
+			groupBy = block.GroupBy
+		}
+	}
+	result.GroupBy = groupBy
+
+	entries, err := libraryEntries(ws, file)
+	if err != nil {
+		return nil, err
+	}
+	result.FileCount = len(entries)
+
+	updated := indexpage.Update(content, indexpage.Render(entries, groupBy, path.Dir(file)), groupBy)
+	if updated == string(existing) {
+		return result, nil
+	}
+
+	result.Changed = true
+	if err := cmdutil.WriteFileContent(filePath, []byte(updated)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// refreshIndexPage regenerates the default index page if it has managed
+// markers, so new files are listed without a separate step
+func refreshIndexPage(ws *workspace.Workspace) (bool, error) {
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, defaultIndexPage))
+	if err != nil {
+		return false, nil
+	}
+	if _, ok := indexpage.Find(string(content)); !ok {
+		return false, nil
+	}
+	result, err := generateIndexPage(ws, defaultIndexPage, "")
+	if err != nil {
+		return false, err
+	}
+	return result.Changed, nil
+}
+
+// libraryEntries lists the markdown files under lib/, except the index page
+func libraryEntries(ws *workspace.Workspace, indexFile string) ([]indexpage.Entry, error) {
+	var entries []indexpage.Entry
+	if !ws.LibExists() {
+		return entries, nil
+	}
+
+	err := ws.Walk(ws.LibDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if filePath != ws.LibDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(info.Name(), ".md") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(ws.Root, filePath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == indexFile {
+			return nil
+		}
+
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return nil
+		}
+
+		entry := indexpage.Entry{Path: rel, Title: titleFromFileName(rel), Tags: indexpage.Tags(string(content))}
+		if sections := fileSections(rel, filePath, maskFrontmatter(content)); len(sections) > 0 {
+			entry.Title = sections[0].Heading
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// IndexPageResponse represents the JSON response for index-page generate
+type IndexPageResponse struct {
+	Operation string               `json:"operation"`
+	File      string               `json:"file"`
+	GroupBy   string               `json:"group_by"`
+	FileCount int                  `json:"file_count"`
+	Created   bool                 `json:"created"`
+	Changed   bool                 `json:"changed"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func init() {
+	indexPageGenerateCmd.Flags().String("file", defaultIndexPage, "Index page to write, relative to the workspace root")
+	indexPageGenerateCmd.Flags().String("group-by", "", "Group files by directory or tag (default: as recorded in the page, else directory)")
+	indexPageCmd.AddCommand(indexPageGenerateCmd)
+}
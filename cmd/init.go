@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +25,9 @@ The workspace will be created in the current directory or the specified path.
 To register this workspace for global access:
   jot workspace add <name> <path>
 
+Until it is registered, the workspace runs in safe mode: its hooks,
+template shell commands and code blocks do not run.
+
 Examples:
   jot init                    # Initialize in current directory
   jot init ~/my-notes         # Initialize in specific directory`,
@@ -103,6 +107,8 @@ This is your inbox for capturing new notes quickly. Use 'jot capture' to add new
 		gitignoreContent := `# Jot internal files
 *.db
 *.log
+lock
+reads/
 tmp/
 `
 		if err := pathUtil.SafeWriteFile(gitignorePath, []byte(gitignoreContent)); err != nil {
@@ -140,7 +146,7 @@ This directory contains your organized notes. You can structure them however you
 
 Use 'jot refile' to move notes from your inbox to organized files here.
 `
-		if err := os.WriteFile(libReadmePath, []byte(libReadmeContent), 0644); err != nil {
+		if err := dryrun.WriteFile(libReadmePath, []byte(libReadmeContent), 0644); err != nil {
 			return ctx.HandleOperationError("create lib/README.md", err)
 		}
 		createdFiles = append(createdFiles, InitFile{
@@ -176,10 +182,10 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 			return cmdutil.OutputJSON(response)
 		}
 
-		fmt.Println("✓ Created inbox.md")
-		fmt.Println("✓ Created lib/ directory")
-		fmt.Println("✓ Created .jot/ directory")
-		fmt.Println("✓ Initialized workspace structure")
+		cmdutil.Println("✓ Created inbox.md")
+		cmdutil.Println("✓ Created lib/ directory")
+		cmdutil.Println("✓ Created .jot/ directory")
+		cmdutil.Println("✓ Initialized workspace structure")
 		fmt.Println()
 		fmt.Println("Workspace created successfully!")
 		fmt.Println()
@@ -187,6 +193,7 @@ Use 'jot refile' to move notes from your inbox to organized files here.
 		fmt.Printf("  jot workspace add <name> %s\n", absPath)
 		fmt.Println()
 		fmt.Println("Or work locally by running commands from within the workspace directory.")
+		fmt.Println("Hooks, template shell commands and code blocks run only once it is registered.")
 
 		return nil
 	},
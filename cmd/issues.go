@@ -0,0 +1,567 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/issues"
+	"github.com/johncoder/jot/internal/journal"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/netutil"
+	"github.com/johncoder/jot/internal/protect"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/secrets"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ingestRepo   string
+	ingestLabels []string
+	ingestState  string
+	ingestLimit  int
+	ingestTo     string
+	syncRepo     string
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Pull items from other tools into notes",
+	Long: `Pull items from other tools into notes.
+
+Examples:
+  jot ingest github-issues --repo owner/name --label notes
+  jot ingest gitlab-issues --repo group/project --to "work.md#issues"`,
+}
+
+var ingestGitHubCmd = &cobra.Command{
+	Use:   "github-issues --repo OWNER/NAME",
+	Short: "Pull GitHub issues and pull requests into notes as TODO items",
+	Long: `Pull GitHub issues and pull requests into notes as TODO items.
+
+Each issue becomes a task item with its title, link and status, checked
+when the issue is closed:
+
+  - [ ] [owner/name#12](https://github.com/owner/name/issues/12) Fix crash (open)
+
+Items are added under --to (inbox.md#Issues by default). Issues already
+in the workspace are not added again; their title and status are brought
+up to date, and their checkbox is left alone. Use 'jot todo sync-github' to send
+checkbox changes back as comments.
+
+The token is read from GITHUB_TOKEN, or as configured under "issues" in
+.jot/config.json. Public repositories can be read without one.
+
+Examples:
+  jot ingest github-issues --repo owner/name --label notes
+  jot ingest github-issues --repo owner/name --state all --to "work.md#issues"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngestIssues(cmd, issues.GitHub)
+	},
+}
+
+var ingestGitLabCmd = &cobra.Command{
+	Use:   "gitlab-issues --repo GROUP/PROJECT",
+	Short: "Pull GitLab issues into notes as TODO items",
+	Long: `Pull GitLab issues into notes as TODO items.
+
+Works as 'jot ingest github-issues' does. The token is read from
+GITLAB_TOKEN, or as configured under "issues" in .jot/config.json; set
+api_url there for a self-hosted GitLab.
+
+Examples:
+  jot ingest gitlab-issues --repo group/project --label notes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIngestIssues(cmd, issues.GitLab)
+	},
+}
+
+var todoCmd = &cobra.Command{
+	Use:   "todo",
+	Short: "Work with TODO items kept in notes",
+	Long: `Work with TODO items kept in notes.
+
+Examples:
+  jot todo sync-github
+  jot todo sync-gitlab --dry-run`,
+}
+
+var todoSyncGitHubCmd = &cobra.Command{
+	Use:   "sync-github",
+	Short: "Comment on GitHub issues whose TODO items were checked or unchecked",
+	Long: `Comment on GitHub issues whose TODO items were checked or unchecked.
+
+Every task item in the workspace that links to a GitHub issue or pull
+request is compared with its state when it was last ingested or synced.
+When it has been checked, a comment says it was marked done in jot; when
+it has been unchecked, a comment says it was reopened. Issues are not
+closed or reopened. Items jot has not seen before are recorded without a
+comment. Use --dry-run to see the comments without posting them.
+
+Examples:
+  jot todo sync-github
+  jot todo sync-github --repo owner/name --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSyncIssues(cmd, issues.GitHub)
+	},
+}
+
+var todoSyncGitLabCmd = &cobra.Command{
+	Use:   "sync-gitlab",
+	Short: "Comment on GitLab issues whose TODO items were checked or unchecked",
+	Long: `Comment on GitLab issues whose TODO items were checked or unchecked.
+
+Works as 'jot todo sync-github' does, for items linking to GitLab issues.
+
+Examples:
+  jot todo sync-gitlab --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSyncIssues(cmd, issues.GitLab)
+	},
+}
+
+// Comments posted by the todo sync commands
+const (
+	issueDoneComment     = "Marked done in jot notes."
+	issueReopenedComment = "Reopened in jot notes."
+)
+
+// issueClient returns a client for a tracker using the workspace's settings
+func issueClient(ws *workspace.Workspace, tracker string) (*issues.Client, error) {
+	if err := netutil.CheckOnline(tracker); err != nil {
+		return nil, err
+	}
+	cfg := ws.GetTracker(tracker)
+	token, err := resolveToken(cfg.Token, cfg.TokenEnv, cfg.TokenCommand)
+	if err != nil {
+		return nil, err
+	}
+	return issues.NewClient(tracker, cfg.APIURL, token)
+}
+
+// resolveToken reads the configured keyring secret, or runs the token
+// command, or reads the token variable when there is neither
+func resolveToken(token, env, command string) (string, error) {
+	if token != "" {
+		name, ok := secrets.ParseRef(token)
+		if !ok {
+			return "", fmt.Errorf("token must refer to a secret, as \"{{secret github_token}}\"; store the token with 'jot secret set' rather than in the config file")
+		}
+		return secrets.Get(name)
+	}
+	if command == "" {
+		return os.Getenv(env), nil
+	}
+	if err := safemode.Check("the token command from .jot/config.json"); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), issues.DefaultTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// issueLine is a task item found in a workspace file
+type issueLine struct {
+	issues.Item
+	file string // Workspace-relative
+	line int    // 1-based
+}
+
+// findIssueItems returns the issue task items of every workspace file, in
+// file order, with each file's content by absolute path
+func findIssueItems(ws *workspace.Workspace) ([]issueLine, map[string][]byte, error) {
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(files)
+
+	var items []issueLine
+	contents := map[string][]byte{}
+	for _, file := range files {
+		path := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		content, err := storage.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		contents[path] = content
+
+		for i, line := range strings.Split(string(content), "\n") {
+			if item, ok := issues.ParseItem(strings.TrimRight(line, "\r")); ok {
+				items = append(items, issueLine{Item: item, file: filepath.ToSlash(file), line: i + 1})
+			}
+		}
+	}
+	return items, contents, nil
+}
+
+// refreshIssueLines brings the title and status of every item in content
+// for an issue in byKey up to date, leaving items in protected regions
+// alone. It returns the new content and how many items changed.
+func refreshIssueLines(content []byte, byKey map[string]issues.Issue, regions []protect.Region) ([]byte, int) {
+	lines := strings.SplitAfter(string(content), "\n")
+	changed := 0
+	offset := 0
+	for i, line := range lines {
+		start := offset
+		offset += len(line)
+		text := strings.TrimRight(line, "\r\n")
+		item, ok := issues.ParseItem(text)
+		if !ok {
+			continue
+		}
+		issue, ok := byKey[item.Key()]
+		if !ok || protect.Containing(regions, start) != nil {
+			continue
+		}
+		if refreshed := issues.RefreshItem(text, issue); refreshed != text {
+			lines[i] = refreshed + line[len(text):]
+			changed++
+		}
+	}
+	return []byte(strings.Join(lines, "")), changed
+}
+
+func runIngestIssues(cmd *cobra.Command, tracker string) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	if ingestRepo == "" {
+		return ctx.HandleValidation("repo", ingestRepo, fmt.Errorf("a repository is required (--repo)"))
+	}
+	switch ingestState {
+	case "open", "closed", "all":
+	default:
+		return ctx.HandleValidation("state", ingestState, fmt.Errorf("must be open, closed or all"))
+	}
+	if ingestLimit < 0 {
+		return ctx.HandleValidation("limit", fmt.Sprint(ingestLimit), fmt.Errorf("must not be negative"))
+	}
+	destPath, err := markdown.ParsePath(ingestTo)
+	if err != nil {
+		return ctx.HandleValidation("destination path", ingestTo, err)
+	}
+
+	client, err := issueClient(ws, tracker)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	found, err := client.List(ingestRepo, issues.ListOptions{Labels: ingestLabels, State: ingestState, Limit: ingestLimit})
+	if err != nil {
+		return ctx.HandleOperationError("list issues", err)
+	}
+
+	existing, contents, err := findIssueItems(ws)
+	if err != nil {
+		return ctx.HandleOperationError("scan workspace", err)
+	}
+	have := map[string]bool{}
+	for _, item := range existing {
+		have[item.Key()] = true
+	}
+
+	byKey := map[string]issues.Issue{}
+	var added []issues.Issue
+	var lines []string
+	for _, issue := range found {
+		byKey[issue.Key()] = issue
+		if !have[issue.Key()] {
+			added = append(added, issue)
+			lines = append(lines, issues.FormatItem(issue))
+		}
+	}
+
+	// New items go in first, so the destination's offsets still hold;
+	// refreshing only rewrites the ends of lines
+	var destFile string
+	var dest *DestinationTarget
+	inserted := 0
+	if len(added) > 0 {
+		dest, err = ResolveDestination(ws, destPath, false)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to resolve destination: %w", err))
+		}
+		if err := checkProtectedDestination(ws, dest); err != nil {
+			return ctx.HandleError(err)
+		}
+		destFile = cmdutil.ResolveWorkspaceRelativePath(ws, dest.File)
+		if _, ok := contents[destFile]; !ok {
+			content, err := storage.ReadFile(destFile)
+			if err != nil {
+				return ctx.HandleFileOperation("read", dest.File, err)
+			}
+			contents[destFile] = content
+		}
+		before := len(contents[destFile])
+		contents[destFile] = insertCaptured(contents[destFile], dest, []byte(strings.Join(lines, "\n")))
+		inserted = len(contents[destFile]) - before
+	}
+
+	var writes []journal.Write
+	refreshed := 0
+	paths := make([]string, 0, len(contents))
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		regions, _, err := protectedRegions(ws, path)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if path == destFile {
+			// Regions were found in the file as it is on disk
+			for i := range regions {
+				if regions[i].Start >= dest.InsertOffset {
+					regions[i].Start += inserted
+				}
+				if regions[i].End >= dest.InsertOffset {
+					regions[i].End += inserted
+				}
+			}
+		}
+		content, changed := refreshIssueLines(contents[path], byKey, regions)
+		refreshed += changed
+		if changed > 0 || path == destFile {
+			writes = append(writes, journal.Write{Path: path, Content: content})
+		}
+	}
+	if err := journal.Apply(writes, nil); err != nil {
+		return ctx.HandleOperationError("ingest", err)
+	}
+
+	if len(added) > 0 {
+		states, err := ws.LoadIssueStates()
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		now := time.Now().UTC()
+		for _, issue := range added {
+			states[issue.Key()] = workspace.IssueState{Done: issue.State == "closed", Synced: now}
+		}
+		if err := ws.SaveIssueStates(states); err != nil {
+			return ctx.HandleError(err)
+		}
+	}
+
+	response := IngestResponse{
+		Tracker:   tracker,
+		Repo:      ingestRepo,
+		Found:     len(found),
+		Added:     make([]issues.Issue, 0, len(added)),
+		Refreshed: refreshed,
+	}
+	response.Added = append(response.Added, added...)
+	if dest != nil {
+		response.Destination = ingestTo
+	}
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+
+	if len(found) == 0 {
+		fmt.Printf("No matching issues in %s.\n", ingestRepo)
+		return nil
+	}
+	if len(added) > 0 {
+		cmdutil.ShowSuccess("✓ Added %d issue%s from %s to '%s'", len(added), pluralize(len(added)), ingestRepo, ingestTo)
+		for _, issue := range added {
+			fmt.Printf("  #%d %s (%s)\n", issue.Number, issue.Title, issue.State)
+		}
+	} else {
+		fmt.Printf("No new issues in %s.\n", ingestRepo)
+	}
+	if refreshed > 0 {
+		fmt.Printf("  Updated %d existing item%s\n", refreshed, pluralize(refreshed))
+	}
+	return nil
+}
+
+func runSyncIssues(cmd *cobra.Command, tracker string) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	items, _, err := findIssueItems(ws)
+	if err != nil {
+		return ctx.HandleOperationError("scan workspace", err)
+	}
+	states, err := ws.LoadIssueStates()
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	// An issue listed more than once is synced from its first item
+	var changes []issueLine
+	seen := map[string]bool{}
+	recorded := 0
+	now := time.Now().UTC()
+	for _, item := range items {
+		if item.Tracker != tracker || (syncRepo != "" && item.Repo != syncRepo) || seen[item.Key()] {
+			continue
+		}
+		seen[item.Key()] = true
+		state, ok := states[item.Key()]
+		switch {
+		case !ok:
+			states[item.Key()] = workspace.IssueState{Done: item.Done, Synced: now}
+			recorded++
+		case state.Done != item.Done:
+			changes = append(changes, item)
+		}
+	}
+
+	response := TodoSyncResponse{Tracker: tracker, Recorded: recorded, Comments: make([]TodoSyncItem, 0, len(changes))}
+	var syncErr error
+	posted := 0
+	if len(changes) > 0 && !dryrun.Enabled() {
+		var client *issues.Client
+		client, syncErr = issueClient(ws, tracker)
+		if syncErr == nil && client.Token == "" {
+			syncErr = fmt.Errorf("no %s token: set %s, or token_command under \"issues\" in .jot/config.json", tracker, ws.GetTracker(tracker).TokenEnv)
+		}
+		for _, item := range changes {
+			if syncErr != nil {
+				break
+			}
+			if syncErr = client.Comment(item.Repo, item.Number, syncComment(item.Done)); syncErr == nil {
+				states[item.Key()] = workspace.IssueState{Done: item.Done, Synced: now}
+				response.Comments = append(response.Comments, syncItem(item))
+				posted++
+			}
+		}
+	} else {
+		for _, item := range changes {
+			response.Comments = append(response.Comments, syncItem(item))
+		}
+	}
+
+	if recorded > 0 || posted > 0 {
+		if err := ws.SaveIssueStates(states); err != nil {
+			return ctx.HandleError(err)
+		}
+	}
+	if syncErr != nil {
+		return ctx.HandleOperationError("sync issues", syncErr)
+	}
+
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+
+	if len(response.Comments) == 0 {
+		fmt.Println("No TODO changes to sync.")
+	} else if dryrun.Enabled() {
+		fmt.Printf("Would comment on %d issue%s:\n", len(response.Comments), pluralize(len(response.Comments)))
+	} else {
+		cmdutil.ShowSuccess("✓ Commented on %d issue%s", len(response.Comments), pluralize(len(response.Comments)))
+	}
+	for _, item := range response.Comments {
+		fmt.Printf("  %s#%d %s  (%s:%d)\n", item.Repo, item.Number, item.Change, item.File, item.Line)
+	}
+	if recorded > 0 {
+		fmt.Printf("  Recorded %d new item%s\n", recorded, pluralize(recorded))
+	}
+	return nil
+}
+
+// syncComment is the comment for an item checked (done) or unchecked
+func syncComment(done bool) string {
+	if done {
+		return issueDoneComment
+	}
+	return issueReopenedComment
+}
+
+func syncItem(item issueLine) TodoSyncItem {
+	change := "reopened"
+	if item.Done {
+		change = "done"
+	}
+	return TodoSyncItem{
+		Repo:    item.Repo,
+		Number:  item.Number,
+		URL:     item.URL,
+		Change:  change,
+		Comment: syncComment(item.Done),
+		File:    item.file,
+		Line:    item.line,
+	}
+}
+
+// IngestResponse represents the JSON response for ingest commands
+type IngestResponse struct {
+	Tracker     string               `json:"tracker"`
+	Repo        string               `json:"repo"`
+	Found       int                  `json:"found"`
+	Added       []issues.Issue       `json:"added"`
+	Refreshed   int                  `json:"refreshed"`
+	Destination string               `json:"destination,omitempty"`
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// TodoSyncResponse represents the JSON response for todo sync commands
+type TodoSyncResponse struct {
+	Tracker  string               `json:"tracker"`
+	Comments []TodoSyncItem       `json:"comments"`
+	Recorded int                  `json:"recorded"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// TodoSyncItem is one comment posted, or to be posted in dry-run mode
+type TodoSyncItem struct {
+	Repo    string `json:"repo"`
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	Change  string `json:"change"` // "done" or "reopened"
+	Comment string `json:"comment"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+func init() {
+	for _, c := range []*cobra.Command{ingestGitHubCmd, ingestGitLabCmd} {
+		c.Flags().StringVar(&ingestRepo, "repo", "", "Repository, as owner/name (GitHub) or group/project (GitLab)")
+		c.Flags().StringSliceVar(&ingestLabels, "label", nil, "Only issues with this label (repeatable)")
+		c.Flags().StringVar(&ingestState, "state", "open", "Issues to pull: open, closed or all")
+		c.Flags().IntVar(&ingestLimit, "limit", 0, "Pull at most this many issues (0 for all)")
+		c.Flags().StringVar(&ingestTo, "to", "inbox.md#Issues", "Destination path for new items (e.g., 'work.md#issues')")
+		c.Flags().BoolVar(&forceProtected, "force", false, "Write into protected files and subtrees")
+		ingestCmd.AddCommand(c)
+	}
+	for _, c := range []*cobra.Command{todoSyncGitHubCmd, todoSyncGitLabCmd} {
+		c.Flags().StringVar(&syncRepo, "repo", "", "Only sync items from this repository")
+		todoCmd.AddCommand(c)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/johncoder/jot/internal/issues"
+	"github.com/johncoder/jot/internal/protect"
+)
+
+func TestRefreshIssueLines(t *testing.T) {
+	content := []byte(`# Issues
+- [x] [o/n#12](https://github.com/o/n/issues/12) Old title (open)
+- [ ] [o/n#13](https://github.com/o/n/issues/13) Unchanged (open)
+- [ ] [o/n#14](https://github.com/o/n/issues/14) Protected (open)
+`)
+	byKey := map[string]issues.Issue{}
+	for _, issue := range []issues.Issue{
+		{Tracker: issues.GitHub, Repo: "o/n", Number: 12, Title: "New title", State: "closed"},
+		{Tracker: issues.GitHub, Repo: "o/n", Number: 13, Title: "Unchanged", State: "open"},
+		{Tracker: issues.GitHub, Repo: "o/n", Number: 14, Title: "Changed", State: "closed"},
+	} {
+		byKey[issue.Key()] = issue
+	}
+	start := len(content) - len("- [ ] [o/n#14](https://github.com/o/n/issues/14) Protected (open)\n")
+	regions := []protect.Region{{Heading: "Protected", Start: start, End: len(content)}}
+
+	got, changed := refreshIssueLines(content, byKey, regions)
+	want := `# Issues
+- [x] [o/n#12](https://github.com/o/n/issues/12) New title (closed)
+- [ ] [o/n#13](https://github.com/o/n/issues/13) Unchanged (open)
+- [ ] [o/n#14](https://github.com/o/n/issues/14) Protected (open)
+`
+	if changed != 1 || string(got) != want {
+		t.Errorf("refreshIssueLines() = %d changes:\n%s\nwant 1:\n%s", changed, got, want)
+	}
+}
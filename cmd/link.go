@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/clipboard"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var linkCmd = &cobra.Command{
+	Use:   "link SELECTOR",
+	Short: "Print a markdown link to a note or heading",
+	Long: `Print a markdown link to a note or heading, ready to paste into another
+note.
+
+The link is titled with the heading, or with the file's first heading for a
+whole file, and points at it in one of three styles:
+
+  path   [Design review](<work.md#Work/Design review>)   the full selector
+  slug   [Design review](work.md#design-review)          a GitHub-style anchor
+  id     [Design review](work.md#review)                 the heading's {#review}
+
+The style comes from --style, or from "style" under "links" in
+.jot/config.json, and is path by default. All three resolve as selectors
+in jot, and slug links also work on GitHub. A heading without an explicit
+{#id} is linked by its slug in the id style.
+
+Paths are relative to the workspace root or, with --from, to the note the
+link will be pasted into.
+
+Examples:
+  jot link "work.md#design review"
+  jot link "work.md#design review" --style slug --copy
+  jot link @proj --from journal/2024-06.md
+  jot link notes/setup.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		style, _ := cmd.Flags().GetString("style")
+		if style == "" && ws.Config != nil && ws.Config.Links != nil {
+			style = ws.Config.Links.Style
+		}
+		if style == "" {
+			style = "path"
+		}
+		if style != "path" && style != "slug" && style != "id" {
+			return ctx.HandleValidation("style", style, fmt.Errorf("must be path, slug or id"))
+		}
+		from, _ := cmd.Flags().GetString("from")
+		copyLink, _ := cmd.Flags().GetBool("copy")
+
+		link, err := buildLink(ws, args[0], style, from)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if copyLink {
+			if err := clipboard.Copy(link.Markdown); err != nil {
+				return ctx.HandleOperationError("copy", err)
+			}
+			link.Copied = true
+		}
+
+		if ctx.IsJSONOutput() {
+			link.Metadata = cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(link)
+		}
+		fmt.Println(link.Markdown)
+		if link.Copied {
+			// Keep stdout to the link alone, for $(jot link ...)
+			cmdutil.Fprintf(os.Stderr, "✓ Copied to clipboard\n")
+		}
+		return nil
+	},
+}
+
+// LinkResponse is the JSON response for link
+type LinkResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"` // Full-path selector of the target
+	Style     string               `json:"style"`    // The style used, slug where an id link had no ID
+	Text      string               `json:"text"`
+	Target    string               `json:"target"`
+	Markdown  string               `json:"markdown"`
+	Copied    bool                 `json:"copied"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// buildLink makes a markdown link to what selector names, with its anchor
+// in the given style. The path is relative to from, a note in the
+// workspace, when from is not empty.
+func buildLink(ws *workspace.Workspace, selector, style, from string) (*LinkResponse, error) {
+	if expanded, ok := ws.ExpandAlias(selector); ok {
+		selector = expanded
+	}
+	check, err := checkSelector(ws, selector, false)
+	if err != nil {
+		return nil, err
+	}
+	if !check.Valid {
+		if len(check.Suggestions) > 0 {
+			return nil, fmt.Errorf("%s; try %s", check.Reason, strings.Join(check.Suggestions, ", "))
+		}
+		return nil, fmt.Errorf("%s", check.Reason)
+	}
+
+	file := filepath.ToSlash(check.File)
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", file, err)
+	}
+
+	link := &LinkResponse{Operation: "link", Selector: file, Style: style}
+	anchor := ""
+	if check.MatchCount == 0 {
+		link.Text, _ = markdown.HeadingID(firstHeading(string(content)))
+		if link.Text == "" {
+			link.Text = strings.TrimSuffix(path.Base(file), ".md")
+		}
+	} else {
+		match := check.Matches[0]
+		link.Selector = match.Selector
+		text, id := markdown.HeadingID(match.Heading)
+		link.Text = text
+		switch {
+		case style == "path":
+			_, anchor, _ = strings.Cut(match.Selector, "#")
+		case style == "id" && id != "":
+			anchor = id
+		default:
+			link.Style = "slug"
+			anchor = headingSlug(content, match.Line)
+		}
+	}
+
+	target := file
+	if from != "" {
+		rel, err := filepath.Rel(path.Dir(filepath.ToSlash(from)), file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot link from %s: %w", from, err)
+		}
+		target = filepath.ToSlash(rel)
+		if target == path.Base(from) && anchor != "" {
+			target = ""
+		}
+	}
+	if anchor != "" {
+		target += "#" + anchor
+	}
+	link.Target = target
+	link.Markdown = fmt.Sprintf("[%s](%s)", linkTextEscaper.Replace(link.Text), linkDestination(target))
+	return link, nil
+}
+
+// headingSlug returns the slug of the heading on line, numbered among the
+// file's repeated headings as GitHub numbers them
+func headingSlug(content []byte, line int) string {
+	var slugger markdown.Slugger
+	for _, heading := range markdown.FindAllHeadings(markdown.ParseDocument(content), content) {
+		slug := slugger.Next(heading.Text)
+		if markdown.CalculateLineNumber(content, heading.Offset) == line {
+			return slug
+		}
+	}
+	return ""
+}
+
+// linkTextEscaper escapes the characters that would end a link's text
+var linkTextEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// linkDestination writes a link target so markdown reads it whole, in
+// angle brackets when it holds spaces or parentheses
+func linkDestination(target string) string {
+	if !strings.ContainsAny(target, " ()<>") {
+		return target
+	}
+	return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(target) + ">"
+}
+
+func init() {
+	linkCmd.Flags().String("style", "", "Anchor style: path, slug or id (default from config, else path)")
+	linkCmd.Flags().String("from", "", "Make the path relative to this note")
+	linkCmd.Flags().Bool("copy", false, "Also copy the link to the clipboard")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildLink(t *testing.T) {
+	ws := fsckWorkspace(t)
+	content := "# Work\n\n## Design review {#review}\n\n## Notes\n\n### Notes\n"
+	if err := os.WriteFile(filepath.Join(ws.Root, "work.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		style    string
+		from     string
+		want     string
+	}{
+		{"work.md#design", "path", "", "[Design review](<work.md#Work/Design review {#review}>)"},
+		{"work.md#design", "slug", "", "[Design review](work.md#design-review-review)"},
+		{"work.md#design", "id", "", "[Design review](work.md#review)"},
+		{"work.md#Work/Notes/Notes", "slug", "", "[Notes](work.md#notes-1)"},
+		{"work.md#Work/Notes/Notes", "id", "", "[Notes](work.md#notes-1)"},
+		{"work.md#design", "id", "journal/2024-06.md", "[Design review](../work.md#review)"},
+		{"work.md#design", "id", "work.md", "[Design review](#review)"},
+		{"work.md", "slug", "", "[Work](work.md)"},
+		{"@work", "path", "", "[Work](work.md#Work)"},
+	}
+	for _, tt := range tests {
+		link, err := buildLink(ws, tt.selector, tt.style, tt.from)
+		if err != nil {
+			t.Errorf("%s (%s): %v", tt.selector, tt.style, err)
+			continue
+		}
+		if link.Markdown != tt.want {
+			t.Errorf("%s (%s, from %q): got %s, want %s", tt.selector, tt.style, tt.from, link.Markdown, tt.want)
+		}
+	}
+
+	if _, err := buildLink(ws, "work.md#Work/*", "path", ""); err == nil {
+		t.Error("expected an error for a selector matching several headings")
+	}
+	if _, err := buildLink(ws, "missing.md", "path", ""); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/journal"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/netutil"
+	"github.com/johncoder/jot/internal/protect"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/tickets"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	linksAll      bool
+	linksNoLookup bool
+)
+
+var linksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "Work with links in notes",
+	Long: `Work with links in notes.
+
+Examples:
+  jot links resolve work.md
+  jot links resolve --all --dry-run`,
+}
+
+var linksResolveCmd = &cobra.Command{
+	Use:   "resolve [FILE | SELECTOR]...",
+	Short: "Turn ticket references such as ABC-123 into titled links",
+	Long: `Turn ticket references such as ABC-123 into titled links.
+
+Ticket references are recognized by the project keys configured under
+"links" in .jot/config.json. Each becomes a link built from the rule's URL
+template, titled from Jira or Linear when the rule has a lookup:
+
+  Blocked on ABC-123.
+  Blocked on [ABC-123: Login fails on Safari](https://acme.atlassian.net/browse/ABC-123).
+
+References in headings, code, frontmatter and existing links are left
+alone, so running resolve again changes nothing. A reference whose title
+cannot be fetched is still linked, without a title. Protected files and
+subtrees are skipped unless --force is given.
+
+Examples:
+  jot links resolve work.md                      # One file
+  jot links resolve "work.md#sprint 12"          # One subtree
+  jot links resolve --all --dry-run              # Every note, without writing
+  jot links resolve inbox.md --no-lookup         # Links without titles`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if linksAll == (len(args) > 0) {
+			return ctx.HandleValidation("targets", strings.Join(args, " "), fmt.Errorf("give files or selectors, or --all"))
+		}
+
+		resolver, err := ticketResolver(ws, linksNoLookup)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		targets, err := linkTargets(ws, args)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		response := LinksResolveResponse{Files: []LinksFile{}}
+		var writes []journal.Write
+		for _, target := range targets {
+			regions, relFile, err := protectedRegions(ws, target.path)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			if region := enclosingRegion(regions, target); region != nil {
+				if linksAll {
+					continue
+				}
+				return ctx.HandleError(protectedError(relFile, region))
+			}
+
+			content, links := expandTickets(resolver, target.content, target.start, target.end, regions)
+			if len(links) == 0 {
+				continue
+			}
+			writes = append(writes, journal.Write{Path: target.path, Content: content})
+			response.Files = append(response.Files, LinksFile{File: relFile, Links: links})
+			response.Linked += len(links)
+		}
+
+		if err := journal.Apply(writes, nil); err != nil {
+			return ctx.HandleOperationError("resolve links", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		if response.Linked == 0 {
+			fmt.Println("No ticket references to link.")
+			return nil
+		}
+		cmdutil.ShowSuccess("✓ Linked %d ticket reference%s in %d file%s", response.Linked, pluralize(response.Linked), len(response.Files), pluralize(len(response.Files)))
+		for _, file := range response.Files {
+			fmt.Printf("  %s\n", file.File)
+			for _, link := range file.Links {
+				if link.Title != "" {
+					fmt.Printf("    %s  %s\n", link.ID, link.Title)
+				} else {
+					fmt.Printf("    %s\n", link.ID)
+				}
+			}
+		}
+		for _, file := range response.Files {
+			for _, link := range file.Links {
+				if link.Error != "" {
+					cmdutil.ShowWarning("Warning: no title for %s: %s", link.ID, link.Error)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// ticketResolver builds a resolver from the workspace's ticket rules,
+// fetching the tokens their lookups need
+func ticketResolver(ws *workspace.Workspace, noLookup bool) (*tickets.Resolver, error) {
+	configured := ws.GetTicketRules()
+	if len(configured) == 0 {
+		return nil, fmt.Errorf("no ticket rules are configured; add \"links\": {\"tickets\": [...]} to .jot/config.json")
+	}
+	rules := make([]tickets.Rule, 0, len(configured))
+	for _, cfg := range configured {
+		rule := tickets.Rule{Prefixes: cfg.Prefixes, URL: cfg.URL, Lookup: cfg.Lookup, APIURL: cfg.APIURL}
+		if noLookup {
+			rule.Lookup = ""
+		}
+		if rule.Lookup != "" {
+			if err := netutil.CheckOnline(rule.Lookup + " title lookups"); err != nil {
+				return nil, fmt.Errorf("%w; or use --no-lookup to link without titles", err)
+			}
+			token, err := resolveToken(cfg.Token, cfg.TokenEnv, cfg.TokenCommand)
+			if err != nil {
+				return nil, err
+			}
+			rule.Token = token
+		}
+		rules = append(rules, rule)
+	}
+	return tickets.NewResolver(rules)
+}
+
+// linkTarget is a file, or the part of one a selector names, to resolve
+// links in
+type linkTarget struct {
+	path       string
+	content    []byte
+	start, end int
+}
+
+// linkTargets reads the files and subtrees named by args, or every
+// workspace file when there are none. A file named twice is read once, as
+// a whole.
+func linkTargets(ws *workspace.Workspace, args []string) ([]linkTarget, error) {
+	if len(args) == 0 {
+		files, err := scanWorkspaceMarkdownFiles(ws)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		args = files
+	}
+
+	var targets []linkTarget
+	seen := map[string]int{}
+	for _, arg := range args {
+		file, _, isSelector := strings.Cut(arg, "#")
+		path := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		if file == "inbox.md" {
+			path = ws.InboxPath
+		}
+		content, err := storage.ReadFile(path)
+		if err != nil {
+			return nil, cmdutil.NewFileError("read", file, err)
+		}
+
+		target := linkTarget{path: path, content: content, end: len(content)}
+		if isSelector {
+			selector, err := markdown.ParsePath(arg)
+			if err != nil {
+				return nil, err
+			}
+			subtree, err := markdown.FindSubtree(markdown.ParseDocument(content), content, selector)
+			if err != nil {
+				return nil, err
+			}
+			target.start, target.end = subtree.StartOffset, subtree.EndOffset
+		}
+
+		if i, ok := seen[path]; ok {
+			targets[i].start, targets[i].end = 0, len(content)
+			continue
+		}
+		seen[path] = len(targets)
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// enclosingRegion returns the protected region covering all of a target:
+// the whole file, or a subtree around the selected one
+func enclosingRegion(regions []protect.Region, target linkTarget) *protect.Region {
+	for i, region := range regions {
+		if region.WholeFile() || (region.Start <= target.start && target.end <= region.End) {
+			return &regions[i]
+		}
+	}
+	return nil
+}
+
+// expandTickets expands the ticket references in content[start:end],
+// skipping protected subtrees
+func expandTickets(resolver *tickets.Resolver, content []byte, start, end int, regions []protect.Region) ([]byte, []tickets.Link) {
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+
+	var out []byte
+	var links []tickets.Link
+	out = append(out, content[:start]...)
+	for offset := start; offset < end; {
+		next := end
+		skipTo := end
+		for _, region := range regions {
+			if region.End > offset && region.Start < next {
+				next = max(region.Start, offset)
+				skipTo = min(region.End, end)
+				break
+			}
+		}
+		expanded, made := resolver.Expand(content[offset:next])
+		out = append(out, expanded...)
+		links = append(links, made...)
+		out = append(out, content[next:skipTo]...)
+		offset = skipTo
+	}
+	out = append(out, content[end:]...)
+	return out, links
+}
+
+// LinksResolveResponse represents the JSON response for links resolve
+type LinksResolveResponse struct {
+	Files    []LinksFile          `json:"files"`
+	Linked   int                  `json:"linked"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// LinksFile lists the links made in one file
+type LinksFile struct {
+	File  string         `json:"file"`
+	Links []tickets.Link `json:"links"`
+}
+
+func init() {
+	linksResolveCmd.Flags().BoolVar(&linksAll, "all", false, "Resolve references in every note in the workspace")
+	linksResolveCmd.Flags().BoolVar(&linksNoLookup, "no-lookup", false, "Link references without fetching their titles")
+	linksResolveCmd.Flags().BoolVar(&forceProtected, "force", false, "Rewrite protected files and subtrees")
+	linksCmd.AddCommand(linksResolveCmd)
+}
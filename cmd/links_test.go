@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/protect"
+	"github.com/johncoder/jot/internal/tickets"
+)
+
+func TestExpandTicketsSkipsProtected(t *testing.T) {
+	resolver, err := tickets.NewResolver([]tickets.Rule{{Prefixes: []string{"ABC"}, URL: "https://t/{{id}}"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "ABC-1\n\n## Locked\nABC-2\n\n## Open\nABC-3\n"
+	start := strings.Index(content, "## Locked")
+	end := strings.Index(content, "## Open")
+	regions := []protect.Region{{Heading: "Locked", Start: start, End: end}}
+
+	got, links := expandTickets(resolver, []byte(content), 0, len(content), regions)
+	want := "[ABC-1](https://t/ABC-1)\n\n## Locked\nABC-2\n\n## Open\n[ABC-3](https://t/ABC-3)\n"
+	if string(got) != want || len(links) != 2 {
+		t.Errorf("expandTickets() = %d links:\n%s\nwant 2:\n%s", len(links), got, want)
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Timestamp layouts for log entries and day headings
+const (
+	logTimeLayout = "2006-01-02 15:04"
+	logDayLayout  = "2006-01-02"
+	logHourLayout = "15:04"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log SELECTOR [MESSAGE]",
+	Short: "Add a timestamped entry to a work log",
+	Long: `Add a timestamped bullet under a heading, for work logs kept from the
+shell:
+
+  - 2024-06-12 14:03 Deployed 2.4 to staging
+
+Without MESSAGE, the message is read from stdin. Entries go at the end of
+the heading's own text, or first with --prepend.
+
+With --day-headings, entries are filed under a subheading for the day, such
+as "### 2024-06-12", created when needed, and the bullet gives only the
+time. Both settings can be made the default under "log" in
+.jot/config.json.
+
+Examples:
+  jot log "work.md#log" "Deployed 2.4 to staging"
+  jot log "work.md#log" --prepend "Paged about disk space"
+  git log -1 --format=%s | jot log "work.md#log" --day-headings`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		cfg := ws.GetLog()
+		if cmd.Flags().Changed("prepend") {
+			cfg.Prepend, _ = cmd.Flags().GetBool("prepend")
+		}
+		if cmd.Flags().Changed("day-headings") {
+			cfg.DayHeadings, _ = cmd.Flags().GetBool("day-headings")
+		}
+
+		selector := args[0]
+		var message string
+		if len(args) > 1 {
+			message = args[1]
+		} else {
+			if isTerminal(os.Stdin) {
+				return ctx.HandleUsage(fmt.Errorf("give the message as an argument or on stdin"))
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return ctx.HandleOperationError("read stdin", fmt.Errorf("failed to read from stdin: %w", err))
+			}
+			message = string(data)
+		}
+		// An entry is one bullet, so the message is kept to one line
+		message = strings.Join(strings.Fields(message), " ")
+		if message == "" {
+			return ctx.HandleValidation("message", message, fmt.Errorf("nothing to log"))
+		}
+		if err := checkContent([]byte(message)); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		path, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleValidation("selector", selector, err)
+		}
+		if len(path.Segments) == 0 {
+			return ctx.HandleValidation("selector", selector, fmt.Errorf("selector must name a heading, such as file.md#log"))
+		}
+
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, path.File)
+		if err := checkDestinationContent(ws, filePath); err != nil {
+			return ctx.HandleError(err)
+		}
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", path.File, err))
+		}
+		doc := markdown.ParseDocument(content)
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if err := checkProtectedRange(ws, path.File, subtree.StartOffset, subtree.EndOffset); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		now := time.Now()
+		updated, line, entry := addLogEntry(doc, content, subtree, message, now, cfg)
+		if err := cmdutil.WriteFileContent(filePath, updated); err != nil {
+			return ctx.HandleOperationError("log", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response := LogResponse{
+				Operation: "log",
+				Selector:  selector,
+				File:      path.File,
+				Heading:   subtree.Heading,
+				Entry:     entry,
+				Line:      line,
+				Timestamp: now.Format(time.RFC3339),
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			}
+			if cfg.DayHeadings {
+				response.DayHeading = now.Format(logDayLayout)
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		cmdutil.ShowSuccess("✓ Logged to '%s' at %s:%d", subtree.Heading, path.File, line)
+		return nil
+	},
+}
+
+// LogResponse is the JSON response for log
+type LogResponse struct {
+	Operation  string               `json:"operation"`
+	Selector   string               `json:"selector"`
+	File       string               `json:"file"`
+	Heading    string               `json:"heading"`
+	DayHeading string               `json:"day_heading,omitempty"`
+	Entry      string               `json:"entry"` // The bullet as written
+	Line       int                  `json:"line"`
+	Timestamp  string               `json:"timestamp"`
+	Metadata   cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// addLogEntry returns content with a bullet for message under subtree, or
+// under its heading for the day, the line the bullet is on, and the bullet
+func addLogEntry(doc ast.Node, content []byte, subtree *markdown.Subtree, message string, now time.Time, cfg workspace.LogConfig) ([]byte, int, string) {
+	children := markdown.ChildSubtrees(doc, content, subtree)
+	if !cfg.DayHeadings {
+		entry := now.Format(logTimeLayout) + " " + message
+		updated, line := addToSection(content, subtree.StartOffset, sectionEnd(subtree, children), entry, cfg.Prepend)
+		return updated, line, "- " + entry
+	}
+
+	entry := now.Format(logHourLayout) + " " + message
+	day := now.Format(logDayLayout)
+	for _, child := range children {
+		if child.Heading == day {
+			grandchildren := markdown.ChildSubtrees(doc, content, child)
+			updated, line := addToSection(content, child.StartOffset, sectionEnd(child, grandchildren), entry, cfg.Prepend)
+			return updated, line, "- " + entry
+		}
+	}
+
+	// A new day goes after the other days, or before them with --prepend
+	level := min(subtree.Level+1, 6)
+	block := fmt.Sprintf("%s %s\n\n- %s", strings.Repeat("#", level), day, entry)
+	end := subtree.EndOffset
+	if cfg.Prepend {
+		end = sectionEnd(subtree, children)
+	}
+	updated, line := appendToSection(content, subtree.StartOffset, end, block, false)
+	return updated, line + 2, "- " + entry
+}
+
+// sectionEnd returns where subtree's own text ends: at its first child
+// heading and the markers attached to it, or its end
+func sectionEnd(subtree *markdown.Subtree, children []*markdown.Subtree) int {
+	if len(children) > 0 {
+		return children[0].MarkerStart()
+	}
+	return subtree.EndOffset
+}
+
+// addToSection adds entry as a bullet at the end of the section's text in
+// [start, end), or at its start when prepend is set, returning the line of
+// the bullet
+func addToSection(content []byte, start, end int, entry string, prepend bool) ([]byte, int) {
+	if !prepend {
+		return appendToSection(content, start, end, entry, true)
+	}
+
+	headingEnd := bytes.IndexByte(content[start:end], '\n')
+	if headingEnd < 0 {
+		return appendToSection(content, start, end, entry, true)
+	}
+	bodyStart := start + headingEnd + 1
+	at := bodyStart
+	for at < end && content[at] == '\n' {
+		at++
+	}
+	if at == end {
+		return appendToSection(content, start, end, entry, true)
+	}
+
+	// Continue a bulleted list that opens the section
+	insert := "- " + entry + "\n\n"
+	if match := listItemPattern.FindSubmatch(content[at:end]); match != nil && len(match[1]) == 0 && len(match[2]) > 0 {
+		insert = string(match[2]) + " " + entry + "\n"
+	}
+	line := at
+	if at == bodyStart {
+		insert = "\n" + insert
+		line++
+	}
+
+	updated := make([]byte, 0, len(content)+len(insert))
+	updated = append(updated, content[:at]...)
+	updated = append(updated, insert...)
+	updated = append(updated, content[at:]...)
+	return updated, markdown.CalculateLineNumber(updated, line)
+}
+
+func init() {
+	logCmd.Flags().Bool("prepend", false, "Put the entry first, newest at the top")
+	logCmd.Flags().Bool("day-headings", false, "File the entry under a heading for today")
+	logCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected subtrees, and binary or very large files")
+}
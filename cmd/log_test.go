@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestAddLogEntry(t *testing.T) {
+	now := time.Date(2024, 6, 12, 14, 3, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		content  string
+		cfg      workspace.LogConfig
+		want     string
+		wantLine int
+	}{
+		{
+			name:     "first entry",
+			content:  "# Log\n\n## Other\n",
+			want:     "# Log\n\n- 2024-06-12 14:03 msg\n\n## Other\n",
+			wantLine: 3,
+		},
+		{
+			name:     "prepend to list",
+			content:  "# Log\n\n- 2024-06-11 09:00 old\n",
+			cfg:      workspace.LogConfig{Prepend: true},
+			want:     "# Log\n\n- 2024-06-12 14:03 msg\n- 2024-06-11 09:00 old\n",
+			wantLine: 3,
+		},
+		{
+			name:     "new day heading",
+			content:  "# Log\n\n## 2024-06-11\n\n- 09:00 old\n",
+			cfg:      workspace.LogConfig{DayHeadings: true},
+			want:     "# Log\n\n## 2024-06-11\n\n- 09:00 old\n\n## 2024-06-12\n\n- 14:03 msg\n",
+			wantLine: 9,
+		},
+		{
+			name:     "new day heading first",
+			content:  "# Log\n\n## 2024-06-11\n\n- 09:00 old\n",
+			cfg:      workspace.LogConfig{DayHeadings: true, Prepend: true},
+			want:     "# Log\n\n## 2024-06-12\n\n- 14:03 msg\n\n## 2024-06-11\n\n- 09:00 old\n",
+			wantLine: 5,
+		},
+		{
+			name:     "existing day heading",
+			content:  "# Log\n\n## 2024-06-12\n\n- 09:00 earlier\n",
+			cfg:      workspace.LogConfig{DayHeadings: true},
+			want:     "# Log\n\n## 2024-06-12\n\n- 09:00 earlier\n- 14:03 msg\n",
+			wantLine: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte(tt.content)
+			doc := markdown.ParseDocument(content)
+			path, _ := markdown.ParsePath("f.md#log")
+			subtree, err := markdown.FindSubtree(doc, content, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, line, _ := addLogEntry(doc, content, subtree, "msg", now, tt.cfg)
+			if string(got) != tt.want {
+				t.Errorf("addLogEntry() = %q, want %q", got, tt.want)
+			}
+			if line != tt.wantLine {
+				t.Errorf("line = %d, want %d", line, tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/spf13/cobra"
+	"github.com/yuin/goldmark/ast"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move SELECTOR (--up | --down | --to-top | --to-bottom)",
+	Short: "Reorder a subtree among its sibling headings",
+	Long: `Reorder a subtree among its siblings under the same parent heading.
+
+The subtree keeps its level and parent; nested headings move with it. The
+blank lines between siblings stay where they were, so only the order of the
+sections changes. To move a subtree to another parent or file, use
+'jot refile'; to change its level, use 'jot promote' or 'jot demote'.
+
+Examples:
+  jot move "work.md#projects/backend" --up
+  jot move "work.md#projects/backend" --down --by 2
+  jot move "work.md#projects/urgent" --to-top`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		up, _ := cmd.Flags().GetBool("up")
+		down, _ := cmd.Flags().GetBool("down")
+		top, _ := cmd.Flags().GetBool("to-top")
+		bottom, _ := cmd.Flags().GetBool("to-bottom")
+		by, _ := cmd.Flags().GetInt("by")
+		directions := 0
+		for _, set := range []bool{up, down, top, bottom} {
+			if set {
+				directions++
+			}
+		}
+		if directions != 1 {
+			return ctx.HandleUsage(fmt.Errorf("give one of --up, --down, --to-top or --to-bottom"))
+		}
+		if by < 1 {
+			return ctx.HandleValidation("by", fmt.Sprint(by), fmt.Errorf("must be at least 1"))
+		}
+		if cmd.Flags().Changed("by") && (top || bottom) {
+			return ctx.HandleUsage(fmt.Errorf("--by applies to --up and --down"))
+		}
+
+		selector := args[0]
+		path, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleValidation("selector", selector, err)
+		}
+
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, path.File)
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", path.File, err))
+		}
+		doc := markdown.ParseDocument(content)
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		siblings, from := siblingSubtrees(doc, content, subtree)
+
+		to := from
+		switch {
+		case up:
+			to = max(from-by, 0)
+		case down:
+			to = min(from+by, len(siblings)-1)
+		case top:
+			to = 0
+		case bottom:
+			to = len(siblings) - 1
+		}
+
+		if to != from {
+			first, last := siblings[min(from, to)], siblings[max(from, to)]
+			if err := checkProtectedRange(ws, path.File, first.StartOffset, last.EndOffset); err != nil {
+				return ctx.HandleError(err)
+			}
+			if err := cmdutil.WriteFileContent(filePath, reorderSiblings(content, siblings, from, to)); err != nil {
+				return ctx.HandleOperationError("move", err)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(MoveResponse{
+				Operation: "move",
+				Selector:  selector,
+				File:      path.File,
+				Heading:   subtree.Heading,
+				From:      from + 1,
+				To:        to + 1,
+				Siblings:  len(siblings),
+				Moved:     to != from,
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		if to == from {
+			cmdutil.ShowInfo("'%s' is already at position %d of %d; nothing to move", subtree.Heading, from+1, len(siblings))
+			return nil
+		}
+		cmdutil.ShowSuccess("✓ Moved '%s' from position %d to %d of %d", subtree.Heading, from+1, to+1, len(siblings))
+		return nil
+	},
+}
+
+// MoveResponse is the JSON response for move. Positions count from 1.
+type MoveResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"`
+	File      string               `json:"file"`
+	Heading   string               `json:"heading"`
+	From      int                  `json:"from_position"`
+	To        int                  `json:"to_position"`
+	Siblings  int                  `json:"siblings"`
+	Moved     bool                 `json:"moved"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// siblingSubtrees returns subtree and its siblings under the same parent, in
+// document order, with subtree's index among them
+func siblingSubtrees(doc ast.Node, content []byte, subtree *markdown.Subtree) ([]*markdown.Subtree, int) {
+	siblings := markdown.TopLevelSubtrees(doc, content)
+	for {
+		var parent *markdown.Subtree
+		for _, s := range siblings {
+			if s.StartOffset < subtree.StartOffset && subtree.StartOffset < s.EndOffset {
+				parent = s
+				break
+			}
+		}
+		if parent == nil {
+			break
+		}
+		siblings = markdown.ChildSubtrees(doc, content, parent)
+	}
+	for i, s := range siblings {
+		if s.StartOffset == subtree.StartOffset {
+			return siblings, i
+		}
+	}
+	return []*markdown.Subtree{subtree}, 0
+}
+
+// reorderSiblings returns content with the sibling at index from moved to
+// index to. The text between siblings stays in place, so the blank lines
+// separating sections are kept however the sections are reordered.
+func reorderSiblings(content []byte, siblings []*markdown.Subtree, from, to int) []byte {
+	sections := make([][]byte, len(siblings))
+	gaps := make([][]byte, len(siblings))
+	for i, s := range siblings {
+		section := content[s.MarkerStart():s.EndOffset]
+		text := bytes.TrimRight(section, "\n")
+		sections[i] = text
+		gaps[i] = section[len(text):]
+	}
+
+	moved := sections[from]
+	sections = append(sections[:from], sections[from+1:]...)
+	sections = append(sections[:to], append([][]byte{moved}, sections[to:]...)...)
+
+	var result bytes.Buffer
+	result.Write(content[:siblings[0].MarkerStart()])
+	for i, section := range sections {
+		result.Write(section)
+		gap := gaps[i]
+		if i < len(sections)-1 && len(gap) == 0 {
+			gap = []byte("\n")
+		}
+		result.Write(gap)
+	}
+	result.Write(content[siblings[len(siblings)-1].EndOffset:])
+	return result.Bytes()
+}
+
+func init() {
+	moveCmd.Flags().Bool("up", false, "Move before the previous sibling")
+	moveCmd.Flags().Bool("down", false, "Move after the next sibling")
+	moveCmd.Flags().Bool("to-top", false, "Move before all siblings")
+	moveCmd.Flags().Bool("to-bottom", false, "Move after all siblings")
+	moveCmd.Flags().Int("by", 1, "Number of positions to move with --up or --down")
+	moveCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected files and subtrees")
+}
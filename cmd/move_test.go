@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/johncoder/jot/internal/markdown"
+)
+
+func TestReorderSiblings(t *testing.T) {
+	content := "# Work\n\n## A\n\na\n\n## B\n\n### B1\n\n## C\nc"
+
+	tests := []struct {
+		name     string
+		selector string
+		to       int
+		want     string
+	}{
+		{
+			name:     "last to top keeps gaps in place",
+			selector: "f.md#work/c",
+			to:       0,
+			want:     "# Work\n\n## C\nc\n\n## A\n\na\n\n## B\n\n### B1",
+		},
+		{
+			name:     "first down one with children",
+			selector: "f.md#work/a",
+			to:       1,
+			want:     "# Work\n\n## B\n\n### B1\n\n## A\n\na\n\n## C\nc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := markdown.ParsePath(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc := markdown.ParseDocument([]byte(content))
+			subtree, err := markdown.FindSubtree(doc, []byte(content), path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			siblings, from := siblingSubtrees(doc, []byte(content), subtree)
+			if len(siblings) != 3 {
+				t.Fatalf("found %d siblings, want 3", len(siblings))
+			}
+			if got := string(reorderSiblings([]byte(content), siblings, from, tt.to)); got != tt.want {
+				t.Errorf("reorderSiblings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorderSiblingsKeepsMarkers(t *testing.T) {
+	content := []byte("# Work\n\n<!-- id: a -->\n## A\na\n\n<!-- id: b -->\n## B\nb\n")
+	path, err := markdown.ParsePath("f.md#work/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := markdown.ParseDocument(content)
+	subtree, err := markdown.FindSubtree(doc, content, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	siblings, from := siblingSubtrees(doc, content, subtree)
+	want := "# Work\n\n<!-- id: b -->\n## B\nb\n\n<!-- id: a -->\n## A\na\n"
+	if got := string(reorderSiblings(content, siblings, from, 0)); got != want {
+		t.Errorf("reorderSiblings() = %q, want %q", got, want)
+	}
+}
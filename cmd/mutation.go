@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Commands that change notes run through the same stages, so that new ones
+// behave like the old:
+//
+//  1. workspace resolution, in prepareCommand
+//  2. the workspace lock, taken by registerMutating around the whole command
+//  3. the pre- hook, run by mutation.run for each change
+//  4. the operation, whose multi-file writes go through journal.Apply, which
+//     verifies them with --verify-writes and journals them for 'jot recover'
+//  5. the post- hook, run by mutation.run once the change is made, and given
+//     a diff of the files it touched on stdin
+//  6. output, left to the command
+//
+// Commands that restructure notes are registered in addCommands. One with
+// hooks wraps each change it makes in a mutation.
+
+// registerMutating makes each command hold the workspace lock while it runs,
+// so two jot commands never change the same workspace at once
+func registerMutating(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		run := cmd.RunE
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			ws, err := getWorkspace(cmd)
+			if err != nil {
+				// The command reports a missing workspace itself
+				return run(cmd, args)
+			}
+			unlock, err := ws.Lock(planCommandLine(os.Args))
+			if err != nil {
+				return cmdutil.StartCommand(cmd).HandleError(err)
+			}
+			defer unlock()
+			return run(cmd, args)
+		}
+	}
+}
+
+// mutation is one change a command makes, named for the hooks run around it
+type mutation struct {
+	Hook     string // Hook family, "refile" or "archive"
+	Source   string // What the hooks see as the source
+	Dest     string // What the hooks see as the destination
+	NoVerify bool   // --no-verify: run no hooks
+}
+
+// mutationHooks are the pre- and post- hooks of each hook family
+var mutationHooks = map[string][2]hooks.HookType{
+	"refile":  {hooks.PreRefile, hooks.PostRefile},
+	"archive": {hooks.PreArchive, hooks.PostArchive},
+}
+
+// run makes the change with operation, between the pre- and post- hooks. A
+// pre- hook that fails or aborts stops the change before anything is
+// written. A post- hook failing is only a warning, which JSON output leaves
+// out, as do RPC requests, which pass a nil ctx.
+func (m mutation) run(ctx *cmdutil.CommandContext, ws *workspace.Workspace, operation func() error) error {
+	types, ok := mutationHooks[m.Hook]
+	if !ok {
+		return fmt.Errorf("no hooks for %q changes", m.Hook)
+	}
+
+	manager := hooks.NewManager(ws)
+	if !m.NoVerify {
+		result, err := manager.Execute(m.hookContext(ws, types[0]))
+		if err != nil {
+			return cmdutil.NewExternalError(string(types[0])+" hook", nil, err)
+		}
+		if result.Aborted {
+			return fmt.Errorf("%s hook aborted operation", types[0])
+		}
+	}
+
+	var snapshot *hooks.Snapshot
+	if !m.NoVerify {
+		snapshot = postHookSnapshot(manager, types[1], ws, m.files(ws)...)
+	}
+
+	if err := operation(); err != nil {
+		return err
+	}
+
+	if !m.NoVerify {
+		hookCtx := m.hookContext(ws, types[1])
+		hookCtx.Diff = snapshot.Diff()
+		if _, err := manager.Execute(hookCtx); err != nil && ctx != nil && !ctx.IsJSONOutput() {
+			cmdutil.ShowWarning("Warning: %s hook failed: %s", types[1], err.Error())
+		}
+	}
+	return nil
+}
+
+// postHookSnapshot reads the files a change will write, for the diff given
+// to its post- hooks. It returns nil when no such hook will run, so files
+// are only read twice when one needs it.
+func postHookSnapshot(manager *hooks.Manager, hookType hooks.HookType, ws *workspace.Workspace, paths ...string) *hooks.Snapshot {
+	if dryrun.Enabled() {
+		return nil
+	}
+	if active, _ := manager.Active(hookType); len(active) == 0 {
+		return nil
+	}
+	return hooks.TakeSnapshot(ws.Root, paths...)
+}
+
+// files returns the paths of the files the change touches
+func (m mutation) files(ws *workspace.Workspace) []string {
+	var paths []string
+	for _, selector := range []string{m.Source, m.Dest} {
+		if file, _, _ := strings.Cut(selector, "#"); file != "" {
+			paths = append(paths, cmdutil.ResolveWorkspaceRelativePath(ws, file))
+		}
+	}
+	return paths
+}
+
+func (m mutation) hookContext(ws *workspace.Workspace, hookType hooks.HookType) *hooks.HookContext {
+	return &hooks.HookContext{
+		Type:        hookType,
+		Workspace:   ws,
+		SourceFile:  m.Source,
+		DestPath:    m.Dest,
+		AllowBypass: m.NoVerify,
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func TestMutationHooks(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot"), InboxPath: filepath.Join(root, "inbox.md")}
+	hooksDir := filepath.Join(ws.JotDir, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	ran := filepath.Join(root, "ran")
+	hook := func(name, script string) {
+		if err := os.WriteFile(filepath.Join(hooksDir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hook("pre-archive", "echo \"pre $JOT_SOURCE_FILE\" >> "+ran+"\n")
+	hook("post-archive", "echo \"post $JOT_DEST_PATH\" >> "+ran+"\ncat > "+filepath.Join(root, "diff.json")+"\n")
+	hook("pre-refile", "exit 1\n")
+
+	inbox := filepath.Join(root, "inbox.md")
+	if err := os.WriteFile(inbox, []byte("# Done\nshipped\n# Next\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := cmdutil.StartCommand(&cobra.Command{})
+	operated := false
+	change := mutation{Hook: "archive", Source: "inbox.md#Done", Dest: "archive/archive.md#Archive"}
+	if err := change.run(ctx, ws, func() error {
+		operated = true
+		return os.WriteFile(inbox, []byte("# Next\n"), 0644)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	log, _ := os.ReadFile(ran)
+	if !operated || string(log) != "pre inbox.md#Done\npost archive/archive.md#Archive\n" {
+		t.Errorf("operated = %v, hooks ran: %q", operated, log)
+	}
+	// The post- hook reads what changed on stdin
+	payload, _ := os.ReadFile(filepath.Join(root, "diff.json"))
+	want := `{"files":[{"file":"inbox.md","created":false,"deleted":false,"added":[],"removed":[{"start_line":1,"end_line":2,"heading_path":"Done","text":"# Done\nshipped"}]}]}` + "\n"
+	if string(payload) != want {
+		t.Errorf("post-archive stdin = %s, want %s", payload, want)
+	}
+
+	// A failing pre- hook stops the change
+	operated = false
+	change = mutation{Hook: "refile", Source: "inbox.md#Done", Dest: "work.md#Work"}
+	err := change.run(ctx, ws, func() error {
+		operated = true
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "pre-refile") || operated {
+		t.Errorf("got %v, operated = %v; want the pre-refile hook to stop it", err, operated)
+	}
+
+	// --no-verify runs no hooks
+	change.NoVerify = true
+	if err := change.run(ctx, ws, func() error { return nil }); err != nil {
+		t.Errorf("--no-verify: %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/journal"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// urlSchemePattern matches link targets that are URLs rather than paths
+var urlSchemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+
+var mvCmd = &cobra.Command{
+	Use:   "mv OLD NEW",
+	Short: "Move or rename a note file or directory, updating links to it",
+	Long: `Move or rename a note file or directory inside the workspace.
+
+Unlike a plain mv, jot keeps the workspace pointing at the new path:
+
+  - Markdown links and images to the moved files are rewritten in every
+    note, and relative links inside moved notes are adjusted
+  - Bookmarks, the reading queue, aliases, the archive location and the
+    capture log refer to the new path
+  - The heading index drops the old path
+
+All files are written as one journaled operation, so an interrupted move
+can be rolled back or finished with 'jot recover'. If NEW is an existing
+directory, OLD is moved into it.
+
+Examples:
+  jot mv ideas.md lib/ideas.md          # Move a note into lib/
+  jot mv lib/work lib/projects          # Rename a directory
+  jot mv draft.md lib/ --dry-run        # See what would change
+
+Protected files are not moved, and links inside protected subtrees are not
+rewritten, unless --force is given.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		from, to, err := resolveMove(ws, args[0], args[1])
+		if err != nil {
+			return ctx.HandleValidation("path", args[0], err)
+		}
+
+		move, err := planMove(ws, from, to)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if err := journal.Apply(move.writes, nil); err != nil {
+			return ctx.HandleOperationError("move", err)
+		}
+		removeEmptyDirectories(ws, from)
+
+		cache := loadHeadingCache(ws)
+		for file := range cache.Files {
+			if _, moved := workspace.MovedPath(file, from, to); moved {
+				delete(cache.Files, file)
+				cache.dirty = true
+			}
+		}
+		cache.save()
+
+		response := MvResponse{
+			From:      from,
+			To:        to,
+			Files:     move.files,
+			Links:     move.links,
+			LinkFiles: move.linkFiles,
+			State:     move.state,
+		}
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		cmdutil.ShowSuccess("✓ Moved %s to %s", from, to)
+		if len(move.files) > 1 {
+			fmt.Printf("  %d files\n", len(move.files))
+		}
+		if move.links > 0 {
+			fmt.Printf("  Updated %d link%s in %d file%s\n", move.links, pluralize(move.links), len(move.linkFiles), pluralize(len(move.linkFiles)))
+		}
+		if updated := describeMovedState(move.state); updated != "" {
+			fmt.Printf("  Updated %s\n", updated)
+		}
+		return nil
+	},
+}
+
+// resolveMove turns the arguments of jot mv into workspace-relative,
+// slash-separated paths, checking that the move can be made
+func resolveMove(ws *workspace.Workspace, oldArg, newArg string) (string, string, error) {
+	relative := func(arg string) (string, error) {
+		rel, err := filepath.Rel(ws.Root, cmdutil.ResolveWorkspaceRelativePath(ws, arg))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("%s is outside the workspace", arg)
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".jot" || strings.HasPrefix(rel, ".jot/") {
+			return "", fmt.Errorf("%s is inside the .jot directory", arg)
+		}
+		return rel, nil
+	}
+
+	from, err := relative(oldArg)
+	if err != nil {
+		return "", "", err
+	}
+	if from == "." {
+		return "", "", fmt.Errorf("cannot move the workspace root")
+	}
+	if _, moved := workspace.MovedPath("inbox.md", from, ""); moved {
+		return "", "", fmt.Errorf("the inbox cannot be moved")
+	}
+	if _, err := storage.Stat(filepath.Join(ws.Root, filepath.FromSlash(from))); err != nil {
+		return "", "", fmt.Errorf("%s does not exist", oldArg)
+	}
+
+	to, err := relative(newArg)
+	if err != nil {
+		return "", "", err
+	}
+	if info, err := storage.Stat(filepath.Join(ws.Root, filepath.FromSlash(to))); err == nil && info.IsDir() {
+		to = path.Join(to, path.Base(from))
+	}
+	if to == "." || to == from {
+		return "", "", fmt.Errorf("%s is already at %s", oldArg, newArg)
+	}
+	if _, inside := workspace.MovedPath(to, from, ""); inside {
+		return "", "", fmt.Errorf("cannot move %s into itself", from)
+	}
+	if _, err := storage.Stat(filepath.Join(ws.Root, filepath.FromSlash(to))); err == nil {
+		return "", "", fmt.Errorf("%s already exists", to)
+	}
+	return from, to, nil
+}
+
+// plannedMove holds the writes that make a move, and what they change
+type plannedMove struct {
+	writes    []journal.Write
+	files     []MvFile
+	links     int
+	linkFiles []string
+	state     workspace.MovedState
+}
+
+// planMove reads every file the move touches and prepares its writes: the
+// moved files at their new paths, notes whose links change, the saved
+// selectors, and finally the removal of the old paths
+func planMove(ws *workspace.Workspace, from, to string) (*plannedMove, error) {
+	move := &plannedMove{linkFiles: []string{}}
+	abs := func(rel string) string { return filepath.Join(ws.Root, filepath.FromSlash(rel)) }
+
+	var moving []string
+	err := storage.Walk(abs(from), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(ws.Root, p)
+			moving = append(moving, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", from, err)
+	}
+	sort.Strings(moving)
+
+	notes, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	rewritten := make(map[string][]byte)
+	for _, note := range notes {
+		note = filepath.ToSlash(note)
+		content, err := storage.ReadFile(abs(note))
+		if err != nil {
+			return nil, cmdutil.NewFileError("read", note, err)
+		}
+		updated, changed := moveLinks(ws, content, note, from, to)
+		newNote, moved := workspace.MovedPath(note, from, to)
+		if !moved {
+			if changed == 0 {
+				continue
+			}
+			start, end := changedSpan(content, updated)
+			if err := checkProtectedRange(ws, note, start, end); err != nil {
+				return nil, err
+			}
+			move.writes = append(move.writes, journal.Write{Path: abs(note), Content: updated})
+		} else {
+			rewritten[note] = updated
+		}
+		if changed > 0 {
+			move.links += changed
+			move.linkFiles = append(move.linkFiles, newNote)
+		}
+	}
+
+	var moves []journal.Write
+	var removals []journal.Write
+	for _, file := range moving {
+		newFile, _ := workspace.MovedPath(file, from, to)
+		content, ok := rewritten[file]
+		if !ok {
+			if content, err = storage.ReadFile(abs(file)); err != nil {
+				return nil, cmdutil.NewFileError("read", file, err)
+			}
+		}
+		if strings.HasSuffix(file, ".md") {
+			regions, relFile, err := protectedRegions(ws, file)
+			if err != nil {
+				return nil, err
+			}
+			for i := range regions {
+				if regions[i].WholeFile() {
+					return nil, protectedError(relFile, &regions[i])
+				}
+			}
+		}
+		moves = append(moves, journal.Write{Path: abs(newFile), Content: content})
+		removals = append(removals, journal.Write{Path: abs(file), Remove: true})
+		move.files = append(move.files, MvFile{From: file, To: newFile})
+	}
+
+	stateWrites, state, err := ws.MoveStateWrites(from, to)
+	if err != nil {
+		return nil, err
+	}
+	move.state = state
+
+	move.writes = append(append(append(moves, move.writes...), stateWrites...), removals...)
+	return move, nil
+}
+
+// moveLinks rewrites the relative links of a note, at workspace path note,
+// so they point at the same files after from moves to to. Links inside a
+// moved note are adjusted to its new directory.
+func moveLinks(ws *workspace.Workspace, content []byte, note, from, to string) ([]byte, int) {
+	newNote, noteMoved := workspace.MovedPath(note, from, to)
+	return markdown.RewriteLinks(content, func(target string) (string, bool) {
+		if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") || urlSchemePattern.MatchString(target) {
+			return "", false
+		}
+		linkPath, suffix := target, ""
+		if i := strings.IndexAny(target, "#?"); i >= 0 {
+			linkPath, suffix = target[:i], target[i:]
+		}
+		decoded, err := url.PathUnescape(linkPath)
+		if err != nil {
+			decoded = linkPath
+		}
+
+		resolved, err := filepath.Rel(ws.Root, filepath.Join(ws.Root, filepath.FromSlash(path.Dir(note)), filepath.FromSlash(decoded)))
+		if err != nil {
+			return "", false
+		}
+		resolved = filepath.ToSlash(resolved)
+		movedTarget, targetMoved := workspace.MovedPath(resolved, from, to)
+		if !targetMoved && !noteMoved {
+			return "", false
+		}
+
+		rel, err := filepath.Rel(filepath.Join(ws.Root, filepath.FromSlash(path.Dir(newNote))), filepath.Join(ws.Root, filepath.FromSlash(movedTarget)))
+		if err != nil {
+			return "", false
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(linkPath, "./") && !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+		if decoded != linkPath {
+			rel = strings.ReplaceAll(rel, " ", "%20")
+		}
+		return rel + suffix, true
+	})
+}
+
+// changedSpan returns the range of before that differs from after
+func changedSpan(before, after []byte) (int, int) {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	return prefix, len(before) - suffix
+}
+
+// removeEmptyDirectories removes the directories a moved directory leaves
+// empty, deepest first
+func removeEmptyDirectories(ws *workspace.Workspace, from string) {
+	root := filepath.Join(ws.Root, filepath.FromSlash(from))
+	if dryrun.Enabled() || !storage.IsFilesystem() {
+		return
+	}
+	var dirs []string
+	_ = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+}
+
+// describeMovedState lists the saved selectors a move updated
+func describeMovedState(state workspace.MovedState) string {
+	var parts []string
+	add := func(count int, noun, plural string) {
+		switch {
+		case count == 1:
+			parts = append(parts, "1 "+noun)
+		case count > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count, plural))
+		}
+	}
+	add(state.Bookmarks, "bookmark", "bookmarks")
+	add(state.Queue, "queued item", "queued items")
+	add(state.Aliases, "alias", "aliases")
+	add(state.Captures, "capture record", "capture records")
+	if state.ArchiveLocation {
+		parts = append(parts, "the archive location")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// MvResponse represents the JSON response for mv
+type MvResponse struct {
+	From      string               `json:"from"`
+	To        string               `json:"to"`
+	Files     []MvFile             `json:"files"`
+	Links     int                  `json:"links"`      // Links rewritten
+	LinkFiles []string             `json:"link_files"` // Notes whose links changed, at their new paths
+	State     workspace.MovedState `json:"state"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// MvFile is one file moved
+type MvFile struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func init() {
+	mvCmd.Flags().BoolVar(&forceProtected, "force", false, "Move protected files and rewrite links in protected subtrees")
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/johncoder/jot/internal/netutil"
+	"github.com/spf13/cobra"
+)
+
+// offlineFlag holds the global --offline flag
+var offlineFlag bool
+
+// configureNetwork applies --offline (also set by JOT_OFFLINE) and the
+// workspace's network settings to every HTTP client integrations use
+func configureNetwork(cmd *cobra.Command) {
+	opts := netutil.Options{Offline: offlineFlag, Retries: netutil.DefaultRetries}
+	if ws, err := getWorkspace(cmd); err == nil && ws.Config != nil && ws.Config.Network != nil {
+		cfg := ws.Config.Network
+		opts.Offline = opts.Offline || cfg.Offline
+		opts.Proxy = cfg.Proxy
+		opts.Timeout = time.Duration(cfg.Timeout) * time.Second
+		if cfg.Retries != nil {
+			opts.Retries = *cfg.Retries
+		}
+	}
+	if on, err := strconv.ParseBool(os.Getenv("JOT_OFFLINE")); err == nil {
+		opts.Offline = opts.Offline || on
+	}
+	netutil.Configure(opts)
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new PATH",
+	Short: "Create a new library file, optionally from a template",
+	Long: `Create a new markdown file in the library and print selectors for its
+sections, ready to use with capture and refile.
+
+PATH is relative to lib/ unless it already starts with "lib/". The .md
+extension is added when missing. Missing directories are created. Paths
+outside lib/ are refused.
+
+With --template, the file starts from a template in .jot/templates. The
+template's frontmatter is kept except for capture settings (destination,
+refile_mode, file_template), "{{title}}" is replaced with the title, and
+shell commands run as they do for capture. Without a template, the file
+starts with a single heading.
+
+The title defaults to the file name in title case ("api-design" becomes
+"Api Design").
+
+Examples:
+  jot new projects/api-design
+  jot new meetings/2025-q3 --template quarterly-meetings
+  jot new recipes --title "Family Recipes"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		templateName, _ := cmd.Flags().GetString("template")
+		title, _ := cmd.Flags().GetString("title")
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		file, err := newFilePath(args[0])
+		if err != nil {
+			return ctx.HandleValidation("path", args[0], err)
+		}
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		if !ws.Contains(filePath) {
+			return ctx.HandleValidation("path", file, fmt.Errorf("resolves outside the workspace"))
+		}
+		if _, err := storage.Stat(filePath); err == nil {
+			return ctx.HandleValidation("path", file, fmt.Errorf("file already exists"))
+		}
+
+		if title == "" {
+			title = titleFromFileName(file)
+		}
+
+		content, err := newFileContent(ws, templateName, title)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if err := cmdutil.WriteFileContent(filePath, []byte(content)); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		forgetHeadings(ws, file)
+
+		indexed, err := refreshIndexPage(ws)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		sections := fileSections(file, filePath, maskFrontmatter([]byte(content)))
+
+		if ctx.IsJSONOutput() {
+			response := NewFileResponse{
+				Operation: "new",
+				File:      file,
+				FilePath:  filePath,
+				Template:  templateName,
+				Indexed:   indexed,
+				Sections:  make([]NewFileSection, 0, len(sections)),
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			for _, section := range sections {
+				response.Sections = append(response.Sections, NewFileSection{
+					Heading:  section.Heading,
+					Level:    section.Level,
+					Selector: section.Selector(),
+				})
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		cmdutil.ShowSuccess("✓ Created %s", file)
+		if templateName != "" {
+			cmdutil.ShowSuccess("✓ Used template: %s", templateName)
+		}
+		if indexed {
+			cmdutil.ShowSuccess("✓ Added to %s", defaultIndexPage)
+		}
+		if len(sections) > 0 {
+			fmt.Println("\nSections:")
+			for _, section := range sections {
+				fmt.Printf("  %s%s\n", strings.Repeat("  ", section.Level-1), section.Selector())
+			}
+		}
+		return nil
+	},
+}
+
+// newFilePath places a path under lib/ and adds the .md extension. Paths
+// that are absolute or climb out of lib/ are refused.
+func newFilePath(name string) (string, error) {
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("must be relative to lib/")
+	}
+	file := filepath.ToSlash(filepath.Clean(name))
+	outside := file == "." || file == ".." || strings.HasPrefix(file, "../")
+	if outside || strings.HasPrefix(filepath.ToSlash(name), "lib/") && !strings.HasPrefix(file, "lib/") {
+		return "", fmt.Errorf("must be a file inside lib/")
+	}
+	if !strings.HasPrefix(file, "lib/") {
+		file = "lib/" + file
+	}
+	if !strings.HasSuffix(strings.ToLower(file), ".md") {
+		file += ".md"
+	}
+	return file, nil
+}
+
+// titleFromFileName turns "api-design.md" into "Api Design"
+func titleFromFileName(file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || unicode.IsSpace(r)
+	})
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// newFileContent renders the named template, or a single heading without one
+func newFileContent(ws *workspace.Workspace, templateName, title string) (string, error) {
+	if templateName == "" {
+		return fmt.Sprintf("# %s\n\n", title), nil
+	}
+
+	manager := template.NewManager(ws)
+	tmpl, err := manager.Get(templateName)
+	if err != nil {
+		return "", err
+	}
+	content, err := manager.RenderFile(tmpl, title)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content, nil
+}
+
+// maskFrontmatter blanks out YAML frontmatter, keeping offsets, so it is not
+// parsed as a heading
+func maskFrontmatter(content []byte) []byte {
+	text := string(content)
+	if !strings.HasPrefix(text, "---\n") {
+		return content
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end < 0 {
+		return content
+	}
+	end += 4 + len("\n---")
+
+	masked := []byte(text)
+	for i := 0; i < end; i++ {
+		if masked[i] != '\n' {
+			masked[i] = ' '
+		}
+	}
+	return masked
+}
+
+// NewFileResponse represents the JSON response for the new command
+type NewFileResponse struct {
+	Operation string               `json:"operation"`
+	File      string               `json:"file"`
+	FilePath  string               `json:"file_path"`
+	Template  string               `json:"template,omitempty"`
+	Indexed   bool                 `json:"indexed"` // Whether index.md was refreshed
+	Sections  []NewFileSection     `json:"sections"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type NewFileSection struct {
+	Heading  string `json:"heading"`
+	Level    int    `json:"level"`
+	Selector string `json:"selector"`
+}
+
+func init() {
+	newCmd.Flags().StringP("template", "t", "", "Template to create the file from")
+	newCmd.Flags().String("title", "", "Title for the file (default: from the file name)")
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestNewFilePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"projects/api-design", "lib/projects/api-design.md", false},
+		{"lib/recipes.md", "lib/recipes.md", false},
+		{"Notes.MD", "lib/Notes.MD", false},
+		{"projects/../ideas", "lib/ideas.md", false},
+		{"lib/a/../b", "lib/b.md", false},
+		{"/etc/passwd", "", true},
+		{"../outside", "", true},
+		{"lib/../../x", "", true},
+		{"lib/../x", "", true},
+		{"..", "", true},
+		{"lib/..", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := newFilePath(tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("newFilePath(%q) = %q, %v; want %q, error %v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTitleFromFileName(t *testing.T) {
+	tests := map[string]string{
+		"lib/api-design.md":     "Api Design",
+		"lib/family_recipes.md": "Family Recipes",
+		"lib/2025-q3.md":        "2025 Q3",
+	}
+	for file, want := range tests {
+		if got := titleFromFileName(file); got != want {
+			t.Errorf("titleFromFileName(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestForgetHeadings(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot")}
+	file := "lib/notes.md"
+	path := filepath.Join(root, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# Notes\n\n## Today\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry left from an earlier file that matches the new one's time and size
+	cache := loadHeadingCache(ws)
+	cache.Files[file] = &headingCacheFile{ModTime: info.ModTime(), Size: info.Size(), Headings: []TOCHeading{{Heading: "Stale"}}}
+	cache.Files["lib/other.md"] = &headingCacheFile{Headings: []TOCHeading{{Heading: "Other"}}}
+	cache.dirty = true
+	cache.save()
+
+	forgetHeadings(ws, file)
+
+	cache = loadHeadingCache(ws)
+	if _, cached := cache.Files["lib/other.md"]; !cached {
+		t.Errorf("forgetHeadings dropped another file's entry")
+	}
+	headings := cache.headings(ws, file, time.Now().Add(time.Second))
+	if len(headings) != 2 || headings[0].Heading != "Notes" {
+		t.Errorf("headings after forgetHeadings = %+v, want Notes and Today", headings)
+	}
+}
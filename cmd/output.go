@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// plainOutput is set by --plain
+var plainOutput bool
+
+// humanOutput is set by --human
+var humanOutput bool
+
+// configureOutput turns on plain output from --plain, or else from JOT_PLAIN
+// or the "plain" setting in the user config
+func configureOutput(cmd *cobra.Command) {
+	if cmd.Flags().Changed("plain") {
+		cmdutil.SetPlain(plainOutput)
+		return
+	}
+	if value, ok := os.LookupEnv("JOT_PLAIN"); ok {
+		on, err := strconv.ParseBool(value)
+		cmdutil.SetPlain(err == nil && on)
+		return
+	}
+	if err := config.Initialize(cfgFile); err == nil {
+		cmdutil.SetPlain(config.Get().Plain)
+	}
+}
+
+// configureJSON makes JSON the default output when JOT_JSON or the "json"
+// setting in the user config asks for it. --json and --human choose for one
+// command.
+func configureJSON(cmd *cobra.Command) error {
+	human := cmd.Flags().Changed("human") && humanOutput
+	if cmd.Flags().Changed("json") {
+		if jsonOutput && human {
+			return cmdutil.NewUsageError(fmt.Errorf("--json and --human cannot be used together"))
+		}
+		return nil
+	}
+	if human {
+		return nil
+	}
+
+	on := false
+	if value, ok := os.LookupEnv("JOT_JSON"); ok {
+		on, _ = strconv.ParseBool(value)
+	} else if err := config.Initialize(cfgFile); err == nil {
+		on = config.Get().JSON
+	}
+	if !on {
+		return nil
+	}
+	return cmd.Flags().Set("json", "true")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestConfigureJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		want    bool
+		wantErr bool
+	}{
+		{name: "default text", args: nil, want: false},
+		{name: "JOT_JSON", env: "1", args: nil, want: true},
+		{name: "JOT_JSON off", env: "0", args: nil, want: false},
+		{name: "human overrides JOT_JSON", env: "1", args: []string{"--human"}, want: false},
+		{name: "json flag", args: []string{"--json"}, want: true},
+		{name: "json=false overrides JOT_JSON", env: "1", args: []string{"--json=false"}, want: false},
+		{name: "json and human", args: []string{"--json", "--human"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("JOT_JSON", tt.env)
+			}
+			jsonOutput, humanOutput = false, false
+			root := &cobra.Command{Use: "jot"}
+			root.PersistentFlags().BoolVar(&jsonOutput, "json", false, "")
+			root.PersistentFlags().BoolVar(&humanOutput, "human", false, "")
+			child := &cobra.Command{Use: "status"}
+			root.AddCommand(child)
+			if err := child.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := configureJSON(child)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configureJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && jsonOutput != tt.want {
+				t.Errorf("jsonOutput = %v, want %v", jsonOutput, tt.want)
+			}
+		})
+	}
+	jsonOutput, humanOutput = false, false
+}
@@ -2,13 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/export"
 	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 	"github.com/yuin/goldmark/ast"
@@ -38,6 +42,9 @@ Examples:
   jot peek "inbox.md" --toc                     # Show table of contents for entire file
   jot peek "work.md#projects" --toc             # Show TOC for projects subtree
   jot peek "work.md" --toc --short              # Show TOC with shortest selectors
+  jot peek "design.md#architecture" --render    # Label mermaid/plantuml diagrams
+  jot peek "blog.md#draft" --analyze            # Word count, reading time, readability
+  jot expand "lib/*.md#todo" | jot peek --stdin # Peek at every selector piped in
 
 This is useful for quickly reviewing files or specific sections without opening them in an editor.`,
 
@@ -55,12 +62,49 @@ This is useful for quickly reviewing files or specific sections without opening
 		info, _ := cmd.Flags().GetBool("info")
 		toc, _ := cmd.Flags().GetBool("toc")
 		short, _ := cmd.Flags().GetBool("short")
+		render, _ := cmd.Flags().GetBool("render")
+		analyze, _ := cmd.Flags().GetBool("analyze")
+		includeOffsets, _ := cmd.Flags().GetBool("include-offsets")
+		if includeOffsets && !cmdutil.IsJSONOutput(ctx.Cmd) {
+			return ctx.HandleUsage(fmt.Errorf("--include-offsets requires --json"))
+		}
+
+		if fromStdin, _ := cmd.Flags().GetBool("stdin"); fromStdin {
+			if toc || analyze || includeOffsets {
+				return ctx.HandleUsage(fmt.Errorf("--stdin cannot be combined with --toc, --analyze or --include-offsets"))
+			}
+			selectors, err := stdinSelectors(ws, args)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			first := true
+			return runBatch(ctx, "peek", selectors, func(item *BatchItem) error {
+				content, err := peekBatchItem(ws, item, noWorkspace)
+				if err != nil || ctx.IsJSONOutput() {
+					return err
+				}
+				if render {
+					content = export.LabelDiagrams(content)
+				}
+				if raw {
+					os.Stdout.Write(content)
+					return nil
+				}
+				if !first {
+					fmt.Println()
+				}
+				first = false
+				fmt.Printf("==> %s <==\n", item.Selector)
+				fmt.Println(strings.TrimRight(string(content), "\n"))
+				return nil
+			})
+		}
 
 		// Handle TOC mode
 		if toc {
 			if len(args) == 0 {
 				err := fmt.Errorf("table of contents requires a file or selector (e.g., 'inbox.md' or 'work.md#projects')")
-				return ctx.HandleError(err)
+				return ctx.HandleUsage(err)
 			}
 
 			if cmdutil.IsJSONOutput(ctx.Cmd) {
@@ -72,7 +116,7 @@ This is useful for quickly reviewing files or specific sections without opening
 		// Regular peek mode requires exactly one argument
 		if len(args) != 1 {
 			err := fmt.Errorf("peek requires a selector argument (e.g., 'inbox.md#meeting' or 'filename.md' for whole file)")
-			return ctx.HandleError(err)
+			return ctx.HandleUsage(err)
 		}
 
 		selector := args[0]
@@ -83,13 +127,18 @@ This is useful for quickly reviewing files or specific sections without opening
 			selector = enhancedSelector
 		}
 
+		// Handle analysis mode for both files and subtrees
+		if analyze {
+			return showContentAnalysis(ctx, ws, selector, noWorkspace)
+		}
+
 		// Check if this is a whole file request (no # selector) or a subtree request
 		if !strings.Contains(selector, "#") {
 			// Handle whole file display
 			if cmdutil.IsJSONOutput(ctx.Cmd) {
-				return showWholeFileJSON(ctx, ws, selector, noWorkspace)
+				return showWholeFileJSON(ctx, ws, selector, noWorkspace, includeOffsets)
 			}
-			return showWholeFile(ws, selector, raw, info, noWorkspace)
+			return showWholeFile(ws, selector, raw, info, render, noWorkspace)
 		}
 
 		// Parse the source path selector for subtree extraction
@@ -100,15 +149,26 @@ This is useful for quickly reviewing files or specific sections without opening
 		}
 
 		// Extract the subtree
-		subtree, err := ExtractSubtreeWithOptions(ws, sourcePath, noWorkspace)
+		subtree, err := peekSubtree(ws, sourcePath, noWorkspace)
 		if err != nil {
 			err := fmt.Errorf("failed to extract subtree: %w", err)
 			return ctx.HandleError(err)
 		}
+		if !noWorkspace {
+			recordSelectors(ws, selector)
+		}
 
 		// Handle JSON output for regular peek
 		if cmdutil.IsJSONOutput(ctx.Cmd) {
-			return outputPeekJSON(ctx, args[0], sourcePath, subtree, ws)
+			var anchors []PeekHeadingAnchor
+			if includeOffsets {
+				content, err := storage.ReadFile(cmdutil.ResolvePath(ws, sourcePath.File, noWorkspace))
+				if err != nil {
+					return ctx.HandleError(cmdutil.NewFileError("read", sourcePath.File, err))
+				}
+				anchors = headingAnchors(sourcePath.File, content, subtree)
+			}
+			return outputPeekJSON(ctx, args[0], sourcePath, subtree, ws, anchors)
 		}
 
 		// Display subtree information if requested
@@ -117,6 +177,11 @@ This is useful for quickly reviewing files or specific sections without opening
 			fmt.Println()
 		}
 
+		// Label diagram blocks that cannot be drawn in the terminal
+		if render {
+			subtree.Content = export.LabelDiagrams(subtree.Content)
+		}
+
 		// Display the subtree content
 		if raw {
 			// Raw mode: output just the content without any formatting
@@ -137,12 +202,12 @@ This is useful for quickly reviewing files or specific sections without opening
 }
 
 // showWholeFile displays the entire content of a file
-func showWholeFile(ws *workspace.Workspace, filename string, raw bool, info bool, noWorkspace bool) error {
+func showWholeFile(ws *workspace.Workspace, filename string, raw bool, info bool, render bool, noWorkspace bool) error {
 	// Construct full file path using the new resolution function
 	filePath := resolvePeekFilePath(ws, filename, noWorkspace)
 
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		return cmdutil.NewFileError("read", filename, err)
 	}
@@ -157,6 +222,11 @@ func showWholeFile(ws *workspace.Workspace, filename string, raw bool, info bool
 		fmt.Println()
 	}
 
+	// Label diagram blocks that cannot be drawn in the terminal
+	if render {
+		content = export.LabelDiagrams(content)
+	}
+
 	// Display the file content
 	if raw {
 		// Raw mode: output just the content without any formatting
@@ -177,12 +247,12 @@ func showWholeFile(ws *workspace.Workspace, filename string, raw bool, info bool
 }
 
 // showWholeFileJSON outputs the whole file content in JSON format
-func showWholeFileJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspace, filename string, noWorkspace bool) error {
+func showWholeFileJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspace, filename string, noWorkspace bool, includeOffsets bool) error {
 	// Use the same file resolution logic as the non-JSON path
 	filePath := resolvePeekFilePath(ws, filename, noWorkspace)
 
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		err := cmdutil.NewFileError("read", filename, err)
 		return ctx.HandleError(err)
@@ -200,10 +270,88 @@ func showWholeFileJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspace, fil
 		},
 		"metadata": cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
 	}
+	if includeOffsets {
+		response["headings"] = headingAnchors(filename, content, nil)
+	}
 
 	return cmdutil.OutputJSON(response)
 }
 
+// PeekAnalysis represents the JSON response for peek --analyze
+type PeekAnalysis struct {
+	Selector           string               `json:"selector"`
+	Words              int                  `json:"words"`
+	Sentences          int                  `json:"sentences"`
+	ReadingTimeMinutes float64              `json:"reading_time_minutes"`
+	ReadabilityScore   float64              `json:"readability_score"`
+	ReadabilityLevel   string               `json:"readability_level,omitempty"`
+	Headings           int                  `json:"headings"`
+	Links              int                  `json:"links"`
+	CodeBlocks         int                  `json:"code_blocks"`
+	Metadata           cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// showContentAnalysis reports statistics for a whole file or subtree
+func showContentAnalysis(ctx *cmdutil.CommandContext, ws *workspace.Workspace, selector string, noWorkspace bool) error {
+	var content []byte
+	if !strings.Contains(selector, "#") {
+		data, err := storage.ReadFile(resolvePeekFilePath(ws, selector, noWorkspace))
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", selector, err))
+		}
+		content = data
+	} else {
+		sourcePath, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("invalid selector: %w", err))
+		}
+		subtree, err := ExtractSubtreeWithOptions(ws, sourcePath, noWorkspace)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to extract subtree: %w", err))
+		}
+		content = subtree.Content
+	}
+
+	stats := markdown.AnalyzeContent(content)
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(PeekAnalysis{
+			Selector:           selector,
+			Words:              stats.Words,
+			Sentences:          stats.Sentences,
+			ReadingTimeMinutes: stats.ReadingTimeMinutes,
+			ReadabilityScore:   stats.ReadabilityScore,
+			ReadabilityLevel:   stats.ReadabilityLevel,
+			Headings:           stats.Headings,
+			Links:              stats.Links,
+			CodeBlocks:         stats.CodeBlocks,
+			Metadata:           cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	readability := "n/a"
+	if stats.ReadabilityLevel != "" {
+		readability = fmt.Sprintf("%.1f (%s)", stats.ReadabilityScore, stats.ReadabilityLevel)
+	}
+
+	cmdutil.ShowInfo("Analysis: %s", selector)
+	cmdutil.ShowInfo("  Words: %d", stats.Words)
+	cmdutil.ShowInfo("  Reading time: %s", formatReadingTime(stats.ReadingTimeMinutes))
+	cmdutil.ShowInfo("  Readability: %s", readability)
+	cmdutil.ShowInfo("  Headings: %d", stats.Headings)
+	cmdutil.ShowInfo("  Links: %d", stats.Links)
+	cmdutil.ShowInfo("  Code blocks: %d", stats.CodeBlocks)
+	return nil
+}
+
+// formatReadingTime formats an estimated reading time for display
+func formatReadingTime(minutes float64) string {
+	if minutes < 1 {
+		return "< 1 min"
+	}
+	return fmt.Sprintf("%d min", int(math.Ceil(minutes)))
+}
+
 // printSubtreeInfo displays metadata about the subtree
 func printSubtreeInfo(subtree *markdown.Subtree, filename string) {
 	cmdutil.ShowInfo("Subtree Information:")
@@ -270,7 +418,8 @@ func splitLines(content []byte) []string {
 // showTableOfContents displays a table of contents for a file or subtree
 func showTableOfContents(ws *workspace.Workspace, selector string, useShortSelectors bool, noWorkspace bool) error {
 	// Check if this is a simple file name or a path selector
-	var content []byte
+	var headings []HeadingInfo
+	var size int64
 	var filename string
 	var baseFilename string
 	var subtreePath string
@@ -283,12 +432,13 @@ func showTableOfContents(ws *workspace.Workspace, selector string, useShortSelec
 			return fmt.Errorf("invalid selector: %w", parseErr)
 		}
 
-		subtree, extractErr := ExtractSubtreeWithOptions(ws, sourcePath, noWorkspace)
+		subtree, extractErr := peekSubtree(ws, sourcePath, noWorkspace)
 		if extractErr != nil {
 			return fmt.Errorf("failed to extract subtree: %w", extractErr)
 		}
 
-		content = subtree.Content
+		headings = extractHeadingsFromContent(markdown.ParseDocument(subtree.Content), subtree.Content)
+		size = int64(len(subtree.Content))
 		baseFilename = sourcePath.File
 		subtreePath = strings.Join(sourcePath.Segments, "/")
 		filename = fmt.Sprintf("%s#%s", baseFilename, subtreePath)
@@ -313,26 +463,21 @@ func showTableOfContents(ws *workspace.Workspace, selector string, useShortSelec
 		}
 
 		// Check if file exists
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return fmt.Errorf("file not found: %s", selector)
+		if _, err := storage.Stat(filePath); os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, selector)
 		}
 
-		// Read file content
-		content, err = os.ReadFile(filePath)
+		headings, size, err = peekFileHeadings(filePath, selector)
 		if err != nil {
-			return cmdutil.NewFileError("read", selector, err)
+			return err
 		}
 	}
 
-	if len(content) == 0 {
+	if size == 0 {
 		cmdutil.ShowInfo("File %s is empty (no table of contents available)", filename)
 		return nil
 	}
 
-	// Parse document and extract headings
-	doc := markdown.ParseDocument(content)
-	headings := extractHeadingsFromContent(doc, content)
-
 	if len(headings) == 0 {
 		cmdutil.ShowInfo("No headings found in %s", filename)
 		return nil
@@ -355,7 +500,7 @@ func showTableOfContents(ws *workspace.Workspace, selector string, useShortSelec
 		// Check if this heading is unselectable
 		// Mark unselectable headings with a warning indicator
 		if unselectableHeadings[i] {
-			fmt.Printf(" ⚠️")
+			cmdutil.Printf(" ⚠️")
 		}
 		fmt.Println()
 
@@ -367,7 +512,7 @@ func showTableOfContents(ws *workspace.Workspace, selector string, useShortSelec
 			} else {
 				selectorHint = generateOptimalSelector(baseFilename, heading, headings)
 			}
-			fmt.Printf("%s%s\n", indent, fmt.Sprintf("  → %s", selectorHint))
+			cmdutil.Printf("%s%s\n", indent, fmt.Sprintf("  → %s", selectorHint))
 		}
 
 		// Add spacing between entries for readability
@@ -411,6 +556,7 @@ type HeadingInfo struct {
 // extractHeadingsFromContent extracts all headings from markdown content
 func extractHeadingsFromContent(doc ast.Node, content []byte) []HeadingInfo {
 	var headings []HeadingInfo
+	lines := newLineIndex(content)
 
 	// Walk the AST to find all headings
 	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -422,7 +568,7 @@ func extractHeadingsFromContent(doc ast.Node, content []byte) []HeadingInfo {
 			headingText := markdown.ExtractHeadingText(heading, content)
 			if strings.TrimSpace(headingText) != "" {
 				offset := markdown.GetNodeOffset(heading, content)
-				lineNum := markdown.CalculateLineNumber(content, offset)
+				lineNum := lines.line(offset)
 
 				headings = append(headings, HeadingInfo{
 					Text:  headingText,
@@ -550,7 +696,7 @@ func (t *HeadingTrie) markLeavesAndPathsRecursive(node *TrieNode, path []string)
 
 // normalizeForMatching normalizes text for case-insensitive contains matching
 func normalizeForMatching(text string) string {
-	return strings.ToLower(strings.TrimSpace(text))
+	return markdown.Fold(text)
 }
 
 // GenerateSelector creates an accurate selector path for a heading
@@ -756,6 +902,10 @@ func init() {
 	peekCmd.Flags().BoolP("info", "i", false, "Show subtree metadata information")
 	peekCmd.Flags().BoolP("toc", "t", false, "Show table of contents for file or subtree")
 	peekCmd.Flags().BoolP("short", "s", false, "Generate shortest possible selectors (use with --toc)")
+	peekCmd.Flags().Bool("render", false, "Render content for terminal display, labelling diagram blocks")
+	peekCmd.Flags().Bool("analyze", false, "Report word count, reading time, readability, links and code blocks")
+	peekCmd.Flags().Bool("include-offsets", false, "With --json, list each heading with its byte offsets, lines and selector")
+	peekCmd.Flags().Bool("stdin", false, "Peek at each selector read from stdin, one per line")
 	peekCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
 
 	// Add to root command
@@ -804,13 +954,7 @@ func buildHierarchicalPath(target HeadingInfo, allHeadings []HeadingInfo) []stri
 	var path []string
 
 	// Find the target heading in the list
-	targetIndex := -1
-	for i, h := range allHeadings {
-		if h.Line == target.Line && h.Text == target.Text && h.Level == target.Level {
-			targetIndex = i
-			break
-		}
-	}
+	targetIndex := headingIndex(target, allHeadings)
 
 	if targetIndex == -1 {
 		return []string{target.Text}
@@ -840,6 +984,17 @@ func buildHierarchicalPath(target HeadingInfo, allHeadings []HeadingInfo) []stri
 	return path
 }
 
+// headingIndex finds target among headings, which are in line order, or -1
+func headingIndex(target HeadingInfo, allHeadings []HeadingInfo) int {
+	i := sort.Search(len(allHeadings), func(i int) bool { return allHeadings[i].Line >= target.Line })
+	for ; i < len(allHeadings) && allHeadings[i].Line == target.Line; i++ {
+		if h := allHeadings[i]; h.Text == target.Text && h.Level == target.Level {
+			return i
+		}
+	}
+	return -1
+}
+
 // detectUnselectableHeadings identifies headings that cannot be uniquely selected
 func detectUnselectableHeadings(headings []HeadingInfo) map[int]bool {
 	unselectable := make(map[int]bool)
@@ -849,7 +1004,7 @@ func detectUnselectableHeadings(headings []HeadingInfo) map[int]bool {
 
 	for i, heading := range headings {
 		path := buildHierarchicalPath(heading, headings)
-		pathKey := strings.ToLower(strings.Join(path, "/"))
+		pathKey := markdown.Fold(strings.Join(path, "/"))
 		pathGroups[pathKey] = append(pathGroups[pathKey], i)
 	}
 
@@ -892,7 +1047,7 @@ func generateShortSelector(filename string, target HeadingInfo, allHeadings []He
 		"loops":      "l",
 	}
 
-	lowerTarget := strings.ToLower(target.Text)
+	lowerTarget := markdown.Fold(target.Text)
 	if shortcut, exists := singleLetterShortcuts[lowerTarget]; exists {
 		// Check if this single letter is unique using jot's actual contains matching
 		matchCount := 0
@@ -1119,6 +1274,7 @@ type PeekResponse struct {
 	FileInfo        PeekFileInfo         `json:"file_info"`
 	Extraction      *PeekExtraction      `json:"extraction,omitempty"`
 	TableOfContents *PeekTOC             `json:"table_of_contents,omitempty"`
+	Headings        []PeekHeadingAnchor  `json:"headings,omitempty"` // With --include-offsets
 	Metadata        cmdutil.JSONMetadata `json:"metadata"`
 }
 
@@ -1155,7 +1311,7 @@ type PeekTOCHeading struct {
 }
 
 // outputPeekJSON outputs JSON response for regular peek mode
-func outputPeekJSON(ctx *cmdutil.CommandContext, selector string, sourcePath *markdown.HeadingPath, subtree *markdown.Subtree, ws *workspace.Workspace) error {
+func outputPeekJSON(ctx *cmdutil.CommandContext, selector string, sourcePath *markdown.HeadingPath, subtree *markdown.Subtree, ws *workspace.Workspace, anchors []PeekHeadingAnchor) error {
 	pathUtil := cmdutil.NewPathUtil(ws)
 	// Build file info
 	filePath := pathUtil.WorkspaceJoin(sourcePath.File)
@@ -1165,7 +1321,7 @@ func outputPeekJSON(ctx *cmdutil.CommandContext, selector string, sourcePath *ma
 
 	fileExists := true
 	var lastModified *string
-	if info, err := os.Stat(filePath); err == nil {
+	if info, err := storage.Stat(filePath); err == nil {
 		modTime := info.ModTime().Format(time.RFC3339)
 		lastModified = &modTime
 	} else {
@@ -1206,6 +1362,7 @@ func outputPeekJSON(ctx *cmdutil.CommandContext, selector string, sourcePath *ma
 			EndLine:       0, // We don't have line info from markdown.Subtree
 			ContentOffset: [2]int{subtree.StartOffset, subtree.EndOffset},
 		},
+		Headings: anchors,
 		Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
 	}
 
@@ -1216,7 +1373,8 @@ func outputPeekJSON(ctx *cmdutil.CommandContext, selector string, sourcePath *ma
 func showTableOfContentsJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspace, selector string, useShortSelectors bool) error {
 	pathUtil := cmdutil.NewPathUtil(ws)
 	// Parse selector to determine if it's file-only or includes path
-	var content []byte
+	var headings []HeadingInfo
+	var size int64
 	var baseFilename string
 	var subtreePath string
 	var filePath string
@@ -1230,12 +1388,13 @@ func showTableOfContentsJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspac
 			return ctx.HandleError(fmt.Errorf("invalid selector: %w", parseErr))
 		}
 
-		subtree, extractErr := ExtractSubtreeWithOptions(ws, sourcePath, false) // TODO: Add noWorkspace support to JSON functions
+		subtree, extractErr := peekSubtree(ws, sourcePath, false) // TODO: Add noWorkspace support to JSON functions
 		if extractErr != nil {
 			return ctx.HandleError(fmt.Errorf("failed to extract subtree: %w", extractErr))
 		}
 
-		content = subtree.Content
+		headings = extractHeadingsFromContent(markdown.ParseDocument(subtree.Content), subtree.Content)
+		size = int64(len(subtree.Content))
 		baseFilename = sourcePath.File
 		subtreePath = strings.Join(sourcePath.Segments, "/")
 		isFullFile = false
@@ -1261,18 +1420,17 @@ func showTableOfContentsJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspac
 		}
 
 		// Check if file exists
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return ctx.HandleError(fmt.Errorf("file not found: %s", selector))
+		if _, err := storage.Stat(filePath); os.IsNotExist(err) {
+			return ctx.HandleError(fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, selector))
 		}
 
-		// Read file content
-		content, err = os.ReadFile(filePath)
+		headings, size, err = peekFileHeadings(filePath, selector)
 		if err != nil {
 			return ctx.HandleError(err)
 		}
 	}
 
-	if len(content) == 0 {
+	if size == 0 {
 		// Empty file case
 		response := PeekResponse{
 			Selector: selector,
@@ -1290,10 +1448,6 @@ func showTableOfContentsJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspac
 		return cmdutil.OutputJSON(response)
 	}
 
-	// Parse document and extract headings
-	doc := markdown.ParseDocument(content)
-	headings := extractHeadingsFromContent(doc, content)
-
 	if len(headings) == 0 {
 		// No headings case
 		response := PeekResponse{
@@ -1355,13 +1509,7 @@ func showTableOfContentsJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspac
 // buildPathToHeading builds a hierarchical path array for a heading based on the document structure
 func buildPathToHeading(target HeadingInfo, allHeadings []HeadingInfo) []string {
 	// Find target index
-	var targetIndex int = -1
-	for i, h := range allHeadings {
-		if h.Text == target.Text && h.Level == target.Level && h.Line == target.Line {
-			targetIndex = i
-			break
-		}
-	}
+	targetIndex := headingIndex(target, allHeadings)
 
 	if targetIndex == -1 {
 		return []string{strings.ToLower(target.Text)}
@@ -1441,7 +1589,7 @@ func parseEnhancedSelector(ws *workspace.Workspace, selector string) (string, er
 	}
 
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		// Can't read file, return original selector
 		return selector, nil
@@ -1486,3 +1634,35 @@ func resolvePeekFilePath(ws *workspace.Workspace, filename string, noWorkspace b
 	}
 	return filename // Fallback
 }
+
+// peekBatchItem reads the file or subtree an item of a --stdin batch names,
+// filling in the item and returning the content
+func peekBatchItem(ws *workspace.Workspace, item *BatchItem, noWorkspace bool) ([]byte, error) {
+	selector := item.Selector
+	if enhanced, err := parseEnhancedSelector(ws, selector); err == nil {
+		selector = enhanced
+	}
+
+	if !strings.Contains(selector, "#") {
+		content, err := storage.ReadFile(resolvePeekFilePath(ws, selector, noWorkspace))
+		if err != nil {
+			return nil, cmdutil.NewFileError("read", selector, err)
+		}
+		item.File = selector
+		item.Content = string(content)
+		return content, nil
+	}
+
+	sourcePath, err := markdown.ParsePath(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	subtree, err := peekSubtree(ws, sourcePath, noWorkspace)
+	if err != nil {
+		return nil, err
+	}
+	item.File = sourcePath.File
+	item.Heading = subtree.Heading
+	item.Content = string(subtree.Content)
+	return subtree.Content, nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// peekOutlineThreshold is the file size from which peek reads headings line
+// by line instead of loading and parsing the whole file
+const peekOutlineThreshold = 8 << 20
+
+// peekSubtree extracts the subtree a selector names. Large files on the
+// filesystem are scanned for their headings, and only the matching subtree
+// is read.
+func peekSubtree(ws *workspace.Workspace, sourcePath *markdown.HeadingPath, noWorkspace bool) (*markdown.Subtree, error) {
+	filePath := cmdutil.ResolvePath(ws, sourcePath.File, noWorkspace)
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() < peekOutlineThreshold || !storage.IsFilesystem() {
+		return ExtractSubtreeWithOptions(ws, sourcePath, noWorkspace)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", sourcePath.File, err)
+	}
+	defer file.Close()
+
+	outline, err := markdown.ScanOutline(file)
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", sourcePath.File, err)
+	}
+	return outline.FindSubtree(file, sourcePath)
+}
+
+// peekFileHeadings lists a whole file's headings for its table of contents,
+// along with the file's size. Large files are scanned line by line.
+func peekFileHeadings(filePath, name string) ([]HeadingInfo, int64, error) {
+	info, err := storage.Stat(filePath)
+	if err != nil {
+		return nil, 0, cmdutil.NewFileError("read", name, err)
+	}
+
+	if info.Size() < peekOutlineThreshold || !storage.IsFilesystem() {
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return nil, 0, cmdutil.NewFileError("read", name, err)
+		}
+		return extractHeadingsFromContent(markdown.ParseDocument(content), content), int64(len(content)), nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, cmdutil.NewFileError("read", name, err)
+	}
+	defer file.Close()
+
+	outline, err := markdown.ScanOutline(file)
+	if err != nil {
+		return nil, 0, cmdutil.NewFileError("read", name, err)
+	}
+	skeleton := outline.Skeleton()
+	headings := extractHeadingsFromContent(markdown.ParseDocument(skeleton), skeleton)
+	for i := range headings {
+		headings[i].Line = outline.FileLine(headings[i].Line)
+	}
+	return headings, outline.Size, nil
+}
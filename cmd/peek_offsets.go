@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/johncoder/jot/internal/markdown"
+)
+
+// PeekHeadingAnchor locates a heading and its subtree in the file, for
+// editors that fold or navigate by heading. Offsets are bytes into the
+// file, end exclusive; lines are 1-based and inclusive.
+type PeekHeadingAnchor struct {
+	Heading     string              `json:"heading"`
+	Level       int                 `json:"level"`
+	Selector    string              `json:"selector"`
+	StartOffset int                 `json:"start_offset"`
+	BodyOffset  int                 `json:"body_offset"` // First byte after the heading line
+	EndOffset   int                 `json:"end_offset"`
+	StartLine   int                 `json:"start_line"`
+	EndLine     int                 `json:"end_line"` // Last non-blank line of the subtree
+	Children    []PeekHeadingAnchor `json:"children,omitempty"`
+}
+
+// headingAnchors returns the anchors of the subtrees in a file, nested by
+// heading level. With root, only root and the headings under it are
+// returned; otherwise every heading in the file is.
+func headingAnchors(file string, content []byte, root *markdown.Subtree) []PeekHeadingAnchor {
+	content = maskFrontmatter(content)
+	doc := markdown.ParseDocument(content)
+	lines := newLineIndex(content)
+
+	// Full paths by line, for selectors
+	paths := make(map[int][]string)
+	for _, heading := range markdown.FindAllHeadings(doc, content) {
+		paths[lines.line(heading.Offset)] = heading.Path
+	}
+
+	var build func(subtrees []*markdown.Subtree) []PeekHeadingAnchor
+	build = func(subtrees []*markdown.Subtree) []PeekHeadingAnchor {
+		anchors := make([]PeekHeadingAnchor, 0, len(subtrees))
+		for _, subtree := range subtrees {
+			startLine := lines.line(subtree.StartOffset)
+			bodyOffset := subtree.EndOffset
+			if end := bytes.IndexByte(content[subtree.StartOffset:subtree.EndOffset], '\n'); end >= 0 {
+				bodyOffset = subtree.StartOffset + end + 1
+			}
+			last := subtree.StartOffset + len(bytes.TrimRight(content[subtree.StartOffset:subtree.EndOffset], " \t\n"))
+
+			anchors = append(anchors, PeekHeadingAnchor{
+				Heading:     subtree.Heading,
+				Level:       subtree.Level,
+				Selector:    file + "#" + strings.Join(paths[startLine], "/"),
+				StartOffset: subtree.StartOffset,
+				BodyOffset:  bodyOffset,
+				EndOffset:   subtree.EndOffset,
+				StartLine:   startLine,
+				EndLine:     lines.line(last - 1),
+				Children:    build(markdown.ChildSubtrees(doc, content, subtree)),
+			})
+		}
+		return anchors
+	}
+
+	if root != nil {
+		return build([]*markdown.Subtree{root})
+	}
+	return build(markdown.TopLevelSubtrees(doc, content))
+}
+
+// lineIndex maps byte offsets to 1-based line numbers
+type lineIndex []int
+
+func newLineIndex(content []byte) lineIndex {
+	var newlines lineIndex
+	for i, b := range content {
+		if b == '\n' {
+			newlines = append(newlines, i)
+		}
+	}
+	return newlines
+}
+
+// line returns the line holding offset: one more than the newlines before it
+func (l lineIndex) line(offset int) int {
+	return sort.SearchInts(l, offset) + 1
+}
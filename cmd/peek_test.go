@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -616,3 +617,36 @@ func TestShortSelectorGeneration(t *testing.T) {
 		})
 	}
 }
+
+func TestPeekDuplicateHeadings(t *testing.T) {
+	ws := refileWorkspace(t, map[string]string{
+		"u.md": "# U\n\n## Dup\nfirst\n\n## Dup\nsecond\n",
+	})
+
+	path, _ := markdown.ParsePath("u.md#dup")
+	if _, err := peekSubtree(ws, path, false); !errors.Is(err, markdown.ErrAmbiguous) {
+		t.Errorf("peek u.md#dup: err = %v, want multiple headings match", err)
+	}
+
+	path, _ = markdown.ParsePath("u.md#dup-1")
+	subtree, err := peekSubtree(ws, path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(subtree.Content), "second") {
+		t.Errorf("peek u.md#dup-1 = %q, want the second Dup", subtree.Content)
+	}
+}
+
+func TestDetectUnselectableHeadingsFoldsCase(t *testing.T) {
+	headings := []HeadingInfo{
+		{Text: "Notes", Level: 1, Line: 1},
+		{Text: "Straße", Level: 2, Line: 3},
+		{Text: "STRASSE", Level: 2, Line: 5},
+		{Text: "Other", Level: 2, Line: 7},
+	}
+	unselectable := detectUnselectableHeadings(headings)
+	if !unselectable[1] || !unselectable[2] || unselectable[3] {
+		t.Errorf("unselectable = %v, want the two spellings of Straße that one selector matches", unselectable)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/johncoder/jot/internal/rpc"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// previewTimeout bounds a preview read through 'jot serve'
+const previewTimeout = 2 * time.Second
+
+var previewSocketFlag string
+
+var previewCmd = &cobra.Command{
+	Use:   "preview SELECTOR",
+	Short: "Print a selector for an FZF preview window",
+	Long: `Print the content of a file or subtree for an FZF preview window.
+
+With --socket, the content is read from the 'jot serve' daemon listening on
+that socket, which keeps the workspace loaded, so moving through a long list
+does not parse the file again for every highlighted line. If the daemon does
+not answer, or refuses the request, the selector is shown as 'jot peek'
+would show it.
+
+FZF pickers use this automatically when 'jot serve' is running.`,
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	// Previews run on every keypress: skip the per-command setup unless the
+	// daemon cannot answer, and never record a snapshot
+	PersistentPreRunE:  func(cmd *cobra.Command, args []string) error { return nil },
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if previewSocketFlag != "" {
+			if content, err := daemonPeek(previewSocketFlag, args[0]); err == nil {
+				fmt.Print(content)
+				return nil
+			}
+		}
+		if err := prepareCommand(peekCmd, args); err != nil {
+			return err
+		}
+		return peekCmd.RunE(peekCmd, args)
+	},
+}
+
+// daemonPeek reads selector through the 'jot serve' daemon on socket
+func daemonPeek(socket, selector string) (string, error) {
+	conn, err := net.DialTimeout("unix", socket, daemonDialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(previewTimeout))
+
+	var result rpcPeekResult
+	params := map[string]string{"selector": selector}
+	if err := rpc.Call(conn, bufio.NewReader(conn), "preview", "peek", params, &result); err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// daemonSocket returns the socket FZF previews should read through: the
+// workspace's 'jot serve' socket when the daemon is running, otherwise ""
+func daemonSocket(ws *workspace.Workspace) string {
+	if ws == nil {
+		return ""
+	}
+	daemon := contextDaemon(ws)
+	if !daemon.Running {
+		return ""
+	}
+	return daemon.Socket
+}
+
+func init() {
+	previewCmd.Flags().StringVar(&previewSocketFlag, "socket", "", "Socket of a running 'jot serve' to read through")
+}
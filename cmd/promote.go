@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote SELECTOR",
+	Short: "Move a subtree one heading level up",
+	Long: `Move a subtree one heading level up, nested headings with it.
+
+The subtree leaves its parent and becomes the parent's next sibling: it is
+moved past any later siblings, which stay where they are, so it does not
+adopt them as children.
+
+Examples:
+  jot promote "work.md#projects/frontend/design review"
+  jot promote "notes.md#ideas/garden" --by 2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runShiftLevel(cmd, args[0], -1)
+	},
+}
+
+var demoteCmd = &cobra.Command{
+	Use:   "demote SELECTOR",
+	Short: "Move a subtree one heading level down",
+	Long: `Move a subtree one heading level down, nested headings with it.
+
+The subtree becomes the last child of the sibling heading before it. A
+subtree with no sibling before it is refused, as is one that would land more
+than a level below the heading before it, since either skips a level.
+
+Examples:
+  jot demote "work.md#projects/design review"
+  jot demote "notes.md#garden" --by 2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runShiftLevel(cmd, args[0], 1)
+	},
+}
+
+// ShiftLevelResponse is the JSON response for promote and demote
+type ShiftLevelResponse struct {
+	Operation   string               `json:"operation"`
+	Selector    string               `json:"selector"`
+	File        string               `json:"file"`
+	Heading     string               `json:"heading"`
+	FromLevel   int                  `json:"from_level"`
+	ToLevel     int                  `json:"to_level"`
+	NewSelector string               `json:"new_selector"`
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// runShiftLevel moves the subtree at selector --by levels in direction:
+// -1 to promote, 1 to demote
+func runShiftLevel(cmd *cobra.Command, selector string, direction int) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := workspace.RequireWorkspace()
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	by, _ := cmd.Flags().GetInt("by")
+	if by < 1 {
+		return ctx.HandleValidation("by", fmt.Sprint(by), fmt.Errorf("must be at least 1"))
+	}
+
+	path, err := markdown.ParsePath(selector)
+	if err != nil {
+		return ctx.HandleValidation("selector", selector, err)
+	}
+
+	filePath := cmdutil.ResolveWorkspaceRelativePath(ws, path.File)
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		return ctx.HandleError(cmdutil.NewFileError("read", path.File, err))
+	}
+	doc := markdown.ParseDocument(content)
+	subtree, err := markdown.FindSubtree(doc, content, path)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	headings := markdown.FindAllHeadings(doc, content)
+
+	level := subtree.Level + direction*by
+	if level < 1 {
+		return ctx.HandleError(fmt.Errorf("'%s' is at level %d and cannot be promoted %d level(s)", subtree.Heading, subtree.Level, by))
+	}
+	if deepest := deepestLevel(headings, subtree); deepest+direction*by > 6 {
+		return ctx.HandleError(fmt.Errorf("'%s' has headings at level %d, which cannot be demoted past level 6", subtree.Heading, deepest))
+	}
+	if direction > 0 {
+		if before := headingBefore(headings, subtree); before != nil && level > before.Level+1 {
+			return ctx.HandleError(fmt.Errorf("'%s' cannot be demoted %d level(s): the heading before it, '%s', is at level %d, so it would skip a level", subtree.Heading, by, before.Text, before.Level))
+		}
+	}
+
+	if err := checkProtectedRange(ws, path.File, subtree.StartOffset, subtree.EndOffset); err != nil {
+		return ctx.HandleError(err)
+	}
+
+	op := &RefileOperation{
+		SourcePath:         filePath,
+		DestPath:           filePath,
+		Subtree:            subtree,
+		TransformedContent: TransformSubtreeLevel(subtree, level),
+		InsertOffset:       afterText(content, subtree.MarkerStart()),
+		TargetLevel:        level,
+	}
+	if direction < 0 {
+		if offset := promoteOffset(content, headings, subtree, level); offset >= subtree.EndOffset {
+			op.InsertOffset = offset
+		}
+	}
+	if err := op.Execute(); err != nil {
+		return ctx.HandleOperationError(cmd.Name(), err)
+	}
+
+	newSelector := shiftedSelector(ws, path.File, subtree.Heading, level)
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(ShiftLevelResponse{
+			Operation:   cmd.Name(),
+			Selector:    selector,
+			File:        path.File,
+			Heading:     subtree.Heading,
+			FromLevel:   subtree.Level,
+			ToLevel:     level,
+			NewSelector: newSelector,
+			Metadata:    cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+		})
+	}
+
+	cmdutil.ShowSuccess("✓ Moved '%s' from level %d to level %d", subtree.Heading, subtree.Level, level)
+	cmdutil.ShowInfo("  Now at: %s", newSelector)
+	return nil
+}
+
+// deepestLevel returns the deepest heading level within subtree
+func deepestLevel(headings []markdown.HeadingInfo, subtree *markdown.Subtree) int {
+	deepest := subtree.Level
+	for _, h := range headings {
+		if h.Offset >= subtree.StartOffset && h.Offset < subtree.EndOffset && h.Level > deepest {
+			deepest = h.Level
+		}
+	}
+	return deepest
+}
+
+// headingBefore returns the last heading before subtree, which a demoted
+// subtree nests under, or nil when it is the file's first heading
+func headingBefore(headings []markdown.HeadingInfo, subtree *markdown.Subtree) *markdown.HeadingInfo {
+	var before *markdown.HeadingInfo
+	for i := range headings {
+		if headings[i].Offset >= subtree.StartOffset {
+			break
+		}
+		before = &headings[i]
+	}
+	return before
+}
+
+// promoteOffset returns where a subtree promoted to level goes: after the
+// headings that follow it until one at level or above, so later siblings
+// are not adopted as its children
+func promoteOffset(content []byte, headings []markdown.HeadingInfo, subtree *markdown.Subtree, level int) int {
+	for _, h := range headings {
+		if h.Offset >= subtree.EndOffset && h.Level <= level {
+			offset := h.Offset
+			for offset > 0 && content[offset-1] != '\n' {
+				offset--
+			}
+			return afterText(content, offset)
+		}
+	}
+	return afterText(content, len(content))
+}
+
+// afterText moves offset back over blank lines, so content inserted there
+// follows the text of the section before it and keeps the blank line after
+func afterText(content []byte, offset int) int {
+	for offset > 1 && content[offset-1] == '\n' && content[offset-2] == '\n' {
+		offset--
+	}
+	return offset
+}
+
+// shiftedSelector returns a full-path selector for heading at level after
+// a promote or demote, by re-reading the file
+func shiftedSelector(ws *workspace.Workspace, file, heading string, level int) string {
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		return file + "#" + heading
+	}
+	for _, h := range markdown.FindAllHeadings(markdown.ParseDocument(content), content) {
+		if h.Level == level && h.Text == heading {
+			return file + "#" + strings.Join(h.Path, "/")
+		}
+	}
+	return file + "#" + heading
+}
+
+func init() {
+	promoteCmd.Flags().Int("by", 1, "Number of levels to promote")
+	demoteCmd.Flags().Int("by", 1, "Number of levels to demote")
+	promoteCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected files and subtrees")
+	demoteCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected files and subtrees")
+}
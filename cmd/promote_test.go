@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestShiftLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		selector  string
+		direction int
+		by        string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "promote past later siblings",
+			selector:  "work.md#Work/Projects/Frontend",
+			direction: -1,
+			want:      "# Work\n\n## Projects\n\n### Backend\n\n## Frontend\n\n### Design\n\n## Notes\n",
+		},
+		{
+			name:      "demote under the previous sibling",
+			selector:  "work.md#Work/Notes",
+			direction: 1,
+			want:      "# Work\n\n## Projects\n\n### Frontend\n\n#### Design\n\n### Backend\n\n### Notes\n",
+		},
+		{
+			name:      "demote a first child",
+			selector:  "work.md#Work/Projects/Frontend",
+			direction: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "demote two levels under a sibling's child",
+			selector:  "work.md#Work/Notes",
+			direction: 1,
+			by:        "2",
+			want:      "# Work\n\n## Projects\n\n### Frontend\n\n#### Design\n\n### Backend\n\n#### Notes\n",
+		},
+		{
+			name:      "demote past the heading before it",
+			selector:  "work.md#Work/Notes",
+			direction: 1,
+			by:        "3",
+			wantErr:   true,
+		},
+		{
+			name:      "promote past level 1",
+			selector:  "work.md#Work/Projects",
+			direction: -1,
+			by:        "2",
+			wantErr:   true,
+		},
+		{
+			name:      "demote nested headings past level 6",
+			selector:  "work.md#Work/Projects",
+			direction: 1,
+			by:        "3",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "# Work\n\n## Projects\n\n### Frontend\n\n#### Design\n\n### Backend\n\n## Notes\n"
+			ws := refileWorkspace(t, map[string]string{"work.md": content})
+			t.Chdir(ws.Root)
+			t.Setenv("HOME", ws.Root)
+
+			cmd := &cobra.Command{Use: "shift"}
+			cmd.Flags().Int("by", 1, "")
+			if tt.by != "" {
+				if err := cmd.Flags().Set("by", tt.by); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := runShiftLevel(cmd, tt.selector, tt.direction)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runShiftLevel() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			got, _ := os.ReadFile(filepath.Join(ws.Root, "work.md"))
+			want := tt.want
+			if tt.wantErr {
+				want = content
+			}
+			if string(got) != want {
+				t.Errorf("work.md = %q, want %q", got, want)
+			}
+		})
+	}
+}
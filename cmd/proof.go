@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/proof"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var proofCmd = &cobra.Command{
+	Use:   "proof SELECTOR",
+	Short: "Check spelling and grammar in a file or subtree",
+	Long: `Run a file or subtree through a spelling or grammar checker and report
+each finding with its file, line, column and heading path.
+
+Code blocks, inline code and HTML are blanked out before checking so only
+prose is reported. Line numbers always refer to the original file.
+
+Checkers:
+  hunspell       Spelling via "hunspell -a" (default)
+  languagetool   Spelling and grammar via the languagetool command line client
+  <command>      Any shell command that reads text on stdin and prints
+                 findings as "LINE:COLUMN: MESSAGE" lines
+
+The checker is chosen by --checker, then "proof_checker" in .jot/config.json,
+then hunspell.
+
+Examples:
+  jot proof notes.md
+  jot proof "inbox.md#meeting notes"
+  jot proof design.md --checker languagetool
+  jot proof design.md --checker "aspell list | my-locator"
+  jot proof design.md --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		checkerSpec, _ := cmd.Flags().GetString("checker")
+		if checkerSpec == "" && ws != nil && ws.Config != nil {
+			checkerSpec = ws.Config.ProofChecker
+			if spec := strings.TrimSpace(checkerSpec); spec != "" && spec != proof.CheckerHunspell && spec != proof.CheckerLanguageTool {
+				if err := safemode.Check("the proof checker from .jot/config.json"); err != nil {
+					return ctx.HandleError(err)
+				}
+			}
+		}
+		checker := proof.NewChecker(checkerSpec)
+
+		findings, filePath, err := runProof(ws, args[0], checker, noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response := ProofResponse{
+				Operation: "proof",
+				Selector:  args[0],
+				File:      filePath,
+				Checker:   checker.Name(),
+				Findings:  findings,
+				Summary: ProofSummary{
+					TotalFindings: len(findings),
+					Clean:         len(findings) == 0,
+				},
+				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			}
+			return cmdutil.OutputJSON(response)
+		}
+
+		if len(findings) == 0 {
+			cmdutil.ShowSuccess("✓ No problems found in '%s'", args[0])
+			return nil
+		}
+
+		for _, finding := range findings {
+			location := fmt.Sprintf("%s:%d:%d", filePath, finding.Line, finding.Column)
+			if finding.HeadingPath != "" {
+				location += " [" + finding.HeadingPath + "]"
+			}
+			fmt.Printf("%s: %s\n", location, finding.Message)
+			if len(finding.Suggestions) > 0 {
+				fmt.Printf("    suggestions: %s\n", strings.Join(finding.Suggestions, ", "))
+			}
+		}
+		fmt.Printf("\n%d problem(s) found by %s\n", len(findings), checker.Name())
+		return nil
+	},
+}
+
+// ProofResponse represents the JSON response for the proof command
+type ProofResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"`
+	File      string               `json:"file"`
+	Checker   string               `json:"checker"`
+	Findings  []ProofFinding       `json:"findings"`
+	Summary   ProofSummary         `json:"summary"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// ProofFinding is a checker finding mapped back to its location in the file
+type ProofFinding struct {
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	EndLine     int      `json:"end_line"`
+	EndColumn   int      `json:"end_column"`
+	HeadingPath string   `json:"heading_path,omitempty"`
+	Selector    string   `json:"selector,omitempty"`
+	Text        string   `json:"text,omitempty"`
+	Message     string   `json:"message"`
+	Rule        string   `json:"rule,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// ProofSummary summarizes the proof results
+type ProofSummary struct {
+	TotalFindings int  `json:"total_findings"`
+	Clean         bool `json:"clean"`
+}
+
+// runProof checks a file or subtree and maps findings to file positions
+func runProof(ws *workspace.Workspace, selector string, checker proof.Checker, noWorkspace bool) ([]ProofFinding, string, error) {
+	file := selector
+	var headingPath *markdown.HeadingPath
+	if strings.Contains(selector, "#") {
+		parsed, err := markdown.ParsePath(selector)
+		if err != nil {
+			return nil, "", cmdutil.NewValidationError("selector", selector, err)
+		}
+		headingPath = parsed
+		file = parsed.File
+	}
+
+	filePath := cmdutil.ResolvePath(ws, file, noWorkspace)
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		return nil, "", cmdutil.NewFileError("read", file, err)
+	}
+
+	start, end := 0, len(content)
+	if headingPath != nil {
+		subtree, err := markdown.FindSubtree(markdown.ParseDocument(content), content, headingPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find subtree: %w", err)
+		}
+		start, end = subtree.StartOffset, subtree.EndOffset
+	}
+
+	masked := proof.MaskCode(content)
+	results, err := checker.Check(masked[start:end])
+	if err != nil {
+		return nil, "", cmdutil.NewExternalError(checker.Name(), nil, err)
+	}
+
+	findings := make([]ProofFinding, 0, len(results))
+	lines := make([]int, 0, len(results))
+	for _, result := range results {
+		offset := start + result.Offset
+		line, column := markdown.CalculateLineColumn(content, offset)
+		endLine, endColumn := markdown.CalculateLineColumn(content, offset+result.Length)
+		findings = append(findings, ProofFinding{
+			Line:        line,
+			Column:      column,
+			EndLine:     endLine,
+			EndColumn:   endColumn,
+			Text:        result.Text,
+			Message:     result.Message,
+			Rule:        result.Rule,
+			Suggestions: result.Suggestions,
+		})
+		lines = append(lines, line)
+	}
+
+	headings, err := markdown.FindNearestHeadingsForLines(content, lines)
+	if err == nil {
+		for i := range findings {
+			findings[i].HeadingPath = headings[findings[i].Line]
+			if findings[i].HeadingPath != "" {
+				findings[i].Selector = file + "#" + findings[i].HeadingPath
+			}
+		}
+	}
+
+	return findings, filePath, nil
+}
+
+func init() {
+	proofCmd.Flags().String("checker", "", "Checker to run: hunspell, languagetool, or a shell command")
+	proofCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
+}
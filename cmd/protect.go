@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/protect"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// forceProtected allows commands to modify protected files and subtrees,
+// and to write binary or very large content
+var forceProtected bool
+
+// checkProtectedRange refuses to remove or rewrite bytes [start, end) of a
+// file when they overlap a protected region
+func checkProtectedRange(ws *workspace.Workspace, file string, start, end int) error {
+	regions, relFile, err := protectedRegions(ws, file)
+	if err != nil || len(regions) == 0 {
+		return err
+	}
+	if region := protect.Overlapping(regions, start, end); region != nil {
+		return protectedError(relFile, region)
+	}
+	return nil
+}
+
+// checkProtectedDestination refuses to insert under a heading inside a
+// protected region, or anywhere in a protected file
+func checkProtectedDestination(ws *workspace.Workspace, dest *DestinationTarget) error {
+	regions, relFile, err := protectedRegions(ws, dest.File)
+	if err != nil || len(regions) == 0 {
+		return err
+	}
+	if region := protect.Containing(regions, dest.HeadingOffset); region != nil {
+		return protectedError(relFile, region)
+	}
+	return nil
+}
+
+// checkProtectedFile refuses to write anywhere in a file protected as a
+// whole
+func checkProtectedFile(ws *workspace.Workspace, file string) error {
+	regions, relFile, err := protectedRegions(ws, file)
+	if err != nil {
+		return err
+	}
+	for i := range regions {
+		if regions[i].WholeFile() {
+			return protectedError(relFile, &regions[i])
+		}
+	}
+	return nil
+}
+
+// protectedRegions loads the protected regions of a workspace file. Nothing
+// is protected when --force is given.
+func protectedRegions(ws *workspace.Workspace, file string) ([]protect.Region, string, error) {
+	if forceProtected || ws == nil {
+		return nil, file, nil
+	}
+
+	filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+	relFile := file
+	if rel, err := filepath.Rel(ws.Root, filePath); err == nil {
+		relFile = filepath.ToSlash(rel)
+	}
+
+	content, err := storage.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, relFile, nil
+	}
+	if err != nil {
+		return nil, relFile, fmt.Errorf("failed to read %s: %w", relFile, err)
+	}
+
+	var configured []string
+	if ws.Config != nil {
+		configured = ws.Config.Protected
+	}
+	return protect.Regions(relFile, content, configured), relFile, nil
+}
+
+func protectedError(file string, region *protect.Region) error {
+	target := file
+	if !region.WholeFile() {
+		target = fmt.Sprintf("%s#%s", file, region.Heading)
+	}
+	return fmt.Errorf("'%s' is protected (%s); use --force to modify it", target, region.Reason)
+}
+
+// checkContent refuses captured content that looks binary or is very large
+// unless --force is given
+func checkContent(data []byte) error {
+	return allowContent(cmdutil.CheckContent("", data))
+}
+
+// checkDestinationContent refuses to write into a destination file that
+// looks binary or is very large, such as a generated file selected by
+// mistake, unless --force is given
+func checkDestinationContent(ws *workspace.Workspace, file string) error {
+	filePath, relFile := file, file
+	if ws != nil {
+		filePath = cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		if rel, err := filepath.Rel(ws.Root, filePath); err == nil {
+			relFile = filepath.ToSlash(rel)
+		}
+	}
+
+	info, err := storage.Stat(filePath)
+	if err != nil {
+		return nil // A missing destination is created
+	}
+	if info.Size() > cmdutil.LargeContentSize {
+		return allowContent(&cmdutil.ContentError{Path: relFile, Kind: cmdutil.ContentTooLarge, Size: info.Size()})
+	}
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	return allowContent(cmdutil.CheckContent(relFile, content))
+}
+
+// allowContent lets content through despite a content error when --force is
+// given, warning on stderr and recording it for JSON metadata
+func allowContent(err error) error {
+	var contentErr *cmdutil.ContentError
+	if !errors.As(err, &contentErr) {
+		return err
+	}
+	if !forceProtected {
+		return fmt.Errorf("%w; use --force to write it anyway", err)
+	}
+	cmdutil.RecordForcedContent(contentErr)
+	fmt.Fprintf(os.Stderr, "Warning: %s; writing it because of --force\n", err)
+	return nil
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queueLIFO bool
+	queueKeep bool
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Defer subtrees to a reading queue",
+	Long: `Keep a queue of files and subtrees to read later, without refiling them.
+
+During triage, push what you want to come back to, then work through it with
+'jot queue next', which prints the oldest item and removes it from the
+queue. Use --lifo to take the newest item instead. The queue is kept in
+.jot/queue.json.
+
+Examples:
+  jot queue push "inbox.md#article"      # Defer a subtree
+  jot queue                              # List the queue
+  jot queue next                         # Read the oldest item
+  jot queue next --lifo                  # Read the newest item
+  jot queue next --keep                  # Read it, but leave it queued
+  jot queue clear                        # Empty the queue`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return queueList(cmd)
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the reading queue",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return queueList(cmd)
+	},
+}
+
+var queuePushCmd = &cobra.Command{
+	Use:   "push SELECTOR...",
+	Short: "Add files or subtrees to the reading queue",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		for _, selector := range args {
+			if _, _, err := readSelectorTarget(ws, selector); err != nil {
+				return ctx.HandleError(err)
+			}
+		}
+
+		added, err := ws.PushQueue(args...)
+		if err != nil {
+			return ctx.HandleOperationError("save queue", err)
+		}
+		items, err := ws.LoadQueue()
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(QueueResponse{
+				Operation: "push",
+				Items:     added,
+				Length:    len(items),
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		for _, item := range added {
+			cmdutil.ShowSuccess("✓ Queued %s", item.Selector)
+		}
+		if skipped := len(args) - len(added); skipped > 0 {
+			fmt.Printf("%d already queued\n", skipped)
+		}
+		fmt.Printf("%d in queue\n", len(items))
+		return nil
+	},
+}
+
+var queueNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Print the next queued item and remove it",
+	Long: `Print the next queued file or subtree and remove it from the queue. Items
+whose selector no longer matches, because they were refiled or deleted, are
+dropped with a warning and the next one is shown.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		items, err := ws.LoadQueue()
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		response := QueueNextResponse{Dropped: []workspace.QueueItem{}}
+		for len(items) > 0 {
+			i := 0
+			if queueLIFO {
+				i = len(items) - 1
+			}
+			item := items[i]
+			target, _, err := readSelectorTarget(ws, item.Selector)
+			if err != nil && !isMissingTarget(err) {
+				return ctx.HandleError(err)
+			}
+			if err == nil && queueKeep {
+				response.Item, response.Content = &item, target.Content
+				break
+			}
+			items = append(items[:i], items[i+1:]...)
+			if err != nil {
+				response.Dropped = append(response.Dropped, item)
+				if !ctx.IsJSONOutput() {
+					fmt.Fprintf(os.Stderr, "Warning: dropped %s from the queue: %v\n", item.Selector, err)
+				}
+				continue
+			}
+			response.Item, response.Content = &item, target.Content
+			break
+		}
+
+		if !queueKeep || len(response.Dropped) > 0 {
+			if err := ws.SaveQueue(items); err != nil {
+				return ctx.HandleOperationError("save queue", err)
+			}
+		}
+		response.Remaining = len(items)
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		if response.Item == nil {
+			fmt.Println("The reading queue is empty.")
+			return nil
+		}
+		fmt.Print(response.Content)
+		if !strings.HasSuffix(response.Content, "\n") {
+			fmt.Println()
+		}
+		fmt.Printf("\n(%s, %d left in queue)\n", response.Item.Selector, response.Remaining)
+		return nil
+	},
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Empty the reading queue",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		items, err := ws.LoadQueue()
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if err := ws.SaveQueue([]workspace.QueueItem{}); err != nil {
+			return ctx.HandleOperationError("save queue", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(QueueResponse{
+				Operation: "clear",
+				Items:     items,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Cleared %d queued items", len(items))
+		return nil
+	},
+}
+
+// isMissingTarget reports whether a selector failed to resolve because its
+// file or heading no longer exists
+func isMissingTarget(err error) bool {
+	return os.IsNotExist(err) || cmdutil.DescribeError(err).Category == cmdutil.CategoryNotFound
+}
+
+// queueList shows the reading queue in the order 'queue next' takes it
+func queueList(cmd *cobra.Command) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	items, err := ws.LoadQueue()
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(QueueResponse{
+			Operation: "list",
+			Items:     items,
+			Length:    len(items),
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	if len(items) == 0 {
+		fmt.Println("The reading queue is empty.")
+		fmt.Println("\nUse 'jot queue push <selector>' to defer something to read later")
+		return nil
+	}
+	for i, item := range items {
+		fmt.Printf("%3d. %s  (%s)\n", i+1, item.Selector, formatRelativeTime(item.Added))
+	}
+	return nil
+}
+
+// QueueResponse represents the JSON response for queue list, push and clear
+type QueueResponse struct {
+	Operation string                `json:"operation"`
+	Items     []workspace.QueueItem `json:"items"`
+	Length    int                   `json:"length"`
+	Metadata  cmdutil.JSONMetadata  `json:"metadata"`
+}
+
+// QueueNextResponse represents the JSON response for queue next
+type QueueNextResponse struct {
+	Item      *workspace.QueueItem  `json:"item"` // null when the queue is empty
+	Content   string                `json:"content,omitempty"`
+	Dropped   []workspace.QueueItem `json:"dropped"` // Items that no longer resolve
+	Remaining int                   `json:"remaining"`
+	Metadata  cmdutil.JSONMetadata  `json:"metadata"`
+}
+
+func init() {
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queuePushCmd)
+	queueCmd.AddCommand(queueNextCmd)
+	queueCmd.AddCommand(queueClearCmd)
+	queuePushCmd.ValidArgsFunction = completeSelectorArgs
+	queueNextCmd.Flags().BoolVar(&queueLIFO, "lifo", false, "Take the most recently queued item instead of the oldest")
+	queueNextCmd.Flags().BoolVar(&queueKeep, "keep", false, "Leave the item in the queue")
+}
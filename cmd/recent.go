@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "Show recent activity in the workspace",
+	Long: `Show what changed in the workspace lately, newest first, so you can pick
+up where you left off: files modified, subtrees captured, and subtrees
+refiled or archived, each with a selector to open or peek at.
+
+Captures come from the capture log and refiles from the refile log, both
+kept in .jot/. Refiled subtrees are listed at their destination, with the
+selector they were refiled from; selectors are as they were at the time.
+
+Examples:
+  jot recent                  # The last 7 days
+  jot recent --days 1         # Since yesterday
+  jot recent --limit 5
+  jot recent --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		days, _ := cmd.Flags().GetInt("days")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if days < 1 {
+			return ctx.HandleValidation("days", fmt.Sprint(days), fmt.Errorf("must be at least 1"))
+		}
+		if limit < 0 {
+			return ctx.HandleValidation("limit", fmt.Sprint(limit), fmt.Errorf("cannot be negative"))
+		}
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+		activity, err := recentActivity(ws, since)
+		if err != nil {
+			return ctx.HandleOperationError("recent", err)
+		}
+		total := len(activity)
+		if limit > 0 && len(activity) > limit {
+			activity = activity[:limit]
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(RecentResponse{
+				Operation: "recent",
+				Days:      days,
+				Activity:  activity,
+				Total:     total,
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(activity) == 0 {
+			fmt.Printf("No activity in the last %d day%s.\n", days, pluralize(days))
+			return nil
+		}
+		printRecentActivity(activity)
+		if total > len(activity) {
+			fmt.Printf("\n... and %d more; use --limit 0 to see all\n", total-len(activity))
+		}
+		return nil
+	},
+}
+
+// RecentResponse is the JSON response for recent
+type RecentResponse struct {
+	Operation string               `json:"operation"`
+	Days      int                  `json:"days"`
+	Activity  []RecentItem         `json:"activity"`
+	Total     int                  `json:"total"` // Items in the period, before --limit
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// RecentItem is one piece of recent activity
+type RecentItem struct {
+	Kind     string    `json:"kind"` // modified, captured or refiled
+	Time     time.Time `json:"time"`
+	Ago      string    `json:"ago"`
+	Selector string    `json:"selector"`
+	File     string    `json:"file"`
+	From     string    `json:"from,omitempty"` // Where a refiled subtree came from
+}
+
+// recentActivity returns the subtrees captured and refiled, and the files
+// modified otherwise, since the given time, newest first
+func recentActivity(ws *workspace.Workspace, since time.Time) ([]RecentItem, error) {
+	activity := []RecentItem{}
+
+	captures, err := ws.LoadCaptures()
+	if err != nil {
+		return nil, err
+	}
+	var captured []RecentItem
+	for _, record := range captures {
+		// A capture to a heading records its selector as the file
+		file, _, underHeading := strings.Cut(record.File, "#")
+		item := RecentItem{Kind: "captured", Time: record.Time, Selector: record.File, File: file}
+		switch {
+		case record.Heading != "" && underHeading:
+			item.Selector += "/" + record.Heading
+		case record.Heading != "":
+			item.Selector += "#" + record.Heading
+		}
+		// An amended capture replaces the one before it
+		if record.Amended && len(captured) > 0 {
+			captured = captured[:len(captured)-1]
+		}
+		captured = append(captured, item)
+	}
+	for _, item := range captured {
+		if !item.Time.Before(since) {
+			activity = append(activity, item)
+		}
+	}
+
+	refiles, err := ws.LoadRefiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range refiles {
+		if record.Time.Before(since) {
+			continue
+		}
+		file, _, _ := strings.Cut(record.To, "#")
+		activity = append(activity, RecentItem{Kind: "refiled", Time: record.Time, Selector: record.To, File: file, From: record.From})
+	}
+
+	// A file last changed by a capture or refile listed here is left out
+	touched := map[string]time.Time{}
+	for _, item := range activity {
+		touched[item.File] = later(touched[item.File], item.Time)
+		if from, _, _ := strings.Cut(item.From, "#"); from != "" {
+			touched[from] = later(touched[from], item.Time)
+		}
+	}
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		info, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		rel := filepath.ToSlash(file)
+		if info.ModTime().Sub(touched[rel]) < time.Minute {
+			continue
+		}
+		activity = append(activity, RecentItem{Kind: "modified", Time: info.ModTime(), Selector: rel, File: rel})
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].Time.After(activity[j].Time)
+	})
+	for i := range activity {
+		activity[i].Ago = formatRelativeTime(activity[i].Time)
+	}
+	return activity, nil
+}
+
+// later returns the later of two times
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// printRecentActivity lists activity one item per line, aligned
+func printRecentActivity(activity []RecentItem) {
+	width := 0
+	for _, item := range activity {
+		width = max(width, len(item.Ago))
+	}
+	for _, item := range activity {
+		line := fmt.Sprintf("%-*s  %-8s  %s", width, item.Ago, item.Kind, item.Selector)
+		if item.From != "" {
+			line += "  (from " + item.From + ")"
+		}
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	recentCmd.Flags().Int("days", 7, "How many days back to look")
+	recentCmd.Flags().Int("limit", 20, "Most items to show (0 for all)")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestRecentActivity(t *testing.T) {
+	ws := fsckWorkspace(t)
+	root := ws.Root
+	if err := os.WriteFile(ws.InboxPath, []byte("# Inbox\n\n## Kickoff\n\nAgenda\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(root, "old.md")
+	if err := os.WriteFile(old, []byte("# Old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lastMonth := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(old, lastMonth, lastMonth); err != nil {
+		t.Fatal(err)
+	}
+	edited := filepath.Join(root, "edited.md")
+	if err := os.WriteFile(edited, []byte("# Edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hourAgo := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(edited, hourAgo, hourAgo); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refiling records the subtree at its destination
+	sourcePath := &markdown.HeadingPath{File: "inbox.md", Segments: []string{"Kickoff"}}
+	subtree, err := ExtractSubtree(ws, sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest, err := ResolveDestination(ws, &markdown.HeadingPath{File: "work.md", Segments: []string{"Work", "Notes"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := performRefile(ws, sourcePath, subtree, dest, TransformSubtreeLevel(subtree, dest.TargetLevel)); err != nil {
+		t.Fatal(err)
+	}
+
+	records := []workspace.CaptureRecord{
+		{Time: lastMonth, File: "inbox.md", Heading: "Ancient"},
+		{Time: time.Now().Add(-2 * time.Hour), File: "inbox.md", Heading: "Draft"},
+		{Time: time.Now().Add(-2 * time.Hour), File: "inbox.md", Heading: "Draft", Amended: true},
+		{Time: time.Now().Add(-3 * time.Hour), File: "work.md#Work/Notes", Heading: "Idea"},
+	}
+	for _, record := range records {
+		if err := ws.RecordCapture(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	activity, err := recentActivity(ws, time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RecentItem{
+		{Kind: "refiled", Selector: "work.md#Work/Notes/Kickoff", File: "work.md", From: "inbox.md#Kickoff"},
+		{Kind: "modified", Selector: "edited.md", File: "edited.md"},
+		{Kind: "captured", Selector: "inbox.md#Draft", File: "inbox.md"},
+		{Kind: "captured", Selector: "work.md#Work/Notes/Idea", File: "work.md"},
+	}
+	if len(activity) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(activity), len(want), activity)
+	}
+	for i, item := range activity {
+		if item.Kind != want[i].Kind || item.Selector != want[i].Selector || item.File != want[i].File || item.From != want[i].From {
+			t.Errorf("item %d = %+v, want %+v", i, item, want[i])
+		}
+		if item.Ago == "" {
+			t.Errorf("item %d has no relative time", i)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/journal"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover [ID...]",
+	Short: "Roll back or complete interrupted operations",
+	Long: `Roll back or complete operations that were interrupted part way.
+
+Refile, archive, promote and demote record the files they are about to
+write in .jot/journal/ before writing them, and remove the record once
+every file is written. If jot is killed in between, the record stays
+behind, and jot warns about it on the next run.
+
+Without flags, list the interrupted operations and how far each got.
+With --rollback, restore every file to its content before the operation.
+With --complete, write the rest of the operation's changes. Give IDs to
+act on some operations only.
+
+Run recover when no other jot command is writing to the workspace.
+
+Examples:
+  jot recover                 # List interrupted operations
+  jot recover --rollback      # Undo them, restoring the original files
+  jot recover --complete      # Finish them
+  jot recover --complete 20261016T101500.000000000-4242`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		rollback, _ := cmd.Flags().GetBool("rollback")
+		complete, _ := cmd.Flags().GetBool("complete")
+		if rollback && complete {
+			return ctx.HandleValidation("flags", "--rollback --complete", fmt.Errorf("choose one of --rollback or --complete"))
+		}
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		entries, err := journal.Pending(journalDir(ws))
+		if err != nil {
+			return ctx.HandleOperationError("read journal", err)
+		}
+		entries, err = selectJournalEntries(entries, args)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		action := ""
+		switch {
+		case rollback:
+			action = "rolled_back"
+		case complete:
+			action = "completed"
+		}
+
+		response := RecoverResponse{Operation: "recover", Entries: make([]RecoverEntry, 0, len(entries))}
+		for _, entry := range entries {
+			item := RecoverEntry{
+				ID:      entry.ID,
+				Command: entry.Command,
+				Started: entry.Started,
+				Files:   relativePaths(ws, entry.Paths()),
+				State:   entry.State(),
+			}
+			switch {
+			case rollback:
+				err = entry.Rollback()
+			case complete:
+				err = entry.Complete()
+			}
+			if err != nil {
+				return ctx.HandleOperationError("recover "+entry.ID, err)
+			}
+			item.Action = action
+			response.Entries = append(response.Entries, item)
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		if len(response.Entries) == 0 {
+			fmt.Println("No interrupted operations.")
+			return nil
+		}
+		for _, entry := range response.Entries {
+			switch action {
+			case "rolled_back":
+				cmdutil.ShowSuccess("✓ Rolled back %s: %s", entry.ID, entry.Command)
+			case "completed":
+				cmdutil.ShowSuccess("✓ Completed %s: %s", entry.ID, entry.Command)
+			default:
+				fmt.Printf("%s  %s\n", entry.ID, entry.Command)
+				fmt.Printf("  Started: %s\n", entry.Started.Local().Format("2006-01-02 15:04:05"))
+				fmt.Printf("  State:   %s\n", entry.State)
+				for _, file := range entry.Files {
+					fmt.Printf("  File:    %s\n", file)
+				}
+			}
+		}
+		if action == "" {
+			fmt.Println()
+			fmt.Println("Use 'jot recover --rollback' to restore the original files, or 'jot recover --complete' to finish.")
+		}
+		return nil
+	},
+}
+
+// journalDir is where a workspace's operation journal is kept
+func journalDir(ws *workspace.Workspace) string {
+	return filepath.Join(ws.JotDir, "journal")
+}
+
+// configureJournal journals the command's multi-file writes in its
+// workspace, and warns about operations a previous run left unfinished
+func configureJournal(cmd *cobra.Command) {
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		journal.Configure("", "")
+		journal.SetVerify(verifyWrites)
+		return
+	}
+	journal.Configure(journalDir(ws), planCommandLine(os.Args))
+	journal.SetVerify(verifyWrites || (ws.Config != nil && ws.Config.VerifyWrites) || os.Getenv("JOT_VERIFY_WRITES") != "")
+
+	if cmd == recoverCmd || cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return
+	}
+	if entries, err := journal.Pending(journalDir(ws)); err == nil && len(entries) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d interrupted operation(s) found, the first from %q. Run 'jot recover' to roll back or complete them.\n", len(entries), entries[0].Command)
+	}
+}
+
+// selectJournalEntries keeps the entries with the given IDs, or all of them
+func selectJournalEntries(entries []*journal.Entry, ids []string) ([]*journal.Entry, error) {
+	if len(ids) == 0 {
+		return entries, nil
+	}
+	var selected []*journal.Entry
+	for _, id := range ids {
+		found := false
+		for _, entry := range entries {
+			if entry.ID == id {
+				selected = append(selected, entry)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no interrupted operation with ID %s", id)
+		}
+	}
+	return selected, nil
+}
+
+// relativePaths shows paths relative to the workspace root where possible
+func relativePaths(ws *workspace.Workspace, paths []string) []string {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = path
+		if rel, err := filepath.Rel(ws.Root, path); err == nil {
+			result[i] = filepath.ToSlash(rel)
+		}
+	}
+	return result
+}
+
+// RecoverResponse represents the JSON response for recover
+type RecoverResponse struct {
+	Operation string               `json:"operation"`
+	Entries   []RecoverEntry       `json:"entries"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// RecoverEntry describes one interrupted operation
+type RecoverEntry struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+	Files   []string  `json:"files"`
+	State   string    `json:"state"`            // not-started, partial or written
+	Action  string    `json:"action,omitempty"` // rolled_back or completed
+}
+
+func init() {
+	recoverCmd.Flags().Bool("rollback", false, "Restore the files to their content before the operation")
+	recoverCmd.Flags().Bool("complete", false, "Write the rest of the operation's changes")
+}
@@ -1,22 +1,27 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/editor"
 	"github.com/johncoder/jot/internal/fzf"
-	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/journal"
 	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/text"
 )
 
 // DestinationTarget represents a resolved destination
@@ -26,6 +31,10 @@ type DestinationTarget struct {
 	InsertOffset int      // Byte position for insertion
 	CreatePath   []string // Missing headings to create
 	Exists       bool     // Whether the target path exists
+
+	// HeadingOffset is the offset of the deepest existing heading on the path,
+	// or -1 when content is appended at the top level of the file
+	HeadingOffset int
 }
 
 // RefileOperation encapsulates a refile operation with atomic execution for same-file operations
@@ -37,6 +46,11 @@ type RefileOperation struct {
 	InsertOffset       int
 	CreatePath         []string
 	TargetLevel        int
+	Stub               []byte // Left in place of the subtree in the source, if any
+
+	// Subtrees, when set, are moved together instead of Subtree, in
+	// document order, with TransformedContent holding all of them
+	Subtrees []*markdown.Subtree
 }
 
 // IsSameFile returns true if source and destination are the same file
@@ -63,27 +77,25 @@ func (op *RefileOperation) executeSameFile() error {
 	// Perform simple same-file refile
 	newContent := op.performSimpleSameFileRefile(content)
 
-	// Write the modified content back to file using unified content utilities
-	return cmdutil.WriteFileContent(op.SourcePath, newContent)
+	// Write the modified content back through the journal, so an interrupted
+	// write can be recovered
+	return journal.Apply([]journal.Write{{Path: op.SourcePath, Content: newContent}}, op.verification())
 }
 
 // performSimpleSameFileRefile performs safe same-file refile with consistent formatting
 func (op *RefileOperation) performSimpleSameFileRefile(content []byte) []byte {
-	// Step 1: Prepare content to move with consistent formatting
+	// Step 1: Prepare content to move with consistent formatting, under any
+	// headings that have to be created for it
 	contentToMove := op.ensureConsistentFormatting(op.TransformedContent)
-
-	// Step 2: Remove the original subtree cleanly
-	beforeSubtree := content[:op.Subtree.StartOffset]
-	afterSubtree := content[op.Subtree.EndOffset:]
-	contentWithoutSubtree := append(beforeSubtree, afterSubtree...)
-
-	// Step 3: Adjust insertion offset for removed content
-	adjustedOffset := op.InsertOffset
-	if op.InsertOffset > op.Subtree.StartOffset {
-		removedLength := op.Subtree.EndOffset - op.Subtree.StartOffset
-		adjustedOffset = op.InsertOffset - removedLength
+	if len(op.CreatePath) > 0 {
+		pathContent := markdown.CreateHeadingStructure(op.CreatePath, op.TargetLevel-len(op.CreatePath))
+		contentToMove = append(pathContent, contentToMove...)
 	}
 
+	// Step 2 & 3: Remove the original subtree cleanly, leaving the stub if
+	// any, and adjust the insertion offset for removed content
+	contentWithoutSubtree, adjustedOffset := op.removeSubtrees(content, op.InsertOffset)
+
 	// Step 4: Ensure we don't go past the content boundary
 	if adjustedOffset > len(contentWithoutSubtree) {
 		adjustedOffset = len(contentWithoutSubtree)
@@ -103,37 +115,82 @@ func (op *RefileOperation) performSimpleSameFileRefile(content []byte) []byte {
 	// Add content
 	result = append(result, contentToMove...)
 
-	// Add remaining content
-	result = append(result, contentWithoutSubtree[adjustedOffset:]...)
+	// Add remaining content; blank lines that ended the file stay before the
+	// moved content rather than trailing it
+	if remaining := contentWithoutSubtree[adjustedOffset:]; len(bytes.TrimSpace(remaining)) > 0 {
+		result = append(result, remaining...)
+	}
 
 	// Post-process to normalize spacing: ensure exactly one blank line between sections
 	return op.normalizeMarkdownSpacing(result)
 }
 
-// executeCrossFile handles cross-file refile operations
+// subtrees returns the subtrees being moved, in document order
+func (op *RefileOperation) subtrees() []*markdown.Subtree {
+	if len(op.Subtrees) > 0 {
+		return op.Subtrees
+	}
+	return []*markdown.Subtree{op.Subtree}
+}
+
+// removeSubtrees cuts the moved subtrees out of content, leaving the stub in
+// place of each, and maps offset in content to the same place in the result
+func (op *RefileOperation) removeSubtrees(content []byte, offset int) ([]byte, int) {
+	result := make([]byte, 0, len(content)+len(op.Stub))
+	adjusted := offset
+	last := 0
+	for _, subtree := range op.subtrees() {
+		stub := op.stubBefore(content[subtree.EndOffset:])
+		result = append(result, content[last:subtree.MarkerStart()]...)
+		result = append(result, stub...)
+		if offset > subtree.MarkerStart() {
+			adjusted -= subtree.EndOffset - subtree.MarkerStart() - len(stub)
+		}
+		last = subtree.EndOffset
+	}
+	result = append(result, content[last:]...)
+	return result, adjusted
+}
+
+// stubBefore returns the stub to leave ahead of the content that followed
+// the subtree, without a trailing blank line at the end of the file
+func (op *RefileOperation) stubBefore(after []byte) []byte {
+	if len(op.Stub) > 0 && len(bytes.TrimSpace(after)) == 0 {
+		line := bytes.TrimRight(op.Stub, "\n")
+		return append(append([]byte{}, line...), '\n')
+	}
+	return op.Stub
+}
+
+// executeCrossFile handles cross-file refile operations. Both files are
+// journaled before either is written, so the subtree is never left removed
+// from the source without being inserted at the destination.
 func (op *RefileOperation) executeCrossFile() error {
-	// Step 1: Read and update source file using unified content utilities
+	// Step 1: Read the source file and remove the subtrees
 	sourceContent, err := cmdutil.ReadFileContent(op.SourcePath)
 	if err != nil {
 		return err
 	}
+	newSourceContent, _ := op.removeSubtrees(sourceContent, 0)
 
-	newSourceContent := append(sourceContent[:op.Subtree.StartOffset], sourceContent[op.Subtree.EndOffset:]...)
-	if err := cmdutil.WriteFileContent(op.SourcePath, newSourceContent); err != nil {
-		return err
-	}
-
-	// Step 2: Read and update destination file using unified content utilities
+	// Step 2: Read the destination file and insert the content
 	destContent, err := cmdutil.ReadFileContent(op.DestPath)
 	if err != nil {
 		return err
 	}
 
+	// Build into a fresh slice: appending to destContent[:offset] would
+	// overwrite the tail that still has to be copied
 	insertContent := op.prepareInsertContent(destContent, op.InsertOffset)
-	newDestContent := append(destContent[:op.InsertOffset], insertContent...)
+	newDestContent := make([]byte, 0, len(destContent)+len(insertContent))
+	newDestContent = append(newDestContent, destContent[:op.InsertOffset]...)
+	newDestContent = append(newDestContent, insertContent...)
 	newDestContent = append(newDestContent, destContent[op.InsertOffset:]...)
 
-	return cmdutil.WriteFileContent(op.DestPath, newDestContent)
+	return journal.Apply([]journal.Write{
+		{Path: op.SourcePath, Content: newSourceContent},
+		{Path: op.DestPath, Content: newDestContent},
+	}, op.verification())
 }
 
 // prepareInsertContent prepares the content to be inserted, including missing headings and spacing
@@ -198,7 +255,24 @@ Examples:
   jot refile "inbox.md#meeting" --to "work.md#projects"
   jot refile "notes.md#research/database" --to "archive.md#technical"  
   jot refile "inbox.md#/foo/bar" --to "work.md#tasks"  # Skip level 1
-  jot refile --to "work.md#projects/frontend"          # Inspect destination`,
+  jot refile --to "work.md#projects/frontend"          # Inspect destination
+  jot refile "inbox.md#2024-06-*" --to "archive.md#june"  # Every matching subtree
+  jot refile --children-of "inbox.md#inbox" --to "archive.md#2024"
+  jot expand "inbox.md#*standup" | jot refile --stdin --to "work.md#meetings"
+  jot refile --auto --dry-run                          # Preview rules-based filing
+  jot refile --auto                                    # File inbox using .jot/rules.yaml
+
+Rules (.jot/rules.yaml) map matchers to destinations. Every matcher in a rule
+must match; the first matching rule wins:
+  rules:
+    - name: standups
+      match:
+        heading: "(?i)standup"       # regex on the heading text
+        tags: [meeting]              # any #hashtag in the subtree
+        template: meeting            # template used at capture time
+        keywords: [agenda]           # any case-insensitive phrase
+      to: "work.md#meetings"
+      prepend: false`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmdutil.StartCommand(cmd)
@@ -214,15 +288,61 @@ Examples:
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		interactive, _ := cmd.Flags().GetBool("interactive")
 
+		// --edit works on one subtree, with the terminal free for the editor
+		if edit, _ := cmd.Flags().GetBool("edit"); edit {
+			auto, _ := cmd.Flags().GetBool("auto")
+			fromStdin, _ := cmd.Flags().GetBool("stdin")
+			childrenOf, _ := cmd.Flags().GetString("children-of")
+			if auto || fromStdin || childrenOf != "" || (len(args) > 0 && isMultiRefile(args[0])) {
+				return ctx.HandleUsage(fmt.Errorf("--edit refiles one subtree at a time, so it cannot be combined with --auto, --stdin, --children-of or a glob"))
+			}
+		}
+
+		// Rules-based filing of inbox subtrees
+		if auto, _ := cmd.Flags().GetBool("auto"); auto {
+			if len(args) > 0 || to != "" {
+				return ctx.HandleUsage(fmt.Errorf("--auto cannot be combined with a source or --to"))
+			}
+			return runAutoRefile(ctx, ws, dryrun.Enabled())
+		}
+
+		// Selectors piped in, each refiled to the same destination
+		if fromStdin, _ := cmd.Flags().GetBool("stdin"); fromStdin {
+			if to == "" {
+				return ctx.HandleUsage(fmt.Errorf("--stdin needs a destination: use --to"))
+			}
+			if childrenOf, _ := cmd.Flags().GetString("children-of"); childrenOf != "" || interactive {
+				return ctx.HandleUsage(fmt.Errorf("--stdin cannot be combined with --children-of or --interactive"))
+			}
+			selectors, err := stdinSelectors(ws, args)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			return runBatch(ctx, "refile", selectors, func(item *BatchItem) error {
+				return executeRefile(item.Selector, to, ctx, ws)
+			})
+		}
+
 		// Check for interactive mode
 		if fzf.ShouldUseFZF(interactive) {
 			return runInteractiveRefile(ctx, args, ws)
 		}
 
+		// Several subtrees at once: a glob source or every child of a heading
+		if childrenOf, _ := cmd.Flags().GetString("children-of"); childrenOf != "" {
+			if len(args) > 0 {
+				return ctx.HandleUsage(fmt.Errorf("--children-of replaces the source selector"))
+			}
+			return runMultiRefile(ctx, ws, childrenOf, to, true)
+		}
+		if len(args) > 0 && isMultiRefile(args[0]) {
+			return runMultiRefile(ctx, ws, args[0], to, false)
+		}
+
 		// No source and no destination: show usage help
 		if len(args) == 0 && to == "" {
 			err := fmt.Errorf("provide a source file or --to destination")
-			return ctx.HandleError(err)
+			return ctx.HandleUsage(err)
 		}
 
 		if to == "" {
@@ -230,7 +350,7 @@ Examples:
 			if len(args) == 1 && !strings.Contains(args[0], "#") {
 				return showSelectorsForFile(ws, args[0])
 			}
-			err := fmt.Errorf("destination path required: use --to flag")
+			err := cmdutil.NewUsageError(fmt.Errorf("destination path required: use --to flag"))
 			if ctx.IsJSONOutput() {
 				return ctx.HandleError(err)
 			}
@@ -294,67 +414,44 @@ Examples:
 		}
 
 		// Transform subtree level
-		transformedContent := TransformSubtreeLevel(subtree, dest.TargetLevel)
-
-		// Run pre-refile hook
-		hookManager := hooks.NewManager(ws)
-		if !refileNoVerify {
-			hookCtx := &hooks.HookContext{
-				Type:        hooks.PreRefile,
-				Workspace:   ws,
-				SourceFile:  args[0],
-				DestPath:    to,
-				Timeout:     30 * time.Second,
-				AllowBypass: refileNoVerify,
-			}
-
-			result, err := hookManager.Execute(hookCtx)
+		level, err := refileLevel(cmd, dest.TargetLevel)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		transformedContent := TransformSubtreeLevel(subtree, level)
+		heading := subtree.Heading
+		if edit, _ := cmd.Flags().GetBool("edit"); edit {
+			transformedContent, heading, err = editRefileContent(transformedContent, heading)
 			if err != nil {
-				err := cmdutil.NewExternalError("pre-refile hook", nil, err)
-				if ctx.IsJSONOutput() {
-					return ctx.HandleError(err)
-				}
-				return err
+				return ctx.HandleError(err)
 			}
+		}
 
-			if result.Aborted {
-				err := fmt.Errorf("pre-refile hook aborted operation")
-				if ctx.IsJSONOutput() {
-					return ctx.HandleError(err)
-				}
-				return err
-			}
+		// Leave a link to the new location behind if asked
+		var stub []byte
+		movedTo := ""
+		if leaveLink, _ := cmd.Flags().GetBool("leave-link"); leaveLink {
+			movedTo = movedSelector(ws, destPath.File, dest, heading, level)
+			stub = sourceStub(movedTo)
 		}
 
-		// Perform the refile operation
-		if err := performRefile(ws, sourcePath, subtree, dest, transformedContent); err != nil {
-			err := fmt.Errorf("refile operation failed: %w", err)
+		change := mutation{Hook: "refile", Source: args[0], Dest: to, NoVerify: refileNoVerify}
+		err = change.run(ctx, ws, func() error {
+			if err := performRefileWithStub(ws, sourcePath, subtree, dest, transformedContent, stub); err != nil {
+				return fmt.Errorf("refile operation failed: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
 			if ctx.IsJSONOutput() {
 				return ctx.HandleError(err)
 			}
 			return err
 		}
 
-		// Run post-refile hook (informational only)
-		if !refileNoVerify {
-			hookCtx := &hooks.HookContext{
-				Type:        hooks.PostRefile,
-				Workspace:   ws,
-				SourceFile:  args[0],
-				DestPath:    to,
-				Timeout:     30 * time.Second,
-				AllowBypass: refileNoVerify,
-			}
-
-			_, hookErr := hookManager.Execute(hookCtx)
-			if hookErr != nil && !ctx.IsJSONOutput() {
-				fmt.Printf("Warning: post-refile hook failed: %s\n", hookErr.Error())
-			}
-		}
-
 		// Handle JSON output
 		if ctx.IsJSONOutput() {
-			return outputRefileJSON(ctx, sourcePath, destPath, subtree, dest, transformedContent)
+			return outputRefileJSON(ctx, sourcePath, destPath, subtree, dest, level, transformedContent, movedTo)
 		}
 
 		// Human-readable output
@@ -386,7 +483,7 @@ func inspectDestination(ws *workspace.Workspace, destPath *markdown.HeadingPath)
 		filePath = pathUtil.WorkspaceJoin(destPath.File)
 	}
 
-	if _, err := os.Stat(filePath); err != nil {
+	if _, err := storage.Stat(filePath); err != nil {
 		if cmdutil.IsFileNotFound(err) {
 			cmdutil.ShowError("✗ File not found: %s", destPath.File)
 			return nil
@@ -397,7 +494,7 @@ func inspectDestination(ws *workspace.Workspace, destPath *markdown.HeadingPath)
 	cmdutil.ShowSuccess("✓ File exists: %s", destPath.File)
 
 	// Read and parse the file to analyze the path
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		// Use structured error inspection for better error handling
 		if fileErr, ok := cmdutil.GetFileError(err); ok {
@@ -411,13 +508,13 @@ func inspectDestination(ws *workspace.Workspace, destPath *markdown.HeadingPath)
 	doc := markdown.ParseDocument(content)
 	pathResolution, err := navigateHeadingPath(doc, content, destPath)
 	if err != nil {
-		fmt.Printf("✗ Error analyzing path: %s\n", err.Error())
+		cmdutil.Printf("✗ Error analyzing path: %s\n", err.Error())
 		return nil
 	}
 
 	if pathResolution.TargetHeading != nil {
 		// Complete path exists
-		fmt.Printf("✓ Path exists: %s\n", strings.Join(destPath.Segments, " > "))
+		cmdutil.Printf("✓ Path exists: %s\n", strings.Join(destPath.Segments, " > "))
 		targetLevel := pathResolution.TargetHeading.Level + 1
 		fmt.Printf("Ready to receive content at level %d\n", targetLevel)
 	} else if len(pathResolution.FoundSegments) > 0 {
@@ -436,7 +533,7 @@ func inspectDestination(ws *workspace.Workspace, destPath *markdown.HeadingPath)
 		fmt.Printf("Ready to receive content at level %d\n", finalLevel)
 	} else {
 		// No path exists
-		fmt.Printf("✗ Missing path: %s\n", strings.Join(destPath.Segments, " > "))
+		cmdutil.Printf("✗ Missing path: %s\n", strings.Join(destPath.Segments, " > "))
 
 		// Show what would be created
 		baseLevel := destPath.SkipLevels + 1
@@ -463,7 +560,7 @@ func ExtractSubtreeWithOptions(ws *workspace.Workspace, sourcePath *markdown.Hea
 	filePath := cmdutil.ResolvePath(ws, sourcePath.File, noWorkspace)
 
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		return nil, cmdutil.NewFileError("read", sourcePath.File, err)
 	}
@@ -493,18 +590,18 @@ func ResolveDestination(ws *workspace.Workspace, destPath *markdown.HeadingPath,
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("destination file not found: %s", destPath.File)
+	if _, err := storage.Stat(filePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("destination %w: %s", cmdutil.ErrFileNotFound, destPath.File)
 	}
 
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read destination file: %w", err)
 	}
 
-	// Parse document
-	doc := markdown.ParseDocument(content)
+	// Parse document, not mistaking frontmatter for a heading
+	doc := markdown.ParseDocument(maskFrontmatter(content))
 
 	// Find or create the destination path
 	return resolveDestinationPath(doc, content, destPath, prepend)
@@ -520,17 +617,20 @@ func resolveDestinationPath(doc ast.Node, content []byte, destPath *markdown.Hea
 
 	var insertOffset int
 	var targetLevel int
+	headingOffset := -1
 
 	if pathResolution.TargetHeading != nil {
 		// Found existing target heading - insert content under it
 		insertOffset = calculateInsertionPoint(pathResolution.TargetHeading, content, prepend)
 		targetLevel = pathResolution.TargetHeading.Level + 1
+		headingOffset = markdown.GetNodeOffset(pathResolution.TargetHeading, content)
 	} else {
 		// Need to create missing path
 		if pathResolution.ParentHeading != nil {
 			// Insert under the deepest found parent
 			insertOffset = calculateInsertionPoint(pathResolution.ParentHeading, content, false)
 			targetLevel = pathResolution.ParentHeading.Level + len(pathResolution.MissingSegments) + 1
+			headingOffset = markdown.GetNodeOffset(pathResolution.ParentHeading, content)
 		} else {
 			// No parent found, append to end of file
 			insertOffset = len(content)
@@ -541,17 +641,20 @@ func resolveDestinationPath(doc ast.Node, content []byte, destPath *markdown.Hea
 			if len(destPath.Segments) == 0 {
 				targetLevel = 2 // Default level for top-level insertion
 			} else {
-				targetLevel = destPath.SkipLevels + len(destPath.Segments)
+				// Created headings start where the selector would match them,
+				// one level below the skipped ones
+				targetLevel = destPath.SkipLevels + 1 + len(destPath.Segments)
 			}
 		}
 	}
 
 	return &DestinationTarget{
-		File:         destPath.File,
-		TargetLevel:  targetLevel,
-		InsertOffset: insertOffset,
-		CreatePath:   pathResolution.MissingSegments,
-		Exists:       pathResolution.TargetHeading != nil,
+		File:          destPath.File,
+		TargetLevel:   targetLevel,
+		InsertOffset:  insertOffset,
+		CreatePath:    pathResolution.MissingSegments,
+		Exists:        pathResolution.TargetHeading != nil,
+		HeadingOffset: headingOffset,
 	}, nil
 }
 
@@ -561,9 +664,124 @@ func TransformSubtreeLevel(subtree *markdown.Subtree, newBaseLevel int) []byte {
 	return markdown.TransformHeadingLevels(subtree.Content, levelDiff)
 }
 
-// performRefile executes the actual refile operation
+// refileLevel returns the heading level a refiled subtree takes: the
+// destination's automatic level, unless --level sets it outright or
+// --promote/--demote shift it
+func refileLevel(cmd *cobra.Command, automatic int) (int, error) {
+	level, _ := cmd.Flags().GetInt("level")
+	promote, _ := cmd.Flags().GetInt("promote")
+	demote, _ := cmd.Flags().GetInt("demote")
+
+	set := 0
+	for _, n := range []int{level, promote, demote} {
+		if n != 0 {
+			set++
+		}
+	}
+	if set > 1 {
+		return 0, fmt.Errorf("use only one of --level, --promote and --demote")
+	}
+
+	switch {
+	case level != 0:
+		if level < 1 || level > 6 {
+			return 0, cmdutil.NewValidationError("level", fmt.Sprint(level), fmt.Errorf("must be between 1 and 6"))
+		}
+		return level, nil
+	case promote < 0 || demote < 0:
+		return 0, cmdutil.NewUsageError(fmt.Errorf("--promote and --demote take a positive number of levels"))
+	}
+
+	shifted := automatic - promote + demote
+	if shifted < 1 || shifted > 6 {
+		return 0, fmt.Errorf("subtree would move to level %d (destination level %d); headings must be between levels 1 and 6", shifted, automatic)
+	}
+	return shifted, nil
+}
+
+// movedSelector returns a full-path selector for where a subtree lands:
+// the destination heading's ancestors and any headings created for it that
+// sit above the subtree's new level, then the subtree heading itself
+func movedSelector(ws *workspace.Workspace, destFile string, dest *DestinationTarget, heading string, level int) string {
+	type pathHeading struct {
+		text  string
+		level int
+	}
+	var path []pathHeading
+
+	if dest.HeadingOffset >= 0 {
+		if content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, destFile)); err == nil {
+			for _, h := range markdown.FindAllHeadings(markdown.ParseDocument(content), content) {
+				for len(path) > 0 && path[len(path)-1].level >= h.Level {
+					path = path[:len(path)-1]
+				}
+				path = append(path, pathHeading{h.Text, h.Level})
+				if h.Offset == dest.HeadingOffset {
+					break
+				}
+			}
+		}
+	}
+	baseLevel := dest.TargetLevel - len(dest.CreatePath)
+	for i, text := range dest.CreatePath {
+		path = append(path, pathHeading{text, baseLevel + i})
+	}
+
+	var segments []string
+	for _, h := range path {
+		if h.level >= level {
+			break
+		}
+		segments = append(segments, h.text)
+	}
+	return destFile + "#" + strings.Join(append(segments, heading), "/")
+}
+
+// errRefileEditEmpty aborts a refile whose subtree was emptied in the editor
+var errRefileEditEmpty = errors.New("the edited subtree is empty, so the refile was aborted and nothing was changed")
+
+// editRefileContent opens a subtree on its way to the destination in the
+// editor, for --edit, returning the edited content and its heading. Emptying
+// it aborts the refile; the content must still open with a heading.
+func editRefileContent(content []byte, heading string) ([]byte, string, error) {
+	edited, err := editor.OpenEditor(string(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open editor: %w", err)
+	}
+	trimmed := strings.TrimSpace(edited)
+	if trimmed == "" {
+		return nil, "", errRefileEditEmpty
+	}
+	scanner := markdown.NewHeadingScanner([]byte(trimmed))
+	if !scanner.Next() || scanner.Heading().Offset != 0 {
+		return nil, "", fmt.Errorf("the edited subtree must start with its heading, so the refile was aborted and nothing was changed")
+	}
+	return []byte(trimmed + "\n"), scanner.Heading().Text(), nil
+}
+
+// sourceStub returns the line --leave-link leaves where a subtree was
+func sourceStub(selector string) []byte {
+	return []byte("> moved to " + selector + "\n\n")
+}
+
 // performRefile executes the actual refile operation using RefileOperation for atomic same-file handling
 func performRefile(ws *workspace.Workspace, sourcePath *markdown.HeadingPath, subtree *markdown.Subtree, dest *DestinationTarget, transformedContent []byte) error {
+	return performRefileWithStub(ws, sourcePath, subtree, dest, transformedContent, nil)
+}
+
+// performRefileWithStub is performRefile, leaving stub in the source in
+// place of the subtree
+func performRefileWithStub(ws *workspace.Workspace, sourcePath *markdown.HeadingPath, subtree *markdown.Subtree, dest *DestinationTarget, transformedContent, stub []byte) error {
+	if err := checkProtectedRange(ws, sourcePath.File, subtree.StartOffset, subtree.EndOffset); err != nil {
+		return err
+	}
+	if err := checkProtectedDestination(ws, dest); err != nil {
+		return err
+	}
+	if err := checkDestinationContent(ws, dest.File); err != nil {
+		return err
+	}
+
 	// Create a RefileOperation with all necessary data
 	operation := &RefileOperation{
 		SourcePath:         cmdutil.ResolveWorkspaceRelativePath(ws, sourcePath.File),
@@ -573,95 +791,168 @@ func performRefile(ws *workspace.Workspace, sourcePath *markdown.HeadingPath, su
 		InsertOffset:       dest.InsertOffset,
 		CreatePath:         dest.CreatePath,
 		TargetLevel:        dest.TargetLevel,
+		Stub:               stub,
 	}
 
+	// The moved content opens with the subtree's heading at its new level,
+	// renamed if it was edited
+	heading, level := subtree.Heading, dest.TargetLevel
+	if scanner := markdown.NewHeadingScanner(transformedContent); scanner.Next() {
+		heading, level = scanner.Heading().Text(), scanner.Heading().Level
+	}
+	records := refileRecords(ws, sourcePath.File, []*markdown.Subtree{subtree}, []string{heading}, dest, level)
+
 	// Execute the operation with proper same-file handling
-	return operation.Execute()
+	if err := operation.Execute(); err != nil {
+		return err
+	}
+	recordRefiles(ws, records)
+	return nil
 }
 
-// executeRefile executes the refile operation using existing logic
-func executeRefile(sourceSelector, targetSelector string, ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
-	// Initialize hook manager
-	hookManager := hooks.NewManager(ws)
-
-	// Run pre-refile hook
-	if !refileNoVerify {
-		hookCtx := &hooks.HookContext{
-			Type:        hooks.PreRefile,
-			Workspace:   ws,
-			SourceFile:  sourceSelector,
-			DestPath:    targetSelector,
-			Timeout:     30 * time.Second,
-			AllowBypass: refileNoVerify,
-		}
-
-		result, err := hookManager.Execute(hookCtx)
-		if err != nil {
-			return cmdutil.NewExternalError("pre-refile hook", nil, err)
-		}
+// refileRecords returns the refile log records for subtrees about to move
+// from sourceFile to dest at level, where they take the given headings. The
+// destination selectors are worked out before the write, while dest's
+// offsets still hold.
+func refileRecords(ws *workspace.Workspace, sourceFile string, subtrees []*markdown.Subtree, headings []string, dest *DestinationTarget, level int) []workspace.RefileRecord {
+	now := time.Now()
+	records := make([]workspace.RefileRecord, 0, len(subtrees))
+	for i, subtree := range subtrees {
+		records = append(records, workspace.RefileRecord{
+			Time:    now,
+			Heading: headings[i],
+			From:    filepath.ToSlash(sourceFile) + "#" + subtree.Heading,
+			To:      movedSelector(ws, filepath.ToSlash(dest.File), dest, headings[i], level),
+		})
+	}
+	return records
+}
 
-		if result.Aborted {
-			return fmt.Errorf("pre-refile hook aborted operation")
-		}
+// recordRefiles adds refiled subtrees to the refile log. The log only feeds
+// 'jot recent', so failures never fail the refile.
+func recordRefiles(ws *workspace.Workspace, records []workspace.RefileRecord) {
+	for _, record := range records {
+		_ = ws.RecordRefile(record)
 	}
+}
 
-	// Parse paths
-	sourcePath, err := markdown.ParsePath(sourceSelector)
-	if err != nil {
-		return cmdutil.NewValidationError("source selector", sourceSelector, err)
+// insertAtDestination inserts new content under a resolved destination without
+// removing anything from a source file, creating missing headings as needed
+func insertAtDestination(ws *workspace.Workspace, dest *DestinationTarget, content []byte) error {
+	if err := checkProtectedDestination(ws, dest); err != nil {
+		return err
+	}
+	if err := checkDestinationContent(ws, dest.File); err != nil {
+		return err
 	}
 
-	destPath, err := markdown.ParsePath(targetSelector)
+	destFilePath := cmdutil.ResolveWorkspaceRelativePath(ws, dest.File)
+
+	destContent, err := cmdutil.ReadFileContent(destFilePath)
 	if err != nil {
-		return fmt.Errorf("invalid target selector '%s': %w", targetSelector, err)
+		return err
 	}
 
-	// Extract subtree from source
-	subtree, err := ExtractSubtree(ws, sourcePath)
-	if err != nil {
-		return fmt.Errorf("failed to extract subtree: %w", err)
+	operation := &RefileOperation{
+		DestPath:           destFilePath,
+		TransformedContent: content,
+		InsertOffset:       dest.InsertOffset,
+		CreatePath:         dest.CreatePath,
+		TargetLevel:        dest.TargetLevel,
 	}
 
-	// Get flags
-	prepend, _ := ctx.Cmd.Flags().GetBool("prepend")
-	verbose, _ := ctx.Cmd.Flags().GetBool("verbose")
+	insertContent := operation.prepareInsertContent(destContent, dest.InsertOffset)
 
-	// Resolve destination
-	destTarget, err := ResolveDestination(ws, destPath, prepend)
-	if err != nil {
-		return fmt.Errorf("failed to resolve destination: %w", err)
-	}
+	// The inserted block already ends with a blank line, so drop the blank lines
+	// that separated the destination subtree from whatever followed it
+	remaining := bytes.TrimLeft(destContent[dest.InsertOffset:], "\n")
 
-	// Transform subtree level
-	transformedContent := TransformSubtreeLevel(subtree, destTarget.TargetLevel)
+	newDestContent := make([]byte, 0, len(destContent)+len(insertContent))
+	newDestContent = append(newDestContent, destContent[:dest.InsertOffset]...)
+	newDestContent = append(newDestContent, insertContent...)
+	newDestContent = append(newDestContent, remaining...)
 
-	// Perform the refile operation using existing logic
-	err = performRefile(ws, sourcePath, subtree, destTarget, transformedContent)
-	if err != nil {
-		return fmt.Errorf("refile operation failed: %w", err)
-	}
+	return cmdutil.WriteFileContent(destFilePath, newDestContent)
+}
+
+// executeRefile executes the refile operation using existing logic
+func executeRefile(sourceSelector, targetSelector string, ctx *cmdutil.CommandContext, ws *workspace.Workspace) error {
+	return executeRefileWith(sourceSelector, targetSelector, ctx, ws, nil)
+}
+
+// refileStamp rewrites a subtree on its way to the destination, already at
+// its new level, as archive does to note when and where it was archived from
+type refileStamp func(content []byte, sourcePath *markdown.HeadingPath, subtree *markdown.Subtree) []byte
+
+// executeRefileWith is executeRefile, passing the moved content through
+// stamp when it is set
+func executeRefileWith(sourceSelector, targetSelector string, ctx *cmdutil.CommandContext, ws *workspace.Workspace, stamp refileStamp) error {
+	var subtree *markdown.Subtree
+	var destPath *markdown.HeadingPath
+	change := mutation{Hook: "refile", Source: sourceSelector, Dest: targetSelector, NoVerify: refileNoVerify}
+	err := change.run(ctx, ws, func() error {
+		// Parse paths
+		sourcePath, err := markdown.ParsePath(sourceSelector)
+		if err != nil {
+			return cmdutil.NewValidationError("source selector", sourceSelector, err)
+		}
+
+		destPath, err = markdown.ParsePath(targetSelector)
+		if err != nil {
+			return fmt.Errorf("invalid target selector '%s': %w", targetSelector, err)
+		}
+
+		// Extract subtree from source
+		subtree, err = ExtractSubtree(ws, sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to extract subtree: %w", err)
+		}
 
-	// Run post-refile hook (informational only)
-	if !refileNoVerify {
-		hookCtx := &hooks.HookContext{
-			Type:        hooks.PostRefile,
-			Workspace:   ws,
-			SourceFile:  sourceSelector,
-			DestPath:    targetSelector,
-			Timeout:     30 * time.Second,
-			AllowBypass: refileNoVerify,
+		// Resolve destination
+		prepend, _ := ctx.Cmd.Flags().GetBool("prepend")
+		destTarget, err := ResolveDestination(ws, destPath, prepend)
+		if err != nil {
+			return fmt.Errorf("failed to resolve destination: %w", err)
 		}
 
-		_, hookErr := hookManager.Execute(hookCtx)
-		if hookErr != nil {
-			// Check for JSON output to determine if we should show warnings
-			if !ctx.IsJSONOutput() {
-				fmt.Printf("Warning: post-refile hook failed: %s\n", hookErr.Error())
+		// Transform subtree level
+		level, err := refileLevel(ctx.Cmd, destTarget.TargetLevel)
+		if err != nil {
+			return err
+		}
+		transformedContent := TransformSubtreeLevel(subtree, level)
+		if stamp != nil {
+			transformedContent = stamp(transformedContent, sourcePath, subtree)
+		}
+		heading := subtree.Heading
+		if edit, _ := ctx.Cmd.Flags().GetBool("edit"); edit {
+			transformedContent, heading, err = editRefileContent(transformedContent, heading)
+			if err != nil {
+				return err
 			}
 		}
+
+		var stub []byte
+		if leaveLink, _ := ctx.Cmd.Flags().GetBool("leave-link"); leaveLink {
+			stub = sourceStub(movedSelector(ws, destPath.File, destTarget, heading, level))
+		}
+
+		// Perform the refile operation using existing logic
+		if err := performRefileWithStub(ws, sourcePath, subtree, destTarget, transformedContent, stub); err != nil {
+			return fmt.Errorf("refile operation failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if verbose {
+	recordSelectors(ws, sourceSelector, targetSelector)
+	if ctx.IsJSONOutput() {
+		return nil
+	}
+
+	if verbose, _ := ctx.Cmd.Flags().GetBool("verbose"); verbose {
 		cmdutil.ShowSuccess("✓ Refiled subtree from %s to %s", sourceSelector, targetSelector)
 	} else {
 		cmdutil.ShowSuccess("✓ Successfully refiled '%s' to '%s'",
@@ -712,7 +1003,17 @@ func init() {
 	refileCmd.Flags().Bool("prepend", false, "Insert content at the beginning under target heading")
 	refileCmd.Flags().BoolP("verbose", "v", false, "Show detailed information about the refile operation")
 	refileCmd.Flags().BoolP("interactive", "i", false, "Interactive mode using FZF (requires JOT_FZF=1)")
+	refileCmd.Flags().BoolP("multi", "m", false, "In interactive mode, mark several subtrees with TAB and refile them together")
 	refileCmd.Flags().BoolVar(&refileNoVerify, "no-verify", false, "Skip hooks verification")
+	refileCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected files and subtrees, and binary or very large destinations")
+	refileCmd.Flags().Bool("auto", false, "File inbox subtrees using rules in .jot/rules.yaml")
+	refileCmd.Flags().Bool("leave-link", false, "Replace the moved subtree in the source with a link to its new location")
+	refileCmd.Flags().Int("level", 0, "Heading level for the moved subtree, instead of the destination's")
+	refileCmd.Flags().Int("promote", 0, "Move the subtree this many levels shallower than the destination's level")
+	refileCmd.Flags().Int("demote", 0, "Move the subtree this many levels deeper than the destination's level")
+	refileCmd.Flags().String("children-of", "", "Move every child of this heading, leaving the heading in place")
+	refileCmd.Flags().Bool("edit", false, "Edit the subtree in $EDITOR on its way to the destination; saving it empty aborts")
+	refileCmd.Flags().Bool("stdin", false, "Refile each selector read from stdin, one per line, to --to")
 }
 
 // showSelectorsForFile displays available selectors for a specific file
@@ -734,12 +1035,12 @@ func showSelectorsForFile(ws *workspace.Workspace, filename string) error {
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("file not found: %s", filename)
+	if _, err := storage.Stat(filePath); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, filename)
 	}
 
 	// Read and parse the file
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		return cmdutil.NewFileError("read", filename, err)
 	}
@@ -863,7 +1164,7 @@ func calculatePathMatch(headingPath []string, targetSegments []string, skipLevel
 			}
 
 			headingSeg := adjustedPath[pathIndex]
-			if strings.Contains(strings.ToLower(headingSeg), strings.ToLower(targetSeg)) {
+			if markdown.ContainsFold(headingSeg, targetSeg) {
 				matchCount++
 			} else {
 				break // Stop on first non-match for consecutive matching
@@ -954,6 +1255,7 @@ type RefileSource struct {
 	FilePath      string `json:"file_path"`
 	Heading       string `json:"heading"`
 	OriginalLevel int    `json:"original_level"`
+	LeftLink      string `json:"left_link,omitempty"` // Selector in the stub left by --leave-link
 }
 
 type RefileDestination struct {
@@ -1000,7 +1302,7 @@ type InspectHeadingCreation struct {
 
 // outputRefileJSON outputs JSON response for refile operation
 func outputRefileJSON(ctx *cmdutil.CommandContext, sourcePath *markdown.HeadingPath, destPath *markdown.HeadingPath,
-	subtree *markdown.Subtree, dest *DestinationTarget, transformedContent []byte) error {
+	subtree *markdown.Subtree, dest *DestinationTarget, level int, transformedContent []byte, leftLink string) error {
 
 	// Get source file path
 	sourceFilePath := sourcePath.File
@@ -1032,6 +1334,7 @@ func outputRefileJSON(ctx *cmdutil.CommandContext, sourcePath *markdown.HeadingP
 			FilePath:      sourceFilePath,
 			Heading:       subtree.Heading,
 			OriginalLevel: subtree.Level,
+			LeftLink:      leftLink,
 		},
 		Destination: RefileDestination{
 			Selector:        destPath.File + "#" + strings.Join(destPath.Segments, "/"),
@@ -1044,7 +1347,7 @@ func outputRefileJSON(ctx *cmdutil.CommandContext, sourcePath *markdown.HeadingP
 			Content:          string(transformedContent),
 			CharacterCount:   len(transformedContent),
 			LineCount:        lineCount,
-			TransformedLevel: dest.TargetLevel,
+			TransformedLevel: level,
 		},
 		Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
 	}
@@ -1066,7 +1369,7 @@ func inspectDestinationJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspace
 	}
 
 	fileExists := true
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	if _, err := storage.Stat(filePath); os.IsNotExist(err) {
 		fileExists = false
 	}
 
@@ -1101,7 +1404,7 @@ func inspectDestinationJSON(ctx *cmdutil.CommandContext, ws *workspace.Workspace
 	}
 
 	// Read and parse the file to analyze the path
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		// Return error as JSON
 		return ctx.HandleError(fmt.Errorf("error reading file: %w", err))
@@ -1187,6 +1490,7 @@ type SubtreeItem struct {
 	Title    string // Heading title for display
 	Level    int    // Heading level (1-6)
 	Preview  string // First few lines for display
+	Offset   int    // Where the heading's line starts in its file
 }
 
 // runInteractiveRefile handles the interactive refile workflow using FZF
@@ -1198,6 +1502,10 @@ func runInteractiveRefile(ctx *cmdutil.CommandContext, args []string, ws *worksp
 	to, _ := ctx.Cmd.Flags().GetString("to")
 	verbose, _ := ctx.Cmd.Flags().GetBool("verbose")
 
+	if multi, _ := ctx.Cmd.Flags().GetBool("multi"); multi {
+		return runInteractiveMultiRefile(ctx, args, ws)
+	}
+
 	// Stage 1 & 2: Select source (if not provided)
 	if len(args) > 0 {
 		providedArg := args[0]
@@ -1305,7 +1613,8 @@ func selectSourceFile(ws *workspace.Workspace, defaultFile string, verbose bool)
 		return "", fmt.Errorf("no markdown files found in workspace")
 	}
 
-	// Move default file to front if it exists
+	// Recently used files come first, after the default file
+	files = recentFilesFirst(ws, files)
 	if defaultFile != "" {
 		files = moveToFront(files, defaultFile)
 	}
@@ -1331,7 +1640,7 @@ func selectSourceSubtree(ws *workspace.Workspace, sourceFile string, verbose boo
 	// Check for duplicate heading titles
 	duplicates := findDuplicateHeadings(subtrees)
 	if len(duplicates) > 0 && verbose {
-		fmt.Printf("⚠️  Warning: Found duplicate headings in %s: %s\n", sourceFile, strings.Join(duplicates, ", "))
+		cmdutil.Printf("⚠️  Warning: Found duplicate headings in %s: %s\n", sourceFile, strings.Join(duplicates, ", "))
 		fmt.Println("   Use the preview (TAB) to distinguish between them")
 	}
 
@@ -1339,7 +1648,7 @@ func selectSourceSubtree(ws *workspace.Workspace, sourceFile string, verbose boo
 		fmt.Printf("Found %d subtrees in %s\n", len(subtrees), sourceFile)
 	}
 
-	selector, err := runSubtreeSelectionFZF(subtrees, "Select subtree to refile > ")
+	selector, err := runSubtreeSelectionFZF(ws, withRecentSubtrees(ws, sourceFile, subtrees), "Select subtree to refile > ")
 	if err != nil {
 		return "", err
 	}
@@ -1367,7 +1676,7 @@ func selectTargetFile(ws *workspace.Workspace, verbose bool) (string, error) {
 		fmt.Printf("Found %d markdown files for target\n", len(files))
 	}
 
-	return runFileSelectionFZF(ws, files, "Select target file > ")
+	return runFileSelectionFZF(ws, recentFilesFirst(ws, files), "Select target file > ")
 }
 
 // selectTargetLocation shows FZF heading browser for the selected target file
@@ -1388,7 +1697,7 @@ func selectTargetLocation(ws *workspace.Workspace, targetFile string, verbose bo
 	// Check for duplicate heading titles
 	duplicates := findDuplicateHeadings(subtrees)
 	if len(duplicates) > 0 && verbose {
-		fmt.Printf("⚠️  Warning: Found duplicate headings in %s: %s\n", targetFile, strings.Join(duplicates, ", "))
+		cmdutil.Printf("⚠️  Warning: Found duplicate headings in %s: %s\n", targetFile, strings.Join(duplicates, ", "))
 		fmt.Println("   Use the preview (TAB) to distinguish between them")
 	}
 
@@ -1403,13 +1712,24 @@ func selectTargetLocation(ws *workspace.Workspace, targetFile string, verbose bo
 		Level:    0,
 		Preview:  "Insert at the beginning of the file",
 	}
-	allTargets := append([]SubtreeItem{topLevel}, subtrees...)
+	createNew := SubtreeItem{
+		Selector: createHeadingSelector,
+		Title:    "(Create new heading…)",
+		Level:    0,
+		Preview:  "Type a heading path such as Projects/New Project",
+	}
+	allTargets := withRecentSubtrees(ws, targetFile, append([]SubtreeItem{topLevel, createNew}, subtrees...))
 
-	selector, err := runSubtreeSelectionFZF(allTargets, "Select target location > ")
+	selector, query, err := runTargetSelectionFZF(ws, allTargets, "Select target location > ")
 	if err != nil {
 		return "", err
 	}
 
+	// A typed path that matches nothing, or the create entry, mints a heading
+	if selector == createHeadingSelector || (selector == "" && query != "") {
+		return newHeadingSelector(targetFile, query)
+	}
+
 	if selector == "" {
 		return "", nil // User cancelled
 	}
@@ -1429,29 +1749,32 @@ func confirmRefile(sourceSelector, targetSelector string, ws *workspace.Workspac
 	separator := strings.Repeat("-", 60)
 
 	fmt.Printf("\n%s\n", border)
-	fmt.Printf("📋 REFILE OPERATION SUMMARY\n")
+	cmdutil.Printf("📋 REFILE OPERATION SUMMARY\n")
 	fmt.Printf("%s\n", border)
 
 	// Parse selectors to get more detailed info
 	sourcePath, err := markdown.ParsePath(sourceSelector)
 	if err != nil {
-		fmt.Printf("  Source: %s (⚠️  parse error: %v)\n", sourceSelector, err)
+		cmdutil.Printf("  Source: %s (⚠️  parse error: %v)\n", sourceSelector, err)
 	} else {
 		if len(sourcePath.Segments) > 0 {
-			fmt.Printf("  📤 Source: %s → '%s'\n", sourcePath.File, strings.Join(sourcePath.Segments, "/"))
+			cmdutil.Printf("  📤 Source: %s → '%s'\n", sourcePath.File, strings.Join(sourcePath.Segments, "/"))
 		} else {
-			fmt.Printf("  📤 Source: %s (entire file)\n", sourcePath.File)
+			cmdutil.Printf("  📤 Source: %s (entire file)\n", sourcePath.File)
 		}
 	}
 
 	destPath, err := markdown.ParsePath(targetSelector)
 	if err != nil {
-		fmt.Printf("  Target: %s (⚠️  parse error: %v)\n", targetSelector, err)
+		cmdutil.Printf("  Target: %s (⚠️  parse error: %v)\n", targetSelector, err)
 	} else {
 		if len(destPath.Segments) > 0 {
-			fmt.Printf("  📥 Target: %s → '%s'\n", destPath.File, strings.Join(destPath.Segments, "/"))
+			cmdutil.Printf("  📥 Target: %s → '%s'\n", destPath.File, strings.Join(destPath.Segments, "/"))
 		} else {
-			fmt.Printf("  📥 Target: %s (top level)\n", destPath.File)
+			cmdutil.Printf("  📥 Target: %s (top level)\n", destPath.File)
+		}
+		if dest, err := ResolveDestination(ws, destPath, false); err == nil && len(dest.CreatePath) > 0 {
+			cmdutil.Printf("  ✨ Creates: '%s'\n", strings.Join(dest.CreatePath, "/"))
 		}
 	}
 
@@ -1466,47 +1789,41 @@ func extractSubtreesFromFile(ws *workspace.Workspace, filename string) ([]Subtre
 	filePath := cmdutil.ResolveWorkspaceRelativePath(ws, filename)
 
 	// Read file content
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		return nil, cmdutil.NewFileError("read", filePath, err)
 	}
 
-	// Parse markdown document
-	doc := goldmark.New().Parser().Parse(text.NewReader(content))
-
 	var subtrees []SubtreeItem
 
-	// Walk through the document and find headings
-	err = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering {
-			return ast.WalkContinue, nil
+	// Only heading lines are needed, so scan for them instead of parsing
+	headings := markdown.ScanHeadings(content)
+	for i, heading := range headings {
+		headingText := heading.Text()
+		if strings.TrimSpace(headingText) == "" {
+			continue
 		}
 
-		if heading, ok := n.(*ast.Heading); ok {
-			// Extract heading text
-			headingText := markdown.ExtractHeadingText(heading, content)
-			if strings.TrimSpace(headingText) == "" {
-				return ast.WalkContinue, nil
-			}
-
-			// Generate selector using proper jot format
-			selector := fmt.Sprintf("%s#%s", filename, headingText)
+		// Generate selector using proper jot format
+		selector := fmt.Sprintf("%s#%s", filename, headingText)
 
-			// Extract preview content (next few lines after heading)
-			preview := extractPreviewContent(n, content, 100)
-
-			subtrees = append(subtrees, SubtreeItem{
-				Selector: selector,
-				Title:    headingText,
-				Level:    heading.Level,
-				Preview:  preview,
-			})
+		// Extract preview content (next few lines after heading)
+		bodyEnd := len(content)
+		if i+1 < len(headings) {
+			bodyEnd = headings[i+1].Offset
 		}
+		preview := extractPreviewContent(content[heading.End:bodyEnd], 100)
 
-		return ast.WalkContinue, nil
-	})
+		subtrees = append(subtrees, SubtreeItem{
+			Selector: selector,
+			Title:    headingText,
+			Level:    heading.Level,
+			Preview:  preview,
+			Offset:   heading.Offset,
+		})
+	}
 
-	return subtrees, err
+	return subtrees, nil
 }
 
 // scanWorkspaceMarkdownFiles returns all markdown files in the workspace
@@ -1514,12 +1831,12 @@ func scanWorkspaceMarkdownFiles(ws *workspace.Workspace) ([]string, error) {
 	var files []string
 
 	// Add inbox.md if it exists
-	if _, err := os.Stat(ws.InboxPath); err == nil {
+	if _, err := storage.Stat(ws.InboxPath); err == nil {
 		files = append(files, "inbox.md")
 	}
 
 	// Scan for markdown files in workspace root
-	err := filepath.Walk(ws.Root, func(path string, info os.FileInfo, err error) error {
+	err := ws.Walk(ws.Root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -1643,23 +1960,77 @@ func runFileSelectionFZF(ws *workspace.Workspace, files []string, prompt string)
 	return selected, nil
 }
 
+// createHeadingSelector marks the "create new heading" entry in the target list
+const createHeadingSelector = "+new"
+
+// newHeadingSelector builds a selector for a heading path typed during
+// target selection, asking for one when nothing was typed. Missing headings
+// on the path are created when the refile runs.
+func newHeadingSelector(targetFile, headingPath string) (string, error) {
+	headingPath = strings.Trim(strings.TrimSpace(headingPath), "/")
+	if headingPath == "" {
+		fmt.Print("New heading path (e.g. Projects/New Project): ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", nil // User cancelled
+		}
+		headingPath = strings.Trim(strings.TrimSpace(line), "/")
+		if headingPath == "" {
+			return "", nil
+		}
+	}
+	return targetFile + "#" + headingPath, nil
+}
+
+// runTargetSelectionFZF runs FZF for target selection, also returning the
+// typed query so a path that matches no heading can be created
+func runTargetSelectionFZF(ws *workspace.Workspace, subtrees []SubtreeItem, prompt string) (string, string, error) {
+	return runSubtreeFZF(ws, subtrees, prompt, true)
+}
+
 // runSubtreeSelectionFZF runs FZF for subtree selection
-func runSubtreeSelectionFZF(subtrees []SubtreeItem, prompt string) (string, error) {
+func runSubtreeSelectionFZF(ws *workspace.Workspace, subtrees []SubtreeItem, prompt string) (string, error) {
+	selector, _, err := runSubtreeFZF(ws, subtrees, prompt, false)
+	return selector, err
+}
+
+// runSubtreeFZF runs FZF over subtrees and returns the selected selector.
+// With printQuery, the typed query is returned too, including when it
+// matches nothing.
+func runSubtreeFZF(ws *workspace.Workspace, subtrees []SubtreeItem, prompt string, printQuery bool) (string, string, error) {
+	selected, query, err := subtreeFZF(ws, subtrees, prompt, printQuery, false)
+	if err != nil || len(selected) == 0 {
+		return "", query, err
+	}
+	return selected[0].Selector, query, nil
+}
+
+// runSubtreeMultiFZF runs FZF over subtrees, letting several be marked, and
+// returns the marked ones
+func runSubtreeMultiFZF(ws *workspace.Workspace, subtrees []SubtreeItem, prompt string) ([]SubtreeItem, error) {
+	selected, _, err := subtreeFZF(ws, subtrees, prompt, false, true)
+	return selected, err
+}
+
+// subtreeFZF runs FZF over subtrees and returns the selected ones, with the
+// typed query when printQuery is set. With multi, TAB marks subtrees
+// instead of toggling the preview.
+func subtreeFZF(ws *workspace.Workspace, subtrees []SubtreeItem, prompt string, printQuery, multi bool) ([]SubtreeItem, string, error) {
 	// Validate FZF availability
 	if _, err := exec.LookPath("fzf"); err != nil {
-		return "", fmt.Errorf("fzf not found in PATH. Please install fzf or set JOT_FZF=0 to disable")
+		return nil, "", fmt.Errorf("fzf not found in PATH. Please install fzf or set JOT_FZF=0 to disable")
 	}
 
 	// Create temporary file with subtree list
 	tempFile, err := os.CreateTemp("", "jot-subtrees-*.txt")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+		return nil, "", fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer os.Remove(tempFile.Name())
 	defer tempFile.Close()
 
-	// Write subtrees to temp file in format: selector\ttitle\tpreview
-	for _, subtree := range subtrees {
+	// Write subtrees to temp file in format: selector\ttitle\tpreview\tindex
+	for i, subtree := range subtrees {
 		var levelIndent string
 		if subtree.Level == 0 {
 			levelIndent = "" // Top-level insertion option
@@ -1667,28 +2038,40 @@ func runSubtreeSelectionFZF(subtrees []SubtreeItem, prompt string) (string, erro
 			levelIndent = strings.Repeat("  ", subtree.Level-1)
 		}
 		displayTitle := fmt.Sprintf("%s%s", levelIndent, subtree.Title)
-		line := fmt.Sprintf("%s\t%s\t%s\n", subtree.Selector, displayTitle, subtree.Preview)
+		line := fmt.Sprintf("%s\t%s\t%s\t%d\n", subtree.Selector, displayTitle, subtree.Preview, i)
 		tempFile.WriteString(line)
 	}
 	tempFile.Close()
 
 	// Build FZF command
-	cmd := exec.Command("fzf",
+	preview := fzf.PreviewCommand(daemonSocket(ws), "{1}") // Use first field (selector) for preview
+	header := "ENTER:select | TAB:preview | ESC:cancel"
+	args := []string{
 		"--delimiter", "\t",
 		"--with-nth", "2,3", // Show title and preview
 		"--prompt", prompt,
-		"--preview", "jot peek {1}", // Use first field (selector) for preview
+		"--preview", preview,
 		"--preview-window", "right:50%:wrap",
 		"--bind", "tab:toggle-preview",
-		"--header", "ENTER:select | TAB:preview | ESC:cancel",
 		"--height", "60%",
 		"--border",
-	)
+	}
+	if multi {
+		header = "TAB:mark | ENTER:refile marked | ?:preview | ESC:cancel"
+		args = append(args, "--multi", "--bind", "?:toggle-preview", "--bind", "tab:toggle+down")
+	}
+	if printQuery {
+		header = "ENTER:select, or type a new path to create it | TAB:preview | ESC:cancel"
+		// The create entry has no subtree to peek, so show its description
+		args = append(args, "--print-query", "--preview", preview+" 2>/dev/null || echo {3}")
+	}
+	args = append(args, "--header", header)
+	cmd := exec.Command("fzf", args...)
 
 	// Set up input from temp file
 	tempFileRead, err := os.Open(tempFile.Name())
 	if err != nil {
-		return "", fmt.Errorf("failed to open temp file: %w", err)
+		return nil, "", fmt.Errorf("failed to open temp file: %w", err)
 	}
 	defer tempFileRead.Close()
 
@@ -1698,27 +2081,34 @@ func runSubtreeSelectionFZF(subtrees []SubtreeItem, prompt string) (string, erro
 	// Run FZF and capture selection
 	output, err := cmd.Output()
 	if err != nil {
-		// Check if it's a cancellation (exit code 130) vs actual error
-		if exitError, ok := err.(*exec.ExitError); ok {
-			if exitError.ExitCode() == 130 {
-				return "", nil // User cancelled
-			}
+		exitError, ok := err.(*exec.ExitError)
+		switch {
+		case ok && exitError.ExitCode() == 130:
+			return nil, "", nil // User cancelled
+		case ok && exitError.ExitCode() == 1 && printQuery:
+			// Nothing matched the query; it is still printed
+		default:
+			return nil, "", fmt.Errorf("fzf command failed: %w", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	query := ""
+	if printQuery && len(lines) > 0 {
+		query = strings.TrimSpace(lines[0])
+		lines = lines[1:]
+	}
+	// The last field is the subtree's index
+	var selected []SubtreeItem
+	for _, line := range lines {
+		parts := strings.Split(line, "\t")
+		index, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil || index < 0 || index >= len(subtrees) {
+			continue
 		}
-		return "", fmt.Errorf("fzf command failed: %w", err)
-	}
-
-	selectedLine := strings.TrimSpace(string(output))
-	if selectedLine == "" {
-		return "", nil
-	}
-
-	// Extract the selector (first field)
-	parts := strings.Split(selectedLine, "\t")
-	if len(parts) > 0 {
-		return parts[0], nil
+		selected = append(selected, subtrees[index])
 	}
-
-	return "", nil
+	return selected, query, nil
 }
 
 // findDuplicateHeadings checks for duplicate heading titles in subtrees
@@ -1761,7 +2151,7 @@ func validateAndDisambiguateSelector(ws *workspace.Workspace, selector string, s
 	}
 
 	if len(matches) == 0 {
-		return "", fmt.Errorf("heading '%s' not found in the file", headingName)
+		return "", fmt.Errorf("heading '%s' %w in the file", headingName, cmdutil.ErrNotFound)
 	}
 
 	if len(matches) == 1 {
@@ -1770,36 +2160,21 @@ func validateAndDisambiguateSelector(ws *workspace.Workspace, selector string, s
 
 	// Multiple matches - this could be problematic for the actual refile operation
 	// For now, we'll use the first match but warn the user
-	fmt.Printf("⚠️  Warning: Multiple headings named '%s' found. Using the first occurrence.\n", headingName)
+	cmdutil.Printf("⚠️  Warning: Multiple headings named '%s' found. Using the first occurrence.\n", headingName)
 	fmt.Printf("   Preview showed: %s\n", matches[0].Preview)
 
 	return selector, nil
 }
 
-// extractPreviewContent gets some preview text after a heading
-func extractPreviewContent(node ast.Node, content []byte, maxLen int) string {
-	// Get the position of this heading in the content
-	startPos := markdown.GetNodeOffset(node, content)
-
-	// Find the heading line and skip it
-	lines := strings.Split(string(content), "\n")
-	var headingLineIndex int
-	var currentPos int
-
-	// Find which line this heading is on
-	for i, line := range lines {
-		if currentPos >= startPos {
-			headingLineIndex = i
-			break
-		}
-		currentPos += len(line) + 1 // +1 for newline
-	}
+// extractPreviewContent gets some preview text from a heading's body
+func extractPreviewContent(body []byte, maxLen int) string {
+	lines := strings.Split(string(body), "\n")
 
 	// Collect content from the next few lines after the heading
 	var preview strings.Builder
 	lineCount := 0
 
-	for i := headingLineIndex + 1; i < len(lines) && lineCount < 3; i++ {
+	for i := 0; i < len(lines) && lineCount < 3; i++ {
 		line := strings.TrimSpace(lines[i])
 
 		// Skip empty lines at the beginning
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/rules"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/yuin/goldmark/ast"
+)
+
+// AutoRefileResponse represents the JSON response for refile --auto
+type AutoRefileResponse struct {
+	Operation string               `json:"operation"`
+	DryRun    bool                 `json:"dry_run"`
+	RulesFile string               `json:"rules_file"`
+	Moves     []AutoRefileMove     `json:"moves"`
+	Unmatched []string             `json:"unmatched"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// AutoRefileMove describes an inbox subtree matched by a rule
+type AutoRefileMove struct {
+	Heading     string `json:"heading"`
+	Line        int    `json:"line"`
+	Rule        string `json:"rule"`
+	Destination string `json:"destination"`
+	Moved       bool   `json:"moved"`
+	Error       string `json:"error,omitempty"`
+}
+
+// runAutoRefile files inbox subtrees according to .jot/rules.yaml
+func runAutoRefile(ctx *cmdutil.CommandContext, ws *workspace.Workspace, dryRun bool) error {
+	rulesPath := filepath.Join(ws.JotDir, rules.FileName)
+	ruleSet, err := rules.Load(rulesPath)
+	if errors.Is(err, rules.ErrNoRules) {
+		return ctx.HandleError(fmt.Errorf("no rules file found at %s\nCreate it to map inbox subtrees to destinations (see 'jot refile --help')", rulesPath))
+	}
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	inboxPath := cmdutil.ResolveWorkspaceRelativePath(ws, "inbox.md")
+	for _, rule := range ruleSet.Rules {
+		destPath, err := markdown.ParsePath(rule.To)
+		if err != nil {
+			return ctx.HandleValidation("destination", rule.To, fmt.Errorf("%s: %w", rule.Name, err))
+		}
+		if cmdutil.ResolveWorkspaceRelativePath(ws, destPath.File) == inboxPath {
+			return ctx.HandleValidation("destination", rule.To, fmt.Errorf("%s: rules cannot file into the inbox", rule.Name))
+		}
+	}
+
+	content, err := cmdutil.ReadFileContent(inboxPath)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	templates := captureTemplatesByHeading(ws)
+
+	var moves []AutoRefileMove
+	var subtrees []*markdown.Subtree
+	var matched []*rules.Rule
+	unmatched := []string{}
+	for _, subtree := range inboxSubtrees(content) {
+		item := rules.Item{
+			Heading:  subtree.Heading,
+			Content:  string(subtree.Content),
+			Template: templates[subtree.Heading],
+		}
+		rule := ruleSet.Find(item)
+		if rule == nil {
+			unmatched = append(unmatched, subtree.Heading)
+			continue
+		}
+		moves = append(moves, AutoRefileMove{
+			Heading:     subtree.Heading,
+			Line:        markdown.CalculateLineNumber(content, subtree.StartOffset),
+			Rule:        rule.Name,
+			Destination: rule.To,
+		})
+		subtrees = append(subtrees, subtree)
+		matched = append(matched, rule)
+	}
+
+	if !dryRun {
+		// Move from the bottom of the inbox up so earlier offsets stay valid
+		order := make([]int, len(moves))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool {
+			return subtrees[order[a]].StartOffset > subtrees[order[b]].StartOffset
+		})
+
+		for _, i := range order {
+			if err := autoRefileSubtree(ctx, ws, subtrees[i], matched[i]); err != nil {
+				moves[i].Error = err.Error()
+				continue
+			}
+			moves[i].Moved = true
+		}
+	}
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(AutoRefileResponse{
+			Operation: "refile_auto",
+			DryRun:    dryRun,
+			RulesFile: rulesPath,
+			Moves:     moves,
+			Unmatched: unmatched,
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	if len(moves) == 0 {
+		cmdutil.ShowInfo("No inbox subtrees matched the refile rules")
+		return nil
+	}
+
+	verb := "Moved"
+	if dryRun {
+		verb = "Would move"
+	}
+	failed := 0
+	for _, move := range moves {
+		if move.Error != "" {
+			failed++
+			cmdutil.ShowError("✗ %s (line %d) -> %s: %s", move.Heading, move.Line, move.Destination, move.Error)
+			continue
+		}
+		cmdutil.ShowSuccess("%s '%s' -> %s [%s]", verb, move.Heading, move.Destination, move.Rule)
+	}
+	if len(unmatched) > 0 {
+		cmdutil.ShowInfo("%d subtree(s) left in inbox", len(unmatched))
+	}
+	if failed > 0 {
+		return ctx.HandleError(fmt.Errorf("%d of %d automatic refile(s) failed", failed, len(moves)))
+	}
+	return nil
+}
+
+// autoRefileSubtree moves one inbox subtree to a rule's destination, running refile hooks
+func autoRefileSubtree(ctx *cmdutil.CommandContext, ws *workspace.Workspace, subtree *markdown.Subtree, rule *rules.Rule) error {
+	sourceSelector := "inbox.md#" + subtree.Heading
+	change := mutation{Hook: "refile", Source: sourceSelector, Dest: rule.To, NoVerify: refileNoVerify}
+	return change.run(ctx, ws, func() error {
+		destPath, err := markdown.ParsePath(rule.To)
+		if err != nil {
+			return err
+		}
+		dest, err := ResolveDestination(ws, destPath, rule.Prepend)
+		if err != nil {
+			return fmt.Errorf("failed to resolve destination: %w", err)
+		}
+
+		transformed := TransformSubtreeLevel(subtree, dest.TargetLevel)
+		return performRefile(ws, &markdown.HeadingPath{File: "inbox.md"}, subtree, dest, transformed)
+	})
+}
+
+// inboxSubtrees returns the items in an inbox: its top-level subtrees, or the
+// children of a single top-level heading such as "# Inbox"
+func inboxSubtrees(content []byte) []*markdown.Subtree {
+	doc := markdown.ParseDocument(content)
+
+	var headings []*ast.Heading
+	minLevel := 7
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if heading, ok := node.(*ast.Heading); ok {
+			headings = append(headings, heading)
+			minLevel = min(minLevel, heading.Level)
+		}
+	}
+
+	var roots []*ast.Heading
+	for _, heading := range headings {
+		if heading.Level == minLevel {
+			roots = append(roots, heading)
+		}
+	}
+
+	itemLevel := minLevel
+	if len(roots) == 1 && len(headings) > 1 {
+		itemLevel = 7
+		for _, heading := range headings {
+			if heading.Level > minLevel {
+				itemLevel = min(itemLevel, heading.Level)
+			}
+		}
+	}
+
+	var subtrees []*markdown.Subtree
+	for _, heading := range headings {
+		if heading.Level == itemLevel {
+			subtrees = append(subtrees, markdown.SubtreeFromHeading(heading, content))
+		}
+	}
+	return subtrees
+}
+
+// captureTemplatesByHeading maps inbox capture headings to the template used,
+// keeping the most recent capture for each heading
+func captureTemplatesByHeading(ws *workspace.Workspace) map[string]string {
+	templates := make(map[string]string)
+	records, err := ws.LoadCaptures()
+	if err != nil {
+		return templates
+	}
+	for _, record := range records {
+		if record.File == "inbox.md" && record.Heading != "" && record.Template != "" {
+			templates[record.Heading] = record.Template
+		}
+	}
+	return templates
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/yuin/goldmark/ast"
+)
+
+// MultiRefileResponse is the JSON response for refiling several subtrees
+type MultiRefileResponse struct {
+	Operation   string               `json:"operation"`
+	Sources     []RefileSource       `json:"sources"`
+	Destination RefileDestination    `json:"destination"`
+	Count       int                  `json:"count"`
+	Level       int                  `json:"transformed_level"`
+	Results     []RefileItemResult   `json:"results,omitempty"` // Each selected subtree, for interactive batches
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// RefileItemResult is what happened to one subtree selected for a batch
+type RefileItemResult struct {
+	Heading string `json:"heading"`
+	Line    int    `json:"line"`
+	Result  string `json:"result"` // moved, skipped or failed
+	Reason  string `json:"reason,omitempty"`
+}
+
+// isMultiRefile reports whether a refile source selects several subtrees:
+// a glob in its last heading segment
+func isMultiRefile(selector string) bool {
+	sourcePath, err := markdown.ParsePath(selector)
+	if err != nil || len(sourcePath.Segments) == 0 {
+		return false
+	}
+	return markdown.IsGlobSegment(sourcePath.Segments[len(sourcePath.Segments)-1])
+}
+
+// findMultiRefileSubtrees returns the subtrees selected by a glob source
+// selector, or the children of the heading when children is set
+func findMultiRefileSubtrees(ws *workspace.Workspace, sourcePath *markdown.HeadingPath, children bool) ([]*markdown.Subtree, error) {
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, sourcePath.File))
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", sourcePath.File, err)
+	}
+	doc := markdown.ParseDocument(content)
+
+	if !children {
+		return markdown.FindGlobMatches(doc, content, sourcePath)
+	}
+	parent, err := markdown.FindSubtree(doc, content, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	return markdown.ChildSubtrees(doc, content, parent), nil
+}
+
+// runMultiRefile moves every subtree matched by a glob selector, or every
+// child of the --children-of heading, to one destination in a single write
+func runMultiRefile(ctx *cmdutil.CommandContext, ws *workspace.Workspace, source, to string, children bool) error {
+	if to == "" {
+		return ctx.HandleUsage(fmt.Errorf("destination path required: use --to flag"))
+	}
+	if leaveLink, _ := ctx.Cmd.Flags().GetBool("leave-link"); leaveLink {
+		return ctx.HandleUsage(fmt.Errorf("--leave-link moves a single subtree and cannot be used with several"))
+	}
+	field := "source path"
+	if children {
+		field = "children-of"
+	}
+	sourcePath, err := markdown.ParsePath(source)
+	if err != nil {
+		return ctx.HandleValidation(field, source, err)
+	}
+	if _, err := markdown.ParsePath(to); err != nil {
+		return ctx.HandleValidation("destination path", to, err)
+	}
+
+	subtrees, err := findMultiRefileSubtrees(ws, sourcePath, children)
+	if err != nil {
+		return ctx.HandleError(fmt.Errorf("failed to extract subtrees: %w", err))
+	}
+	if len(subtrees) == 0 {
+		return ctx.HandleError(fmt.Errorf("no subtrees match '%s'", source))
+	}
+	return refileSubtrees(ctx, ws, sourcePath, subtrees, source, to, nil)
+}
+
+// refileSubtrees moves subtrees of one file to the destination to in a
+// single write. results, when set, report each selected subtree; the ones
+// still pending are marked moved, or failed if the write fails.
+func refileSubtrees(ctx *cmdutil.CommandContext, ws *workspace.Workspace, sourcePath *markdown.HeadingPath, subtrees []*markdown.Subtree, source, to string, results []RefileItemResult) error {
+	prepend, _ := ctx.Cmd.Flags().GetBool("prepend")
+	verbose, _ := ctx.Cmd.Flags().GetBool("verbose")
+	destPath, err := markdown.ParsePath(to)
+	if err != nil {
+		return ctx.HandleValidation("destination path", to, err)
+	}
+
+	if verbose && !ctx.IsJSONOutput() {
+		for _, subtree := range subtrees {
+			printVerboseSubtreeInfo(subtree, sourcePath.File)
+		}
+	}
+
+	dest, err := ResolveDestination(ws, destPath, prepend)
+	if err != nil {
+		return ctx.HandleError(fmt.Errorf("failed to resolve destination: %w", err))
+	}
+	if verbose && !ctx.IsJSONOutput() {
+		printVerboseDestinationInfo(dest)
+	}
+
+	sourceFile := cmdutil.ResolveWorkspaceRelativePath(ws, sourcePath.File)
+	destFile := cmdutil.ResolveWorkspaceRelativePath(ws, dest.File)
+	if sourceFile == destFile {
+		for _, subtree := range subtrees {
+			if dest.InsertOffset > subtree.MarkerStart() && dest.InsertOffset < subtree.EndOffset {
+				return ctx.HandleError(fmt.Errorf("cannot refile '%s' into itself", subtree.Heading))
+			}
+		}
+	}
+
+	level, err := refileLevel(ctx.Cmd, dest.TargetLevel)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	op := multiRefileOperation(sourceFile, destFile, subtrees, dest, level)
+
+	for _, subtree := range subtrees {
+		if err := checkProtectedRange(ws, sourcePath.File, subtree.StartOffset, subtree.EndOffset); err != nil {
+			return ctx.HandleError(err)
+		}
+	}
+	if err := checkProtectedDestination(ws, dest); err != nil {
+		return ctx.HandleError(err)
+	}
+	if err := checkDestinationContent(ws, dest.File); err != nil {
+		return ctx.HandleError(err)
+	}
+
+	headings := make([]string, len(subtrees))
+	for i, subtree := range subtrees {
+		headings[i] = subtree.Heading
+	}
+	records := refileRecords(ws, sourcePath.File, subtrees, headings, dest, level)
+	change := mutation{Hook: "refile", Source: source, Dest: to, NoVerify: refileNoVerify}
+	err = change.run(ctx, ws, func() error {
+		if err := op.Execute(); err != nil {
+			if results != nil && !ctx.IsJSONOutput() {
+				printRefileResults(settleRefileResults(results, "failed", err.Error()))
+			}
+			return fmt.Errorf("refile operation failed: %w", err)
+		}
+		results = settleRefileResults(results, "moved", "")
+		recordRefiles(ws, records)
+		return nil
+	})
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	destSelector := destPath.File + "#" + strings.Join(destPath.Segments, "/")
+	if ctx.IsJSONOutput() {
+		response := MultiRefileResponse{
+			Operation: "refile",
+			Destination: RefileDestination{
+				Selector:        destSelector,
+				FilePath:        dest.File,
+				TargetLevel:     dest.TargetLevel,
+				PathExists:      dest.Exists,
+				CreatedHeadings: dest.CreatePath,
+			},
+			Count:    len(subtrees),
+			Level:    level,
+			Results:  results,
+			Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		}
+		for _, subtree := range subtrees {
+			response.Sources = append(response.Sources, RefileSource{
+				Selector:      sourcePath.File + "#" + subtree.Heading,
+				FilePath:      sourcePath.File,
+				Heading:       subtree.Heading,
+				OriginalLevel: subtree.Level,
+			})
+		}
+		return outputJSON(response)
+	}
+
+	if results != nil {
+		printRefileResults(results)
+	} else {
+		for _, subtree := range subtrees {
+			fmt.Printf("  %s\n", subtree.Heading)
+		}
+	}
+	noun := "subtrees"
+	if len(subtrees) == 1 {
+		noun = "subtree"
+	}
+	fmt.Printf("Successfully refiled %d %s to '%s'\n", len(subtrees), noun, destSelector)
+	return nil
+}
+
+// multiRefileOperation prepares moving several subtrees of one file to dest,
+// one after another, at level
+func multiRefileOperation(sourceFile, destFile string, subtrees []*markdown.Subtree, dest *DestinationTarget, level int) *RefileOperation {
+	op := &RefileOperation{
+		SourcePath:   sourceFile,
+		DestPath:     destFile,
+		Subtree:      subtrees[0],
+		Subtrees:     subtrees,
+		InsertOffset: dest.InsertOffset,
+		CreatePath:   dest.CreatePath,
+		TargetLevel:  dest.TargetLevel,
+	}
+	var moved []string
+	for _, subtree := range subtrees {
+		moved = append(moved, string(op.ensureConsistentFormatting(TransformSubtreeLevel(subtree, level))))
+	}
+	op.TransformedContent = []byte(strings.Join(moved, "\n"))
+	return op
+}
+
+// settleRefileResults gives every pending result the outcome of the write
+func settleRefileResults(results []RefileItemResult, outcome, reason string) []RefileItemResult {
+	for i := range results {
+		if results[i].Result == "" {
+			results[i].Result = outcome
+			results[i].Reason = reason
+		}
+	}
+	return results
+}
+
+// printRefileResults lists what happened to each selected subtree
+func printRefileResults(results []RefileItemResult) {
+	for _, result := range results {
+		line := fmt.Sprintf("  %-7s %s (line %d)", result.Result, result.Heading, result.Line)
+		if result.Reason != "" {
+			line += ": " + result.Reason
+		}
+		fmt.Println(line)
+	}
+}
+
+// runInteractiveMultiRefile lets several subtrees of one file be marked in
+// FZF and refiles them to one destination in a single confirmed write
+func runInteractiveMultiRefile(ctx *cmdutil.CommandContext, args []string, ws *workspace.Workspace) error {
+	to, _ := ctx.Cmd.Flags().GetString("to")
+	prepend, _ := ctx.Cmd.Flags().GetBool("prepend")
+	verbose, _ := ctx.Cmd.Flags().GetBool("verbose")
+
+	var sourceFile string
+	var err error
+	if len(args) > 0 {
+		if strings.Contains(args[0], "#") {
+			return ctx.HandleValidation("source", args[0], fmt.Errorf("--multi picks subtrees from a file; pass the file without a heading"))
+		}
+		sourceFile = args[0]
+	} else {
+		sourceFile, err = selectSourceFile(ws, "inbox.md", verbose)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("source file selection failed: %w", err))
+		}
+		if sourceFile == "" {
+			fmt.Println("Source selection cancelled.")
+			return nil
+		}
+	}
+
+	items, err := extractSubtreesFromFile(ws, sourceFile)
+	if err != nil {
+		return ctx.HandleError(fmt.Errorf("failed to extract subtrees: %w", err))
+	}
+	if len(items) == 0 {
+		return ctx.HandleError(fmt.Errorf("no headings found in %s - cannot refile from a file without headings", sourceFile))
+	}
+	picked, err := runSubtreeMultiFZF(ws, items, "Mark subtrees to refile > ")
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	if len(picked) == 0 {
+		fmt.Println("Source selection cancelled.")
+		return nil
+	}
+
+	if to == "" {
+		to, err = selectTarget(ws, verbose)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if to == "" {
+			fmt.Println("Target selection cancelled.")
+			return nil
+		}
+	}
+	destPath, err := markdown.ParsePath(to)
+	if err != nil {
+		return ctx.HandleValidation("destination path", to, err)
+	}
+
+	var dest *DestinationTarget
+	if resolved, err := ResolveDestination(ws, destPath, prepend); err == nil {
+		dest = resolved
+	}
+	subtrees, results, err := resolvePickedSubtrees(ws, sourceFile, picked, dest)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	confirmed, err := confirmMultiRefile(sourceFile, to, dest, results)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	if !confirmed {
+		fmt.Println("Refile cancelled.")
+		return nil
+	}
+	if len(subtrees) == 0 {
+		printRefileResults(results)
+		return ctx.HandleError(fmt.Errorf("none of the selected subtrees can be refiled"))
+	}
+	return refileSubtrees(ctx, ws, &markdown.HeadingPath{File: sourceFile}, subtrees, sourceFile, to, results)
+}
+
+// resolvePickedSubtrees finds the subtrees marked in FZF in the file as it
+// is now, in document order. Subtrees inside another marked one move with it
+// and are skipped; ones that changed since they were listed, are protected,
+// or contain the destination fail. Only the rest are returned to move.
+func resolvePickedSubtrees(ws *workspace.Workspace, sourceFile string, picked []SubtreeItem, dest *DestinationTarget) ([]*markdown.Subtree, []RefileItemResult, error) {
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, sourceFile))
+	if err != nil {
+		return nil, nil, cmdutil.NewFileError("read", sourceFile, err)
+	}
+	doc := markdown.ParseDocument(content)
+	sameFile := dest != nil && cmdutil.ResolveWorkspaceRelativePath(ws, dest.File) == cmdutil.ResolveWorkspaceRelativePath(ws, sourceFile)
+
+	sort.Slice(picked, func(i, j int) bool { return picked[i].Offset < picked[j].Offset })
+
+	var subtrees []*markdown.Subtree
+	var results []RefileItemResult
+	for _, item := range picked {
+		result := RefileItemResult{Heading: item.Title, Line: markdown.CalculateLineNumber(content, item.Offset)}
+		subtree := subtreeAt(doc, content, item.Offset)
+		switch {
+		case subtree == nil || subtree.Heading != item.Title:
+			result.Result, result.Reason = "failed", "the heading changed since it was listed"
+		case len(subtrees) > 0 && subtree.StartOffset < subtrees[len(subtrees)-1].EndOffset:
+			result.Result, result.Reason = "skipped", fmt.Sprintf("moves with '%s'", subtrees[len(subtrees)-1].Heading)
+		case sameFile && dest.InsertOffset > subtree.MarkerStart() && dest.InsertOffset < subtree.EndOffset:
+			result.Result, result.Reason = "failed", "the destination is inside it"
+		default:
+			if err := checkProtectedRange(ws, sourceFile, subtree.StartOffset, subtree.EndOffset); err != nil {
+				result.Result, result.Reason = "failed", err.Error()
+			} else {
+				subtrees = append(subtrees, subtree)
+			}
+		}
+		results = append(results, result)
+	}
+	return subtrees, results, nil
+}
+
+// subtreeAt returns the subtree whose heading line starts at offset
+func subtreeAt(doc ast.Node, content []byte, offset int) *markdown.Subtree {
+	var found *markdown.Subtree
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		if subtree := markdown.SubtreeFromHeading(heading, content); subtree.StartOffset == offset {
+			found = subtree
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// confirmMultiRefile lists a batch's subtrees and asks before moving them
+func confirmMultiRefile(sourceFile, to string, dest *DestinationTarget, results []RefileItemResult) (bool, error) {
+	border := strings.Repeat("=", 60)
+	noun := "SUBTREES"
+	if len(results) == 1 {
+		noun = "SUBTREE"
+	}
+
+	fmt.Printf("\n%s\n", border)
+	cmdutil.Printf("📋 REFILE %d %s FROM %s\n", len(results), noun, sourceFile)
+	fmt.Printf("%s\n", border)
+	for _, result := range results {
+		if result.Result == "" {
+			cmdutil.Printf("  📤 %s (line %d)\n", result.Heading, result.Line)
+		} else {
+			cmdutil.Printf("  ⚠️  %s (line %d) will be %s: %s\n", result.Heading, result.Line, result.Result, result.Reason)
+		}
+	}
+	cmdutil.Printf("  📥 Target: %s\n", to)
+	if dest != nil && len(dest.CreatePath) > 0 {
+		cmdutil.Printf("  ✨ Creates: '%s'\n", strings.Join(dest.CreatePath, "/"))
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+
+	return cmdutil.ConfirmOperation("\n🚀 Execute refile operation?")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/johncoder/jot/internal/markdown"
 	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
 	"github.com/yuin/goldmark/ast"
 )
 
@@ -254,7 +256,16 @@ func TestResolveDestinationPath(t *testing.T) {
 			skipLevels:     1,
 			prepend:        false,
 			expectExists:   false,
-			expectedLevel:  3, // Archive level 1 + Old Projects level 2 = content at level 3
+			expectedLevel:  4, // Archive level 2, as "/Archive" matches, + Old Projects level 3 = content at level 4
+			expectedCreate: []string{"Archive", "Old Projects"},
+		},
+		{
+			name:           "resolve new path without leading slash",
+			pathSegments:   []string{"Archive", "Old Projects"},
+			skipLevels:     0,
+			prepend:        false,
+			expectExists:   false,
+			expectedLevel:  3, // Created the same as /Archive/Old Projects, not at level 0
 			expectedCreate: []string{"Archive", "Old Projects"},
 		},
 		{
@@ -531,6 +542,144 @@ Old stuff
 	}
 }
 
+func TestResolvePickedSubtrees(t *testing.T) {
+	tempDir := t.TempDir()
+	ws := &workspace.Workspace{Root: tempDir, JotDir: filepath.Join(tempDir, ".jot"), InboxPath: filepath.Join(tempDir, "inbox.md")}
+	content := "# Inbox\n\n## Alpha\na\n### Alpha child\nc\n## Beta\nb\n## Gamma\ng\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "inbox.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := extractSubtreesFromFile(ws, "inbox.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byTitle := make(map[string]SubtreeItem)
+	for _, item := range items {
+		byTitle[item.Title] = item
+	}
+	stale := byTitle["Beta"]
+	stale.Title = "Renamed"
+	picked := []SubtreeItem{byTitle["Gamma"], byTitle["Alpha child"], stale, byTitle["Alpha"]}
+
+	subtrees, results, err := resolvePickedSubtrees(ws, "inbox.md", picked, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subtrees) != 2 || subtrees[0].Heading != "Alpha" || subtrees[1].Heading != "Gamma" {
+		t.Fatalf("subtrees = %v, want Alpha and Gamma in document order", subtrees)
+	}
+	want := []RefileItemResult{
+		{Heading: "Alpha", Line: 3},
+		{Heading: "Alpha child", Line: 5, Result: "skipped", Reason: "moves with 'Alpha'"},
+		{Heading: "Renamed", Line: 7, Result: "failed", Reason: "the heading changed since it was listed"},
+		{Heading: "Gamma", Line: 9},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
+
+func TestRefileVerification(t *testing.T) {
+	source := []byte("# Inbox\n\n## Task\n\nDetails\n\n## Other\n")
+	dest := []byte("# Work\n")
+	op := &RefileOperation{
+		SourcePath:         "inbox.md",
+		DestPath:           "work.md",
+		Subtree:            &markdown.Subtree{Heading: "Task", Level: 2, StartOffset: 9, EndOffset: 27},
+		TransformedContent: []byte("### Task\n\nDetails\n"),
+		CreatePath:         []string{"Projects"},
+		TargetLevel:        3,
+	}
+	before := map[string][]byte{"inbox.md": source, "work.md": dest}
+	check := op.verification()
+
+	good := map[string][]byte{
+		"inbox.md": []byte("# Inbox\n\n## Other\n"),
+		"work.md":  []byte("# Work\n\n## Projects\n\n### Task\n\nDetails\n"),
+	}
+	if err := check(before, good); err != nil {
+		t.Errorf("verification of a correct move failed: %v", err)
+	}
+
+	bad := []map[string][]byte{
+		{ // Removed but never inserted
+			"inbox.md": []byte("# Inbox\n\n## Other\n"),
+			"work.md":  []byte("# Work\n\n## Projects\n"),
+		},
+		{ // Inserted twice
+			"inbox.md": []byte("# Inbox\n\n## Other\n"),
+			"work.md":  []byte("# Work\n\n## Projects\n\n### Task\n\nDetails\n\n### Task\n\nDetails\n"),
+		},
+		{ // Other content lost
+			"inbox.md": []byte("# Inbox\n"),
+			"work.md":  []byte("# Work\n\n## Projects\n\n### Task\n\nDetails\n"),
+		},
+	}
+	for i, after := range bad {
+		if err := check(before, after); err == nil {
+			t.Errorf("case %d: verification passed a broken move", i)
+		}
+	}
+
+	// Content edited with --edit is checked as saved, headings and all
+	source = []byte("# Inbox\n\n## Task\n\n### Noise\n\nchatter\n")
+	op = &RefileOperation{
+		SourcePath:         "inbox.md",
+		DestPath:           "work.md",
+		Subtree:            &markdown.Subtree{Heading: "Task", Level: 2, StartOffset: 9, EndOffset: len(source)},
+		TransformedContent: []byte("## Task\n\nDone\n"),
+		TargetLevel:        2,
+	}
+	before = map[string][]byte{"inbox.md": source, "work.md": dest}
+	edited := map[string][]byte{
+		"inbox.md": []byte("# Inbox\n"),
+		"work.md":  []byte("# Work\n\n## Task\n\nDone\n"),
+	}
+	if err := op.verification()(before, edited); err != nil {
+		t.Errorf("verification of an edited move failed: %v", err)
+	}
+}
+
+func TestEditRefileContent(t *testing.T) {
+	dir := t.TempDir()
+	editorScript := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	content := []byte("### Standup\n\nnoise\nDecided to ship\n")
+
+	t.Setenv("EDITOR", editorScript("keep.sh", "true"))
+	edited, heading, err := editRefileContent(content, "Standup")
+	if err != nil || string(edited) != string(content) || heading != "Standup" {
+		t.Errorf("unchanged edit = %q, %q, %v", edited, heading, err)
+	}
+
+	t.Setenv("EDITOR", editorScript("tidy.sh", `sed -i '/noise/d; s/Standup/Standup decisions/' "$1"`))
+	edited, heading, err = editRefileContent(content, "Standup")
+	if err != nil || string(edited) != "### Standup decisions\n\nDecided to ship\n" || heading != "Standup decisions" {
+		t.Errorf("tidied edit = %q, %q, %v", edited, heading, err)
+	}
+
+	t.Setenv("EDITOR", editorScript("empty.sh", `: > "$1"`))
+	if _, _, err := editRefileContent(content, "Standup"); !errors.Is(err, errRefileEditEmpty) {
+		t.Errorf("emptied edit error = %v, want errRefileEditEmpty", err)
+	}
+
+	t.Setenv("EDITOR", editorScript("noheading.sh", `sed -i '1d' "$1"`))
+	if _, _, err := editRefileContent(content, "Standup"); err == nil {
+		t.Error("expected an error for an edit without a heading")
+	}
+}
+
 // Helper function to compare string slices
 func sliceEqual(a, b []string) bool {
 	if len(a) != len(b) {
@@ -543,3 +692,281 @@ func sliceEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestRefileKeepsMarkers(t *testing.T) {
+	tempDir := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      tempDir,
+		JotDir:    filepath.Join(tempDir, ".jot"),
+		InboxPath: filepath.Join(tempDir, "inbox.md"),
+	}
+	inbox := "# Inbox\n\n<!-- id: task -->\n## Task\ndo it\n\n<!-- id: other -->\n![[other]]\n## Other\nkeep\n"
+	work := "# Work\n\n## Projects\n\n<!-- id: archive -->\n## Archive\n"
+	if err := os.WriteFile(ws.InboxPath, []byte(inbox), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "work.md"), []byte(work), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sourcePath := &markdown.HeadingPath{File: "inbox.md", Segments: []string{"Inbox", "Task"}}
+	subtree, err := ExtractSubtree(ws, sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest, err := ResolveDestination(ws, &markdown.HeadingPath{File: "work.md", Segments: []string{"Work", "Projects"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := performRefile(ws, sourcePath, subtree, dest, TransformSubtreeLevel(subtree, dest.TargetLevel)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The task's marker moves with it, and the next heading's stays behind
+	gotInbox, _ := os.ReadFile(ws.InboxPath)
+	if want := "# Inbox\n\n<!-- id: other -->\n![[other]]\n## Other\nkeep\n"; string(gotInbox) != want {
+		t.Errorf("inbox = %q, want %q", gotInbox, want)
+	}
+	// It lands under Projects, before the markers of the heading after
+	gotWork, _ := os.ReadFile(filepath.Join(tempDir, "work.md"))
+	task := strings.Index(string(gotWork), "## Projects\n\n<!-- id: task -->\n### Task\n")
+	archive := strings.Index(string(gotWork), "\n\n<!-- id: archive -->\n## Archive\n")
+	if task < 0 || archive < task {
+		t.Errorf("work.md = %q, want the task and its marker under Projects, before Archive's marker", gotWork)
+	}
+}
+
+// refileWorkspace creates a workspace holding files, by relative path
+func refileWorkspace(t *testing.T, files map[string]string) *workspace.Workspace {
+	t.Helper()
+	root := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "inbox.md"),
+		LibDir:    filepath.Join(root, "lib"),
+	}
+	if err := os.MkdirAll(ws.JotDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ws
+}
+
+func TestRefileLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		want    int
+		wantErr bool
+	}{
+		{"automatic", nil, 3, false},
+		{"level", map[string]string{"level": "1"}, 1, false},
+		{"level 6", map[string]string{"level": "6"}, 6, false},
+		{"level past 6", map[string]string{"level": "7"}, 0, true},
+		{"promote", map[string]string{"promote": "2"}, 1, false},
+		{"promote past 1", map[string]string{"promote": "3"}, 0, true},
+		{"demote", map[string]string{"demote": "3"}, 6, false},
+		{"demote past 6", map[string]string{"demote": "4"}, 0, true},
+		{"negative", map[string]string{"promote": "-1"}, 0, true},
+		{"more than one", map[string]string{"level": "2", "demote": "1"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Int("level", 0, "")
+			cmd.Flags().Int("promote", 0, "")
+			cmd.Flags().Int("demote", 0, "")
+			for flag, value := range tt.flags {
+				if err := cmd.Flags().Set(flag, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := refileLevel(cmd, 3)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("refileLevel() = %d, %v; want %d, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefileLevelShiftsNestedHeadings(t *testing.T) {
+	ws := refileWorkspace(t, map[string]string{
+		"inbox.md": "# Inbox\n\n## Task\n\n### Sub\n\n#### Deep\n",
+	})
+	subtree, err := ExtractSubtree(ws, &markdown.HeadingPath{File: "inbox.md", Segments: []string{"Inbox", "Task"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		level int
+		want  string
+	}{
+		{1, "# Task\n\n## Sub\n\n### Deep\n"},
+		{3, "### Task\n\n#### Sub\n\n##### Deep\n"},
+		// Children that would pass level 6 stop there
+		{5, "##### Task\n\n###### Sub\n\n###### Deep\n"},
+	}
+	for _, tt := range tests {
+		if got := string(TransformSubtreeLevel(subtree, tt.level)); got != tt.want {
+			t.Errorf("TransformSubtreeLevel(%d) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestNewHeadingSelector(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Projects/Launch", "work.md#Projects/Launch"},
+		{" /Projects/Launch/ ", "work.md#Projects/Launch"},
+	}
+	for _, tt := range tests {
+		if got, err := newHeadingSelector("work.md", tt.query); err != nil || got != tt.want {
+			t.Errorf("newHeadingSelector(%q) = %q, %v; want %q", tt.query, got, err, tt.want)
+		}
+	}
+
+	// Without a typed path, it asks for one
+	stdin := func(input string) {
+		t.Helper()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.WriteString(input)
+		w.Close()
+		old := os.Stdin
+		os.Stdin = r
+		t.Cleanup(func() { os.Stdin = old; r.Close() })
+	}
+	stdin("Work/Launch\n")
+	if got, err := newHeadingSelector("work.md", ""); err != nil || got != "work.md#Work/Launch" {
+		t.Errorf("newHeadingSelector() with a typed answer = %q, %v", got, err)
+	}
+	stdin("\n")
+	if got, err := newHeadingSelector("work.md", ""); err != nil || got != "" {
+		t.Errorf("newHeadingSelector() with no answer = %q, %v; want it cancelled", got, err)
+	}
+}
+
+func TestRefileCreatesPickedHeading(t *testing.T) {
+	ws := refileWorkspace(t, map[string]string{
+		"inbox.md": "# Inbox\n\n## Task\ndo it\n",
+		"work.md":  "# Work\n\n## Projects\n",
+	})
+	selector, err := newHeadingSelector("work.md", "Work/Launch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	destPath, err := markdown.ParsePath(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest, err := ResolveDestination(ws, destPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sliceEqual(dest.CreatePath, []string{"Launch"}) {
+		t.Errorf("CreatePath = %v, want [Launch]", dest.CreatePath)
+	}
+
+	sourcePath := &markdown.HeadingPath{File: "inbox.md", Segments: []string{"Inbox", "Task"}}
+	subtree, err := ExtractSubtree(ws, sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := performRefile(ws, sourcePath, subtree, dest, TransformSubtreeLevel(subtree, dest.TargetLevel)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(filepath.Join(ws.Root, "work.md"))
+	if !strings.Contains(string(got), "## Launch\n") || !strings.Contains(string(got), "### Task\ndo it\n") {
+		t.Errorf("work.md = %q, want Task under a new Launch heading", got)
+	}
+}
+
+func TestRefileLeaveLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		inbox string
+		want  string
+	}{
+		{
+			name:  "between headings",
+			inbox: "# Inbox\n\n## Task\ndo it\n\n## Other\nkeep\n",
+			want:  "# Inbox\n\n> moved to work.md#Work/Archive/Task\n\n## Other\nkeep\n",
+		},
+		{
+			name:  "at the end of the file",
+			inbox: "# Inbox\n\n## Other\nkeep\n\n## Task\ndo it\n",
+			want:  "# Inbox\n\n## Other\nkeep\n\n> moved to work.md#Work/Archive/Task\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := refileWorkspace(t, map[string]string{
+				"inbox.md": tt.inbox,
+				"work.md":  "# Work\n\n## Archive\n",
+			})
+			sourcePath := &markdown.HeadingPath{File: "inbox.md", Segments: []string{"Inbox", "Task"}}
+			subtree, err := ExtractSubtree(ws, sourcePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dest, err := ResolveDestination(ws, &markdown.HeadingPath{File: "work.md", Segments: []string{"Work", "Archive"}}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stub := sourceStub(movedSelector(ws, "work.md", dest, subtree.Heading, dest.TargetLevel))
+			if err := performRefileWithStub(ws, sourcePath, subtree, dest, TransformSubtreeLevel(subtree, dest.TargetLevel), stub); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := os.ReadFile(ws.InboxPath)
+			if string(got) != tt.want {
+				t.Errorf("inbox = %q, want %q", got, tt.want)
+			}
+			work, _ := os.ReadFile(filepath.Join(ws.Root, "work.md"))
+			if !strings.Contains(string(work), "## Archive\n\n### Task\ndo it\n") {
+				t.Errorf("work.md = %q, want Task under Archive", work)
+			}
+		})
+	}
+}
+
+func TestRefileDuplicateHeadings(t *testing.T) {
+	ws := refileWorkspace(t, map[string]string{
+		"u.md":    "# U\n\n## Dup\nfirst\n\n## Dup\nsecond\n",
+		"work.md": "# Work\n",
+	})
+
+	sourcePath, _ := markdown.ParsePath("u.md#dup")
+	if _, err := ExtractSubtree(ws, sourcePath); !errors.Is(err, markdown.ErrAmbiguous) {
+		t.Fatalf("refile u.md#dup: err = %v, want multiple headings match", err)
+	}
+
+	sourcePath, _ = markdown.ParsePath("u.md#dup-1")
+	subtree, err := ExtractSubtree(ws, sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest, err := ResolveDestination(ws, &markdown.HeadingPath{File: "work.md", Segments: []string{"Work"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := performRefile(ws, sourcePath, subtree, dest, TransformSubtreeLevel(subtree, dest.TargetLevel)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(filepath.Join(ws.Root, "u.md"))
+	if !strings.Contains(string(got), "first") || strings.Contains(string(got), "second") {
+		t.Errorf("u.md = %q, want only the second Dup moved", got)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/johncoder/jot/internal/markdown"
+)
+
+// verifyWrites holds the global --verify-writes flag
+var verifyWrites bool
+
+// verification checks a refile's written files: the moved content appears
+// once more at the destination than it did with the subtrees removed, the
+// files hold as many headings as before plus any created on the path, and no
+// text was gained or lost beyond what was moved
+func (op *RefileOperation) verification() func(before, after map[string][]byte) error {
+	return func(before, after map[string][]byte) error {
+		moved := op.ensureConsistentFormatting(op.TransformedContent)
+
+		var removed, stubs []byte
+		source := before[op.SourcePath]
+		for _, subtree := range op.subtrees() {
+			if subtree.EndOffset <= len(source) {
+				removed = append(removed, source[subtree.MarkerStart():subtree.EndOffset]...)
+			}
+			stubs = append(stubs, op.Stub...)
+		}
+		var created []byte
+		if len(op.CreatePath) > 0 {
+			created = markdown.CreateHeadingStructure(op.CreatePath, op.TargetLevel-len(op.CreatePath))
+		}
+
+		destBefore := before[op.DestPath]
+		if op.IsSameFile() {
+			destBefore, _ = op.removeSubtrees(destBefore, 0)
+		}
+		block := collapseBlankLines(bytes.TrimSpace(moved))
+		want := bytes.Count(collapseBlankLines(destBefore), block) + 1
+		if got := bytes.Count(collapseBlankLines(after[op.DestPath]), block); got != want {
+			return fmt.Errorf("moved content appears %d time(s) in %s, expected %d", got, displayPath(op.DestPath), want)
+		}
+
+		headingsBefore, headingsAfter := 0, 0
+		textBefore, textAfter := 0, 0
+		for path, content := range after {
+			headingsBefore += len(markdown.ScanHeadings(before[path]))
+			headingsAfter += len(markdown.ScanHeadings(content))
+			textBefore += visibleBytes(before[path])
+			textAfter += visibleBytes(content)
+		}
+		// Content edited with --edit may gain or lose headings on the way
+		headingsBefore += len(markdown.ScanHeadings(moved)) - len(markdown.ScanHeadings(removed))
+		if want := headingsBefore + len(op.CreatePath); headingsAfter != want {
+			return fmt.Errorf("files hold %d heading(s) after the move, expected %d", headingsAfter, want)
+		}
+
+		want = textBefore - visibleBytes(removed) + visibleBytes(moved) + visibleBytes(created) + visibleBytes(stubs)
+		if textAfter != want {
+			return fmt.Errorf("files hold %d bytes of text after the move, expected %d", textAfter, want)
+		}
+		return nil
+	}
+}
+
+// collapseBlankLines reduces runs of blank lines to one, the way refile
+// normalizes spacing, so content can be compared across a move
+func collapseBlankLines(content []byte) []byte {
+	for bytes.Contains(content, []byte("\n\n\n")) {
+		content = bytes.ReplaceAll(content, []byte("\n\n\n"), []byte("\n\n"))
+	}
+	return content
+}
+
+// visibleBytes counts the bytes of content that are not whitespace
+func visibleBytes(content []byte) int {
+	count := 0
+	for _, c := range content {
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			count++
+		}
+	}
+	return count
+}
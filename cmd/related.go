@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/similarity"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var relatedCmd = &cobra.Command{
+	Use:   "related SELECTOR",
+	Short: "Find notes similar to a subtree",
+	Long: `Rank every other section in the workspace by textual similarity to a
+file or subtree, so you can find existing notes on the same topic before
+writing a duplicate.
+
+A section is a heading and the text up to the next heading. Sections inside
+the selected subtree are skipped.
+
+Backends:
+  tfidf       TF-IDF cosine similarity computed locally (default)
+  embedding   Cosine similarity of vectors from an external command. The
+              command reads {"texts": [...]} on stdin and prints
+              {"embeddings": [[...], ...]}, one vector per text. Set it with
+              --embed-command or "related_embed_command" in .jot/config.json.
+
+Examples:
+  jot related "inbox.md#kubernetes upgrade"
+  jot related "work.md#projects/search" --limit 5
+  jot related drafts.md --min-score 0.2
+  jot related "inbox.md#idea" --backend embedding --embed-command "python3 embed.py"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		backend, _ := cmd.Flags().GetString("backend")
+		embedCommand, _ := cmd.Flags().GetString("embed-command")
+		limit, _ := cmd.Flags().GetInt("limit")
+		minScore, _ := cmd.Flags().GetFloat64("min-score")
+
+		if embedCommand == "" && ws.Config != nil && ws.Config.RelatedEmbedCommand != "" {
+			if err := safemode.Check("the embed command from .jot/config.json"); err != nil {
+				return ctx.HandleError(err)
+			}
+			embedCommand = ws.Config.RelatedEmbedCommand
+		}
+
+		selector := args[0]
+		file := selector
+		var source *markdown.Subtree
+		var query []byte
+		if strings.Contains(selector, "#") {
+			sourcePath, err := markdown.ParsePath(selector)
+			if err != nil {
+				return ctx.HandleValidation("selector", selector, err)
+			}
+			source, err = ExtractSubtree(ws, sourcePath)
+			if err != nil {
+				return ctx.HandleError(fmt.Errorf("failed to extract subtree: %w", err))
+			}
+			file = sourcePath.File
+			query = source.Content
+		} else {
+			content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+			if err != nil {
+				return ctx.HandleError(cmdutil.NewFileError("read", file, err))
+			}
+			// A whole file excludes all of its own sections
+			source = &markdown.Subtree{StartOffset: 0, EndOffset: len(content)}
+			query = content
+		}
+
+		sections, err := collectWorkspaceSections(ws)
+		if err != nil {
+			return ctx.HandleOperationError("scan workspace", err)
+		}
+
+		sourceFilePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		bySelector := make(map[string]workspaceSection)
+		var docs []similarity.Document
+		for _, section := range sections {
+			if section.Within(sourceFilePath, source) {
+				continue
+			}
+			bySelector[section.Selector()] = section
+			docs = append(docs, similarity.Document{ID: section.Selector(), Text: section.Heading + "\n" + section.Content})
+		}
+
+		var matches []similarity.Match
+		switch backend {
+		case similarity.BackendTFIDF:
+			matches = similarity.RankTFIDF(string(query), docs)
+		case similarity.BackendEmbedding:
+			if embedCommand == "" {
+				return ctx.HandleValidation("embed-command", "", fmt.Errorf("the embedding backend needs --embed-command or related_embed_command"))
+			}
+			matches, err = similarity.RankEmbedding(&similarity.CommandEmbedder{Command: embedCommand}, string(query), docs)
+			if err != nil {
+				return ctx.HandleError(cmdutil.NewExternalError(embedCommand, nil, err))
+			}
+		default:
+			return ctx.HandleValidation("backend", backend, fmt.Errorf("must be %s or %s", similarity.BackendTFIDF, similarity.BackendEmbedding))
+		}
+
+		var results []RelatedResult
+		for _, match := range matches {
+			if match.Score <= 0 || match.Score < minScore || len(results) >= limit {
+				break
+			}
+			section := bySelector[match.ID]
+			results = append(results, RelatedResult{
+				Selector: match.ID,
+				File:     section.File,
+				Heading:  section.Heading,
+				Path:     section.Path,
+				Score:    match.Score,
+				Preview:  sectionPreview(section.Content, 80),
+			})
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(RelatedResponse{
+				Operation: "related",
+				Selector:  selector,
+				Backend:   backend,
+				Compared:  len(docs),
+				Results:   results,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(results) == 0 {
+			cmdutil.ShowInfo("No related notes found for '%s'", selector)
+			return nil
+		}
+
+		for _, result := range results {
+			fmt.Printf("%.2f  %s\n", result.Score, result.Selector)
+			if result.Preview != "" {
+				fmt.Printf("      %s\n", result.Preview)
+			}
+		}
+		return nil
+	},
+}
+
+// RelatedResponse represents the JSON response for the related command
+type RelatedResponse struct {
+	Operation string               `json:"operation"`
+	Selector  string               `json:"selector"`
+	Backend   string               `json:"backend"`
+	Compared  int                  `json:"compared"`
+	Results   []RelatedResult      `json:"results"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// RelatedResult is a section ranked by similarity
+type RelatedResult struct {
+	Selector string  `json:"selector"`
+	File     string  `json:"file"`
+	Heading  string  `json:"heading"`
+	Path     string  `json:"path"`
+	Score    float64 `json:"score"`
+	Preview  string  `json:"preview,omitempty"`
+}
+
+// workspaceSection is a heading and the text up to the next heading
+type workspaceSection struct {
+	File     string // Workspace-relative file name
+	FilePath string // Resolved file path
+	Heading  string // Heading text
+	Path     string // Heading path from the top of the file ("A/B/C")
+	Level    int    // Heading level
+	Offset   int    // Byte offset of the heading
+	Content  string // Text between this heading and the next heading
+}
+
+// Selector returns the full-path selector for the section
+func (s workspaceSection) Selector() string {
+	return s.File + "#" + s.Path
+}
+
+// Within reports whether the section's heading lies inside a subtree of a file
+func (s workspaceSection) Within(filePath string, subtree *markdown.Subtree) bool {
+	return subtree != nil && s.FilePath == filePath &&
+		s.Offset >= subtree.StartOffset && s.Offset < subtree.EndOffset
+}
+
+// collectWorkspaceSections returns every headed section in workspace markdown files
+func collectWorkspaceSections(ws *workspace.Workspace) ([]workspaceSection, error) {
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []workspaceSection
+	for _, file := range files {
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+
+		sections = append(sections, fileSections(file, filePath, content)...)
+	}
+
+	return sections, nil
+}
+
+// fileSections returns the headed sections of one markdown file
+func fileSections(file, filePath string, content []byte) []workspaceSection {
+	var sections []workspaceSection
+	headings := markdown.ScanHeadings(content)
+
+	var path []string
+	var levels []int
+	for i, heading := range headings {
+		text := heading.Text()
+		for len(levels) > 0 && levels[len(levels)-1] >= heading.Level {
+			levels = levels[:len(levels)-1]
+			path = path[:len(path)-1]
+		}
+		levels = append(levels, heading.Level)
+		path = append(path, text)
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		bodyEnd := len(content)
+		if i+1 < len(headings) {
+			bodyEnd = headings[i+1].Offset
+		}
+		body := ""
+		if heading.End < bodyEnd {
+			body = strings.TrimSpace(string(content[heading.End:bodyEnd]))
+		}
+
+		sections = append(sections, workspaceSection{
+			File:     file,
+			FilePath: filePath,
+			Heading:  text,
+			Path:     strings.Join(path, "/"),
+			Level:    heading.Level,
+			Offset:   heading.Offset,
+			Content:  body,
+		})
+	}
+
+	return sections
+}
+
+// sectionPreview returns the first line of section text, truncated to maxLen
+func sectionPreview(content string, maxLen int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if len(line) > maxLen {
+		line = line[:maxLen-3] + "..."
+	}
+	return line
+}
+
+func init() {
+	relatedCmd.Flags().String("backend", similarity.BackendTFIDF, "Similarity backend (tfidf, embedding)")
+	relatedCmd.Flags().String("embed-command", "", "Embedding command for the embedding backend")
+	relatedCmd.Flags().Int("limit", 10, "Maximum number of results")
+	relatedCmd.Flags().Float64("min-score", 0.05, "Minimum similarity score to report")
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/merge"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve FILE | FILE:LINE",
+	Short: "Resolve merge conflicts, or map a line to its selector",
+	Long: `Resolve git conflict markers in a markdown file one subtree at a time.
+
+Conflicts are grouped by the headings that enclose them. For each group jot
+shows both sides and asks which to keep:
+
+  o  ours     keep our side
+  t  theirs   keep their side
+  b  both     keep our side followed by their side
+  s  skip     leave the conflict markers in place
+  q  quit     stop and save the choices made so far
+
+Use --take to resolve every conflict the same way without prompting. After
+all conflicts are resolved, stage the file with 'git add' to finish the merge.
+
+With FILE:LINE, resolve prints the selector of the subtree containing that
+line instead, so editors can turn a cursor position into a selector. --json
+adds the heading path and the subtree's line and byte bounds.
+
+Examples:
+  jot resolve work.md
+  jot resolve inbox.md --take theirs
+  jot resolve lib/projects.md --take both --json
+  jot resolve work.md:42
+  jot peek "$(jot resolve work.md:42)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		take, _ := cmd.Flags().GetString("take")
+
+		if file, line, ok := parseLineLocation(args[0]); ok {
+			ws, err := workspace.GetWorkspaceContext(noWorkspace)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			return resolveLine(ctx, ws, file, line, noWorkspace)
+		}
+
+		var strategy merge.Resolution
+		if take != "" {
+			parsed, err := merge.ParseResolution(take)
+			if err != nil {
+				return ctx.HandleValidation("take", take, err)
+			}
+			strategy = parsed
+		} else if ctx.IsJSONOutput() {
+			return ctx.HandleValidation("take", "", fmt.Errorf("--take is required with --json"))
+		}
+
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		filePath := cmdutil.ResolvePath(ws, args[0], noWorkspace)
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("read", args[0], err))
+		}
+
+		doc, err := merge.Parse(string(content))
+		if err != nil {
+			return ctx.HandleOperationError("parse conflicts", err)
+		}
+
+		groups := doc.Groups()
+		if len(groups) == 0 {
+			if ctx.IsJSONOutput() {
+				return cmdutil.OutputJSON(newResolveResponse(ctx, args[0], doc))
+			}
+			cmdutil.ShowInfo("No conflicts found in %s", args[0])
+			return nil
+		}
+
+		if strategy != merge.Unresolved {
+			for _, c := range doc.Conflicts() {
+				c.Resolution = strategy
+			}
+		} else if err := promptConflictGroups(args[0], groups); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if err := dryrun.WriteFile(filePath, []byte(doc.Render()), 0644); err != nil {
+			return ctx.HandleError(cmdutil.NewFileError("write", args[0], err))
+		}
+
+		response := newResolveResponse(ctx, args[0], doc)
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(response)
+		}
+
+		fmt.Println()
+		cmdutil.ShowSuccess("✓ Resolved %d of %d conflicts in %s", response.Summary.Resolved, response.Summary.Total, args[0])
+		if response.Summary.Remaining > 0 {
+			cmdutil.ShowWarning("%d conflict(s) still have markers; run 'jot resolve %s' again to finish", response.Summary.Remaining, args[0])
+		} else {
+			cmdutil.ShowInfo("Run 'git add %s' to mark the file as resolved", args[0])
+		}
+		return nil
+	},
+}
+
+// promptConflictGroups asks for a resolution for each group of conflicts
+func promptConflictGroups(filename string, groups []merge.Group) error {
+	reader := bufio.NewReader(os.Stdin)
+	separator := strings.Repeat("-", 60)
+
+	for i, group := range groups {
+		location := "(top of file)"
+		if len(group.HeadingPath) > 0 {
+			location = strings.Join(group.HeadingPath, " > ")
+		}
+
+		fmt.Printf("\n%s\n", separator)
+		fmt.Printf("Subtree %d/%d: %s: %s\n", i+1, len(groups), filename, location)
+		for _, c := range group.Conflicts {
+			fmt.Printf("\nConflict at line %d\n", c.Line)
+			printConflictSide("ours", c.OursLabel, c.Ours)
+			printConflictSide("theirs", c.TheirsLabel, c.Theirs)
+		}
+		fmt.Printf("%s\n", separator)
+
+		resolution, quit, err := readConflictChoice(reader)
+		if err != nil {
+			return err
+		}
+		if quit {
+			return nil
+		}
+		for _, c := range group.Conflicts {
+			c.Resolution = resolution
+		}
+	}
+	return nil
+}
+
+// readConflictChoice prompts until a valid choice is entered. A skip leaves
+// the resolution unset.
+func readConflictChoice(reader *bufio.Reader) (merge.Resolution, bool, error) {
+	for {
+		fmt.Print("Keep [o]urs, [t]heirs, [b]oth, [s]kip, or [q]uit? ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return merge.Unresolved, false, fmt.Errorf("failed to read user input: %w", err)
+		}
+
+		switch choice := cmdutil.NormalizeUserInput(input); choice {
+		case "s", "skip":
+			return merge.Unresolved, false, nil
+		case "q", "quit":
+			return merge.Unresolved, true, nil
+		default:
+			if resolution, err := merge.ParseResolution(choice); err == nil {
+				return resolution, false, nil
+			}
+		}
+	}
+}
+
+func printConflictSide(side, label, content string) {
+	if label != "" {
+		fmt.Printf("  %s (%s):\n", side, label)
+	} else {
+		fmt.Printf("  %s:\n", side)
+	}
+	if content == "" {
+		fmt.Println("    (empty)")
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		fmt.Printf("    %s\n", line)
+	}
+}
+
+func newResolveResponse(ctx *cmdutil.CommandContext, filename string, doc *merge.Document) ResolveResponse {
+	response := ResolveResponse{
+		Operation: "resolve",
+		File:      filename,
+		Conflicts: []ResolveConflict{},
+		Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+	}
+	for _, c := range doc.Conflicts() {
+		response.Conflicts = append(response.Conflicts, ResolveConflict{
+			Line:        c.Line,
+			HeadingPath: c.HeadingPath,
+			Resolution:  c.Resolution.String(),
+		})
+		response.Summary.Total++
+		if c.Resolution == merge.Unresolved {
+			response.Summary.Remaining++
+		} else {
+			response.Summary.Resolved++
+		}
+	}
+	return response
+}
+
+// ResolveResponse represents the JSON response for the resolve command
+type ResolveResponse struct {
+	Operation string               `json:"operation"`
+	File      string               `json:"file"`
+	Conflicts []ResolveConflict    `json:"conflicts"`
+	Summary   ResolveSummary       `json:"summary"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type ResolveConflict struct {
+	Line        int      `json:"line"`
+	HeadingPath []string `json:"heading_path"`
+	Resolution  string   `json:"resolution"`
+}
+
+type ResolveSummary struct {
+	Total     int `json:"total"`
+	Resolved  int `json:"resolved"`
+	Remaining int `json:"remaining"`
+}
+
+func init() {
+	resolveCmd.Flags().String("take", "", "Resolve every conflict with ours, theirs or both without prompting")
+	resolveCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// lineLocationPattern matches "FILE:LINE" arguments
+var lineLocationPattern = regexp.MustCompile(`^(.+):(\d+)$`)
+
+// parseLineLocation splits a "FILE:LINE" argument
+func parseLineLocation(arg string) (string, int, bool) {
+	match := lineLocationPattern.FindStringSubmatch(arg)
+	if match == nil {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], line, true
+}
+
+// resolveLine maps a line of a file to the selector of the subtree containing it
+func resolveLine(ctx *cmdutil.CommandContext, ws *workspace.Workspace, file string, line int, noWorkspace bool) error {
+	filePath := resolvePeekFilePath(ws, file, noWorkspace)
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		return ctx.HandleError(cmdutil.NewFileError("read", file, err))
+	}
+
+	lineCount := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lineCount++
+	}
+	if line < 1 || line > lineCount {
+		return ctx.HandleValidation("line", strconv.Itoa(line), fmt.Errorf("%s has %d lines", file, lineCount))
+	}
+
+	headingMap, err := markdown.FindNearestHeadingsForLines(content, []int{line})
+	if err != nil {
+		return ctx.HandleOperationError("parse", err)
+	}
+
+	response := ResolveLineResponse{
+		Operation: "resolve_line",
+		File:      file,
+		Line:      line,
+		Selector:  file,
+		Subtree: LineSubtree{
+			StartLine:   1,
+			EndLine:     lineCount,
+			StartOffset: 0,
+			EndOffset:   len(content),
+		},
+	}
+
+	headingPath := headingMap[line]
+	for _, section := range fileSections(file, filePath, content) {
+		// A heading line belongs to its own subtree
+		if markdown.CalculateLineNumber(content, section.Offset) == line {
+			headingPath = section.Path
+		}
+	}
+
+	if headingPath != "" {
+		section, matches := sectionAtLine(file, filePath, content, headingPath, line)
+		if section != nil {
+			response.Heading = section.Heading
+			response.Level = section.Level
+			response.HeadingPath = strings.Split(section.Path, "/")
+			response.Selector = section.Selector()
+			response.Ambiguous = matches > 1
+			response.Subtree = lineSubtreeBounds(content, section)
+		}
+	}
+
+	if ctx.IsJSONOutput() {
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+
+	fmt.Println(response.Selector)
+	return nil
+}
+
+// sectionAtLine finds the section with the given heading path that starts at
+// or before line, and counts the sections sharing that path
+func sectionAtLine(file, filePath string, content []byte, headingPath string, line int) (*workspaceSection, int) {
+	var found *workspaceSection
+	matches := 0
+	for _, section := range fileSections(file, filePath, content) {
+		if section.Path != headingPath {
+			continue
+		}
+		matches++
+		if markdown.CalculateLineNumber(content, section.Offset) <= line {
+			section := section
+			found = &section
+		}
+	}
+	return found, matches
+}
+
+// lineSubtreeBounds returns the lines and bytes spanned by a section's subtree
+func lineSubtreeBounds(content []byte, section *workspaceSection) LineSubtree {
+	end := len(content)
+	for _, other := range fileSections(section.File, section.FilePath, content) {
+		if other.Offset > section.Offset && other.Level <= section.Level {
+			end = other.Offset
+			break
+		}
+	}
+
+	endLine := markdown.CalculateLineNumber(content, end)
+	if end == len(content) && end > 0 && content[end-1] != '\n' {
+		endLine++
+	}
+	return LineSubtree{
+		StartLine:   markdown.CalculateLineNumber(content, section.Offset),
+		EndLine:     endLine - 1,
+		StartOffset: section.Offset,
+		EndOffset:   end,
+	}
+}
+
+// ResolveLineResponse represents the JSON response for resolving FILE:LINE
+type ResolveLineResponse struct {
+	Operation   string               `json:"operation"`
+	File        string               `json:"file"`
+	Line        int                  `json:"line"`
+	Selector    string               `json:"selector"`
+	Heading     string               `json:"heading,omitempty"`
+	Level       int                  `json:"level,omitempty"`
+	HeadingPath []string             `json:"heading_path,omitempty"`
+	Ambiguous   bool                 `json:"ambiguous,omitempty"`
+	Subtree     LineSubtree          `json:"subtree"`
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type LineSubtree struct {
+	StartLine   int `json:"start_line"`
+	EndLine     int `json:"end_line"`
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func TestParseLineLocation(t *testing.T) {
+	tests := []struct {
+		arg  string
+		file string
+		line int
+		ok   bool
+	}{
+		{"work.md:12", "work.md", 12, true},
+		{"lib/a:b.md:3", "lib/a:b.md", 3, true},
+		{`C:\notes\work.md:7`, `C:\notes\work.md`, 7, true},
+		{`C:\notes\work.md`, "", 0, false},
+		{"work.md:abc", "", 0, false},
+		{"work.md:", "", 0, false},
+		{"work.md#Work", "", 0, false},
+	}
+	for _, tt := range tests {
+		file, line, ok := parseLineLocation(tt.arg)
+		if file != tt.file || line != tt.line || ok != tt.ok {
+			t.Errorf("parseLineLocation(%q) = %q, %d, %v; want %q, %d, %v", tt.arg, file, line, ok, tt.file, tt.line, tt.ok)
+		}
+	}
+}
+
+func TestResolveLine(t *testing.T) {
+	ws := refileWorkspace(t, map[string]string{
+		"work.md": "intro\n\n# Work\n\n## Notes\ntext\n\n## Other\nmore\n",
+	})
+
+	tests := []struct {
+		name     string
+		line     int
+		selector string
+		wantErr  bool
+	}{
+		{"inside a heading", 6, "work.md#Work/Notes", false},
+		{"on a heading", 8, "work.md#Work/Other", false},
+		{"before the first heading", 1, "work.md", false},
+		{"past the end of the file", 10, "", true},
+		{"line zero", 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("json", true, "")
+			var err error
+			out := captureOutput(t, func() {
+				err = resolveLine(cmdutil.StartCommand(cmd), ws, "work.md", tt.line, false)
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("line %d: expected an error", tt.line)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var response ResolveLineResponse
+			if err := json.Unmarshal(out, &response); err != nil {
+				t.Fatalf("bad JSON %q: %v", out, err)
+			}
+			if response.Selector != tt.selector {
+				t.Errorf("line %d resolved to %q, want %q", tt.line, response.Selector, tt.selector)
+			}
+		})
+	}
+}
+
+// captureOutput returns what fn prints to stdout
+func captureOutput(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	fn()
+	w.Close()
+	os.Stdout = old
+	return <-done
+}
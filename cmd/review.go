@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/template"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewDate  string
+	reviewPrint bool
+	reviewForce bool
+)
+
+var (
+	// deadlinePattern finds "due: 2025-07-11", "DEADLINE: <2025-07-11>" and "@due(2025-07-11)"
+	deadlinePattern = regexp.MustCompile(`(?i)\bdeadline:\s*<(\d{4}-\d{2}-\d{2})[^>\n]*>|@due\((\d{4}-\d{2}-\d{2})\)|\b(?:due|deadline):\s*(\d{4}-\d{2}-\d{2})\b`)
+	taskPrefix      = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?|^#+\s+(?:(?:TODO|DONE)\s+)?`)
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Generate periodic review documents",
+	Long: `Generate a review document for a week or a month and write it to a dated file.
+
+A review collects, for the period:
+  captured   notes captured, from the capture log
+  completed  done task items in files changed during the period
+  stale      inbox items older than the inbox aging threshold
+  deadlines  open items due within the next days, and overdue ones
+
+Deadlines are written as "due: 2025-07-11", "DEADLINE: <2025-07-11>" or
+"@due(2025-07-11)" anywhere on an item's line.
+
+The sections, their order, the files written and how far ahead to look for
+deadlines are set under "review" in .jot/config.json.
+
+Examples:
+  jot review weekly                    # Write this week's review
+  jot review weekly --date 2025-07-01  # The week containing July 1
+  jot review monthly --print           # Print this month's review, write nothing`,
+}
+
+var reviewWeeklyCmd = &cobra.Command{
+	Use:   "weekly",
+	Short: "Generate the review for a week, Monday to Sunday",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReview(cmd, "weekly")
+	},
+}
+
+var reviewMonthlyCmd = &cobra.Command{
+	Use:   "monthly",
+	Short: "Generate the review for a calendar month",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReview(cmd, "monthly")
+	},
+}
+
+func runReview(cmd *cobra.Command, kind string) error {
+	ctx := cmdutil.StartCommand(cmd)
+
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	day := time.Now()
+	if reviewDate != "" {
+		day, err = time.ParseInLocation("2006-01-02", reviewDate, time.Local)
+		if err != nil {
+			return ctx.HandleValidation("date", reviewDate, fmt.Errorf("use YYYY-MM-DD"))
+		}
+	}
+	cfg := ws.GetReview()
+	for _, section := range cfg.Sections {
+		if reviewSectionTitles[section] == "" {
+			return ctx.HandleValidation("review.sections", section,
+				fmt.Errorf("unknown section; use %s", strings.Join(workspace.DefaultReviewSections, ", ")))
+		}
+	}
+
+	start, end, destination := reviewPeriod(kind, day, cfg)
+	file := template.ExpandPlaceholders(destination, start, nil)
+	review, err := buildReview(ws, cfg, start, end, time.Now(), filepath.Dir(file))
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+	review.Kind = kind
+	review.File = file
+	review.Content = renderReview(review, cfg.Sections, start)
+
+	if !reviewPrint {
+		filePath := captureFilePath(ws, file)
+		if _, err := storage.Stat(filePath); err == nil && !reviewForce {
+			return ctx.HandleError(fmt.Errorf("%s already exists; use --force to replace it, or --print", file))
+		}
+		if err := cmdutil.WriteFileContent(filePath, []byte(review.Content)); err != nil {
+			return ctx.HandleFileOperation("write", file, err)
+		}
+		review.Written = true
+	}
+
+	if ctx.IsJSONOutput() {
+		review.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(review)
+	}
+	if reviewPrint {
+		fmt.Print(review.Content)
+		return nil
+	}
+	cmdutil.ShowSuccess("✓ Wrote %s review to %s", kind, file)
+	fmt.Printf("  %d captured, %d completed, %d stale, %d deadlines\n",
+		len(review.Captured), len(review.Completed), len(review.Stale), len(review.Deadlines))
+	return nil
+}
+
+// reviewPeriod returns the first day of the period containing day, the
+// first day after it, and the file its review is written to
+func reviewPeriod(kind string, day time.Time, cfg workspace.ReviewConfig) (time.Time, time.Time, string) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	if kind == "monthly" {
+		start := day.AddDate(0, 0, 1-day.Day())
+		return start, start.AddDate(0, 1, 0), cfg.Monthly
+	}
+	start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return start, start.AddDate(0, 0, 7), cfg.Weekly
+}
+
+// buildReview gathers each section's items. Files under skipDir, where
+// reviews are written, are not scanned, so reviews do not review themselves.
+func buildReview(ws *workspace.Workspace, cfg workspace.ReviewConfig, start, end, now time.Time, skipDir string) (*ReviewResponse, error) {
+	review := &ReviewResponse{
+		Start:     start.Format("2006-01-02"),
+		End:       end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Captured:  []ReviewCapture{},
+		Completed: []ReviewTask{},
+		Stale:     []InboxAgingItem{},
+		Deadlines: []ReviewTask{},
+	}
+
+	records, err := ws.LoadCaptures()
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		if record.Time.Before(start) || !record.Time.Before(end) || record.Amended {
+			continue
+		}
+		// An amended capture's latest text is in the records that follow it
+		for _, later := range records[i+1:] {
+			if later.Amended && later.Time.Equal(record.Time) {
+				record = later
+			}
+		}
+		title := record.Heading
+		if title == "" {
+			title, _, _ = strings.Cut(strings.TrimSpace(record.Content), "\n")
+		}
+		review.Captured = append(review.Captured, ReviewCapture{Time: record.Time, Title: title, File: record.File})
+	}
+
+	if report, err := buildInboxAgingReport(ws, now); err == nil {
+		review.Stale = report.StaleItems
+	}
+
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	horizon := today.AddDate(0, 0, cfg.DeadlineDays)
+	for _, file := range files {
+		if skipDir != "." && strings.HasPrefix(filepath.ToSlash(file), filepath.ToSlash(skipDir)+"/") {
+			continue
+		}
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+		info, err := storage.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		content, err := storage.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		changed := !info.ModTime().Before(start) && info.ModTime().Before(end)
+		completed, deadlines := reviewTasks(filepath.ToSlash(file), content, changed, horizon)
+		review.Completed = append(review.Completed, completed...)
+		review.Deadlines = append(review.Deadlines, deadlines...)
+	}
+	sort.SliceStable(review.Deadlines, func(i, j int) bool { return review.Deadlines[i].Due < review.Deadlines[j].Due })
+	for i := range review.Deadlines {
+		review.Deadlines[i].Overdue = review.Deadlines[i].Due < today.Format("2006-01-02")
+	}
+	return review, nil
+}
+
+// reviewTasks finds done task items, when completed is set, and open items
+// due before horizon in a file's content. Code blocks are skipped.
+func reviewTasks(file string, content []byte, completed bool, horizon time.Time) (done, due []ReviewTask) {
+	heading := ""
+	inFence := false
+	// Split rather than scan, so no line is too long to read
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		isDone := doneTodoPattern.MatchString(line)
+		if isDone && completed {
+			done = append(done, ReviewTask{Text: taskText(line), File: file, Heading: heading})
+		}
+		if match := deadlinePattern.FindStringSubmatch(line); match != nil && !isDone {
+			day := match[1] + match[2] + match[3] // Only one form matches
+			if date, err := time.ParseInLocation("2006-01-02", day, horizon.Location()); err == nil && date.Before(horizon) {
+				due = append(due, ReviewTask{Text: taskText(line), File: file, Heading: heading, Due: day})
+			}
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+	}
+	return done, due
+}
+
+// taskText returns an item's text without its list marker, checkbox,
+// heading markers or deadline
+func taskText(line string) string {
+	text := taskPrefix.ReplaceAllString(line, "")
+	text = deadlinePattern.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+var reviewSectionTitles = map[string]string{
+	"captured":  "Captured",
+	"completed": "Completed",
+	"stale":     "Stale Inbox Items",
+	"deadlines": "Upcoming Deadlines",
+}
+
+// renderReview writes the review document. Items are plain list entries, so
+// a later review does not count them as tasks or deadlines again.
+func renderReview(review *ReviewResponse, sections []string, start time.Time) string {
+	var b strings.Builder
+	if review.Kind == "monthly" {
+		fmt.Fprintf(&b, "# Monthly Review %s\n\n", start.Format("January 2006"))
+	} else {
+		fmt.Fprintf(&b, "# Weekly Review %s\n\n", template.ExpandPlaceholders("{{week}}", start, nil))
+	}
+	fmt.Fprintf(&b, "%s to %s\n", review.Start, review.End)
+
+	where := func(file, heading string) string {
+		if heading == "" {
+			return file
+		}
+		return file + "#" + heading
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", reviewSectionTitles[section])
+		count := 0
+		switch section {
+		case "captured":
+			for _, item := range review.Captured {
+				fmt.Fprintf(&b, "- %s %s (%s)\n", item.Time.Local().Format("Mon 01-02"), item.Title, item.File)
+			}
+			count = len(review.Captured)
+		case "completed":
+			for _, task := range review.Completed {
+				fmt.Fprintf(&b, "- %s (%s)\n", task.Text, where(task.File, task.Heading))
+			}
+			count = len(review.Completed)
+		case "stale":
+			for _, item := range review.Stale {
+				fmt.Fprintf(&b, "- %s, captured %d days ago\n", item.Heading, item.AgeDays)
+			}
+			count = len(review.Stale)
+		case "deadlines":
+			for _, task := range review.Deadlines {
+				overdue := ""
+				if task.Overdue {
+					overdue = " (overdue)"
+				}
+				fmt.Fprintf(&b, "- %s%s %s (%s)\n", task.Due, overdue, task.Text, where(task.File, task.Heading))
+			}
+			count = len(review.Deadlines)
+		}
+		if count == 0 {
+			b.WriteString("Nothing.\n")
+		}
+	}
+	return b.String()
+}
+
+// ReviewResponse represents the JSON response for review
+type ReviewResponse struct {
+	Kind      string               `json:"kind"` // weekly or monthly
+	Start     string               `json:"start"`
+	End       string               `json:"end"` // Last day of the period
+	File      string               `json:"file"`
+	Written   bool                 `json:"written"`
+	Captured  []ReviewCapture      `json:"captured"`
+	Completed []ReviewTask         `json:"completed"`
+	Stale     []InboxAgingItem     `json:"stale"`
+	Deadlines []ReviewTask         `json:"deadlines"`
+	Content   string               `json:"content"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// ReviewCapture is a note captured during the period
+type ReviewCapture struct {
+	Time  time.Time `json:"time"`
+	Title string    `json:"title"`
+	File  string    `json:"file"`
+}
+
+// ReviewTask is a completed item, or an item with a deadline
+type ReviewTask struct {
+	Text    string `json:"text"`
+	File    string `json:"file"`
+	Heading string `json:"heading,omitempty"`
+	Due     string `json:"due,omitempty"`
+	Overdue bool   `json:"overdue,omitempty"`
+}
+
+func init() {
+	reviewCmd.AddCommand(reviewWeeklyCmd)
+	reviewCmd.AddCommand(reviewMonthlyCmd)
+	reviewCmd.PersistentFlags().StringVar(&reviewDate, "date", "", "Review the period containing this date (YYYY-MM-DD)")
+	reviewCmd.PersistentFlags().BoolVar(&reviewPrint, "print", false, "Print the review instead of writing it")
+	reviewCmd.PersistentFlags().BoolVar(&reviewForce, "force", false, "Replace a review file that already exists")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestReviewTasks(t *testing.T) {
+	content := []byte(`# Release
+
+- [x] Write notes
+- [ ] Ship v2 due: 2025-07-10
+- [ ] Someday due: 2026-01-01
+## TODO Call vendor DEADLINE: <2025-07-08 Tue>
+- [x] Done already @due(2025-07-09)
+
+` + "```" + `
+- [x] Not a task
+` + "```" + `
+`)
+	horizon := time.Date(2025, 7, 20, 0, 0, 0, 0, time.Local)
+
+	done, due := reviewTasks("work.md", content, true, horizon)
+	if len(done) != 2 || done[0].Text != "Write notes" || done[0].Heading != "Release" {
+		t.Errorf("done = %+v", done)
+	}
+	if len(due) != 2 {
+		t.Fatalf("due = %+v", due)
+	}
+	if due[0].Text != "Ship v2" || due[0].Due != "2025-07-10" {
+		t.Errorf("due[0] = %+v", due[0])
+	}
+	if due[1].Text != "Call vendor" || due[1].Due != "2025-07-08" {
+		t.Errorf("due[1] = %+v", due[1])
+	}
+
+	if done, _ := reviewTasks("work.md", content, false, horizon); len(done) != 0 {
+		t.Errorf("expected no completed items from an unchanged file, got %+v", done)
+	}
+
+	// A line longer than a scanner's default limit does not hide the tasks after it
+	long := []byte("# Log\n\n" + strings.Repeat("x", 100*1024) + "\n- [x] After the log\r\n")
+	if done, _ := reviewTasks("log.md", long, true, horizon); len(done) != 1 || done[0].Text != "After the log" {
+		t.Errorf("done after a long line = %+v", done)
+	}
+}
+
+func TestReviewPeriod(t *testing.T) {
+	cfg := workspace.ReviewConfig{Weekly: "w", Monthly: "m"}
+	day := time.Date(2025, 7, 6, 15, 0, 0, 0, time.Local) // A Sunday
+
+	start, end, file := reviewPeriod("weekly", day, cfg)
+	if start.Format("2006-01-02") != "2025-06-30" || end.Format("2006-01-02") != "2025-07-07" || file != "w" {
+		t.Errorf("weekly = %s, %s, %s", start, end, file)
+	}
+	start, end, file = reviewPeriod("monthly", day, cfg)
+	if start.Format("2006-01-02") != "2025-07-01" || end.Format("2006-01-02") != "2025-08-01" || file != "m" {
+		t.Errorf("monthly = %s, %s, %s", start, end, file)
+	}
+}
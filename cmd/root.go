@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/johncoder/jot/internal/cmdutil"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -33,6 +34,8 @@ Examples:
   jot find <query>      # Search through your notes
   jot status            # Show workspace status
   jot doctor            # Diagnose and fix common issues`,
+	PersistentPreRunE:  prepareCommand,
+	PersistentPostRunE: finishCommand,
 }
 
 func Execute() error {
@@ -47,23 +50,54 @@ func Execute() error {
 	// Check if this is a known command first
 	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
 		// It's a known command, let cobra handle it normally
-		return rootCmd.Execute()
+		return executeRoot()
 	}
 
 	// Not a known command, try external command with proper global flag handling
 	if err := TryExecuteExternalCommand(args); err != nil {
 		// If it's marked as a built-in command, handle normally
 		if err.Error() == "built-in command" {
-			return rootCmd.Execute()
+			return executeRoot()
 		}
 		// For other errors (external command not found), let cobra handle to show error
-		return rootCmd.Execute()
+		return executeRoot()
 	}
 
 	// External command executed successfully
 	return nil
 }
 
+// executeRoot runs the command line. In JSON mode, an error the command
+// returned without writing it as JSON is written here, so every failure
+// produces an error object.
+func executeRoot() error {
+	usageErrors(rootCmd)
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil && jsonOutput && !cmdutil.ErrorReported() {
+		cmdutil.OutputJSONError(cmd, err, commandStartTime)
+	}
+	return err
+}
+
+// usageErrors reports the errors cobra raises for wrong arguments and flags
+// as validation errors, as commands report their own usage errors
+func usageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return cmdutil.NewUsageError(err)
+	})
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return cmdutil.NewUsageError(err)
+			}
+			return nil
+		}
+	}
+	for _, child := range cmd.Commands() {
+		usageErrors(child)
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -71,6 +105,13 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.jotrc)")
 	rootCmd.PersistentFlags().StringVarP(&workspaceName, "workspace", "w", "", "use specific workspace (bypasses discovery)")
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+	rootCmd.PersistentFlags().BoolVar(&humanOutput, "human", false, "output text for people, even when JSON is the configured default")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "print text without emoji, symbols or color, for screen readers")
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "show what would change without writing any files")
+	rootCmd.PersistentFlags().StringVar(&planFile, "plan", "", "write the changes a command would make to a plan file for 'jot apply'")
+	rootCmd.PersistentFlags().BoolVar(&verifyWrites, "verify-writes", false, "re-read files after refile-style moves and roll back if anything was lost")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "fail commands that need the network at once, without contacting anything")
+	rootCmd.PersistentFlags().BoolVar(&safeFlag, "safe", false, "never run the workspace's hooks, template shell commands or code blocks (on for unregistered workspaces)")
 
 	// Version handling - format output according to Linux CLI conventions
 	if version == "dev" || version == "" || !strings.HasPrefix(version, "v") {
@@ -131,6 +172,16 @@ func addCommands() {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(captureCmd)
 	rootCmd.AddCommand(refileCmd)
+	rootCmd.AddCommand(promoteCmd)
+	rootCmd.AddCommand(demoteCmd)
+	rootCmd.AddCommand(moveCmd)
+	rootCmd.AddCommand(appendCmd)
+	rootCmd.AddCommand(annotateCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(countCmd)
+	rootCmd.AddCommand(fsckCmd)
+	rootCmd.AddCommand(expandCmd)
+	rootCmd.AddCommand(recentCmd)
 	rootCmd.AddCommand(archiveCmd)
 	rootCmd.AddCommand(findCmd)
 	rootCmd.AddCommand(statusCmd)
@@ -141,6 +192,57 @@ func addCommands() {
 	rootCmd.AddCommand(tangleCmd)
 	rootCmd.AddCommand(workspaceCmd)
 	rootCmd.AddCommand(hooksCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(proofCmd)
+	rootCmd.AddCommand(suggestCmd)
+	rootCmd.AddCommand(relatedCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(resolveCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(scaffoldCmd)
+	rootCmd.AddCommand(editorRPCCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(selectorCmd)
+	rootCmd.AddCommand(tocCmd)
+	rootCmd.AddCommand(indexPageCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(recoverCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(accessCmd)
+	rootCmd.AddCommand(contextCmd)
+	rootCmd.AddCommand(previewCmd)
+	rootCmd.AddCommand(bookmarkCmd)
+	rootCmd.AddCommand(goCmd)
+	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(cpCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(gcCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(ingestCmd)
+	rootCmd.AddCommand(todoCmd)
+	rootCmd.AddCommand(linksCmd)
+	rootCmd.AddCommand(linkCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(runCaptureCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(readCmd)
+	rootCmd.AddCommand(unreadCmd)
+	registerSelectorCompletion()
+
+	// Commands that change notes hold the workspace lock while they run.
+	// Capture and template new and edit wait on an editor, so they lock
+	// around their writes instead, as do the editor RPC handlers.
+	registerMutating(
+		refileCmd, archiveCmd, gcCmd, mvCmd, cpCmd, moveCmd, promoteCmd, demoteCmd,
+		appendCmd, annotateCmd, applyCmd, recoverCmd, newCmd, logCmd, importCSVCmd,
+		ingestGitHubCmd, ingestGitLabCmd, todoSyncGitHubCmd, todoSyncGitLabCmd,
+		linksResolveCmd, reviewWeeklyCmd, reviewMonthlyCmd, indexPageGenerateCmd,
+		suggestRefileCmd, scaffoldCmd, countCmd, resolveCmd, runCaptureCmd,
+		evalCmd, tangleCmd, templateApproveCmd, templateRemoveCmd,
+	)
 }
 
 // getWorkspace returns a workspace using the global workspace flag override if provided
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/eval"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runSets     []string
+	runApprove  bool
+	runNoVerify bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run FILE [RECIPE]",
+	Short: "Run a shell recipe kept in a note",
+	Long: `Run a shell recipe kept in a note.
+
+A recipe is a fenced shell block marked with a recipe element:
+
+  <recipe name="deploy" params="env, version=latest" description="Ship a build" />
+  ` + "```" + `bash
+  ./deploy.sh "$env" "$version"
+  ` + "```" + `
+
+Declared parameters reach the script as environment variables. Give them
+with --set; any left out are asked for, with their defaults offered. The
+recipe runs attached to the terminal, in the note's directory unless it
+sets cwd="...", and its output is not written back to the note. A recipe
+may also set shell="..." and timeout="...".
+
+Recipes go through the same approval as eval blocks. An unapproved recipe,
+or one changed since it was approved, is shown and you are asked before it
+runs; answering yes approves that version. --approve approves it without
+asking. With --dry-run the script and parameters are shown and nothing
+runs. The pre-eval and post-eval hooks run unless --no-verify is given.
+
+Without RECIPE, the recipes in FILE are listed.
+
+Examples:
+  jot run ops.md                                   # List recipes
+  jot run ops.md deploy                            # Ask for env, then run
+  jot run ops.md deploy --set env=prod --set version=1.4
+  jot run ops.md deploy --set env=prod --dry-run   # Show what would run`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		filename := cmdutil.ResolvePath(ws, args[0], noWorkspace)
+
+		sm, err := eval.NewSecurityManager()
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to initialize security manager: %w", err))
+		}
+
+		if len(args) == 1 {
+			return listRecipes(ctx, sm, args[0], filename)
+		}
+
+		recipe, err := eval.FindRecipe(filename, args[1])
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if _, err := recipe.Shell(); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		values := make(map[string]string, len(runSets))
+		for _, set := range runSets {
+			name, value, ok := strings.Cut(set, "=")
+			if !ok || name == "" {
+				return ctx.HandleValidation("set", set, fmt.Errorf("use NAME=VALUE"))
+			}
+			values[name] = value
+		}
+		for name := range values {
+			if !declaresParam(recipe, name) {
+				return ctx.HandleValidation("set", name, fmt.Errorf("recipe '%s' has no parameter %s", recipe.Name, name))
+			}
+		}
+
+		interactive := !ctx.IsJSONOutput() && isTerminal(os.Stdin)
+		reader := bufio.NewReader(os.Stdin)
+		if err := fillParams(fmt.Sprintf("recipe '%s'", recipe.Name), recipe.Params, "set", values, interactive, reader); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		approved, err := recipe.CheckApproval(sm, filename)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if dryrun.Enabled() {
+			return showRecipePlan(ctx, recipe, values, approved)
+		}
+
+		if !approved {
+			switch {
+			case runApprove:
+			case interactive:
+				ok, err := confirmRecipe(recipe, reader)
+				if err != nil {
+					return ctx.HandleError(err)
+				}
+				if !ok {
+					fmt.Println("Not run.")
+					return nil
+				}
+			default:
+				return ctx.HandleError(fmt.Errorf("recipe '%s' requires approval; run 'jot run %s %s --approve' after reading it", recipe.Name, args[0], recipe.Name))
+			}
+			if err := recipe.Approve(sm, filename, eval.ApprovalModeHash); err != nil {
+				return ctx.HandleError(fmt.Errorf("failed to approve recipe: %w", err))
+			}
+		}
+
+		if err := safemode.Check("recipes"); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		runHooks := ws != nil && !runNoVerify
+		if runHooks {
+			result, err := hooks.NewManager(ws).Execute(&hooks.HookContext{
+				Type:        hooks.PreEval,
+				Workspace:   ws,
+				SourceFile:  filename,
+				AllowBypass: runNoVerify,
+			})
+			if err != nil {
+				return ctx.HandleExternalCommand("pre-eval hook", nil, err)
+			}
+			if result.Aborted {
+				return ctx.HandleOperationError("pre-eval hook", fmt.Errorf("pre-eval hook aborted operation"))
+			}
+		}
+
+		var stdout, stderr bytes.Buffer
+		if ctx.IsJSONOutput() {
+			err = recipe.Run(filename, values, nil, &stdout, &stderr)
+		} else {
+			err = recipe.Run(filename, values, os.Stdin, os.Stdout, os.Stderr)
+		}
+
+		if runHooks {
+			_, hookErr := hooks.NewManager(ws).Execute(&hooks.HookContext{
+				Type:        hooks.PostEval,
+				Workspace:   ws,
+				SourceFile:  filename,
+				AllowBypass: runNoVerify,
+			})
+			if hookErr != nil && !ctx.IsJSONOutput() {
+				cmdutil.ShowWarning("Warning: post-eval hook failed: %s", hookErr.Error())
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			response := RunResponse{
+				Recipe:   recipe.Name,
+				File:     args[0],
+				Params:   values,
+				Approved: true,
+				Ran:      true,
+				Stdout:   stdout.String(),
+				Stderr:   stderr.String(),
+			}
+			if err != nil {
+				response.Error = err.Error()
+			}
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, err == nil, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+		if err != nil {
+			return ctx.HandleOperationError("run recipe", err)
+		}
+		return nil
+	},
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+func declaresParam(recipe *eval.Recipe, name string) bool {
+	for _, p := range recipe.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fillParams asks for each parameter of owner not given with --flag,
+// offering its default. Without a terminal, defaults are used and a
+// parameter with none is an error.
+func fillParams(owner string, params []eval.Param, flag string, values map[string]string, interactive bool, reader *bufio.Reader) error {
+	for _, p := range params {
+		if _, ok := values[p.Name]; ok {
+			continue
+		}
+		if !interactive {
+			if !p.HasDefault {
+				return fmt.Errorf("%s needs %s (use --%s %s=VALUE)", owner, p.Name, flag, p.Name)
+			}
+			values[p.Name] = p.Default
+			continue
+		}
+
+		for {
+			if p.HasDefault {
+				fmt.Printf("%s [%s]: ", p.Name, p.Default)
+			} else {
+				fmt.Printf("%s: ", p.Name)
+			}
+			input, err := reader.ReadString('\n')
+			if err != nil && input == "" {
+				return fmt.Errorf("failed to read user input: %w", err)
+			}
+			input = strings.TrimSpace(input)
+			if input == "" && p.HasDefault {
+				input = p.Default
+			}
+			if input != "" || p.HasDefault {
+				values[p.Name] = input
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// confirmRecipe shows an unapproved recipe and asks whether to approve and
+// run it
+func confirmRecipe(recipe *eval.Recipe, reader *bufio.Reader) (bool, error) {
+	cmdutil.ShowWarning("⚠ Recipe '%s' has not been approved, or changed since it was:", recipe.Name)
+	fmt.Println()
+	for _, line := range recipe.Block.Code {
+		fmt.Printf("    %s\n", line)
+	}
+	fmt.Println()
+	fmt.Print("Approve and run it? [y/N]: ")
+	input, err := reader.ReadString('\n')
+	if err != nil && input == "" {
+		return false, fmt.Errorf("failed to read user input: %w", err)
+	}
+	return cmdutil.IsConfirmationYes(input), nil
+}
+
+// showRecipePlan prints what running a recipe would do
+func showRecipePlan(ctx *cmdutil.CommandContext, recipe *eval.Recipe, values map[string]string, approved bool) error {
+	shell, _ := recipe.Shell()
+	if ctx.IsJSONOutput() {
+		response := RunResponse{
+			Recipe:   recipe.Name,
+			Params:   values,
+			Approved: approved,
+			Shell:    shell,
+			Code:     recipe.Code(),
+		}
+		response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+		return cmdutil.OutputJSON(response)
+	}
+
+	fmt.Printf("Would run recipe '%s' with %s:\n", recipe.Name, shell)
+	for _, p := range recipe.Params {
+		fmt.Printf("  %s=%s\n", p.Name, values[p.Name])
+	}
+	fmt.Println()
+	for _, line := range recipe.Block.Code {
+		fmt.Printf("    %s\n", line)
+	}
+	if !approved {
+		fmt.Println()
+		cmdutil.ShowWarning("⚠ Not approved yet; you would be asked first")
+	}
+	return nil
+}
+
+func listRecipes(ctx *cmdutil.CommandContext, sm *eval.SecurityManager, display, filename string) error {
+	recipes, err := eval.FindRecipes(filename)
+	if err != nil {
+		return ctx.HandleError(err)
+	}
+
+	items := make([]RecipeItem, 0, len(recipes))
+	for _, recipe := range recipes {
+		approved, err := recipe.CheckApproval(sm, filename)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		params := recipe.Params
+		if params == nil {
+			params = []eval.Param{}
+		}
+		items = append(items, RecipeItem{
+			Name:        recipe.Name,
+			Description: recipe.Description,
+			Params:      params,
+			Line:        recipe.Block.StartLine,
+			Approved:    approved,
+		})
+	}
+
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(RunListResponse{
+			File:     display,
+			Recipes:  items,
+			Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	if len(items) == 0 {
+		fmt.Printf("No recipes in %s.\n", display)
+		return nil
+	}
+	fmt.Printf("Recipes in %s:\n", display)
+	for _, item := range items {
+		var names []string
+		for _, p := range item.Params {
+			if p.HasDefault {
+				names = append(names, p.Name+"="+p.Default)
+			} else {
+				names = append(names, p.Name)
+			}
+		}
+		status := "✓ APPROVED"
+		if !item.Approved {
+			status = "⚠ NEEDS APPROVAL"
+		}
+		line := "  " + item.Name
+		if len(names) > 0 {
+			line += " (" + strings.Join(names, ", ") + ")"
+		}
+		if item.Description != "" {
+			line += " - " + item.Description
+		}
+		cmdutil.Printf("%s  %s\n", line, status)
+	}
+	return nil
+}
+
+// RunResponse represents the JSON response for running a recipe
+type RunResponse struct {
+	Recipe   string               `json:"recipe"`
+	File     string               `json:"file,omitempty"`
+	Params   map[string]string    `json:"params"`
+	Approved bool                 `json:"approved"`
+	Ran      bool                 `json:"ran"`
+	Shell    string               `json:"shell,omitempty"`
+	Code     string               `json:"code,omitempty"`
+	Stdout   string               `json:"stdout,omitempty"`
+	Stderr   string               `json:"stderr,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// RunListResponse represents the JSON response for listing recipes
+type RunListResponse struct {
+	File     string               `json:"file"`
+	Recipes  []RecipeItem         `json:"recipes"`
+	Metadata cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// RecipeItem describes one recipe
+type RecipeItem struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Params      []eval.Param `json:"params"`
+	Line        int          `json:"line"`
+	Approved    bool         `json:"approved"`
+}
+
+func init() {
+	runCmd.Flags().StringArrayVar(&runSets, "set", nil, "Set a parameter, NAME=VALUE (repeatable)")
+	runCmd.Flags().BoolVar(&runApprove, "approve", false, "Approve the recipe as it is now without asking")
+	runCmd.Flags().BoolVar(&runNoVerify, "no-verify", false, "Skip the pre-eval and post-eval hooks")
+	runCmd.Flags().Bool("no-workspace", false, "Resolve FILE relative to the current directory")
+}
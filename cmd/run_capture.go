@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var runCaptureCmd = &cobra.Command{
+	Use:   "run-capture [flags] -- COMMAND [ARGS...]",
+	Short: "Run a command and file a record of it and its output",
+	Long: `Run a command and file a note recording it: the command, the directory it
+ran in, when it ran, how long it took, its exit code, and its output in a
+fenced block. For keeping reproducible records of ops work.
+
+A single argument is run by sh, so pipes and redirections work; several
+are run as they are. Output is shown as it comes, and stdout and stderr are
+both recorded, in the order they were written. Only the last --max-lines
+lines are kept in the note.
+
+The record goes under --to, or "to" under "run_capture" in
+.jot/config.json, or at the end of the inbox. Its heading is --title, or
+the command. jot exits with the command's exit code once the record is
+filed. With --dry-run the command is not run.
+
+Examples:
+  jot run-capture -- kubectl get pods -n prod
+  jot run-capture --to "ops.md#incidents/disk" -- "df -h | sort -k5 -r"
+  jot run-capture --title "Rotate certificates" --timeout 5m -- ./rotate.sh`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		cfg := ws.GetRunCapture()
+		to, _ := cmd.Flags().GetString("to")
+		if to == "" {
+			to = cfg.To
+		}
+		if to == "" {
+			to = "inbox.md"
+		}
+		selector := to
+		if !strings.Contains(selector, "#") {
+			selector += "#" // The end of the file
+		}
+		maxLines := cfg.MaxLines
+		if cmd.Flags().Changed("max-lines") {
+			maxLines, _ = cmd.Flags().GetInt("max-lines")
+		}
+		if maxLines < 0 {
+			return ctx.HandleValidation("max-lines", fmt.Sprint(maxLines), fmt.Errorf("must not be negative"))
+		}
+		title, _ := cmd.Flags().GetString("title")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		destPath, err := markdown.ParsePath(selector)
+		if err != nil {
+			return ctx.HandleValidation("to", to, err)
+		}
+		dest, err := ResolveDestination(ws, destPath, false)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to resolve destination: %w", err))
+		}
+
+		commandLine := shellJoin(args)
+		if title == "" {
+			title = commandLine
+		}
+		dir, err := os.Getwd()
+		if err != nil {
+			return ctx.HandleOperationError("get working directory", err)
+		}
+
+		if dryrun.Enabled() {
+			if ctx.IsJSONOutput() {
+				return cmdutil.OutputJSON(RunCaptureResponse{
+					Operation:   "run_capture",
+					Command:     commandLine,
+					Dir:         dir,
+					Destination: to,
+					Heading:     title,
+					Metadata:    cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+				})
+			}
+			fmt.Printf("Would run: %s\n", commandLine)
+			fmt.Printf("Would file the record under: %s\n", to)
+			return nil
+		}
+
+		var echo io.Writer
+		if !quiet && !ctx.IsJSONOutput() {
+			echo = os.Stdout
+		}
+		run, err := runCaptured(args, dir, timeout, echo)
+		if err != nil {
+			return ctx.HandleExternalCommand(args[0], args[1:], err)
+		}
+
+		output, shown, total := lastLines(run.Output, maxLines)
+		record := runRecord(title, dest.TargetLevel, commandLine, dir, run, output, shown, total)
+		if err := insertAtDestination(ws, dest, []byte(record)); err != nil {
+			return ctx.HandleOperationError("run-capture", err)
+		}
+
+		if ctx.IsJSONOutput() {
+			err = cmdutil.OutputJSON(RunCaptureResponse{
+				Operation:   "run_capture",
+				Command:     commandLine,
+				Dir:         dir,
+				Destination: to,
+				Heading:     title,
+				ExitCode:    run.ExitCode,
+				TimedOut:    run.TimedOut,
+				Duration:    run.Duration.Round(time.Millisecond).String(),
+				OutputLines: total,
+				LinesKept:   shown,
+				Metadata:    cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		} else {
+			status := fmt.Sprintf("exit %d", run.ExitCode)
+			if run.TimedOut {
+				status = "timed out"
+			}
+			cmdutil.ShowSuccess("✓ Filed '%s' under %s (%s)", title, to, status)
+		}
+
+		if run.ExitCode != 0 {
+			os.Exit(max(run.ExitCode, 1))
+		}
+		return err
+	},
+}
+
+// RunCaptureResponse is the JSON response for run-capture
+type RunCaptureResponse struct {
+	Operation   string               `json:"operation"`
+	Command     string               `json:"command"`
+	Dir         string               `json:"dir"`
+	Destination string               `json:"destination"`
+	Heading     string               `json:"heading"`
+	ExitCode    int                  `json:"exit_code"`
+	TimedOut    bool                 `json:"timed_out,omitempty"`
+	Duration    string               `json:"duration,omitempty"`
+	OutputLines int                  `json:"output_lines"` // Lines the command wrote
+	LinesKept   int                  `json:"lines_kept"`   // Lines kept in the note
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// capturedRun is the outcome of a command run by runCaptured
+type capturedRun struct {
+	Started  time.Time
+	Duration time.Duration
+	ExitCode int // -1 when it timed out
+	TimedOut bool
+	Output   string // stdout and stderr, interleaved
+}
+
+// runCaptured runs args in dir, a single argument through sh, recording
+// stdout and stderr together and copying both to echo when it is set. It
+// only fails when the command could not be started.
+func runCaptured(args []string, dir string, timeout time.Duration, echo io.Writer) (*capturedRun, error) {
+	runCtx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, timeout)
+		defer cancel()
+	}
+
+	var command *exec.Cmd
+	if len(args) == 1 {
+		command = exec.CommandContext(runCtx, "sh", "-c", args[0])
+	} else {
+		command = exec.CommandContext(runCtx, args[0], args[1:]...)
+	}
+	command.Dir = dir
+	command.Stdin = os.Stdin
+
+	// Don't wait on children of a timed out command that still hold its pipes
+	command.WaitDelay = time.Second
+
+	output := &lockedBuffer{}
+	var out io.Writer = output
+	if echo != nil {
+		out = io.MultiWriter(output, echo)
+	}
+	command.Stdout = out
+	command.Stderr = out
+
+	run := &capturedRun{Started: time.Now()}
+	err := command.Run()
+	run.Duration = time.Since(run.Started)
+	run.Output = output.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case runCtx.Err() == context.DeadlineExceeded:
+		run.ExitCode = -1
+		run.TimedOut = true
+	case errors.As(err, &exitErr):
+		run.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		return nil, err
+	}
+	return run, nil
+}
+
+// lockedBuffer is a buffer stdout and stderr may be copied to at once
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// lastLines returns the last limit lines of output, all of them when limit
+// is 0, with how many were kept and how many there were
+func lastLines(output string, limit int) (string, int, int) {
+	output = strings.TrimRight(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	if output == "" {
+		return "", 0, 0
+	}
+	lines := strings.Split(output, "\n")
+	if limit > 0 && len(lines) > limit {
+		return strings.Join(lines[len(lines)-limit:], "\n"), limit, len(lines)
+	}
+	return output, len(lines), len(lines)
+}
+
+// runRecord formats a run as a subtree at level: a heading, a list of
+// what ran where and how it went, and the output in a fence no line of
+// it can close
+func runRecord(title string, level int, commandLine, dir string, run *capturedRun, output string, shown, total int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n\n", strings.Repeat("#", level), strings.ReplaceAll(title, "\n", " "))
+	fmt.Fprintf(&b, "- Command: %s\n", inlineCode(commandLine))
+	fmt.Fprintf(&b, "- Directory: %s\n", inlineCode(dir))
+	fmt.Fprintf(&b, "- Started: %s\n", run.Started.Format(logTimeLayout))
+	fmt.Fprintf(&b, "- Duration: %s\n", run.Duration.Round(time.Millisecond))
+	if run.TimedOut {
+		b.WriteString("- Exit code: none, timed out\n")
+	} else {
+		fmt.Fprintf(&b, "- Exit code: %d\n", run.ExitCode)
+	}
+
+	if total == 0 {
+		b.WriteString("\n_No output._\n")
+		return b.String()
+	}
+	if shown < total {
+		fmt.Fprintf(&b, "\n_Showing the last %d of %d lines of output._\n", shown, total)
+	}
+	fence := strings.Repeat("`", max(3, longestRun(output, '`')+1))
+	fmt.Fprintf(&b, "\n%sconsole\n%s\n%s\n", fence, output, fence)
+	return b.String()
+}
+
+// inlineCode wraps text in a code span, with enough backticks that none in
+// text end it
+func inlineCode(text string) string {
+	ticks := strings.Repeat("`", longestRun(text, '`')+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return ticks + " " + text + " " + ticks
+	}
+	return ticks + text + ticks
+}
+
+// longestRun returns the length of the longest run of c in text
+func longestRun(text string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// shellJoin writes args as a shell command line, quoting those that need
+// it. A single argument is already one, as sh will run it.
+func shellJoin(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.IndexFunc(arg, needsQuoting) < 0 {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+func needsQuoting(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=@%+,", r)
+}
+
+func init() {
+	// Flags after the command belong to it
+	runCaptureCmd.Flags().SetInterspersed(false)
+	runCaptureCmd.Flags().String("to", "", "Destination selector (default: run_capture.to, or the inbox)")
+	runCaptureCmd.Flags().String("title", "", "Heading of the record (default: the command)")
+	runCaptureCmd.Flags().Int("max-lines", workspace.DefaultRunCaptureMaxLines, "Output lines to keep, the last ones (0 for all)")
+	runCaptureCmd.Flags().Duration("timeout", 0, "Stop the command after this long, such as 30s or 5m")
+	runCaptureCmd.Flags().BoolP("quiet", "q", false, "Do not show the output as it runs")
+	runCaptureCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected subtrees, and binary or very large files")
+}
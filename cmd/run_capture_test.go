@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCaptured(t *testing.T) {
+	run, err := runCaptured([]string{"echo out; echo err >&2; exit 3"}, t.TempDir(), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.ExitCode != 3 || run.TimedOut {
+		t.Errorf("exit code = %d, timed out = %v, want 3 and false", run.ExitCode, run.TimedOut)
+	}
+	if run.Output != "out\nerr\n" {
+		t.Errorf("output = %q, want stdout and stderr in order", run.Output)
+	}
+
+	run, err = runCaptured([]string{"sleep", "5"}, t.TempDir(), 50*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !run.TimedOut || run.ExitCode != -1 {
+		t.Errorf("exit code = %d, timed out = %v, want -1 and true", run.ExitCode, run.TimedOut)
+	}
+
+	if _, err := runCaptured([]string{"no-such-command-for-jot", "x"}, t.TempDir(), 0, nil); err == nil {
+		t.Error("expected an error for a command that cannot start")
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		output string
+		limit  int
+		want   string
+		shown  int
+		total  int
+	}{
+		{"", 10, "", 0, 0},
+		{"a\nb\nc\n", 0, "a\nb\nc", 3, 3},
+		{"a\r\nb\r\nc\r\n", 5, "a\nb\nc", 3, 3},
+		{"a\nb\nc\n\n", 2, "b\nc", 2, 3},
+	}
+	for _, tt := range tests {
+		got, shown, total := lastLines(tt.output, tt.limit)
+		if got != tt.want || shown != tt.shown || total != tt.total {
+			t.Errorf("lastLines(%q, %d) = %q, %d, %d, want %q, %d, %d", tt.output, tt.limit, got, shown, total, tt.want, tt.shown, tt.total)
+		}
+	}
+}
+
+func TestRunRecord(t *testing.T) {
+	run := &capturedRun{
+		Started:  time.Date(2024, 6, 12, 14, 3, 0, 0, time.Local),
+		Duration: 1204 * time.Millisecond,
+		ExitCode: 2,
+	}
+	output := "before\n```\nafter"
+	got := runRecord("Deploy", 3, "make `deploy`", "/srv/app", run, output, 3, 10)
+	want := "### Deploy\n\n" +
+		"- Command: `` make `deploy` ``\n" +
+		"- Directory: `/srv/app`\n" +
+		"- Started: 2024-06-12 14:03\n" +
+		"- Duration: 1.204s\n" +
+		"- Exit code: 2\n" +
+		"\n_Showing the last 3 of 10 lines of output._\n" +
+		"\n````console\nbefore\n```\nafter\n````\n"
+	if got != want {
+		t.Errorf("runRecord() = %q, want %q", got, want)
+	}
+
+	run.TimedOut = true
+	got = runRecord("Wait", 2, "sleep 9", "/", run, "", 0, 0)
+	if !strings.Contains(got, "- Exit code: none, timed out\n") || !strings.HasSuffix(got, "\n_No output._\n") {
+		t.Errorf("runRecord() = %q, want a timed out run with no output", got)
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"df -h | sort"}, "df -h | sort"},
+		{[]string{"kubectl", "get", "pods", "-n", "prod"}, "kubectl get pods -n prod"},
+		{[]string{"echo", "two words", "it's", ""}, `echo 'two words' 'it'\''s' ''`},
+	}
+	for _, tt := range tests {
+		if got := shellJoin(tt.args); got != tt.want {
+			t.Errorf("shellJoin(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// safeFlag holds the global --safe flag
+var safeFlag bool
+
+// configureSafeMode turns safe mode on with --safe or JOT_SAFE, and for a
+// workspace found by discovery that is not in the workspace registry.
+// --safe=false and JOT_SAFE=0 run an unregistered workspace's code anyway.
+func configureSafeMode(cmd *cobra.Command) {
+	if cmd.Flags().Changed("safe") {
+		safemode.Configure(safeFlag, "--safe was given")
+		return
+	}
+	if on, err := strconv.ParseBool(os.Getenv("JOT_SAFE")); err == nil {
+		safemode.Configure(on, "JOT_SAFE is set")
+		return
+	}
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		safemode.Configure(false, "")
+		return
+	}
+	if !isRegisteredWorkspace(ws) {
+		safemode.Configure(true, "this workspace is not in the workspace registry; trust it with 'jot workspace add NAME "+ws.Root+"'")
+		return
+	}
+	safemode.Configure(false, "")
+}
+
+// isRegisteredWorkspace reports whether the registry names ws, by its root
+// or, for a remote workspace, by its location
+func isRegisteredWorkspace(ws *workspace.Workspace) bool {
+	if err := config.Initialize(cfgFile); err != nil {
+		return false
+	}
+	root := canonicalPath(ws.Root)
+	for _, path := range config.ListWorkspaces() {
+		if ws.Location != "" && path == ws.Location {
+			return true
+		}
+		if canonicalPath(path) == root {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalPath returns path made absolute with symlinks resolved, so a
+// workspace reached through a link still matches its registry entry
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
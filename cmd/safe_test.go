@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestIsRegisteredWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	trusted := filepath.Join(dir, "notes")
+	if err := os.MkdirAll(trusted, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(trusted, link); err != nil {
+		t.Fatal(err)
+	}
+	rc := filepath.Join(dir, ".jotrc")
+	if err := os.WriteFile(rc, []byte(`{"workspaces": {"notes": "`+trusted+`/"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := cfgFile
+	cfgFile = rc
+	defer func() { cfgFile = saved }()
+
+	tests := map[string]bool{trusted: true, link: true, filepath.Join(dir, "cloned"): false}
+	for root, want := range tests {
+		if got := isRegisteredWorkspace(&workspace.Workspace{Root: root}); got != want {
+			t.Errorf("isRegisteredWorkspace(%s) = %v, want %v", root, got, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/protect"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var scaffoldCmd = &cobra.Command{
+	Use:   "scaffold FILE --outline PATHS",
+	Short: "Create a nested heading structure in a file",
+	Long: `Create a tree of headings in one step, so destinations exist before
+bulk refiling.
+
+--outline takes comma-separated heading paths. Each path uses the same
+segments as a selector: existing headings are matched with case-insensitive
+contains matching, and missing headings are created beneath the deepest
+match. Paths that already exist are left alone, so scaffold can be re-run.
+
+The file is created if it does not exist.
+
+Examples:
+  jot scaffold work.md --outline "Projects/Frontend,Projects/Backend,Meetings"
+  jot scaffold lib/reading.md --outline "Books/Fiction" --outline "Books/Nonfiction"
+  jot scaffold work.md --outline "//Archive/2025"   # Start at level 3`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		outline, _ := cmd.Flags().GetStringSlice("outline")
+		if len(outline) == 0 {
+			return ctx.HandleValidation("outline", "", fmt.Errorf("at least one heading path is required"))
+		}
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		file := args[0]
+		filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+
+		original, err := storage.ReadFile(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return ctx.HandleOperationError("read", err)
+		}
+		regions, relFile, err := protectedRegions(ws, file)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		content := append([]byte{}, original...)
+		var results []ScaffoldPath
+		for _, path := range outline {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+
+			destPath, err := markdown.ParsePath(file + "#" + path)
+			if err != nil {
+				return ctx.HandleValidation("outline", path, err)
+			}
+
+			// Check protection against the file as it is on disk
+			if len(regions) > 0 {
+				dest, err := resolveDestinationPath(markdown.ParseDocument(original), original, destPath, false)
+				if err != nil {
+					return ctx.HandleValidation("outline", path, err)
+				}
+				if len(dest.CreatePath) > 0 {
+					if region := protect.Containing(regions, dest.HeadingOffset); region != nil {
+						return ctx.HandleError(protectedError(relFile, region))
+					}
+				}
+			}
+
+			dest, err := resolveDestinationPath(markdown.ParseDocument(content), content, destPath, false)
+			if err != nil {
+				return ctx.HandleValidation("outline", path, err)
+			}
+
+			results = append(results, ScaffoldPath{
+				Path:    path,
+				Created: dest.CreatePath,
+				Exists:  dest.Exists,
+			})
+			if len(dest.CreatePath) > 0 {
+				content = insertHeadingStructure(content, dest)
+			}
+		}
+
+		created := 0
+		for _, result := range results {
+			created += len(result.Created)
+		}
+		if created > 0 {
+			if err := cmdutil.WriteFileContent(filePath, content); err != nil {
+				return ctx.HandleError(err)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(ScaffoldResponse{
+				Operation:       "scaffold",
+				File:            file,
+				Paths:           results,
+				HeadingsCreated: created,
+				Metadata:        cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		for _, result := range results {
+			if len(result.Created) == 0 {
+				cmdutil.ShowInfo("  exists   %s#%s", file, result.Path)
+			} else {
+				cmdutil.ShowSuccess("✓ created  %s#%s", file, result.Path)
+			}
+		}
+		if created == 0 {
+			cmdutil.ShowInfo("Nothing to create; %s already has this outline", file)
+		} else {
+			cmdutil.ShowSuccess("✓ Added %d heading(s) to %s", created, file)
+		}
+		return nil
+	},
+}
+
+// insertHeadingStructure inserts the missing headings of dest into content
+func insertHeadingStructure(content []byte, dest *DestinationTarget) []byte {
+	baseLevel := dest.TargetLevel - len(dest.CreatePath)
+	headings := markdown.CreateHeadingStructure(dest.CreatePath, baseLevel)
+
+	offset := dest.InsertOffset
+	if offset > 0 {
+		if content[offset-1] != '\n' {
+			headings = append([]byte("\n\n"), headings...)
+		} else {
+			headings = append([]byte("\n"), headings...)
+		}
+	}
+	// The insertion point is before the blank lines ending the parent's subtree
+	if offset < len(content) && content[offset] == '\n' {
+		headings = headings[:len(headings)-1]
+	}
+
+	result := make([]byte, 0, len(content)+len(headings))
+	result = append(result, content[:offset]...)
+	result = append(result, headings...)
+	return append(result, content[offset:]...)
+}
+
+// ScaffoldResponse represents the JSON response for the scaffold command
+type ScaffoldResponse struct {
+	Operation       string               `json:"operation"`
+	File            string               `json:"file"`
+	Paths           []ScaffoldPath       `json:"paths"`
+	HeadingsCreated int                  `json:"headings_created"`
+	Metadata        cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type ScaffoldPath struct {
+	Path    string   `json:"path"`
+	Created []string `json:"created"`
+	Exists  bool     `json:"exists"`
+}
+
+func init() {
+	scaffoldCmd.Flags().StringSlice("outline", nil, "Comma-separated heading paths to create")
+	scaffoldCmd.Flags().BoolVar(&forceProtected, "force", false, "Write into protected files and subtrees")
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/johncoder/jot/internal/markdown"
+)
+
+// scaffoldOutline applies outline paths to content the way scaffold does
+func scaffoldOutline(t *testing.T, content string, paths ...string) string {
+	t.Helper()
+	result := []byte(content)
+	for _, path := range paths {
+		destPath, err := markdown.ParsePath("work.md#" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest, err := resolveDestinationPath(markdown.ParseDocument(result), result, destPath, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dest.CreatePath) > 0 {
+			result = insertHeadingStructure(result, dest)
+		}
+	}
+	return string(result)
+}
+
+func TestInsertHeadingStructure(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		paths   []string
+		want    string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			paths:   []string{"Projects/Frontend", "Projects/Backend", "Meetings"},
+			want:    "# Projects\n\n## Frontend\n\n## Backend\n\n# Meetings\n",
+		},
+		{
+			name:    "no trailing newline",
+			content: "# Work\n\nSome text",
+			paths:   []string{"Work/Projects"},
+			want:    "# Work\n\nSome text\n\n## Projects\n",
+		},
+		{
+			name:    "under an existing heading with content after it",
+			content: "# Work\n\n## Notes\n\nText.\n\n# Later\n",
+			paths:   []string{"Work/Meetings"},
+			want:    "# Work\n\n## Notes\n\nText.\n\n## Meetings\n\n# Later\n",
+		},
+		{
+			name:    "skip levels",
+			content: "",
+			paths:   []string{"//Archive/2025"},
+			want:    "### Archive\n\n#### 2025\n",
+		},
+		{
+			name:    "skip levels after other headings",
+			content: "# Work\n\n## Notes\n",
+			paths:   []string{"/Archive"},
+			want:    "# Work\n\n## Notes\n\n## Archive\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaffoldOutline(t, tt.content, tt.paths...); got != tt.want {
+				t.Errorf("scaffold =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaffoldRerun(t *testing.T) {
+	outline := []string{"Work/Projects/Frontend", "Work/Projects/Backend", "Work/Meetings", "//Archive"}
+	first := scaffoldOutline(t, "# Work\n\n## Projects\n\n### Frontend\n\nIn progress.\n", outline...)
+	want := "# Work\n\n## Projects\n\n### Frontend\n\nIn progress.\n\n### Backend\n\n## Meetings\n\n### Archive\n"
+	if first != want {
+		t.Fatalf("first run =\n%q\nwant\n%q", first, want)
+	}
+	if again := scaffoldOutline(t, first, outline...); again != first {
+		t.Errorf("second run changed the file:\n%q\nwant\n%q", again, first)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets kept in the OS keyring",
+	Long: `Manage tokens and other secrets kept in the OS keyring: the login
+keychain on macOS, or the Secret Service (GNOME Keyring, KWallet) through
+secret-tool on Linux.
+
+Configuration and templates refer to a secret by name, as
+{{secret github_token}}, so the value never has to be written to a file:
+
+  "issues": {"github": {"token": "{{secret github_token}}"}}
+  "links": {"tickets": [{"lookup": "jira", "token": "{{secret jira_token}}", ...}]}
+
+In a template, a secret may appear inside a $(...) command, which gets it
+in an environment variable rather than on its command line:
+
+  $(curl -s -H "Authorization: Bearer {{secret github_token}}" https://...)
+
+Secrets belong to the user, not the workspace, so every workspace sees the
+same ones.
+
+Examples:
+  jot secret set github_token
+  gh auth token | jot secret set github_token
+  jot secret get github_token
+  jot secret remove github_token`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set NAME",
+	Short: "Store a secret, read from stdin or prompted for",
+	Long: `Store a secret in the OS keyring, replacing any with the same name.
+
+The value is read from stdin when it is piped, or prompted for without
+echo. There is no flag for it, so it stays out of shell history.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		name := args[0]
+		if err := secrets.ValidateName(name); err != nil {
+			return ctx.HandleValidation("name", name, err)
+		}
+
+		value, err := readSecretValue(name)
+		if err != nil {
+			return ctx.HandleOperationError("read secret", err)
+		}
+		if value == "" {
+			return ctx.HandleValidation("value", "", fmt.Errorf("secret is empty"))
+		}
+
+		// The keyring is outside the workspace, so --dry-run leaves it alone
+		if !dryrun.Enabled() {
+			if err := secrets.Set(name, value); err != nil {
+				return ctx.HandleOperationError("store secret", err)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(SecretResponse{
+				Operation: "set_secret",
+				Name:      name,
+				Reference: "{{secret " + name + "}}",
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Stored secret %s; refer to it as {{secret %s}}", name, name)
+		return nil
+	},
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get NAME",
+	Short: "Print a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		value, err := secrets.Get(args[0])
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(SecretResponse{
+				Operation: "get_secret",
+				Name:      args[0],
+				Reference: "{{secret " + args[0] + "}}",
+				Value:     value,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var secretRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a secret from the keyring",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		if dryrun.Enabled() {
+			if _, err := secrets.Get(args[0]); err != nil {
+				return ctx.HandleError(err)
+			}
+		} else if err := secrets.Delete(args[0]); err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(SecretResponse{
+				Operation: "remove_secret",
+				Name:      args[0],
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		cmdutil.ShowSuccess("✓ Removed secret %s", args[0])
+		return nil
+	},
+}
+
+// SecretResponse is the JSON response for secret commands. The value is only
+// given by get.
+type SecretResponse struct {
+	Operation string               `json:"operation"`
+	Name      string               `json:"name"`
+	Reference string               `json:"reference,omitempty"`
+	Value     string               `json:"value,omitempty"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// readSecretValue reads a secret's value from stdin, prompting for it with
+// echo turned off when stdin is a terminal. One trailing newline is dropped.
+func readSecretValue(name string) (string, error) {
+	if !isTerminal(os.Stdin) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+	if err := stty("-echo"); err == nil {
+		defer func() {
+			_ = stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// stty sets a mode of the terminal on stdin
+func stty(mode string) error {
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+func init() {
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretRemoveCmd)
+}
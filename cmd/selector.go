@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var selectorCmd = &cobra.Command{
+	Use:   "selector",
+	Short: "Inspect selectors",
+	Long: `Inspect selectors before using them in scripts.
+
+Examples:
+  jot selector check "work.md#projects/frontend"
+  jot selector check "inbox.md#meeting" --json`,
+}
+
+var selectorCheckCmd = &cobra.Command{
+	Use:   "check SELECTOR",
+	Short: "Check whether a selector resolves to exactly one subtree",
+	Long: `Check whether a selector resolves, and to what.
+
+Reports the number of matching subtrees and, for each, its heading, level
+and line. When the selector matches several subtrees, full-path selectors
+for each are suggested; when it matches none, headings containing the last
+segment are suggested.
+
+Exits with status 1 unless the selector resolves to exactly one subtree, or
+names an existing file.
+
+Examples:
+  jot selector check "work.md#projects/frontend"
+  jot selector check "inbox.md#meeting" --json
+  jot selector check @proj && jot refile "inbox.md#todo" --to @proj`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		noWorkspace, _ := cmd.Flags().GetBool("no-workspace")
+		ws, err := workspace.GetWorkspaceContext(noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		response, err := checkSelector(ws, args[0], noWorkspace)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			if err := cmdutil.OutputJSON(response); err != nil {
+				return err
+			}
+		} else {
+			printSelectorCheck(response)
+		}
+
+		if !response.Valid {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// checkSelector resolves a selector and describes every match
+func checkSelector(ws *workspace.Workspace, selector string, noWorkspace bool) (*SelectorCheckResponse, error) {
+	response := &SelectorCheckResponse{
+		Operation: "selector_check",
+		Selector:  selector,
+		Matches:   []SelectorMatch{},
+	}
+
+	file := selector
+	var path *markdown.HeadingPath
+	if strings.Contains(selector, "#") {
+		parsed, err := markdown.ParsePath(selector)
+		if err != nil {
+			response.Reason = err.Error()
+			return response, nil
+		}
+		path = parsed
+		file = parsed.File
+	}
+	response.File = file
+
+	content, err := storage.ReadFile(cmdutil.ResolvePath(ws, file, noWorkspace))
+	if os.IsNotExist(err) {
+		response.Reason = fmt.Sprintf("file not found: %s", file)
+		return response, nil
+	}
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", file, err)
+	}
+	response.FileExists = true
+
+	if path == nil || len(path.Segments) == 0 {
+		response.Valid = true
+		return response, nil
+	}
+
+	doc := markdown.ParseDocument(content)
+	headings := markdown.FindAllHeadings(doc, content)
+	for _, match := range markdown.FindSubtreeMatches(doc, content, path) {
+		line := markdown.CalculateLineNumber(content, match.StartOffset)
+		selectorMatch := SelectorMatch{Heading: match.Heading, Level: match.Level, Line: line}
+		for _, heading := range headings {
+			if markdown.CalculateLineNumber(content, heading.Offset) == line {
+				selectorMatch.Selector = file + "#" + strings.Join(heading.Path, "/")
+				break
+			}
+		}
+		response.Matches = append(response.Matches, selectorMatch)
+	}
+	response.MatchCount = len(response.Matches)
+
+	switch response.MatchCount {
+	case 0:
+		response.Reason = fmt.Sprintf("no headings match %q", strings.Join(path.Segments, "/"))
+		last := path.Segments[len(path.Segments)-1]
+		for _, heading := range headings {
+			if markdown.ContainsFold(heading.Text, last) {
+				response.Suggestions = append(response.Suggestions, file+"#"+strings.Join(heading.Path, "/"))
+			}
+		}
+	case 1:
+		response.Valid = true
+	default:
+		response.Reason = fmt.Sprintf("%d headings match %q", response.MatchCount, strings.Join(path.Segments, "/"))
+		for _, match := range response.Matches {
+			response.Suggestions = append(response.Suggestions, match.Selector)
+		}
+	}
+
+	return response, nil
+}
+
+func printSelectorCheck(response *SelectorCheckResponse) {
+	switch {
+	case response.Valid && response.MatchCount == 0:
+		cmdutil.ShowSuccess("✓ %s resolves to the whole file", response.Selector)
+	case response.Valid:
+		match := response.Matches[0]
+		cmdutil.ShowSuccess("✓ %s resolves to %q (level %d, line %d)", response.Selector, match.Heading, match.Level, match.Line)
+		if match.Selector != "" && match.Selector != response.Selector {
+			fmt.Printf("  Full path: %s\n", match.Selector)
+		}
+	default:
+		cmdutil.ShowError("✗ %s: %s", response.Selector, response.Reason)
+		if response.MatchCount > 1 {
+			for _, match := range response.Matches {
+				fmt.Printf("  - %q (level %d, line %d)\n", match.Heading, match.Level, match.Line)
+			}
+		}
+		if len(response.Suggestions) > 0 {
+			fmt.Println("\nTry:")
+			for _, suggestion := range response.Suggestions {
+				fmt.Printf("  %s\n", suggestion)
+			}
+		}
+	}
+}
+
+// SelectorCheckResponse represents the JSON response for selector check
+type SelectorCheckResponse struct {
+	Operation   string               `json:"operation"`
+	Selector    string               `json:"selector"`
+	File        string               `json:"file"`
+	FileExists  bool                 `json:"file_exists"`
+	Valid       bool                 `json:"valid"`
+	MatchCount  int                  `json:"match_count"`
+	Matches     []SelectorMatch      `json:"matches"`
+	Reason      string               `json:"reason,omitempty"`
+	Suggestions []string             `json:"suggestions,omitempty"`
+	Metadata    cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type SelectorMatch struct {
+	Heading  string `json:"heading"`
+	Level    int    `json:"level"`
+	Line     int    `json:"line"`
+	Selector string `json:"selector"` // Full-path selector for this match
+}
+
+func init() {
+	selectorCheckCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
+	selectorCmd.AddCommand(selectorCheckCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+// recentSuggestions is how many recently used headings interactive
+// selection offers above a file's headings
+const recentSuggestions = 5
+
+// recordSelectors adds selectors used at a terminal to the selector
+// history. Scripts and the FZF preview, which peeks with its output piped,
+// leave the history alone. The history is a convenience, so failing to
+// write it is reported on stderr but never fails the command.
+func recordSelectors(ws *workspace.Workspace, selectors ...string) {
+	if ws == nil || !isTerminal(os.Stdout) {
+		return
+	}
+	for _, selector := range selectors {
+		if err := ws.RecordSelector(selector); err != nil {
+			cmdutil.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return
+		}
+	}
+}
+
+// recentFilesFirst moves the files of recently used selectors to the front
+// of files, most recent first
+func recentFilesFirst(ws *workspace.Workspace, files []string) []string {
+	history, err := ws.SelectorHistory()
+	if err != nil || len(history) == 0 {
+		return files
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		file, _, _ := strings.Cut(history[i], "#")
+		files = moveToFront(files, file)
+	}
+	return files
+}
+
+// withRecentSubtrees returns subtrees with the ones of file used most
+// recently copied above them, titled by the selector they were used with
+func withRecentSubtrees(ws *workspace.Workspace, file string, subtrees []SubtreeItem) []SubtreeItem {
+	history, err := ws.SelectorHistory()
+	if err != nil || len(history) == 0 {
+		return subtrees
+	}
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		return subtrees
+	}
+	doc := markdown.ParseDocument(content)
+
+	var recent []SubtreeItem
+	seen := make(map[int]bool)
+	for _, selector := range history {
+		if len(recent) == recentSuggestions {
+			break
+		}
+		path, err := markdown.ParsePath(selector)
+		if err != nil || path.File != file || len(path.Segments) == 0 {
+			continue
+		}
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil || seen[subtree.StartOffset] {
+			continue
+		}
+		for _, item := range subtrees {
+			if item.Offset == subtree.StartOffset {
+				seen[item.Offset] = true
+				item.Title = "(recent) " + strings.Join(path.Segments, "/")
+				item.Level = 0
+				recent = append(recent, item)
+				break
+			}
+		}
+	}
+	return append(recent, subtrees...)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestRecentSuggestions(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "inbox.md"),
+		LibDir:    filepath.Join(root, "lib"),
+	}
+	content := "# Projects\n\n## Alpha\n\nFirst.\n\n## Beta\n\nSecond.\n\n# Archive\n\n## Alpha\n\nOld.\n"
+	if err := os.WriteFile(filepath.Join(root, "work.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, selector := range []string{"work.md#projects/beta", "inbox.md#meeting", "work.md#archive/alpha", "work.md#nowhere", "work.md#projects/beta"} {
+		if err := ws.RecordSelector(selector); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := recentFilesFirst(ws, []string{"inbox.md", "lib/a.md", "work.md"})
+	if want := []string{"work.md", "inbox.md", "lib/a.md"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("recentFilesFirst() = %q, want %q", files, want)
+	}
+
+	subtrees, err := extractSubtreesFromFile(ws, "work.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := withRecentSubtrees(ws, "work.md", subtrees)
+	if len(items) != len(subtrees)+2 {
+		t.Fatalf("got %d items, want the %d subtrees and 2 recent ones", len(items), len(subtrees))
+	}
+	if items[0].Title != "(recent) projects/beta" || items[0].Offset != subtrees[2].Offset {
+		t.Errorf("first suggestion = %+v, want Beta", items[0])
+	}
+	if items[1].Title != "(recent) archive/alpha" || items[1].Offset != subtrees[4].Offset {
+		t.Errorf("second suggestion = %+v, want the archived Alpha", items[1])
+	}
+	if items[1].Selector != subtrees[4].Selector {
+		t.Errorf("suggestion selector = %q, want %q", items[1].Selector, subtrees[4].Selector)
+	}
+}
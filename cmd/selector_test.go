@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckSelector(t *testing.T) {
+	ws := refileWorkspace(t, map[string]string{
+		"work.md": "# Work\n\n## Projects\n\n### Frontend\n\n## Meetings\n\n### Frontend\n",
+	})
+
+	tests := []struct {
+		name        string
+		selector    string
+		valid       bool
+		fileExists  bool
+		lines       []int
+		suggestions []string
+	}{
+		{"valid", "work.md#work/projects/frontend", true, true, []int{5}, nil},
+		{"whole file", "work.md", true, true, nil, nil},
+		{"missing heading", "work.md#work/front-end", false, true, nil, nil},
+		{"missing heading with suggestions", "work.md#work/design/frontend", false, true, nil,
+			[]string{"work.md#Work/Projects/Frontend", "work.md#Work/Meetings/Frontend"}},
+		{"ambiguous", "work.md#frontend", false, true, []int{5, 9},
+			[]string{"work.md#Work/Projects/Frontend", "work.md#Work/Meetings/Frontend"}},
+		{"missing file", "missing.md#work", false, false, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := checkSelector(ws, tt.selector, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Valid != tt.valid || response.FileExists != tt.fileExists {
+				t.Errorf("valid %v, file exists %v; want %v, %v (%s)", response.Valid, response.FileExists, tt.valid, tt.fileExists, response.Reason)
+			}
+			var lines []int
+			for _, match := range response.Matches {
+				lines = append(lines, match.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) || response.MatchCount != len(tt.lines) {
+				t.Errorf("matched lines %v (count %d), want %v", lines, response.MatchCount, tt.lines)
+			}
+			if !reflect.DeepEqual(response.Suggestions, tt.suggestions) {
+				t.Errorf("suggestions %v, want %v", response.Suggestions, tt.suggestions)
+			}
+			if !tt.valid && response.Reason == "" {
+				t.Error("an invalid selector gave no reason")
+			}
+		})
+	}
+}
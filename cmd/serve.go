@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/rpc"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// serveDebounce groups the burst of events an editor's save produces
+const serveDebounce = 100 * time.Millisecond
+
+var serveSocket string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve editor requests over a socket and watch the workspace",
+	Long: `Run a daemon that answers editor plugin requests over a Unix socket and
+watches the workspace for changes.
+
+The socket speaks the same protocol as 'jot editor-rpc', and any number of
+clients may connect. When markdown files change, whether through jot or an
+external editor, the heading cache used for selector completion is updated
+and every connected client is sent a "changed" notification with the new
+headings of each file, so plugins can refresh tables of contents live.
+
+The socket is .jot/serve.sock unless --socket is given. The daemon runs
+until interrupted.
+
+Examples:
+  jot serve                          # Serve the current workspace
+  jot serve --socket /tmp/jot.sock   # Listen somewhere else`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		socket := serveSocket
+		if socket == "" {
+			socket = filepath.Join(ws.JotDir, "serve.sock")
+		}
+		listener, err := listenSocket(socket)
+		if err != nil {
+			return ctx.HandleOperationError("listen", err)
+		}
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			listener.Close()
+			return ctx.HandleOperationError("watch", err)
+		}
+		defer watcher.Close()
+
+		s := newServer(ws, !ctx.IsJSONOutput())
+		if s.policy, err = access.Load(ws.JotDir); errors.Is(err, access.ErrNoPolicy) {
+			s.policy = nil
+		} else if err != nil {
+			listener.Close()
+			return ctx.HandleError(err)
+		}
+		s.prime()
+		dirs := s.addWatches(watcher, ws.Root)
+
+		if !ctx.IsJSONOutput() {
+			cmdutil.ShowSuccess("✓ Serving %s on %s", ws.Root, socket)
+			cmdutil.ShowInfo("  %d directories watched, %d files indexed", dirs, len(s.cache.Files))
+		}
+
+		signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-signals.Done()
+			listener.Close()
+		}()
+		go s.watch(watcher)
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				break
+			}
+			go s.serveConn(conn)
+		}
+
+		os.Remove(socket)
+		if signals.Err() == nil {
+			return ctx.HandleOperationError("accept", fmt.Errorf("listener closed"))
+		}
+		return nil
+	},
+}
+
+// listenSocket listens on a Unix socket, replacing a stale socket file left
+// by a daemon that did not shut down cleanly
+func listenSocket(socket string) (net.Listener, error) {
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("jot serve is already running on %s", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	return net.Listen("unix", socket)
+}
+
+// server shares one workspace and heading cache between connections
+type server struct {
+	ws      *workspace.Workspace
+	verbose bool
+	policy  *access.Policy // Access policy clients are held to, if any
+
+	mu    sync.Mutex // Serializes requests and cache updates
+	cache *headingCache
+
+	clientsMu sync.Mutex
+	clients   map[*serveClient]struct{}
+}
+
+type serveClient struct {
+	mu     sync.Mutex
+	writer *bufio.Writer
+	user   string // Set once the client authenticates
+}
+
+// send writes one message and flushes it, so responses and notifications
+// never interleave
+func (c *serveClient) send(write func(io.Writer) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := write(c.writer); err != nil {
+		return err
+	}
+	return c.writer.Flush()
+}
+
+// serveChange describes one file in a "changed" notification
+type serveChange struct {
+	File     string       `json:"file"`
+	Change   string       `json:"change"` // created, modified or removed
+	Headings []TOCHeading `json:"headings"`
+}
+
+type serveChanged struct {
+	Changes []serveChange `json:"changes"`
+}
+
+func newServer(ws *workspace.Workspace, verbose bool) *server {
+	return &server{
+		ws:      ws,
+		verbose: verbose,
+		cache:   loadHeadingCache(ws),
+		clients: make(map[*serveClient]struct{}),
+	}
+}
+
+// prime brings the heading cache up to date with every markdown file,
+// dropping entries for files that no longer exist
+func (s *server) prime() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := scanWorkspaceMarkdownFiles(s.ws)
+	if err != nil {
+		return
+	}
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		file = filepath.ToSlash(file)
+		present[file] = true
+		s.refresh(file)
+	}
+	for file := range s.cache.Files {
+		if !present[file] {
+			delete(s.cache.Files, file)
+			s.cache.dirty = true
+		}
+	}
+	s.cache.save()
+}
+
+// refresh updates one file's cache entry, returning the change it made, or
+// nil when the file is unchanged or was never indexed
+func (s *server) refresh(file string) *serveChange {
+	entry, cached := s.cache.Files[file]
+	info, err := storage.Stat(cmdutil.ResolveWorkspaceRelativePath(s.ws, file))
+	if err != nil {
+		if !cached {
+			return nil
+		}
+		delete(s.cache.Files, file)
+		s.cache.dirty = true
+		return &serveChange{File: file, Change: "removed", Headings: []TOCHeading{}}
+	}
+	if cached && entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() {
+		return nil
+	}
+
+	headings, err := fileTOC(s.ws, file)
+	if err != nil {
+		return nil
+	}
+	s.cache.Files[file] = &headingCacheFile{ModTime: info.ModTime(), Size: info.Size(), Headings: headings}
+	s.cache.dirty = true
+
+	change := "modified"
+	if !cached {
+		change = "created"
+	}
+	return &serveChange{File: file, Change: change, Headings: headings}
+}
+
+// addWatches watches dir and the directories beneath it that scans reach,
+// returning how many were added
+func (s *server) addWatches(watcher *fsnotify.Watcher, dir string) int {
+	count := 0
+	_ = s.ws.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != s.ws.Root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if watcher.Add(path) == nil {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// watch turns file system events into cache updates and notifications,
+// waiting for events to settle before acting on them
+func (s *server) watch(watcher *fsnotify.Watcher) {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(serveDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Files written before the watch was added raise no events of their own
+					s.addWatches(watcher, event.Name)
+					_ = s.ws.Walk(event.Name, func(path string, info os.FileInfo, err error) error {
+						if err == nil && isMarkdownFile(path) {
+							pending[path] = true
+						}
+						return nil
+					})
+				}
+			}
+			if isMarkdownFile(event.Name) {
+				pending[event.Name] = true
+			}
+			timer.Reset(serveDebounce)
+
+		case <-timer.C:
+			s.apply(pending)
+			pending = make(map[string]bool)
+
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// apply refreshes the pending files and tells clients what changed
+func (s *server) apply(pending map[string]bool) {
+	s.mu.Lock()
+	var changes []serveChange
+	for path := range pending {
+		rel, err := filepath.Rel(s.ws.Root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if change := s.refresh(filepath.ToSlash(rel)); change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	s.cache.save()
+	s.mu.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].File < changes[j].File })
+
+	if s.verbose {
+		for _, change := range changes {
+			fmt.Printf("%s %s\n", change.File, change.Change)
+		}
+	}
+	s.broadcast(changes)
+}
+
+// broadcast tells each client about the changes to files it may read
+func (s *server) broadcast(changes []serveChange) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	for client := range s.clients {
+		visible := changes
+		if s.policy != nil {
+			if visible = s.readableChanges(client.user, changes); len(visible) == 0 {
+				continue
+			}
+		}
+		notification := rpc.Notification{Method: "changed", Params: serveChanged{Changes: visible}}
+		_ = client.send(func(w io.Writer) error {
+			return rpc.WriteNotification(w, notification)
+		})
+	}
+}
+
+// serveConn answers one client's requests until it disconnects
+func (s *server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	client := &serveClient{writer: bufio.NewWriter(conn)}
+	s.clientsMu.Lock()
+	s.clients[client] = struct{}{}
+	s.clientsMu.Unlock()
+	defer func() {
+		s.clientsMu.Lock()
+		delete(s.clients, client)
+		s.clientsMu.Unlock()
+	}()
+
+	send := func(response rpc.Response) error {
+		return client.send(func(w io.Writer) error {
+			return rpc.WriteResponse(w, response)
+		})
+	}
+	_ = serveRPC(conn, send, func(request rpc.Request) rpc.Response {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.handle(client, request)
+	})
+}
+
+func isMarkdownFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".md")
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "Unix socket to listen on (default .jot/serve.sock)")
+}
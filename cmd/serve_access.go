@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/rpc"
+)
+
+type rpcAuthenticateResult struct {
+	User string `json:"user"`
+}
+
+// handle answers one request. Requests may only name files inside the
+// workspace. In a workspace with an access policy the client must first
+// authenticate with a token, and each request is held to the token user's
+// levels: reads are checked here, and writes by the guard configureAccess
+// installed, enforced even in warn mode.
+func (s *server) handle(client *serveClient, request rpc.Request) rpc.Response {
+	if request.Method == "authenticate" {
+		return s.authenticate(client, request)
+	}
+	response := rpc.Response{ID: request.ID}
+	if s.policy == nil {
+		if err := s.checkPaths(request); err != nil {
+			response.Error = &rpc.Error{Code: rpc.CodeForbidden, Message: err.Error()}
+			return response
+		}
+		return handleEditorRequest(s.ws, request)
+	}
+
+	if client.user == "" {
+		response.Error = &rpc.Error{Code: rpc.CodeUnauthorized, Message: "authenticate with a token first"}
+		return response
+	}
+	if err := s.authorizeRead(client.user, request); err != nil {
+		response.Error = &rpc.Error{Code: rpc.CodeForbidden, Message: err.Error()}
+		return response
+	}
+
+	defer accessControl.actAs(client.user, true)()
+	response = handleEditorRequest(s.ws, request)
+	if result, ok := response.Result.(rpcCompleteResult); ok {
+		response.Result = s.readableCompletions(client.user, result)
+	}
+	return response
+}
+
+// authenticate identifies the client by its token, reloading the policy
+// first. Without an access policy no token is needed, and any is accepted.
+func (s *server) authenticate(client *serveClient, request rpc.Request) rpc.Response {
+	response := rpc.Response{ID: request.ID}
+	var params struct {
+		Token string `json:"token"`
+	}
+	if err := decodeParams(request.Params, &params); err != nil {
+		response.Error = &rpc.Error{Code: rpc.CodeInvalidParams, Message: err.Error()}
+		return response
+	}
+	if s.policy == nil {
+		response.Result = rpcAuthenticateResult{}
+		return response
+	}
+
+	// Pick up tokens issued, and policy changes made, since the server started
+	if policy, err := access.Load(s.ws.JotDir); err == nil {
+		s.policy = policy
+	}
+	userName, ok := s.policy.Authenticate(params.Token)
+	if !ok {
+		response.Error = &rpc.Error{Code: rpc.CodeUnauthorized, Message: "invalid token"}
+		return response
+	}
+	s.clientsMu.Lock()
+	client.user = userName
+	s.clientsMu.Unlock()
+	response.Result = rpcAuthenticateResult{User: userName}
+	return response
+}
+
+// checkPaths refuses a request naming a file outside the workspace, as an
+// absolute path, one climbing out with "..", or a symlink leading out.
+// Access rules only cover the workspace, so this holds whatever the
+// default level.
+func (s *server) checkPaths(request rpc.Request) error {
+	var params struct {
+		Selector    string `json:"selector"`
+		File        string `json:"file"`
+		Prefix      string `json:"prefix"`
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return nil
+	}
+	for _, selector := range []string{params.Selector, params.File, params.Prefix, params.Source, params.Destination} {
+		file, _, _ := strings.Cut(selector, "#")
+		if file != "" && !s.ws.Contains(cmdutil.ResolveWorkspaceRelativePath(s.ws, file)) {
+			return fmt.Errorf("%s is outside the workspace", file)
+		}
+	}
+	return nil
+}
+
+// authorizeRead checks that the request stays inside the workspace and
+// that the user may read the file it looks at
+func (s *server) authorizeRead(userName string, request rpc.Request) error {
+	if err := s.checkPaths(request); err != nil {
+		return err
+	}
+	var params struct {
+		Selector string `json:"selector"`
+		File     string `json:"file"`
+		Prefix   string `json:"prefix"`
+		Source   string `json:"source"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil || len(request.Params) == 0 {
+		return nil
+	}
+
+	var file string
+	switch request.Method {
+	case "peek":
+		file, _, _ = strings.Cut(params.Selector, "#")
+	case "toc":
+		file = params.File
+	case "refile":
+		file, _, _ = strings.Cut(params.Source, "#")
+	case "selector-complete":
+		prefix, _, hasHash := strings.Cut(params.Prefix, "#")
+		if !hasHash {
+			return nil
+		}
+		file = prefix
+	}
+	if file == "" {
+		return nil
+	}
+	return s.policy.Check(userName, s.relative(file), access.Read)
+}
+
+// readableCompletions drops files the user may not read from completions
+func (s *server) readableCompletions(userName string, result rpcCompleteResult) rpcCompleteResult {
+	completions := []rpcCompletion{}
+	for _, completion := range result.Completions {
+		file, _, _ := strings.Cut(completion.Selector, "#")
+		if s.policy.Level(userName, s.relative(file)) >= access.Read {
+			completions = append(completions, completion)
+		}
+	}
+	result.Completions = completions
+	return result
+}
+
+// readableChanges keeps the changes to files the user may read, and none
+// before the client authenticates
+func (s *server) readableChanges(userName string, changes []serveChange) []serveChange {
+	var readable []serveChange
+	if userName == "" {
+		return nil
+	}
+	for _, change := range changes {
+		if s.policy.Level(userName, change.File) >= access.Read {
+			readable = append(readable, change)
+		}
+	}
+	return readable
+}
+
+// relative returns a workspace file's path relative to the root
+func (s *server) relative(file string) string {
+	return filepath.ToSlash(s.ws.RelativePath(cmdutil.ResolveWorkspaceRelativePath(s.ws, file)))
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/rpc"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestServeRefusesPathsOutsideWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "notes")
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot"), InboxPath: filepath.Join(root, "inbox.md")}
+	files := map[string]string{
+		filepath.Join(root, "inbox.md"):           "# Inbox\n",
+		filepath.Join(root, "secret", "s.md"):     "# Secret\n",
+		filepath.Join(dir, "outside.md"):          "# Outside\n",
+		filepath.Join(ws.JotDir, access.FileName): "rules:\n  - path: secret/\n    access:\n      \"*\": none\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(dir, filepath.Join(root, "up")); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(method string, params map[string]string) rpc.Request {
+		raw, _ := json.Marshal(params)
+		return rpc.Request{ID: json.RawMessage("1"), Method: method, Params: raw}
+	}
+	outside := []rpc.Request{
+		request("peek", map[string]string{"selector": filepath.Join(dir, "outside.md")}),
+		request("peek", map[string]string{"selector": "../outside.md"}),
+		request("peek", map[string]string{"selector": "up/outside.md#outside"}),
+		request("toc", map[string]string{"file": "../outside.md"}),
+		request("refile", map[string]string{"source": "inbox.md#inbox", "destination": "../outside.md#outside"}),
+	}
+
+	policy, err := access.Load(ws.JotDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*server{{ws: ws}, {ws: ws, policy: policy}} {
+		client := &serveClient{user: "dana"}
+		for _, r := range outside {
+			response := s.handle(client, r)
+			if response.Error == nil || response.Error.Code != rpc.CodeForbidden {
+				t.Errorf("policy %v: %s %s = %+v, want forbidden", s.policy != nil, r.Method, r.Params, response)
+			}
+		}
+		if response := s.handle(client, request("peek", map[string]string{"selector": "inbox.md#inbox"})); response.Error != nil {
+			t.Errorf("policy %v: peeking inside the workspace: %+v", s.policy != nil, response.Error)
+		}
+	}
+}
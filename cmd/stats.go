@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/metrics"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show workspace statistics and their history",
+	Long: `Show note and TODO counts for the workspace.
+
+jot records a snapshot of these counts in .jot/metrics/ the first time it
+runs each day, and 'jot stats' refreshes today's snapshot. With --history,
+the snapshots are shown as sparklines, or as a time series with --json.
+
+TODOs are unchecked task items ("- [ ]") and headings starting with TODO;
+done items are checked tasks ("- [x]") and headings starting with DONE.
+
+Set "disable_metrics": true in .jot/config.json to stop recording snapshots.
+
+Examples:
+  jot stats
+  jot stats --history
+  jot stats --history --days 90 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		history, _ := cmd.Flags().GetBool("history")
+		days, _ := cmd.Flags().GetInt("days")
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		now := time.Now()
+		current := takeSnapshot(ws, now)
+		if !ws.Config.DisableMetrics && !dryrun.Enabled() {
+			if err := metrics.Save(metricsDir(ws), current); err != nil {
+				return ctx.HandleOperationError("record metrics", err)
+			}
+		}
+
+		var snapshots []metrics.Snapshot
+		if history {
+			all, err := metrics.Load(metricsDir(ws))
+			if err != nil {
+				return ctx.HandleOperationError("load metrics", err)
+			}
+			snapshots = all
+			if days > 0 {
+				snapshots = metrics.Since(all, now.AddDate(0, 0, -(days-1)))
+			}
+			if len(snapshots) == 0 || snapshots[len(snapshots)-1].Date != current.Date {
+				// Metrics are disabled or today's snapshot was not written
+				snapshots = append(snapshots, current)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(StatsResponse{
+				Operation: "stats",
+				Current:   current,
+				History:   snapshots,
+				Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		if !history {
+			fmt.Printf("Workspace statistics (%s)\n\n", current.Date)
+			for _, series := range metrics.Series {
+				fmt.Printf("  %-12s %d\n", seriesLabel(series), current.Value(series))
+			}
+			return nil
+		}
+
+		fmt.Printf("Workspace history (%s to %s, %d snapshot(s))\n\n", snapshots[0].Date, current.Date, len(snapshots))
+		for _, series := range metrics.Series {
+			values := make([]int, len(snapshots))
+			for i, snapshot := range snapshots {
+				values[i] = snapshot.Value(series)
+			}
+			if cmdutil.Plain() {
+				cmdutil.Printf("  %-12s %d → %d\n", seriesLabel(series), values[0], values[len(values)-1])
+				continue
+			}
+			cmdutil.Printf("  %-12s %s  %d → %d\n", seriesLabel(series), metrics.Sparkline(values), values[0], values[len(values)-1])
+		}
+		return nil
+	},
+}
+
+func seriesLabel(series string) string {
+	return strings.ReplaceAll(series, "_", " ")
+}
+
+func metricsDir(ws *workspace.Workspace) string {
+	return filepath.Join(ws.JotDir, "metrics")
+}
+
+// recordDailySnapshot saves a snapshot if none has been recorded today.
+// Metrics are best effort, so failures are ignored.
+func recordDailySnapshot(ws *workspace.Workspace) {
+	if ws.Config != nil && ws.Config.DisableMetrics {
+		return
+	}
+	now := time.Now()
+	if metrics.Has(metricsDir(ws), now) {
+		return
+	}
+	_ = metrics.Save(metricsDir(ws), takeSnapshot(ws, now))
+}
+
+// takeSnapshot counts notes the same way as 'jot status', plus TODOs across
+// all workspace markdown files
+func takeSnapshot(ws *workspace.Workspace, now time.Time) metrics.Snapshot {
+	inboxNotes := countNotesInFile(ws.InboxPath)
+	libNotes, libFiles := countNotesInDir(ws, ws.LibDir)
+	snapshot := metrics.Snapshot{
+		Date:       now.Format(metrics.DateFormat),
+		InboxNotes: inboxNotes,
+		LibNotes:   libNotes,
+		LibFiles:   libFiles,
+		TotalNotes: inboxNotes + libNotes,
+	}
+
+	files, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return snapshot
+	}
+	for _, file := range files {
+		open, done := countTodosInFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+		snapshot.OpenTodos += open
+		snapshot.DoneTodos += done
+	}
+	return snapshot
+}
+
+var (
+	openTodoPattern = regexp.MustCompile(`^\s*[-*+] \[ \]\s|^#+\s+TODO\b`)
+	doneTodoPattern = regexp.MustCompile(`^\s*[-*+] \[[xX]\]\s|^#+\s+DONE\b`)
+)
+
+// countTodosInFile counts open and done task items and TODO/DONE headings
+func countTodosInFile(path string) (open, done int) {
+	file, err := storage.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	inFence := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		switch {
+		case openTodoPattern.MatchString(line):
+			open++
+		case doneTodoPattern.MatchString(line):
+			done++
+		}
+	}
+	return open, done
+}
+
+// StatsResponse represents the JSON response for the stats command
+type StatsResponse struct {
+	Operation string               `json:"operation"`
+	Current   metrics.Snapshot     `json:"current"`
+	History   []metrics.Snapshot   `json:"history,omitempty"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func init() {
+	statsCmd.Flags().Bool("history", false, "Show recorded snapshots as sparklines or a time series")
+	statsCmd.Flags().Int("days", 30, "Days of history to show (0 for all)")
+}
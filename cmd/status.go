@@ -4,11 +4,11 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -44,7 +44,7 @@ Examples:
 		}
 
 		inboxNotes := countNotesInFile(ws.InboxPath)
-		libNotes, libFiles := countNotesInDir(ws.LibDir)
+		libNotes, libFiles := countNotesInDir(ws, ws.LibDir)
 		totalNotes := inboxNotes + libNotes
 
 		healthStatus := "healthy"
@@ -55,13 +55,18 @@ Examples:
 		var lastActivity *time.Time
 		var lastActivityText string
 		if ws.InboxExists() {
-			if info, err := os.Stat(ws.InboxPath); err == nil {
+			if info, err := storage.Stat(ws.InboxPath); err == nil {
 				modTime := info.ModTime()
 				lastActivity = &modTime
 				lastActivityText = formatRelativeTime(modTime)
 			}
 		}
 
+		aging, err := buildInboxAgingReport(ws, time.Now())
+		if err != nil {
+			return ctx.HandleOperationError("compute inbox aging", err)
+		}
+
 		// Output JSON if requested
 		if cmdutil.IsJSONOutput(ctx.Cmd) {
 			response := StatusResponse{
@@ -81,7 +86,8 @@ Examples:
 					Status: healthStatus,
 					Issues: issues,
 				},
-				Metadata: cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+				InboxAging: aging,
+				Metadata:   cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
 			}
 
 			if lastActivity != nil {
@@ -118,11 +124,23 @@ Examples:
 			fmt.Printf("Last inbox activity: %s\n", lastActivityText)
 		}
 
+		if len(aging.Warnings) > 0 {
+			fmt.Println()
+			cmdutil.Println("Inbox Triage: ⚠ Needs attention")
+			for _, warning := range aging.Warnings {
+				fmt.Printf("  - %s\n", warning)
+			}
+			for _, item := range aging.StaleItems {
+				fmt.Printf("      %s (%d days)\n", item.Heading, item.AgeDays)
+			}
+			fmt.Println("\nRun 'jot refile' or 'jot refile --auto' to triage")
+		}
+
 		fmt.Println()
 		if len(issues) == 0 {
-			fmt.Println("Workspace Health: ✓ Good")
+			cmdutil.Println("Workspace Health: ✓ Good")
 		} else {
-			fmt.Println("Workspace Health: ⚠ Issues found")
+			cmdutil.Println("Workspace Health: ⚠ Issues found")
 			for _, issue := range issues {
 				fmt.Printf("  - %s\n", issue)
 			}
@@ -135,11 +153,12 @@ Examples:
 
 // StatusResponse represents the JSON response for status command
 type StatusResponse struct {
-	Workspace StatusWorkspace      `json:"workspace"`
-	Files     StatusFiles          `json:"files"`
-	Health    StatusHealth         `json:"health"`
-	Activity  StatusActivity       `json:"activity,omitempty"`
-	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+	Workspace  StatusWorkspace      `json:"workspace"`
+	Files      StatusFiles          `json:"files"`
+	Health     StatusHealth         `json:"health"`
+	Activity   StatusActivity       `json:"activity,omitempty"`
+	InboxAging *InboxAgingReport    `json:"inbox_aging,omitempty"`
+	Metadata   cmdutil.JSONMetadata `json:"metadata"`
 }
 
 type StatusWorkspace struct {
@@ -168,7 +187,7 @@ type StatusActivity struct {
 
 // countNotesInFile counts ## headers in a markdown file
 func countNotesInFile(path string) int {
-	file, err := os.Open(path)
+	file, err := storage.Open(path)
 	if err != nil {
 		return 0
 	}
@@ -186,11 +205,11 @@ func countNotesInFile(path string) int {
 }
 
 // countNotesInDir counts notes in all markdown files in a directory
-func countNotesInDir(dir string) (int, int) {
+func countNotesInDir(ws *workspace.Workspace, dir string) (int, int) {
 	totalNotes := 0
 	fileCount := 0
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := ws.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip files we can't read
 		}
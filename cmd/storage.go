@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// defaultSQLiteDatabase is where the sqlite backend keeps notes, relative
+// to the workspace root
+const defaultSQLiteDatabase = ".jot/notes.db"
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Show or migrate the workspace's storage backend",
+	Long: `Show where the workspace's notes are stored, and copy them between the
+filesystem and the configured backend.
+
+Notes are plain files by default. Set "storage" in .jot/config.json to keep
+them in another backend; selectors address them by path as before:
+
+  "storage": { "backend": "sqlite", "path": ".jot/notes.db" }
+
+The sqlite backend is experimental. It keeps every note in one database
+file and needs the sqlite3 command on PATH. Configuration, hooks and other
+files under .jot stay on the filesystem.
+
+Examples:
+  jot storage                 # Show the backend in use
+  jot storage import          # Copy the workspace's markdown files into it
+  jot storage export          # Write its notes back out as files`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		notes, err := storageNotes(ws, storage.Current())
+		if err != nil {
+			return ctx.HandleOperationError("list notes", err)
+		}
+
+		response := StorageResponse{
+			Operation: "status",
+			Backend:   storage.Current().Name(),
+			Files:     notes,
+			FileCount: len(notes),
+		}
+		switch backend := storage.Current().(type) {
+		case *storage.SQLite:
+			response.Database = backend.Database
+		case *storage.SSH:
+			response.Remote = ws.Location
+			response.Cache = backend.Root
+			if err := backend.Offline(); err != nil {
+				response.Offline = err.Error()
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		}
+
+		fmt.Printf("Backend:  %s\n", response.Backend)
+		if response.Database != "" {
+			fmt.Printf("Database: %s\n", response.Database)
+		}
+		if response.Remote != "" {
+			fmt.Printf("Remote:   %s\n", response.Remote)
+			fmt.Printf("Cache:    %s\n", response.Cache)
+		}
+		if response.Offline != "" {
+			fmt.Printf("Offline:  %s\n", response.Offline)
+		}
+		fmt.Printf("Notes:    %d\n", response.FileCount)
+		return nil
+	},
+}
+
+var storageImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Copy the workspace's markdown files into the storage backend",
+	Long: `Copy every markdown file in the workspace directory into the configured
+storage backend, replacing notes of the same path. The files themselves are
+left in place.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if storage.IsFilesystem() {
+			return ctx.HandleError(fmt.Errorf("the workspace stores notes as files; configure a storage backend in .jot/config.json first"))
+		}
+		if ws.Location != "" {
+			return ctx.HandleError(fmt.Errorf("%s is a remote workspace; run 'jot storage import' on the host", ws.Location))
+		}
+
+		files, err := storageNotes(ws, storage.Filesystem{})
+		if err != nil {
+			return ctx.HandleOperationError("list files", err)
+		}
+		for _, file := range files {
+			path := filepath.Join(ws.Root, filepath.FromSlash(file))
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return ctx.HandleFileOperation("read", file, err)
+			}
+			if err := dryrun.WriteFile(path, content, 0644); err != nil {
+				return ctx.HandleFileOperation("import", file, err)
+			}
+		}
+
+		return storageCopyResult(ctx, "import", files)
+	},
+}
+
+var storageExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the storage backend's notes out as files",
+	Long: `Write every note in the configured storage backend to a file of the same
+path in the workspace directory, replacing existing files. Switch the
+workspace back to the filesystem afterwards by removing "storage" from
+.jot/config.json.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if storage.IsFilesystem() {
+			return ctx.HandleError(fmt.Errorf("the workspace already stores notes as files"))
+		}
+		if ws.Location != "" {
+			return ctx.HandleError(fmt.Errorf("%s is a remote workspace; run 'jot storage export' on the host", ws.Location))
+		}
+
+		notes, err := storageNotes(ws, storage.Current())
+		if err != nil {
+			return ctx.HandleOperationError("list notes", err)
+		}
+		if !dryrun.Enabled() {
+			files := storage.Filesystem{}
+			for _, note := range notes {
+				path := filepath.Join(ws.Root, filepath.FromSlash(note))
+				content, err := storage.ReadFile(path)
+				if err != nil {
+					return ctx.HandleFileOperation("read", note, err)
+				}
+				if err := files.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return ctx.HandleFileOperation("export", note, err)
+				}
+				if err := files.WriteFile(path, content, 0644); err != nil {
+					return ctx.HandleFileOperation("export", note, err)
+				}
+			}
+		}
+
+		return storageCopyResult(ctx, "export", notes)
+	},
+}
+
+// configureStorage switches to the storage backend the workspace configures
+func configureStorage(cmd *cobra.Command) error {
+	storage.Use(storage.Filesystem{})
+	ws, err := getWorkspace(cmd)
+	if err != nil {
+		return nil
+	}
+	if ws.Backend != nil {
+		storage.Use(ws.Backend)
+		return nil
+	}
+	if ws.Config == nil || ws.Config.Storage == nil {
+		return nil
+	}
+
+	backend, err := openStorage(ws, ws.Config.Storage)
+	if err != nil {
+		return err
+	}
+	storage.Use(backend)
+	return nil
+}
+
+// openStorage opens a configured storage backend
+func openStorage(ws *workspace.Workspace, config *workspace.StorageConfig) (storage.Backend, error) {
+	switch config.Backend {
+	case "", "filesystem":
+		return storage.Filesystem{}, nil
+	case "sqlite":
+		database := config.Path
+		if database == "" {
+			database = defaultSQLiteDatabase
+		}
+		if !filepath.IsAbs(database) {
+			database = filepath.Join(ws.Root, filepath.FromSlash(database))
+		}
+		return storage.NewSQLite(database, ws.Root)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q (expected filesystem or sqlite)", config.Backend)
+}
+
+// storageNotes lists the markdown notes a backend holds for the workspace,
+// relative to its root, leaving out hidden directories such as .jot
+func storageNotes(ws *workspace.Workspace, backend storage.Backend) ([]string, error) {
+	var notes []string
+	err := backend.Walk(ws.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != ws.Root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(info.Name(), ".md") {
+			if rel, err := filepath.Rel(ws.Root, path); err == nil {
+				notes = append(notes, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return notes, err
+}
+
+// storageCopyResult reports the notes copied by import or export
+func storageCopyResult(ctx *cmdutil.CommandContext, operation string, files []string) error {
+	if ctx.IsJSONOutput() {
+		return cmdutil.OutputJSON(StorageResponse{
+			Operation: operation,
+			Backend:   storage.Current().Name(),
+			Files:     files,
+			FileCount: len(files),
+			Metadata:  cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+		})
+	}
+
+	verb := "Imported"
+	if operation == "export" {
+		verb = "Exported"
+	}
+	cmdutil.ShowSuccess("✓ %s %d note(s)", verb, len(files))
+	return nil
+}
+
+// StorageResponse represents the JSON response for storage commands
+type StorageResponse struct {
+	Operation string               `json:"operation"`
+	Backend   string               `json:"backend"`
+	Database  string               `json:"database,omitempty"`
+	Remote    string               `json:"remote,omitempty"`
+	Cache     string               `json:"cache,omitempty"`
+	Offline   string               `json:"offline,omitempty"`
+	Files     []string             `json:"files"`
+	FileCount int                  `json:"file_count"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+func init() {
+	storageCmd.AddCommand(storageImportCmd)
+	storageCmd.AddCommand(storageExportCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestFindAndStatusReadThroughStorage(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	root := t.TempDir()
+	ws := &workspace.Workspace{
+		Root:      root,
+		JotDir:    filepath.Join(root, ".jot"),
+		InboxPath: filepath.Join(root, "inbox.md"),
+		LibDir:    filepath.Join(root, "lib"),
+	}
+	backend, err := storage.NewSQLite(filepath.Join(ws.JotDir, "notes.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.Use(backend)
+	defer storage.Use(storage.Filesystem{})
+
+	if err := backend.WriteFile(ws.InboxPath, []byte("# Inbox\n\n## First\nfindme\n\n## Second\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.WriteFile(filepath.Join(ws.LibDir, "work.md"), []byte("# Work\n\nalso findme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(limit int) { findLimit = limit }(findLimit)
+	findLimit = 10
+	if results := collectSearchResults(ws, "findme"); len(results) != 2 {
+		t.Errorf("find found %d matches, want 2: %+v", len(results), results)
+	}
+	if notes := countNotesInFile(ws.InboxPath); notes != 2 {
+		t.Errorf("status counted %d inbox notes, want 2", notes)
+	}
+	if notes, files := countNotesInDir(ws, ws.LibDir); files != 1 {
+		t.Errorf("status counted %d notes in %d lib files, want 1 file", notes, files)
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/netutil"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/suggest"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Ask an external assistant for suggestions",
+	Long: `Ask a user-configured external command or HTTP endpoint for suggestions.
+
+jot does not call any model itself. It sends a JSON request to the configured
+backend and validates the response, so any local script, model or service can
+be plugged in.
+
+Configure a backend in .jot/config.json:
+  "suggest_command": "my-ranker"                  # JSON on stdin, JSON on stdout
+  "suggest_endpoint": "http://localhost:8080/rank" # JSON POST body and response
+
+Set JOT_SUGGEST_TOKEN to send a bearer token to the endpoint.`,
+}
+
+var suggestRefileCmd = &cobra.Command{
+	Use:   "refile SELECTOR",
+	Short: "Rank refile destinations for a subtree",
+	Long: `Send a subtree and every candidate destination heading in the workspace to
+the suggestion backend and show the ranked destinations it returns.
+
+Request (stdin or POST body):
+  {"task": "refile",
+   "source": {"selector": "...", "heading": "...", "content": "..."},
+   "candidates": [{"selector": "work.md#Projects", "file": "work.md",
+                   "heading": "Projects", "path": "Projects", "level": 1}],
+   "limit": 5}
+
+Response:
+  {"suggestions": [{"selector": "work.md#Projects", "score": 0.9,
+                    "reason": "mentions the project"}]}
+
+Suggestions for selectors that are not in the candidate list are ignored.
+Use --apply to refile to the top suggestion, or --pick N to choose another.
+
+Examples:
+  jot suggest refile "inbox.md#standup notes"
+  jot suggest refile "inbox.md#standup notes" --apply
+  jot suggest refile "inbox.md#standup notes" --pick 2
+  jot suggest refile "inbox.md#idea" --command "python3 rank.py"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		command, _ := cmd.Flags().GetString("command")
+		endpoint, _ := cmd.Flags().GetString("endpoint")
+		limit, _ := cmd.Flags().GetInt("limit")
+		apply, _ := cmd.Flags().GetBool("apply")
+		pick, _ := cmd.Flags().GetInt("pick")
+		refileNoVerify, _ = cmd.Flags().GetBool("no-verify")
+
+		if command == "" && endpoint == "" && ws.Config != nil {
+			command = ws.Config.SuggestCommand
+			endpoint = ws.Config.SuggestEndpoint
+			if strings.TrimSpace(command) != "" {
+				if err := safemode.Check("the suggestion command from .jot/config.json"); err != nil {
+					return ctx.HandleError(err)
+				}
+			}
+		}
+
+		backend, err := suggest.NewBackend(command, endpoint)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if strings.TrimSpace(command) == "" {
+			if err := netutil.CheckOnline("the suggestion endpoint"); err != nil {
+				return ctx.HandleError(err)
+			}
+		}
+
+		if limit < 1 {
+			return ctx.HandleValidation("limit", fmt.Sprint(limit), fmt.Errorf("limit must be at least 1"))
+		}
+		if pick < 0 {
+			return ctx.HandleValidation("pick", fmt.Sprint(pick), fmt.Errorf("pick must be at least 1"))
+		}
+		if pick > 0 {
+			apply = true
+		} else if apply {
+			pick = 1
+		}
+
+		sourceSelector := args[0]
+		sourcePath, err := markdown.ParsePath(sourceSelector)
+		if err != nil {
+			return ctx.HandleValidation("selector", sourceSelector, err)
+		}
+
+		subtree, err := ExtractSubtree(ws, sourcePath)
+		if err != nil {
+			return ctx.HandleError(fmt.Errorf("failed to extract subtree: %w", err))
+		}
+
+		candidates, err := collectRefileCandidates(ws, sourcePath.File, subtree)
+		if err != nil {
+			return ctx.HandleOperationError("collect candidates", err)
+		}
+		if len(candidates) == 0 {
+			return ctx.HandleError(fmt.Errorf("no candidate destinations found in workspace"))
+		}
+
+		resp, err := backend.Suggest(&suggest.Request{
+			Task: suggest.TaskRefile,
+			Source: suggest.Source{
+				Selector: sourceSelector,
+				Heading:  subtree.Heading,
+				Content:  string(subtree.Content),
+			},
+			Candidates: candidates,
+			Limit:      limit,
+		})
+		if err != nil {
+			return ctx.HandleError(cmdutil.NewExternalError(backend.Name(), nil, err))
+		}
+
+		suggestions, unknown := suggest.Rank(resp, candidates, limit)
+
+		var applied string
+		if apply {
+			if pick > len(suggestions) {
+				return ctx.HandleValidation("pick", fmt.Sprint(pick), fmt.Errorf("only %d suggestion(s) available", len(suggestions)))
+			}
+			applied = suggestions[pick-1].Selector
+			if err := executeRefile(sourceSelector, applied, ctx, ws); err != nil {
+				return ctx.HandleOperationError("refile", err)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(SuggestRefileResponse{
+				Operation:        "suggest_refile",
+				Source:           sourceSelector,
+				Backend:          backend.Name(),
+				CandidateCount:   len(candidates),
+				Suggestions:      suggestions,
+				IgnoredSelectors: unknown,
+				Applied:          applied,
+				Metadata:         cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime),
+			})
+		}
+
+		for _, selector := range unknown {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring suggestion for unknown destination %q\n", selector)
+		}
+
+		if apply {
+			return nil
+		}
+
+		if len(suggestions) == 0 {
+			cmdutil.ShowWarning("No suggestions returned for '%s'", sourceSelector)
+			return nil
+		}
+
+		fmt.Printf("Suggested destinations for '%s':\n", subtree.Heading)
+		for i, suggestion := range suggestions {
+			fmt.Printf("  %d. %s (%.2f)\n", i+1, suggestion.Selector, suggestion.Score)
+			if suggestion.Reason != "" {
+				fmt.Printf("     %s\n", suggestion.Reason)
+			}
+		}
+		fmt.Printf("\nApply with: jot suggest refile %q --pick N\n", sourceSelector)
+		return nil
+	},
+}
+
+// SuggestRefileResponse represents the JSON response for suggest refile
+type SuggestRefileResponse struct {
+	Operation        string               `json:"operation"`
+	Source           string               `json:"source"`
+	Backend          string               `json:"backend"`
+	CandidateCount   int                  `json:"candidate_count"`
+	Suggestions      []suggest.Suggestion `json:"suggestions"`
+	IgnoredSelectors []string             `json:"ignored_selectors,omitempty"`
+	Applied          string               `json:"applied,omitempty"`
+	Metadata         cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// collectRefileCandidates lists every heading in the workspace as a refile
+// destination, excluding the source subtree and its descendants
+func collectRefileCandidates(ws *workspace.Workspace, sourceFile string, source *markdown.Subtree) ([]suggest.Candidate, error) {
+	sections, err := collectWorkspaceSections(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceFilePath := cmdutil.ResolveWorkspaceRelativePath(ws, sourceFile)
+
+	var candidates []suggest.Candidate
+	for _, section := range sections {
+		if section.Within(sourceFilePath, source) {
+			continue
+		}
+		candidates = append(candidates, suggest.Candidate{
+			Selector: section.Selector(),
+			File:     section.File,
+			Heading:  section.Heading,
+			Path:     section.Path,
+			Level:    section.Level,
+		})
+	}
+
+	return candidates, nil
+}
+
+func init() {
+	suggestRefileCmd.Flags().String("command", "", "Suggestion command to run (overrides suggest_command)")
+	suggestRefileCmd.Flags().String("endpoint", "", "Suggestion HTTP endpoint (overrides suggest_endpoint)")
+	suggestRefileCmd.Flags().Int("limit", 5, "Maximum number of suggestions to show")
+	suggestRefileCmd.Flags().Bool("apply", false, "Refile to the top suggestion")
+	suggestRefileCmd.Flags().Int("pick", 0, "Refile to the Nth suggestion")
+	suggestRefileCmd.Flags().Bool("no-verify", false, "Skip refile hooks when applying")
+	suggestRefileCmd.Flags().BoolVar(&forceProtected, "force", false, "Modify protected files and subtrees when applying")
+	suggestCmd.AddCommand(suggestRefileCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/johncoder/jot/internal/tangle"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
@@ -37,7 +38,7 @@ Examples:
 		// Resolve file path relative to workspace or current directory
 		resolvedFilename := cmdutil.ResolvePath(ws, filename, noWorkspace)
 
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		dryRun := dryrun.Enabled()
 		verbose, _ := cmd.Flags().GetBool("verbose")
 
 		if !cmdutil.IsJSONOutput(ctx.Cmd) {
@@ -53,7 +54,6 @@ Examples:
 }
 
 func init() {
-	tangleCmd.Flags().Bool("dry-run", false, "Show what would be tangled without actually writing files")
 	tangleCmd.Flags().BoolP("verbose", "v", false, "Show detailed information about the tangle operation")
 	tangleCmd.Flags().Bool("no-workspace", false, "Resolve file paths relative to current directory instead of workspace")
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/johncoder/jot/internal/editor"
 	"github.com/johncoder/jot/internal/template"
 	"github.com/johncoder/jot/internal/workspace"
@@ -90,7 +91,7 @@ var templateListCmd = &cobra.Command{
 			if t.Approved {
 				status = "✓ approved"
 			}
-			fmt.Printf("  %s (%s)\n", t.Name, status)
+			cmdutil.Printf("  %s (%s)\n", t.Name, status)
 		}
 
 		return nil
@@ -132,9 +133,15 @@ refile_mode: append
 
 `, strings.ToTitle(name))
 
-		// Create template
+		// Create template. The editor may stay open a while, so the
+		// workspace lock is held only around each write.
 		pathUtil := cmdutil.NewPathUtil(ws)
+		unlock, err := ws.Lock(planCommandLine(os.Args))
+		if err != nil {
+			return ctx.HandleError(err)
+		}
 		err = tm.Create(name, defaultContent)
+		unlock()
 		if err != nil {
 			err := fmt.Errorf("failed to create template: %w", err)
 			if ctx.IsJSONOutput() {
@@ -170,7 +177,12 @@ refile_mode: append
 				fmt.Printf("Edit manually: %s\n", templatePath)
 			} else {
 				// Write back the edited content using unified content utilities
+				unlock, err := ws.Lock(planCommandLine(os.Args))
+				if err != nil {
+					return err
+				}
 				err = cmdutil.WriteFileContent(templatePath, []byte(editedContent))
+				unlock()
 				if err != nil {
 					if ctx.IsJSONOutput() {
 						return ctx.HandleError(err)
@@ -247,7 +259,12 @@ var templateEditCmd = &cobra.Command{
 			if err != nil {
 				return fmt.Errorf("failed to read stdin: %w", err)
 			}
+			unlock, err := ws.Lock(planCommandLine(os.Args))
+			if err != nil {
+				return err
+			}
 			err = cmdutil.WriteFileContent(templatePath, stdinContent)
+			unlock()
 			if err != nil {
 				return fmt.Errorf("failed to save template: %w", err)
 			}
@@ -289,8 +306,14 @@ var templateEditCmd = &cobra.Command{
 			return fmt.Errorf("failed to open editor: %w", err)
 		}
 
-		// Write back the edited content using unified content utilities
+		// Write back the edited content, under the workspace lock now the
+		// editor has closed
+		unlock, err := ws.Lock(planCommandLine(os.Args))
+		if err != nil {
+			return err
+		}
 		err = cmdutil.WriteFileContent(templatePath, []byte(editedContent))
+		unlock()
 		if err != nil {
 			return fmt.Errorf("failed to save template: %w", err)
 		}
@@ -504,12 +527,12 @@ var templateRemoveCmd = &cobra.Command{
 		// Check if file exists
 		if _, err := cmdutil.ReadFileContent(templatePath); err != nil {
 			if ctx.IsJSONOutput() {
-				return ctx.HandleError(fmt.Errorf("template '%s' not found", name))
+				return ctx.HandleError(fmt.Errorf("template '%s' %w", name, cmdutil.ErrNotFound))
 			}
-			return fmt.Errorf("template '%s' not found", name)
+			return fmt.Errorf("template '%s' %w", name, cmdutil.ErrNotFound)
 		}
 
-		err = os.Remove(templatePath)
+		err = dryrun.Remove(templatePath)
 		if err != nil {
 			if ctx.IsJSONOutput() {
 				return ctx.HandleError(fmt.Errorf("failed to remove template: %w", err))
@@ -0,0 +1,400 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/rules"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var tocCmd = &cobra.Command{
+	Use:   "toc [FILE...]",
+	Short: "Show a table of contents for the workspace",
+	Long: `Show the headings of every markdown file in the workspace as one tree,
+a sitemap of the knowledge base. Give file names to limit the tree to those
+files.
+
+With --annotate, each heading shows the state of its subtree: its TODO or
+DONE keyword, checklist completion as [done/total] over every task item in
+the subtree, #hashtags in its own text, and how many headings it contains.
+On a terminal, states and checklists are coloured: open work in yellow,
+finished work in green.
+
+Formats:
+  text       Indented tree (default)
+  markdown   Nested list of links, ready to paste into an index page
+  json       Files and headings with selectors (same as --json)
+
+Examples:
+  jot toc
+  jot toc --depth 2
+  jot toc work.md --annotate        # TODO states, checklists, tags, children
+  jot toc lib/ --format markdown > lib/index.md
+  jot toc work.md inbox.md --json
+  jot toc work.md --slugs           # Selectors as GitHub-style anchors`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		depth, _ := cmd.Flags().GetInt("depth")
+		slugs, _ := cmd.Flags().GetBool("slugs")
+		annotate, _ := cmd.Flags().GetBool("annotate")
+		format, _ := cmd.Flags().GetString("format")
+		if ctx.IsJSONOutput() {
+			format = "json"
+		}
+		if format != "text" && format != "markdown" && format != "json" {
+			return ctx.HandleValidation("format", format, fmt.Errorf("must be text, markdown or json"))
+		}
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		files, err := tocFiles(ws, args)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		response := TOCResponse{Operation: "toc", Files: make([]TOCFile, 0, len(files))}
+		for _, file := range files {
+			headings, err := fileTOC(ws, file)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			if slugs {
+				slugSelectors(file, headings)
+			}
+			if annotate {
+				if err := annotateTOC(ws, file, headings); err != nil {
+					return ctx.HandleError(err)
+				}
+			}
+			if depth > 0 {
+				headings = limitTOCDepth(headings, depth)
+			}
+			response.Files = append(response.Files, TOCFile{File: file, Headings: headings})
+			response.HeadingCount += len(headings)
+		}
+		response.FileCount = len(response.Files)
+
+		switch format {
+		case "json":
+			response.Metadata = cmdutil.CreateJSONMetadata(ctx.Cmd, true, ctx.StartTime)
+			return cmdutil.OutputJSON(response)
+		case "markdown":
+			fmt.Print(renderTOCMarkdown(response.Files))
+		default:
+			color := useColor(os.Stdout)
+			for _, file := range response.Files {
+				fmt.Println(file.File)
+				depths := tocDepths(file.Headings)
+				for i, heading := range file.Headings {
+					if annotate {
+						fmt.Printf("%s%s\n", strings.Repeat("  ", depths[i]), annotatedHeading(heading, color))
+						continue
+					}
+					if slugs {
+						fmt.Printf("%s%s  %s\n", strings.Repeat("  ", depths[i]), heading.Heading, heading.Selector)
+						continue
+					}
+					fmt.Printf("%s%s\n", strings.Repeat("  ", depths[i]), heading.Heading)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// tocFiles returns the files to include: every workspace markdown file, or
+// those under the given files and directories
+func tocFiles(ws *workspace.Workspace, args []string) ([]string, error) {
+	all, err := scanWorkspaceMarkdownFiles(ws)
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		all[i] = filepath.ToSlash(all[i])
+	}
+	if len(args) == 0 {
+		return all, nil
+	}
+
+	var files []string
+	for _, arg := range args {
+		arg = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(arg)), "/")
+		found := false
+		for _, file := range all {
+			if file == arg || strings.HasPrefix(file, arg+"/") {
+				files = append(files, file)
+				found = true
+			}
+		}
+		if !found {
+			return nil, cmdutil.NewFileError("read", arg, os.ErrNotExist)
+		}
+	}
+	return files, nil
+}
+
+// fileTOC lists the headings of a file with full-path selectors
+func fileTOC(ws *workspace.Workspace, file string) ([]TOCHeading, error) {
+	filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", cmdutil.ErrFileNotFound, file)
+		}
+		return nil, err
+	}
+
+	sections := fileSections(file, filePath, maskFrontmatter(content))
+	headings := make([]TOCHeading, 0, len(sections))
+	lines := newLineIndex(content)
+	for _, section := range sections {
+		headings = append(headings, TOCHeading{
+			Heading:  section.Heading,
+			Level:    section.Level,
+			Line:     lines.line(section.Offset),
+			Selector: section.Selector(),
+		})
+	}
+	return headings, nil
+}
+
+// slugSelectors replaces each heading's selector with its GitHub-style slug
+// form, file.md#heading-slug. It must see every heading of the file so
+// repeated headings get the same suffixes GitHub gives them.
+func slugSelectors(file string, headings []TOCHeading) {
+	var slugger markdown.Slugger
+	for i := range headings {
+		headings[i].Selector = file + "#" + slugger.Next(headings[i].Heading)
+	}
+}
+
+// limitTOCDepth drops headings deeper than depth levels
+func limitTOCDepth(headings []TOCHeading, depth int) []TOCHeading {
+	limited := make([]TOCHeading, 0, len(headings))
+	for _, heading := range headings {
+		if heading.Level <= depth {
+			limited = append(limited, heading)
+		}
+	}
+	return limited
+}
+
+// tocDepths returns each heading's depth in the file's tree, starting at 1,
+// so that skipped heading levels do not add indentation
+func tocDepths(headings []TOCHeading) []int {
+	depths := make([]int, len(headings))
+	var levels []int
+	for i, heading := range headings {
+		for len(levels) > 0 && levels[len(levels)-1] >= heading.Level {
+			levels = levels[:len(levels)-1]
+		}
+		levels = append(levels, heading.Level)
+		depths[i] = len(levels)
+	}
+	return depths
+}
+
+// renderTOCMarkdown renders files and headings as a nested list of links
+func renderTOCMarkdown(files []TOCFile) string {
+	var b strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&b, "- [%s](%s)\n", file.File, file.File)
+
+		var slugger markdown.Slugger
+		depths := tocDepths(file.Headings)
+		for i, heading := range file.Headings {
+			anchor := slugger.Next(heading.Heading)
+			fmt.Fprintf(&b, "%s- [%s](%s#%s)\n", strings.Repeat("  ", depths[i]), heading.Heading, file.File, anchor)
+		}
+	}
+	return b.String()
+}
+
+// TOCResponse represents the JSON response for the toc command
+type TOCResponse struct {
+	Operation    string               `json:"operation"`
+	Files        []TOCFile            `json:"files"`
+	FileCount    int                  `json:"file_count"`
+	HeadingCount int                  `json:"heading_count"`
+	Metadata     cmdutil.JSONMetadata `json:"metadata"`
+}
+
+type TOCFile struct {
+	File     string       `json:"file"`
+	Headings []TOCHeading `json:"headings"`
+}
+
+type TOCHeading struct {
+	Heading  string     `json:"heading"`
+	Level    int        `json:"level"`
+	Line     int        `json:"line"`
+	Selector string     `json:"selector"`
+	Status   *TOCStatus `json:"status,omitempty"` // With --annotate
+}
+
+// TOCStatus summarizes a heading's subtree for --annotate
+type TOCStatus struct {
+	State    string   `json:"state,omitempty"` // TODO or DONE keyword starting the heading
+	Tags     []string `json:"tags,omitempty"`  // Heading tags, then #hashtags in its own text
+	Checked  int      `json:"checked"`         // Checked task items in the subtree
+	Tasks    int      `json:"tasks"`           // Task items in the subtree
+	Children int      `json:"children"`        // Direct child headings
+}
+
+var (
+	// todoKeywordPattern matches a TODO or DONE keyword starting a heading
+	todoKeywordPattern = regexp.MustCompile(`^(TODO|DONE)\b`)
+	// taskItemPattern matches a task list item, capturing its check mark
+	taskItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s`)
+)
+
+// ANSI colours for annotated headings
+const (
+	ansiReset  = "\x1b[0m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiDim    = "\x1b[2m"
+)
+
+// annotateTOC sets the status of each of a file's headings, which must be
+// every heading fileTOC returned for it
+func annotateTOC(ws *workspace.Workspace, file string, headings []TOCHeading) error {
+	filePath := cmdutil.ResolveWorkspaceRelativePath(ws, file)
+	content, err := storage.ReadFile(filePath)
+	if err != nil {
+		return cmdutil.NewFileError("read", file, err)
+	}
+	sections := fileSections(file, filePath, maskFrontmatter(content))
+	if len(sections) != len(headings) {
+		return fmt.Errorf("%s changed while reading it", file)
+	}
+
+	statuses := make([]TOCStatus, len(sections))
+	for i, section := range sections {
+		status := &statuses[i]
+		if match := todoKeywordPattern.FindString(section.Heading); match != "" {
+			status.State = match
+		}
+		status.Tags = append(status.Tags, markdown.HeadingTags(section.Heading)...)
+		status.Tags = append(status.Tags, rules.ExtractTags(section.Content)...)
+		status.Checked, status.Tasks = countTasks(section.Content)
+	}
+
+	// Fold each heading's tasks into its ancestors, and count children. A
+	// heading's own counts are added before its descendants add theirs, so
+	// each task counts once for every heading above it.
+	var open []int
+	for i, section := range sections {
+		for len(open) > 0 && sections[open[len(open)-1]].Level >= section.Level {
+			open = open[:len(open)-1]
+		}
+		if len(open) > 0 {
+			statuses[open[len(open)-1]].Children++
+		}
+		for _, ancestor := range open {
+			statuses[ancestor].Checked += statuses[i].Checked
+			statuses[ancestor].Tasks += statuses[i].Tasks
+		}
+		open = append(open, i)
+	}
+
+	for i := range headings {
+		headings[i].Status = &statuses[i]
+	}
+	return nil
+}
+
+// countTasks counts the checked and all task items in text, outside code
+// blocks
+func countTasks(text string) (checked, total int) {
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := taskItemPattern.FindStringSubmatch(line); match != nil {
+			total++
+			if match[1] != " " {
+				checked++
+			}
+		}
+	}
+	return checked, total
+}
+
+// annotatedHeading returns a heading with its status for the text tree,
+// coloured when color is set
+func annotatedHeading(heading TOCHeading, color bool) string {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	status := heading.Status
+	text := heading.Heading
+	switch status.State {
+	case "TODO":
+		text = paint(ansiYellow, status.State) + strings.TrimPrefix(text, status.State)
+	case "DONE":
+		text = paint(ansiGreen, status.State) + strings.TrimPrefix(text, status.State)
+	}
+
+	parts := []string{text}
+	if status.Tasks > 0 {
+		cookie := fmt.Sprintf("[%d/%d]", status.Checked, status.Tasks)
+		if status.Checked == status.Tasks {
+			parts = append(parts, paint(ansiGreen, cookie))
+		} else {
+			parts = append(parts, paint(ansiYellow, cookie))
+		}
+	}
+	headingTags := len(markdown.HeadingTags(heading.Heading))
+	if hashtags := status.Tags[headingTags:]; len(hashtags) > 0 {
+		parts = append(parts, paint(ansiCyan, "#"+strings.Join(hashtags, " #")))
+	}
+	if status.Children > 0 {
+		parts = append(parts, paint(ansiDim, fmt.Sprintf("(%d %s)", status.Children, pluralWord(status.Children, "child", "children"))))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// useColor reports whether to colour output written to f: on a terminal,
+// unless --plain or NO_COLOR asks for none
+func useColor(f *os.File) bool {
+	return isTerminal(f) && !cmdutil.Plain() && os.Getenv("NO_COLOR") == ""
+}
+
+// pluralWord returns singular for a count of one, plural otherwise
+func pluralWord(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+func init() {
+	tocCmd.Flags().Bool("annotate", false, "Show TODO states, checklist completion, tags and child counts")
+	tocCmd.Flags().Int("depth", 0, "Deepest heading level to include (0 for all)")
+	tocCmd.Flags().String("format", "text", "Output format: text, markdown or json")
+	tocCmd.Flags().Bool("slugs", false, "Use GitHub-style slug selectors (file.md#heading-slug)")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestAnnotateTOC(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot"), InboxPath: filepath.Join(root, "inbox.md")}
+	content := strings.Join([]string{
+		"# Project :work:",
+		"Owner #alice",
+		"## TODO Design",
+		"- [x] sketch",
+		"- [ ] review #blocked",
+		"### Notes",
+		"1. [ ] ask",
+		"## DONE Setup",
+		"- [X] repo",
+		"```",
+		"- [ ] not a task",
+		"```",
+		"## Misc",
+	}, "\n\n")
+	if err := os.WriteFile(filepath.Join(root, "p.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	headings, err := fileTOC(ws, "p.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := annotateTOC(ws, "p.md", headings); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []TOCStatus{
+		{Tags: []string{"work", "alice"}, Checked: 2, Tasks: 4, Children: 3},
+		{State: "TODO", Tags: []string{"blocked"}, Checked: 1, Tasks: 3, Children: 1},
+		{Checked: 0, Tasks: 1},
+		{State: "DONE", Checked: 1, Tasks: 1},
+		{},
+	}
+	if len(headings) != len(want) {
+		t.Fatalf("got %d headings, want %d", len(headings), len(want))
+	}
+	for i, heading := range headings {
+		got := *heading.Status
+		if got.State != want[i].State || strings.Join(got.Tags, ",") != strings.Join(want[i].Tags, ",") ||
+			got.Checked != want[i].Checked || got.Tasks != want[i].Tasks || got.Children != want[i].Children {
+			t.Errorf("%s: got %+v, want %+v", heading.Heading, got, want[i])
+		}
+	}
+
+	if got := annotatedHeading(headings[1], false); got != "TODO Design  [1/3]  #blocked  (1 child)" {
+		t.Errorf("annotatedHeading = %q", got)
+	}
+}
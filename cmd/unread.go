@@ -0,0 +1,426 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+	"github.com/johncoder/jot/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var unreadCmd = &cobra.Command{
+	Use:   "unread [FILE|SELECTOR...]",
+	Short: "List subtrees changed since you last read them",
+	Long: `List the subtrees that changed since you last read them, so that in a
+workspace synced with a team you can see what others have added.
+
+'jot read' marks files or subtrees as read, remembering each heading's text
+as it is now. 'jot unread' then lists every heading whose own text has
+changed since, was added, or was removed. A file you have never read is
+listed once, as unread; start with 'jot read --all' to take everything as
+read.
+
+Markers are kept per user in .jot/reads/, which is not synced. The user is
+JOT_USER when set, otherwise the operating system user.
+
+Examples:
+  jot read --all                   # Everything as it is now is read
+  jot unread                       # What changed since
+  jot unread "projects/*.md"
+  jot unread "work.md#meetings"
+  jot unread --mark                # List, then mark all of it read`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		mark, _ := cmd.Flags().GetBool("mark")
+
+		user := access.CurrentUser()
+		marks, err := ws.LoadReadMarks(user)
+		if err != nil {
+			return ctx.HandleOperationError("load read markers", err)
+		}
+
+		targets, err := readTargets(ws, args)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+
+		items := []UnreadItem{}
+		for _, target := range targets {
+			found, err := unreadIn(ws, marks, target)
+			if err != nil {
+				return ctx.HandleError(err)
+			}
+			items = append(items, found...)
+		}
+		if len(args) == 0 {
+			// Files read before and deleted since
+			existing := map[string]bool{}
+			for _, target := range targets {
+				existing[target.File] = true
+			}
+			var gone []string
+			for file := range marks.Files {
+				if !existing[file] {
+					gone = append(gone, file)
+				}
+			}
+			sort.Strings(gone)
+			for _, file := range gone {
+				items = append(items, UnreadItem{Status: "removed", Selector: file, File: file})
+			}
+		}
+
+		if mark {
+			if err := markRead(ws, marks, targets, len(args) == 0); err != nil {
+				return ctx.HandleError(err)
+			}
+			if err := ws.SaveReadMarks(user, marks); err != nil {
+				return ctx.HandleOperationError("save read markers", err)
+			}
+		}
+
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(UnreadResponse{
+				Operation: "unread",
+				User:      user,
+				Items:     items,
+				Marked:    mark,
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(items) == 0 {
+			fmt.Println("Nothing unread.")
+			return nil
+		}
+		neverRead := false
+		for _, item := range items {
+			line := fmt.Sprintf("%-8s  %s", item.Status, item.Selector)
+			if item.Line > 0 {
+				line += fmt.Sprintf(":%d", item.Line)
+			}
+			fmt.Println(line)
+			neverRead = neverRead || item.Status == "unread"
+		}
+		if mark {
+			cmdutil.ShowSuccess("\n✓ Marked %d item%s read", len(items), pluralize(len(items)))
+		} else if neverRead {
+			fmt.Println("\nUse 'jot read --all' to mark everything read as it is now")
+		}
+		return nil
+	},
+}
+
+var readCmd = &cobra.Command{
+	Use:   "read [FILE|SELECTOR...]",
+	Short: "Mark files or subtrees as read",
+	Long: `Mark files or subtrees as read as they are now, so 'jot unread' only
+lists what changes after this. A selector marks its heading and everything
+nested under it; globs work as in 'jot expand'.
+
+Examples:
+  jot read --all
+  jot read work.md
+  jot read "work.md#meetings"
+  jot read "projects/*.md"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmdutil.StartCommand(cmd)
+
+		ws, err := getWorkspace(cmd)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) > 0) {
+			return ctx.HandleValidation("selector", strings.Join(args, " "), fmt.Errorf("give files or selectors to mark read, or --all"))
+		}
+
+		user := access.CurrentUser()
+		marks, err := ws.LoadReadMarks(user)
+		if err != nil {
+			return ctx.HandleOperationError("load read markers", err)
+		}
+		targets, err := readTargets(ws, args)
+		if err != nil {
+			return ctx.HandleError(err)
+		}
+		if err := markRead(ws, marks, targets, all); err != nil {
+			return ctx.HandleError(err)
+		}
+		if err := ws.SaveReadMarks(user, marks); err != nil {
+			return ctx.HandleOperationError("save read markers", err)
+		}
+
+		selectors := make([]string, 0, len(targets))
+		for _, target := range targets {
+			selectors = append(selectors, target.Selector)
+		}
+		if ctx.IsJSONOutput() {
+			return cmdutil.OutputJSON(ReadResponse{
+				Operation: "read",
+				User:      user,
+				Selectors: selectors,
+				Metadata:  cmdutil.CreateJSONMetadata(cmd, true, ctx.StartTime),
+			})
+		}
+
+		if len(selectors) == 1 {
+			cmdutil.ShowSuccess("✓ Marked %s read", selectors[0])
+		} else {
+			cmdutil.ShowSuccess("✓ Marked %d files and subtrees read", len(selectors))
+		}
+		return nil
+	},
+}
+
+// UnreadResponse is the JSON response for unread
+type UnreadResponse struct {
+	Operation string               `json:"operation"`
+	User      string               `json:"user"`
+	Items     []UnreadItem         `json:"items"`
+	Marked    bool                 `json:"marked"` // --mark: the items are now read
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// UnreadItem is a heading, or a whole file, changed since it was read
+type UnreadItem struct {
+	Status   string `json:"status"` // changed, new, removed, or unread for a file never read
+	Selector string `json:"selector"`
+	File     string `json:"file"`
+	Heading  string `json:"heading,omitempty"`
+	Line     int    `json:"line,omitempty"`
+}
+
+// ReadResponse is the JSON response for read
+type ReadResponse struct {
+	Operation string               `json:"operation"`
+	User      string               `json:"user"`
+	Selectors []string             `json:"selectors"`
+	Metadata  cmdutil.JSONMetadata `json:"metadata"`
+}
+
+// readTargets expands the files and selectors given to read and unread,
+// or returns every markdown file in the workspace when none are given
+func readTargets(ws *workspace.Workspace, args []string) ([]ExpandedSelector, error) {
+	var files []string
+	if len(args) == 0 {
+		scanned, err := scanWorkspaceMarkdownFiles(ws)
+		if err != nil {
+			return nil, err
+		}
+		targets := make([]ExpandedSelector, 0, len(scanned))
+		for _, file := range scanned {
+			file = filepath.ToSlash(file)
+			targets = append(targets, ExpandedSelector{Selector: file, File: file})
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i].File < targets[j].File })
+		return targets, nil
+	}
+
+	var targets []ExpandedSelector
+	for _, arg := range args {
+		expanded, err := expandPattern(ws, arg, &files)
+		if err != nil {
+			return nil, err
+		}
+		if len(expanded) == 0 {
+			return nil, fmt.Errorf("no files or headings match %s", arg)
+		}
+		targets = append(targets, expanded...)
+	}
+	return targets, nil
+}
+
+// noteSection is a heading and its own text, up to the next heading, or the
+// text before the first heading
+type noteSection struct {
+	Key   string // Heading path, unique within the file
+	Path  string // Heading path, as a selector has it
+	Level int    // 0 for the text before the first heading
+	Line  int
+	Hash  string
+}
+
+// noteSections splits content into sections and hashes each one
+func noteSections(content []byte) []noteSection {
+	doc := markdown.ParseDocument(content)
+	headings := markdown.FindAllHeadings(doc, content)
+
+	starts := make([]int, len(headings))
+	for i, heading := range headings {
+		starts[i] = bytes.LastIndexByte(content[:heading.Offset], '\n') + 1
+	}
+
+	var sections []noteSection
+	first := len(content)
+	if len(starts) > 0 {
+		first = starts[0]
+	}
+	if text := bytes.TrimSpace(content[:first]); len(text) > 0 {
+		sections = append(sections, noteSection{Line: 1, Hash: sectionHash(text)})
+	}
+
+	seen := map[string]int{}
+	for i, heading := range headings {
+		end := len(content)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		path := strings.Join(heading.Path, "/")
+		key := path
+		if seen[path]++; seen[path] > 1 {
+			key = fmt.Sprintf("%s (%d)", path, seen[path])
+		}
+		sections = append(sections, noteSection{
+			Key:   key,
+			Path:  path,
+			Level: heading.Level,
+			Line:  markdown.CalculateLineNumber(content, starts[i]),
+			Hash:  sectionHash(content[starts[i]:end]),
+		})
+	}
+	return sections
+}
+
+// sectionHash is a short hash of a section's text, ignoring the blank lines
+// and spaces around it
+func sectionHash(text []byte) string {
+	sum := sha256.Sum256(bytes.TrimSpace(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// sectionsUnder returns the sections a target covers: all of them for a
+// file, or for a selector its heading and the ones nested under it
+func sectionsUnder(sections []noteSection, target ExpandedSelector) []noteSection {
+	if target.Heading == "" {
+		return sections
+	}
+	for i, section := range sections {
+		if section.Level == 0 || section.Line != target.Line {
+			continue
+		}
+		end := i + 1
+		for end < len(sections) && sections[end].Level > section.Level {
+			end++
+		}
+		return sections[i:end]
+	}
+	return nil
+}
+
+// loadSections reads a workspace file and splits it into sections
+func loadSections(ws *workspace.Workspace, file string) ([]noteSection, error) {
+	content, err := storage.ReadFile(cmdutil.ResolveWorkspaceRelativePath(ws, file))
+	if err != nil {
+		return nil, cmdutil.NewFileError("read", file, err)
+	}
+	return noteSections(content), nil
+}
+
+// unreadIn lists what in target changed since it was read
+func unreadIn(ws *workspace.Workspace, marks *workspace.ReadMarks, target ExpandedSelector) ([]UnreadItem, error) {
+	read := marks.Files[target.File]
+	if read == nil {
+		return []UnreadItem{{Status: "unread", Selector: target.Selector, File: target.File}}, nil
+	}
+	sections, err := loadSections(ws, target.File)
+	if err != nil {
+		return nil, err
+	}
+	return diffSections(target.File, read.Sections, sectionsUnder(sections, target), target.Heading == ""), nil
+}
+
+// diffSections compares sections against the hashes they had when read.
+// Sections read but no longer there are only reported when sections is the
+// whole file.
+func diffSections(file string, read map[string]string, sections []noteSection, whole bool) []UnreadItem {
+	var items []UnreadItem
+	current := map[string]bool{}
+	for _, section := range sections {
+		current[section.Key] = true
+		hash, ok := read[section.Key]
+		if ok && hash == section.Hash {
+			continue
+		}
+		item := UnreadItem{Status: "changed", Selector: file, File: file, Line: section.Line}
+		if !ok {
+			item.Status = "new"
+		}
+		if section.Level > 0 {
+			item.Selector += "#" + section.Path
+			item.Heading = section.Path[strings.LastIndex(section.Path, "/")+1:]
+		}
+		items = append(items, item)
+	}
+	if !whole {
+		return items
+	}
+
+	var removed []string
+	for key := range read {
+		if !current[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		item := UnreadItem{Status: "removed", Selector: file, File: file}
+		if key != "" {
+			item.Selector += "#" + key
+			item.Heading = key[strings.LastIndex(key, "/")+1:]
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// markRead records targets as read as they are now. A whole file's marks
+// are replaced, so headings removed from it are forgotten. prune also
+// forgets files that no longer exist.
+func markRead(ws *workspace.Workspace, marks *workspace.ReadMarks, targets []ExpandedSelector, prune bool) error {
+	for _, target := range targets {
+		sections, err := loadSections(ws, target.File)
+		if err != nil {
+			return err
+		}
+		read := marks.Files[target.File]
+		if read == nil || read.Sections == nil || target.Heading == "" {
+			read = &workspace.FileMarks{Sections: map[string]string{}}
+			marks.Files[target.File] = read
+		}
+		for _, section := range sectionsUnder(sections, target) {
+			read.Sections[section.Key] = section.Hash
+		}
+	}
+	if prune {
+		existing := map[string]bool{}
+		for _, target := range targets {
+			existing[target.File] = true
+		}
+		for file := range marks.Files {
+			if !existing[file] {
+				delete(marks.Files, file)
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	unreadCmd.Flags().Bool("mark", false, "Mark what is listed as read")
+	readCmd.Flags().Bool("all", false, "Mark every file in the workspace read")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNoteSections(t *testing.T) {
+	content := "intro\n\n# Log\nday one\n## Notes\nx\n# Log\nagain\n"
+	sections := noteSections([]byte(content))
+
+	var keys []string
+	var lines []int
+	for _, section := range sections {
+		keys = append(keys, section.Key)
+		lines = append(lines, section.Line)
+	}
+	if want := []string{"", "Log", "Log/Notes", "Log (2)"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+	if want := []int{1, 3, 5, 7}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+
+	// A nested heading changing leaves its parent's hash alone
+	edited := noteSections([]byte("intro\n\n# Log\nday one\n## Notes\ny\n# Log\nagain\n"))
+	if edited[1].Hash != sections[1].Hash || edited[2].Hash == sections[2].Hash {
+		t.Error("only the edited section's hash should change")
+	}
+
+	under := sectionsUnder(sections, ExpandedSelector{File: "a.md", Heading: "Log", Line: 3})
+	if len(under) != 2 || under[1].Key != "Log/Notes" {
+		t.Errorf("sectionsUnder = %+v", under)
+	}
+}
+
+func TestDiffSections(t *testing.T) {
+	before := noteSections([]byte("# A\none\n# B\ntwo\n# C\nthree\n"))
+	read := map[string]string{}
+	for _, section := range before {
+		read[section.Key] = section.Hash
+	}
+
+	after := noteSections([]byte("# A\none\n# B\nTWO\n# D\nfour\n"))
+	var got []string
+	for _, item := range diffSections("a.md", read, after, true) {
+		got = append(got, item.Status+" "+item.Selector)
+	}
+	want := []string{"changed a.md#B", "new a.md#D", "removed a.md#C"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if items := diffSections("a.md", read, after[:1], false); len(items) != 0 {
+		t.Errorf("unchanged subtree: got %+v", items)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/johncoder/jot/internal/cmdutil"
 	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -205,8 +206,11 @@ func workspaceAdd(cmd *cobra.Command, name, path string) error {
 		return ctx.HandleError(fmt.Errorf("failed to initialize config: %w", err))
 	}
 
-	// Resolve absolute path
-	absPath, err := filepath.Abs(path)
+	// Resolve absolute path; remote workspaces keep their ssh:// URL
+	absPath, err := path, error(nil)
+	if !storage.IsSSHURL(path) {
+		absPath, err = filepath.Abs(path)
+	}
 	if err != nil {
 		err := fmt.Errorf("failed to resolve path: %w", err)
 		if cmdutil.IsJSONOutput(cmd) {
@@ -225,7 +229,14 @@ func workspaceAdd(cmd *cobra.Command, name, path string) error {
 	}
 
 	// Validate that path exists and is initialized
-	if !workspace.IsValid(absPath) {
+	if storage.IsSSHURL(absPath) {
+		if _, err := workspace.RemoteWorkspace(absPath); err != nil {
+			if cmdutil.IsJSONOutput(cmd) {
+				return ctx.HandleError(err)
+			}
+			return err
+		}
+	} else if !workspace.IsValid(absPath) {
 		err := fmt.Errorf("path %s does not exist or is not initialized\nRun 'jot init %s' to initialize it first", absPath, absPath)
 		if cmdutil.IsJSONOutput(cmd) {
 			return ctx.HandleError(err)
@@ -285,7 +296,7 @@ func workspaceRemove(cmd *cobra.Command, name string) error {
 	// Check if workspace exists
 	workspacePath, err := config.GetWorkspace(name)
 	if err != nil {
-		err := fmt.Errorf("workspace '%s' not found in registry\nUse 'jot workspace list' to see available workspaces", name)
+		err := fmt.Errorf("workspace '%s' %w\nUse 'jot workspace list' to see available workspaces", name, workspace.ErrNotRegistered)
 		if cmdutil.IsJSONOutput(cmd) {
 			return ctx.HandleError(err)
 		}
@@ -363,7 +374,7 @@ func workspaceSetDefault(cmd *cobra.Command, name string) error {
 	// Check if workspace exists
 	_, err := config.GetWorkspace(name)
 	if err != nil {
-		err := fmt.Errorf("workspace '%s' not found in registry\nUse 'jot workspace list' to see available workspaces", name)
+		err := fmt.Errorf("workspace '%s' %w\nUse 'jot workspace list' to see available workspaces", name, workspace.ErrNotRegistered)
 		if cmdutil.IsJSONOutput(cmd) {
 			return ctx.HandleError(err)
 		}
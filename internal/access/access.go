@@ -0,0 +1,324 @@
+// Package access maps users and groups to the parts of a shared workspace
+// they may read or write, as declared in .jot/access.yaml
+package access
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the policy file name inside the .jot directory
+const FileName = "access.yaml"
+
+// TokensFileName holds the hashes of serve tokens, one "user hash" per line
+const TokensFileName = "access-tokens"
+
+// ErrNoPolicy is returned when the policy file does not exist
+var ErrNoPolicy = errors.New("no access policy found")
+
+// Level is what a user may do with a file
+type Level int
+
+const (
+	None Level = iota
+	Read
+	Write
+)
+
+// ParseLevel reads "none", "read" or "write"
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return None, nil
+	case "read":
+		return Read, nil
+	case "write":
+		return Write, nil
+	}
+	return None, fmt.Errorf("unknown access level %q (expected none, read or write)", s)
+}
+
+func (l Level) String() string {
+	switch l {
+	case Read:
+		return "read"
+	case Write:
+		return "write"
+	}
+	return "none"
+}
+
+// Policy is the parsed policy file
+type Policy struct {
+	Mode    string              `yaml:"mode"`    // "enforce" (default) refuses writes, "warn" reports them
+	Default string              `yaml:"default"` // Level for files no rule covers; write when empty
+	Groups  map[string][]string `yaml:"groups"`  // Group name to member user names
+	Rules   []*Rule             `yaml:"rules"`
+
+	defaultLevel Level
+	tokens       map[string]string // Token hash to user
+}
+
+// Rule grants levels on a directory, file or glob to users ("alice"),
+// groups ("group:editors") and everyone ("*")
+type Rule struct {
+	Path   string            `yaml:"path"`
+	Access map[string]string `yaml:"access"`
+
+	levels map[string]Level
+}
+
+// Load reads and validates the policy in a .jot directory, along with its
+// serve tokens
+func Load(jotDir string) (*Policy, error) {
+	data, err := os.ReadFile(filepath.Join(jotDir, FileName))
+	if os.IsNotExist(err) {
+		return nil, ErrNoPolicy
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access policy: %w", err)
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.loadTokens(filepath.Join(jotDir, TokensFileName)); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Parse parses and validates policy file content
+func Parse(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse access policy: %w", err)
+	}
+
+	switch policy.Mode {
+	case "":
+		policy.Mode = "enforce"
+	case "enforce", "warn":
+	default:
+		return nil, fmt.Errorf("access policy: unknown mode %q (expected enforce or warn)", policy.Mode)
+	}
+
+	policy.defaultLevel = Write
+	if policy.Default != "" {
+		level, err := ParseLevel(policy.Default)
+		if err != nil {
+			return nil, fmt.Errorf("access policy: default: %w", err)
+		}
+		policy.defaultLevel = level
+	}
+
+	for i, rule := range policy.Rules {
+		rule.Path = cleanPath(rule.Path)
+		rule.levels = make(map[string]Level, len(rule.Access))
+		for principal, value := range rule.Access {
+			level, err := ParseLevel(value)
+			if err != nil {
+				return nil, fmt.Errorf("access policy: rule %d (%s): %w", i+1, rule.Path, err)
+			}
+			if group, ok := strings.CutPrefix(principal, "group:"); ok {
+				if _, known := policy.Groups[group]; !known {
+					return nil, fmt.Errorf("access policy: rule %d (%s): unknown group %q", i+1, rule.Path, group)
+				}
+			}
+			rule.levels[principal] = level
+		}
+	}
+
+	// The most specific rule is consulted first
+	sort.SliceStable(policy.Rules, func(i, j int) bool {
+		return specificity(policy.Rules[i].Path) > specificity(policy.Rules[j].Path)
+	})
+	return &policy, nil
+}
+
+// Warn reports whether denied writes are only reported
+func (p *Policy) Warn() bool {
+	return p.Mode == "warn"
+}
+
+// Level returns what user may do with a workspace-relative file. The most
+// specific rule naming the user, one of their groups, or "*" decides;
+// within a rule, the user's own entry wins over their groups, whose highest
+// level wins over "*". Files no rule decides get the default level.
+func (p *Policy) Level(userName, file string) Level {
+	file = cleanPath(file)
+	for _, rule := range p.Rules {
+		if !rule.matches(file) {
+			continue
+		}
+		if level, ok := p.ruleLevel(rule, userName); ok {
+			return level
+		}
+	}
+	return p.defaultLevel
+}
+
+func (p *Policy) ruleLevel(rule *Rule, userName string) (Level, bool) {
+	if level, ok := rule.levels[userName]; ok {
+		return level, true
+	}
+	best, found := None, false
+	for group, members := range p.Groups {
+		level, ok := rule.levels["group:"+group]
+		if !ok || !contains(members, userName) {
+			continue
+		}
+		if !found || level > best {
+			best, found = level, true
+		}
+	}
+	if found {
+		return best, true
+	}
+	level, ok := rule.levels["*"]
+	return level, ok
+}
+
+// DeniedError reports a user lacking the access an operation needs
+type DeniedError struct {
+	User string
+	Path string
+	Want Level
+	Have Level
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("access denied: %s may not %s %s (access: %s)", e.User, e.Want, e.Path, e.Have)
+}
+
+// Check returns a DeniedError unless user has at least want on file
+func (p *Policy) Check(userName, file string, want Level) error {
+	if have := p.Level(userName, file); have < want {
+		return &DeniedError{User: userName, Path: cleanPath(file), Want: want, Have: have}
+	}
+	return nil
+}
+
+// Authenticate returns the user a serve token belongs to
+func (p *Policy) Authenticate(token string) (string, bool) {
+	hash := HashToken(token)
+	for known, userName := range p.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(hash)) == 1 {
+			return userName, true
+		}
+	}
+	return "", false
+}
+
+func (p *Policy) loadTokens(file string) error {
+	p.tokens = make(map[string]string)
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read access tokens: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && !strings.HasPrefix(fields[0], "#") {
+			p.tokens[fields[1]] = fields[0]
+		}
+	}
+	return scanner.Err()
+}
+
+// NewToken returns a random serve token and the hash to store for it
+func NewToken() (token, hash string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = "jot_" + hex.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of a token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// CurrentUser names the user running jot: JOT_USER when set, otherwise the
+// operating system user
+func CurrentUser() string {
+	if name := os.Getenv("JOT_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// matches reports whether a rule covers file: the rule's path itself,
+// anything beneath it, or anything a glob path matches
+func (r *Rule) matches(file string) bool {
+	if r.Path == "" || r.Path == file || strings.HasPrefix(file, r.Path+"/") {
+		return true
+	}
+	if strings.ContainsAny(r.Path, "*?[") {
+		if ok, _ := path.Match(r.Path, file); ok {
+			return true
+		}
+		// A glob naming directories covers what is beneath them
+		for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+			if ok, _ := path.Match(r.Path, dir); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// specificity ranks rule paths: deeper paths first, literal before glob
+func specificity(p string) int {
+	if p == "" {
+		return 0
+	}
+	score := 2 * (strings.Count(p, "/") + 1)
+	if !strings.ContainsAny(p, "*?[") {
+		score++
+	}
+	return score
+}
+
+// cleanPath normalizes a workspace-relative path; the workspace root is ""
+func cleanPath(p string) string {
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	p = strings.TrimPrefix(p, "/")
+	if p == "." || p == "*" || p == "**" {
+		return ""
+	}
+	return p
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
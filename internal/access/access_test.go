@@ -0,0 +1,112 @@
+package access
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPolicy = `
+default: read
+groups:
+  hr: [dana, erin]
+  leads: [erin]
+rules:
+  - path: lib/hr
+    access:
+      group:hr: write
+      "*": none
+  - path: lib/hr/handbook.md
+    access:
+      "*": read
+  - path: lib/*/drafts
+    access:
+      "*": write
+  - path: inbox.md
+    access:
+      "*": write
+      mallory: read
+`
+
+func TestLevel(t *testing.T) {
+	policy, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user, file string
+		want       Level
+	}{
+		{"alice", "lib/work.md", Read},
+		{"alice", "lib/hr/reviews.md", None},
+		{"dana", "lib/hr/reviews.md", Write},
+		{"dana", "lib/hr", Write},
+		{"alice", "lib/hrx.md", Read},
+		{"alice", "lib/hr/handbook.md", Read},
+		{"dana", "lib/hr/handbook.md", Read},
+		{"alice", "lib/eng/drafts/plan.md", Write},
+		{"alice", "inbox.md", Write},
+		{"mallory", "inbox.md", Read},
+		{"alice", "./lib/hr/../hr/x.md", None},
+	}
+	for _, tt := range tests {
+		if got := policy.Level(tt.user, tt.file); got != tt.want {
+			t.Errorf("Level(%q, %q) = %v, want %v", tt.user, tt.file, got, tt.want)
+		}
+	}
+
+	var denied *DeniedError
+	if err := policy.Check("alice", "lib/work.md", Write); !errors.As(err, &denied) || denied.Have != Read {
+		t.Errorf("Check = %v, want a DeniedError with read access", err)
+	}
+	if err := policy.Check("dana", "lib/hr/x.md", Write); err != nil {
+		t.Errorf("Check = %v, want nil", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []string{
+		"mode: strict\n",
+		"default: admin\n",
+		"rules:\n  - path: lib\n    access:\n      alice: owner\n",
+		"rules:\n  - path: lib\n    access:\n      group:nobody: read\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded", data)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(dir); !errors.Is(err, ErrNoPolicy) {
+		t.Fatalf("Load without a policy = %v, want ErrNoPolicy", err)
+	}
+
+	token, hash, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("mode: warn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, TokensFileName), []byte("dana "+hash+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !policy.Warn() {
+		t.Error("Warn() = false for mode: warn")
+	}
+	if user, ok := policy.Authenticate(token); !ok || user != "dana" {
+		t.Errorf("Authenticate = %q, %v; want dana", user, ok)
+	}
+	if _, ok := policy.Authenticate(token + "x"); ok {
+		t.Error("Authenticate accepted a wrong token")
+	}
+}
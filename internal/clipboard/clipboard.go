@@ -0,0 +1,40 @@
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// commands are the clipboard tools tried, in order, to copy text
+var commands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+	{"clip"},
+}
+
+// Copy puts text on the system clipboard, using the first clipboard tool
+// found on the PATH. JOT_CLIPBOARD, when set, names the command to use
+// instead; it reads the text from stdin.
+func Copy(text string) error {
+	candidates := commands
+	if custom := strings.Fields(os.Getenv("JOT_CLIPBOARD")); len(custom) > 0 {
+		candidates = [][]string{custom}
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(candidate[0], candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %w: %s", candidate[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard tool found; install pbcopy, wl-copy, xclip or xsel, or set JOT_CLIPBOARD")
+}
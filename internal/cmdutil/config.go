@@ -2,10 +2,10 @@ package cmdutil
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/spf13/cobra"
 )
 
@@ -151,7 +151,7 @@ func CreateDefaultWorkspaceConfig(jotDir string) (string, []byte, error) {
 }
 `
 
-	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+	if err := dryrun.WriteFile(configPath, []byte(configContent), 0644); err != nil {
 		return "", nil, fmt.Errorf("failed to create workspace config: %w", err)
 	}
 
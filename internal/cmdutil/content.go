@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"gopkg.in/yaml.v3"
 )
@@ -40,13 +42,13 @@ func (f *FileOperator) ReadMarkdownFile(path string) (*MarkdownContent, error) {
 	resolvedPath := ResolvePath(f.workspace, path, f.noWorkspace)
 
 	// Read file content
-	content, err := os.ReadFile(resolvedPath)
+	content, err := storage.ReadFile(resolvedPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", resolvedPath, err)
 	}
 
 	// Get file info for mod time
-	info, err := os.Stat(resolvedPath)
+	info, err := storage.Stat(resolvedPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info for %s: %w", resolvedPath, err)
 	}
@@ -70,12 +72,12 @@ func (f *FileOperator) WriteMarkdownFile(path string, content *MarkdownContent)
 
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(resolvedPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := dryrun.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
 	// Write file
-	if err := os.WriteFile(resolvedPath, []byte(content.Raw), 0644); err != nil {
+	if err := dryrun.WriteFile(resolvedPath, []byte(content.Raw), 0644); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", resolvedPath, err)
 	}
 
@@ -87,7 +89,7 @@ func (f *FileOperator) BackupFile(path string) (string, error) {
 	resolvedPath := ResolvePath(f.workspace, path, f.noWorkspace)
 
 	// Check if file exists
-	if _, err := os.Stat(resolvedPath); os.IsNotExist(err) {
+	if _, err := storage.Stat(resolvedPath); os.IsNotExist(err) {
 		return "", nil // No backup needed for non-existent file
 	}
 
@@ -96,13 +98,13 @@ func (f *FileOperator) BackupFile(path string) (string, error) {
 	backupPath := resolvedPath + ".backup." + timestamp
 
 	// Read original content
-	content, err := os.ReadFile(resolvedPath)
+	content, err := storage.ReadFile(resolvedPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file for backup %s: %w", resolvedPath, err)
 	}
 
 	// Write backup
-	if err := os.WriteFile(backupPath, content, 0644); err != nil {
+	if err := dryrun.WriteFile(backupPath, content, 0644); err != nil {
 		return "", fmt.Errorf("failed to create backup %s: %w", backupPath, err)
 	}
 
@@ -115,19 +117,12 @@ func (f *FileOperator) AppendToFile(path, content string) error {
 
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(resolvedPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := dryrun.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	// Open file for appending, create if doesn't exist
-	file, err := os.OpenFile(resolvedPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open file for appending %s: %w", resolvedPath, err)
-	}
-	defer file.Close()
-
-	// Write content
-	if _, err := file.WriteString(content); err != nil {
+	// Append content, creating the file if it doesn't exist
+	if err := dryrun.AppendFile(resolvedPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to append to file %s: %w", resolvedPath, err)
 	}
 
@@ -137,7 +132,7 @@ func (f *FileOperator) AppendToFile(path, content string) error {
 // FileExists checks if a file exists
 func (f *FileOperator) FileExists(path string) bool {
 	resolvedPath := ResolvePath(f.workspace, path, f.noWorkspace)
-	_, err := os.Stat(resolvedPath)
+	_, err := storage.Stat(resolvedPath)
 	return err == nil
 }
 
@@ -195,12 +190,12 @@ func ValidateMarkdownFile(path string) error {
 	}
 
 	// Check if file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if _, err := storage.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("file %s does not exist", path)
 	}
 
 	// Check if file is readable
-	file, err := os.Open(path)
+	file, err := storage.Open(path)
 	if err != nil {
 		return fmt.Errorf("cannot read file %s: %w", path, err)
 	}
@@ -287,7 +282,7 @@ func (cp *ContentProcessor) validateTemplateContent(content *MarkdownContent) er
 
 // ReadFileContent reads file content with unified error handling
 func ReadFileContent(path string) ([]byte, error) {
-	content, err := os.ReadFile(path)
+	content, err := storage.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
@@ -298,11 +293,11 @@ func ReadFileContent(path string) ([]byte, error) {
 func WriteFileContent(path string, content []byte) error {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := dryrun.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	if err := os.WriteFile(path, content, 0644); err != nil {
+	if err := dryrun.WriteFile(path, content, 0644); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", path, err)
 	}
 	return nil
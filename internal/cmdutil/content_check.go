@@ -0,0 +1,89 @@
+package cmdutil
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+// Kinds of ContentError
+const (
+	ContentBinary   = "binary"
+	ContentTooLarge = "too_large"
+)
+
+// LargeContentSize is the size above which captured content or a refile
+// destination is treated as a mistake, such as a generated file
+const LargeContentSize = 10 << 20
+
+// binarySniffSize is how much of the content LooksBinary examines
+const binarySniffSize = 8000
+
+// ContentError reports content that looks binary or is very large, which
+// capture and refile refuse to write without --force
+type ContentError struct {
+	Path string `json:"path,omitempty"` // The file concerned; empty for captured content
+	Kind string `json:"kind"`           // ContentBinary or ContentTooLarge
+	Size int64  `json:"size"`
+}
+
+func (e *ContentError) Error() string {
+	subject := "captured content"
+	if e.Path != "" {
+		subject = fmt.Sprintf("'%s'", e.Path)
+	}
+	if e.Kind == ContentBinary {
+		return fmt.Sprintf("%s looks binary, not text", subject)
+	}
+	return fmt.Sprintf("%s is %.1f MB, over the %d MB limit", subject, float64(e.Size)/(1<<20), LargeContentSize>>20)
+}
+
+// LooksBinary reports whether data looks like binary rather than text: its
+// start holds a NUL byte or is not valid UTF-8. data may be the first part
+// of a larger text, cut off mid-character.
+func LooksBinary(data []byte) bool {
+	sample := data[:min(len(data), binarySniffSize)]
+	// A character cut off by the end of the sample is not invalid
+	for cut := 1; cut < utf8.UTFMax && cut <= len(sample); cut++ {
+		if start := len(sample) - cut; utf8.RuneStart(sample[start]) {
+			if !utf8.FullRune(sample[start:]) {
+				sample = sample[:start]
+			}
+			break
+		}
+	}
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(sample)
+}
+
+// CheckContent returns a *ContentError when data looks binary or is larger
+// than LargeContentSize. path names the file data came from, if any.
+func CheckContent(path string, data []byte) error {
+	if LooksBinary(data) {
+		return &ContentError{Path: path, Kind: ContentBinary, Size: int64(len(data))}
+	}
+	if len(data) > LargeContentSize {
+		return &ContentError{Path: path, Kind: ContentTooLarge, Size: int64(len(data))}
+	}
+	return nil
+}
+
+var (
+	forcedMu sync.Mutex
+	forced   []ContentError
+)
+
+// RecordForcedContent notes content written despite err because of --force,
+// so JSON metadata can report it
+func RecordForcedContent(err *ContentError) {
+	forcedMu.Lock()
+	defer forcedMu.Unlock()
+	forced = append(forced, *err)
+}
+
+// forcedContent returns the content written despite a ContentError
+func forcedContent() []ContentError {
+	forcedMu.Lock()
+	defer forcedMu.Unlock()
+	return append([]ContentError(nil), forced...)
+}
@@ -0,0 +1,53 @@
+package cmdutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLooksBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"text", []byte("# Notes\n\n- café ☕\n"), false},
+		{"cut mid-character", []byte("café ☕")[:8], false},
+		{"NUL byte", []byte("abc\x00def"), true},
+		{"invalid UTF-8", []byte{0x89, 'P', 'N', 'G', '\r', '\n'}, true},
+		{"long text cut at the sample", []byte(strings.Repeat("é", binarySniffSize)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LooksBinary(tt.data); got != tt.want {
+				t.Errorf("LooksBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckContent(t *testing.T) {
+	if err := CheckContent("notes.md", []byte("# Notes\n")); err != nil {
+		t.Errorf("CheckContent(text) = %v", err)
+	}
+
+	var contentErr *ContentError
+	err := CheckContent("build/out.md", bytes.Repeat([]byte("x"), LargeContentSize+1))
+	if !errors.As(err, &contentErr) || contentErr.Kind != ContentTooLarge || contentErr.Path != "build/out.md" {
+		t.Errorf("CheckContent(large) = %v", err)
+	}
+	if desc := DescribeError(err); desc.Code != "content_too_large" || desc.Path != "build/out.md" {
+		t.Errorf("DescribeError(large) = %+v", desc)
+	}
+
+	err = CheckContent("", []byte("\x00\x01"))
+	if !errors.As(err, &contentErr) || contentErr.Kind != ContentBinary {
+		t.Errorf("CheckContent(binary) = %v", err)
+	}
+	if !strings.Contains(err.Error(), "captured content looks binary") {
+		t.Errorf("message = %q", err)
+	}
+}
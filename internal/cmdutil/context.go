@@ -79,6 +79,11 @@ func (ctx *CommandContext) HandleValidation(field, value string, err error) erro
 	return ctx.WrapValidationError(field, value, err)
 }
 
+// HandleUsage reports a command line that is used wrongly as a validation error
+func (ctx *CommandContext) HandleUsage(err error) error {
+	return ctx.HandleError(NewUsageError(err))
+}
+
 // HandleExternalCommand provides specialized external command error handling
 func (ctx *CommandContext) HandleExternalCommand(command string, args []string, err error) error {
 	if err == nil {
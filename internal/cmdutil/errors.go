@@ -3,15 +3,21 @@ package cmdutil
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"time"
 
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 // Standard error variables for error inspection
 var (
 	ErrFileNotFound    = errors.New("file not found")
+	ErrNotFound        = errors.New("not found")
 	ErrInvalidInput    = errors.New("invalid input")
 	ErrOperationFailed = errors.New("operation failed")
 	ErrExternalCommand = errors.New("external command failed")
@@ -100,7 +106,8 @@ func (e *FileError) Is(target error) bool {
 	return false
 }
 
-// ValidationError represents input validation errors
+// ValidationError represents input validation errors. Without a Field it
+// is a usage error: a missing argument or flags that cannot be combined.
 type ValidationError struct {
 	Field string
 	Value string
@@ -108,6 +115,9 @@ type ValidationError struct {
 }
 
 func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
 	if e.Value != "" {
 		return fmt.Sprintf("invalid %s '%s': %v", e.Field, e.Value, e.Err)
 	}
@@ -154,6 +164,12 @@ func NewValidationError(field, value string, err error) *ValidationError {
 	return &ValidationError{Field: field, Value: value, Err: err}
 }
 
+// NewUsageError creates a validation error for a command line that is used
+// wrongly, keeping err's message
+func NewUsageError(err error) *ValidationError {
+	return &ValidationError{Err: err}
+}
+
 // NewExternalError creates an external command error
 func NewExternalError(command string, args []string, err error) *ExternalError {
 	return &ExternalError{Command: command, Args: args, Err: err}
@@ -204,3 +220,135 @@ func (e *OperationError) WithTarget(target string) *OperationError {
 	}
 	return e
 }
+
+// Error categories reported in JSON error objects
+const (
+	CategoryValidation = "validation"
+	CategoryNotFound   = "not_found"
+	CategoryAmbiguous  = "ambiguous"
+	CategoryHookAbort  = "hook_abort"
+	CategoryPermission = "permission"
+	CategoryIO         = "io"
+	CategoryUnknown    = "unknown"
+)
+
+// DescribeError classifies an error for JSON output: a stable code, its
+// category, the selector or path it concerns, and hints for fixing it
+func DescribeError(err error) JSONError {
+	desc := JSONError{
+		Message:  err.Error(),
+		Code:     "unknown_error",
+		Category: CategoryUnknown,
+		Details:  map[string]interface{}{},
+	}
+
+	var (
+		hookErr     *hooks.AbortError
+		deniedErr   *access.DeniedError
+		selectorErr *markdown.SelectorError
+		validation  *ValidationError
+		fileErr     *FileError
+		pathErr     *fs.PathError
+		externalErr *ExternalError
+		contentErr  *ContentError
+	)
+
+	switch {
+	case errors.As(err, &hookErr):
+		desc.Code, desc.Category = "hook_failure", CategoryHookAbort
+		if hookErr.TimedOut {
+			desc.Code = "hook_timeout"
+		}
+		desc.Details["hook"] = hookErr.Hook
+		desc.Details["hook_type"] = string(hookErr.Type)
+		desc.Details["exit_code"] = hookErr.ExitCode
+		desc.Hints = []string{
+			fmt.Sprintf("Run 'jot hooks trace %s' to see what each hook received and printed", hookErr.Type),
+			"Pass --no-verify to skip hooks for this command",
+		}
+
+	case errors.As(err, &deniedErr):
+		desc.Code, desc.Category = "permission_denied", CategoryPermission
+		desc.Path = deniedErr.Path
+		desc.Details["user"] = deniedErr.User
+		desc.Details["access"] = deniedErr.Have.String()
+		desc.Hints = []string{fmt.Sprintf("Run 'jot access %s' to see who may write it", deniedErr.Path)}
+
+	case errors.As(err, &selectorErr):
+		desc.Selector = selectorErr.Selector
+		desc.Path = selectorErr.File
+		switch {
+		case errors.Is(selectorErr, markdown.ErrAmbiguous):
+			desc.Code, desc.Category = "ambiguous_selector", CategoryAmbiguous
+			desc.Details["matches"] = selectorErr.Matches
+			desc.Hints = []string{"Add a parent heading to the selector, e.g. file.md#parent/heading"}
+		case errors.Is(selectorErr, markdown.ErrNoMatch):
+			desc.Code, desc.Category = "selector_not_found", CategoryNotFound
+			desc.Hints = []string{fmt.Sprintf("Run 'jot peek %s --toc' to list its headings", selectorErr.File)}
+		default:
+			desc.Code, desc.Category = "invalid_selector", CategoryValidation
+			desc.Hints = []string{"Selectors look like file.md#heading/subheading"}
+		}
+
+	case errors.Is(err, workspace.ErrNoWorkspace) || errors.Is(err, workspace.ErrNotRegistered):
+		desc.Code, desc.Category = "workspace_not_found", CategoryNotFound
+		desc.Hints = []string{
+			"Run 'jot init' to create a workspace in the current directory",
+			"Pass --workspace NAME to use a workspace from 'jot workspace list'",
+		}
+
+	case errors.As(err, &contentErr):
+		desc.Code, desc.Category = "binary_content", CategoryValidation
+		if contentErr.Kind == ContentTooLarge {
+			desc.Code = "content_too_large"
+		}
+		desc.Path = contentErr.Path
+		desc.Details["size"] = contentErr.Size
+		desc.Hints = []string{"Pass --force to write it anyway"}
+
+	case errors.As(err, &validation):
+		desc.Code, desc.Category = "validation_error", CategoryValidation
+		if validation.Field != "" {
+			desc.Details["field"] = validation.Field
+		}
+		if validation.Value != "" {
+			desc.Details["value"] = validation.Value
+		}
+
+	case errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrFileNotFound):
+		desc.Code, desc.Category = "file_not_found", CategoryNotFound
+		desc.Path = errorPath(err, fileErr, pathErr)
+		desc.Hints = []string{"Paths are relative to the workspace root; 'jot files' lists its notes"}
+
+	case errors.Is(err, fs.ErrPermission):
+		desc.Code, desc.Category = "permission_denied", CategoryPermission
+		desc.Path = errorPath(err, fileErr, pathErr)
+
+	case errors.As(err, &fileErr) || errors.As(err, &pathErr):
+		desc.Code, desc.Category = "io_error", CategoryIO
+		desc.Path = errorPath(err, fileErr, pathErr)
+
+	case errors.As(err, &externalErr):
+		desc.Code, desc.Category = "external_command_failed", CategoryIO
+		desc.Details["command"] = externalErr.Command
+
+	case errors.Is(err, ErrNotFound):
+		desc.Code, desc.Category = "not_found", CategoryNotFound
+
+	case errors.Is(err, workspace.ErrConfig):
+		desc.Code = "workspace_error" // Reported before errors had categories; kept for scripts matching it
+	}
+	return desc
+}
+
+// errorPath returns the path a file error concerns, preferring the path the
+// command named over the one the operating system reported
+func errorPath(err error, fileErr *FileError, pathErr *fs.PathError) string {
+	if errors.As(err, &fileErr) && fileErr.Path != "" {
+		return fileErr.Path
+	}
+	if errors.As(err, &pathErr) {
+		return pathErr.Path
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/johncoder/jot/internal/access"
+	"github.com/johncoder/jot/internal/hooks"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestDescribeError(t *testing.T) {
+	doc := []byte("# Work\n\n# Frontend\n")
+	_, ambiguous := markdown.FindSubtree(markdown.ParseDocument(doc), doc, &markdown.HeadingPath{File: "work.md", Segments: []string{"o"}})
+	_, missing := markdown.FindSubtree(markdown.ParseDocument(doc), doc, &markdown.HeadingPath{File: "work.md", Segments: []string{"nope"}})
+	_, invalid := markdown.ParsePath("work.md")
+	_, notExist := os.ReadFile("/nonexistent/work.md")
+
+	tests := []struct {
+		name     string
+		err      error
+		code     string
+		category string
+		selector string
+		path     string
+	}{
+		{"ambiguous", fmt.Errorf("refile: %w", ambiguous), "ambiguous_selector", CategoryAmbiguous, "work.md#o", "work.md"},
+		{"no match", missing, "selector_not_found", CategoryNotFound, "work.md#nope", "work.md"},
+		{"invalid selector", invalid, "invalid_selector", CategoryValidation, "work.md", ""},
+		{"no workspace", fmt.Errorf("%w\nRun 'jot init'", workspace.ErrNoWorkspace), "workspace_not_found", CategoryNotFound, "", ""},
+		{"validation", NewValidationError("user", "a b", errors.New("no spaces")), "validation_error", CategoryValidation, "", ""},
+		{"missing file", NewFileError("read", "work.md", notExist), "file_not_found", CategoryNotFound, "", "work.md"},
+		{"io", NewFileError("write", "work.md", errors.New("disk full")), "io_error", CategoryIO, "", "work.md"},
+		{"hook", NewExternalError("pre-capture hook", nil, &hooks.AbortError{Type: hooks.PreCapture, Hook: "pre-capture.01", ExitCode: 1, Err: errors.New("hook pre-capture.01 failed with exit code 1")}), "hook_failure", CategoryHookAbort, "", ""},
+		{"denied", NewFileError("write", "lib/hr.md", &access.DeniedError{User: "dana", Path: "lib/hr.md", Want: access.Write}), "permission_denied", CategoryPermission, "", "lib/hr.md"},
+		{"unregistered workspace", fmt.Errorf("workspace 'work' %w", workspace.ErrNotRegistered), "workspace_not_found", CategoryNotFound, "", ""},
+		{"usage", NewUsageError(errors.New("provide a source file or --to destination")), "validation_error", CategoryValidation, "", ""},
+		{"file not found", fmt.Errorf("%w: work.md", ErrFileNotFound), "file_not_found", CategoryNotFound, "", ""},
+		{"not found", fmt.Errorf("template 'daily' %w", ErrNotFound), "not_found", CategoryNotFound, "", ""},
+		{"workspace", fmt.Errorf("%w: %w", workspace.ErrConfig, errors.New("bad JSON")), "workspace_error", CategoryUnknown, "", ""},
+		{"unknown", errors.New("something broke"), "unknown_error", CategoryUnknown, "", ""},
+		{"untyped not found", errors.New("workspace thing not found"), "unknown_error", CategoryUnknown, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc := DescribeError(tt.err)
+			if desc.Code != tt.code || desc.Category != tt.category {
+				t.Errorf("code, category = %s, %s; want %s, %s", desc.Code, desc.Category, tt.code, tt.category)
+			}
+			if desc.Selector != tt.selector || desc.Path != tt.path {
+				t.Errorf("selector, path = %q, %q; want %q, %q", desc.Selector, desc.Path, tt.selector, tt.path)
+			}
+			if desc.Message != tt.err.Error() {
+				t.Errorf("message = %q", desc.Message)
+			}
+		})
+	}
+
+	if matches := DescribeError(ambiguous).Details["matches"].([]string); len(matches) != 2 {
+		t.Errorf("matches = %v", matches)
+	}
+	if _, ok := DescribeError(NewUsageError(errors.New("give a file"))).Details["field"]; ok {
+		t.Error("usage error reported an empty field")
+	}
+}
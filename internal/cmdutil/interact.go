@@ -10,7 +10,7 @@ import (
 // ConfirmOperation prompts the user with a yes/no question and returns their response.
 // The prompt should be a complete question without the [y/N] suffix, which is added automatically.
 func ConfirmOperation(prompt string) (bool, error) {
-	fmt.Printf("%s [y/N]: ", prompt)
+	fmt.Printf("%s [y/N]: ", Text(prompt))
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -21,34 +21,32 @@ func ConfirmOperation(prompt string) (bool, error) {
 	return IsConfirmationYes(response), nil
 }
 
+// Status messages. Each prints one line through Printf, so icons in the
+// message are spelled out in plain mode.
+
 // ShowSuccess displays a success message with consistent formatting.
-// Message can include icons and will be printed as-is with formatting.
 func ShowSuccess(message string, args ...interface{}) {
-	fmt.Printf(message+"\n", args...)
+	Printf(message+"\n", args...)
 }
 
 // ShowError displays an error message with consistent formatting.
-// Message can include icons and will be printed as-is with formatting.
 func ShowError(message string, args ...interface{}) {
-	fmt.Printf(message+"\n", args...)
+	Printf(message+"\n", args...)
 }
 
 // ShowWarning displays a warning message with consistent formatting.
-// Message can include icons and will be printed as-is with formatting.
 func ShowWarning(message string, args ...interface{}) {
-	fmt.Printf(message+"\n", args...)
+	Printf(message+"\n", args...)
 }
 
 // ShowInfo displays an informational message with consistent formatting.
-// Message can include icons and will be printed as-is with formatting.
 func ShowInfo(message string, args ...interface{}) {
-	fmt.Printf(message+"\n", args...)
+	Printf(message+"\n", args...)
 }
 
 // ShowProgress displays a progress message with consistent formatting.
-// Message can include icons and will be printed as-is with formatting.
 func ShowProgress(message string, args ...interface{}) {
-	fmt.Printf(message+"\n", args...)
+	Printf(message+"\n", args...)
 }
 
 // NormalizeUserInput trims whitespace and converts input to lowercase for consistent processing.
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 )
 
@@ -156,7 +158,7 @@ func (p *PathUtil) JotDirJoin(relativePath string) string {
 
 // EnsureDir creates a directory if it doesn't exist with standard permissions (0755)
 func (p *PathUtil) EnsureDir(dirPath string) error {
-	return os.MkdirAll(dirPath, 0755)
+	return dryrun.MkdirAll(dirPath, 0755)
 }
 
 // EnsureDirForFile creates the parent directory for a file path if it doesn't exist
@@ -170,7 +172,7 @@ func (p *PathUtil) SafeWriteFile(filePath string, content []byte) error {
 	if err := p.EnsureDirForFile(filePath); err != nil {
 		return err
 	}
-	return os.WriteFile(filePath, content, 0644)
+	return dryrun.WriteFile(filePath, content, 0644)
 }
 
 // SafeAppendFile appends content to a file, creating parent directories as needed
@@ -179,14 +181,7 @@ func (p *PathUtil) SafeAppendFile(filePath string, content []byte) error {
 		return err
 	}
 
-	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write(content)
-	return err
+	return dryrun.AppendFile(filePath, content, 0644)
 }
 
 // ToAbsolute converts a path to an absolute path
@@ -235,7 +230,7 @@ func (p *PathUtil) SplitPath(path string) (dir, base, ext string) {
 
 // CreateBackupFile creates a backup of a file and returns the backup path
 func (p *PathUtil) CreateBackupFile(filePath string) (string, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := storage.ReadFile(filePath)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,65 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// plain makes text output friendly to screen readers: symbols are spelled
+// out or dropped, and no box-drawing or color is printed
+var plain bool
+
+// SetPlain turns plain output on or off
+func SetPlain(on bool) {
+	plain = on
+}
+
+// Plain reports whether plain output is on. Output that draws with symbols,
+// such as sparklines, should be left out when it is.
+func Plain() bool {
+	return plain
+}
+
+// plainReplacer spells out the symbols jot prints. Check marks and crosses
+// are dropped, since the text beside them says what happened; warning signs
+// become a word. Longer forms come first so "⚠️  Warning:" is not doubled.
+var plainReplacer = strings.NewReplacer(
+	"⚠️  Warning: ", "Warning: ",
+	"⚠️  ", "Warning: ",
+	"⚠ ", "Warning: ",
+	"⚠️", "(warning)",
+	"✓ ", "", "✅ ", "", "✗ ", "", "❌ ", "",
+	"✓", "(ok)",
+	"✗", "(failed)",
+	"  → ", "  ",
+	" → ", " to ",
+	"→ ", "",
+	"📋 ", "", "📤 ", "", "📥 ", "", "✨ ", "", "🚀 ", "", "📄 ", "",
+	"────────────────────────────────────────", "",
+)
+
+// Text returns s as it should be printed: unchanged, or with its symbols
+// spelled out in plain mode
+func Text(s string) string {
+	if !plain {
+		return s
+	}
+	return plainReplacer.Replace(s)
+}
+
+// Printf prints like fmt.Printf, through Text
+func Printf(format string, args ...interface{}) {
+	fmt.Print(Text(fmt.Sprintf(format, args...)))
+}
+
+// Println prints like fmt.Println, through Text
+func Println(args ...interface{}) {
+	fmt.Print(Text(fmt.Sprintln(args...)))
+}
+
+// Fprintf prints like fmt.Fprintf, through Text, for messages that go to
+// stderr to keep stdout to a command's result
+func Fprintf(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprint(w, Text(fmt.Sprintf(format, args...)))
+}
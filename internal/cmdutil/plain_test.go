@@ -0,0 +1,29 @@
+package cmdutil
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	defer SetPlain(false)
+
+	tests := map[string]string{
+		"✓ Created inbox.md":                    "Created inbox.md",
+		"Workspace health: ✗ Issues found":      "Workspace health: Issues found",
+		"⚠️  Warning: Found duplicate headings": "Warning: Found duplicate headings",
+		"⚠ NEEDS APPROVAL":                      "Warning: NEEDS APPROVAL",
+		"# Work ⚠️":                             "# Work (warning)",
+		"  → jot peek \"work.md#a\"":            "  jot peek \"work.md#a\"",
+		"  words        12 → 40":                "  words        12 to 40",
+		"\n🚀 Execute refile operation?":         "\nExecute refile operation?",
+		"Plain text is left alone…":             "Plain text is left alone…",
+	}
+	for in, want := range tests {
+		if got := Text(in); got != in {
+			t.Errorf("Text(%q) = %q with plain output off", in, got)
+		}
+		SetPlain(true)
+		if got := Text(in); got != want {
+			t.Errorf("Text(%q) = %q, want %q", in, got, want)
+		}
+		SetPlain(false)
+	}
+}
@@ -3,9 +3,10 @@ package cmdutil
 import (
 	"encoding/json"
 	"os"
-	"strings"
 	"time"
 
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/opstats"
 	"github.com/spf13/cobra"
 )
 
@@ -16,14 +17,33 @@ type JSONMetadata struct {
 	Command       string    `json:"command"`
 	ExecutionTime int64     `json:"execution_time_ms"`
 	Timestamp     time.Time `json:"timestamp"`
+
+	// DryRun lists the changes that were not written, when --dry-run is set
+	DryRun *DryRunReport `json:"dry_run,omitempty"`
+
+	// Metrics counts the I/O, parsing and hook work the command did
+	Metrics opstats.Stats `json:"metrics"`
+
+	// ForcedContent lists binary or very large content written because of
+	// --force
+	ForcedContent []ContentError `json:"forced_content,omitempty"`
+}
+
+// DryRunReport lists the writes skipped in dry-run mode
+type DryRunReport struct {
+	Changes []dryrun.Change `json:"changes"`
 }
 
 // JSONError represents an error in JSON format.
 // Compatible with existing cmd/json.go format.
 type JSONError struct {
-	Message string                 `json:"message"`
-	Code    string                 `json:"code"`
-	Details map[string]interface{} `json:"details,omitempty"`
+	Message  string                 `json:"message"`
+	Code     string                 `json:"code"`
+	Category string                 `json:"category,omitempty"` // validation, not_found, ambiguous, hook_abort, permission, io or unknown
+	Selector string                 `json:"selector,omitempty"` // The selector the error concerns
+	Path     string                 `json:"path,omitempty"`     // The file the error concerns
+	Hints    []string               `json:"hints,omitempty"`    // Ways to fix the error
+	Details  map[string]interface{} `json:"details,omitempty"`
 }
 
 // ResponseManager handles command output in both JSON and text formats.
@@ -56,12 +76,18 @@ func IsJSONOutput(cmd *cobra.Command) bool {
 // CreateJSONMetadata creates standard metadata for JSON responses.
 // Compatible with existing cmd/json.go format.
 func CreateJSONMetadata(cmd *cobra.Command, success bool, startTime time.Time) JSONMetadata {
-	return JSONMetadata{
+	metadata := JSONMetadata{
 		Success:       success,
 		Command:       cmd.CommandPath(),
 		ExecutionTime: time.Since(startTime).Milliseconds(),
 		Timestamp:     time.Now(),
+		Metrics:       opstats.Current(),
+		ForcedContent: forcedContent(),
+	}
+	if dryrun.Enabled() {
+		metadata.DryRun = &DryRunReport{Changes: dryrun.Changes()}
 	}
+	return metadata
 }
 
 // OutputJSON outputs a JSON response to stdout.
@@ -71,28 +97,24 @@ func OutputJSON(data interface{}) error {
 	return encoder.Encode(data)
 }
 
-// OutputJSONError outputs an error in JSON format.
+// errorReported records that an error has been written as JSON, so it is
+// not written again when the command returns it
+var errorReported bool
+
+// ErrorReported reports whether a command already wrote its error as JSON
+func ErrorReported() bool {
+	return errorReported
+}
+
+// OutputJSONError outputs an error in JSON format, described by DescribeError.
 // Compatible with existing cmd/json.go format.
 func OutputJSONError(cmd *cobra.Command, err error, startTime time.Time) error {
-	errorCode := "unknown_error"
-	details := map[string]interface{}{}
-
-	// Extract error details based on error type
-	if strings.Contains(err.Error(), "not found") {
-		errorCode = "not_found"
-	} else if strings.Contains(err.Error(), "workspace") {
-		errorCode = "workspace_error"
-	}
-
 	response := map[string]interface{}{
-		"error": JSONError{
-			Message: err.Error(),
-			Code:    errorCode,
-			Details: details,
-		},
+		"error":    DescribeError(err),
 		"metadata": CreateJSONMetadata(cmd, false, startTime),
 	}
 
+	errorReported = true
 	if jsonErr := OutputJSON(response); jsonErr != nil {
 		// If JSON output fails, fall back to regular error
 		return err
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/spf13/viper"
 	"github.com/titanous/json5"
 )
@@ -20,11 +21,26 @@ type Config struct {
 	// Default is the default workspace name to use when not in a local workspace
 	Default string `json:"default,omitempty"`
 
+	// Plain prints text without emoji, symbols or color, as --plain does
+	Plain bool `json:"plain,omitempty"`
+
+	// JSON makes every command print JSON, as --json does, for CI and bots
+	JSON bool `json:"json,omitempty"`
+
+	// Eval configures code evaluation for every workspace
+	Eval *EvalConfig `json:"eval,omitempty"`
+
 	// Legacy support for old configuration format
 	Locations       map[string]string `json:"locations,omitempty"`       // Deprecated
 	DefaultLocation string            `json:"defaultLocation,omitempty"` // Deprecated
 }
 
+// EvalConfig configures code evaluation
+type EvalConfig struct {
+	// Interpreters maps languages to command lines, such as "python": "uv run python"
+	Interpreters map[string]string `json:"interpreters,omitempty"`
+}
+
 var globalConfig *Config
 var configFilePath string
 
@@ -86,6 +102,12 @@ func Initialize(cfgFile string) error {
 	return nil
 }
 
+// FilePath returns the global configuration file in use, or "" when none
+// was found
+func FilePath() string {
+	return configFilePath
+}
+
 // readConfigFile attempts to read the configuration file
 func readConfigFile(v *viper.Viper) ([]byte, error) {
 	if configFilePath != "" {
@@ -275,12 +297,12 @@ func SaveConfig() error {
 	configJSON5 := formatConfigAsJSON5(cfg)
 
 	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(configFilePath), 0755); err != nil {
+	if err := dryrun.MkdirAll(filepath.Dir(configFilePath), 0755); err != nil {
 		return fmt.Errorf("unable to create config directory: %w", err)
 	}
 
 	// Write the config file
-	if err := os.WriteFile(configFilePath, []byte(configJSON5), 0644); err != nil {
+	if err := dryrun.WriteFile(configFilePath, []byte(configJSON5), 0644); err != nil {
 		return fmt.Errorf("unable to write config file: %w", err)
 	}
 
@@ -471,7 +493,7 @@ func saveConfig(cfg *Config) error {
 	// Convert to JSON5 with comments
 	configData := generateConfigJSON5(cfg)
 
-	if err := os.WriteFile(configPath, []byte(configData), 0644); err != nil {
+	if err := dryrun.WriteFile(configPath, []byte(configData), 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
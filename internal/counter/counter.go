@@ -0,0 +1,172 @@
+// Package counter reads and writes counter tables: markdown tables under a
+// heading that keep named counts, for habit tracking and tallies.
+//
+//	| Counter  | Count | Updated    |
+//	|----------|------:|------------|
+//	| exercise |    12 | 2024-06-12 |
+package counter
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Counter is one row of a counter table
+type Counter struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	Updated string `json:"updated,omitempty"` // Day of the last change, YYYY-MM-DD
+}
+
+// Table is a counter table found in a section's text
+type Table struct {
+	Start    int // Offset of the header row
+	End      int // Offset just past the last row and its newline
+	Counters []Counter
+}
+
+// Find returns the first counter table in text: a table whose header is
+// "Counter | Count", optionally followed by "Updated". It returns nil when
+// text has none, and an error when a count is not a whole number.
+func Find(text []byte) (*Table, error) {
+	lines := splitLines(text)
+	for i := 0; i+1 < len(lines); i++ {
+		if !isHeader(cells(lines[i].text)) || !isDelimiter(lines[i+1].text) {
+			continue
+		}
+
+		table := &Table{Start: lines[i].start, End: lines[i+1].end}
+		for _, line := range lines[i+2:] {
+			if !strings.HasPrefix(strings.TrimSpace(line.text), "|") {
+				break
+			}
+			row := cells(line.text)
+			if len(row) < 2 || row[0] == "" {
+				return nil, fmt.Errorf("counter table row '%s' needs a name and a count", strings.TrimSpace(line.text))
+			}
+			count, err := strconv.Atoi(row[1])
+			if err != nil {
+				return nil, fmt.Errorf("count of '%s' is '%s', not a whole number", row[0], row[1])
+			}
+			counter := Counter{Name: row[0], Count: count}
+			if len(row) > 2 {
+				counter.Updated = row[2]
+			}
+			table.Counters = append(table.Counters, counter)
+			table.End = line.end
+		}
+		return table, nil
+	}
+	return nil, nil
+}
+
+// Get returns the counter named name, matched without regard to case
+func (t *Table) Get(name string) *Counter {
+	for i := range t.Counters {
+		if strings.EqualFold(t.Counters[i].Name, name) {
+			return &t.Counters[i]
+		}
+	}
+	return nil
+}
+
+// Set sets the counter named name, adding it when missing, and returns it
+func (t *Table) Set(name string, count int, day string) *Counter {
+	counter := t.Get(name)
+	if counter == nil {
+		t.Counters = append(t.Counters, Counter{Name: name})
+		counter = &t.Counters[len(t.Counters)-1]
+	}
+	counter.Count = count
+	counter.Updated = day
+	return counter
+}
+
+// Render writes counters as a table with aligned columns
+func Render(counters []Counter) string {
+	rows := [][]string{{"Counter", "Count", "Updated"}}
+	for _, c := range counters {
+		rows = append(rows, []string{c.Name, strconv.Itoa(c.Count), c.Updated})
+	}
+	widths := []int{3, 3, 3}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	var b strings.Builder
+	for r, row := range rows {
+		b.WriteString("|")
+		for i, cell := range row {
+			if i == 1 && r > 0 {
+				fmt.Fprintf(&b, " %*s |", widths[i], cell)
+			} else {
+				fmt.Fprintf(&b, " %-*s |", widths[i], cell)
+			}
+		}
+		b.WriteString("\n")
+		if r == 0 {
+			fmt.Fprintf(&b, "|%s|%s:|%s|\n", strings.Repeat("-", widths[0]+2), strings.Repeat("-", widths[1]+1), strings.Repeat("-", widths[2]+2))
+		}
+	}
+	return b.String()
+}
+
+// line is a line of text and its byte range, including its newline
+type line struct {
+	text       string
+	start, end int
+}
+
+func splitLines(text []byte) []line {
+	var lines []line
+	for start := 0; start < len(text); {
+		end := len(text)
+		if i := bytes.IndexByte(text[start:], '\n'); i >= 0 {
+			end = start + i + 1
+		}
+		lines = append(lines, line{text: strings.TrimRight(string(text[start:end]), "\r\n"), start: start, end: end})
+		start = end
+	}
+	return lines
+}
+
+// cells splits a table row into trimmed cells
+func cells(row string) []string {
+	row = strings.TrimSpace(row)
+	if !strings.HasPrefix(row, "|") {
+		return nil
+	}
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	parts := strings.Split(row, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func isHeader(row []string) bool {
+	if len(row) < 2 || len(row) > 3 {
+		return false
+	}
+	if !strings.EqualFold(row[0], "counter") || !strings.EqualFold(row[1], "count") {
+		return false
+	}
+	return len(row) == 2 || strings.EqualFold(row[2], "updated")
+}
+
+func isDelimiter(row string) bool {
+	parts := cells(row)
+	if len(parts) < 2 {
+		return false
+	}
+	for _, part := range parts {
+		if strings.Trim(part, ":-") != "" || !strings.Contains(part, "-") {
+			return false
+		}
+	}
+	return true
+}
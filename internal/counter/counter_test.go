@@ -0,0 +1,46 @@
+package counter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindAndRender(t *testing.T) {
+	text := "## Habits\n\nSome text.\n\n| Counter | Count |\n|---|---|\n| exercise | 12 |\n| reading | 3 |\n\nAfter.\n"
+	table, err := Find([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Counter{{Name: "exercise", Count: 12}, {Name: "reading", Count: 3}}
+	if !reflect.DeepEqual(table.Counters, want) {
+		t.Errorf("Counters = %+v, want %+v", table.Counters, want)
+	}
+	if got := text[table.Start:table.End]; got != "| Counter | Count |\n|---|---|\n| exercise | 12 |\n| reading | 3 |\n" {
+		t.Errorf("table text = %q", got)
+	}
+
+	table.Set("Exercise", 13, "2024-06-12")
+	table.Set("meditation", 1, "2024-06-12")
+	wantTable := "| Counter    | Count | Updated    |\n" +
+		"|------------|------:|------------|\n" +
+		"| exercise   |    13 | 2024-06-12 |\n" +
+		"| reading    |     3 |            |\n" +
+		"| meditation |     1 | 2024-06-12 |\n"
+	if got := Render(table.Counters); got != wantTable {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, wantTable)
+	}
+
+	again, err := Find([]byte(wantTable))
+	if err != nil || len(again.Counters) != 3 || again.Counters[2].Updated != "2024-06-12" {
+		t.Errorf("Find(Render()) = %+v, %v", again, err)
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	if table, err := Find([]byte("| Name | Count |\n|---|---|\n| a | 1 |\n")); table != nil || err != nil {
+		t.Errorf("Find() of another table = %+v, %v", table, err)
+	}
+	if _, err := Find([]byte("| Counter | Count |\n|---|---|\n| a | many |\n")); err == nil {
+		t.Error("Find() accepted a count that is not a number")
+	}
+}
@@ -0,0 +1,295 @@
+// Package diff computes line-based differences between two texts and renders
+// them as unified or side-by-side output.
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind identifies how a line differs between the two texts
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// String returns the name used for a kind in JSON output
+func (k Kind) String() string {
+	switch k {
+	case Delete:
+		return "delete"
+	case Insert:
+		return "insert"
+	default:
+		return "equal"
+	}
+}
+
+// Line is one line of a diff. AIndex and BIndex are the 0-based positions of
+// the line in each text; for a line missing from one side they give the
+// position it would occupy there.
+type Line struct {
+	Kind   Kind
+	Text   string
+	AIndex int
+	BIndex int
+}
+
+// Hunk is a run of changes with surrounding context lines
+type Hunk struct {
+	AStart int // 1-based start line in the first text
+	ALines int
+	BStart int // 1-based start line in the second text
+	BLines int
+	Lines  []Line
+}
+
+// SplitLines splits text into lines, dropping the final newline
+func SplitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Lines computes a shortest edit script between a and b using Myers'
+// algorithm and returns every line of both texts in diff order
+func Lines(a, b []string) []Line {
+	n, m := len(a), len(b)
+	limit := n + m
+	v := make([]int, 2*limit+2)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[limit+k-1] < v[limit+k+1]) {
+				x = v[limit+k+1]
+			} else {
+				x = v[limit+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[limit+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, limit)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the recorded edit graph from the end to recover the script
+func backtrack(trace [][]int, a, b []string, offset int) []Line {
+	var lines []Line
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			lines = append(lines, Line{Kind: Equal, Text: a[x], AIndex: x, BIndex: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				lines = append(lines, Line{Kind: Insert, Text: b[y], AIndex: x, BIndex: y})
+			} else {
+				x--
+				lines = append(lines, Line{Kind: Delete, Text: a[x], AIndex: x, BIndex: y})
+			}
+		}
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// Changed reports whether a diff contains any insertions or deletions
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of deleted and inserted lines
+func Count(lines []Line) (deleted, inserted int) {
+	for _, line := range lines {
+		switch line.Kind {
+		case Delete:
+			deleted++
+		case Insert:
+			inserted++
+		}
+	}
+	return deleted, inserted
+}
+
+// Hunks groups changed lines with up to context unchanged lines around them.
+// Changes separated by no more than twice the context share a hunk.
+func Hunks(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	i := 0
+	for i < len(lines) {
+		if lines[i].Kind == Equal {
+			i++
+			continue
+		}
+
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Kind != Equal {
+				end = j + 1
+			} else if j-end+1 > 2*context {
+				break
+			}
+		}
+		stop := min(len(lines), end+context)
+
+		hunks = append(hunks, newHunk(lines[start:stop]))
+		i = stop
+	}
+	return hunks
+}
+
+func newHunk(lines []Line) Hunk {
+	h := Hunk{Lines: lines}
+	for _, line := range lines {
+		if line.Kind != Insert {
+			h.ALines++
+		}
+		if line.Kind != Delete {
+			h.BLines++
+		}
+	}
+
+	// Empty ranges point at the line before, as in GNU diff
+	h.AStart = lines[0].AIndex + 1
+	if h.ALines == 0 {
+		h.AStart--
+	}
+	h.BStart = lines[0].BIndex + 1
+	if h.BLines == 0 {
+		h.BStart--
+	}
+	return h
+}
+
+// Unified renders hunks in unified diff format
+func Unified(aName, bName string, hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", aName, bName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", formatRange(h.AStart, h.ALines), formatRange(h.BStart, h.BLines))
+		for _, line := range h.Lines {
+			switch line.Kind {
+			case Delete:
+				b.WriteString("-")
+			case Insert:
+				b.WriteString("+")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line.Text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func formatRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// SideBySide renders a diff in two columns fitting within width. Deleted
+// lines followed by inserted lines are paired as changes and marked "|";
+// unpaired lines are marked "<" or ">".
+func SideBySide(lines []Line, width int) string {
+	column := max((width-3)/2, 10)
+
+	var b strings.Builder
+	row := func(left, marker, right string) {
+		line := pad(truncate(left, column), column) + " " + marker + " " + truncate(right, column)
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteString("\n")
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Equal {
+			row(lines[i].Text, " ", lines[i].Text)
+			i++
+			continue
+		}
+
+		var deleted, inserted []string
+		for i < len(lines) && lines[i].Kind == Delete {
+			deleted = append(deleted, lines[i].Text)
+			i++
+		}
+		for i < len(lines) && lines[i].Kind == Insert {
+			inserted = append(inserted, lines[i].Text)
+			i++
+		}
+
+		for j := 0; j < max(len(deleted), len(inserted)); j++ {
+			switch {
+			case j < len(deleted) && j < len(inserted):
+				row(deleted[j], "|", inserted[j])
+			case j < len(deleted):
+				row(deleted[j], "<", "")
+			default:
+				row("", ">", inserted[j])
+			}
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", " a  b"},
+		{"empty to text", "", "a\n", "+a"},
+		{"text to empty", "a\n", "", "-a"},
+		{"insert middle", "a\nc\n", "a\nb\nc\n", " a +b  c"},
+		{"delete middle", "a\nb\nc\n", "a\nc\n", " a -b  c"},
+		{"replace", "a\nb\nc\n", "a\nx\nc\n", " a -b +x  c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parts []string
+			for _, line := range Lines(SplitLines(tt.a), SplitLines(tt.b)) {
+				prefix := map[Kind]string{Equal: " ", Delete: "-", Insert: "+"}[line.Kind]
+				parts = append(parts, prefix+line.Text)
+			}
+			if got := strings.Join(parts, " "); got != tt.want {
+				t.Errorf("Lines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := SplitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	b := SplitLines("1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\neleven\n")
+
+	got := Unified("a.md", "b.md", Hunks(Lines(a, b), 1))
+	want := `--- a.md
++++ b.md
+@@ -2,3 +2,3 @@
+ 2
+-3
++three
+ 4
+@@ -10 +10,2 @@
+ 10
++eleven
+`
+	if got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHunksMergeNearbyChanges(t *testing.T) {
+	a := SplitLines("1\n2\n3\n4\n5\n")
+	b := SplitLines("1\nx\n3\ny\n5\n")
+
+	hunks := Hunks(Lines(a, b), 1)
+	if len(hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(hunks))
+	}
+	if hunks[0].AStart != 1 || hunks[0].ALines != 5 || hunks[0].BLines != 5 {
+		t.Errorf("unexpected hunk range: %+v", hunks[0])
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	got := SideBySide(Lines(SplitLines("same\nold\ngone\n"), SplitLines("same\nnew\n")), 23)
+	want := "same         same\nold        | new\ngone       <\n"
+	if got != want {
+		t.Errorf("SideBySide() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+// Package dirconfig reads capture defaults scoped to a directory, so captures
+// aimed at a project's files pick up that project's template and layout.
+// Defaults come from a .jot/dirconfig file in the directory, or from the
+// frontmatter of the directory's index.md or README.md.
+package dirconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the directory config file, inside a .jot directory
+const FileName = "dirconfig"
+
+// indexFiles are the directory index files whose frontmatter is read, in order
+var indexFiles = []string{"index.md", "README.md"}
+
+// Capture holds the capture defaults for a directory. A field left empty is
+// taken from the nearest parent directory that sets it.
+type Capture struct {
+	Template   string `yaml:"template,omitempty" json:"template,omitempty"`       // Template for captures without one
+	RefileMode string `yaml:"refile_mode,omitempty" json:"refile_mode,omitempty"` // "append" or "prepend"
+	Heading    string `yaml:"heading,omitempty" json:"heading,omitempty"`         // Heading path for captures that name only a file, such as "log"
+
+	Sources []string `yaml:"-" json:"sources,omitempty"` // Files the defaults came from, relative to the root, nearest first
+}
+
+// settings is the shape of a dirconfig file and of index frontmatter
+type settings struct {
+	Capture Capture `yaml:"capture"`
+}
+
+// Lookup returns the capture defaults for file, which is under root. Each
+// directory from the file's own up to root is consulted, the nearest first;
+// within a directory, .jot/dirconfig wins over index frontmatter. Lookup
+// returns nil when no directory sets anything.
+func Lookup(root, file string) (*Capture, error) {
+	root = filepath.Clean(root)
+	dir := filepath.Dir(filepath.Clean(file))
+	if rel, err := filepath.Rel(root, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, nil
+	}
+
+	var merged Capture
+	for {
+		candidates := []string{filepath.Join(dir, ".jot", FileName)}
+		for _, name := range indexFiles {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+		for _, path := range candidates {
+			found, err := read(path)
+			if err != nil {
+				return nil, err
+			}
+			if found != nil && merged.fill(found) {
+				rel, _ := filepath.Rel(root, path)
+				merged.Sources = append(merged.Sources, filepath.ToSlash(rel))
+			}
+		}
+		if dir == root {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	if len(merged.Sources) == 0 {
+		return nil, nil
+	}
+	if err := merged.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", merged.Sources[0], err)
+	}
+	return &merged, nil
+}
+
+// fill sets c's empty fields from other, reporting whether any was set
+func (c *Capture) fill(other *Capture) bool {
+	filled := false
+	for _, field := range []struct{ dst, src *string }{
+		{&c.Template, &other.Template},
+		{&c.RefileMode, &other.RefileMode},
+		{&c.Heading, &other.Heading},
+	} {
+		if *field.dst == "" && *field.src != "" {
+			*field.dst = *field.src
+			filled = true
+		}
+	}
+	return filled
+}
+
+// validate checks the merged defaults
+func (c *Capture) validate() error {
+	if c.RefileMode != "" && c.RefileMode != "append" && c.RefileMode != "prepend" {
+		return fmt.Errorf("refile_mode must be 'append' or 'prepend', not '%s'", c.RefileMode)
+	}
+	return nil
+}
+
+// read returns the capture settings in a dirconfig or index file, or nil
+// when the file is missing or sets none
+func read(path string) (*Capture, error) {
+	data, err := storage.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Base(path) != FileName {
+		front, ok := frontmatter(string(data))
+		if !ok {
+			return nil, nil
+		}
+		data = []byte(front)
+	}
+
+	var s settings
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid capture settings in %s: %w", path, err)
+	}
+	if s.Capture.Template == "" && s.Capture.RefileMode == "" && s.Capture.Heading == "" {
+		return nil, nil
+	}
+	return &s.Capture, nil
+}
+
+// frontmatter returns the YAML frontmatter at the start of content
+func frontmatter(content string) (string, bool) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(content, "---\n") {
+		return "", false
+	}
+	front, _, found := strings.Cut(content[4:], "\n---")
+	return front, found
+}
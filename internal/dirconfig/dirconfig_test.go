@@ -0,0 +1,73 @@
+package dirconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".jot/dirconfig", "capture:\n  heading: Notes\n  refile_mode: append\n")
+	write("lib/projects/.jot/dirconfig", "capture:\n  template: project-log\n  heading: Log\n")
+	write("lib/projects/README.md", "---\ncapture:\n  template: ignored\n  refile_mode: prepend\n---\n# Projects\n")
+	write("lib/plain/index.md", "# No frontmatter\n")
+
+	tests := []struct {
+		name string
+		file string
+		want *Capture
+	}{
+		{
+			name: "nearest wins per field",
+			file: "lib/projects/x.md",
+			want: &Capture{
+				Template:   "project-log",
+				RefileMode: "prepend",
+				Heading:    "Log",
+				Sources:    []string{"lib/projects/.jot/dirconfig", "lib/projects/README.md"},
+			},
+		},
+		{
+			name: "workspace defaults",
+			file: "lib/plain/y.md",
+			want: &Capture{RefileMode: "append", Heading: "Notes", Sources: []string{".jot/dirconfig"}},
+		},
+		{
+			name: "outside root",
+			file: filepath.Join(filepath.Dir(root), "elsewhere.md"),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := tt.file
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(root, file)
+			}
+			got, err := Lookup(root, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	write("lib/bad/.jot/dirconfig", "capture:\n  refile_mode: sideways\n")
+	if _, err := Lookup(root, filepath.Join(root, "lib/bad/z.md")); err == nil {
+		t.Error("Lookup() accepted an invalid refile_mode")
+	}
+}
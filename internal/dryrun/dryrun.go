@@ -0,0 +1,346 @@
+// Package dryrun routes file writes through a single switch. When dry-run
+// mode is enabled, writes are recorded as planned changes instead of being
+// performed; otherwise they go to the current storage backend.
+package dryrun
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/johncoder/jot/internal/opstats"
+	"github.com/johncoder/jot/internal/storage"
+)
+
+// Change describes one write that would have been made
+type Change struct {
+	Path            string   `json:"path"`
+	Action          string   `json:"action"` // "create", "modify", "append", "remove" or "mkdir"
+	BytesInserted   int      `json:"bytes_inserted"`
+	BytesRemoved    int      `json:"bytes_removed"`
+	HeadingsCreated []string `json:"headings_created,omitempty"`
+}
+
+// PendingFile is the state a file would be left in by the recorded writes
+type PendingFile struct {
+	Path     string
+	Existed  bool   // Whether the file existed before the first write
+	Original []byte // Content before the first write
+	Content  []byte // Content after all writes
+	Removed  bool   // Whether the last change removed the file
+}
+
+var (
+	mu          sync.Mutex
+	enabled     bool
+	changes     []Change
+	pending     map[string]*PendingFile
+	pendingList []*PendingFile
+	directories []string
+
+	// guard vets the path of every write before it is made or recorded
+	guard func(path string) error
+)
+
+// Enable turns dry-run mode on or off and clears recorded changes
+func Enable(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = on
+	changes = nil
+	pending = make(map[string]*PendingFile)
+	pendingList = nil
+	directories = nil
+}
+
+// Enabled reports whether dry-run mode is on
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// SetGuard installs a check run on the path of every write, such as the
+// workspace's access policy. A nil check removes it.
+func SetGuard(check func(path string) error) {
+	mu.Lock()
+	defer mu.Unlock()
+	guard = check
+}
+
+// Check runs the guard on a path about to be written, so operations that
+// write several files can refuse before writing any
+func Check(path string) error {
+	mu.Lock()
+	check := guard
+	mu.Unlock()
+	if check == nil {
+		return nil
+	}
+	return check(path)
+}
+
+// Changes returns the changes recorded so far
+func Changes() []Change {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Change{}, changes...)
+}
+
+// Pending returns the final state of each file written, in order of first write
+func Pending() []PendingFile {
+	mu.Lock()
+	defer mu.Unlock()
+	files := make([]PendingFile, 0, len(pendingList))
+	for _, file := range pendingList {
+		files = append(files, *file)
+	}
+	return files
+}
+
+// Directories returns the directories that would be created
+func Directories() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]string{}, directories...)
+}
+
+// pendingFile returns the tracked state of path, loading it from disk on
+// first use. Callers must hold mu.
+func pendingFile(path string) (*PendingFile, error) {
+	if file, ok := pending[path]; ok {
+		return file, nil
+	}
+
+	file := &PendingFile{Path: path}
+	content, err := storage.ReadFile(path)
+	switch {
+	case err == nil:
+		file.Existed = true
+		file.Original = content
+		file.Content = content
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	pending[path] = file
+	pendingList = append(pendingList, file)
+	return file, nil
+}
+
+// WriteFile writes data to path, or records the change in dry-run mode
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if err := Check(path); err != nil {
+		return err
+	}
+	if !Enabled() {
+		if err := storage.Current().WriteFile(path, data, perm); err != nil {
+			return err
+		}
+		opstats.Write(path, len(data))
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	file, err := pendingFile(path)
+	if err != nil {
+		return err
+	}
+
+	action := "modify"
+	if file.Removed || (!file.Existed && file.Content == nil) {
+		action = "create"
+	}
+
+	inserted, removed := changedBytes(file.Content, data)
+	changes = append(changes, Change{
+		Path:            path,
+		Action:          action,
+		BytesInserted:   inserted,
+		BytesRemoved:    removed,
+		HeadingsCreated: newHeadings(string(file.Content), string(data)),
+	})
+	file.Content = append([]byte{}, data...)
+	file.Removed = false
+	return nil
+}
+
+// AppendFile appends data to path, creating it if needed, or records the
+// change in dry-run mode
+func AppendFile(path string, data []byte, perm os.FileMode) error {
+	if err := Check(path); err != nil {
+		return err
+	}
+	if !Enabled() {
+		if err := storage.Current().AppendFile(path, data, perm); err != nil {
+			return err
+		}
+		opstats.Write(path, len(data))
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	file, err := pendingFile(path)
+	if err != nil {
+		return err
+	}
+
+	action := "append"
+	if file.Removed || (!file.Existed && file.Content == nil) {
+		action = "create"
+	}
+
+	updated := append(append([]byte{}, file.Content...), data...)
+	changes = append(changes, Change{
+		Path:            path,
+		Action:          action,
+		BytesInserted:   len(data),
+		HeadingsCreated: newHeadings(string(file.Content), string(updated)),
+	})
+	file.Content = updated
+	file.Removed = false
+	return nil
+}
+
+// AppendFrom appends everything read from r to path, creating it if needed,
+// and returns the bytes appended. On the filesystem the data is copied
+// without being held in memory; in dry-run mode, or with another backend,
+// it is read in full and appended as AppendFile would.
+func AppendFrom(path string, r io.Reader, perm os.FileMode) (int64, error) {
+	if err := Check(path); err != nil {
+		return 0, err
+	}
+	if Enabled() || !storage.IsFilesystem() {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(data)), AppendFile(path, data, perm)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	opstats.Write(path, int(n))
+	return n, err
+}
+
+// Remove deletes the file at path, or records the removal in dry-run mode
+func Remove(path string) error {
+	if err := Check(path); err != nil {
+		return err
+	}
+	if !Enabled() {
+		if err := storage.Remove(path); err != nil {
+			return err
+		}
+		opstats.Write(path, 0)
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	file, err := pendingFile(path)
+	if err != nil {
+		return err
+	}
+	if file.Removed || (!file.Existed && file.Content == nil) {
+		return &os.PathError{Op: "remove", Path: path, Err: os.ErrNotExist}
+	}
+
+	changes = append(changes, Change{
+		Path:         path,
+		Action:       "remove",
+		BytesRemoved: len(file.Content),
+	})
+	file.Content = nil
+	file.Removed = true
+	return nil
+}
+
+// MkdirAll creates a directory tree, or records it in dry-run mode when the
+// directory does not exist yet
+func MkdirAll(path string, perm os.FileMode) error {
+	if !Enabled() {
+		return storage.Current().MkdirAll(path, perm)
+	}
+	if _, err := storage.Stat(path); !os.IsNotExist(err) {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, dir := range directories {
+		if dir == path {
+			return nil
+		}
+	}
+	directories = append(directories, path)
+	changes = append(changes, Change{Path: path, Action: "mkdir"})
+	return nil
+}
+
+// changedBytes measures the edit between old and new as the bytes removed
+// and inserted between their common prefix and suffix
+func changedBytes(old, new []byte) (inserted, removed int) {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	return len(new) - prefix - suffix, len(old) - prefix - suffix
+}
+
+// newHeadings returns the ATX headings in new that are not in old, counting
+// repeated headings separately
+func newHeadings(old, new string) []string {
+	seen := make(map[string]int)
+	for _, heading := range headings(old) {
+		seen[heading]++
+	}
+
+	var created []string
+	for _, heading := range headings(new) {
+		if seen[heading] > 0 {
+			seen[heading]--
+			continue
+		}
+		created = append(created, heading)
+	}
+	return created
+}
+
+func headings(content string) []string {
+	var result []string
+	inFence := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		if level <= 6 && (len(line) == level || line[level] == ' ' || line[level] == '\t') {
+			result = append(result, strings.TrimSpace(line))
+		}
+	}
+	return result
+}
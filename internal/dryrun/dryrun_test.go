@@ -0,0 +1,170 @@
+package dryrun
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChangedBytes(t *testing.T) {
+	tests := []struct {
+		old, new          string
+		inserted, removed int
+	}{
+		{"abc", "abc", 0, 0},
+		{"abc", "abXc", 1, 0},
+		{"abc", "ac", 0, 1},
+		{"", "hello", 5, 0},
+		{"hello", "jello", 1, 1},
+		{"aaa", "aaaa", 1, 0},
+	}
+
+	for _, tt := range tests {
+		inserted, removed := changedBytes([]byte(tt.old), []byte(tt.new))
+		if inserted != tt.inserted || removed != tt.removed {
+			t.Errorf("changedBytes(%q, %q) = %d, %d; want %d, %d", tt.old, tt.new, inserted, removed, tt.inserted, tt.removed)
+		}
+	}
+}
+
+func TestNewHeadings(t *testing.T) {
+	old := "# Notes\n\n## Todo\n"
+	new := "# Notes\n\n## Todo\n\n## Todo\n\n```\n# not a heading\n```\n### Done\n#hashtag\n"
+
+	got := newHeadings(old, new)
+	want := []string{"## Todo", "### Done"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newHeadings() = %v, want %v", got, want)
+	}
+}
+
+func TestDryRunDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Enable(true)
+	defer Enable(false)
+
+	if err := WriteFile(path, []byte("# Notes\n\n## Added\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendFile(filepath.Join(dir, "new.md"), []byte("text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "# Notes\n" {
+		t.Errorf("file was modified in dry-run mode: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.md")); !os.IsNotExist(err) {
+		t.Error("file was created in dry-run mode")
+	}
+
+	changes := Changes()
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d", len(changes))
+	}
+	if changes[0].Action != "modify" || changes[0].BytesInserted != 10 || !reflect.DeepEqual(changes[0].HeadingsCreated, []string{"## Added"}) {
+		t.Errorf("unexpected modify change: %+v", changes[0])
+	}
+	if changes[1].Action != "create" || changes[1].BytesInserted != 5 {
+		t.Errorf("unexpected create change: %+v", changes[1])
+	}
+	if changes[2].Action != "mkdir" {
+		t.Errorf("unexpected mkdir change: %+v", changes[2])
+	}
+}
+
+func TestPendingComposesWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Enable(true)
+	defer Enable(false)
+
+	_ = AppendFile(path, []byte("b\n"), 0644)
+	_ = WriteFile(path, []byte("a\nb\nc\n"), 0644)
+
+	files := Pending()
+	if len(files) != 1 {
+		t.Fatalf("expected 1 pending file, got %d", len(files))
+	}
+	if string(files[0].Original) != "a\n" || string(files[0].Content) != "a\nb\nc\n" || !files[0].Existed {
+		t.Errorf("unexpected pending file: %+v", files[0])
+	}
+
+	changes := Changes()
+	if changes[1].BytesInserted != 2 || changes[1].BytesRemoved != 0 {
+		t.Errorf("second write should be measured against the first: %+v", changes[1])
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Enable(true)
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove() = %v", err)
+	}
+	if err := Remove(path); !os.IsNotExist(err) {
+		t.Errorf("second Remove() = %v, want not-exist", err)
+	}
+	files := Pending()
+	if len(files) != 1 || !files[0].Removed || files[0].Content != nil {
+		t.Errorf("unexpected pending file: %+v", files)
+	}
+	if changes := Changes(); len(changes) != 1 || changes[0].Action != "remove" || changes[0].BytesRemoved != 2 {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+	Enable(false)
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dry run removed the file: %v", err)
+	}
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove() = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Remove(): %v", err)
+	}
+}
+
+func TestAppendFrom(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "inbox.md")
+	if err := os.WriteFile(path, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Enable(true)
+	n, err := AppendFrom(path, strings.NewReader("bc\n"), 0644)
+	if err != nil || n != 3 {
+		t.Fatalf("AppendFrom() = %d, %v", n, err)
+	}
+	if changes := Changes(); len(changes) != 1 || changes[0].Action != "append" || changes[0].BytesInserted != 3 {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+	Enable(false)
+
+	if n, err := AppendFrom(path, strings.NewReader("de\n"), 0644); err != nil || n != 3 {
+		t.Fatalf("AppendFrom() = %d, %v", n, err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "a\nde\n" {
+		t.Errorf("content = %q", content)
+	}
+}
@@ -13,6 +13,19 @@ import (
 // OpenEditor opens the configured editor with the given content
 // Returns the edited content and any error
 func OpenEditor(initialContent string) (string, error) {
+	return OpenEditorAt(initialContent, 0)
+}
+
+// lineArgEditors accept a "+LINE" argument before the file name
+var lineArgEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true,
+	"emacs": true, "emacsclient": true, "micro": true, "kak": true,
+}
+
+// OpenEditorAt opens the configured editor with the given content, placing
+// the cursor on line when the editor supports it. A line of 0 opens the
+// editor normally.
+func OpenEditorAt(initialContent string, line int) (string, error) {
 	// Create temporary file
 	tempFile, err := os.CreateTemp("", "jot-*.md")
 	if err != nil {
@@ -39,7 +52,11 @@ func OpenEditor(initialContent string) (string, error) {
 	}
 
 	// Prepare command with temp file
-	args := append(parts[1:], tempFile.Name())
+	args := parts[1:]
+	if line > 0 && lineArgEditors[filepath.Base(parts[0])] {
+		args = append(args, fmt.Sprintf("+%d", line))
+	}
+	args = append(args, tempFile.Name())
 	cmd := exec.Command(parts[0], args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -59,6 +76,30 @@ func OpenEditor(initialContent string) (string, error) {
 	return string(content), nil
 }
 
+// OpenFile opens path in the configured editor, placing the cursor on line
+// when the editor supports it. A line of 0 opens the file normally.
+func OpenFile(path string, line int) error {
+	parts := strings.Fields(config.GetEditor())
+	if len(parts) == 0 {
+		return fmt.Errorf("no editor configured")
+	}
+
+	args := parts[1:]
+	if line > 0 && lineArgEditors[filepath.Base(parts[0])] {
+		args = append(args, fmt.Sprintf("+%d", line))
+	}
+	args = append(args, path)
+	cmd := exec.Command(parts[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor command failed: %w", err)
+	}
+	return nil
+}
+
 // OpenPager opens the configured pager with the given content
 func OpenPager(content string) error {
 	if content == "" {
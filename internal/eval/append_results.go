@@ -2,21 +2,37 @@ package eval
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/storage"
 )
 
-// UpdateMarkdownWithResults updates the markdown file by inserting result blocks after eval links
-func UpdateMarkdownWithResults(filename string, results []*EvalResult) error {
-	input, err := os.ReadFile(filename)
+// UpdateMarkdownWithResults updates the markdown file by inserting result blocks after eval links.
+// With provenance, or for blocks with provenance="true", each result is preceded by a comment
+// recording when and how it was produced.
+func UpdateMarkdownWithResults(filename string, results []*EvalResult, provenance bool) error {
+	input, err := storage.ReadFile(filename)
 	if err != nil {
 		return err
 	}
 	lines := strings.Split(string(input), "\n")
+	interpreters := map[string]string{}
 
-	// Find eval links and insert results after them
+	// Work from the bottom of the file up, so inserting one result does
+	// not move the blocks still to be handled
+	ordered := make([]*EvalResult, 0, len(results))
 	for _, r := range results {
+		if r.Block != nil {
+			ordered = append(ordered, r)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Block.StartLine > ordered[j].Block.StartLine })
+
+	// Find eval links and insert results after them
+	for _, r := range ordered {
 		if r.Block == nil || r.Block.Eval == nil {
 			continue
 		}
@@ -64,6 +80,18 @@ func UpdateMarkdownWithResults(filename string, results []*EvalResult) error {
 		if formattedResult == "" {
 			continue // No result to insert
 		}
+		if len(r.Params) > 0 {
+			formattedResult = formatParamsComment(r.Params) + "\n" + formattedResult
+		}
+		if provenance || r.Block.Eval.Params["provenance"] == "true" {
+			lang := blockLanguage(r.Block)
+			interpreter, ok := interpreters[lang]
+			if !ok {
+				interpreter = interpreterVersion(lang)
+				interpreters[lang] = interpreter
+			}
+			formattedResult = formatProvenanceComment(newProvenance(r, interpreter)) + "\n" + formattedResult
+		}
 
 		// Handle different result insertion modes
 		// With new pattern (eval before code), results go after the code block
@@ -81,7 +109,7 @@ func UpdateMarkdownWithResults(filename string, results []*EvalResult) error {
 		}
 	}
 
-	return os.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+	return dryrun.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
 }
 
 // getResultsParam extracts the results parameter, defaulting to "code"
@@ -265,7 +293,7 @@ func formatAsFile(output string, params map[string]string, baseFilename string)
 	}
 
 	// Write output to file
-	err := os.WriteFile(outputPath, []byte(output), 0644)
+	err := dryrun.WriteFile(outputPath, []byte(output), 0644)
 	if err != nil {
 		return "", fmt.Errorf("failed to write output file: %v", err)
 	}
@@ -312,6 +340,9 @@ func replaceResultBlockAfterCode(lines []string, codeBlockEndIndex int, result s
 			}
 			// Remove this code block
 			lines = append(lines[:j], lines[k:]...)
+		} else if isResultComment(line) {
+			// Remove the parameters and provenance recorded for the old result
+			lines = append(lines[:j], lines[j+1:]...)
 		} else if isTableLine(line) {
 			// Found start of a markdown table, find its end
 			k := j
@@ -372,6 +403,8 @@ func appendResultBlockAfterCode(lines []string, codeBlockEndIndex int, result st
 				k++ // include closing ```
 			}
 			j = k
+		} else if isResultComment(line) {
+			j++
 		} else if isTableLine(line) {
 			// Found start of a markdown table, find its end
 			for j < len(lines) {
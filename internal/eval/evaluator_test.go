@@ -0,0 +1,43 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestConfiguredInterpreter(t *testing.T) {
+	m := &EvaluatorManager{
+		cache: map[string]*EvaluatorInfo{},
+		interpreters: map[string]workspace.Interpreter{
+			"shout":  {Language: "shout", Command: `sh -c "tr a-z A-Z"`, Source: "workspace"},
+			"python": {Language: "python", Command: "no-such-interpreter-for-jot --flag", Source: "global"},
+		},
+	}
+
+	info, err := m.DiscoverEvaluator("shout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != "configured" {
+		t.Errorf("type = %q, want configured", info.Type)
+	}
+	out, err := m.ExecuteWithEvaluator("shout", "hello", map[string]string{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out) != "HELLO" {
+		t.Errorf("output = %q", out)
+	}
+
+	// A configured interpreter that is missing is an error, not a fallback
+	if _, err := m.DiscoverEvaluator("python"); err == nil || !strings.Contains(err.Error(), "jot eval doctor") {
+		t.Errorf("missing interpreter: err = %v", err)
+	}
+
+	checks := m.CheckInterpreters()
+	if len(checks) != 2 || checks[0].Language != "python" || checks[0].Error == "" || checks[1].Error != "" {
+		t.Errorf("checks = %+v", checks)
+	}
+}
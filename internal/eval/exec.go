@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/johncoder/jot/internal/workspace"
 )
@@ -15,9 +17,17 @@ type EvalResult struct {
 	Block  *CodeBlock
 	Output string
 	Err    error
+	Params map[string]string // Values of the block's declared parameters
+
+	Started  time.Time
+	Duration time.Duration
+	ExitCode int
 }
 
-func ExecuteEvaluableBlocks(filename string) ([]*EvalResult, error) {
+// values supplies the blocks' declared parameters, by name. Up to jobs blocks
+// run at once; a block waits for those it is linked to (see blockLinks).
+// Results are returned in file order however the blocks were scheduled.
+func ExecuteEvaluableBlocks(filename string, values map[string]string, jobs int) ([]*EvalResult, error) {
 	blocks, err := ParseMarkdownForEvalBlocks(filename)
 	if err != nil {
 		return nil, err
@@ -35,20 +45,28 @@ func ExecuteEvaluableBlocks(filename string) ([]*EvalResult, error) {
 		return nil, err
 	}
 
-	var results []*EvalResult
+	var evalBlocks []*CodeBlock
 	for _, b := range blocks {
-		if b.Eval == nil {
-			continue
+		if b.Eval != nil {
+			evalBlocks = append(evalBlocks, b)
 		}
+	}
+	links, err := blockLinks(evalBlocks)
+	if err != nil {
+		return nil, err
+	}
 
+	results := make([]*EvalResult, len(evalBlocks))
+	var runnable []int
+	for i, b := range evalBlocks {
 		// Check security approval
 		approved, err := sm.CheckApproval(absPath, b)
 		if err != nil {
-			results = append(results, &EvalResult{
+			results[i] = &EvalResult{
 				Block:  b,
 				Output: "",
 				Err:    fmt.Errorf("security check failed: %w", err),
-			})
+			}
 			continue
 		}
 
@@ -57,22 +75,90 @@ func ExecuteEvaluableBlocks(filename string) ([]*EvalResult, error) {
 			if b.Eval.Params["name"] != "" {
 				blockName = b.Eval.Params["name"]
 			}
-			results = append(results, &EvalResult{
+			results[i] = &EvalResult{
 				Block:  b,
 				Output: "",
 				Err:    fmt.Errorf("code block '%s' requires approval", blockName),
-			})
+			}
 			continue
 		}
+		runnable = append(runnable, i)
+	}
 
-		output, err := executeBlock(b, filename)
-		results = append(results, &EvalResult{Block: b, Output: output, Err: err})
+	if jobs < 1 {
+		jobs = 1
+	}
+	done := make([]chan struct{}, len(evalBlocks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	for i := range results {
+		if results[i] != nil {
+			close(done[i])
+		}
 	}
+
+	// Workers take blocks in file order, so every block a worker waits on
+	// has already been taken by another worker
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(jobs, len(runnable)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				for _, j := range links[i] {
+					<-done[j]
+				}
+				results[i] = executeBlock(evalBlocks[i], filename, values)
+				close(done[i])
+			}
+		}()
+	}
+	for _, i := range runnable {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
 	return results, nil
 }
 
-// ExecuteEvaluableBlockByName executes a specific evaluable code block by name
-func ExecuteEvaluableBlockByName(filename, name string) ([]*EvalResult, error) {
+// blockLinks returns, for each block, the earlier blocks it must wait for:
+// those it names in depends="a,b", and the previous block with the same
+// session="..."
+func blockLinks(blocks []*CodeBlock) ([][]int, error) {
+	links := make([][]int, len(blocks))
+	byName := map[string]int{}
+	lastInSession := map[string]int{}
+	for i, b := range blocks {
+		for _, dep := range strings.Split(b.Eval.Params["depends"], ",") {
+			dep = strings.TrimSpace(dep)
+			if dep == "" {
+				continue
+			}
+			j, ok := byName[dep]
+			if !ok {
+				return nil, fmt.Errorf("block '%s' depends on '%s', which is not an earlier block", b.Eval.GetName(), dep)
+			}
+			links[i] = append(links[i], j)
+		}
+		if session := b.Eval.Params["session"]; session != "" {
+			if j, ok := lastInSession[session]; ok {
+				links[i] = append(links[i], j)
+			}
+			lastInSession[session] = i
+		}
+		if name := b.Eval.GetName(); name != "" {
+			byName[name] = i
+		}
+	}
+	return links, nil
+}
+
+// ExecuteEvaluableBlockByName executes a specific evaluable code block by name,
+// with values supplying its declared parameters
+func ExecuteEvaluableBlockByName(filename, name string, values map[string]string) ([]*EvalResult, error) {
 	blocks, err := ParseMarkdownForEvalBlocks(filename)
 	if err != nil {
 		return nil, err
@@ -120,8 +206,7 @@ func ExecuteEvaluableBlockByName(filename, name string) ([]*EvalResult, error) {
 			break
 		}
 
-		output, err := executeBlock(b, filename)
-		results = append(results, &EvalResult{Block: b, Output: output, Err: err})
+		results = append(results, executeBlock(b, filename, values))
 	}
 	if len(results) == 0 {
 		return nil, fmt.Errorf("no evaluable block found with name '%s'", name)
@@ -130,19 +215,41 @@ func ExecuteEvaluableBlockByName(filename, name string) ([]*EvalResult, error) {
 }
 
 // executeBlock runs the code block using the new evaluator system
-func executeBlock(b *CodeBlock, filename string) (string, error) {
-	lang := b.Lang
-	if shell, ok := b.Eval.Params["shell"]; ok && shell != "" {
-		lang = shell
+func executeBlock(b *CodeBlock, filename string, values map[string]string) *EvalResult {
+	declared, err := b.DeclaredParams()
+	if err != nil {
+		return &EvalResult{Block: b, Err: err}
 	}
+	used := paramValues(declared, values)
+	started := time.Now()
+	output, err := runBlock(b, filename, used)
+	return &EvalResult{
+		Block:    b,
+		Output:   output,
+		Err:      err,
+		Params:   used,
+		Started:  started,
+		Duration: time.Since(started),
+		ExitCode: exitCode(err),
+	}
+}
 
-	// Try to get workspace context for enhanced features
-	var manager *EvaluatorManager
-	if ws, err := workspace.GetWorkspaceContext(false); err == nil && ws != nil {
-		manager = NewEvaluatorManagerWithWorkspace(ws)
-	} else {
-		manager = NewEvaluatorManager()
+// blockLanguage returns the language a block runs as: its shell
+// attribute, or its fence's language
+func blockLanguage(b *CodeBlock) string {
+	if shell, ok := b.Eval.Params["shell"]; ok && shell != "" {
+		return shell
 	}
+	return b.Lang
+}
+
+// runBlock runs the code block with its parameter values set in its
+// environment
+func runBlock(b *CodeBlock, filename string, vars map[string]string) (string, error) {
+	lang := blockLanguage(b)
+
+	manager := newBlockManager()
+	manager.SetVariables(vars)
 
 	// Set working directory - default to file's directory (org-mode behavior)
 	workingDir := filepath.Dir(filename)
@@ -166,6 +273,15 @@ func executeBlock(b *CodeBlock, filename string) (string, error) {
 	return output, nil
 }
 
+// newBlockManager returns an evaluator manager with the current workspace's
+// context and interpreters, when there is a workspace
+func newBlockManager() *EvaluatorManager {
+	if ws, err := workspace.GetWorkspaceContext(false); err == nil && ws != nil {
+		return NewEvaluatorManagerWithWorkspace(ws)
+	}
+	return NewEvaluatorManager()
+}
+
 // EvaluatorError represents an error from the evaluator system
 type EvaluatorError struct {
 	Language string
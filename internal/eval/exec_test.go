@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlockLinks(t *testing.T) {
+	blocks := []*CodeBlock{
+		{Eval: &EvalMetadata{Params: map[string]string{"name": "setup", "session": "db"}}},
+		{Eval: &EvalMetadata{Params: map[string]string{"name": "free"}}},
+		{Eval: &EvalMetadata{Params: map[string]string{"name": "query", "session": "db"}}},
+		{Eval: &EvalMetadata{Params: map[string]string{"name": "report", "depends": "free, query"}}},
+	}
+	links, err := blockLinks(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{nil, nil, {0}, {1, 2}}
+	for i := range want {
+		if len(links[i]) != len(want[i]) {
+			t.Errorf("links[%d] = %v, want %v", i, links[i], want[i])
+			continue
+		}
+		for k := range want[i] {
+			if links[i][k] != want[i][k] {
+				t.Errorf("links[%d] = %v, want %v", i, links[i], want[i])
+			}
+		}
+	}
+
+	blocks[0].Eval.Params["depends"] = "report"
+	if _, err := blockLinks(blocks); err == nil {
+		t.Errorf("a dependency on a later block was accepted")
+	}
+}
+
+func TestExecuteEvaluableBlocksWithJobs(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, ".jot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	note := filepath.Join(dir, "slow.md")
+	var content strings.Builder
+	content.WriteString("# Slow\n")
+	for _, name := range []string{"a", "b", "c"} {
+		content.WriteString("\n<eval name=\"" + name + "\" />\n```bash\nsleep 0.3; echo " + name + "\n```\n")
+	}
+	content.WriteString("\n<eval name=\"d\" depends=\"c\" />\n```bash\necho d\n```\n")
+	if err := os.WriteFile(note, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sm, err := NewSecurityManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.ApproveDocument(note, ApprovalModeAlways); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	results, err := ExecuteEvaluableBlocks(note, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Errorf("three 0.3s blocks with 3 jobs took %s", elapsed)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for i, name := range []string{"a", "b", "c", "d"} {
+		if results[i].Err != nil {
+			t.Fatalf("%s failed: %v", name, results[i].Err)
+		}
+		if results[i].Block.Eval.GetName() != name || strings.TrimSpace(results[i].Output) != name {
+			t.Errorf("results[%d] = %s: %q", i, results[i].Block.Eval.GetName(), results[i].Output)
+		}
+	}
+	if !results[3].Started.After(results[2].Started.Add(results[2].Duration)) {
+		t.Errorf("d started before c, which it depends on, finished")
+	}
+}
@@ -3,6 +3,8 @@ package eval
 import (
 	"fmt"
 	"path/filepath"
+
+	"github.com/johncoder/jot/internal/cmdutil"
 )
 
 // ListEvalBlocks lists all evaluable code blocks in a markdown file with approval status
@@ -41,7 +43,7 @@ func ListEvalBlocks(filename string) error {
 				status = "⚠ NEEDS APPROVAL"
 			}
 
-			fmt.Printf("  %s (lines %d-%d) %s - %s\n",
+			cmdutil.Printf("  %s (lines %d-%d) %s - %s\n",
 				blockName, b.StartLine, b.EndLine, b.Lang, status)
 		}
 	}
@@ -0,0 +1,97 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Param is a parameter a recipe or eval block declares with its params
+// attribute. Its value reaches the code as an environment variable.
+type Param struct {
+	Name       string `json:"name"`
+	Default    string `json:"default,omitempty"`
+	HasDefault bool   `json:"has_default"`
+}
+
+// paramNamePattern keeps parameter names usable as environment variables
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// paramsCommentPrefix starts the comment that records the parameters a
+// result was produced with
+const paramsCommentPrefix = "<!-- params:"
+
+// ParseParams reads a params attribute: comma-separated names, each with an
+// optional =default
+func ParseParams(spec string) ([]Param, error) {
+	var params []Param
+	seen := map[string]bool{}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, value, hasDefault := strings.Cut(field, "=")
+		name = strings.TrimSpace(name)
+		if !paramNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid parameter name %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("parameter %q is declared twice", name)
+		}
+		seen[name] = true
+		params = append(params, Param{Name: name, Default: strings.TrimSpace(value), HasDefault: hasDefault})
+	}
+	return params, nil
+}
+
+// DeclaredParams returns the parameters an eval block declares
+func (b *CodeBlock) DeclaredParams() ([]Param, error) {
+	if b.Eval == nil {
+		return nil, nil
+	}
+	params, err := ParseParams(b.Eval.Params["params"])
+	if err != nil {
+		return nil, fmt.Errorf("block '%s': %w", b.Eval.GetName(), err)
+	}
+	return params, nil
+}
+
+// paramValues picks the values of a block's declared parameters out of
+// values
+func paramValues(params []Param, values map[string]string) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	used := make(map[string]string, len(params))
+	for _, p := range params {
+		used[p.Name] = values[p.Name]
+	}
+	return used
+}
+
+// formatParamsComment records parameter values as an HTML comment, so they
+// sit beside a result without showing when the note is rendered
+func formatParamsComment(values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(paramsCommentPrefix)
+	for _, name := range names {
+		value := strings.ReplaceAll(strconv.Quote(values[name]), "--", `-\x2d`)
+		fmt.Fprintf(&b, " %s=%s", name, value)
+	}
+	b.WriteString(" -->")
+	return b.String()
+}
+
+// isParamsComment returns true if the line records a result's parameters
+func isParamsComment(line string) bool {
+	return strings.HasPrefix(line, paramsCommentPrefix) && strings.HasSuffix(line, "-->")
+}
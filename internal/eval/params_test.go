@@ -0,0 +1,93 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseParams(t *testing.T) {
+	params, err := ParseParams("env, version=latest, note=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Param{
+		{Name: "env"},
+		{Name: "version", Default: "latest", HasDefault: true},
+		{Name: "note", HasDefault: true},
+	}
+	if len(params) != len(want) {
+		t.Fatalf("got %d params, want %d", len(params), len(want))
+	}
+	for i := range want {
+		if params[i] != want[i] {
+			t.Errorf("params[%d] = %+v, want %+v", i, params[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{"bad-name", "env, env", "1st"} {
+		if _, err := ParseParams(spec); err == nil {
+			t.Errorf("ParseParams(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestBlockParamsReachCodeAndResults(t *testing.T) {
+	dir := t.TempDir()
+	note := filepath.Join(dir, "ops.md")
+	content := "# Ops\n\n" +
+		"<eval name=\"where\" params=\"env, region=eu\" />\n```bash\necho \"$env-$region\"\n```\n\nAfter.\n"
+	if err := os.WriteFile(note, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := ParseMarkdownForEvalBlocks(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("got %d blocks, want 1", len(blocks))
+	}
+
+	for _, env := range []string{"staging", "prod"} {
+		result := executeBlock(blocks[0], note, map[string]string{"env": env, "region": "eu", "other": "x"})
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		if got := strings.TrimSpace(result.Output); got != env+"-eu" {
+			t.Errorf("output = %q, want %q", got, env+"-eu")
+		}
+		if len(result.Params) != 2 {
+			t.Errorf("params = %v, want only the declared ones", result.Params)
+		}
+		if err := UpdateMarkdownWithResults(note, []*EvalResult{result}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if n := strings.Count(got, paramsCommentPrefix); n != 1 {
+		t.Errorf("got %d params comments, want 1:\n%s", n, got)
+	}
+	if !strings.Contains(got, "<!-- params: env=\"prod\" region=\"eu\" -->\n```\nprod-eu\n```") {
+		t.Errorf("result not recorded with its params:\n%s", got)
+	}
+	if strings.Contains(got, "staging") {
+		t.Errorf("old result was not replaced:\n%s", got)
+	}
+}
+
+func TestFormatParamsComment(t *testing.T) {
+	got := formatParamsComment(map[string]string{"b": "x --> y", "a": ""})
+	if got != `<!-- params: a="" b="x -\x2d> y" -->` {
+		t.Errorf("formatParamsComment = %s", got)
+	}
+	if !isParamsComment(got) {
+		t.Errorf("isParamsComment(%s) = false", got)
+	}
+}
@@ -0,0 +1,241 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/storage"
+)
+
+// Provenance records how a result was produced, so readers can tell how
+// fresh it is and whether the block changed since
+type Provenance struct {
+	Ran         time.Time     `json:"ran"`
+	Duration    time.Duration `json:"duration_ns"`
+	ExitCode    int           `json:"exit_code"`
+	Interpreter string        `json:"interpreter,omitempty"`
+	Source      string        `json:"source"`           // Hash of the block's code
+	Params      string        `json:"params,omitempty"` // Hash of its parameter values
+}
+
+// provenanceCommentPrefix starts the comment that records a result's
+// provenance
+const provenanceCommentPrefix = "<!-- provenance:"
+
+// Block states reported by Status
+const (
+	StatusCurrent    = "current"
+	StatusChanged    = "changed"
+	StatusUnrecorded = "unrecorded"
+)
+
+// BlockStatus says whether a block's recorded result still matches its code
+type BlockStatus struct {
+	Name       string      `json:"name"`
+	Line       int         `json:"line"`
+	State      string      `json:"state"`
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// SourceHash returns a short hash of a block's code
+func SourceHash(b *CodeBlock) string {
+	return shortHash(strings.Join(b.Code, "\n"))
+}
+
+// paramsHash returns a short hash of parameter values, or "" when there
+// are none
+func paramsHash(values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, values[name])
+	}
+	return shortHash(b.String())
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// exitCode returns the exit code a block's run ended with: 0 on success,
+// -1 when it did not exit on its own, such as on a timeout
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	var evaluatorErr *evaluatorExitError
+	if errors.As(err, &evaluatorErr) {
+		return evaluatorErr.code
+	}
+	return -1
+}
+
+// interpreterVersion describes what runs a language: the version a
+// built-in interpreter reports, or the command of any other evaluator
+func interpreterVersion(lang string) string {
+	manager := newBlockManager()
+	evaluator, err := manager.DiscoverEvaluator(lang)
+	if err != nil {
+		return ""
+	}
+	if evaluator.Type != "built-in" {
+		return evaluator.Command // A PATH evaluator's name, or a configured command line
+	}
+
+	command, _ := manager.getBuiltinInterpreter(lang)
+	arg := "--version"
+	if command == "go" {
+		arg = "version"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, command, arg).CombinedOutput()
+	if err != nil {
+		return command
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return command
+}
+
+// newProvenance describes how a result was produced
+func newProvenance(r *EvalResult, interpreter string) *Provenance {
+	return &Provenance{
+		Ran:         r.Started,
+		Duration:    r.Duration,
+		ExitCode:    r.ExitCode,
+		Interpreter: interpreter,
+		Source:      SourceHash(r.Block),
+		Params:      paramsHash(r.Params),
+	}
+}
+
+// formatProvenanceComment records provenance as an HTML comment
+func formatProvenanceComment(p *Provenance) string {
+	clean := strings.NewReplacer(`"`, "", "'", "", "--", "-")
+	var b strings.Builder
+	b.WriteString(provenanceCommentPrefix)
+	fmt.Fprintf(&b, " ran=%q", p.Ran.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, " duration=%q", p.Duration.Round(time.Millisecond).String())
+	fmt.Fprintf(&b, " exit=\"%d\"", p.ExitCode)
+	if p.Interpreter != "" {
+		fmt.Fprintf(&b, " interpreter=\"%s\"", clean.Replace(p.Interpreter))
+	}
+	fmt.Fprintf(&b, " source=%q", p.Source)
+	if p.Params != "" {
+		fmt.Fprintf(&b, " params=%q", p.Params)
+	}
+	b.WriteString(" -->")
+	return b.String()
+}
+
+// parseProvenanceComment reads a provenance comment back
+func parseProvenanceComment(line string) (*Provenance, error) {
+	if !isProvenanceComment(line) {
+		return nil, fmt.Errorf("not a provenance comment")
+	}
+	attrs, err := parseHTMLAttributes(strings.TrimSuffix(strings.TrimPrefix(line, provenanceCommentPrefix), "-->"))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provenance{
+		Interpreter: attrs["interpreter"],
+		Source:      attrs["source"],
+		Params:      attrs["params"],
+	}
+	if p.Source == "" {
+		return nil, fmt.Errorf("provenance comment has no source hash")
+	}
+	p.Ran, _ = time.Parse(time.RFC3339, attrs["ran"])
+	p.Duration, _ = time.ParseDuration(attrs["duration"])
+	p.ExitCode, _ = strconv.Atoi(attrs["exit"])
+	return p, nil
+}
+
+// isProvenanceComment returns true if the line records a result's
+// provenance
+func isProvenanceComment(line string) bool {
+	return strings.HasPrefix(line, provenanceCommentPrefix) && strings.HasSuffix(line, "-->")
+}
+
+// isResultComment returns true if the line is one of the comments jot
+// writes above a result
+func isResultComment(line string) bool {
+	return isParamsComment(line) || isProvenanceComment(line)
+}
+
+// recordedProvenance finds the provenance recorded with the result that
+// follows a code block, if any
+func recordedProvenance(lines []string, b *CodeBlock) *Provenance {
+	for i := b.EndLine; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+			continue
+		case isProvenanceComment(line):
+			if p, err := parseProvenanceComment(line); err == nil {
+				return p
+			}
+		case isResultComment(line):
+			continue
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Status reports, for each named eval block in a file, whether the code
+// changed since its last recorded result
+func Status(filename string) ([]BlockStatus, error) {
+	blocks, err := ParseMarkdownForEvalBlocks(filename)
+	if err != nil {
+		return nil, err
+	}
+	content, err := storage.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(content), "\n")
+
+	var statuses []BlockStatus
+	for _, b := range blocks {
+		if b.Eval == nil || b.Eval.GetName() == "" {
+			continue
+		}
+		status := BlockStatus{Name: b.Eval.GetName(), Line: b.StartLine, State: StatusUnrecorded}
+		if p := recordedProvenance(lines, b); p != nil {
+			status.Provenance = p
+			status.State = StatusCurrent
+			if p.Source != SourceHash(b) {
+				status.State = StatusChanged
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvenanceCommentRoundTrip(t *testing.T) {
+	want := &Provenance{
+		Ran:         time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		Duration:    1500 * time.Millisecond,
+		ExitCode:    2,
+		Interpreter: `Python "3.12" -- build`,
+		Source:      "0123456789ab",
+		Params:      "ba9876543210",
+	}
+	comment := formatProvenanceComment(want)
+	if !isResultComment(comment) {
+		t.Fatalf("isResultComment(%s) = false", comment)
+	}
+
+	got, err := parseProvenanceComment(comment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Ran.Equal(want.Ran) || got.Duration != want.Duration || got.ExitCode != want.ExitCode ||
+		got.Source != want.Source || got.Params != want.Params {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+	if got.Interpreter != "Python 3.12 - build" {
+		t.Errorf("interpreter = %q", got.Interpreter)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(nil); got != 0 {
+		t.Errorf("exitCode(nil) = %d", got)
+	}
+	if got := exitCode(&evaluatorExitError{code: 4, msg: "failed"}); got != 4 {
+		t.Errorf("exitCode(evaluator exit 4) = %d", got)
+	}
+	if got := exitCode(errors.New("command timed out")); got != -1 {
+		t.Errorf("exitCode(timeout) = %d", got)
+	}
+}
+
+func TestStatusAfterResultsWithProvenance(t *testing.T) {
+	dir := t.TempDir()
+	note := filepath.Join(dir, "ops.md")
+	content := "# Ops\n\n" +
+		"<eval name=\"one\" />\n```bash\necho one\n```\n\n" +
+		"<eval name=\"two\" />\n```bash\necho two\n```\n\n" +
+		"<eval name=\"three\" />\n```bash\necho three\n```\n"
+	if err := os.WriteFile(note, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := ParseMarkdownForEvalBlocks(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []*EvalResult
+	for _, b := range blocks[:2] {
+		results = append(results, &EvalResult{Block: b, Output: b.Eval.GetName() + "\n", Started: time.Now()})
+	}
+	if err := UpdateMarkdownWithResults(note, results, true); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"one", "two"} {
+		if !strings.Contains(string(data), "\n```\n"+name+"\n```") {
+			t.Errorf("no result for %s:\n%s", name, data)
+		}
+	}
+
+	changed := strings.Replace(string(data), "echo two", "echo 2", 1)
+	if err := os.WriteFile(note, []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := Status(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"one": StatusCurrent, "two": StatusChanged, "three": StatusUnrecorded}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(want))
+	}
+	for _, status := range statuses {
+		if status.State != want[status.Name] {
+			t.Errorf("%s is %s, want %s", status.Name, status.State, want[status.Name])
+		}
+	}
+}
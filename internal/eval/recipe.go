@@ -0,0 +1,190 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/safemode"
+)
+
+// Recipe is a fenced shell block marked to be run by hand with jot run:
+//
+//	<recipe name="deploy" params="env, version=latest" description="Ship a build" />
+//	```bash
+//	./deploy.sh "$env" "$version"
+//	```
+//
+// Declared parameters reach the script as environment variables.
+type Recipe struct {
+	Name        string
+	Description string
+	Params      []Param
+	Block       *CodeBlock
+}
+
+// recipeShells are the languages a recipe may be written in, with the
+// shell that runs each
+var recipeShells = map[string]string{
+	"":        "sh",
+	"sh":      "sh",
+	"shell":   "sh",
+	"console": "sh",
+	"bash":    "bash",
+	"zsh":     "zsh",
+	"fish":    "fish",
+}
+
+// IsRecipeElement returns true if the given line is a recipe element
+func IsRecipeElement(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "<recipe") && strings.Contains(line, "/>")
+}
+
+// ParseRecipeElement parses an HTML recipe element of the form
+// <recipe name="deploy" params="env, version=latest" />
+func ParseRecipeElement(element string) (*EvalMetadata, error) {
+	element = strings.TrimSpace(element)
+	if !strings.HasPrefix(element, "<recipe") || !strings.HasSuffix(element, "/>") {
+		return nil, fmt.Errorf("not a valid recipe element")
+	}
+
+	params, err := parseHTMLAttributes(element[len("<recipe") : len(element)-2])
+	if err != nil {
+		return nil, err
+	}
+	return &EvalMetadata{Params: params}, nil
+}
+
+// FindRecipes returns the recipes in a markdown file, in order
+func FindRecipes(filename string) ([]*Recipe, error) {
+	blocks, err := ParseMarkdownForEvalBlocks(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipes []*Recipe
+	for _, b := range blocks {
+		if b.Recipe == nil {
+			continue
+		}
+		name := b.Recipe.GetName()
+		if name == "" {
+			return nil, fmt.Errorf("recipe at line %d has no name", b.StartLine)
+		}
+		params, err := ParseParams(b.Recipe.Params["params"])
+		if err != nil {
+			return nil, fmt.Errorf("recipe '%s': %w", name, err)
+		}
+		recipes = append(recipes, &Recipe{
+			Name:        name,
+			Description: b.Recipe.Params["description"],
+			Params:      params,
+			Block:       b,
+		})
+	}
+	return recipes, nil
+}
+
+// FindRecipe returns the recipe called name in a markdown file
+func FindRecipe(filename, name string) (*Recipe, error) {
+	recipes, err := FindRecipes(filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range recipes {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no recipe named '%s' found in %s", name, filename)
+}
+
+// Shell returns the shell that runs the recipe: its shell attribute, or the
+// one its block's language names
+func (r *Recipe) Shell() (string, error) {
+	if shell := r.Block.Recipe.Params["shell"]; shell != "" {
+		return shell, nil
+	}
+	shell, ok := recipeShells[r.Block.Lang]
+	if !ok {
+		return "", fmt.Errorf("recipe '%s' is a %s block; recipes must be shell blocks (or set shell=\"...\")", r.Name, r.Block.Lang)
+	}
+	return shell, nil
+}
+
+// Code returns the recipe's script
+func (r *Recipe) Code() string {
+	return strings.Join(r.Block.Code, "\n")
+}
+
+// CheckApproval reports whether the recipe is approved to run
+func (r *Recipe) CheckApproval(sm *SecurityManager, filename string) (bool, error) {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return false, err
+	}
+	return sm.CheckApproval(absPath, r.Block)
+}
+
+// Approve approves the recipe in mode
+func (r *Recipe) Approve(sm *SecurityManager, filename string, mode ApprovalMode) error {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	return sm.ApproveBlock(absPath, r.Block, mode)
+}
+
+// Run runs the recipe with the given parameter values attached to the
+// terminal, so it can prompt and stream its output. It runs in the note's
+// directory unless the recipe sets cwd, and stops after the recipe's
+// timeout when it sets one.
+func (r *Recipe) Run(filename string, values map[string]string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if err := safemode.Check("recipes"); err != nil {
+		return err
+	}
+	shell, err := r.Shell()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if timeoutStr := r.Block.Recipe.Params["timeout"]; timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return fmt.Errorf("recipe '%s' has an invalid timeout %q: %w", r.Name, timeoutStr, err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, shell, "-c", r.Code())
+	cmd.Dir = filepath.Dir(filename)
+	if cwd := r.Block.Recipe.Params["cwd"]; cwd != "" {
+		cmd.Dir = cwd
+	}
+	cmd.Env = os.Environ()
+	for _, p := range r.Params {
+		cmd.Env = append(cmd.Env, p.Name+"="+values[p.Name])
+	}
+	cmd.Env = append(cmd.Env, "JOT_RECIPE="+r.Name)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("recipe '%s' timed out", r.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("recipe '%s' failed: %w", r.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johncoder/jot/internal/safemode"
+)
+
+func TestFindAndRunRecipe(t *testing.T) {
+	dir := t.TempDir()
+	note := filepath.Join(dir, "ops.md")
+	content := "# Ops\n\n" +
+		"<eval name=\"greet\" />\n```bash\necho eval\n```\n\n" +
+		"<recipe name=\"greet\" params=\"who, greeting=hello\" />\n```bash\necho \"$greeting, $who\"\n```\n\n" +
+		"<recipe name=\"py\" />\n```python\nprint(1)\n```\n"
+	if err := os.WriteFile(note, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recipes, err := FindRecipes(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipes) != 2 || recipes[0].Name != "greet" || len(recipes[0].Params) != 2 {
+		t.Fatalf("recipes = %+v", recipes)
+	}
+	if recipes[0].Block.Eval != nil {
+		t.Errorf("recipe took the eval element of the block before it")
+	}
+	if _, err := recipes[1].Shell(); err == nil {
+		t.Errorf("python recipe has a shell")
+	}
+	if approvalName(recipes[0].Block) != "recipe:greet" {
+		t.Errorf("approvalName = %q", approvalName(recipes[0].Block))
+	}
+
+	var stdout bytes.Buffer
+	if err := recipes[0].Run(note, map[string]string{"who": "ann", "greeting": "hi"}, nil, &stdout, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hi, ann\n" {
+		t.Errorf("output = %q", stdout.String())
+	}
+
+	safemode.Configure(true, "testing")
+	defer safemode.Configure(false, "")
+	stdout.Reset()
+	if err := recipes[0].Run(note, map[string]string{"who": "ann"}, nil, &stdout, &stdout); !errors.Is(err, safemode.ErrSafeMode) || stdout.Len() != 0 {
+		t.Errorf("recipe in safe mode: err %v, output %q", err, stdout.String())
+	}
+}
@@ -2,8 +2,9 @@ package eval
 
 import (
 	"bufio"
-	"os"
 	"strings"
+
+	"github.com/johncoder/jot/internal/storage"
 )
 
 // CodeBlock represents a fenced code block in markdown
@@ -17,6 +18,7 @@ type CodeBlock struct {
 	Lang        string
 	Code        []string
 	Eval        *EvalMetadata
+	Recipe      *EvalMetadata // Set instead of Eval for runnable recipes
 	ResultBlock *ResultBlock
 }
 
@@ -28,7 +30,7 @@ type ResultBlock struct {
 
 // ParseMarkdownForEvalBlocks scans a markdown file and returns all evaluable code blocks
 func ParseMarkdownForEvalBlocks(filename string) ([]*CodeBlock, error) {
-	f, err := os.Open(filename)
+	f, err := storage.Open(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +42,7 @@ func ParseMarkdownForEvalBlocks(filename string) ([]*CodeBlock, error) {
 	var codeBlock *CodeBlock
 	var lineNum int
 	var pendingEval *EvalMetadata // Store eval element waiting for code block
+	var pendingRecipe *EvalMetadata
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -54,6 +57,13 @@ func ParseMarkdownForEvalBlocks(filename string) ([]*CodeBlock, error) {
 			}
 			continue
 		}
+		if !inCode && IsRecipeElement(trim) {
+			meta, err := ParseRecipeElement(trim)
+			if err == nil {
+				pendingRecipe = meta
+			}
+			continue
+		}
 
 		if strings.HasPrefix(trim, "```") {
 			if !inCode {
@@ -64,8 +74,10 @@ func ParseMarkdownForEvalBlocks(filename string) ([]*CodeBlock, error) {
 					StartLine: lineNum,
 					Lang:      lang,
 					Eval:      pendingEval, // Associate with preceding eval element
+					Recipe:    pendingRecipe,
 				}
 				pendingEval = nil // Clear pending eval
+				pendingRecipe = nil
 			} else {
 				// End of code block
 				inCode = false
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/johncoder/jot/internal/workspace"
 	"github.com/spf13/viper"
 )
@@ -32,11 +33,13 @@ const (
 
 // ApprovalRecord represents an approved code block
 type ApprovalRecord struct {
-	Hash       string       `json:"hash"`
-	Mode       ApprovalMode `json:"mode"`
-	FilePath   string       `json:"file_path"`
-	BlockName  string       `json:"block_name"`
-	ApprovedAt string       `json:"approved_at"`
+	Hash        string       `json:"hash"`
+	Mode        ApprovalMode `json:"mode"`
+	FilePath    string       `json:"file_path"`
+	BlockName   string       `json:"block_name"`
+	ApprovedAt  string       `json:"approved_at"`
+	Code        string       `json:"code,omitempty"`        // The code approved, to show what changed since
+	Interpreter string       `json:"interpreter,omitempty"` // The configured interpreter approved to run it
 }
 
 // DocumentApprovalRecord represents an approved document
@@ -52,6 +55,7 @@ type SecurityManager struct {
 	approvals     map[string]*ApprovalRecord
 	docApprovals  map[string]*DocumentApprovalRecord
 	docConfigPath string
+	interpreters  map[string]workspace.Interpreter
 }
 
 // NewSecurityManager creates a new security manager
@@ -69,9 +73,10 @@ func NewSecurityManager() (*SecurityManager, error) {
 
 	sm.configPath = filepath.Join(ws.JotDir, "eval_permissions")
 	sm.docConfigPath = filepath.Join(ws.JotDir, "eval_document_permissions")
+	sm.interpreters = ws.GetInterpreters()
 
 	// Ensure .jot directory exists
-	if err := os.MkdirAll(ws.JotDir, 0755); err != nil {
+	if err := dryrun.MkdirAll(ws.JotDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create .jot directory: %w", err)
 	}
 
@@ -124,11 +129,11 @@ func (sm *SecurityManager) saveApprovals() error {
 	}
 
 	// Ensure .jot directory exists
-	if err := os.MkdirAll(filepath.Dir(sm.configPath), 0755); err != nil {
+	if err := dryrun.MkdirAll(filepath.Dir(sm.configPath), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(sm.configPath, data, 0644)
+	return dryrun.WriteFile(sm.configPath, data, 0644)
 }
 
 // makeApprovalKey creates a unique key for an approval record
@@ -136,13 +141,28 @@ func (sm *SecurityManager) makeApprovalKey(filePath, blockName string) string {
 	return fmt.Sprintf("%s:%s", filePath, blockName)
 }
 
-// hashCodeBlock creates a SHA256 hash of the code block content
+// hashCodeBlock creates a SHA256 hash of the code block content and the
+// configured interpreter that would run it, so changing the interpreter
+// needs the block approved again just as changing the code does
 func (sm *SecurityManager) hashCodeBlock(block *CodeBlock) string {
 	content := strings.Join(block.Code, "\n")
+	if interpreter := sm.interpreterFor(block); interpreter != "" {
+		content += "\x00" + interpreter
+	}
 	hash := sha256.Sum256([]byte(content))
 	return hex.EncodeToString(hash[:])
 }
 
+// interpreterFor returns the configured interpreter command for a block's
+// language, or "" when it runs with a built-in or PATH evaluator
+func (sm *SecurityManager) interpreterFor(block *CodeBlock) string {
+	lang := block.Lang
+	if block.Eval != nil {
+		lang = blockLanguage(block)
+	}
+	return sm.interpreters[lang].Command
+}
+
 // getSecurityConfig gets the security configuration for a given file path
 func (sm *SecurityManager) getSecurityConfig(filePath string) (*SecurityConfig, error) {
 	configs, err := sm.loadSecurityConfigs(filePath)
@@ -231,14 +251,25 @@ func (sm *SecurityManager) loadSecurityConfigFromFile(configPath string) ([]*Sec
 	return configs, nil
 }
 
+// approvalName returns the name a block is approved under. Recipes are
+// kept apart from eval blocks, so approving one never approves the other.
+func approvalName(block *CodeBlock) string {
+	switch {
+	case block.Recipe != nil && block.Recipe.GetName() != "":
+		return "recipe:" + block.Recipe.GetName()
+	case block.Eval != nil:
+		return block.Eval.GetName()
+	}
+	return ""
+}
+
 // CheckApproval checks if a code block is approved for execution
 func (sm *SecurityManager) CheckApproval(filePath string, block *CodeBlock) (bool, error) {
-	if block.Eval == nil || block.Eval.Params["name"] == "" {
+	blockName := approvalName(block)
+	if blockName == "" {
 		return false, fmt.Errorf("code block has no name")
 	}
 
-	blockName := block.Eval.Params["name"]
-
 	// First check if the entire document is approved
 	docApproved, docMode, err := sm.CheckDocumentApproval(filePath)
 	if err != nil {
@@ -291,19 +322,20 @@ func (sm *SecurityManager) CheckApproval(filePath string, block *CodeBlock) (boo
 
 // ApproveBlock approves a code block for execution
 func (sm *SecurityManager) ApproveBlock(filePath string, block *CodeBlock, mode ApprovalMode) error {
-	if block.Eval == nil || block.Eval.Params["name"] == "" {
+	blockName := approvalName(block)
+	if blockName == "" {
 		return fmt.Errorf("code block has no name")
 	}
-
-	blockName := block.Eval.Params["name"]
 	hash := sm.hashCodeBlock(block)
 
 	approval := &ApprovalRecord{
-		Hash:       hash,
-		Mode:       mode,
-		FilePath:   filePath,
-		BlockName:  blockName,
-		ApprovedAt: time.Now().Format(time.RFC3339),
+		Hash:        hash,
+		Mode:        mode,
+		FilePath:    filePath,
+		BlockName:   blockName,
+		ApprovedAt:  time.Now().Format(time.RFC3339),
+		Code:        strings.Join(block.Code, "\n"),
+		Interpreter: sm.interpreterFor(block),
 	}
 
 	key := sm.makeApprovalKey(filePath, blockName)
@@ -364,11 +396,11 @@ func (sm *SecurityManager) saveDocumentApprovals() error {
 	}
 
 	// Ensure .jot directory exists
-	if err := os.MkdirAll(filepath.Dir(sm.docConfigPath), 0755); err != nil {
+	if err := dryrun.MkdirAll(filepath.Dir(sm.docConfigPath), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(sm.docConfigPath, data, 0644)
+	return dryrun.WriteFile(sm.docConfigPath, data, 0644)
 }
 
 // CheckDocumentApproval checks if a document is approved for execution
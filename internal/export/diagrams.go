@@ -0,0 +1,141 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Diagram languages recognized in fenced code blocks
+const (
+	DiagramMermaid  = "mermaid"
+	DiagramPlantUML = "plantuml"
+)
+
+// diagramRenderTimeout bounds how long an external diagram tool may run
+const diagramRenderTimeout = 30 * time.Second
+
+// DiagramLanguage returns the normalized diagram language for a fence info
+// string, or "" when the block is not a diagram
+func DiagramLanguage(info string) string {
+	switch strings.ToLower(strings.TrimSpace(info)) {
+	case "mermaid", "mmd":
+		return DiagramMermaid
+	case "plantuml", "puml", "uml":
+		return DiagramPlantUML
+	}
+	return ""
+}
+
+// DiagramLabel returns a short human-readable label for a diagram block
+// shown in a terminal, pointing at the export that renders it
+func DiagramLabel(language string, lineCount int) string {
+	return fmt.Sprintf("[%s - export with 'jot export --format html' to view]", diagramSize(language, lineCount))
+}
+
+// diagramSourceLabel labels a diagram an HTML export left as source
+func diagramSourceLabel(language string, lineCount int) string {
+	return diagramSize(language, lineCount) + ", shown as source"
+}
+
+// diagramSize describes a diagram block, as "mermaid diagram, 3 lines"
+func diagramSize(language string, lineCount int) string {
+	lines := "lines"
+	if lineCount == 1 {
+		lines = "line"
+	}
+	return fmt.Sprintf("%s diagram, %d %s", language, lineCount, lines)
+}
+
+// renderPlantUML renders PlantUML source to SVG using the plantuml command
+func renderPlantUML(source string) (string, error) {
+	path, err := exec.LookPath("plantuml")
+	if err != nil {
+		return "", fmt.Errorf("plantuml not found in PATH")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagramRenderTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "-tsvg", "-pipe")
+	cmd.Stdin = strings.NewReader(source)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("plantuml failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	svg := stdout.String()
+	// Drop any XML prolog so the SVG can be inlined in HTML
+	if idx := strings.Index(svg, "<svg"); idx > 0 {
+		svg = svg[idx:]
+	}
+	return svg, nil
+}
+
+// blockSource returns the raw lines of a code block
+func blockSource(block *ast.FencedCodeBlock, content []byte) []byte {
+	var source bytes.Buffer
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		source.Write(line.Value(content))
+	}
+	return source.Bytes()
+}
+
+// LabelDiagrams inserts a label line above each diagram block so diagrams are
+// clearly identified when markdown is shown in a terminal
+func LabelDiagrams(content []byte) []byte {
+	type label struct {
+		offset int
+		text   string
+	}
+	var labels []label
+
+	doc := markdown.ParseDocument(content)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		block, ok := n.(*ast.FencedCodeBlock)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		language := DiagramLanguage(string(block.Language(content)))
+		if language != "" && block.Lines().Len() > 0 {
+			// The opening fence is the line before the first content line
+			fenceOffset := lineStart(content, block.Lines().At(0).Start-1)
+			labels = append(labels, label{
+				offset: fenceOffset,
+				text:   DiagramLabel(language, block.Lines().Len()) + "\n",
+			})
+		}
+		return ast.WalkSkipChildren, nil
+	})
+
+	if len(labels) == 0 {
+		return content
+	}
+
+	var result bytes.Buffer
+	previous := 0
+	for _, l := range labels {
+		result.Write(content[previous:l.offset])
+		result.WriteString(l.text)
+		previous = l.offset
+	}
+	result.Write(content[previous:])
+
+	return result.Bytes()
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+const diagramNote = "# Design\n\n```mermaid\ngraph TD\n  A --> B\n```\n\n```puml\n@startuml\n@enduml\n```\n"
+
+func TestDiagramLanguage(t *testing.T) {
+	tests := map[string]string{
+		"mermaid":  DiagramMermaid,
+		" MMD ":    DiagramMermaid,
+		"plantuml": DiagramPlantUML,
+		"puml":     DiagramPlantUML,
+		"uml":      DiagramPlantUML,
+		"go":       "",
+		"":         "",
+	}
+	for info, want := range tests {
+		if got := DiagramLanguage(info); got != want {
+			t.Errorf("DiagramLanguage(%q) = %q, want %q", info, got, want)
+		}
+	}
+}
+
+func TestLabelDiagrams(t *testing.T) {
+	got := string(LabelDiagrams([]byte(diagramNote)))
+	want := "# Design\n\n" +
+		"[mermaid diagram, 2 lines - export with 'jot export --format html' to view]\n```mermaid\ngraph TD\n  A --> B\n```\n\n" +
+		"[plantuml diagram, 2 lines - export with 'jot export --format html' to view]\n```puml\n@startuml\n@enduml\n```\n"
+	if got != want {
+		t.Errorf("LabelDiagrams() =\n%s\nwant\n%s", got, want)
+	}
+
+	plain := "# Notes\n\n```go\nfmt.Println()\n```\n"
+	if got := string(LabelDiagrams([]byte(plain))); got != plain {
+		t.Errorf("LabelDiagrams() changed a note without diagrams:\n%s", got)
+	}
+}
+
+func TestHTMLDiagramSource(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // No plantuml
+
+	tests := []struct {
+		name       string
+		noDiagrams bool
+		want       []string
+		diagrams   int
+		warnings   int
+	}{
+		{
+			name:       "no diagrams",
+			noDiagrams: true,
+			want: []string{
+				`<pre class="diagram-source" data-label="mermaid diagram, 2 lines, shown as source">`,
+				`<pre class="diagram-source" data-label="plantuml diagram, 2 lines, shown as source">`,
+			},
+		},
+		{
+			name: "plantuml missing",
+			want: []string{
+				`<pre class="mermaid">`,
+				`<pre class="diagram-source" data-label="plantuml diagram, 2 lines, shown as source">`,
+			},
+			diagrams: 1,
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderHTML(&Document{Title: "Design", Content: []byte(diagramNote)}, Options{Format: FormatHTML, NoDiagrams: tt.noDiagrams})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result.Content, want) {
+					t.Errorf("HTML is missing %s:\n%s", want, result.Content)
+				}
+			}
+			// The page is the export; it should not tell the reader to export it
+			if strings.Contains(result.Content, "jot export") {
+				t.Errorf("HTML tells the reader to run jot export:\n%s", result.Content)
+			}
+			if result.DiagramCount != tt.diagrams || len(result.Warnings) != tt.warnings {
+				t.Errorf("diagrams, warnings = %d, %v; want %d diagrams and %d warnings", result.DiagramCount, result.Warnings, tt.diagrams, tt.warnings)
+			}
+		})
+	}
+}
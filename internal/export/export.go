@@ -0,0 +1,55 @@
+// Package export converts markdown notes into presentation and publishing formats
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported export formats
+const (
+	FormatSlides = "slides"
+	FormatHTML   = "html"
+)
+
+// Document is a markdown source prepared for export
+type Document struct {
+	Title   string // Document title (root heading text or file name)
+	Source  string // Selector or file the content came from
+	Content []byte // Markdown content to export
+}
+
+// Options configures an export
+type Options struct {
+	Format     string // Output format (see Format* constants)
+	Engine     string // Rendering engine for formats that support several
+	NoDiagrams bool   // Leave diagram blocks as plain code instead of rendering them
+}
+
+// Result is the rendered output of an export
+type Result struct {
+	Format       string   // Format that was produced
+	Engine       string   // Engine that was used, if any
+	Extension    string   // Suggested file extension for the output
+	Content      string   // Rendered output
+	SlideCount   int      // Number of slides for slide exports
+	DiagramCount int      // Number of diagrams rendered
+	Warnings     []string // Non-fatal problems encountered while exporting
+}
+
+// Formats returns the list of supported export formats
+func Formats() []string {
+	return []string{FormatSlides, FormatHTML}
+}
+
+// Export renders a document using the given options
+func Export(doc *Document, opts Options) (*Result, error) {
+	switch opts.Format {
+	case FormatSlides:
+		return renderSlides(doc, opts.Engine)
+	case FormatHTML:
+		return renderHTML(doc, opts)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q (supported: %s)", opts.Format, strings.Join(Formats(), ", "))
+	}
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// mermaidScript loads mermaid.js and renders every <pre class="mermaid"> block
+const mermaidScript = `  <script type="module">
+    import mermaid from "https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.esm.min.mjs";
+    mermaid.initialize({ startOnLoad: true });
+  </script>
+`
+
+// renderHTML renders a document as a standalone HTML page
+func renderHTML(doc *Document, opts Options) (*Result, error) {
+	codeRenderer := &codeBlockRenderer{diagrams: !opts.NoDiagrams}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(codeRenderer, 100)),
+		),
+	)
+
+	var body bytes.Buffer
+	if err := md.Convert(doc.Content, &body); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	page.WriteString("  <meta charset=\"utf-8\">\n")
+	page.WriteString(fmt.Sprintf("  <title>%s</title>\n", html.EscapeString(doc.Title)))
+	page.WriteString("  <style>body { max-width: 48rem; margin: 2rem auto; font-family: sans-serif; line-height: 1.5; } pre { overflow-x: auto; } .diagram-source::before { content: attr(data-label); display: block; font-style: italic; }</style>\n")
+	if codeRenderer.mermaidCount > 0 {
+		page.WriteString(mermaidScript)
+	}
+	page.WriteString("</head>\n<body>\n")
+	page.Write(body.Bytes())
+	page.WriteString("</body>\n</html>\n")
+
+	return &Result{
+		Format:       FormatHTML,
+		Extension:    ".html",
+		Content:      page.String(),
+		DiagramCount: codeRenderer.mermaidCount + codeRenderer.plantumlCount,
+		Warnings:     codeRenderer.warnings,
+	}, nil
+}
+
+// codeBlockRenderer renders fenced code blocks, turning diagram blocks into
+// mermaid containers or inline SVG when diagrams are enabled
+type codeBlockRenderer struct {
+	diagrams      bool
+	mermaidCount  int
+	plantumlCount int
+	warnings      []string
+}
+
+// RegisterFuncs registers the fenced code block renderer
+func (r *codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+// renderFencedCodeBlock writes a single fenced code block
+func (r *codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block := node.(*ast.FencedCodeBlock)
+	info := string(block.Language(source))
+	code := string(blockSource(block, source))
+	language := DiagramLanguage(info)
+
+	if r.diagrams {
+		switch language {
+		case DiagramMermaid:
+			r.mermaidCount++
+			_, _ = w.WriteString("<pre class=\"mermaid\">\n")
+			_, _ = w.WriteString(html.EscapeString(code))
+			_, _ = w.WriteString("</pre>\n")
+			return ast.WalkSkipChildren, nil
+		case DiagramPlantUML:
+			svg, err := renderPlantUML(code)
+			if err == nil {
+				r.plantumlCount++
+				_, _ = w.WriteString("<figure class=\"diagram\">\n")
+				_, _ = w.WriteString(svg)
+				_, _ = w.WriteString("\n</figure>\n")
+				return ast.WalkSkipChildren, nil
+			}
+			r.warnings = append(r.warnings, fmt.Sprintf("plantuml diagram left as source: %v", err))
+		}
+	}
+
+	if language != "" {
+		label := diagramSourceLabel(language, block.Lines().Len())
+		_, _ = w.WriteString(fmt.Sprintf("<pre class=\"diagram-source\" data-label=\"%s\">", html.EscapeString(label)))
+	} else {
+		_, _ = w.WriteString("<pre>")
+	}
+
+	_, _ = w.WriteString("<code")
+	if info != "" {
+		_, _ = w.WriteString(fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(info)))
+	}
+	_, _ = w.WriteString(">")
+	_, _ = w.WriteString(html.EscapeString(code))
+	_, _ = w.WriteString("</code></pre>\n")
+
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,519 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"gopkg.in/yaml.v3"
+)
+
+// Static site generators a site export can write content for
+const (
+	GeneratorHugo = "hugo"
+	GeneratorZola = "zola"
+)
+
+// Generators returns the list of supported site generators
+func Generators() []string {
+	return []string{GeneratorHugo, GeneratorZola}
+}
+
+// Page is a note, or a subtree of one, to publish as a page of a site
+type Page struct {
+	Source   string    // Workspace path of the note, with forward slashes
+	Heading  string    // Heading of a subtree page; empty for a whole note
+	Content  []byte    // Markdown, with the note's frontmatter for a whole note
+	Modified time.Time // The page's date when it gives none
+}
+
+// SiteOptions configures a site export
+type SiteOptions struct {
+	Generator string
+	Title     string // Title of the home page
+	Base      string // Workspace directory published at the site root, such as "lib"
+}
+
+// SiteFile is a file of an exported site
+type SiteFile struct {
+	Path    string // Under the output directory, such as "content/projects/alpha.md"
+	Title   string
+	Source  string // Note, or note#heading, it was made from; empty for section indexes
+	Content []byte
+}
+
+// SiteResult is the content tree of an exported site
+type SiteResult struct {
+	Files    []SiteFile
+	Links    int      // Links rewritten to site paths
+	Warnings []string // Links to notes that were not exported
+}
+
+// urlScheme matches the scheme of an absolute URL, as in "https:"
+var urlScheme = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+
+// sitePage is a page with the path and frontmatter it is published with
+type sitePage struct {
+	Page
+	path  string // Under content/, without ".md"
+	title string
+	slug  string // Slug of the heading of a subtree page
+}
+
+// Site lays pages out as the content/ tree of a Hugo or Zola site. Each page
+// gets frontmatter made from the note's frontmatter, its heading's tags and
+// the name: value lines under the heading, which are taken out of the text.
+// Links between exported notes are rewritten to the generator's internal
+// links, and sections get an _index.md.
+func Site(pages []Page, opts SiteOptions) (*SiteResult, error) {
+	if opts.Generator != GeneratorHugo && opts.Generator != GeneratorZola {
+		return nil, fmt.Errorf("unsupported site generator %q (supported: %s)", opts.Generator, strings.Join(Generators(), ", "))
+	}
+
+	site := make([]*sitePage, 0, len(pages))
+	used := map[string]bool{}
+	for _, page := range pages {
+		p := &sitePage{Page: page, path: sitePath(page, opts.Base)}
+		if page.Heading != "" {
+			p.slug = markdown.Slug(headingTitle(page.Heading))
+		}
+		// Two notes whose names slug alike are numbered
+		base := p.path
+		for n := 1; used[p.path] || used[p.path+"/_index"]; n++ {
+			p.path = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[p.path] = true
+		site = append(site, p)
+	}
+
+	result := &SiteResult{}
+	for _, page := range site {
+		content, links, warnings := renderPage(page, site, opts.Generator)
+		result.Links += links
+		result.Warnings = append(result.Warnings, warnings...)
+		source := page.Source
+		if page.Heading != "" {
+			source += "#" + page.Heading
+		}
+		result.Files = append(result.Files, SiteFile{
+			Path:    "content/" + page.path + ".md",
+			Title:   page.title,
+			Source:  source,
+			Content: content,
+		})
+	}
+	result.Files = append(result.Files, sectionIndexes(site, used, opts)...)
+	return result, nil
+}
+
+// sitePath returns where a page is published, under content/ and without
+// ".md": the note's path under base, each part slugged, and for a subtree,
+// the heading's slug under that
+func sitePath(page Page, base string) string {
+	source := strings.TrimSuffix(page.Source, path.Ext(page.Source))
+	if base != "" && base != "." {
+		source = strings.TrimPrefix(source, strings.TrimSuffix(base, "/")+"/")
+	}
+	parts := strings.Split(source, "/")
+	if page.Heading != "" {
+		parts = append(parts, headingTitle(page.Heading))
+	}
+	for i, part := range parts {
+		if slug := markdown.Slug(part); slug != "" {
+			parts[i] = slug
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// headingTitle returns heading text without tags or an explicit ID
+func headingTitle(heading string) string {
+	text, _ := markdown.HeadingID(markdown.StripHeadingTags(heading))
+	return strings.TrimSpace(text)
+}
+
+// renderPage returns a page's file: frontmatter, then the text below its
+// title heading with headings shifted to start at level 2
+func renderPage(page *sitePage, site []*sitePage, generator string) ([]byte, int, []string) {
+	meta, body := splitFrontmatter(page.Content)
+
+	var tags []string
+	var properties []markdown.Property
+	scanner := markdown.NewHeadingScanner(body)
+	if scanner.Next() && len(bytes.TrimSpace(body[:scanner.Heading().Offset])) == 0 {
+		heading := scanner.Heading()
+		page.title = headingTitle(heading.Text())
+		tags = markdown.HeadingTags(heading.Text())
+		rest := body[heading.End:]
+		var n int
+		properties, n = markdown.LeadingProperties(rest)
+		body = rest[n:]
+	}
+	if title, ok := meta.get("title"); ok {
+		page.title = fmt.Sprint(title)
+	}
+	if page.title == "" {
+		page.title = path.Base(page.path)
+	}
+
+	// The title heading is gone, so what was under it moves up
+	minLevel := 0
+	for scanner := markdown.NewHeadingScanner(body); scanner.Next(); {
+		if level := scanner.Heading().Level; minLevel == 0 || level < minLevel {
+			minLevel = level
+		}
+	}
+	if minLevel > 0 {
+		body = markdown.TransformHeadingLevels(body, 2-minLevel)
+	}
+
+	var date interface{} = page.Modified.Format(time.RFC3339)
+	if value, ok := meta.get("date"); ok {
+		date = value
+	}
+	tags = append(frontmatterTags(meta), tags...)
+	extra := frontmatter{}
+	for _, field := range meta {
+		switch field.key {
+		case "title", "date", "tags":
+		default:
+			extra = append(extra, field)
+		}
+	}
+	for _, property := range properties {
+		name := strings.ToLower(property.Name)
+		switch name {
+		case "date":
+			date = property.Value
+		case "tags":
+			for _, tag := range strings.Split(property.Value, ",") {
+				tags = append(tags, strings.TrimSpace(tag))
+			}
+		default:
+			if _, ok := extra.get(name); !ok {
+				extra = append(extra, field{name, property.Value})
+			}
+		}
+	}
+	tags = uniqueTags(tags)
+
+	body, links, warnings := rewriteSiteLinks(page, site, body, generator)
+	var out bytes.Buffer
+	if generator == GeneratorZola {
+		writeTOMLFrontmatter(&out, page.title, date, tags, extra)
+	} else {
+		writeYAMLFrontmatter(&out, page.title, date, tags, extra)
+	}
+	out.Write(bytes.TrimLeft(body, "\r\n"))
+	if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), links, warnings
+}
+
+// rewriteSiteLinks points links at other exported notes to their pages,
+// using the generator's internal links so it checks them: Hugo's relref
+// shortcode, or Zola's @/ paths. Links are read relative to the note and
+// then to the workspace root, as jot writes them.
+func rewriteSiteLinks(page *sitePage, site []*sitePage, body []byte, generator string) ([]byte, int, []string) {
+	var warnings []string
+	rewritten, count := markdown.RewriteLinks(body, func(target string) (string, bool) {
+		if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") || urlScheme.MatchString(target) {
+			return "", false
+		}
+		linkPath, anchor, _ := strings.Cut(target, "#")
+		linkPath, _, _ = strings.Cut(linkPath, "?")
+		if decoded, err := url.PathUnescape(linkPath); err == nil {
+			linkPath = decoded
+		}
+		if path.Ext(linkPath) != ".md" {
+			return "", false
+		}
+
+		candidates := []string{path.Join(path.Dir(page.Source), linkPath), path.Clean(linkPath)}
+		for _, file := range candidates {
+			linked, anchor := findSitePage(site, file, anchor)
+			if linked == nil {
+				continue
+			}
+			ref := linked.path + ".md"
+			if anchor != "" {
+				ref += "#" + anchor
+			}
+			if generator == GeneratorZola {
+				return "@/" + ref, true
+			}
+			return `{{< relref "/` + ref + `" >}}`, true
+		}
+		warnings = append(warnings, fmt.Sprintf("%s links to %s, which was not exported", page.Source, target))
+		return "", false
+	})
+	return rewritten, count, warnings
+}
+
+// findSitePage returns the page for a link to file#anchor and the anchor to
+// keep: a subtree page for its heading, or else the note's own page with
+// the anchor as a heading slug
+func findSitePage(site []*sitePage, file, anchor string) (*sitePage, string) {
+	slug := ""
+	if anchor != "" {
+		last := anchor[strings.LastIndex(anchor, "/")+1:]
+		if decoded, err := url.PathUnescape(last); err == nil {
+			last = decoded
+		}
+		slug = markdown.Slug(headingTitle(last))
+	}
+
+	var whole *sitePage
+	for _, page := range site {
+		if page.Source != file {
+			continue
+		}
+		if page.Heading == "" {
+			whole = page
+			continue
+		}
+		if _, id := markdown.HeadingID(page.Heading); slug != "" && (page.slug == slug || strings.EqualFold(id, slug)) {
+			return page, ""
+		}
+	}
+	if whole == nil {
+		return nil, ""
+	}
+	return whole, slug
+}
+
+// sectionIndexes returns an _index.md for each directory holding pages, so
+// both generators list them as sections, unless a page is published there
+func sectionIndexes(site []*sitePage, used map[string]bool, opts SiteOptions) []SiteFile {
+	dirs := map[string]bool{"": true}
+	for _, page := range site {
+		for dir := path.Dir(page.path); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	var names []string
+	for dir := range dirs {
+		// A directory of subtree pages belongs to the note's page
+		if !used[dir] {
+			names = append(names, dir)
+		}
+	}
+	sort.Strings(names)
+
+	files := make([]SiteFile, 0, len(names))
+	for _, dir := range names {
+		title := opts.Title
+		indexPath := "content/_index.md"
+		if dir != "" {
+			title = path.Base(dir)
+			indexPath = "content/" + dir + "/_index.md"
+		}
+		var out bytes.Buffer
+		if opts.Generator == GeneratorZola {
+			writeTOMLFrontmatter(&out, title, nil, nil, nil)
+		} else {
+			writeYAMLFrontmatter(&out, title, nil, nil, nil)
+		}
+		files = append(files, SiteFile{Path: indexPath, Title: title, Content: out.Bytes()})
+	}
+	return files
+}
+
+// field is one key of frontmatter
+type field struct {
+	key   string
+	value interface{}
+}
+
+// frontmatter holds frontmatter keys in the order they were written
+type frontmatter []field
+
+func (f frontmatter) get(key string) (interface{}, bool) {
+	for _, field := range f {
+		if field.key == key {
+			return field.value, true
+		}
+	}
+	return nil, false
+}
+
+// splitFrontmatter returns a note's YAML frontmatter, in order, and the
+// text after it. Frontmatter that does not parse is left in the text.
+func splitFrontmatter(content []byte) (frontmatter, []byte) {
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return nil, content
+	}
+	end := bytes.Index(content[4:], []byte("\n---"))
+	if end < 0 {
+		return nil, content
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(content[4:4+end], &node); err != nil || len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+		return nil, content
+	}
+
+	var meta frontmatter
+	pairs := node.Content[0].Content
+	for i := 0; i+1 < len(pairs); i += 2 {
+		var value interface{}
+		if err := pairs[i+1].Decode(&value); err != nil {
+			continue
+		}
+		meta = append(meta, field{pairs[i].Value, value})
+	}
+	rest := content[4+end+len("\n---"):]
+	if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[i+1:]
+	} else {
+		rest = nil
+	}
+	return meta, rest
+}
+
+// frontmatterTags reads tags from frontmatter, as a list or a
+// comma-separated string
+func frontmatterTags(meta frontmatter) []string {
+	value, _ := meta.get("tags")
+	var tags []string
+	switch value := value.(type) {
+	case string:
+		tags = strings.Split(value, ",")
+	case []interface{}:
+		for _, item := range value {
+			tags = append(tags, fmt.Sprint(item))
+		}
+	}
+	return tags
+}
+
+// uniqueTags trims tags and drops empty and repeated ones, ignoring case
+func uniqueTags(tags []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		unique = append(unique, tag)
+	}
+	return unique
+}
+
+// writeYAMLFrontmatter writes Hugo frontmatter. Keys other than title, date
+// and tags are page parameters.
+func writeYAMLFrontmatter(out *bytes.Buffer, title string, date interface{}, tags []string, extra frontmatter) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value interface{}) {
+		var v yaml.Node
+		if err := v.Encode(value); err != nil {
+			return
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &v)
+	}
+	add("title", title)
+	if date != nil {
+		add("date", date)
+	}
+	if len(tags) > 0 {
+		add("tags", tags)
+	}
+	for _, field := range extra {
+		add(field.key, field.value)
+	}
+	encoded, _ := yaml.Marshal(node)
+	out.WriteString("---\n")
+	out.Write(encoded)
+	out.WriteString("---\n\n")
+}
+
+// writeTOMLFrontmatter writes Zola frontmatter. Tags are a taxonomy, and
+// other keys go under [extra], as Zola allows no others.
+func writeTOMLFrontmatter(out *bytes.Buffer, title string, date interface{}, tags []string, extra frontmatter) {
+	out.WriteString("+++\n")
+	fmt.Fprintf(out, "title = %s\n", tomlValue(title))
+	if date != nil {
+		fmt.Fprintf(out, "date = %s\n", tomlValue(date))
+	}
+	if len(tags) > 0 {
+		fmt.Fprintf(out, "\n[taxonomies]\ntags = %s\n", tomlValue(tags))
+	}
+	if len(extra) > 0 {
+		out.WriteString("\n[extra]\n")
+		for _, field := range extra {
+			fmt.Fprintf(out, "%s = %s\n", tomlKey(field.key), tomlValue(field.value))
+		}
+	}
+	out.WriteString("+++\n\n")
+}
+
+// bareKey matches a TOML key that needs no quotes
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlValue writes a scalar or list as TOML. Anything else is written as a
+// string.
+func tomlValue(value interface{}) string {
+	switch value := value.(type) {
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case []string:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = tomlString(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return tomlString(fmt.Sprint(value))
+	}
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSite(t *testing.T) {
+	modified := time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC)
+	pages := []Page{
+		{
+			Source:   "lib/projects/Alpha Plan.md",
+			Modified: modified,
+			Content: []byte("---\ntags: [work]\nauthor: sam\n---\n# Alpha Plan :project:\nstatus: active\n\n" +
+				"See [risks](beta.md#Beta/Risks), [goals](#goals) and [home](../home.md).\n\n### Goals\n\n- one\n"),
+		},
+		{Source: "lib/projects/beta.md", Modified: modified, Content: []byte("# Beta\n\nBack to [alpha](<lib/projects/Alpha Plan.md#Goals>).\n")},
+		{Source: "lib/projects/beta.md", Heading: "Risks", Modified: modified, Content: []byte("## Risks\n\nMany.\n")},
+	}
+
+	site, err := Site(pages, SiteOptions{Generator: GeneratorHugo, Title: "Notes", Base: "lib"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, file := range site.Files {
+		files[file.Path] = string(file.Content)
+	}
+	for _, path := range []string{"content/projects/alpha-plan.md", "content/projects/beta.md", "content/projects/beta/risks.md", "content/_index.md", "content/projects/_index.md"} {
+		if _, ok := files[path]; !ok {
+			t.Errorf("missing %s", path)
+		}
+	}
+	if _, ok := files["content/projects/beta/_index.md"]; ok {
+		t.Error("subtree pages should not get a section index")
+	}
+
+	alpha := files["content/projects/alpha-plan.md"]
+	for _, want := range []string{
+		"---\ntitle: Alpha Plan\ndate: \"2024-06-02T09:00:00Z\"\ntags:\n    - work\n    - project\nauthor: sam\nstatus: active\n---\n\n",
+		`[risks]({{< relref "/projects/beta/risks.md" >}})`,
+		"[goals](#goals)",
+		"[home](../home.md)",
+		"## Goals",
+	} {
+		if !strings.Contains(alpha, want) {
+			t.Errorf("alpha-plan.md lacks %q:\n%s", want, alpha)
+		}
+	}
+	if strings.Contains(alpha, "# Alpha Plan") || strings.Contains(alpha, "status: active\n\n") {
+		t.Errorf("title heading or properties left in the text:\n%s", alpha)
+	}
+	if !strings.Contains(files["content/projects/beta.md"], `{{< relref "/projects/alpha-plan.md#goals" >}}`) {
+		t.Errorf("beta.md link not rewritten:\n%s", files["content/projects/beta.md"])
+	}
+	if site.Links != 2 || len(site.Warnings) != 1 || !strings.Contains(site.Warnings[0], "../home.md") {
+		t.Errorf("got %d links, warnings %v", site.Links, site.Warnings)
+	}
+
+	site, err = Site(pages[:1], SiteOptions{Generator: GeneratorZola, Title: "Notes", Base: "lib"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "+++\ntitle = \"Alpha Plan\"\ndate = \"2024-06-02T09:00:00Z\"\n\n[taxonomies]\ntags = [\"work\", \"project\"]\n\n[extra]\nauthor = \"sam\"\nstatus = \"active\"\n+++\n\n"
+	if got := string(site.Files[0].Content); !strings.HasPrefix(got, want) {
+		t.Errorf("got zola page\n%s", got)
+	}
+
+	if _, err := Site(pages, SiteOptions{Generator: "jekyll"}); err == nil {
+		t.Error("expected an error for an unsupported generator")
+	}
+}
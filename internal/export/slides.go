@@ -0,0 +1,212 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Slide engines
+const (
+	EngineMarp   = "marp"
+	EngineReveal = "reveal"
+)
+
+// Slide is a single slide in a deck
+type Slide struct {
+	Title string // Slide heading text
+	Body  string // Markdown body beneath the heading
+}
+
+// Deck is an outline split into a title slide and content slides
+type Deck struct {
+	Title  string  // Title slide heading
+	Intro  string  // Content between the title and the first slide
+	Slides []Slide // One slide per child heading
+}
+
+// SplitSlides turns an outline into a deck. When the content has a single
+// top-level heading it becomes the title slide and its children become slides;
+// otherwise every top-level heading becomes a slide under the fallback title.
+func SplitSlides(content []byte, fallbackTitle string) *Deck {
+	doc := markdown.ParseDocument(content)
+
+	var headings []*ast.Heading
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if heading, ok := node.(*ast.Heading); ok {
+			headings = append(headings, heading)
+		}
+	}
+
+	deck := &Deck{Title: fallbackTitle}
+	if len(headings) == 0 {
+		deck.Intro = strings.TrimSpace(string(content))
+		return deck
+	}
+
+	rootLevel := headings[0].Level
+	rootCount := 0
+	for _, heading := range headings {
+		if heading.Level < rootLevel {
+			rootLevel = heading.Level
+			rootCount = 0
+		}
+		if heading.Level == rootLevel {
+			rootCount++
+		}
+	}
+
+	slideLevel := rootLevel
+	introStart := 0
+	var titleHeading *ast.Heading
+	if rootCount == 1 && headings[0].Level == rootLevel && len(headings) > 1 {
+		// Single root heading: it titles the deck and its children are slides
+		titleHeading = headings[0]
+		deck.Title = markdown.ExtractHeadingText(titleHeading, content)
+		introStart = lineEnd(content, markdown.GetNodeOffset(titleHeading, content))
+		slideLevel = headings[1].Level
+		for _, heading := range headings[1:] {
+			slideLevel = min(slideLevel, heading.Level)
+		}
+	}
+
+	var slideHeadings []*ast.Heading
+	for _, heading := range headings {
+		if heading != titleHeading && heading.Level == slideLevel {
+			slideHeadings = append(slideHeadings, heading)
+		}
+	}
+
+	introEnd := len(content)
+	if len(slideHeadings) > 0 {
+		introEnd = lineStart(content, markdown.GetNodeOffset(slideHeadings[0], content))
+	}
+	if introStart < introEnd {
+		deck.Intro = strings.TrimSpace(string(content[introStart:introEnd]))
+	}
+
+	for _, heading := range slideHeadings {
+		start := lineEnd(content, markdown.GetNodeOffset(heading, content))
+		end := markdown.FindSubtreeEnd(heading, content)
+		if start > end {
+			start = end
+		}
+
+		// Nested headings are shifted so the slide heading sits at level 1
+		body := markdown.TransformHeadingLevels(bytes.TrimSpace(content[start:end]), 1-heading.Level)
+		deck.Slides = append(deck.Slides, Slide{
+			Title: markdown.ExtractHeadingText(heading, content),
+			Body:  string(body),
+		})
+	}
+
+	return deck
+}
+
+// renderSlides renders a document as a slide deck for the given engine
+func renderSlides(doc *Document, engine string) (*Result, error) {
+	if engine == "" {
+		engine = EngineMarp
+	}
+
+	deck := SplitSlides(doc.Content, doc.Title)
+
+	var output, extension string
+	switch engine {
+	case EngineMarp:
+		output = RenderMarp(deck)
+		extension = ".md"
+	case EngineReveal:
+		output = RenderReveal(deck)
+		extension = ".html"
+	default:
+		return nil, fmt.Errorf("unsupported slide engine %q (supported: %s, %s)", engine, EngineMarp, EngineReveal)
+	}
+
+	return &Result{
+		Format:     FormatSlides,
+		Engine:     engine,
+		Extension:  extension,
+		Content:    output,
+		SlideCount: len(deck.Slides) + 1,
+	}, nil
+}
+
+// RenderMarp renders a deck as Marp-compatible markdown
+func RenderMarp(deck *Deck) string {
+	var builder strings.Builder
+	builder.WriteString("---\nmarp: true\npaginate: true\n---\n\n")
+
+	for i, slide := range deckSlides(deck) {
+		if i > 0 {
+			builder.WriteString("\n---\n\n")
+		}
+		builder.WriteString(slideMarkdown(slide))
+	}
+
+	return builder.String()
+}
+
+// RenderReveal renders a deck as a standalone reveal.js HTML page
+func RenderReveal(deck *Deck) string {
+	var builder strings.Builder
+	builder.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	builder.WriteString("  <meta charset=\"utf-8\">\n")
+	builder.WriteString(fmt.Sprintf("  <title>%s</title>\n", html.EscapeString(deck.Title)))
+	builder.WriteString("  <link rel=\"stylesheet\" href=\"https://cdn.jsdelivr.net/npm/reveal.js@5/dist/reveal.css\">\n")
+	builder.WriteString("  <link rel=\"stylesheet\" href=\"https://cdn.jsdelivr.net/npm/reveal.js@5/dist/theme/white.css\">\n")
+	builder.WriteString("</head>\n<body>\n  <div class=\"reveal\">\n    <div class=\"slides\">\n")
+
+	for _, slide := range deckSlides(deck) {
+		builder.WriteString("      <section data-markdown>\n        <textarea data-template>\n")
+		builder.WriteString(html.EscapeString(slideMarkdown(slide)))
+		builder.WriteString("        </textarea>\n      </section>\n")
+	}
+
+	builder.WriteString("    </div>\n  </div>\n")
+	builder.WriteString("  <script src=\"https://cdn.jsdelivr.net/npm/reveal.js@5/dist/reveal.js\"></script>\n")
+	builder.WriteString("  <script src=\"https://cdn.jsdelivr.net/npm/reveal.js@5/plugin/markdown/markdown.js\"></script>\n")
+	builder.WriteString("  <script>Reveal.initialize({ hash: true, plugins: [ RevealMarkdown ] });</script>\n")
+	builder.WriteString("</body>\n</html>\n")
+
+	return builder.String()
+}
+
+// deckSlides returns the title slide followed by the content slides
+func deckSlides(deck *Deck) []Slide {
+	slides := make([]Slide, 0, len(deck.Slides)+1)
+	slides = append(slides, Slide{Title: deck.Title, Body: deck.Intro})
+	return append(slides, deck.Slides...)
+}
+
+// slideMarkdown renders one slide as markdown ending in a newline
+func slideMarkdown(slide Slide) string {
+	text := "# " + slide.Title + "\n"
+	if slide.Body != "" {
+		text += "\n" + slide.Body + "\n"
+	}
+	return text
+}
+
+// lineStart returns the offset of the beginning of the line containing offset
+func lineStart(content []byte, offset int) int {
+	for offset > 0 && content[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// lineEnd returns the offset just past the end of the line containing offset
+func lineEnd(content []byte, offset int) int {
+	for offset < len(content) {
+		if content[offset] == '\n' {
+			return offset + 1
+		}
+		offset++
+	}
+	return len(content)
+}
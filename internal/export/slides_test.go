@@ -0,0 +1,82 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitSlides(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectedTitle  string
+		expectedIntro  string
+		expectedTitles []string
+	}{
+		{
+			name:           "single root heading becomes title",
+			content:        "## Talk\n\nIntro.\n\n### One\n\nFirst\n\n#### Nested\n\n### Two\n\nSecond\n",
+			expectedTitle:  "Talk",
+			expectedIntro:  "Intro.",
+			expectedTitles: []string{"One", "Two"},
+		},
+		{
+			name:           "multiple root headings use fallback title",
+			content:        "# One\n\nFirst\n\n# Two\n\nSecond\n",
+			expectedTitle:  "notes",
+			expectedIntro:  "",
+			expectedTitles: []string{"One", "Two"},
+		},
+		{
+			name:           "no headings",
+			content:        "Just text\n",
+			expectedTitle:  "notes",
+			expectedIntro:  "Just text",
+			expectedTitles: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deck := SplitSlides([]byte(tt.content), "notes")
+
+			if deck.Title != tt.expectedTitle {
+				t.Errorf("SplitSlides() title = %q, want %q", deck.Title, tt.expectedTitle)
+			}
+			if deck.Intro != tt.expectedIntro {
+				t.Errorf("SplitSlides() intro = %q, want %q", deck.Intro, tt.expectedIntro)
+			}
+
+			var titles []string
+			for _, slide := range deck.Slides {
+				titles = append(titles, slide.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.expectedTitles, ",") {
+				t.Errorf("SplitSlides() slides = %v, want %v", titles, tt.expectedTitles)
+			}
+		})
+	}
+}
+
+func TestSplitSlidesShiftsNestedHeadings(t *testing.T) {
+	deck := SplitSlides([]byte("## Talk\n\n### One\n\n#### Nested\n\nbody\n"), "notes")
+
+	if len(deck.Slides) != 1 {
+		t.Fatalf("SplitSlides() returned %d slides, want 1", len(deck.Slides))
+	}
+	if !strings.HasPrefix(deck.Slides[0].Body, "## Nested") {
+		t.Errorf("SplitSlides() nested heading not shifted, body = %q", deck.Slides[0].Body)
+	}
+}
+
+func TestRenderMarp(t *testing.T) {
+	deck := &Deck{Title: "Talk", Slides: []Slide{{Title: "One", Body: "First"}}}
+	output := RenderMarp(deck)
+
+	if !strings.HasPrefix(output, "---\nmarp: true\n") {
+		t.Errorf("RenderMarp() missing marp front matter: %q", output)
+	}
+	if !strings.Contains(output, "# Talk\n\n---\n\n# One\n\nFirst\n") {
+		t.Errorf("RenderMarp() unexpected slide layout: %q", output)
+	}
+}
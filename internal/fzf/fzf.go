@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/storage"
 )
 
 // IsAvailable checks if FZF is available in the system PATH
@@ -29,8 +30,10 @@ type SearchResult struct {
 	Score       int    // Relevance score
 }
 
-// RunInteractiveSearch runs FZF with search results and handles user interaction
-func RunInteractiveSearch(results []SearchResult, query string) error {
+// RunInteractiveSearch runs FZF with search results and handles user interaction.
+// When previewSocket names a running 'jot serve' socket, previews are read
+// through it rather than by starting 'jot peek' for every line.
+func RunInteractiveSearch(results []SearchResult, query, previewSocket string) error {
 	if len(results) == 0 {
 		fmt.Printf("No matches found for '%s'\n", query)
 		return nil
@@ -44,7 +47,7 @@ func RunInteractiveSearch(results []SearchResult, query string) error {
 	defer os.Remove(tempFile)
 
 	// Run FZF with custom configuration
-	return runFZFLoop(tempFile, results, query)
+	return runFZFLoop(tempFile, results, query, previewSocket)
 }
 
 // createResultsFile creates a temporary file with formatted search results for FZF
@@ -68,10 +71,10 @@ func createResultsFile(results []SearchResult) (string, error) {
 }
 
 // runFZFLoop runs the main FZF interaction loop with preview and actions
-func runFZFLoop(resultsFile string, results []SearchResult, query string) error {
+func runFZFLoop(resultsFile string, results []SearchResult, query, previewSocket string) error {
 	for {
 		// Build FZF command with custom bindings
-		cmd := buildFZFCommand(resultsFile, query)
+		cmd := buildFZFCommand(resultsFile, query, previewSocket)
 
 		// Run FZF and capture selection
 		output, err := cmd.Output()
@@ -152,11 +155,11 @@ func handleAction(result *SearchResult, action string) error {
 }
 
 // buildFZFCommand creates the FZF command with appropriate options
-func buildFZFCommand(resultsFile, query string) *exec.Cmd {
+func buildFZFCommand(resultsFile, query, previewSocket string) *exec.Cmd {
 	args := []string{
 		"--delimiter=|",
 		"--with-nth=2,4", // Show displayline and context, hide index and filepath
-		"--preview=" + buildPreviewCommand(),
+		"--preview=" + buildPreviewCommand(previewSocket),
 		"--preview-window=right:50%:wrap",
 		"--bind=tab:toggle-preview",
 		"--bind=enter:accept",
@@ -177,18 +180,37 @@ func buildFZFCommand(resultsFile, query string) *exec.Cmd {
 }
 
 // buildPreviewCommand creates the preview command for FZF
-func buildPreviewCommand() string {
+func buildPreviewCommand(previewSocket string) string {
 	// Extract the selector from the FZF line and use jot peek
 	// For find results: index|enhanced_selector|filepath|context -> use field 2 (enhanced_selector)
 	// For file results: index|displaypath|filepath|context -> use field 3 (filepath)
 	// Try field 2 first (enhanced selector), fallback to field 3 (filepath)
-	return `selector=$(echo {} | cut -d'|' -f2); filepath=$(echo {} | cut -d'|' -f3); jot peek "$selector" 2>/dev/null || jot peek "$filepath" 2>/dev/null || echo "Preview not available"`
+	peek := PreviewCommand(previewSocket, "")
+	return `selector=$(echo {} | cut -d'|' -f2); filepath=$(echo {} | cut -d'|' -f3); ` +
+		peek + `"$selector" 2>/dev/null || ` + peek + `"$filepath" 2>/dev/null || echo "Preview not available"`
+}
+
+// PreviewCommand returns the shell command FZF runs to preview field, such as
+// "{1}". Without a socket it is 'jot peek', which starts a process that loads
+// the workspace for every highlighted line. With the socket of a running
+// 'jot serve', 'jot preview' asks the daemon instead, keeping navigation fast
+// on large files. An empty field leaves a trailing space for the caller to fill.
+func PreviewCommand(socket, field string) string {
+	if socket == "" {
+		return "jot peek " + field
+	}
+	return "jot preview --socket " + shellQuote(socket) + " " + field
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // viewFile opens the selected file in the configured pager
 func viewFile(result *SearchResult) error {
 	// Read the file content
-	content, err := os.ReadFile(result.FilePath)
+	content, err := storage.ReadFile(result.FilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
@@ -251,7 +273,7 @@ func editFile(result *SearchResult) error {
 	}
 
 	// Check if the file exists
-	if _, err := os.Stat(result.FilePath); os.IsNotExist(err) {
+	if _, err := storage.Stat(result.FilePath); os.IsNotExist(err) {
 		return fmt.Errorf("file not found: %s", result.FilePath)
 	}
 
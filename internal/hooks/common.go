@@ -1,6 +1,8 @@
 package hooks
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -9,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/opstats"
+	"github.com/johncoder/jot/internal/safemode"
 	"github.com/johncoder/jot/internal/workspace"
 )
 
@@ -27,6 +32,19 @@ const (
 	WorkspaceChange HookType = "workspace-change"
 )
 
+// AllTypes lists every hook type in the order operations run them
+var AllTypes = []HookType{PreCapture, PostCapture, PreRefile, PostRefile, PreArchive, PostArchive, PreEval, PostEval, WorkspaceChange}
+
+// Command returns the jot command a hook type belongs to, the key used for
+// per-command hook configuration
+func (t HookType) Command() string {
+	if t == WorkspaceChange {
+		return "workspace"
+	}
+	name := strings.TrimPrefix(string(t), "pre-")
+	return strings.TrimPrefix(name, "post-")
+}
+
 // HookContext contains the context information passed to hooks
 type HookContext struct {
 	Type         HookType
@@ -35,6 +53,7 @@ type HookContext struct {
 	SourceFile   string            // Source file for operations
 	DestPath     string            // Destination path for operations
 	TemplateName string            // Template name for capture
+	Diff         *Diff             // What the operation changed, for post- hooks
 	ExtraEnv     map[string]string // Additional environment variables
 	Timeout      time.Duration
 	AllowBypass  bool // Whether --no-verify flag was used
@@ -42,20 +61,40 @@ type HookContext struct {
 
 // HookResult contains the result of hook execution
 type HookResult struct {
-	Content  string // Modified content (for content hooks)
-	ExitCode int    // Hook exit code
-	Output   string // Hook stdout/stderr output
-	Aborted  bool   // Whether the operation should be aborted
-	Error    error  // Any execution error
+	Content  string  // Modified content (for content hooks)
+	ExitCode int     // Hook exit code
+	Output   string  // Hook stderr output, and stdout for hooks that do not process content
+	Aborted  bool    // Whether the operation should be aborted
+	Error    error   // Any execution error
+	Stages   []Stage // One entry per hook run, in order
+}
+
+// Stage records one hook's step through the pipeline
+type Stage struct {
+	Hook     string        // Hook file name
+	Path     string        // Full path to the hook
+	Input    string        // Content passed on stdin
+	Content  string        // Content after this hook
+	Output   string        // Hook stderr output
+	ExitCode int           // Hook exit code
+	Timeout  time.Duration // Timeout applied to this hook
+	Duration time.Duration // Time the hook took to run
+	TimedOut bool          // Whether the hook was killed by its timeout
 }
 
+// TimeoutDirective sets a per-hook timeout from a comment in the first lines
+// of the hook script, e.g. "# jot-timeout: 5s"
+const TimeoutDirective = "jot-timeout:"
+
 // Manager handles hook discovery and execution
 type Manager struct {
 	workspace      *workspace.Workspace
 	hooksDir       string
 	globalHooksDir string
 	enabled        bool
+	safe           bool // Safe mode is on, so no hook runs
 	timeout        time.Duration
+	config         workspace.HooksConfig
 }
 
 // NewManager creates a new hook manager for the given workspace
@@ -66,18 +105,110 @@ func NewManager(ws *workspace.Workspace) *Manager {
 	homeDir, _ := os.UserHomeDir()
 	globalHooksDir := filepath.Join(homeDir, ".jot", "hooks")
 
-	return &Manager{
+	m := &Manager{
 		workspace:      ws,
 		hooksDir:       hooksDir,
 		globalHooksDir: globalHooksDir,
-		enabled:        true,             // Default enabled, configurable in future releases
-		timeout:        30 * time.Second, // Default 30s timeout, configurable in future releases
+		enabled:        true,
+		safe:           safemode.Enabled(),
+		timeout:        30 * time.Second,
+	}
+	if ws.Config != nil && ws.Config.Hooks != nil {
+		m.config = *ws.Config.Hooks
+		if m.config.Enabled != nil {
+			m.enabled = *m.config.Enabled
+		}
+	}
+	return m
+}
+
+// Enabled reports whether hooks run in the workspace at all
+func (m *Manager) Enabled() bool {
+	return m.enabled && !m.safe
+}
+
+// Active returns the hooks that would run for a hook type, in order, after
+// the command's allow and deny lists. None run while hooks are disabled or
+// safe mode is on.
+func (m *Manager) Active(hookType HookType) ([]string, error) {
+	if !m.Enabled() {
+		return nil, nil
+	}
+	return m.findHooks(hookType)
+}
+
+// Script is a hook script found for a hook type
+type Script struct {
+	Path   string
+	Type   HookType
+	Global bool // In ~/.jot/hooks rather than the workspace
+	Active bool // Runs for its hook type, as Active reports
+}
+
+// Scripts returns every executable hook script for every hook type, in the
+// order they run, with whether hooks being enabled and the allow and deny
+// lists let it run. Global scripts are only listed for types without
+// workspace scripts, as only then are they used.
+func (m *Manager) Scripts() ([]Script, error) {
+	var scripts []Script
+	for _, hookType := range AllTypes {
+		paths, err := m.findHooksInDir(m.hooksDir, hookType)
+		if err != nil {
+			return nil, err
+		}
+		global := len(paths) == 0
+		if global {
+			if paths, err = m.findHooksInDir(m.globalHooksDir, hookType); err != nil {
+				return nil, err
+			}
+		}
+		active := map[string]bool{}
+		if m.Enabled() {
+			for _, path := range m.filterHooks(paths, hookType) {
+				active[path] = true
+			}
+		}
+		for _, path := range paths {
+			scripts = append(scripts, Script{Path: path, Type: hookType, Global: global, Active: active[path]})
+		}
 	}
+	return scripts, nil
 }
 
-// Execute runs hooks for the given context
+// Execute runs hooks for the given context. Hooks run in order as a
+// pipeline: for content hooks, each hook's stdout becomes the next hook's
+// stdin, and the last hook's stdout replaces the content.
 func (m *Manager) Execute(ctx *HookContext) (*HookResult, error) {
-	if !m.enabled || ctx.AllowBypass {
+	// Hooks may have side effects, so none run in dry-run mode
+	if ctx.AllowBypass || dryrun.Enabled() {
+		return &HookResult{Content: ctx.Content}, nil
+	}
+
+	return m.run(ctx)
+}
+
+// Trace runs the hook pipeline like Execute, including in dry-run mode, so the
+// content at each stage can be inspected
+func (m *Manager) Trace(ctx *HookContext) (*HookResult, error) {
+	return m.run(ctx)
+}
+
+// AbortError reports the hook that stopped an operation
+type AbortError struct {
+	Type     HookType
+	Hook     string // The hook's file name
+	ExitCode int
+	TimedOut bool
+	Err      error
+}
+
+func (e *AbortError) Error() string { return e.Err.Error() }
+
+func (e *AbortError) Unwrap() error { return e.Err }
+
+// run executes each hook for ctx.Type in order, stopping at the first failure
+func (m *Manager) run(ctx *HookContext) (*HookResult, error) {
+	if !m.enabled {
 		return &HookResult{Content: ctx.Content}, nil
 	}
 
@@ -86,36 +217,32 @@ func (m *Manager) Execute(ctx *HookContext) (*HookResult, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	if len(hooks) == 0 {
+	if m.safe && len(hooks) > 0 {
+		safemode.Skip(string(ctx.Type) + " hooks")
 		return &HookResult{Content: ctx.Content}, nil
 	}
 
 	result := &HookResult{Content: ctx.Content}
+	start := time.Now()
+	defer func() { opstats.Hook(time.Since(start)) }()
 
 	// Execute hooks in order
 	for _, hookPath := range hooks {
-		hookResult, err := m.executeHook(hookPath, ctx, result.Content)
+		stage, err := m.executeHook(hookPath, ctx, result.Content)
+		result.Stages = append(result.Stages, *stage)
 		if err != nil {
-			return &HookResult{
-				Content: ctx.Content,
-				Error:   err,
-				Aborted: true,
-			}, err
-		}
-
-		if hookResult.ExitCode != 0 {
-			return &HookResult{
-				Content:  ctx.Content,
-				ExitCode: hookResult.ExitCode,
-				Output:   hookResult.Output,
-				Aborted:  true,
-			}, fmt.Errorf("hook %s failed with exit code %d", filepath.Base(hookPath), hookResult.ExitCode)
+			err = &AbortError{Type: ctx.Type, Hook: stage.Hook, ExitCode: stage.ExitCode, TimedOut: stage.TimedOut, Err: err}
+			result.Content = ctx.Content
+			result.ExitCode = stage.ExitCode
+			result.Output += stage.Output
+			result.Error = err
+			result.Aborted = true
+			return result, err
 		}
 
 		// Update content for next hook
-		result.Content = hookResult.Content
-		result.Output += hookResult.Output
+		result.Content = stage.Content
+		result.Output += stage.Output
 	}
 
 	return result, nil
@@ -141,7 +268,42 @@ func (m *Manager) findHooks(hookType HookType) ([]string, error) {
 		hooks = append(hooks, globalHooks...)
 	}
 
-	return hooks, nil
+	return m.filterHooks(hooks, hookType), nil
+}
+
+// filterHooks drops hooks excluded by the command's allow and deny lists.
+// Deny wins when a hook matches both.
+func (m *Manager) filterHooks(hooks []string, hookType HookType) []string {
+	command, ok := m.config.Commands[hookType.Command()]
+	if !ok {
+		return hooks
+	}
+
+	var allowed []string
+	for _, hookPath := range hooks {
+		name := filepath.Base(hookPath)
+		if len(command.Allow) > 0 && !matchesAny(name, command.Allow) {
+			continue
+		}
+		if matchesAny(name, command.Deny) {
+			continue
+		}
+		allowed = append(allowed, hookPath)
+	}
+	return allowed
+}
+
+// matchesAny reports whether name equals or matches any of the glob patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == name {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // findHooksInDir finds hooks in a specific directory
@@ -154,7 +316,8 @@ func (m *Manager) findHooksInDir(dir string, hookType HookType) ([]string, error
 		hooks = append(hooks, exactHook)
 	}
 
-	// Check for numbered hooks (git style: pre-commit.01, pre-commit.02)
+	// Check for numbered hooks (git style: pre-commit.01, pre-commit.02),
+	// which run after the exact match in lexical order
 	pattern := string(hookType) + ".*"
 	matches, err := filepath.Glob(filepath.Join(dir, pattern))
 	if err != nil {
@@ -167,6 +330,11 @@ func (m *Manager) findHooksInDir(dir string, hookType HookType) ([]string, error
 			continue
 		}
 
+		// Samples are never run, even if made executable
+		if strings.HasSuffix(match, ".sample") {
+			continue
+		}
+
 		if m.isExecutableHook(match) {
 			hooks = append(hooks, match)
 		}
@@ -191,44 +359,135 @@ func (m *Manager) isExecutableHook(path string) bool {
 	return stat.Mode()&0111 != 0
 }
 
-// executeHook runs a single hook
-func (m *Manager) executeHook(hookPath string, ctx *HookContext, content string) (*HookResult, error) {
+// executeHook runs a single hook, returning the stage it produced. A hook
+// that exits non-zero or times out returns an error along with its stage.
+func (m *Manager) executeHook(hookPath string, ctx *HookContext, content string) (*Stage, error) {
+	stage := &Stage{
+		Hook:    filepath.Base(hookPath),
+		Path:    hookPath,
+		Input:   content,
+		Content: content,
+		Timeout: m.hookTimeout(hookPath, ctx),
+	}
+
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(context.Background(), ctx.Timeout)
+	execCtx, cancel := context.WithTimeout(context.Background(), stage.Timeout)
 	defer cancel()
 
 	// Create command
 	cmd := exec.CommandContext(execCtx, hookPath)
 
+	// Don't wait on children of a killed hook that still hold its pipes open
+	cmd.WaitDelay = time.Second
+
 	// Set up environment
 	cmd.Env = m.buildEnvironment(ctx)
 
-	// Set up stdin with content for content-processing hooks
+	// Set up stdin with content for content-processing hooks, and with what
+	// changed for post- hooks
 	if m.isContentHook(ctx.Type) {
 		cmd.Stdin = strings.NewReader(content)
+	} else if ctx.Diff != nil {
+		cmd.Stdin = bytes.NewReader(ctx.Diff.JSON())
 	}
 
-	// Capture output
-	output, err := cmd.CombinedOutput()
+	// Keep stdout and stderr apart so diagnostics never leak into content
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	started := time.Now()
+	err := cmd.Run()
+	stage.Duration = time.Since(started)
+	stage.ExitCode = cmd.ProcessState.ExitCode()
+	stage.Output = stderr.String()
 
-	result := &HookResult{
-		ExitCode: cmd.ProcessState.ExitCode(),
-		Output:   string(output),
+	if execCtx.Err() == context.DeadlineExceeded {
+		stage.TimedOut = true
+		return stage, fmt.Errorf("hook %s timed out after %s", stage.Hook, stage.Timeout)
+	}
+	if stage.ExitCode != 0 {
+		return stage, fmt.Errorf("hook %s failed with exit code %d", stage.Hook, stage.ExitCode)
+	}
+	if err != nil {
+		return stage, fmt.Errorf("failed to run hook %s: %w", stage.Hook, err)
 	}
 
 	// For content hooks, use stdout as the new content
-	if m.isContentHook(ctx.Type) && result.ExitCode == 0 {
-		result.Content = string(output)
+	if m.isContentHook(ctx.Type) {
+		stage.Content = stdout.String()
 	} else {
-		result.Content = content
+		stage.Output = stdout.String() + stage.Output
 	}
 
-	return result, err
+	return stage, nil
+}
+
+// hookTimeout returns the timeout for a hook. The first of these wins: the
+// hook's entry in the configured timeouts, a TimeoutDirective in the script's
+// first lines, the command's configured timeout, the workspace's configured
+// timeout, the context timeout, then the default.
+func (m *Manager) hookTimeout(hookPath string, ctx *HookContext) time.Duration {
+	name := filepath.Base(hookPath)
+	if seconds := m.config.Timeouts[name]; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if timeout := scriptTimeout(hookPath); timeout > 0 {
+		return timeout
+	}
+	if seconds := m.config.Commands[ctx.Type.Command()].Timeout; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if m.config.Timeout > 0 {
+		return time.Duration(m.config.Timeout) * time.Second
+	}
+	if ctx.Timeout > 0 {
+		return ctx.Timeout
+	}
+	return m.timeout
+}
+
+// scriptTimeout reads a TimeoutDirective from the first lines of a hook
+// script, returning zero when there is none
+func scriptTimeout(hookPath string) time.Duration {
+	file, err := os.Open(hookPath)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < 10 && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
+			continue
+		}
+		idx := strings.Index(line, TimeoutDirective)
+		if idx < 0 {
+			continue
+		}
+		value := strings.TrimSpace(line[idx+len(TimeoutDirective):])
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return 0
 }
 
 // buildEnvironment creates the environment variables for hook execution
 func (m *Manager) buildEnvironment(ctx *HookContext) []string {
-	env := os.Environ()
+	var env []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if len(m.config.EnvAllow) == 0 || matchesAny(name, m.config.EnvAllow) {
+			env = append(env, entry)
+		}
+	}
+
+	// Configured variables
+	for key, value := range m.config.Env {
+		env = append(env, key+"="+value)
+	}
 
 	// Standard hook environment
 	env = append(env, "JOT_HOOK_TYPE="+string(ctx.Type))
@@ -268,7 +527,7 @@ func (m *Manager) isContentHook(hookType HookType) bool {
 
 // CreateSampleHooks creates sample hook files in the workspace
 func (m *Manager) CreateSampleHooks() error {
-	if err := os.MkdirAll(m.hooksDir, 0755); err != nil {
+	if err := dryrun.MkdirAll(m.hooksDir, 0755); err != nil {
 		return err
 	}
 
@@ -284,7 +543,7 @@ func (m *Manager) CreateSampleHooks() error {
 	for filename, content := range samples {
 		path := filepath.Join(m.hooksDir, filename)
 		if _, err := os.Stat(path); os.IsNotExist(err) {
-			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			if err := dryrun.WriteFile(path, []byte(content), 0644); err != nil {
 				return err
 			}
 		}
@@ -363,6 +622,9 @@ const samplePostRefileHook = `#!/bin/bash
 # JOT_HOOK_TYPE=post-refile
 # JOT_SOURCE_FILE=source_file
 # JOT_DEST_PATH=destination_path
+#
+# The lines added and removed in each file are on stdin as JSON, e.g.
+# {"files": [{"file": "work.md", "added": [{"start_line": 3, ...}], ...}]}
 
 # Example: Log refile operations
 echo "$(date): Refiled from $JOT_SOURCE_FILE to $JOT_DEST_PATH" >> "$JOT_WORKSPACE_ROOT/.jot/refile.log"
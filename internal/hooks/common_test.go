@@ -0,0 +1,152 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot")}
+	hooksDir := filepath.Join(ws.JotDir, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeHook(t, hooksDir, "pre-capture.02", "tr a-z A-Z\n")
+	writeHook(t, hooksDir, "pre-capture.01", "echo tagging >&2\nsed 's/$/ #work/'\n")
+	writeHook(t, hooksDir, "pre-capture.sample", "echo never\n")
+
+	m := &Manager{workspace: ws, hooksDir: hooksDir, enabled: true, timeout: time.Second}
+	result, err := m.Trace(&HookContext{Type: PreCapture, Workspace: ws, Content: "note\n"})
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if result.Content != "NOTE #WORK\n" {
+		t.Errorf("content = %q", result.Content)
+	}
+	if len(result.Stages) != 2 || result.Stages[0].Hook != "pre-capture.01" || result.Stages[1].Hook != "pre-capture.02" {
+		t.Fatalf("stages = %+v", result.Stages)
+	}
+	if result.Stages[0].Output != "tagging\n" || result.Stages[0].Content != "note #work\n" {
+		t.Errorf("stage 1 = %+v", result.Stages[0])
+	}
+
+	writeHook(t, hooksDir, "pre-capture.03", "# jot-timeout: 100ms\nsleep 5\n")
+	result, err = m.Trace(&HookContext{Type: PreCapture, Workspace: ws, Content: "note\n"})
+	if err == nil || !result.Aborted {
+		t.Fatalf("expected timeout, got %+v", result)
+	}
+	last := result.Stages[len(result.Stages)-1]
+	if !last.TimedOut || last.Timeout != 100*time.Millisecond {
+		t.Errorf("last stage = %+v", last)
+	}
+	if result.Content != "note\n" {
+		t.Errorf("aborted content = %q, want original", result.Content)
+	}
+}
+
+func TestCommandConfig(t *testing.T) {
+	root := t.TempDir()
+	hooksDir := filepath.Join(root, ".jot", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeHook(t, hooksDir, "pre-capture.01-tag", "sed 's/$/ #tag/'\n")
+	writeHook(t, hooksDir, "pre-capture.02-env", "cat; echo \"${HOME:-unset} $JOT_LABEL\"\n")
+	writeHook(t, hooksDir, "pre-capture.03-slow", "cat\n")
+
+	ws := &workspace.Workspace{
+		Root:   root,
+		JotDir: filepath.Join(root, ".jot"),
+		Config: &workspace.WorkspaceConfig{Hooks: &workspace.HooksConfig{
+			Timeouts: map[string]int{"pre-capture.01-tag": 7},
+			Env:      map[string]string{"JOT_LABEL": "configured"},
+			EnvAllow: []string{"PATH"},
+			Commands: map[string]workspace.HookCommandConfig{
+				"capture": {Allow: []string{"pre-capture.0*"}, Deny: []string{"*-slow"}, Timeout: 3},
+			},
+		}},
+	}
+
+	m := NewManager(ws)
+	m.globalHooksDir = filepath.Join(root, "none")
+	result, err := m.Trace(&HookContext{Type: PreCapture, Workspace: ws, Content: "note\n"})
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if len(result.Stages) != 2 {
+		t.Fatalf("stages = %+v, want slow hook denied", result.Stages)
+	}
+	if result.Stages[0].Timeout != 7*time.Second || result.Stages[1].Timeout != 3*time.Second {
+		t.Errorf("timeouts = %s, %s", result.Stages[0].Timeout, result.Stages[1].Timeout)
+	}
+	if result.Content != "note #tag\nunset configured\n" {
+		t.Errorf("content = %q", result.Content)
+	}
+	if active, _ := m.Active(PreCapture); len(active) != 2 {
+		t.Errorf("active = %v, want the two allowed hooks", active)
+	}
+	scripts, err := m.Scripts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 3 || !scripts[0].Active || !scripts[1].Active || scripts[2].Active || scripts[2].Type != PreCapture {
+		t.Errorf("scripts = %+v, want three pre-capture hooks with the slow one inactive", scripts)
+	}
+
+	disabled := false
+	ws.Config.Hooks.Enabled = &disabled
+	result, _ = NewManager(ws).Trace(&HookContext{Type: PreCapture, Workspace: ws, Content: "note\n"})
+	if len(result.Stages) != 0 || result.Content != "note\n" {
+		t.Errorf("disabled hooks ran: %+v", result)
+	}
+	if active, _ := NewManager(ws).Active(PreCapture); active != nil {
+		t.Errorf("active while disabled = %v", active)
+	}
+}
+
+func TestSafeModeSkipsHooks(t *testing.T) {
+	root := t.TempDir()
+	ws := &workspace.Workspace{Root: root, JotDir: filepath.Join(root, ".jot")}
+	hooksDir := filepath.Join(ws.JotDir, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(root, "ran")
+	writeHook(t, hooksDir, "pre-capture.01", "touch "+marker+"\ncat\n")
+
+	safemode.Configure(true, "testing")
+	defer safemode.Configure(false, "")
+
+	m := NewManager(ws)
+	m.globalHooksDir = filepath.Join(root, "none")
+	if m.Enabled() {
+		t.Error("hooks are enabled in safe mode")
+	}
+	result, err := m.Execute(&HookContext{Type: PreCapture, Workspace: ws, Content: "note\n"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Content != "note\n" || result.Aborted {
+		t.Errorf("result = %+v, want the content unchanged", result)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("a hook ran in safe mode")
+	}
+	if active, _ := m.Active(PreCapture); active != nil {
+		t.Errorf("active in safe mode = %v", active)
+	}
+}
@@ -0,0 +1,188 @@
+package hooks
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/diff"
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/storage"
+)
+
+// Diff is what an operation changed, which post- hooks read as JSON on
+// stdin so they can act on just the lines that changed
+type Diff struct {
+	Files []FileDiff `json:"files"`
+}
+
+// FileDiff is the lines added to and removed from one file
+type FileDiff struct {
+	File    string  `json:"file"`    // Relative to the workspace root
+	Created bool    `json:"created"` // The file did not exist before
+	Deleted bool    `json:"deleted"` // The file no longer exists
+	Added   []Range `json:"added"`
+	Removed []Range `json:"removed"`
+}
+
+// Range is a run of lines added or removed. Lines are 1-based, counted in
+// the file after the change for added lines and before it for removed ones.
+type Range struct {
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	HeadingPath string `json:"heading_path"` // Heading the first line is under, or is; empty before any heading
+	Text        string `json:"text"`
+}
+
+// Snapshot holds files as they were before an operation, to diff them
+// against once it is done
+type Snapshot struct {
+	root    string
+	paths   []string
+	before  map[string][]byte
+	existed map[string]bool
+}
+
+// TakeSnapshot reads paths, which need not exist, for a later Diff. root is
+// the workspace root the diff's file names are relative to.
+func TakeSnapshot(root string, paths ...string) *Snapshot {
+	s := &Snapshot{root: root, before: map[string][]byte{}, existed: map[string]bool{}}
+	for _, path := range paths {
+		if _, seen := s.before[path]; seen || path == "" {
+			continue
+		}
+		content, err := storage.ReadFile(path)
+		s.paths = append(s.paths, path)
+		s.before[path] = content
+		s.existed[path] = err == nil
+	}
+	return s
+}
+
+// Diff compares the files with how they were when the snapshot was taken,
+// leaving out files that did not change. A nil snapshot has no diff.
+func (s *Snapshot) Diff() *Diff {
+	if s == nil {
+		return nil
+	}
+	d := &Diff{Files: []FileDiff{}}
+	for _, path := range s.paths {
+		before := s.before[path]
+		after, err := storage.ReadFile(path)
+		exists := err == nil
+		if string(before) == string(after) && exists == s.existed[path] {
+			continue
+		}
+		file := FileDiff{
+			File:    s.relative(path),
+			Created: !s.existed[path],
+			Deleted: !exists,
+		}
+		file.Removed, file.Added = changedRanges(before, after)
+		d.Files = append(d.Files, file)
+	}
+	return d
+}
+
+func (s *Snapshot) relative(path string) string {
+	if rel, err := filepath.Rel(s.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// JSON encodes the diff as post- hooks read it
+func (d *Diff) JSON() []byte {
+	data, _ := json.Marshal(d)
+	return append(data, '\n')
+}
+
+// changedRanges returns the runs of lines removed from before and added in
+// after. Lines both share at the start and end are set aside first, so a
+// change to a large file only diffs the part that changed.
+func changedRanges(before, after []byte) (removed, added []Range) {
+	a := diff.SplitLines(string(before))
+	b := diff.SplitLines(string(after))
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	beforeHeadings := headingLines(before)
+	afterHeadings := headingLines(after)
+	removed, added = []Range{}, []Range{}
+	var run *Range
+	var runKind diff.Kind
+	flush := func() {
+		if run == nil {
+			return
+		}
+		if runKind == diff.Delete {
+			run.HeadingPath = headingAt(beforeHeadings, run.StartLine)
+			removed = append(removed, *run)
+		} else {
+			run.HeadingPath = headingAt(afterHeadings, run.StartLine)
+			added = append(added, *run)
+		}
+		run = nil
+	}
+	for _, line := range diff.Lines(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if run != nil && line.Kind != runKind {
+			flush()
+		}
+		if line.Kind == diff.Equal {
+			continue
+		}
+		number := prefix + line.BIndex + 1
+		if line.Kind == diff.Delete {
+			number = prefix + line.AIndex + 1
+		}
+		if run == nil {
+			run = &Range{StartLine: number}
+			runKind = line.Kind
+		} else {
+			run.Text += "\n"
+		}
+		run.EndLine = number
+		run.Text += line.Text
+	}
+	flush()
+	return removed, added
+}
+
+// headingLine is a heading's path and the line it is on
+type headingLine struct {
+	line int
+	path string
+}
+
+func headingLines(content []byte) []headingLine {
+	if len(content) == 0 {
+		return nil
+	}
+	doc := markdown.ParseDocument(content)
+	var lines []headingLine
+	for _, heading := range markdown.FindAllHeadings(doc, content) {
+		lines = append(lines, headingLine{
+			line: markdown.CalculateLineNumber(content, heading.Offset),
+			path: strings.Join(heading.Path, "/"),
+		})
+	}
+	return lines
+}
+
+// headingAt returns the path of the last heading on or before line
+func headingAt(headings []headingLine, line int) string {
+	path := ""
+	for _, heading := range headings {
+		if heading.line > line {
+			break
+		}
+		path = heading.path
+	}
+	return path
+}
@@ -0,0 +1,52 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedRanges(t *testing.T) {
+	before := "# Work\nintro\n## Alpha\nold\n## Beta\nkeep\n"
+	after := "# Work\nintro\n## Alpha\nnew\nmore\n## Beta\nkeep\n## Gamma\nadded\n"
+
+	removed, added := changedRanges([]byte(before), []byte(after))
+	wantRemoved := []Range{{StartLine: 4, EndLine: 4, HeadingPath: "Work/Alpha", Text: "old"}}
+	wantAdded := []Range{
+		{StartLine: 4, EndLine: 5, HeadingPath: "Work/Alpha", Text: "new\nmore"},
+		{StartLine: 8, EndLine: 9, HeadingPath: "Work/Gamma", Text: "## Gamma\nadded"},
+	}
+	if !reflect.DeepEqual(removed, wantRemoved) {
+		t.Errorf("removed = %+v, want %+v", removed, wantRemoved)
+	}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("added = %+v, want %+v", added, wantAdded)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	root := t.TempDir()
+	kept := filepath.Join(root, "kept.md")
+	created := filepath.Join(root, "lib", "new.md")
+	if err := os.WriteFile(kept, []byte("# Kept\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := TakeSnapshot(root, kept, created, kept)
+	if err := os.MkdirAll(filepath.Dir(created), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(created, []byte("# New\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := snapshot.Diff()
+	if len(d.Files) != 1 {
+		t.Fatalf("files = %+v, want only the created one", d.Files)
+	}
+	file := d.Files[0]
+	if file.File != "lib/new.md" || !file.Created || len(file.Added) != 1 || len(file.Removed) != 0 {
+		t.Errorf("file = %+v", file)
+	}
+}
@@ -0,0 +1,181 @@
+// Package indexpage renders a workspace index page and keeps it between
+// managed markers, so text outside the markers survives regeneration.
+package indexpage
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marker prefixes delimiting the generated block
+const (
+	StartMarker = "<!-- jot:index:start"
+	EndMarker   = "<!-- jot:index:end -->"
+)
+
+// Grouping modes
+const (
+	GroupByDirectory = "directory"
+	GroupByTag       = "tag"
+)
+
+// UntaggedGroup holds files without tags when grouping by tag
+const UntaggedGroup = "Untagged"
+
+// Entry is one library file listed in the index
+type Entry struct {
+	Path  string   // Workspace-relative path, with forward slashes
+	Title string   // First heading, or the file name
+	Tags  []string // Tags from frontmatter
+}
+
+// Block is the managed region of an index page
+type Block struct {
+	Start, End int    // Byte range including both markers
+	GroupBy    string // Grouping recorded in the start marker
+}
+
+// Find locates the managed block in content
+func Find(content string) (*Block, bool) {
+	start := strings.Index(content, StartMarker)
+	if start < 0 {
+		return nil, false
+	}
+	endRel := strings.Index(content[start:], EndMarker)
+	if endRel < 0 {
+		return nil, false
+	}
+
+	block := &Block{Start: start, End: start + endRel + len(EndMarker), GroupBy: GroupByDirectory}
+	markerEnd := strings.Index(content[start:], "-->")
+	for _, field := range strings.Fields(content[start+len(StartMarker) : start+markerEnd]) {
+		if value, ok := strings.CutPrefix(field, "group-by="); ok {
+			block.GroupBy = value
+		}
+	}
+	return block, true
+}
+
+// Update returns content with the managed block replaced by body. Without a
+// block, one is appended to content.
+func Update(content, body, groupBy string) string {
+	block := fmt.Sprintf("%s group-by=%s -->\n%s%s", StartMarker, groupBy, body, EndMarker)
+
+	if existing, ok := Find(content); ok {
+		return content[:existing.Start] + block + content[existing.End:]
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" && !strings.HasSuffix(content, "\n\n") {
+		content += "\n"
+	}
+	return content + block + "\n"
+}
+
+// Render lists entries grouped by directory or tag, as markdown linking
+// relative to the directory holding the index page
+func Render(entries []Entry, groupBy, indexDir string) string {
+	groups := make(map[string][]Entry)
+	for _, entry := range entries {
+		for _, group := range groupNames(entry, groupBy) {
+			groups[group] = append(groups[group], entry)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// Untagged files come last
+		if (names[i] == UntaggedGroup) != (names[j] == UntaggedGroup) {
+			return names[j] == UntaggedGroup
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		members := groups[name]
+		sort.Slice(members, func(i, j int) bool { return members[i].Path < members[j].Path })
+		for _, entry := range members {
+			fmt.Fprintf(&b, "- [%s](%s)\n", entry.Title, relativeLink(indexDir, entry.Path))
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func groupNames(entry Entry, groupBy string) []string {
+	if groupBy == GroupByTag {
+		if len(entry.Tags) == 0 {
+			return []string{UntaggedGroup}
+		}
+		return entry.Tags
+	}
+	return []string{path.Dir(entry.Path)}
+}
+
+// relativeLink returns target relative to dir, both workspace-relative
+func relativeLink(dir, target string) string {
+	if dir == "" || dir == "." {
+		return target
+	}
+	dirParts := strings.Split(dir, "/")
+	targetParts := strings.Split(target, "/")
+
+	common := 0
+	for common < len(dirParts) && common < len(targetParts)-1 && dirParts[common] == targetParts[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(dirParts)-common+len(targetParts)-common)
+	for range dirParts[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, targetParts[common:]...)
+	return strings.Join(parts, "/")
+}
+
+// Tags reads the tags listed in a file's YAML frontmatter. Tags may be a list
+// or a comma-separated string.
+func Tags(content string) []string {
+	if !strings.HasPrefix(content, "---\n") {
+		return nil
+	}
+	end := strings.Index(content[4:], "\n---")
+	if end < 0 {
+		return nil
+	}
+
+	var frontmatter struct {
+		Tags interface{} `yaml:"tags"`
+	}
+	if err := yaml.Unmarshal([]byte(content[4:4+end]), &frontmatter); err != nil {
+		return nil
+	}
+
+	var tags []string
+	switch value := frontmatter.Tags.(type) {
+	case string:
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if tag := strings.TrimSpace(fmt.Sprint(item)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,116 @@
+package indexpage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			want:    "<!-- jot:index:start group-by=directory -->\nBODY<!-- jot:index:end -->\n",
+		},
+		{
+			name:    "append after manual text",
+			content: "# Index\n\nWelcome.",
+			want:    "# Index\n\nWelcome.\n\n<!-- jot:index:start group-by=directory -->\nBODY<!-- jot:index:end -->\n",
+		},
+		{
+			name:    "replace existing block",
+			content: "# Index\n\n<!-- jot:index:start group-by=tag -->\nold\n<!-- jot:index:end -->\n\n## Manual\n",
+			want:    "# Index\n\n<!-- jot:index:start group-by=directory -->\nBODY<!-- jot:index:end -->\n\n## Manual\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Update(tt.content, "BODY", GroupByDirectory); got != tt.want {
+				t.Errorf("Update() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	content := "x\n<!-- jot:index:start group-by=tag -->\nbody\n<!-- jot:index:end -->\ny"
+	block, ok := Find(content)
+	if !ok {
+		t.Fatal("Find() found no block")
+	}
+	if block.GroupBy != GroupByTag {
+		t.Errorf("GroupBy = %q, want %q", block.GroupBy, GroupByTag)
+	}
+	if got := content[block.Start:block.End]; !strings.HasPrefix(got, StartMarker) || !strings.HasSuffix(got, EndMarker) {
+		t.Errorf("block = %q", got)
+	}
+
+	if _, ok := Find("<!-- jot:index:start -->\nno end"); ok {
+		t.Error("Find() accepted a block without an end marker")
+	}
+	if block, _ := Find("<!-- jot:index:start -->\n<!-- jot:index:end -->"); block.GroupBy != GroupByDirectory {
+		t.Errorf("default GroupBy = %q", block.GroupBy)
+	}
+}
+
+func TestRender(t *testing.T) {
+	entries := []Entry{
+		{Path: "lib/b.md", Title: "B", Tags: []string{"work"}},
+		{Path: "lib/projects/a.md", Title: "A", Tags: []string{"work", "api"}},
+		{Path: "lib/c.md", Title: "C"},
+	}
+
+	byDir := Render(entries, GroupByDirectory, ".")
+	wantDir := "\n## lib\n\n- [B](lib/b.md)\n- [C](lib/c.md)\n\n## lib/projects\n\n- [A](lib/projects/a.md)\n\n"
+	if byDir != wantDir {
+		t.Errorf("Render(directory) = %q, want %q", byDir, wantDir)
+	}
+
+	byTag := Render(entries, GroupByTag, "lib")
+	wantTag := "\n## api\n\n- [A](projects/a.md)\n\n## work\n\n- [B](b.md)\n- [A](projects/a.md)\n\n## Untagged\n\n- [C](c.md)\n\n"
+	if byTag != wantTag {
+		t.Errorf("Render(tag) = %q, want %q", byTag, wantTag)
+	}
+}
+
+func TestRelativeLink(t *testing.T) {
+	tests := []struct{ dir, target, want string }{
+		{".", "lib/a.md", "lib/a.md"},
+		{"lib", "lib/a.md", "a.md"},
+		{"lib/x", "lib/y/a.md", "../y/a.md"},
+		{"notes", "lib/a.md", "../lib/a.md"},
+	}
+	for _, tt := range tests {
+		if got := relativeLink(tt.dir, tt.target); got != tt.want {
+			t.Errorf("relativeLink(%q, %q) = %q, want %q", tt.dir, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"list", "---\ntags: [a, b]\n---\n# X", []string{"a", "b"}},
+		{"block list", "---\ntags:\n  - a\n  - b\n---\n", []string{"a", "b"}},
+		{"string", "---\ntags: a, b\n---\n", []string{"a", "b"}},
+		{"no tags", "---\ntitle: x\n---\n", nil},
+		{"no frontmatter", "# X\ntags: a", nil},
+		{"invalid yaml", "---\ntags: [a\n---\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tags(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
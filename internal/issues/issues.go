@@ -0,0 +1,341 @@
+// Package issues reads issues and pull requests from GitHub and GitLab, and
+// writes comments back, so tracker items can be kept as TODO items in notes.
+// Only the REST calls jot needs are implemented.
+package issues
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/netutil"
+)
+
+// Tracker names
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Default API locations, for github.com and gitlab.com
+const (
+	DefaultGitHubAPI = "https://api.github.com"
+	DefaultGitLabAPI = "https://gitlab.com/api/v4"
+)
+
+// DefaultTimeout bounds each request to a tracker
+const DefaultTimeout = 30 * time.Second
+
+// Issue is an issue or pull request
+type Issue struct {
+	Tracker     string   `json:"tracker"`
+	Repo        string   `json:"repo"`
+	Number      int      `json:"number"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	State       string   `json:"state"` // "open" or "closed"
+	PullRequest bool     `json:"pull_request,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// Key identifies an issue across trackers, as "github:owner/name#12"
+func (i Issue) Key() string {
+	return Key(i.Tracker, i.Repo, i.Number)
+}
+
+// Key builds an issue key from its parts
+func Key(tracker, repo string, number int) string {
+	return fmt.Sprintf("%s:%s#%d", tracker, repo, number)
+}
+
+// ListOptions selects the issues to list
+type ListOptions struct {
+	Labels []string // Issues must have every label
+	State  string   // "open", "closed" or "all"
+	Limit  int      // At most this many issues; 0 for no limit
+}
+
+// Client talks to one tracker
+type Client struct {
+	Tracker string
+	APIURL  string
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewClient returns a client for tracker at apiURL, or at the tracker's
+// public API when apiURL is empty
+func NewClient(tracker, apiURL, token string) (*Client, error) {
+	if apiURL == "" {
+		switch tracker {
+		case GitHub:
+			apiURL = DefaultGitHubAPI
+		case GitLab:
+			apiURL = DefaultGitLabAPI
+		default:
+			return nil, fmt.Errorf("unknown issue tracker %q (use github or gitlab)", tracker)
+		}
+	}
+	return &Client{
+		Tracker: tracker,
+		APIURL:  strings.TrimSuffix(apiURL, "/"),
+		Token:   token,
+		HTTP:    netutil.NewClient(DefaultTimeout),
+	}, nil
+}
+
+// perPage is the page size requested from both trackers
+const perPage = 100
+
+// List returns the issues of repo matching opts, newest first. GitHub
+// lists pull requests along with issues.
+func (c *Client) List(repo string, opts ListOptions) ([]Issue, error) {
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+
+	var issues []Issue
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(perPage))
+		query.Set("page", strconv.Itoa(page))
+		if len(opts.Labels) > 0 {
+			query.Set("labels", strings.Join(opts.Labels, ","))
+		}
+
+		var batch []Issue
+		var err error
+		switch c.Tracker {
+		case GitHub:
+			query.Set("state", state)
+			batch, err = c.listGitHub(repo, query)
+		case GitLab:
+			if state == "open" {
+				state = "opened"
+			}
+			if state != "all" {
+				query.Set("state", state)
+			}
+			batch, err = c.listGitLab(repo, query)
+		default:
+			return nil, fmt.Errorf("unknown issue tracker %q", c.Tracker)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, batch...)
+		if opts.Limit > 0 && len(issues) >= opts.Limit {
+			return issues[:opts.Limit], nil
+		}
+		if len(batch) < perPage {
+			return issues, nil
+		}
+	}
+}
+
+func (c *Client) listGitHub(repo string, query url.Values) ([]Issue, error) {
+	var raw []struct {
+		Number      int             `json:"number"`
+		Title       string          `json:"title"`
+		HTMLURL     string          `json:"html_url"`
+		State       string          `json:"state"`
+		PullRequest json.RawMessage `json:"pull_request"`
+		Labels      []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := c.do(http.MethodGet, "/repos/"+repo+"/issues?"+query.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(raw))
+	for _, r := range raw {
+		issue := Issue{
+			Tracker:     GitHub,
+			Repo:        repo,
+			Number:      r.Number,
+			Title:       r.Title,
+			URL:         r.HTMLURL,
+			State:       r.State,
+			PullRequest: len(r.PullRequest) > 0 && string(r.PullRequest) != "null",
+		}
+		for _, label := range r.Labels {
+			issue.Labels = append(issue.Labels, label.Name)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func (c *Client) listGitLab(repo string, query url.Values) ([]Issue, error) {
+	var raw []struct {
+		IID    int      `json:"iid"`
+		Title  string   `json:"title"`
+		WebURL string   `json:"web_url"`
+		State  string   `json:"state"`
+		Labels []string `json:"labels"`
+	}
+	if err := c.do(http.MethodGet, "/projects/"+url.PathEscape(repo)+"/issues?"+query.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	issues := make([]Issue, 0, len(raw))
+	for _, r := range raw {
+		state := r.State
+		if state == "opened" {
+			state = "open"
+		}
+		issues = append(issues, Issue{
+			Tracker: GitLab,
+			Repo:    repo,
+			Number:  r.IID,
+			Title:   r.Title,
+			URL:     r.WebURL,
+			State:   state,
+			Labels:  r.Labels,
+		})
+	}
+	return issues, nil
+}
+
+// Comment adds a comment to an issue or pull request
+func (c *Client) Comment(repo string, number int, body string) error {
+	switch c.Tracker {
+	case GitHub:
+		return c.do(http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), map[string]string{"body": body}, nil)
+	case GitLab:
+		return c.do(http.MethodPost, fmt.Sprintf("/projects/%s/issues/%d/notes", url.PathEscape(repo), number), map[string]string{"body": body}, nil)
+	}
+	return fmt.Errorf("unknown issue tracker %q", c.Tracker)
+}
+
+// do sends a request to the tracker's API and decodes a JSON response into
+// out, when out is not nil
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, c.APIURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("invalid %s API URL: %w", c.Tracker, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		if c.Tracker == GitLab {
+			req.Header.Set("PRIVATE-TOKEN", c.Token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", c.Tracker, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", c.Tracker, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s: %s", c.Tracker, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", c.Tracker, err)
+	}
+	return nil
+}
+
+// itemPattern matches a task item written by FormatItem: the checkbox, the
+// linked reference and the rest of the line
+var itemPattern = regexp.MustCompile(`^(\s*[-*+]\s+\[([ xX])\]\s+)\[([^\]#]+)#(\d+)\]\(([^)\s]+)\)(.*)$`)
+
+// FormatItem writes an issue as a task item, checked when it is closed:
+//
+//   - [ ] [owner/name#12](https://github.com/owner/name/issues/12) Fix crash (open)
+func FormatItem(issue Issue) string {
+	box := " "
+	if issue.State == "closed" {
+		box = "x"
+	}
+	return fmt.Sprintf("- [%s] [%s#%d](%s)%s", box, issue.Repo, issue.Number, issue.URL, itemSuffix(issue))
+}
+
+// itemSuffix is the part of an item after its reference: title and status
+func itemSuffix(issue Issue) string {
+	status := issue.State
+	if issue.PullRequest {
+		status += " pull request"
+	}
+	return fmt.Sprintf(" %s (%s)", issue.Title, status)
+}
+
+// Item is a task item that refers to an issue
+type Item struct {
+	Tracker string
+	Repo    string
+	Number  int
+	URL     string
+	Done    bool
+}
+
+// Key identifies the item's issue
+func (i Item) Key() string {
+	return Key(i.Tracker, i.Repo, i.Number)
+}
+
+// ParseItem reads a task item that refers to an issue. The tracker is told
+// from the link: GitLab links have "/-/" before "issues".
+func ParseItem(line string) (Item, bool) {
+	match := itemPattern.FindStringSubmatch(line)
+	if match == nil {
+		return Item{}, false
+	}
+	number, _ := strconv.Atoi(match[4])
+	item := Item{
+		Tracker: GitHub,
+		Repo:    match[3],
+		Number:  number,
+		URL:     match[5],
+		Done:    match[2] != " ",
+	}
+	switch {
+	case strings.Contains(item.URL, "/-/issues/"):
+		item.Tracker = GitLab
+	case !strings.Contains(item.URL, "/issues/") && !strings.Contains(item.URL, "/pull/"):
+		return Item{}, false
+	}
+	return item, true
+}
+
+// RefreshItem rewrites the title and status of a task item for issue,
+// keeping its checkbox as the user left it
+func RefreshItem(line string, issue Issue) string {
+	match := itemPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return line
+	}
+	return line[:match[11]+1] + itemSuffix(issue)
+}
@@ -0,0 +1,109 @@
+package issues
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListGitHub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/app/issues" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("labels"); got != "notes" {
+			t.Errorf("labels = %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(`[
+			{"number": 12, "title": "Fix crash", "html_url": "https://github.com/acme/app/issues/12", "state": "open", "labels": [{"name": "notes"}]},
+			{"number": 13, "title": "Add flag", "html_url": "https://github.com/acme/app/pull/13", "state": "closed", "pull_request": {}}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(GitHub, server.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := client.List("acme/app", ListOptions{Labels: []string{"notes"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(issues))
+	}
+	if issues[0].Key() != "github:acme/app#12" || issues[0].PullRequest || issues[0].Labels[0] != "notes" {
+		t.Errorf("issues[0] = %+v", issues[0])
+	}
+	if !issues[1].PullRequest || issues[1].State != "closed" {
+		t.Errorf("issues[1] = %+v", issues[1])
+	}
+}
+
+func TestListAndCommentGitLab(t *testing.T) {
+	var comment map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("missing GitLab token header")
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.EscapedPath() != "/projects/acme%2Fapp/issues" || r.URL.Query().Get("state") != "opened" {
+				t.Errorf("request = %s", r.URL)
+			}
+			w.Write([]byte(`[{"iid": 4, "title": "Docs", "web_url": "https://gitlab.com/acme/app/-/issues/4", "state": "opened"}]`))
+		case http.MethodPost:
+			if r.URL.EscapedPath() != "/projects/acme%2Fapp/issues/4/notes" {
+				t.Errorf("comment path = %s", r.URL.EscapedPath())
+			}
+			json.NewDecoder(r.Body).Decode(&comment)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(GitLab, server.URL, "secret")
+	issues, err := client.List("acme/app", ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].State != "open" || issues[0].Number != 4 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if err := client.Comment("acme/app", 4, "Done"); err != nil {
+		t.Fatal(err)
+	}
+	if comment["body"] != "Done" {
+		t.Errorf("comment = %v", comment)
+	}
+}
+
+func TestItems(t *testing.T) {
+	issue := Issue{Tracker: GitHub, Repo: "acme/app", Number: 12, Title: "Fix crash", URL: "https://github.com/acme/app/issues/12", State: "open"}
+	line := FormatItem(issue)
+	if line != "- [ ] [acme/app#12](https://github.com/acme/app/issues/12) Fix crash (open)" {
+		t.Errorf("FormatItem() = %q", line)
+	}
+
+	item, ok := ParseItem("  - [x] [acme/app#12](https://github.com/acme/app/issues/12) Fix crash (open)")
+	if !ok || !item.Done || item.Key() != "github:acme/app#12" {
+		t.Errorf("ParseItem() = %+v, %v", item, ok)
+	}
+	if item, ok := ParseItem("- [ ] [acme/app#4](https://gitlab.com/acme/app/-/issues/4) Docs (open)"); !ok || item.Tracker != GitLab {
+		t.Errorf("GitLab item = %+v, %v", item, ok)
+	}
+	if _, ok := ParseItem("- [ ] [notes#1](notes.md) Not an issue"); ok {
+		t.Error("ParseItem accepted a link that is not an issue")
+	}
+
+	issue.Title, issue.State = "Fix the crash", "closed"
+	refreshed := RefreshItem("- [x] [acme/app#12](https://github.com/acme/app/issues/12) Fix crash (open)", issue)
+	if refreshed != "- [x] [acme/app#12](https://github.com/acme/app/issues/12) Fix the crash (closed)" {
+		t.Errorf("RefreshItem() = %q", refreshed)
+	}
+}
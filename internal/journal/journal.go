@@ -0,0 +1,347 @@
+// Package journal makes multi-file writes recoverable. Before a command
+// writes, every file's content before and after the operation is recorded
+// in an entry under .jot/journal/. The entry is removed once all writes
+// have landed, so an entry left behind marks an operation that was killed
+// part way, which can then be rolled back or completed.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/storage"
+)
+
+// File is one file touched by an operation
+type File struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`           // Whether the file existed before the operation
+	Before  string `json:"before,omitempty"`  // Content before the operation
+	After   string `json:"after"`             // Content the operation writes
+	Removed bool   `json:"removed,omitempty"` // Whether the operation removes the file instead
+}
+
+// Entry records an operation in progress
+type Entry struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+	Files   []File    `json:"files"`
+
+	path string
+}
+
+// Write is a file's new content, or its removal
+type Write struct {
+	Path    string
+	Content []byte
+	Remove  bool
+}
+
+// Check inspects the files an operation wrote, keyed by path, as read back
+// from disk. Before holds nil for files the operation created.
+type Check func(before, after map[string][]byte) error
+
+var (
+	mu      sync.Mutex
+	dir     string
+	command string
+	verify  bool
+)
+
+// Configure sets the directory entries are kept in and the command line
+// recorded with them. With no directory, writes are not journaled.
+func Configure(journalDir, commandLine string) {
+	mu.Lock()
+	defer mu.Unlock()
+	dir = journalDir
+	command = commandLine
+}
+
+// Dir returns the configured journal directory
+func Dir() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return dir
+}
+
+// SetVerify turns verification of written files on or off
+func SetVerify(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	verify = on
+}
+
+// Verifying reports whether written files are verified
+func Verifying() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return verify
+}
+
+// Apply writes files in order, journaling them first. Nothing is written
+// unless every path passes the dryrun guard. If a write fails, the
+// files already written are restored. When verification is on, check is run
+// on the written files, and the operation is rolled back if it fails. In
+// dry-run mode, or with no journal directory and nothing to verify, the
+// files are written directly.
+func Apply(writes []Write, check Check) error {
+	for _, write := range writes {
+		if err := dryrun.Check(write.Path); err != nil {
+			return err
+		}
+	}
+
+	journalDir := Dir()
+	verifying := check != nil && Verifying()
+	if dryrun.Enabled() || (journalDir == "" && !verifying) {
+		for _, write := range writes {
+			if err := apply(write.Path, write.Content, write.Remove); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	entry, err := begin(journalDir, writes)
+	if err != nil {
+		return fmt.Errorf("failed to journal operation: %w", err)
+	}
+	for _, write := range writes {
+		if err := apply(write.Path, write.Content, write.Remove); err != nil {
+			return entry.abort(err)
+		}
+	}
+	if verifying {
+		if err := entry.verify(check); err != nil {
+			return entry.abort(fmt.Errorf("verification failed, so the changes were rolled back: %w", err))
+		}
+	}
+	return entry.Discard()
+}
+
+// abort rolls the operation back after err
+func (e *Entry) abort(err error) error {
+	if rollbackErr := e.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("%w (restoring the files also failed: %v; run 'jot recover')", err, rollbackErr)
+	}
+	return err
+}
+
+// verify reads the written files back and runs check on them
+func (e *Entry) verify(check Check) error {
+	before := make(map[string][]byte, len(e.Files))
+	after := make(map[string][]byte, len(e.Files))
+	for _, file := range e.Files {
+		if file.Existed {
+			before[file.Path] = []byte(file.Before)
+		}
+		if file.Removed {
+			continue
+		}
+		content, err := storage.ReadFile(file.Path)
+		if err != nil {
+			return err
+		}
+		after[file.Path] = content
+	}
+	return check(before, after)
+}
+
+// begin records the files' current and new content before any is written.
+// With no journal directory, the entry is only kept in memory.
+func begin(journalDir string, writes []Write) (*Entry, error) {
+	mu.Lock()
+	commandLine := command
+	mu.Unlock()
+
+	now := time.Now()
+	entry := &Entry{
+		ID:      fmt.Sprintf("%s-%d", now.UTC().Format("20060102T150405.000000000"), os.Getpid()),
+		Command: commandLine,
+		Started: now,
+	}
+	for _, write := range writes {
+		file := File{Path: write.Path, After: string(write.Content), Removed: write.Remove}
+		content, err := storage.ReadFile(write.Path)
+		switch {
+		case err == nil:
+			file.Existed = true
+			file.Before = string(content)
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+		entry.Files = append(entry.Files, file)
+	}
+	if journalDir == "" {
+		return entry, nil
+	}
+
+	if err := os.MkdirAll(journalDir, 0755); err != nil {
+		return nil, err
+	}
+	entry.path = filepath.Join(journalDir, entry.ID+".json")
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := syncWrite(entry.path, data); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Pending returns the entries left behind by interrupted operations, oldest
+// first. Entries that cannot be read are reported as errors.
+func Pending(journalDir string) ([]*Entry, error) {
+	paths, err := filepath.Glob(filepath.Join(journalDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var entries []*Entry
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		entry := &Entry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			return nil, fmt.Errorf("invalid journal entry %s: %w", filepath.Base(path), err)
+		}
+		entry.path = path
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Rollback restores every file to its content before the operation, removing
+// files it created, and discards the entry
+func (e *Entry) Rollback() error {
+	for _, file := range e.Files {
+		if !file.Existed {
+			if err := storage.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := writeFile(file.Path, []byte(file.Before)); err != nil {
+			return err
+		}
+	}
+	return e.Discard()
+}
+
+// Complete writes every file's new content, or removes it, finishing the
+// operation, and discards the entry
+func (e *Entry) Complete() error {
+	for _, file := range e.Files {
+		if err := apply(file.Path, []byte(file.After), file.Removed); err != nil {
+			return err
+		}
+	}
+	return e.Discard()
+}
+
+// Discard removes the entry without touching its files
+func (e *Entry) Discard() error {
+	if e.path == "" {
+		return nil
+	}
+	if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// State reports how far the operation got: "not-started" when no file has
+// its new content yet, "written" when every file has, and "partial" otherwise
+func (e *Entry) State() string {
+	written := 0
+	for _, file := range e.Files {
+		content, err := storage.ReadFile(file.Path)
+		if file.Removed && os.IsNotExist(err) || !file.Removed && err == nil && string(content) == file.After {
+			written++
+		}
+	}
+	switch written {
+	case 0:
+		return "not-started"
+	case len(e.Files):
+		return "written"
+	}
+	return "partial"
+}
+
+// Paths returns the files the operation touches
+func (e *Entry) Paths() []string {
+	paths := make([]string, len(e.Files))
+	for i, file := range e.Files {
+		paths[i] = file.Path
+	}
+	return paths
+}
+
+// String describes the entry on one line
+func (e *Entry) String() string {
+	return fmt.Sprintf("%s %q (%s)", e.Started.Local().Format("2006-01-02 15:04:05"), e.Command, strings.Join(e.Paths(), ", "))
+}
+
+// apply writes content to path, or removes the file. A file already gone
+// counts as removed.
+func apply(path string, content []byte, remove bool) error {
+	if !remove {
+		return writeFile(path, content)
+	}
+	if err := dryrun.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove file %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeFile(path string, content []byte) error {
+	if err := dryrun.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+	}
+	if err := dryrun.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	return nil
+}
+
+// syncWrite writes data to path and flushes it to disk, through a temporary
+// file so that a crash never leaves a truncated entry
+func syncWrite(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
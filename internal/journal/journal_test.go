@@ -0,0 +1,184 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyRemovesEntry(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "journal")
+	Configure(dir, "jot refile")
+	defer Configure("", "")
+
+	source := filepath.Join(root, "inbox.md")
+	dest := filepath.Join(root, "lib", "work.md")
+	writeTestFile(t, source, "# Inbox\n\n## Task\n")
+
+	err := Apply([]Write{
+		{Path: source, Content: []byte("# Inbox\n")},
+		{Path: dest, Content: []byte("## Task\n")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, dest); got != "## Task\n" {
+		t.Errorf("dest = %q", got)
+	}
+	entries, err := Pending(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("%d entries left after a complete operation", len(entries))
+	}
+}
+
+func TestApplyRollsBackFailedCheck(t *testing.T) {
+	root := t.TempDir()
+	SetVerify(true)
+	defer SetVerify(false)
+
+	path := filepath.Join(root, "inbox.md")
+	writeTestFile(t, path, "# Inbox\n\n## Task\n")
+
+	err := Apply([]Write{{Path: path, Content: []byte("# Inbox\n")}}, func(before, after map[string][]byte) error {
+		if string(after[path]) != "# Inbox\n" {
+			t.Errorf("check saw %q", after[path])
+		}
+		return errors.New("heading lost")
+	})
+	if err == nil || !strings.Contains(err.Error(), "heading lost") {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := readTestFile(t, path); got != "# Inbox\n\n## Task\n" {
+		t.Errorf("file was not restored: %q", got)
+	}
+}
+
+func TestRecoverInterruptedOperation(t *testing.T) {
+	for _, action := range []string{"rollback", "complete"} {
+		t.Run(action, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "journal")
+			Configure(dir, "jot refile inbox.md#task --to work.md")
+			defer Configure("", "")
+
+			source := filepath.Join(root, "inbox.md")
+			dest := filepath.Join(root, "work.md")
+			writeTestFile(t, source, "# Inbox\n\n## Task\n")
+
+			// Killed after the source was written but before the destination
+			if _, err := begin(dir, []Write{
+				{Path: source, Content: []byte("# Inbox\n")},
+				{Path: dest, Content: []byte("## Task\n")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, source, "# Inbox\n")
+
+			entries, err := Pending(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("found %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Command != "jot refile inbox.md#task --to work.md" {
+				t.Errorf("command = %q", entry.Command)
+			}
+			if state := entry.State(); state != "partial" {
+				t.Errorf("state = %q, want partial", state)
+			}
+
+			if action == "rollback" {
+				if err := entry.Rollback(); err != nil {
+					t.Fatal(err)
+				}
+				if got := readTestFile(t, source); got != "# Inbox\n\n## Task\n" {
+					t.Errorf("source = %q", got)
+				}
+				if _, err := os.Stat(dest); !os.IsNotExist(err) {
+					t.Errorf("created destination was not removed")
+				}
+			} else {
+				if err := entry.Complete(); err != nil {
+					t.Fatal(err)
+				}
+				if got := readTestFile(t, dest); got != "## Task\n" {
+					t.Errorf("dest = %q", got)
+				}
+			}
+
+			if entries, _ := Pending(dir); len(entries) != 0 {
+				t.Errorf("%d entries left after recovery", len(entries))
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestApplyMovesFile(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "journal")
+	Configure(dir, "jot mv old.md new.md")
+	defer Configure("", "")
+
+	source := filepath.Join(root, "old.md")
+	dest := filepath.Join(root, "lib", "new.md")
+	writeTestFile(t, source, "# Old\n")
+
+	if err := Apply([]Write{
+		{Path: dest, Content: []byte("# Old\n")},
+		{Path: source, Remove: true},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, dest); got != "# Old\n" {
+		t.Errorf("dest = %q", got)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Errorf("source was not removed")
+	}
+
+	// An interrupted move rolls back to the source alone
+	entry, err := begin(dir, []Write{
+		{Path: source, Content: []byte("# Old\n")},
+		{Path: dest, Remove: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, source, "# Old\n")
+	if state := entry.State(); state != "partial" {
+		t.Errorf("state = %q, want partial", state)
+	}
+	if err := entry.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Errorf("created file was not removed by rollback")
+	}
+	if got := readTestFile(t, dest); got != "# Old\n" {
+		t.Errorf("removed file was not restored: %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// syntheticDocument builds a document of about size bytes: top-level
+// sections, each with nested projects and tasks holding a paragraph and a
+// list, the shape a long-lived notes file grows into
+func syntheticDocument(size int) []byte {
+	var b strings.Builder
+	b.Grow(size + 1024)
+	body := "Notes about the work, with a [link](https://example.com) and some *emphasis*.\n\n- first item\n- second item\n\n"
+	for section := 0; b.Len() < size; section++ {
+		fmt.Fprintf(&b, "# Section %d\n\n", section)
+		for project := 0; project < 10 && b.Len() < size; project++ {
+			fmt.Fprintf(&b, "## Project %d-%d\n\n%s", section, project, body)
+			for task := 0; task < 5; task++ {
+				fmt.Fprintf(&b, "### Task %d-%d-%d\n\n%s", section, project, task, body)
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+const benchmarkDocumentSize = 1 << 20
+
+func BenchmarkParseDocument(b *testing.B) {
+	content := syntheticDocument(benchmarkDocumentSize)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lastParse.doc = nil
+		ParseDocument(content)
+	}
+}
+
+func BenchmarkFindSubtree(b *testing.B) {
+	content := syntheticDocument(benchmarkDocumentSize)
+	doc := ParseDocument(content)
+
+	selectors := []struct {
+		name     string
+		selector string
+	}{
+		{"contains", "notes.md#Task 40-5-3"},
+		{"path", "notes.md#Section 40/Project 40-5/Task 40-5-3"},
+		{"skip-levels", "notes.md#/Project 40-5/Task 40-5-3"},
+	}
+	for _, s := range selectors {
+		path, err := ParsePath(s.selector)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := FindSubtree(doc, content, path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindAllHeadings(b *testing.B) {
+	content := syntheticDocument(benchmarkDocumentSize)
+	doc := ParseDocument(content)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindAllHeadings(doc, content)
+	}
+}
+
+func BenchmarkExtractSubtree(b *testing.B) {
+	content := syntheticDocument(benchmarkDocumentSize)
+	doc := ParseDocument(content)
+	path, err := ParsePath("notes.md#Section 40")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		subtree, err := FindSubtree(doc, content, path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		TransformHeadingLevels(subtree.Content, 1)
+	}
+}
+
+func BenchmarkScanHeadings(b *testing.B) {
+	content := syntheticDocument(benchmarkDocumentSize)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scanner := NewHeadingScanner(content)
+		for scanner.Next() {
+			scanner.Heading().Text()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldDiacritics makes selector matching ignore accents when set
+var foldDiacritics atomic.Bool
+
+// SetFoldDiacritics turns diacritic-insensitive selector matching on or off.
+// When on, "cafe" matches a heading named "Café".
+func SetFoldDiacritics(on bool) {
+	foldDiacritics.Store(on)
+}
+
+// FoldDiacritics reports whether selector matching ignores diacritics
+func FoldDiacritics() bool {
+	return foldDiacritics.Load()
+}
+
+// Fold normalizes text for selector matching: surrounding space is trimmed,
+// the text is NFC-normalized and Unicode case-folded, so "STRASSE" and
+// "Straße" compare equal. With diacritic folding on, combining marks are
+// removed as well.
+func Fold(text string) string {
+	text = strings.TrimSpace(text)
+	if isASCII(text) {
+		return strings.ToLower(text)
+	}
+
+	if FoldDiacritics() {
+		stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn))), text)
+		if err == nil {
+			text = stripped
+		}
+	}
+	return norm.NFC.String(cases.Fold().String(norm.NFC.String(text)))
+}
+
+// ContainsFold reports whether segment occurs in text after both are folded,
+// the comparison selectors use to match headings
+func ContainsFold(text, segment string) bool {
+	return strings.Contains(Fold(text), Fold(segment))
+}
+
+// HasPrefixFold reports whether text starts with prefix after both are folded
+func HasPrefixFold(text, prefix string) bool {
+	return strings.HasPrefix(Fold(text), Fold(prefix))
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
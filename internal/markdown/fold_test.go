@@ -0,0 +1,32 @@
+package markdown
+
+import "testing"
+
+func TestContainsFold(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		segment  string
+		accents  bool
+		expected bool
+	}{
+		{"ascii", "Project Notes", "notes", false, true},
+		{"sharp s", "Straße", "STRASSE", false, true},
+		{"greek sigma", "ΟΔΟΣ", "οδος", false, true},
+		{"decomposed accent", "Café", "café", false, true},
+		{"accents significant", "Café", "cafe", false, false},
+		{"accents folded", "Café", "cafe", true, true},
+		{"accents folded in segment", "Resume", "résumé", true, true},
+		{"no match", "Über", "unter", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetFoldDiacritics(tt.accents)
+			defer SetFoldDiacritics(false)
+			if got := ContainsFold(tt.text, tt.segment); got != tt.expected {
+				t.Errorf("ContainsFold(%q, %q) = %v, want %v", tt.text, tt.segment, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	// inlineLinkPattern matches the target of an inline link or image,
+	// "[text](target)" or "[text](target "title")"
+	inlineLinkPattern = regexp.MustCompile(`\]\((<[^>\n]*>|[^)\s]+)`)
+
+	// referencePattern matches the target of a link reference definition,
+	// "[ref]: target"
+	referencePattern = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*(<[^>\n]*>|\S+)`)
+)
+
+// RewriteLinks calls rewrite with the target of every inline link, image
+// and link reference definition outside code, and replaces the target when
+// rewrite reports a change. Targets written in angle brackets are passed
+// without them. It returns the new content and the number of links changed.
+func RewriteLinks(content []byte, rewrite func(target string) (string, bool)) ([]byte, int) {
+	var out bytes.Buffer
+	changed := 0
+
+	eachLine(content, func(line []byte, inCode bool) {
+		if inCode {
+			out.Write(line)
+			return
+		}
+
+		code := codeSpans(line)
+		var matches [][]int
+		if m := referencePattern.FindSubmatchIndex(line); m != nil {
+			matches = append(matches, m[2:4])
+		}
+		for _, m := range inlineLinkPattern.FindAllSubmatchIndex(line, -1) {
+			matches = append(matches, m[2:4])
+		}
+
+		last := 0
+		for _, m := range matches {
+			if inRanges(code, m[0]) || m[0] < last {
+				continue
+			}
+			target := string(line[m[0]:m[1]])
+			bracketed := strings.HasPrefix(target, "<")
+			if bracketed {
+				target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+			replacement, ok := rewrite(target)
+			if !ok || replacement == target {
+				continue
+			}
+			if bracketed {
+				replacement = "<" + replacement + ">"
+			}
+			out.Write(line[last:m[0]])
+			out.WriteString(replacement)
+			last = m[1]
+			changed++
+		}
+		out.Write(line[last:])
+	})
+	return out.Bytes(), changed
+}
+
+// RewriteText calls replace with every match of pattern in prose: outside
+// code, headings, frontmatter, link reference definitions, links, autolinks
+// and bare URLs. The match is replaced when replace reports a change. It
+// returns the new content and the number of matches replaced.
+func RewriteText(content []byte, pattern *regexp.Regexp, replace func(match string) (string, bool)) ([]byte, int) {
+	var out bytes.Buffer
+	changed := 0
+
+	if end := frontmatterEnd(content); end > 0 {
+		out.Write(content[:end])
+		content = content[end:]
+	}
+
+	eachLine(content, func(line []byte, inCode bool) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if inCode || bytes.HasPrefix(trimmed, []byte("#")) || referencePattern.Match(line) {
+			out.Write(line)
+			return
+		}
+
+		skip := codeSpans(line)
+		for _, m := range linkSpanPattern.FindAllIndex(line, -1) {
+			skip = append(skip, [2]int{m[0], m[1]})
+		}
+
+		last := 0
+		for _, m := range pattern.FindAllIndex(line, -1) {
+			if inRanges(skip, m[0]) || inRanges(skip, m[1]-1) {
+				continue
+			}
+			match := string(line[m[0]:m[1]])
+			replacement, ok := replace(match)
+			if !ok || replacement == match {
+				continue
+			}
+			out.Write(line[last:m[0]])
+			out.WriteString(replacement)
+			last = m[1]
+			changed++
+		}
+		out.Write(line[last:])
+	})
+	return out.Bytes(), changed
+}
+
+// linkSpanPattern matches text RewriteText leaves alone: inline links and
+// images, reference links, autolinks and bare URLs
+var linkSpanPattern = regexp.MustCompile(`!?\[[^\]\n]*\](?:\([^)\n]*\)|\[[^\]\n]*\])?|<[a-zA-Z][a-zA-Z0-9+.-]*:[^>\s]*>|[a-zA-Z][a-zA-Z0-9+.-]*://\S+`)
+
+// eachLine calls fn with every line of content, ending newline included,
+// and whether the line belongs to a fenced code block
+func eachLine(content []byte, fn func(line []byte, inCode bool)) {
+	var fence []byte
+	for start := 0; start < len(content); {
+		end := bytes.IndexByte(content[start:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += start + 1
+		}
+		line := content[start:end]
+		start = end
+
+		trimmed := bytes.TrimLeft(line, " ")
+		if fence != nil {
+			if bytes.HasPrefix(trimmed, fence) && len(bytes.TrimSpace(bytes.TrimLeft(trimmed, string(fence[:1])))) == 0 {
+				fence = nil
+			}
+			fn(line, true)
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != nil && len(line)-len(trimmed) < 4 {
+			fence = marker
+			fn(line, true)
+			continue
+		}
+		fn(line, false)
+	}
+}
+
+// frontmatterEnd returns the length of the YAML frontmatter content starts
+// with, closing line included, or 0
+func frontmatterEnd(content []byte) int {
+	if !bytes.HasPrefix(content, []byte("---\n")) && !bytes.HasPrefix(content, []byte("---\r\n")) {
+		return 0
+	}
+	offset := bytes.IndexByte(content, '\n') + 1
+	for offset < len(content) {
+		end := bytes.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += offset + 1
+		}
+		if line := bytes.TrimRight(content[offset:end], "\r\n"); string(line) == "---" || string(line) == "..." {
+			return end
+		}
+		offset = end
+	}
+	return 0
+}
+
+// fenceMarker returns the backticks or tildes opening a fenced code block,
+// or nil
+func fenceMarker(line []byte) []byte {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return nil
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	if n < 3 || (line[0] == '`' && bytes.IndexByte(line[n:], '`') >= 0) {
+		return nil
+	}
+	return line[:n]
+}
+
+// codeSpans returns the byte ranges of the inline code spans in line
+func codeSpans(line []byte) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		open := i
+		for i < len(line) && line[i] == '`' {
+			i++
+		}
+		run := i - open
+		closed := false
+		for j := i; j < len(line); {
+			if line[j] != '`' {
+				j++
+				continue
+			}
+			k := j
+			for k < len(line) && line[k] == '`' {
+				k++
+			}
+			if k-j == run {
+				spans = append(spans, [2]int{open, k})
+				i, closed = k, true
+				break
+			}
+			j = k
+		}
+		if !closed {
+			break
+		}
+	}
+	return spans
+}
+
+func inRanges(ranges [][2]int, offset int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRewriteLinks(t *testing.T) {
+	content := "See [notes](old.md#intro) and ![chart](old.md).\n" +
+		"Keep [site](https://example.com/old.md) and `[code](old.md)`.\n" +
+		"[ref]: old.md \"Old\"\n" +
+		"[spaced](<old.md>)\n" +
+		"```\n[fenced](old.md)\n```\n"
+	want := "See [notes](new.md#intro) and ![chart](new.md).\n" +
+		"Keep [site](https://example.com/old.md) and `[code](old.md)`.\n" +
+		"[ref]: new.md \"Old\"\n" +
+		"[spaced](<new.md>)\n" +
+		"```\n[fenced](old.md)\n```\n"
+
+	got, changed := RewriteLinks([]byte(content), func(target string) (string, bool) {
+		if strings.Contains(target, "://") {
+			return "", false
+		}
+		return strings.Replace(target, "old.md", "new.md", 1), true
+	})
+	if string(got) != want {
+		t.Errorf("RewriteLinks() =\n%s\nwant\n%s", got, want)
+	}
+	if changed != 4 {
+		t.Errorf("changed = %d, want 4", changed)
+	}
+}
+
+func TestRewriteText(t *testing.T) {
+	content := "---\nticket: ABC-1\n---\n" +
+		"# ABC-2 heading\n" +
+		"- [ ] Fix ABC-3 and OPS-4, not ABCD-5.\n" +
+		"Linked [ABC-6](https://x/ABC-6), <https://x/ABC-7>, https://x/browse/ABC-8 and `ABC-9`.\n" +
+		"[ref]: https://x/ABC-10\n" +
+		"```\nABC-11\n```\n"
+	want := "---\nticket: ABC-1\n---\n" +
+		"# ABC-2 heading\n" +
+		"- [ ] Fix <ABC-3> and <OPS-4>, not ABCD-5.\n" +
+		"Linked [ABC-6](https://x/ABC-6), <https://x/ABC-7>, https://x/browse/ABC-8 and `ABC-9`.\n" +
+		"[ref]: https://x/ABC-10\n" +
+		"```\nABC-11\n```\n"
+
+	pattern := regexp.MustCompile(`\b(?:ABC|OPS)-\d+\b`)
+	got, changed := RewriteText([]byte(content), pattern, func(match string) (string, bool) {
+		return "<" + match + ">", true
+	})
+	if string(got) != want {
+		t.Errorf("RewriteText() =\n%s\nwant\n%s", got, want)
+	}
+	if changed != 2 {
+		t.Errorf("changed = %d, want 2", changed)
+	}
+}
@@ -3,10 +3,14 @@ package markdown
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/johncoder/jot/internal/opstats"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/text"
@@ -19,27 +23,62 @@ type HeadingPath struct {
 	SkipLevels int      // Number of leading slashes (for unusual document structures)
 }
 
+// Errors a SelectorError wraps, for errors.Is
+var (
+	ErrInvalidSelector = errors.New("invalid selector")
+	ErrNoMatch         = errors.New("no headings match selector")
+	ErrAmbiguous       = errors.New("multiple headings match selector")
+)
+
+// SelectorError reports a selector that could not be parsed, or that
+// matched no heading or more than one
+type SelectorError struct {
+	Selector string   // The selector as written
+	File     string   // The file it names, when it parsed
+	Matches  []string // Each match and its line, when ambiguous
+	Err      error    // ErrInvalidSelector, ErrNoMatch or ErrAmbiguous
+
+	message string
+}
+
+func (e *SelectorError) Error() string { return e.message }
+
+func (e *SelectorError) Unwrap() error { return e.Err }
+
+// String returns the selector a HeadingPath was parsed from
+func (p *HeadingPath) String() string {
+	return p.File + "#" + strings.Repeat("/", p.SkipLevels) + strings.Join(p.Segments, "/")
+}
+
 // Subtree represents a complete markdown subtree (heading + all nested content)
 type Subtree struct {
 	Heading     string // Original heading text
 	Level       int    // Original heading level (1-6)
-	Content     []byte // Full subtree content (markdown)
-	StartOffset int    // Byte position in source
+	Content     []byte // Full subtree content (markdown), from MarkerStart
+	StartOffset int    // Byte position in source of the heading line
 	EndOffset   int    // Byte position in source
+	Markers     int    // Bytes of marker lines attached above the heading
+}
+
+// MarkerStart returns where the subtree begins in the source once the
+// markers attached above its heading are counted
+func (s *Subtree) MarkerStart() int {
+	return s.StartOffset - s.Markers
 }
 
 // ParsePath parses a path selector like "file.md#path/to/heading"
 func ParsePath(pathStr string) (*HeadingPath, error) {
 	parts := strings.SplitN(pathStr, "#", 2)
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("path must contain '#' separator (e.g., 'file.md#heading')")
+		return nil, &SelectorError{Selector: pathStr, Err: ErrInvalidSelector,
+			message: "path must contain '#' separator (e.g., 'file.md#heading')"}
 	}
 
 	file := strings.TrimSpace(parts[0])
 	pathPart := strings.TrimSpace(parts[1])
 
 	if file == "" {
-		return nil, fmt.Errorf("file name cannot be empty")
+		return nil, &SelectorError{Selector: pathStr, Err: ErrInvalidSelector, message: "file name cannot be empty"}
 	}
 
 	// Count leading slashes for skip levels
@@ -66,15 +105,75 @@ func ParsePath(pathStr string) (*HeadingPath, error) {
 	}, nil
 }
 
-// ParseDocument parses markdown content and returns the AST document
+// documentParser is shared by every parse; goldmark parsers are safe for
+// concurrent use once built
+var documentParser = goldmark.New().Parser()
+
+// lastParse remembers the most recently parsed document. Commands often
+// resolve a source and a destination in the same file, or check a selector
+// before acting on it, and parsing dominates their run time.
+var lastParse struct {
+	sync.Mutex
+	content []byte
+	doc     ast.Node
+}
+
+// ParseDocument parses markdown content and returns the AST document. Parsing
+// the same content as the previous call returns the previous document, so
+// callers must not modify it.
 func ParseDocument(content []byte) ast.Node {
-	md := goldmark.New()
-	reader := text.NewReader(content)
-	return md.Parser().Parse(reader)
+	lastParse.Lock()
+	defer lastParse.Unlock()
+
+	if lastParse.doc != nil && bytes.Equal(content, lastParse.content) {
+		return lastParse.doc
+	}
+
+	// Parse a private copy, so the document never sees later changes to content
+	source := append([]byte(nil), content...)
+	start := time.Now()
+	doc := documentParser.Parse(text.NewReader(source))
+	opstats.Parse(time.Since(start))
+	lastParse.content = source
+	lastParse.doc = doc
+	return doc
 }
 
 // FindSubtree finds a subtree matching the given path selector
 func FindSubtree(doc ast.Node, content []byte, path *HeadingPath) (*Subtree, error) {
+	matches := FindSubtreeMatches(doc, content, path)
+	return singleMatch(matches, path, func(offset int) int {
+		return CalculateLineNumber(content, offset)
+	})
+}
+
+// singleMatch returns the only match, or an error listing every match with
+// the line it starts on
+func singleMatch(matches []*Subtree, path *HeadingPath, lineOf func(offset int) int) (*Subtree, error) {
+	if len(matches) == 0 {
+		return nil, &SelectorError{Selector: path.String(), File: path.File, Err: ErrNoMatch,
+			message: fmt.Sprintf("no headings found matching path \"%s\" in %s",
+				strings.Join(path.Segments, "/"), path.File)}
+	}
+
+	if len(matches) > 1 {
+		var found, matchDetails []string
+		for _, match := range matches {
+			line := lineOf(match.StartOffset)
+			found = append(found, fmt.Sprintf("\"%s\" at line %d", match.Heading, line))
+			matchDetails = append(matchDetails, "  - "+found[len(found)-1])
+		}
+		return nil, &SelectorError{Selector: path.String(), File: path.File, Matches: found, Err: ErrAmbiguous,
+			message: fmt.Sprintf("multiple headings match \"%s\" in %s:\n%s\nUse a more specific path",
+				strings.Join(path.Segments, "/"), path.File, strings.Join(matchDetails, "\n"))}
+	}
+
+	return matches[0], nil
+}
+
+// FindSubtreeMatches returns every subtree matching the given path selector,
+// in document order
+func FindSubtreeMatches(doc ast.Node, content []byte, path *HeadingPath) []*Subtree {
 	var matches []*Subtree
 
 	// Walk the AST to find matching headings
@@ -93,22 +192,14 @@ func FindSubtree(doc ast.Node, content []byte, path *HeadingPath) (*Subtree, err
 		return ast.WalkContinue, nil
 	})
 
-	if len(matches) == 0 {
-		return nil, fmt.Errorf("no headings found matching path \"%s\" in %s",
-			strings.Join(path.Segments, "/"), path.File)
+	// Fall back to GitHub-style slugs when contains matching finds nothing,
+	// so anchors copied from rendered markdown work as selectors. Several
+	// contains matches stay ambiguous rather than quietly picking one.
+	if len(matches) == 0 && SlugSelectors() && slugPath(path) {
+		matches = FindSlugMatches(doc, content, path.Segments[0])
 	}
 
-	if len(matches) > 1 {
-		var matchDetails []string
-		for _, match := range matches {
-			line := CalculateLineNumber(content, match.StartOffset)
-			matchDetails = append(matchDetails, fmt.Sprintf("  - \"%s\" at line %d", match.Heading, line))
-		}
-		return nil, fmt.Errorf("multiple headings match \"%s\" in %s:\n%s\nUse a more specific path",
-			strings.Join(path.Segments, "/"), path.File, strings.Join(matchDetails, "\n"))
-	}
-
-	return matches[0], nil
+	return matches
 }
 
 // FindAllHeadings returns all headings in the document with their paths
@@ -174,7 +265,7 @@ func tryMatchPath(heading *ast.Heading, content []byte, path *HeadingPath, segme
 	}
 
 	segment := path.Segments[segmentIndex]
-	if !strings.Contains(strings.ToLower(headingText), strings.ToLower(segment)) {
+	if !ContainsFold(headingText, segment) {
 		return nil
 	}
 
@@ -211,6 +302,11 @@ func tryMatchPath(heading *ast.Heading, content []byte, path *HeadingPath, segme
 	return nil
 }
 
+// SubtreeFromHeading extracts the complete subtree rooted at a heading node
+func SubtreeFromHeading(heading *ast.Heading, content []byte) *Subtree {
+	return extractSubtreeFromHeading(heading, content)
+}
+
 // extractSubtreeFromHeading extracts a complete subtree starting from a heading
 func extractSubtreeFromHeading(heading *ast.Heading, content []byte) *Subtree {
 	headingText := ExtractHeadingText(heading, content)
@@ -227,7 +323,8 @@ func extractSubtreeFromHeading(heading *ast.Heading, content []byte) *Subtree {
 	endOffset := findSubtreeEnd(heading, content)
 
 	// Extract content and trim trailing spacing to avoid spacing ownership issues
-	subtreeContent := content[startOffset:endOffset]
+	markerOffset := AttachedMarkers(content, startOffset)
+	subtreeContent := content[markerOffset:endOffset]
 
 	// Trim trailing newlines/whitespace - spacing belongs to document structure, not content
 	trimmedContent := bytes.TrimRight(subtreeContent, " \t\n")
@@ -245,6 +342,7 @@ func extractSubtreeFromHeading(heading *ast.Heading, content []byte) *Subtree {
 		Content:     subtreeContent,
 		StartOffset: startOffset,
 		EndOffset:   endOffset,
+		Markers:     startOffset - markerOffset,
 	}
 }
 
@@ -289,7 +387,8 @@ func FindSubtreeEnd(heading *ast.Heading, content []byte) int {
 	return findSubtreeEnd(heading, content)
 }
 
-// findSubtreeEnd finds where this subtree ends (before next same-level heading)
+// findSubtreeEnd finds where this subtree ends: before the next same-level
+// heading and any markers attached to it
 func findSubtreeEnd(heading *ast.Heading, content []byte) int {
 	// Walk forward to find the next heading at same or higher level
 	current := heading.NextSibling()
@@ -304,13 +403,7 @@ func findSubtreeEnd(heading *ast.Heading, content []byte) int {
 				lineStart--
 			}
 
-			// If we found a newline, the line starts after it
-			// Otherwise, we're at the beginning of the content
-			if lineStart > 0 && content[lineStart-1] == '\n' {
-				return lineStart
-			}
-
-			return lineStart
+			return AttachedMarkers(content, lineStart)
 		}
 		current = current.NextSibling()
 	}
@@ -495,10 +588,7 @@ func PathMatches(actualPath []string, targetSegments []string, skipLevels int) b
 			return false
 		}
 
-		actual := strings.ToLower(actualPath[actualIndex])
-		target := strings.ToLower(segment)
-
-		if !strings.Contains(actual, target) {
+		if !ContainsFold(actualPath[actualIndex], segment) {
 			return false
 		}
 	}
@@ -533,7 +623,9 @@ func FindNearestHeadingsForLines(content []byte, targetLines []int) (LineHeading
 
 	// Parse the markdown document
 	md := goldmark.New()
+	start := time.Now()
 	doc := md.Parser().Parse(text.NewReader(content))
+	opstats.Parse(time.Since(start))
 
 	// Track current heading context as we walk through the document
 	var currentHeadingPath []string
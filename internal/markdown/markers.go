@@ -0,0 +1,85 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Markers are lines that automation leaves next to headings: HTML comments
+// such as "<!-- id: 42 -->" and transclusions such as ![[weekly]]. Their
+// attachment policy decides which subtree they move with:
+//
+//   - Markers on the lines right under a heading are part of its text, as
+//     any text there is.
+//   - A run of markers right above a heading, with no blank line between it
+//     and the heading and a blank line or the start of the file before it,
+//     belongs to that heading. It starts the subtree's Content, moves and is
+//     removed with it, and is not part of the subtree before.
+//   - A comment closing a span, such as "<!-- /private -->" or
+//     "<!-- jot:index:end -->", ends the text before it and never attaches
+//     to the heading after it.
+//   - An eval element stays with the code block it names. Markdown reads it
+//     and the lines after it, up to a blank line, as one HTML block, so it
+//     never sits right above a heading.
+//
+// Subtree.StartOffset stays at the heading line, so line numbers and
+// heading lookups are unchanged; Subtree.MarkerStart is where attached
+// markers begin.
+
+// closingMarker matches a comment that closes a span opened earlier
+var closingMarker = regexp.MustCompile(`^<!--\s*(?:/|[\w.:-]*:end\b)`)
+
+// transclusionMarker matches an embed of another note on a line of its own
+var transclusionMarker = regexp.MustCompile(`^!\[\[[^\]]+\]\]$`)
+
+// AttachedMarkers returns where the markers attached to the heading whose
+// line starts at lineStart begin, or lineStart when none are attached
+func AttachedMarkers(content []byte, lineStart int) int {
+	start := lineStart
+	for start > 0 {
+		lineEnd := start - 1 // The newline ending the line above
+		prev := bytes.LastIndexByte(content[:lineEnd], '\n') + 1
+		line := bytes.TrimSpace(content[prev:lineEnd])
+
+		if bytes.HasSuffix(line, []byte("-->")) && !bytes.HasPrefix(line, []byte("<!--")) {
+			// The end of a comment spanning several lines
+			open := bytes.LastIndex(content[:prev], []byte("<!--"))
+			if open < 0 {
+				break
+			}
+			openLine := bytes.LastIndexByte(content[:open], '\n') + 1
+			if len(bytes.TrimSpace(content[openLine:open])) > 0 || bytes.Contains(content[open:prev], []byte("-->")) {
+				break
+			}
+			line = bytes.TrimSpace(content[openLine:lineEnd])
+			prev = openLine
+		}
+		if !isMarker(line) {
+			break
+		}
+		start = prev
+	}
+	if start == lineStart {
+		return lineStart
+	}
+	// Only a run set apart from the text above belongs to the heading
+	if start > 0 {
+		above := bytes.LastIndexByte(content[:start-1], '\n') + 1
+		if len(bytes.TrimSpace(content[above:start-1])) > 0 {
+			return lineStart
+		}
+	}
+	return start
+}
+
+// isMarker reports whether a trimmed line is a marker that may attach to the
+// heading below it
+func isMarker(line []byte) bool {
+	switch {
+	case bytes.HasPrefix(line, []byte("<!--")) && bytes.HasSuffix(line, []byte("-->")):
+		return !closingMarker.Match(line)
+	case transclusionMarker.Match(line):
+		return true
+	}
+	return false
+}
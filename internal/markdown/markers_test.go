@@ -0,0 +1,74 @@
+package markdown
+
+import "testing"
+
+func TestAttachedMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string // Content from the returned offset
+	}{
+		{"comment", "intro\n\n<!-- id: 42 -->\n## B\n", "<!-- id: 42 -->\n## B\n"},
+		{"run of markers", "intro\n\n<!-- id: 42 -->\n![[weekly]]\n## B\n", "<!-- id: 42 -->\n![[weekly]]\n## B\n"},
+		{"multi-line comment", "intro\n\n<!--\nowner: sam\n-->\n## B\n", "<!--\nowner: sam\n-->\n## B\n"},
+		{"start of file", "<!-- id: 1 -->\n# A\n", "<!-- id: 1 -->\n# A\n"},
+		{"blank line before heading", "intro\n\n<!-- id: 42 -->\n\n## B\n", "## B\n"},
+		{"follows text", "intro\n<!-- id: 42 -->\n## B\n", "## B\n"},
+		{"under the heading above", "## A\n<!-- locked: true -->\n## B\n", "## B\n"},
+		{"closing span", "<!-- private -->\nsecret\n\n<!-- /private -->\n## B\n", "## B\n"},
+		{"closing index", "intro\n\n<!-- jot:index:end -->\n## B\n", "## B\n"},
+		{"plain text", "intro\n\nnot a marker\n## B\n", "## B\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte(tt.content)
+			heading := len(content) - len(lastLine(tt.content))
+			if got := string(content[AttachedMarkers(content, heading):]); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// lastLine returns the heading line every test content ends with
+func lastLine(content string) string {
+	trimmed := content[:len(content)-1]
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		if trimmed[i] == '\n' {
+			return content[i+1:]
+		}
+	}
+	return content
+}
+
+func TestSubtreeMarkers(t *testing.T) {
+	content := []byte("# Work\n\n<!-- id: a -->\n## A\n<eval name=\"a\" />\n```sh\necho a\n```\n\n<!-- id: b -->\n![[b]]\n## B\nb\n")
+	doc := ParseDocument(content)
+
+	a, err := FindSubtree(doc, content, &HeadingPath{File: "f.md", Segments: []string{"Work", "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a.Content) != "<!-- id: a -->\n## A\n<eval name=\"a\" />\n```sh\necho a\n```\n" {
+		t.Errorf("A content = %q; want its own marker and not B's", a.Content)
+	}
+	if got := string(content[a.StartOffset:]); got[:4] != "## A" {
+		t.Errorf("StartOffset should stay at the heading line, got %q", got)
+	}
+	if got := string(content[a.MarkerStart():a.EndOffset]); got != "<!-- id: a -->\n## A\n<eval name=\"a\" />\n```sh\necho a\n```\n\n" {
+		t.Errorf("A range = %q", got)
+	}
+
+	b, err := FindSubtree(doc, content, &HeadingPath{File: "f.md", Segments: []string{"Work", "B"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b.Content) != "<!-- id: b -->\n![[b]]\n## B\nb\n" {
+		t.Errorf("B content = %q", b.Content)
+	}
+
+	sections := Sections(content)
+	if own := string(sections[1].Own); own != "## A\n<eval name=\"a\" />\n```sh\necho a\n```\n\n" {
+		t.Errorf("A's own text = %q; want it to stop at B's markers", own)
+	}
+}
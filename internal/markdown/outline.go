@@ -0,0 +1,278 @@
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+)
+
+// maxOutlineLine bounds the memory used for a single line while scanning an
+// outline. Longer lines are read past, and only their start is looked at.
+const maxOutlineLine = 64 << 10
+
+// OutlineHeading is a heading found while scanning an outline
+type OutlineHeading struct {
+	Level  int
+	Offset int64 // Start of the heading's first line in the file
+	Line   int   // 1-based line number of that first line
+}
+
+// Outline holds the headings of a file without its content, for files too
+// large to load and parse whole. Selectors resolve against the outline with
+// the same rules as against a parsed document, and only the matching subtree
+// is read from the file.
+type Outline struct {
+	Headings []OutlineHeading
+	Size     int64
+
+	skeleton      []byte // Each heading's lines, followed by a blank line
+	starts        []int  // Offset of each heading in skeleton
+	lines         []int  // Line of each heading in skeleton
+	skeletonLines int
+}
+
+// ScanOutline reads r line by line, keeping only its headings. It finds the
+// same headings as a HeadingScanner, without holding the file in memory.
+func ScanOutline(r io.Reader) (*Outline, error) {
+	o := &Outline{}
+	reader := bufio.NewReaderSize(r, maxOutlineLine)
+
+	var (
+		offset     int64
+		lineNumber int
+		blocks     blockTracker
+		paragraph  []byte // Lines of the current paragraph, for setext headings
+		paraOffset int64
+		paraLine   int
+	)
+
+	for {
+		line, length, err := readOutlineLine(reader)
+		if length == 0 && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		lineNumber++
+		lineOffset := offset
+		offset += length
+
+		trimmed := bytes.TrimRight(line, "\r\n")
+		switch kind, level := blocks.next(trimmed); kind {
+		case lineParagraphStart:
+			paragraph = appendOutlineLine(nil, trimmed)
+			paraOffset, paraLine = lineOffset, lineNumber
+		case lineParagraphContinue:
+			if paragraph = appendOutlineLine(paragraph, trimmed); paragraph == nil {
+				blocks.paragraph = false
+			}
+		case lineATXHeading:
+			o.add(level, lineOffset, lineNumber, trimmed)
+		case lineSetextUnderline:
+			o.add(level, paraOffset, paraLine, appendOutlineLine(paragraph, trimmed))
+		}
+	}
+
+	o.Size = offset
+	return o, nil
+}
+
+// readOutlineLine returns the start of the next line and its full length,
+// reading past whatever does not fit in the buffer
+func readOutlineLine(reader *bufio.Reader) ([]byte, int64, error) {
+	line, err := reader.ReadSlice('\n')
+	length := int64(len(line))
+	if err != bufio.ErrBufferFull {
+		return line, length, err
+	}
+	line = append([]byte(nil), line...)
+	for err == bufio.ErrBufferFull {
+		var more []byte
+		more, err = reader.ReadSlice('\n')
+		length += int64(len(more))
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return line, length, err
+}
+
+func (o *Outline) add(level int, offset int64, line int, source []byte) {
+	if source == nil {
+		return
+	}
+	o.Headings = append(o.Headings, OutlineHeading{Level: level, Offset: offset, Line: line})
+	o.starts = append(o.starts, len(o.skeleton))
+	o.lines = append(o.lines, o.skeletonLines+1)
+	o.skeleton = append(o.skeleton, source...)
+	o.skeleton = append(o.skeleton, "\n\n"...)
+	o.skeletonLines += bytes.Count(source, []byte("\n")) + 2
+}
+
+// appendOutlineLine adds a paragraph line, giving up on paragraphs too long
+// to be a heading worth keeping
+func appendOutlineLine(paragraph, line []byte) []byte {
+	if len(paragraph)+len(line) > maxOutlineLine {
+		return nil
+	}
+	if paragraph != nil {
+		paragraph = append(paragraph, '\n')
+	}
+	return append(paragraph, line...)
+}
+
+func outlineIndent(line []byte) int {
+	indent := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4 - indent%4
+		default:
+			return indent
+		}
+	}
+	return indent
+}
+
+func atxLevel(rest []byte) int {
+	level := 0
+	for level < len(rest) && rest[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0
+	}
+	if level < len(rest) && rest[level] != ' ' && rest[level] != '\t' {
+		return 0
+	}
+	return level
+}
+
+func setextLevel(rest []byte) int {
+	underline := bytes.TrimRight(rest, " \t")
+	switch {
+	case len(bytes.Trim(underline, "=")) == 0:
+		return 1
+	case len(bytes.Trim(underline, "-")) == 0:
+		return 2
+	}
+	return 0
+}
+
+// openingFence returns the length of the code fence rest opens, or 0
+func openingFence(rest []byte) int {
+	if len(rest) < 3 || (rest[0] != '`' && rest[0] != '~') {
+		return 0
+	}
+	n := 0
+	for n < len(rest) && rest[n] == rest[0] {
+		n++
+	}
+	if n < 3 || (rest[0] == '`' && bytes.IndexByte(rest[n:], '`') >= 0) {
+		return 0
+	}
+	return n
+}
+
+func closesFence(rest []byte, char byte, length int) bool {
+	n := 0
+	for n < len(rest) && rest[n] == char {
+		n++
+	}
+	return n >= length && len(bytes.TrimSpace(rest[n:])) == 0
+}
+
+func startsContainer(rest []byte) bool {
+	if rest[0] == '>' {
+		return true
+	}
+	if (rest[0] == '-' || rest[0] == '*' || rest[0] == '+') && (len(rest) == 1 || rest[1] == ' ' || rest[1] == '\t') {
+		return true
+	}
+	digits := 0
+	for digits < len(rest) && digits < 9 && rest[digits] >= '0' && rest[digits] <= '9' {
+		digits++
+	}
+	return digits > 0 && digits < len(rest) && (rest[digits] == '.' || rest[digits] == ')') &&
+		(digits+1 == len(rest) || rest[digits+1] == ' ' || rest[digits+1] == '\t')
+}
+
+func isThematicBreak(rest []byte) bool {
+	c := rest[0]
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	count := 0
+	for _, b := range rest {
+		switch b {
+		case c:
+			count++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// Skeleton returns a small document holding only the outline's headings,
+// for parsing in place of the file. FileLine maps its lines to the file's.
+func (o *Outline) Skeleton() []byte {
+	return o.skeleton
+}
+
+// FileLine maps a line of the skeleton to the same line in the file
+func (o *Outline) FileLine(line int) int {
+	i := sort.SearchInts(o.lines, line+1) - 1
+	if i < 0 {
+		return line
+	}
+	return o.Headings[i].Line + line - o.lines[i]
+}
+
+// FindSubtree resolves path against the outline and reads the one matching
+// subtree from r, the file the outline was scanned from
+func (o *Outline) FindSubtree(r io.ReaderAt, path *HeadingPath) (*Subtree, error) {
+	matches := FindSubtreeMatches(ParseDocument(o.skeleton), o.skeleton, path)
+	for _, match := range matches {
+		match.StartOffset = int(o.fileOffset(match.StartOffset))
+		match.EndOffset = int(o.fileOffset(match.EndOffset))
+		match.Content = nil
+	}
+
+	subtree, err := singleMatch(matches, path, func(offset int) int {
+		i := sort.Search(len(o.Headings), func(i int) bool { return o.Headings[i].Offset >= int64(offset) })
+		if i < len(o.Headings) {
+			return o.Headings[i].Line
+		}
+		return 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, subtree.EndOffset-subtree.StartOffset)
+	if _, err := r.ReadAt(content, int64(subtree.StartOffset)); err != nil && err != io.EOF {
+		return nil, err
+	}
+	content = bytes.TrimRight(content, " \t\n")
+	if len(content) > 0 {
+		content = append(content, '\n')
+	}
+	subtree.Content = content
+	return subtree, nil
+}
+
+// fileOffset maps the start of a heading in the outline's document, or its
+// end, to the same place in the file
+func (o *Outline) fileOffset(offset int) int64 {
+	i := sort.SearchInts(o.starts, offset)
+	if i < len(o.starts) && o.starts[i] == offset {
+		return o.Headings[i].Offset
+	}
+	return o.Size
+}
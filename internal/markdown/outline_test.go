@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOutlineFindSubtree(t *testing.T) {
+	content := []byte(`# Work
+
+Intro paragraph.
+
+## Projects
+
+` + "```sh\n# not a heading\n```" + `
+
+### Frontend
+- item
+- # list heading is not outlined here
+
+Backend
+-------
+
+Notes with *emphasis*
+over two lines
+==============
+
+    # indented code
+
+## Meetings
+
+### Standup
+
+***
+
+## Tasks
+`)
+
+	outline, err := ScanOutline(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outline.Size != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", outline.Size, len(content))
+	}
+
+	selectors := []string{
+		"f.md#work/projects",
+		"f.md#frontend",
+		"f.md#backend",
+		"f.md#emphasis",
+		"f.md#meetings",
+		"f.md#/meetings/standup",
+		"f.md#tasks",
+	}
+	for _, selector := range selectors {
+		t.Run(selector, func(t *testing.T) {
+			path, err := ParsePath(selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := FindSubtree(ParseDocument(content), content, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := outline.FindSubtree(bytes.NewReader(content), path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Heading != want.Heading || got.Level != want.Level || got.StartOffset != want.StartOffset ||
+				got.EndOffset != want.EndOffset || !bytes.Equal(got.Content, want.Content) {
+				t.Errorf("outline subtree = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+
+	path, _ := ParsePath("f.md#o")
+	_, err = outline.FindSubtree(bytes.NewReader(content), path)
+	if err == nil || !strings.Contains(err.Error(), `"Work" at line 1`) || !strings.Contains(err.Error(), `"Frontend" at line 11`) {
+		t.Errorf("ambiguous selector error = %v", err)
+	}
+}
+
+func TestOutlineFileLine(t *testing.T) {
+	content := []byte("# One\n\ntext\n\nTwo\n===\n\nbody\n\n## Three\n")
+	outline, err := ScanOutline(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []int
+	skeleton := outline.Skeleton()
+	for _, heading := range FindAllHeadings(ParseDocument(skeleton), skeleton) {
+		lines = append(lines, outline.FileLine(CalculateLineNumber(skeleton, heading.Offset)))
+	}
+	want := []int{1, 5, 10}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("lines = %v, want %v", lines, want)
+		}
+	}
+}
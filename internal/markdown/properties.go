@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// propertyPattern matches a "name: value" line, written plainly, as a list
+// item or inside an HTML comment
+var propertyPattern = regexp.MustCompile(`(?m)^[ \t]*(?:<!--[ \t]*)?(?:[-*+][ \t]+)?([A-Za-z][\w-]*):[ \t]*(.*?)[ \t]*(?:-->)?[ \t]*$`)
+
+// tagsPattern matches org-style tags at the end of heading text, as in
+// "Salary review :private:hr:"
+var tagsPattern = regexp.MustCompile(`(?:^|\s):((?:[\w@-]+:)+)$`)
+
+// Section is a heading's subtree together with its own text: the heading
+// and the content before the next heading of any level
+type Section struct {
+	HeadingInfo
+	Subtree *Subtree
+	Own     []byte
+}
+
+// Sections returns a section for every heading of content, in document order
+func Sections(content []byte) []Section {
+	doc := ParseDocument(content)
+	infos := FindAllHeadings(doc, content)
+	var sections []Section
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := n.(*ast.Heading); ok && entering && len(sections) < len(infos) {
+			sections = append(sections, Section{
+				HeadingInfo: infos[len(sections)],
+				Subtree:     SubtreeFromHeading(heading, content),
+			})
+		}
+		return ast.WalkContinue, nil
+	})
+	for i := range sections {
+		end := len(content)
+		if i+1 < len(sections) {
+			end = sections[i+1].Subtree.MarkerStart()
+		}
+		sections[i].Own = content[sections[i].Subtree.StartOffset:end]
+	}
+	return sections
+}
+
+// SectionProperty returns the value of the first name: line in a section's
+// own text, the content between its heading and the next. Names are matched
+// without regard to case.
+func SectionProperty(section []byte, name string) (string, bool) {
+	for _, match := range propertyPattern.FindAllSubmatch(section, -1) {
+		if strings.EqualFold(string(match[1]), name) {
+			return string(match[2]), true
+		}
+	}
+	return "", false
+}
+
+// HeadingTags returns the org-style tags at the end of heading text
+func HeadingTags(text string) []string {
+	match := tagsPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(match[1], ":"), ":")
+}
+
+// StripHeadingTags returns heading text without its org-style tags
+func StripHeadingTags(text string) string {
+	text = strings.TrimSpace(text)
+	if loc := tagsPattern.FindStringIndex(text); loc != nil {
+		return strings.TrimSpace(text[:loc[0]])
+	}
+	return text
+}
+
+// Property is a "name: value" line
+type Property struct {
+	Name  string
+	Value string
+}
+
+// LeadingProperties returns the name: value lines that open body, the text
+// after a heading, and the length of body they take up, including blank
+// lines before them. It returns nothing when body opens with anything else.
+func LeadingProperties(body []byte) ([]Property, int) {
+	var properties []Property
+	end, pos := 0, 0
+	for pos < len(body) {
+		next := bytes.IndexByte(body[pos:], '\n')
+		if next < 0 {
+			next = len(body)
+		} else {
+			next += pos + 1
+		}
+		line := bytes.TrimRight(body[pos:next], "\r\n")
+		if len(bytes.TrimSpace(line)) == 0 && properties == nil {
+			pos = next
+			continue
+		}
+		match := propertyPattern.FindSubmatch(line)
+		// A URL is not a property
+		if match == nil || bytes.HasPrefix(match[2], []byte("//")) {
+			break
+		}
+		properties = append(properties, Property{Name: string(match[1]), Value: string(match[2])})
+		pos, end = next, next
+	}
+	return properties, end
+}
+
+// HasTag reports whether heading text carries tag, ignoring case
+func HasTag(text, tag string) bool {
+	for _, t := range HeadingTags(text) {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSectionProperty(t *testing.T) {
+	section := []byte("## Offsite\nNote: bring a laptop\n- Expires: 2025-07-01\n<!-- private: true -->\n")
+
+	tests := []struct {
+		name, want string
+		found      bool
+	}{
+		{"expires", "2025-07-01", true},
+		{"private", "true", true},
+		{"note", "bring a laptop", true},
+		{"owner", "", false},
+	}
+	for _, tt := range tests {
+		got, found := SectionProperty(section, tt.name)
+		if got != tt.want || found != tt.found {
+			t.Errorf("SectionProperty(%q) = %q, %v; want %q, %v", tt.name, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestHeadingTags(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Salary review :private:hr:", []string{"private", "hr"}},
+		{"Salary review :private:", []string{"private"}},
+		{"Ratio 1:2:", nil},
+		{"Plain heading", nil},
+	}
+	for _, tt := range tests {
+		if got := HeadingTags(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("HeadingTags(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	if !HasTag("Salary review :Private:", "private") {
+		t.Error("HasTag should ignore case")
+	}
+}
+
+func TestLeadingProperties(t *testing.T) {
+	body := []byte("\nstatus: active\n- owner: sam\n<!-- locked: true -->\n\nNote: not a property\n")
+	properties, n := LeadingProperties(body)
+	want := []Property{{"status", "active"}, {"owner", "sam"}, {"locked", "true"}}
+	if !reflect.DeepEqual(properties, want) {
+		t.Errorf("got %+v, want %+v", properties, want)
+	}
+	if rest := string(body[n:]); rest != "\nNote: not a property\n" {
+		t.Errorf("got rest %q", rest)
+	}
+
+	for _, body := range []string{"Intro text\nstatus: active\n", "https://example.com\n", ""} {
+		if properties, n := LeadingProperties([]byte(body)); properties != nil || n != 0 {
+			t.Errorf("LeadingProperties(%q) = %+v, %d; want none", body, properties, n)
+		}
+	}
+
+	if got := StripHeadingTags("Salary review :private:hr:"); got != "Salary review" {
+		t.Errorf("StripHeadingTags = %q", got)
+	}
+}
@@ -0,0 +1,199 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// lineKind is what a line means for finding headings
+type lineKind int
+
+const (
+	lineOther             lineKind = iota // Blank, fenced, indented or inside a container
+	lineParagraphStart                    // Starts a paragraph that may become a setext heading
+	lineParagraphContinue                 // Continues the current paragraph
+	lineATXHeading                        // An ATX heading on its own
+	lineSetextUnderline                   // Turns the current paragraph into a heading
+)
+
+// blockTracker follows just enough block structure, line by line, to tell
+// headings apart from lines that only look like them. Fenced code is
+// skipped, and headings nested in lists or block quotes are not found.
+type blockTracker struct {
+	fenceChar byte
+	fenceLen  int // Length of the opening fence while inside fenced code
+	paragraph bool
+	container bool // Inside a list item or block quote
+}
+
+// next classifies a line, given without its line ending, and returns the
+// heading level for ATX headings and setext underlines
+func (b *blockTracker) next(line []byte) (lineKind, int) {
+	indent := outlineIndent(line)
+	rest := bytes.TrimLeft(line, " \t")
+
+	switch {
+	case b.fenceLen > 0:
+		if indent <= 3 && closesFence(rest, b.fenceChar, b.fenceLen) {
+			b.fenceLen = 0
+		}
+		return lineOther, 0
+	case len(rest) == 0:
+		b.paragraph, b.container = false, false
+		return lineOther, 0
+	case indent > 3:
+		if b.paragraph {
+			return lineParagraphContinue, 0
+		}
+		return lineOther, 0
+	}
+
+	if n := openingFence(rest); n > 0 {
+		b.fenceChar, b.fenceLen, b.paragraph = rest[0], n, false
+		return lineOther, 0
+	}
+	if level := atxLevel(rest); level > 0 {
+		b.paragraph = false
+		return lineATXHeading, level
+	}
+	if b.paragraph {
+		if level := setextLevel(rest); level > 0 {
+			b.paragraph = false
+			return lineSetextUnderline, level
+		}
+		if !startsContainer(rest) && !isThematicBreak(rest) {
+			return lineParagraphContinue, 0
+		}
+		b.paragraph = false
+	}
+	if startsContainer(rest) {
+		b.container = true
+	}
+	if b.container || isThematicBreak(rest) {
+		return lineOther, 0
+	}
+	b.paragraph = true
+	return lineParagraphStart, 0
+}
+
+// ScannedHeading is a heading found by a HeadingScanner. Source points into
+// the scanned content and is only valid while the content is unchanged.
+type ScannedHeading struct {
+	Level  int
+	Offset int    // Start of the heading's first line
+	End    int    // End of its last line, including the line ending
+	Line   int    // 1-based line number of the first line
+	Source []byte // The heading's lines as written, without the final line ending
+}
+
+// HeadingScanner finds the top-level headings of a document line by line,
+// for callers that need only headings and not a parsed tree. It recognises
+// the same headings as ParseDocument outside of lists and block quotes, and
+// does not allocate while scanning.
+type HeadingScanner struct {
+	content []byte
+	pos     int
+	line    int
+	blocks  blockTracker
+
+	paraOffset int
+	paraLine   int
+	heading    ScannedHeading
+}
+
+// NewHeadingScanner returns a scanner over content
+func NewHeadingScanner(content []byte) *HeadingScanner {
+	return &HeadingScanner{content: content}
+}
+
+// Next advances to the next heading, reporting false at the end of content
+func (s *HeadingScanner) Next() bool {
+	for s.pos < len(s.content) {
+		start := s.pos
+		end := bytes.IndexByte(s.content[start:], '\n')
+		if end < 0 {
+			s.pos = len(s.content)
+		} else {
+			s.pos = start + end + 1
+		}
+		s.line++
+		line := bytes.TrimRight(s.content[start:s.pos], "\r\n")
+
+		switch kind, level := s.blocks.next(line); kind {
+		case lineParagraphStart:
+			s.paraOffset, s.paraLine = start, s.line
+		case lineATXHeading:
+			s.heading = ScannedHeading{Level: level, Offset: start, End: s.pos, Line: s.line, Source: line}
+			return true
+		case lineSetextUnderline:
+			source := bytes.TrimRight(s.content[s.paraOffset:s.pos], "\r\n")
+			s.heading = ScannedHeading{Level: level, Offset: s.paraOffset, End: s.pos, Line: s.paraLine, Source: source}
+			return true
+		}
+	}
+	return false
+}
+
+// Heading returns the heading found by the last call to Next
+func (s *HeadingScanner) Heading() ScannedHeading {
+	return s.heading
+}
+
+// ScanHeadings returns every top-level heading of content
+func ScanHeadings(content []byte) []ScannedHeading {
+	var headings []ScannedHeading
+	scanner := NewHeadingScanner(content)
+	for scanner.Next() {
+		headings = append(headings, scanner.Heading())
+	}
+	return headings
+}
+
+// inlineMarkup holds the bytes that may start inline markup, escapes or
+// entities in heading text
+const inlineMarkup = "\\`*_[]<>!&"
+
+// Text returns the heading's text as ExtractHeadingText would. Plain
+// single-line headings are read directly; headings with inline markup or
+// several lines are parsed on their own.
+func (h ScannedHeading) Text() string {
+	line := bytes.TrimLeft(h.Source, " \t")
+	if atxLevel(line) > 0 {
+		line = atxContent(line)
+	} else if underline := bytes.LastIndexByte(line, '\n'); underline >= 0 {
+		line = line[:underline]
+	}
+	if bytes.ContainsAny(line, inlineMarkup+"\n") {
+		return h.parsedText()
+	}
+	return strings.TrimSpace(string(line))
+}
+
+// parsedText parses the heading's source on its own to read its text
+func (h ScannedHeading) parsedText() string {
+	doc := documentParser.Parse(text.NewReader(h.Source))
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if heading, ok := node.(*ast.Heading); ok {
+			return ExtractHeadingText(heading, h.Source)
+		}
+	}
+	return ""
+}
+
+// atxContent strips the opening and optional closing sequence of an ATX
+// heading line, given without leading indentation
+func atxContent(line []byte) []byte {
+	text := bytes.TrimLeft(line, "#")
+	text = bytes.TrimSpace(text)
+	closing := bytes.TrimRight(text, "#")
+	if len(closing) == 0 {
+		return closing
+	}
+	if len(closing) < len(text) && (closing[len(closing)-1] == ' ' || closing[len(closing)-1] == '\t') {
+		return bytes.TrimRight(closing, " \t")
+	}
+	return text
+}
@@ -0,0 +1,78 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestScanHeadingsMatchesParser(t *testing.T) {
+	content := []byte("# Work\n\nIntro paragraph.\n\n## Projects ##\n\n" +
+		"```sh\n# not a heading\n```\n\n" +
+		"### Frontend *UI* and `code`\n- item\n- # list heading is skipped\n\n" +
+		"Backend\n-------\n\n" +
+		"Notes with *emphasis*\nover two lines\n==============\n\n" +
+		"    # indented code\n\n" +
+		"## [Linked](https://example.com) \\#1\r\n\n" +
+		"~~~\n## fenced\n~~~~\n\n" +
+		"### Trailing #hash\n\n" +
+		"#### #\n\n" +
+		"***\n\n## Tasks")
+
+	doc := documentParser.Parse(text.NewReader(content))
+	var want []string
+	var levels []int
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		if heading, ok := node.(*ast.Heading); ok {
+			want = append(want, ExtractHeadingText(heading, content))
+			levels = append(levels, heading.Level)
+		}
+	}
+
+	got := ScanHeadings(content)
+	if len(got) != len(want) {
+		for _, h := range got {
+			t.Logf("scanned %q", h.Source)
+		}
+		t.Fatalf("scanned %d headings, want %d", len(got), len(want))
+	}
+	for i, heading := range got {
+		if text := heading.Text(); text != want[i] {
+			t.Errorf("heading %d text = %q, want %q", i, text, want[i])
+		}
+		if heading.Level != levels[i] {
+			t.Errorf("heading %d level = %d, want %d", i, heading.Level, levels[i])
+		}
+		if line := CalculateLineNumber(content, heading.Offset); line != heading.Line {
+			t.Errorf("heading %d line = %d, want %d", i, heading.Line, line)
+		}
+	}
+}
+
+func TestScanHeadingsSetextRange(t *testing.T) {
+	content := []byte("Title\nmore\n===\nbody\n")
+	headings := ScanHeadings(content)
+	if len(headings) != 1 {
+		t.Fatalf("scanned %d headings, want 1", len(headings))
+	}
+	heading := headings[0]
+	if heading.Offset != 0 || heading.End != len("Title\nmore\n===\n") || heading.Line != 1 {
+		t.Errorf("heading = %+v", heading)
+	}
+	if text := heading.Text(); text != "Titlemore" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestHeadingScannerDoesNotAllocate(t *testing.T) {
+	content := syntheticDocument(64 << 10)
+	allocs := testing.AllocsPerRun(10, func() {
+		scanner := HeadingScanner{content: content}
+		for scanner.Next() {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("scanning allocated %.0f times, want 0", allocs)
+	}
+}
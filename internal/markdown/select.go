@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// IsGlobSegment reports whether a selector segment is a glob pattern, such
+// as "2024-06-*", that can match several headings
+func IsGlobSegment(segment string) bool {
+	return strings.ContainsAny(segment, "*?[")
+}
+
+// FindGlobMatches finds the subtrees whose heading matches the glob in the
+// last segment of the path. Earlier segments select the parent, whose direct
+// children are matched; without them, any heading may match. Matching
+// ignores case like other selectors. Subtrees nested inside another match
+// are part of it and not returned separately.
+func FindGlobMatches(doc ast.Node, content []byte, selector *HeadingPath) ([]*Subtree, error) {
+	if len(selector.Segments) == 0 {
+		return nil, fmt.Errorf("selector has no heading pattern")
+	}
+	pattern := Fold(selector.Segments[len(selector.Segments)-1])
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid heading pattern %q: %w", pattern, err)
+	}
+
+	var candidates []*Subtree
+	if len(selector.Segments) > 1 {
+		parentPath := &HeadingPath{
+			File:       selector.File,
+			Segments:   selector.Segments[:len(selector.Segments)-1],
+			SkipLevels: selector.SkipLevels,
+		}
+		parent, err := FindSubtree(doc, content, parentPath)
+		if err != nil {
+			return nil, err
+		}
+		candidates = ChildSubtrees(doc, content, parent)
+	} else {
+		candidates = headingSubtrees(doc, content, 0, len(content), selector.SkipLevels)
+	}
+
+	var matches []*Subtree
+	for _, subtree := range candidates {
+		if ok, _ := path.Match(pattern, Fold(subtree.Heading)); ok {
+			matches = append(matches, subtree)
+		}
+	}
+	return outermost(matches), nil
+}
+
+// ChildSubtrees returns the direct children of parent: the outermost
+// subtrees beneath its heading, in document order
+func ChildSubtrees(doc ast.Node, content []byte, parent *Subtree) []*Subtree {
+	return outermost(headingSubtrees(doc, content, parent.StartOffset+1, parent.EndOffset, parent.Level))
+}
+
+// TopLevelSubtrees returns the subtrees not nested in any other, in
+// document order
+func TopLevelSubtrees(doc ast.Node, content []byte) []*Subtree {
+	return outermost(headingSubtrees(doc, content, 0, len(content), 0))
+}
+
+// headingSubtrees returns the subtrees of headings deeper than minLevel
+// starting within [start, end), in document order
+func headingSubtrees(doc ast.Node, content []byte, start, end, minLevel int) []*Subtree {
+	var subtrees []*Subtree
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering || heading.Level <= minLevel {
+			return ast.WalkContinue, nil
+		}
+		subtree := extractSubtreeFromHeading(heading, content)
+		if subtree.StartOffset >= start && subtree.StartOffset < end {
+			subtrees = append(subtrees, subtree)
+		}
+		return ast.WalkContinue, nil
+	})
+	return subtrees
+}
+
+// outermost drops subtrees that lie inside an earlier one in the list
+func outermost(subtrees []*Subtree) []*Subtree {
+	var result []*Subtree
+	for _, subtree := range subtrees {
+		if len(result) > 0 && subtree.StartOffset < result[len(result)-1].EndOffset {
+			continue
+		}
+		result = append(result, subtree)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindGlobMatches(t *testing.T) {
+	content := []byte("# Inbox\n\n## 2024-06-01 Standup\n\n### 2024-06-01 notes\n\n## 2024-05-30 Misc\n\n## 2024-06-12 Review\n\n# Later\n\n## 2024-06-20 Trip\n")
+	doc := ParseDocument(content)
+
+	tests := []struct {
+		selector string
+		expected []string
+	}{
+		{"f.md#inbox/2024-06-*", []string{"2024-06-01 Standup", "2024-06-12 Review"}},
+		{"f.md#2024-06-*", []string{"2024-06-01 Standup", "2024-06-12 Review", "2024-06-20 Trip"}},
+		{"f.md#inbox/*review", []string{"2024-06-12 Review"}},
+		{"f.md#inbox/2024-0[5]-*", []string{"2024-05-30 Misc"}},
+		{"f.md#later/2024-05-*", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			path, err := ParsePath(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matches, err := FindGlobMatches(doc, content, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var headings []string
+			for _, m := range matches {
+				headings = append(headings, m.Heading)
+			}
+			if !reflect.DeepEqual(headings, tt.expected) {
+				t.Errorf("got %q, want %q", headings, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChildSubtrees(t *testing.T) {
+	content := []byte("# Inbox\n\n## One\n\n### Nested\n\n## Two\n\n# Later\n\n## Three\n")
+	doc := ParseDocument(content)
+
+	path, _ := ParsePath("f.md#inbox")
+	parent, err := FindSubtree(doc, content, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var headings []string
+	for _, child := range ChildSubtrees(doc, content, parent) {
+		headings = append(headings, child.Heading)
+	}
+	if expected := []string{"One", "Two"}; !reflect.DeepEqual(headings, expected) {
+		t.Errorf("got %q, want %q", headings, expected)
+	}
+}
+
+func TestTopLevelSubtrees(t *testing.T) {
+	content := []byte("## Intro\n\n# Inbox\n\n## One\n\n# Later\n")
+	doc := ParseDocument(content)
+
+	var headings []string
+	for _, subtree := range TopLevelSubtrees(doc, content) {
+		headings = append(headings, subtree.Heading)
+	}
+	if expected := []string{"Intro", "Inbox", "Later"}; !reflect.DeepEqual(headings, expected) {
+		t.Errorf("got %q, want %q", headings, expected)
+	}
+}
@@ -0,0 +1,107 @@
+package markdown
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// slugSelectorsOff stops selectors falling back to heading slugs when set
+var slugSelectorsOff atomic.Bool
+
+// SetSlugSelectors turns matching selectors against GitHub-style heading
+// slugs on or off. It is on by default.
+func SetSlugSelectors(on bool) {
+	slugSelectorsOff.Store(!on)
+}
+
+// SlugSelectors reports whether selectors may match heading slugs
+func SlugSelectors() bool {
+	return !slugSelectorsOff.Load()
+}
+
+// Slug returns the GitHub-style anchor for a heading: lowercased, with
+// spaces turned into hyphens and punctuation other than "-" and "_" removed
+func Slug(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// headingIDPattern matches an explicit ID at the end of a heading
+var headingIDPattern = regexp.MustCompile(`\s*\{#([\w-]+)\}\s*$`)
+
+// HeadingID splits an explicit ID, written "{#id}" at the end of a heading
+// as Pandoc and kramdown do, from the heading's text. id is empty when the
+// heading has none.
+func HeadingID(heading string) (text, id string) {
+	match := headingIDPattern.FindStringSubmatchIndex(heading)
+	if match == nil {
+		return heading, ""
+	}
+	return heading[:match[0]], heading[match[2]:match[3]]
+}
+
+// Slugger hands out unique slugs for the headings of one document, adding
+// "-1", "-2" and so on to repeats the way GitHub does
+type Slugger struct {
+	seen map[string]int
+}
+
+// Next returns the slug for the next heading in document order
+func (s *Slugger) Next(heading string) string {
+	if s.seen == nil {
+		s.seen = make(map[string]int)
+	}
+	slug := Slug(heading)
+	n, ok := s.seen[slug]
+	s.seen[slug] = n + 1
+	if !ok {
+		return slug
+	}
+	return fmt.Sprintf("%s-%d", slug, n)
+}
+
+// FindSlugMatches finds the headings whose slug, or explicit ID, is exactly
+// slug. Percent escapes, as found in links copied from a browser, are
+// decoded first. A slug shared by repeated headings matches all of them;
+// the "-1", "-2" suffixed slugs pick out a single repeat.
+func FindSlugMatches(doc ast.Node, content []byte, slug string) []*Subtree {
+	if decoded, err := url.PathUnescape(slug); err == nil {
+		slug = decoded
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	var matches []*Subtree
+	var slugger Slugger
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := n.(*ast.Heading); ok && entering {
+			text := ExtractHeadingText(heading, content)
+			unique := slugger.Next(text)
+			if _, id := HeadingID(text); unique == slug || Slug(text) == slug || strings.EqualFold(id, slug) {
+				matches = append(matches, extractSubtreeFromHeading(heading, content))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return matches
+}
+
+// slugPath reports whether a selector path could be a heading slug: a
+// single segment with no spaces and no skip levels
+func slugPath(path *HeadingPath) bool {
+	return len(path.Segments) == 1 && path.SkipLevels == 0 &&
+		path.Segments[0] != "" && !strings.ContainsAny(path.Segments[0], " \t")
+}
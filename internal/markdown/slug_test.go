@@ -0,0 +1,103 @@
+package markdown
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		heading  string
+		expected string
+	}{
+		{"Project Notes", "project-notes"},
+		{"Setup & Install", "setup--install"},
+		{"What's new in v1.2?", "whats-new-in-v12"},
+		{"snake_case and-kebab", "snake_case-and-kebab"},
+		{"Café Menü", "café-menü"},
+	}
+
+	for _, tt := range tests {
+		if got := Slug(tt.heading); got != tt.expected {
+			t.Errorf("Slug(%q) = %q, want %q", tt.heading, got, tt.expected)
+		}
+	}
+}
+
+func TestSlugSelectors(t *testing.T) {
+	content := []byte("# Project Notes\n\n## Notes\n\nfirst\n\n## Notes\n\nsecond\n\n## Setup & Install\n\n## Café Menu\n\n## Set Up\n\n## Set Up\n")
+	doc := ParseDocument(content)
+
+	tests := []struct {
+		selector string
+		heading  string // Empty when the selector should match nothing
+		matches  int    // Matches expected when more than one
+	}{
+		{"f.md#project-notes", "Project Notes", 0},
+		{"f.md#notes", "", 3},
+		{"f.md#notes-1", "Notes", 0},
+		{"f.md#setup--install", "Setup & Install", 0},
+		{"f.md#caf%C3%A9-menu", "Café Menu", 0},
+		{"f.md#set-up", "", 2},
+		{"f.md#set-up-1", "Set Up", 0},
+		{"f.md#install-setup", "", 0},
+		{"f.md#notes-2", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			path, err := ParsePath(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matches := FindSubtreeMatches(doc, content, path)
+			if tt.matches > 1 {
+				if len(matches) != tt.matches {
+					t.Errorf("got %d matches, want %d", len(matches), tt.matches)
+				}
+				return
+			}
+			if tt.heading == "" {
+				if len(matches) != 0 {
+					t.Errorf("expected no match, got %d", len(matches))
+				}
+				return
+			}
+			if len(matches) != 1 || matches[0].Heading != tt.heading {
+				t.Fatalf("got %d matches, want %q", len(matches), tt.heading)
+			}
+		})
+	}
+
+	// The second "Notes" is selected by its suffixed slug
+	path, _ := ParsePath("f.md#notes-1")
+	if match := FindSubtreeMatches(doc, content, path)[0]; CalculateLineNumber(content, match.StartOffset) != 7 {
+		t.Errorf("notes-1 resolved to line %d, want 7", CalculateLineNumber(content, match.StartOffset))
+	}
+
+	SetSlugSelectors(false)
+	defer SetSlugSelectors(true)
+	path, _ = ParsePath("f.md#project-notes")
+	if matches := FindSubtreeMatches(doc, content, path); len(matches) != 0 {
+		t.Errorf("slug matched with slug selectors off")
+	}
+}
+
+func TestHeadingID(t *testing.T) {
+	tests := []struct {
+		heading, text, id string
+	}{
+		{"Design review {#review}", "Design review", "review"},
+		{"Notes {#notes-2024}  ", "Notes", "notes-2024"},
+		{"Notes", "Notes", ""},
+		{"Sets {#a} and {b}", "Sets {#a} and {b}", ""},
+	}
+	for _, tt := range tests {
+		if text, id := HeadingID(tt.heading); text != tt.text || id != tt.id {
+			t.Errorf("HeadingID(%q) = %q, %q, want %q, %q", tt.heading, text, id, tt.text, tt.id)
+		}
+	}
+
+	content := []byte("# Project\n\n## Design review {#review}\n")
+	matches := FindSlugMatches(ParseDocument(content), content, "review")
+	if len(matches) != 1 || matches[0].Level != 2 {
+		t.Errorf("got %d matches for an explicit ID", len(matches))
+	}
+}
@@ -0,0 +1,128 @@
+package markdown
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// WordsPerMinute is the reading speed used to estimate reading time
+const WordsPerMinute = 200
+
+// ContentStats summarizes the prose and structure of markdown content
+type ContentStats struct {
+	Words              int     // Words of prose, excluding code
+	Sentences          int     // Sentences of prose
+	Syllables          int     // Estimated syllables across all words
+	ReadingTimeMinutes float64 // Estimated reading time at WordsPerMinute
+	ReadabilityScore   float64 // Flesch reading ease (higher is easier)
+	ReadabilityLevel   string  // Human-readable band for the readability score
+	Headings           int     // Number of headings
+	Links              int     // Inline, reference and autolinks
+	CodeBlocks         int     // Fenced and indented code blocks
+}
+
+// AnalyzeContent computes word, readability, link and code block statistics.
+// Code blocks and inline code are excluded from prose counts.
+func AnalyzeContent(content []byte) *ContentStats {
+	stats := &ContentStats{}
+	var prose strings.Builder
+
+	doc := ParseDocument(content)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			// Blocks end a sentence even without punctuation (headings, list items)
+			if n.Type() == ast.TypeBlock && n.Kind() != ast.KindList {
+				prose.WriteString(".\n")
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			stats.CodeBlocks++
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading:
+			stats.Headings++
+		case *ast.Link:
+			stats.Links++
+		case *ast.AutoLink:
+			stats.Links++
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			prose.Write(node.Segment.Value(content))
+			prose.WriteByte(' ')
+		}
+		return ast.WalkContinue, nil
+	})
+
+	countProse(prose.String(), stats)
+
+	stats.ReadingTimeMinutes = math.Round(float64(stats.Words)/WordsPerMinute*10) / 10
+	if stats.Words > 0 && stats.Sentences > 0 {
+		score := 206.835 - 1.015*float64(stats.Words)/float64(stats.Sentences) - 84.6*float64(stats.Syllables)/float64(stats.Words)
+		stats.ReadabilityScore = math.Round(score*10) / 10
+		stats.ReadabilityLevel = ReadabilityLevel(stats.ReadabilityScore)
+	}
+
+	return stats
+}
+
+// countProse counts words, sentences and syllables in plain text
+func countProse(text string, stats *ContentStats) {
+	inSentence := false
+	for _, field := range strings.Fields(text) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word != "" {
+			stats.Words++
+			stats.Syllables += countSyllables(word)
+			inSentence = true
+		}
+		if inSentence && strings.ContainsAny(field, ".!?") {
+			stats.Sentences++
+			inSentence = false
+		}
+	}
+	if inSentence {
+		stats.Sentences++
+	}
+}
+
+// countSyllables estimates syllables by counting vowel groups
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	// A trailing silent 'e' does not add a syllable ("note"), but "-le" does ("table")
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	return max(count, 1)
+}
+
+// ReadabilityLevel describes a Flesch reading ease score
+func ReadabilityLevel(score float64) string {
+	switch {
+	case score >= 80:
+		return "easy"
+	case score >= 60:
+		return "standard"
+	case score >= 30:
+		return "difficult"
+	default:
+		return "very difficult"
+	}
+}
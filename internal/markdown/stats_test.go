@@ -0,0 +1,57 @@
+package markdown
+
+import "testing"
+
+func TestAnalyzeContent(t *testing.T) {
+	content := []byte(`# Notes
+
+The cat sat on the mat. See [docs](https://example.com) and <https://example.org>.
+
+- First item
+- Second item
+
+` + "```go\nfunc ignored() { many words in code }\n```\n\nUse `inline code` sparingly.\n")
+
+	stats := AnalyzeContent(content)
+
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"words", stats.Words, 16},
+		{"headings", stats.Headings, 1},
+		{"links", stats.Links, 2},
+		{"code blocks", stats.CodeBlocks, 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if stats.Sentences < 5 {
+		t.Errorf("sentences = %d, want at least 5", stats.Sentences)
+	}
+	if stats.ReadabilityScore <= 0 || stats.ReadabilityLevel == "" {
+		t.Errorf("expected a readability score, got %v (%q)", stats.ReadabilityScore, stats.ReadabilityLevel)
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"cat", 1},
+		{"note", 1},
+		{"table", 2},
+		{"readability", 5},
+		{"rhythm", 1},
+	}
+	for _, tt := range tests {
+		if got := countSyllables(tt.word); got != tt.want {
+			t.Errorf("countSyllables(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package merge parses git conflict markers in markdown files so conflicts
+// can be resolved one subtree at a time.
+package merge
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Resolution is the choice made for a conflict
+type Resolution int
+
+const (
+	Unresolved Resolution = iota
+	Ours
+	Theirs
+	Both
+)
+
+// String returns the name used for a resolution in output
+func (r Resolution) String() string {
+	switch r {
+	case Ours:
+		return "ours"
+	case Theirs:
+		return "theirs"
+	case Both:
+		return "both"
+	default:
+		return "unresolved"
+	}
+}
+
+// ParseResolution parses "ours", "theirs" or "both"
+func ParseResolution(s string) (Resolution, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "ours", "o":
+		return Ours, nil
+	case "theirs", "t":
+		return Theirs, nil
+	case "both", "b":
+		return Both, nil
+	}
+	return Unresolved, fmt.Errorf("unknown resolution '%s' (expected ours, theirs or both)", s)
+}
+
+// Conflict is one conflicted region of a file
+type Conflict struct {
+	Ours        string   // Lines from our side, including newlines
+	Base        string   // Lines from the common ancestor (diff3 style only)
+	Theirs      string   // Lines from their side, including newlines
+	OursLabel   string   // Text after the <<<<<<< marker
+	TheirsLabel string   // Text after the >>>>>>> marker
+	Line        int      // 1-based line of the <<<<<<< marker
+	HeadingPath []string // Headings enclosing the conflict
+	Resolution  Resolution
+
+	raw string // Original text including markers
+}
+
+// Resolved returns the text that replaces the conflict
+func (c *Conflict) Resolved() string {
+	switch c.Resolution {
+	case Ours:
+		return c.Ours
+	case Theirs:
+		return c.Theirs
+	case Both:
+		return c.Ours + c.Theirs
+	default:
+		return c.raw
+	}
+}
+
+// Group is a run of conflicts that share the same enclosing subtree
+type Group struct {
+	HeadingPath []string
+	Conflicts   []*Conflict
+}
+
+// Document is a file split into plain text and conflicts
+type Document struct {
+	segments []segment
+}
+
+type segment struct {
+	text     string
+	conflict *Conflict
+}
+
+var headingLine = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// HasConflicts reports whether content contains a conflict start marker
+func HasConflicts(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if isMarker(line, "<<<<<<<") {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse splits content into text and conflicts. Both the default and diff3
+// conflict styles are supported.
+func Parse(content string) (*Document, error) {
+	doc := &Document{}
+	headings := &headingTracker{}
+
+	const (
+		inText = iota
+		inOurs
+		inBase
+		inTheirs
+	)
+	state := inText
+
+	var text strings.Builder
+	var current *Conflict
+	var raw strings.Builder
+
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		bare := strings.TrimRight(line, "\r\n")
+
+		switch state {
+		case inText:
+			if isMarker(bare, "<<<<<<<") {
+				if text.Len() > 0 {
+					doc.segments = append(doc.segments, segment{text: text.String()})
+					text.Reset()
+				}
+				current = &Conflict{
+					OursLabel:   markerLabel(bare),
+					Line:        i + 1,
+					HeadingPath: headings.path(),
+				}
+				raw.Reset()
+				raw.WriteString(line)
+				state = inOurs
+				continue
+			}
+			headings.feed(bare)
+			text.WriteString(line)
+			continue
+		}
+
+		raw.WriteString(line)
+		switch {
+		case state == inOurs && isMarker(bare, "|||||||"):
+			state = inBase
+		case (state == inOurs || state == inBase) && isMarker(bare, "======="):
+			state = inTheirs
+		case state == inTheirs && isMarker(bare, ">>>>>>>"):
+			current.TheirsLabel = markerLabel(bare)
+			current.raw = raw.String()
+			doc.segments = append(doc.segments, segment{conflict: current})
+			// Later headings are tracked through our side of the conflict
+			for _, l := range strings.Split(current.Ours, "\n") {
+				headings.feed(l)
+			}
+			current = nil
+			state = inText
+		case state == inOurs:
+			current.Ours += line
+		case state == inBase:
+			current.Base += line
+		case state == inTheirs:
+			current.Theirs += line
+		}
+	}
+
+	if state != inText {
+		return nil, fmt.Errorf("unterminated conflict starting at line %d", current.Line)
+	}
+	if text.Len() > 0 {
+		doc.segments = append(doc.segments, segment{text: text.String()})
+	}
+	return doc, nil
+}
+
+// Conflicts returns the conflicts in document order
+func (d *Document) Conflicts() []*Conflict {
+	var conflicts []*Conflict
+	for _, seg := range d.segments {
+		if seg.conflict != nil {
+			conflicts = append(conflicts, seg.conflict)
+		}
+	}
+	return conflicts
+}
+
+// Groups returns conflicts grouped by enclosing subtree, in document order.
+// Only consecutive conflicts under the same headings share a group.
+func (d *Document) Groups() []Group {
+	var groups []Group
+	for _, c := range d.Conflicts() {
+		if n := len(groups); n > 0 && samePath(groups[n-1].HeadingPath, c.HeadingPath) {
+			groups[n-1].Conflicts = append(groups[n-1].Conflicts, c)
+			continue
+		}
+		groups = append(groups, Group{HeadingPath: c.HeadingPath, Conflicts: []*Conflict{c}})
+	}
+	return groups
+}
+
+// Render returns the document with resolved conflicts replaced. Unresolved
+// conflicts keep their original markers.
+func (d *Document) Render() string {
+	var b strings.Builder
+	for _, seg := range d.segments {
+		if seg.conflict != nil {
+			b.WriteString(seg.conflict.Resolved())
+		} else {
+			b.WriteString(seg.text)
+		}
+	}
+	return b.String()
+}
+
+func isMarker(line, marker string) bool {
+	if !strings.HasPrefix(line, marker) {
+		return false
+	}
+	rest := line[len(marker):]
+	return rest == "" || rest[0] == ' '
+}
+
+func markerLabel(line string) string {
+	return strings.TrimSpace(line[7:])
+}
+
+func samePath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// headingTracker follows the ATX heading hierarchy line by line, ignoring
+// lines inside fenced code blocks
+type headingTracker struct {
+	stack   []string
+	levels  []int
+	inFence bool
+}
+
+func (t *headingTracker) feed(line string) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		t.inFence = !t.inFence
+		return
+	}
+	if t.inFence {
+		return
+	}
+
+	m := headingLine.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	level := len(m[1])
+	for len(t.levels) > 0 && t.levels[len(t.levels)-1] >= level {
+		t.levels = t.levels[:len(t.levels)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+	t.levels = append(t.levels, level)
+	t.stack = append(t.stack, m[2])
+}
+
+func (t *headingTracker) path() []string {
+	return append([]string(nil), t.stack...)
+}
@@ -0,0 +1,114 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+const conflicted = `# Work
+
+## Projects
+
+### API
+<<<<<<< HEAD
+- ours line
+=======
+- theirs line
+>>>>>>> origin/main
+
+## Meetings
+<<<<<<< HEAD
+standup at 9
+||||||| base
+standup at 10
+=======
+standup at 9:30
+>>>>>>> origin/main
+done
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(conflicted)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	conflicts := doc.Conflicts()
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %d", len(conflicts))
+	}
+
+	first := conflicts[0]
+	if first.Ours != "- ours line\n" || first.Theirs != "- theirs line\n" {
+		t.Errorf("unexpected sides: %q / %q", first.Ours, first.Theirs)
+	}
+	if first.OursLabel != "HEAD" || first.TheirsLabel != "origin/main" {
+		t.Errorf("unexpected labels: %q / %q", first.OursLabel, first.TheirsLabel)
+	}
+	if got := strings.Join(first.HeadingPath, "/"); got != "Work/Projects/API" {
+		t.Errorf("first heading path = %q", got)
+	}
+	if first.Line != 6 {
+		t.Errorf("first line = %d, want 6", first.Line)
+	}
+
+	second := conflicts[1]
+	if second.Base != "standup at 10\n" {
+		t.Errorf("base = %q", second.Base)
+	}
+	if got := strings.Join(second.HeadingPath, "/"); got != "Work/Meetings" {
+		t.Errorf("second heading path = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolution Resolution
+		want       string
+	}{
+		{"ours", Ours, "a\nmine\nb\n"},
+		{"theirs", Theirs, "a\nyours\nb\n"},
+		{"both", Both, "a\nmine\nyours\nb\n"},
+		{"unresolved", Unresolved, "a\n<<<<<<< HEAD\nmine\n=======\nyours\n>>>>>>> x\nb\n"},
+	}
+
+	input := "a\n<<<<<<< HEAD\nmine\n=======\nyours\n>>>>>>> x\nb\n"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			doc.Conflicts()[0].Resolution = tt.resolution
+			if got := doc.Render(); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	input := "## A\n<<<<<<< x\n1\n=======\n2\n>>>>>>> y\ntext\n<<<<<<< x\n3\n=======\n4\n>>>>>>> y\n## B\n<<<<<<< x\n5\n=======\n6\n>>>>>>> y\n"
+	doc, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	groups := doc.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if len(groups[0].Conflicts) != 2 || len(groups[1].Conflicts) != 1 {
+		t.Errorf("unexpected group sizes: %d, %d", len(groups[0].Conflicts), len(groups[1].Conflicts))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("<<<<<<< HEAD\nmine\n=======\n"); err == nil {
+		t.Error("expected error for unterminated conflict")
+	}
+	if HasConflicts("no markers here\n=======\n") {
+		t.Error("HasConflicts() = true for text without a start marker")
+	}
+}
@@ -0,0 +1,151 @@
+// Package metrics keeps daily snapshots of workspace counts in
+// .jot/metrics/, one JSON file per day, so growth can be charted over time.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// DateFormat names snapshot files and dates
+const DateFormat = "2006-01-02"
+
+// Snapshot holds the counts for one day
+type Snapshot struct {
+	Date       string `json:"date"`
+	InboxNotes int    `json:"inbox_notes"`
+	LibNotes   int    `json:"lib_notes"`
+	LibFiles   int    `json:"lib_files"`
+	TotalNotes int    `json:"total_notes"`
+	OpenTodos  int    `json:"open_todos"`
+	DoneTodos  int    `json:"done_todos"`
+}
+
+// Series are the snapshot fields that can be charted, in display order
+var Series = []string{"total_notes", "inbox_notes", "lib_notes", "lib_files", "open_todos", "done_todos"}
+
+// Value returns the named series value of a snapshot
+func (s Snapshot) Value(series string) int {
+	switch series {
+	case "inbox_notes":
+		return s.InboxNotes
+	case "lib_notes":
+		return s.LibNotes
+	case "lib_files":
+		return s.LibFiles
+	case "total_notes":
+		return s.TotalNotes
+	case "open_todos":
+		return s.OpenTodos
+	case "done_todos":
+		return s.DoneTodos
+	}
+	return 0
+}
+
+// Path returns the file holding the snapshot for date
+func Path(dir string, date time.Time) string {
+	return filepath.Join(dir, date.Format(DateFormat)+".json")
+}
+
+// Has reports whether a snapshot exists for date
+func Has(dir string, date time.Time) bool {
+	_, err := os.Stat(Path(dir, date))
+	return err == nil
+}
+
+// Save writes a snapshot, replacing any snapshot for the same day
+func Save(dir string, snapshot Snapshot) error {
+	date, err := time.Parse(DateFormat, snapshot.Date)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot date %q: %w", snapshot.Date, err)
+	}
+	if err := dryrun.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return dryrun.WriteFile(Path(dir, date), append(data, '\n'), 0644)
+}
+
+// Load returns every snapshot in dir, oldest first. Unreadable files are skipped.
+func Load(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if _, err := time.Parse(DateFormat, strings.TrimSuffix(name, ".json")); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			continue
+		}
+		snapshot.Date = strings.TrimSuffix(name, ".json")
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Date < snapshots[j].Date })
+	return snapshots, nil
+}
+
+// Since returns the snapshots on or after date
+func Since(snapshots []Snapshot, date time.Time) []Snapshot {
+	cutoff := date.Format(DateFormat)
+	for i, snapshot := range snapshots {
+		if snapshot.Date >= cutoff {
+			return snapshots[i:]
+		}
+	}
+	return nil
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of block characters scaled between the
+// smallest and largest value
+func Sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = min(low, v)
+		high = max(high, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		index := 0
+		if high > low {
+			index = (v - low) * (len(sparkBlocks) - 1) / (high - low)
+		}
+		b.WriteRune(sparkBlocks[index])
+	}
+	return b.String()
+}
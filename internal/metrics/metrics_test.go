@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"flat", []int{3, 3, 3}, "▁▁▁"},
+		{"rising", []int{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{"scaled", []int{10, 20, 15}, "▁█▄"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "metrics")
+
+	for _, snapshot := range []Snapshot{
+		{Date: "2025-07-03", TotalNotes: 12},
+		{Date: "2025-07-01", TotalNotes: 10},
+		{Date: "2025-07-03", TotalNotes: 13}, // Replaces the earlier snapshot for the day
+	} {
+		if err := Save(dir, snapshot); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("Load() returned %d snapshots, want 2", len(snapshots))
+	}
+	if snapshots[0].Date != "2025-07-01" || snapshots[1].TotalNotes != 13 {
+		t.Errorf("Load() = %+v", snapshots)
+	}
+
+	date, _ := time.Parse(DateFormat, "2025-07-03")
+	if !Has(dir, date) {
+		t.Error("Has() = false for a saved day")
+	}
+	if got := Since(snapshots, date); len(got) != 1 || got[0].Date != "2025-07-03" {
+		t.Errorf("Since() = %+v", got)
+	}
+
+	if err := Save(dir, Snapshot{Date: "July"}); err == nil {
+		t.Error("Save() accepted an invalid date")
+	}
+}
+
+func TestLoadMissingDir(t *testing.T) {
+	snapshots, err := Load(filepath.Join(t.TempDir(), "none"))
+	if err != nil || snapshots != nil {
+		t.Errorf("Load() = %v, %v; want nil, nil", snapshots, err)
+	}
+}
@@ -0,0 +1,273 @@
+// Package netutil is the HTTP client integrations share. Requests are
+// retried with backoff when a server is briefly unavailable or rate limits
+// them, each attempt is bounded by a timeout, a proxy may be configured, and
+// the whole network can be turned off with --offline.
+package netutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRetries is how many times a failed request is retried
+const DefaultRetries = 3
+
+// MaxWait is the longest a request waits for a rate limit to lift before
+// giving up
+const MaxWait = time.Minute
+
+// ErrOffline is returned for any request made while offline
+var ErrOffline = errors.New("offline")
+
+// Options configure every client
+type Options struct {
+	Offline bool          // Refuse all requests
+	Proxy   string        // Proxy URL; the environment's is used when empty
+	Timeout time.Duration // Per attempt, overriding each client's own when set
+	Retries int           // Retries after a failed attempt; negative for none
+}
+
+var (
+	mu      sync.Mutex
+	current = Options{Retries: DefaultRetries}
+)
+
+// backoff is the wait before the first retry, doubling for each one after
+var backoff = 500 * time.Millisecond
+
+// Configure sets the options of every client, including ones already made
+func Configure(opts Options) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = opts
+}
+
+func options() Options {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// Offline reports whether the network is turned off
+func Offline() bool {
+	return options().Offline
+}
+
+// CheckOnline fails with a clear message when the network is turned off, for
+// commands to call before any work that needs it. what names the service,
+// such as "github".
+func CheckOnline(what string) error {
+	if Offline() {
+		return fmt.Errorf("%w: %s needs the network, which --offline, JOT_OFFLINE or \"offline\" under \"network\" in .jot/config.json turned off", ErrOffline, what)
+	}
+	return nil
+}
+
+// NewClient returns a client whose attempts each take at most timeout,
+// unless the configured timeout overrides it
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: &transport{timeout: timeout}}
+}
+
+// transport retries requests over a shared base transport
+type transport struct {
+	timeout time.Duration
+}
+
+var (
+	baseOnce sync.Once
+	baseHTTP *http.Transport
+)
+
+// base returns the transport requests are sent with, proxying them as
+// configured
+func base() *http.Transport {
+	baseOnce.Do(func() {
+		baseHTTP = http.DefaultTransport.(*http.Transport).Clone()
+		baseHTTP.Proxy = func(req *http.Request) (*url.URL, error) {
+			if proxy := options().Proxy; proxy != "" {
+				return url.Parse(proxy)
+			}
+			return http.ProxyFromEnvironment(req)
+		}
+	})
+	return baseHTTP
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	opts := options()
+	if opts.Offline {
+		closeBody(req)
+		return nil, fmt.Errorf("%w: not contacting %s", ErrOffline, req.URL.Host)
+	}
+	timeout := t.timeout
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout
+	}
+	// A body can only be sent again when it can be read again
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	idempotent := isIdempotent(req.Method)
+
+	for attempt := 0; ; attempt++ {
+		last := attempt >= opts.Retries || !replayable
+		ctx, cancel := req.Context(), context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), timeout)
+		}
+		try := req.Clone(ctx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			try.Body = body
+		}
+
+		resp, err := base().RoundTrip(try)
+		if err != nil {
+			timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil
+			cancel()
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			if !last && (idempotent || isDialError(err)) {
+				if waitErr := sleep(req.Context(), delay(attempt)); waitErr != nil {
+					return nil, waitErr
+				}
+				continue
+			}
+			if timedOut {
+				return nil, fmt.Errorf("no response from %s within %s", req.URL.Host, timeout)
+			}
+			return nil, err
+		}
+
+		wait, retry := retryable(resp, idempotent, attempt)
+		if !retry || last {
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		drain(resp)
+		cancel()
+		if wait > MaxWait {
+			return nil, fmt.Errorf("%s is rate limiting requests until %s; try again then", req.URL.Host, time.Now().Add(wait).Format("15:04:05"))
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether resp is worth trying again for, and how long to
+// wait first. Rate limits and 503s were refused before any work was done,
+// so they are retried for every method; gateway errors only for methods
+// that are safe to repeat.
+func retryable(resp *http.Response, idempotent bool, attempt int) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		// GitHub's rate limit
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusGatewayTimeout:
+		if !idempotent {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if wait, ok := retryAfter(resp.Header, time.Now()); ok {
+		return wait, true
+	}
+	return delay(attempt), true
+}
+
+// retryAfter reads how long the server asked for the client to wait, from
+// Retry-After or a rate limit's reset time
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+	return 0, false
+}
+
+// delay is the backoff before retry attempt+1, doubling each time, with
+// jitter so clients that failed together do not retry together
+func delay(attempt int) time.Duration {
+	d := backoff << attempt
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// isIdempotent reports whether a request with method may safely be sent
+// twice
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether err happened before a connection was made, so
+// the server cannot have seen the request
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain reads and closes a response that will not be used, so its
+// connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// closeBody closes a request body that will not be sent, as a RoundTripper
+// must
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// cancelBody ends an attempt's timeout once its response has been read
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,105 @@
+package netutil
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetries(t *testing.T) {
+	backoff = time.Millisecond
+	defer func() { backoff = 500 * time.Millisecond }()
+	Configure(Options{Retries: DefaultRetries})
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch {
+		case r.URL.Path == "/flaky" && n < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/gateway":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(time.Second)
+
+	resp, err := client.Get(server.URL + "/flaky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("got %s after %d calls, want 200 after 3", resp.Status, calls.Load())
+	}
+
+	// A POST that may have been acted on is not sent twice
+	calls.Store(0)
+	resp, err = client.Post(server.URL+"/gateway", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+		t.Errorf("got %s after %d calls, want 502 after 1", resp.Status, calls.Load())
+	}
+
+	// A GET is retried until the retries run out
+	calls.Store(0)
+	resp, err = client.Get(server.URL + "/gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls.Load() != DefaultRetries+1 {
+		t.Errorf("got %d calls, want %d", calls.Load(), DefaultRetries+1)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{http.Header{"Retry-After": {"5"}}, 5 * time.Second, true},
+		{http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute, true},
+		{http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1717243320"}}, 2 * time.Minute, true},
+		{http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {"1717243320"}}, 0, false},
+		{http.Header{}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%v) = %s, %v; want %s, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	Configure(Options{Offline: true})
+	defer Configure(Options{Retries: DefaultRetries})
+
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(time.Second).Get(server.URL); !errors.Is(err, ErrOffline) {
+		t.Errorf("got %v, want ErrOffline", err)
+	}
+	if called {
+		t.Error("the server was contacted while offline")
+	}
+	if err := CheckOnline("github"); !errors.Is(err, ErrOffline) {
+		t.Errorf("CheckOnline: got %v, want ErrOffline", err)
+	}
+}
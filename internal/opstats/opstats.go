@@ -0,0 +1,85 @@
+// Package opstats counts the work a command does: bytes read and written,
+// the files it touched, and the time spent parsing markdown and running
+// hooks. The counts are reported in JSON metadata, so automation can watch
+// performance and hook overhead across large batches.
+package opstats
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats are the counts for one command
+type Stats struct {
+	BytesRead    int64   `json:"bytes_read"`
+	BytesWritten int64   `json:"bytes_written"`
+	FilesTouched int     `json:"files_touched"` // Distinct files read, written or removed
+	ParseTimeMs  float64 `json:"parse_time_ms"`
+	HookTimeMs   float64 `json:"hook_time_ms"`
+}
+
+var (
+	mu        sync.Mutex
+	bytesRead int64
+	written   int64
+	touched   = map[string]bool{}
+	parseTime time.Duration
+	hookTime  time.Duration
+)
+
+// Reset clears the counts, ready for a new command
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	bytesRead, written = 0, 0
+	touched = map[string]bool{}
+	parseTime, hookTime = 0, 0
+}
+
+// Read counts n bytes read from path
+func Read(path string, n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	bytesRead += int64(n)
+	touched[path] = true
+}
+
+// Write counts n bytes written to path; removing a file writes 0
+func Write(path string, n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	written += int64(n)
+	touched[path] = true
+}
+
+// Parse counts time spent parsing markdown
+func Parse(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	parseTime += d
+}
+
+// Hook counts time spent running hooks
+func Hook(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	hookTime += d
+}
+
+// Current returns the counts so far
+func Current() Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	return Stats{
+		BytesRead:    bytesRead,
+		BytesWritten: written,
+		FilesTouched: len(touched),
+		ParseTimeMs:  milliseconds(parseTime),
+		HookTimeMs:   milliseconds(hookTime),
+	}
+}
+
+// milliseconds converts d to milliseconds, to the microsecond
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,27 @@
+package opstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounts(t *testing.T) {
+	Reset()
+	Read("a.md", 100)
+	Read("a.md", 100)
+	Write("a.md", 40)
+	Write("b.md", 0)
+	Parse(1500 * time.Microsecond)
+	Hook(2 * time.Millisecond)
+	Hook(time.Millisecond)
+
+	want := Stats{BytesRead: 200, BytesWritten: 40, FilesTouched: 2, ParseTimeMs: 1.5, HookTimeMs: 3}
+	if got := Current(); got != want {
+		t.Errorf("Current() = %+v, want %+v", got, want)
+	}
+
+	Reset()
+	if got := Current(); got != (Stats{}) {
+		t.Errorf("after Reset, Current() = %+v", got)
+	}
+}
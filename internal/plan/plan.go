@@ -0,0 +1,234 @@
+// Package plan serializes the file changes a command would make so they can
+// be reviewed and applied later with "jot apply".
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/diff"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/journal"
+	"github.com/johncoder/jot/internal/storage"
+)
+
+// Version is the plan format version written by this build. Version 2
+// added the base content that diffs are checked against.
+const Version = 2
+
+// Plan is a reviewable set of file changes produced by a command
+type Plan struct {
+	Version     int       `json:"version"`
+	Command     string    `json:"command"`
+	CreatedAt   time.Time `json:"created_at"`
+	Directories []string  `json:"directories,omitempty"`
+	Files       []File    `json:"files"`
+}
+
+// File is the final content of one file. Paths are relative to the
+// workspace root unless the file lies outside it, and only files inside it
+// can be applied.
+type File struct {
+	Path       string `json:"path"`
+	Action     string `json:"action"`                // "create", "modify" or "remove"
+	BaseSHA256 string `json:"base_sha256,omitempty"` // Hash of the content the plan was made against
+	Base       string `json:"base,omitempty"`        // The content the plan was made against
+	Diff       string `json:"diff"`                  // Unified diff for review, from Base to Content
+	Content    string `json:"content"`
+}
+
+// Conflict describes a file that changed after the plan was made
+type Conflict struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Build creates a plan from the pending writes recorded in dry-run mode
+func Build(root, command string, pending []dryrun.PendingFile, directories []string) *Plan {
+	p := &Plan{
+		Version:   Version,
+		Command:   command,
+		CreatedAt: time.Now().UTC(),
+		Files:     []File{},
+	}
+
+	for _, dir := range directories {
+		p.Directories = append(p.Directories, relativeTo(root, dir))
+	}
+
+	for _, pf := range pending {
+		if pf.Existed && !pf.Removed && string(pf.Original) == string(pf.Content) {
+			continue
+		}
+		if pf.Removed && !pf.Existed {
+			continue
+		}
+
+		path := relativeTo(root, pf.Path)
+		file := File{Path: path, Action: "create", Content: string(pf.Content)}
+		if pf.Existed {
+			file.Action = "modify"
+			file.BaseSHA256 = hash(pf.Original)
+			file.Base = string(pf.Original)
+		}
+		if pf.Removed {
+			file.Action = "remove"
+			file.Content = ""
+		}
+		file.Diff = unifiedDiff(path, file.Base, file.Content)
+		p.Files = append(p.Files, file)
+	}
+	return p
+}
+
+// Load reads a plan file
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid plan file: %w", err)
+	}
+	if p.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %d (expected %d)", p.Version, Version)
+	}
+	return &p, nil
+}
+
+// Save writes the plan as indented JSON. Plans are always written, even in
+// dry-run mode, since writing one is the point of planning.
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Check compares each file with the content the plan was made against
+func (p *Plan) Check(root string) []Conflict {
+	var conflicts []Conflict
+	for _, file := range p.Files {
+		current, err := storage.ReadFile(resolve(root, file.Path))
+		exists := err == nil
+
+		switch {
+		case err != nil && !os.IsNotExist(err):
+			conflicts = append(conflicts, Conflict{Path: file.Path, Reason: err.Error()})
+		case file.Action == "create" && exists:
+			conflicts = append(conflicts, Conflict{Path: file.Path, Reason: "file was created after the plan was made"})
+		case file.Action != "create" && !exists:
+			conflicts = append(conflicts, Conflict{Path: file.Path, Reason: "file no longer exists"})
+		case file.Action != "create" && hash(current) != file.BaseSHA256:
+			conflicts = append(conflicts, Conflict{Path: file.Path, Reason: "file changed after the plan was made"})
+		}
+	}
+	return conflicts
+}
+
+// Validate checks that every path stays inside root and that each file's
+// diff is the one its base and content make, so the diff a person reviewed
+// is exactly what applying writes
+func (p *Plan) Validate() error {
+	for _, dir := range p.Directories {
+		if err := checkPath(dir); err != nil {
+			return err
+		}
+	}
+	for _, file := range p.Files {
+		if err := checkPath(file.Path); err != nil {
+			return err
+		}
+		switch file.Action {
+		case "create":
+			if file.Base != "" || file.BaseSHA256 != "" {
+				return fmt.Errorf("%s: a created file has no base content", file.Path)
+			}
+		case "modify", "remove":
+			if hash([]byte(file.Base)) != file.BaseSHA256 {
+				return fmt.Errorf("%s: the base content does not match base_sha256", file.Path)
+			}
+			if file.Action == "remove" && file.Content != "" {
+				return fmt.Errorf("%s: a removed file has no content", file.Path)
+			}
+		default:
+			return fmt.Errorf("%s: unknown action %q", file.Path, file.Action)
+		}
+		if unifiedDiff(file.Path, file.Base, file.Content) != file.Diff {
+			return fmt.Errorf("%s: the diff does not match the content the plan would write", file.Path)
+		}
+	}
+	return nil
+}
+
+// Apply validates the plan, creates the planned directories, and writes or
+// removes every file through the journal, so an interrupted apply can be
+// recovered and a failed one leaves nothing half written
+func (p *Plan) Apply(root string) error {
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid plan: %w", err)
+	}
+	for _, dir := range p.Directories {
+		if err := dryrun.MkdirAll(resolve(root, dir), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	writes := make([]journal.Write, 0, len(p.Files))
+	for _, file := range p.Files {
+		writes = append(writes, journal.Write{
+			Path:    resolve(root, file.Path),
+			Content: []byte(file.Content),
+			Remove:  file.Action == "remove",
+		})
+	}
+	return journal.Apply(writes, nil)
+}
+
+// checkPath refuses absolute paths and paths that climb out of the
+// workspace
+func checkPath(path string) error {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if path == "" || filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" {
+		return fmt.Errorf("%q is not a path inside the workspace", path)
+	}
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%q is outside the workspace", path)
+	}
+	return nil
+}
+
+func unifiedDiff(path, base, content string) string {
+	lines := diff.Lines(diff.SplitLines(base), diff.SplitLines(content))
+	return diff.Unified("a/"+path, "b/"+path, diff.Hunks(lines, 3))
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func relativeTo(root, path string) string {
+	if root == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func resolve(root, path string) string {
+	if filepath.IsAbs(path) || root == "" {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
@@ -0,0 +1,132 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+func TestBuildCheckApply(t *testing.T) {
+	root := t.TempDir()
+	existing := filepath.Join(root, "work.md")
+	if err := os.WriteFile(existing, []byte("# Work\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	removed := filepath.Join(root, "old.md")
+	if err := os.WriteFile(removed, []byte("# Old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pending := []dryrun.PendingFile{
+		{Path: existing, Existed: true, Original: []byte("# Work\n"), Content: []byte("# Work\n\n## Added\n")},
+		{Path: filepath.Join(root, "lib", "new.md"), Content: []byte("# New\n")},
+		{Path: filepath.Join(root, "same.md"), Existed: true, Original: []byte("x"), Content: []byte("x")},
+		{Path: removed, Existed: true, Original: []byte("# Old\n"), Removed: true},
+	}
+	p := Build(root, "jot refile a --to b", pending, []string{filepath.Join(root, "lib")})
+
+	if len(p.Files) != 3 {
+		t.Fatalf("expected 3 files (unchanged files skipped), got %d", len(p.Files))
+	}
+	if p.Files[0].Path != "work.md" || p.Files[0].Action != "modify" || p.Files[0].BaseSHA256 == "" {
+		t.Errorf("unexpected modify entry: %+v", p.Files[0])
+	}
+	if !strings.Contains(p.Files[0].Diff, "+## Added") {
+		t.Errorf("diff missing added heading:\n%s", p.Files[0].Diff)
+	}
+	if p.Files[1].Path != "lib/new.md" || p.Files[1].Action != "create" {
+		t.Errorf("unexpected create entry: %+v", p.Files[1])
+	}
+	if p.Files[2].Path != "old.md" || p.Files[2].Action != "remove" || p.Files[2].BaseSHA256 == "" {
+		t.Errorf("unexpected remove entry: %+v", p.Files[2])
+	}
+
+	if conflicts := p.Check(root); len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %+v", conflicts)
+	}
+
+	planPath := filepath.Join(root, "plan.json")
+	if err := p.Save(planPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(planPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Apply(root); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(existing)
+	if string(content) != "# Work\n\n## Added\n" {
+		t.Errorf("work.md = %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(root, "lib", "new.md")); err != nil {
+		t.Errorf("lib/new.md not created: %v", err)
+	}
+	if _, err := os.Stat(removed); !os.IsNotExist(err) {
+		t.Errorf("old.md not removed: %v", err)
+	}
+
+	// Once applied, the plan no longer matches the files it was made against
+	if conflicts := loaded.Check(root); len(conflicts) != 3 {
+		t.Errorf("expected 3 conflicts after applying, got %+v", conflicts)
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "files": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for unknown plan version")
+	}
+}
+
+func TestApplyRejectsUnsafePlans(t *testing.T) {
+	root := t.TempDir()
+	work := filepath.Join(root, "work.md")
+	if err := os.WriteFile(work, []byte("# Work\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	valid := func() *Plan {
+		return Build(root, "jot capture", []dryrun.PendingFile{
+			{Path: work, Existed: true, Original: []byte("# Work\n"), Content: []byte("# Work\n\n- note\n")},
+		}, nil)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(p *Plan)
+	}{
+		{"parent path", func(p *Plan) { p.Files[0].Path = "../escaped.md" }},
+		{"nested parent path", func(p *Plan) { p.Files[0].Path = "lib/../../escaped.md" }},
+		{"absolute path", func(p *Plan) { p.Files[0].Path = filepath.Join(root, "..", "escaped.md") }},
+		{"directory outside", func(p *Plan) { p.Directories = []string{"../out"} }},
+		{"content differs from diff", func(p *Plan) { p.Files[0].Content = "# Work\n\n- something else\n" }},
+		{"base differs from hash", func(p *Plan) { p.Files[0].Base = "# Other\n" }},
+		{"removal with content", func(p *Plan) { p.Files[0].Action = "remove" }},
+		{"created file with base", func(p *Plan) { p.Files[0].Action = "create" }},
+	}
+	for _, tt := range tests {
+		p := valid()
+		tt.tamper(p)
+		if err := p.Apply(root); err == nil {
+			t.Errorf("%s: applied", tt.name)
+		}
+	}
+	if content, _ := os.ReadFile(work); string(content) != "# Work\n" {
+		t.Errorf("work.md = %q, want it untouched", content)
+	}
+	if _, err := os.Stat(filepath.Join(root, "..", "escaped.md")); err == nil {
+		t.Error("a plan wrote outside the workspace")
+	}
+
+	if err := valid().Apply(root); err != nil {
+		t.Fatalf("valid plan: %v", err)
+	}
+}
@@ -0,0 +1,410 @@
+// Package proof runs markdown content through external spelling and grammar checkers
+package proof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/yuin/goldmark/ast"
+)
+
+// Built-in checker names
+const (
+	CheckerHunspell     = "hunspell"
+	CheckerLanguageTool = "languagetool"
+)
+
+// DefaultChecker is used when no checker is configured
+const DefaultChecker = CheckerHunspell
+
+// checkerTimeout bounds how long an external checker may run
+const checkerTimeout = 60 * time.Second
+
+// Finding is a single problem reported by a checker. Offset and Length are
+// byte positions in the text that was checked.
+type Finding struct {
+	Offset      int
+	Length      int
+	Text        string
+	Message     string
+	Rule        string
+	Suggestions []string
+}
+
+// Checker checks plain text and reports findings
+type Checker interface {
+	Name() string
+	Check(text []byte) ([]Finding, error)
+}
+
+// NewChecker returns the checker for a configured spec. The spec is either a
+// built-in checker name or a shell command that reads text on stdin and prints
+// findings as "LINE:COLUMN: MESSAGE" lines.
+func NewChecker(spec string) Checker {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "", CheckerHunspell:
+		return &hunspellChecker{}
+	case CheckerLanguageTool:
+		return &languageToolChecker{}
+	default:
+		return &commandChecker{command: spec}
+	}
+}
+
+// MaskCode replaces code blocks, inline code and HTML in markdown with spaces
+// so checkers ignore them. Line breaks and byte offsets are preserved.
+func MaskCode(content []byte) []byte {
+	masked := make([]byte, len(content))
+	copy(masked, content)
+
+	blank := func(start, stop int) {
+		for i := start; i < stop && i < len(masked); i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+	}
+
+	doc := markdown.ParseDocument(content)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock:
+			lines := node.Lines()
+			if lines.Len() == 0 {
+				return ast.WalkSkipChildren, nil
+			}
+			start := lines.At(0).Start
+			if _, fenced := node.(*ast.FencedCodeBlock); fenced {
+				// Include the opening fence and its info string
+				start = lineStart(content, start-1)
+			}
+			stop := lines.At(lines.Len() - 1).Stop
+			if _, fenced := node.(*ast.FencedCodeBlock); fenced {
+				stop = closingFenceEnd(content, stop)
+			}
+			blank(start, stop)
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+				if t, ok := child.(*ast.Text); ok {
+					// Mask the backticks around the span as well
+					blank(t.Segment.Start-1, t.Segment.Stop+1)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink, *ast.RawHTML:
+			if segments := rawSegments(node); segments != nil {
+				blank(segments[0], segments[1])
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return masked
+}
+
+// rawSegments returns the byte range covered by inline raw HTML
+func rawSegments(n ast.Node) []int {
+	if raw, ok := n.(*ast.RawHTML); ok && raw.Segments.Len() > 0 {
+		return []int{raw.Segments.At(0).Start, raw.Segments.At(raw.Segments.Len() - 1).Stop}
+	}
+	return nil
+}
+
+// closingFenceEnd returns the offset just past a closing fence line that
+// follows offset, or offset itself when there is none
+func closingFenceEnd(content []byte, offset int) int {
+	end := offset
+	for end < len(content) && content[end] != '\n' {
+		end++
+	}
+	line := strings.TrimSpace(string(content[offset:end]))
+	if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+		return end
+	}
+	return offset
+}
+
+// lineStart returns the offset of the beginning of the line containing offset
+func lineStart(content []byte, offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	for offset > 0 && content[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// runChecker executes a checker command with text on stdin and returns stdout
+func runChecker(name string, args []string, text []byte) ([]byte, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found in PATH", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkerTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = bytes.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// Some checkers exit non-zero when they report problems
+		if _, ok := err.(*exec.ExitError); !ok || stdout.Len() == 0 {
+			return nil, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return stdout.Bytes(), nil
+}
+
+// wordPattern matches words for locating misspellings in text
+var wordPattern = regexp.MustCompile(`[\p{L}][\p{L}'’]*`)
+
+// hunspellChecker reports misspelled words using "hunspell -a"
+type hunspellChecker struct{}
+
+func (c *hunspellChecker) Name() string { return CheckerHunspell }
+
+// Check runs hunspell in pipe mode one line at a time so findings map to lines
+func (c *hunspellChecker) Check(text []byte) ([]Finding, error) {
+	// Prefix each line with '^' so hunspell treats it as text, not a command
+	var input bytes.Buffer
+	lines := bytes.Split(text, []byte("\n"))
+	for _, line := range lines {
+		input.WriteByte('^')
+		input.Write(line)
+		input.WriteByte('\n')
+	}
+
+	output, err := runChecker("hunspell", []string{"-a"}, input.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHunspell(output, lines), nil
+}
+
+// parseHunspell converts "hunspell -a" output into findings. Each input line
+// produces zero or more result lines terminated by a blank line.
+func parseHunspell(output []byte, lines [][]byte) []Finding {
+	var findings []Finding
+
+	outputLines := strings.Split(string(output), "\n")
+	if len(outputLines) > 0 && strings.HasPrefix(outputLines[0], "@(#)") {
+		outputLines = outputLines[1:]
+	}
+
+	lineIndex := 0
+	lineOffset := 0
+	for _, result := range outputLines {
+		if lineIndex >= len(lines) {
+			break
+		}
+		if result == "" {
+			lineOffset += len(lines[lineIndex]) + 1
+			lineIndex++
+			continue
+		}
+
+		var word, rest string
+		switch result[0] {
+		case '&', '?':
+			// & word count offset: suggestion, suggestion
+			fields := strings.SplitN(result, ":", 2)
+			parts := strings.Fields(fields[0])
+			if len(parts) < 4 {
+				continue
+			}
+			word = parts[1]
+			column, _ := strconv.Atoi(parts[3])
+			if len(fields) == 2 {
+				rest = fields[1]
+			}
+			findings = append(findings, hunspellFinding(word, lineOffset+locateWord(lines[lineIndex], word, column), rest))
+		case '#':
+			// # word offset
+			parts := strings.Fields(result)
+			if len(parts) < 3 {
+				continue
+			}
+			word = parts[1]
+			column, _ := strconv.Atoi(parts[2])
+			findings = append(findings, hunspellFinding(word, lineOffset+locateWord(lines[lineIndex], word, column), ""))
+		}
+	}
+
+	return findings
+}
+
+// locateWord returns the byte offset of word within line near the column
+// hunspell reported. Hunspell versions disagree on whether the '^' prefix is
+// counted, so the word is searched for starting just before that column.
+func locateWord(line []byte, word string, column int) int {
+	from := min(max(column-2, 0), len(line))
+	if idx := bytes.Index(line[from:], []byte(word)); idx >= 0 {
+		return from + idx
+	}
+	if idx := bytes.Index(line, []byte(word)); idx >= 0 {
+		return idx
+	}
+	return min(max(column-1, 0), len(line))
+}
+
+// hunspellFinding builds a finding for a misspelled word
+func hunspellFinding(word string, offset int, suggestions string) Finding {
+	finding := Finding{
+		Offset:  offset,
+		Length:  len(word),
+		Text:    word,
+		Message: fmt.Sprintf("Possible spelling mistake: %q", word),
+		Rule:    "spelling",
+	}
+	for _, suggestion := range strings.Split(suggestions, ",") {
+		if suggestion = strings.TrimSpace(suggestion); suggestion != "" {
+			finding.Suggestions = append(finding.Suggestions, suggestion)
+		}
+	}
+	return finding
+}
+
+// languageToolChecker reports spelling and grammar problems using the
+// languagetool command line client's JSON output
+type languageToolChecker struct{}
+
+func (c *languageToolChecker) Name() string { return CheckerLanguageTool }
+
+// languageToolOutput is the subset of languagetool JSON output jot uses
+type languageToolOutput struct {
+	Matches []struct {
+		Message      string `json:"message"`
+		Offset       int    `json:"offset"`
+		Length       int    `json:"length"`
+		Replacements []struct {
+			Value string `json:"value"`
+		} `json:"replacements"`
+		Rule struct {
+			ID string `json:"id"`
+		} `json:"rule"`
+	} `json:"matches"`
+}
+
+// Check runs languagetool and converts its character offsets to byte offsets
+func (c *languageToolChecker) Check(text []byte) ([]Finding, error) {
+	output, err := runChecker("languagetool", []string{"--json", "-l", "auto", "-"}, text)
+	if err != nil {
+		return nil, err
+	}
+
+	// The JSON document follows any informational lines
+	if idx := bytes.IndexByte(output, '{'); idx > 0 {
+		output = output[idx:]
+	}
+
+	var result languageToolOutput
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse languagetool output: %w", err)
+	}
+
+	var findings []Finding
+	for _, match := range result.Matches {
+		start := runeOffsetToByte(text, match.Offset)
+		end := runeOffsetToByte(text, match.Offset+match.Length)
+		finding := Finding{
+			Offset:  start,
+			Length:  end - start,
+			Text:    string(text[start:end]),
+			Message: match.Message,
+			Rule:    match.Rule.ID,
+		}
+		for _, replacement := range match.Replacements {
+			finding.Suggestions = append(finding.Suggestions, replacement.Value)
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// runeOffsetToByte converts a character offset into a byte offset
+func runeOffsetToByte(text []byte, runes int) int {
+	offset := 0
+	for i := 0; i < runes && offset < len(text); i++ {
+		_, size := utf8.DecodeRune(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// commandChecker runs a user-supplied shell command
+type commandChecker struct {
+	command string
+}
+
+func (c *commandChecker) Name() string { return c.command }
+
+// findingPattern matches "LINE:COLUMN: MESSAGE" output lines
+var findingPattern = regexp.MustCompile(`^(\d+):(\d+):\s*(.*)$`)
+
+// Check runs the command through the shell and parses its findings
+func (c *commandChecker) Check(text []byte) ([]Finding, error) {
+	output, err := runChecker("sh", []string{"-c", c.command}, text)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommandOutput(output, text), nil
+}
+
+// parseCommandOutput converts "LINE:COLUMN: MESSAGE" lines into findings.
+// The word at the reported position is used as the finding text.
+func parseCommandOutput(output, text []byte) []Finding {
+	lineOffsets := []int{0}
+	for i, b := range text {
+		if b == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+
+	var findings []Finding
+	for _, line := range strings.Split(string(output), "\n") {
+		match := findingPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		lineNumber, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		if lineNumber < 1 || lineNumber > len(lineOffsets) {
+			continue
+		}
+
+		// Clamp the column to the end of the reported line
+		lineEnd := len(text)
+		if lineNumber < len(lineOffsets) {
+			lineEnd = lineOffsets[lineNumber] - 1
+		}
+		offset := min(lineOffsets[lineNumber-1]+max(column-1, 0), lineEnd)
+		finding := Finding{Offset: offset, Message: match[3]}
+		if loc := wordPattern.FindIndex(text[offset:]); loc != nil && loc[0] == 0 {
+			finding.Length = loc[1]
+			finding.Text = string(text[offset : offset+loc[1]])
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
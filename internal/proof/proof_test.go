@@ -0,0 +1,69 @@
+package proof
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskCode(t *testing.T) {
+	content := "# Title\n\nSome `inlne` text.\n\n```go\nfunc mian() {}\n```\n\nAfter.\n"
+	masked := string(MaskCode([]byte(content)))
+
+	if len(masked) != len(content) {
+		t.Fatalf("masked length = %d, want %d", len(masked), len(content))
+	}
+	if strings.Count(masked, "\n") != strings.Count(content, "\n") {
+		t.Errorf("masking changed line count")
+	}
+	for _, hidden := range []string{"inlne", "mian", "```"} {
+		if strings.Contains(masked, hidden) {
+			t.Errorf("masked content still contains %q:\n%s", hidden, masked)
+		}
+	}
+	for _, kept := range []string{"# Title", "Some", "text.", "After."} {
+		if !strings.Contains(masked, kept) {
+			t.Errorf("masked content lost %q:\n%s", kept, masked)
+		}
+	}
+}
+
+func TestParseHunspell(t *testing.T) {
+	lines := [][]byte{[]byte("Ths is fine"), []byte(""), []byte("a wrod and xyzzy")}
+	output := "@(#) International Ispell Version 3.2.06 (but really Hunspell 1.7.0)\n" +
+		"& Ths 2 1: This, Tho\n*\n*\n\n" +
+		"\n" +
+		"*\n& wrod 3 3: word, wood, rod\n*\n# xyzzy 12\n\n"
+
+	findings := parseHunspell([]byte(output), lines)
+	if len(findings) != 3 {
+		t.Fatalf("got %d findings, want 3: %+v", len(findings), findings)
+	}
+
+	tests := []struct {
+		text        string
+		offset      int
+		suggestions int
+	}{
+		{"Ths", 0, 2},
+		{"wrod", 15, 3},
+		{"xyzzy", 24, 0},
+	}
+	for i, tt := range tests {
+		if findings[i].Text != tt.text || findings[i].Offset != tt.offset || len(findings[i].Suggestions) != tt.suggestions {
+			t.Errorf("finding %d = %+v, want text %q offset %d with %d suggestions", i, findings[i], tt.text, tt.offset, tt.suggestions)
+		}
+	}
+}
+
+func TestParseCommandOutput(t *testing.T) {
+	text := []byte("first line\nsecond wrod here\n")
+	output := []byte("2:8: unknown word\nnoise\n9:1: out of range\n")
+
+	findings := parseCommandOutput(output, text)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	if findings[0].Offset != 18 || findings[0].Text != "wrod" || findings[0].Message != "unknown word" {
+		t.Errorf("unexpected finding %+v", findings[0])
+	}
+}
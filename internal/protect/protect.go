@@ -0,0 +1,152 @@
+// Package protect finds the parts of markdown files that are marked as
+// protected so commands can refuse to modify them.
+package protect
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/yuin/goldmark/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// LockMarker is the comment placed on the line after a heading to lock its subtree
+const LockMarker = "<!-- locked: true -->"
+
+var lockComment = regexp.MustCompile(`(?i)^\s*<!--\s*locked:\s*true\s*-->\s*$`)
+
+// Region is a protected byte range of a file
+type Region struct {
+	Heading string // Heading text, empty when the whole file is protected
+	Start   int
+	End     int
+	Reason  string // "config", "frontmatter" or "marker"
+}
+
+// WholeFile reports whether the region covers the entire file
+func (r Region) WholeFile() bool {
+	return r.Heading == ""
+}
+
+// Regions returns the protected regions of a file. The file is protected as a
+// whole when it is listed in configured or has "locked: true" in its
+// frontmatter. Subtrees are protected when a configured selector names them or
+// when the line after their heading is the lock marker.
+func Regions(file string, content []byte, configured []string) []Region {
+	file = filepath.ToSlash(filepath.Clean(file))
+
+	if lockedFrontmatter(content) {
+		return []Region{{Start: 0, End: len(content), Reason: "frontmatter"}}
+	}
+
+	var regions []Region
+	var doc ast.Node
+	for _, entry := range configured {
+		entryFile, selector, hasSelector := strings.Cut(entry, "#")
+		if filepath.ToSlash(filepath.Clean(entryFile)) != file {
+			continue
+		}
+		if !hasSelector {
+			return []Region{{Start: 0, End: len(content), Reason: "config"}}
+		}
+
+		path, err := markdown.ParsePath(file + "#" + selector)
+		if err != nil {
+			continue
+		}
+		if doc == nil {
+			doc = markdown.ParseDocument(content)
+		}
+		subtree, err := markdown.FindSubtree(doc, content, path)
+		if err != nil || subtree == nil {
+			continue
+		}
+		regions = append(regions, Region{
+			Heading: subtree.Heading,
+			Start:   subtree.StartOffset,
+			End:     subtree.EndOffset,
+			Reason:  "config",
+		})
+	}
+
+	if !strings.Contains(string(content), "locked") {
+		return regions
+	}
+	if doc == nil {
+		doc = markdown.ParseDocument(content)
+	}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		if lockComment.MatchString(lineAfterHeading(heading, content)) {
+			subtree := markdown.SubtreeFromHeading(heading, content)
+			regions = append(regions, Region{
+				Heading: subtree.Heading,
+				Start:   subtree.StartOffset,
+				End:     subtree.EndOffset,
+				Reason:  "marker",
+			})
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return regions
+}
+
+// Overlapping returns the first region that overlaps the range [start, end)
+func Overlapping(regions []Region, start, end int) *Region {
+	for i := range regions {
+		if start < regions[i].End && end > regions[i].Start {
+			return &regions[i]
+		}
+	}
+	return nil
+}
+
+// Containing returns the first region containing offset. The whole-file
+// region contains every offset, including the end of the file.
+func Containing(regions []Region, offset int) *Region {
+	for i := range regions {
+		if regions[i].WholeFile() || (offset >= regions[i].Start && offset < regions[i].End) {
+			return &regions[i]
+		}
+	}
+	return nil
+}
+
+// lockedFrontmatter reports whether content starts with YAML frontmatter
+// containing "locked: true"
+func lockedFrontmatter(content []byte) bool {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return false
+	}
+	front, _, found := strings.Cut(text[4:], "\n---")
+	if !found {
+		return false
+	}
+
+	var meta struct {
+		Locked bool `yaml:"locked"`
+	}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return false
+	}
+	return meta.Locked
+}
+
+// lineAfterHeading returns the line following a heading's line
+func lineAfterHeading(heading *ast.Heading, content []byte) string {
+	offset := markdown.GetNodeOffset(heading, content)
+	newline := strings.IndexByte(string(content[offset:]), '\n')
+	if newline < 0 {
+		return ""
+	}
+	rest := string(content[offset+newline+1:])
+	line, _, _ := strings.Cut(rest, "\n")
+	return line
+}
@@ -0,0 +1,75 @@
+package protect
+
+import "testing"
+
+const notes = `# Reference
+
+## Standards
+<!-- locked: true -->
+
+Always use UTC.
+
+## Scratch
+
+Anything goes.
+`
+
+func TestRegions(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		configured []string
+		want       []string // Reasons, with the heading when present
+	}{
+		{"marker", "ref.md", notes, nil, []string{"marker:Standards"}},
+		{"configured subtree", "ref.md", notes, []string{"ref.md#reference/scratch"}, []string{"config:Scratch", "marker:Standards"}},
+		{"configured file", "lib/ref.md", notes, []string{"./lib/ref.md"}, []string{"config"}},
+		{"other file", "ref.md", "# Title\n", []string{"other.md"}, nil},
+		{"frontmatter", "ref.md", "---\nlocked: true\n---\n# Title\n", nil, []string{"frontmatter"}},
+		{"frontmatter unlocked", "ref.md", "---\nlocked: false\n---\n# Title\n", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions := Regions(tt.file, []byte(tt.content), tt.configured)
+			var got []string
+			for _, r := range regions {
+				label := r.Reason
+				if r.Heading != "" {
+					label += ":" + r.Heading
+				}
+				got = append(got, label)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Regions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Regions()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOverlappingAndContaining(t *testing.T) {
+	regions := Regions("ref.md", []byte(notes), nil)
+	if len(regions) != 1 {
+		t.Fatalf("expected 1 region, got %d", len(regions))
+	}
+	r := regions[0]
+
+	if Overlapping(regions, 0, r.Start) != nil {
+		t.Error("range ending at region start should not overlap")
+	}
+	if Overlapping(regions, r.Start+1, r.Start+2) == nil {
+		t.Error("range inside region should overlap")
+	}
+	if Containing(regions, r.Start+3) == nil {
+		t.Error("offset inside region should be contained")
+	}
+	if Containing(regions, r.End) != nil {
+		t.Error("offset at region end should not be contained")
+	}
+}
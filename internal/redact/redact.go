@@ -0,0 +1,72 @@
+// Package redact removes private content from notes before they leave the
+// workspace
+package redact
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/johncoder/jot/internal/markdown"
+)
+
+// Tag marks a private subtree, as a heading tag (":private:") or a
+// "private: true" property in the subtree's own text
+const Tag = "private"
+
+// Placeholder stands in for a private inline span
+const Placeholder = "[redacted]"
+
+// spanPattern matches an inline span between private markers
+var spanPattern = regexp.MustCompile(`(?s)<!--[ \t]*private[ \t]*-->.*?<!--[ \t]*/private[ \t]*-->`)
+
+// Result is content with its private parts removed
+type Result struct {
+	Content  []byte
+	Subtrees int // Private subtrees left out
+	Spans    int // Private spans replaced with Placeholder
+}
+
+// Removed reports whether anything was redacted
+func (r Result) Removed() bool {
+	return r.Subtrees > 0 || r.Spans > 0
+}
+
+// Private reports whether a section is marked private
+func Private(section markdown.Section) bool {
+	if markdown.HasTag(section.Text, Tag) {
+		return true
+	}
+	value, ok := markdown.SectionProperty(section.Own, Tag)
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(value) {
+	case "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// Redact leaves out private subtrees, children included, and replaces
+// private inline spans with Placeholder
+func Redact(content []byte) Result {
+	var result Result
+	var out bytes.Buffer
+	kept := 0
+	for _, section := range markdown.Sections(content) {
+		if section.Subtree.StartOffset < kept || !Private(section) {
+			continue
+		}
+		out.Write(content[kept:section.Subtree.MarkerStart()])
+		kept = section.Subtree.EndOffset
+		result.Subtrees++
+	}
+	out.Write(content[kept:])
+
+	result.Content = spanPattern.ReplaceAllFunc(out.Bytes(), func([]byte) []byte {
+		result.Spans++
+		return []byte(Placeholder)
+	})
+	return result
+}
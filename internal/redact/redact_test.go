@@ -0,0 +1,44 @@
+package redact
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	content := []byte(`# Team
+
+## Standup
+Shipped the parser. <!-- private -->Ask Sam about the offer.<!-- /private -->
+
+## Salary review :private:hr:
+Numbers.
+
+### Follow up
+More numbers.
+
+## Credentials
+- private: yes
+
+Pointer to the vault.
+
+## Retro
+Went well.
+`)
+	want := `# Team
+
+## Standup
+Shipped the parser. [redacted]
+
+## Retro
+Went well.
+`
+	result := Redact(content)
+	if string(result.Content) != want {
+		t.Errorf("Redact() content =\n%s\nwant\n%s", result.Content, want)
+	}
+	if result.Subtrees != 2 || result.Spans != 1 {
+		t.Errorf("Redact() removed %d subtrees and %d spans, want 2 and 1", result.Subtrees, result.Spans)
+	}
+
+	if Redact([]byte("# Public\n\nprivate: false\n")).Removed() {
+		t.Error("private: false should not redact")
+	}
+}
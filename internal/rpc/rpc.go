@@ -0,0 +1,175 @@
+// Package rpc frames JSON messages for long-lived editor integrations. Each
+// message is preceded by a "Content-Length: N" header and a blank line, as in
+// the Language Server Protocol.
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxMessageSize bounds the body of a single message
+const MaxMessageSize = 64 << 20
+
+// Request is a single call from the client
+type Request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers a request with either a result or an error
+type Response struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Notification is sent by the server without a request, such as when files change
+type Notification struct {
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// Error describes a failed request
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error codes
+const (
+	CodeParseError     = "parse_error"
+	CodeInvalidParams  = "invalid_params"
+	CodeMethodNotFound = "method_not_found"
+	CodeNotFound       = "not_found"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeFailed         = "failed"
+)
+
+// Read reads the body of the next message. It returns io.EOF when the input
+// ends cleanly between messages.
+func Read(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	sawHeader := false
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && !sawHeader && line == "" {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("reading header: %w", io.ErrUnexpectedEOF)
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !sawHeader {
+				continue // Tolerate blank lines between messages
+			}
+			break
+		}
+		sawHeader = true
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	if length > MaxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds limit of %d", length, MaxMessageSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	return body, nil
+}
+
+// Write writes body as a single message
+func Write(w io.Writer, body []byte) error {
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// WriteResponse encodes and writes a response
+func WriteResponse(w io.Writer, response Response) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return Write(w, body)
+}
+
+// WriteNotification encodes and writes a notification
+func WriteNotification(w io.Writer, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return Write(w, body)
+}
+
+// Call sends a request and decodes the result of the matching response into
+// result. Notifications and responses to other requests are skipped.
+func Call(w io.Writer, r *bufio.Reader, id, method string, params, result interface{}) error {
+	rawID, err := json.Marshal(id)
+	if err != nil {
+		return err
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(Request{ID: rawID, Method: method, Params: rawParams})
+	if err != nil {
+		return err
+	}
+	if err := Write(w, body); err != nil {
+		return err
+	}
+
+	for {
+		body, err := Read(r)
+		if err != nil {
+			return err
+		}
+		var response struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *Error          `json:"error"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		if string(response.ID) != string(rawID) {
+			continue
+		}
+		if response.Error != nil {
+			return fmt.Errorf("%s: %s", response.Error.Code, response.Error.Message)
+		}
+		if result == nil || len(response.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(response.Result, result)
+	}
+}
@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "single message",
+			input: "Content-Length: 2\r\n\r\n{}",
+			want:  []string{"{}"},
+		},
+		{
+			name:  "several messages",
+			input: "Content-Length: 2\r\n\r\n{}Content-Length: 7\r\n\r\n{\"a\":1}",
+			want:  []string{"{}", `{"a":1}`},
+		},
+		{
+			name:  "extra headers and bare newlines",
+			input: "content-length: 2\nContent-Type: application/json\n\n[]",
+			want:  []string{"[]"},
+		},
+		{
+			name:  "blank line between messages",
+			input: "Content-Length: 2\r\n\r\n{}\r\nContent-Length: 2\r\n\r\n[]",
+			want:  []string{"{}", "[]"},
+		},
+		{
+			name:  "body with newlines",
+			input: "Content-Length: 5\r\n\r\n{\n}\r\n",
+			want:  []string{"{\n}\r\n"},
+		},
+		{
+			name:  "empty input",
+			input: "",
+		},
+		{
+			name:    "missing length",
+			input:   "Content-Type: x\r\n\r\n{}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid length",
+			input:   "Content-Length: abc\r\n\r\n{}",
+			wantErr: true,
+		},
+		{
+			name:    "short body",
+			input:   "Content-Length: 10\r\n\r\n{}",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "truncated header",
+			input:   "Content-Length: 2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			var got []string
+			for {
+				body, err := Read(r)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if !tt.wantErr {
+						t.Fatalf("Read() error = %v", err)
+					}
+					return
+				}
+				got = append(got, string(body))
+			}
+			if tt.wantErr {
+				t.Fatalf("Read() expected an error, got %q", got)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResponse(&buf, Response{ID: []byte("7"), Result: map[string]int{"n": 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteResponse(&buf, Response{Error: &Error{Code: CodeFailed, Message: "boom"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteNotification(&buf, Notification{Method: "changed", Params: map[string]string{"file": "a.md"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(&buf)
+	first, err := Read(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != `{"id":7,"result":{"n":1}}` {
+		t.Errorf("first message = %s", first)
+	}
+	second, err := Read(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(second) != `{"error":{"code":"failed","message":"boom"}}` {
+		t.Errorf("second message = %s", second)
+	}
+	third, err := Read(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(third) != `{"method":"changed","params":{"file":"a.md"}}` {
+		t.Errorf("third message = %s", third)
+	}
+	if _, err := Read(r); err != io.EOF {
+		t.Errorf("expected io.EOF after last message, got %v", err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	var replies bytes.Buffer
+	WriteNotification(&replies, Notification{Method: "changed"})
+	WriteResponse(&replies, Response{ID: []byte(`"other"`), Result: "wrong"})
+	WriteResponse(&replies, Response{ID: []byte(`"p1"`), Result: map[string]string{"content": "# Hi"}})
+	WriteResponse(&replies, Response{ID: []byte(`"p2"`), Error: &Error{Code: CodeNotFound, Message: "no such heading"}})
+
+	var sent bytes.Buffer
+	r := bufio.NewReader(&replies)
+	var result struct {
+		Content string `json:"content"`
+	}
+	if err := Call(&sent, r, "p1", "peek", map[string]string{"selector": "a.md#hi"}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != "# Hi" {
+		t.Errorf("content = %q", result.Content)
+	}
+	body, err := Read(bufio.NewReader(&sent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"id":"p1","method":"peek","params":{"selector":"a.md#hi"}}` {
+		t.Errorf("request = %s", body)
+	}
+
+	err = Call(&sent, r, "p2", "peek", nil, &result)
+	if err == nil || !strings.Contains(err.Error(), "no such heading") {
+		t.Errorf("expected error response, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package rules maps inbox subtrees to refile destinations using matchers
+// declared in .jot/rules.yaml
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the rules file name inside the .jot directory
+const FileName = "rules.yaml"
+
+// ErrNoRules is returned when the rules file does not exist
+var ErrNoRules = errors.New("no rules file found")
+
+// tagPattern matches #hashtags in note content
+var tagPattern = regexp.MustCompile(`(?:^|[\s(])#([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+
+// headingMarker matches the leading '#' characters of an ATX heading
+var headingMarker = regexp.MustCompile(`^\s{0,3}#{1,6}(\s|$)`)
+
+// RuleSet is the parsed rules file
+type RuleSet struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule files subtrees that satisfy every configured matcher to a destination
+type Rule struct {
+	Name    string  `yaml:"name"`
+	Match   Matcher `yaml:"match"`
+	To      string  `yaml:"to"`
+	Prepend bool    `yaml:"prepend,omitempty"`
+
+	heading *regexp.Regexp
+}
+
+// Matcher lists the conditions a subtree must meet. Every non-empty field must
+// match; within a list, any single entry is enough.
+type Matcher struct {
+	Tags     []string `yaml:"tags,omitempty"`     // #hashtags in the subtree, without '#'
+	Heading  string   `yaml:"heading,omitempty"`  // Regular expression tested against the heading text
+	Template string   `yaml:"template,omitempty"` // Template the subtree was captured with
+	Keywords []string `yaml:"keywords,omitempty"` // Case-insensitive words or phrases in the subtree
+}
+
+// Item is a subtree to be matched against rules
+type Item struct {
+	Heading  string
+	Content  string
+	Template string
+}
+
+// Load reads and validates a rules file
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoRules
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates rules file content
+func Parse(data []byte) (*RuleSet, error) {
+	var set RuleSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	for i, rule := range set.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if strings.TrimSpace(rule.To) == "" {
+			return nil, fmt.Errorf("%s: missing 'to' destination", rule.Name)
+		}
+		if !strings.Contains(rule.To, "#") {
+			return nil, fmt.Errorf("%s: destination %q must be a selector like 'file.md#heading'", rule.Name, rule.To)
+		}
+		if rule.Match.IsEmpty() {
+			return nil, fmt.Errorf("%s: at least one matcher (tags, heading, template, keywords) is required", rule.Name)
+		}
+		if rule.Match.Heading != "" {
+			re, err := regexp.Compile(rule.Match.Heading)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid heading pattern: %w", rule.Name, err)
+			}
+			rule.heading = re
+		}
+	}
+
+	return &set, nil
+}
+
+// IsEmpty reports whether the matcher has no conditions
+func (m Matcher) IsEmpty() bool {
+	return len(m.Tags) == 0 && m.Heading == "" && m.Template == "" && len(m.Keywords) == 0
+}
+
+// Find returns the first rule that matches the item, or nil
+func (s *RuleSet) Find(item Item) *Rule {
+	for _, rule := range s.Rules {
+		if rule.Matches(item) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Matches reports whether every configured matcher matches the item
+func (r *Rule) Matches(item Item) bool {
+	m := r.Match
+
+	if r.heading != nil && !r.heading.MatchString(item.Heading) {
+		return false
+	}
+
+	if m.Template != "" && !strings.EqualFold(m.Template, item.Template) {
+		return false
+	}
+
+	if len(m.Tags) > 0 {
+		tags := make(map[string]bool)
+		for _, tag := range ExtractTags(item.Content) {
+			tags[strings.ToLower(tag)] = true
+		}
+		found := false
+		for _, tag := range m.Tags {
+			if tags[strings.ToLower(strings.TrimPrefix(tag, "#"))] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(m.Keywords) > 0 {
+		lower := strings.ToLower(item.Content)
+		found := false
+		for _, keyword := range m.Keywords {
+			if keyword != "" && strings.Contains(lower, strings.ToLower(keyword)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ExtractTags returns the #hashtags in content, ignoring markdown headings
+func ExtractTags(content string) []string {
+	var tags []string
+	for _, line := range strings.Split(content, "\n") {
+		// Heading markers are not tags, but tags after the heading text are
+		line = headingMarker.ReplaceAllString(line, "")
+		for _, match := range tagPattern.FindAllStringSubmatch(line, -1) {
+			tags = append(tags, match[1])
+		}
+	}
+	return tags
+}
@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testRules = `
+rules:
+  - name: standups
+    match:
+      heading: "(?i)standup"
+    to: "work.md#meetings/standups"
+  - name: reading
+    match:
+      tags: [read, "#article"]
+    to: "reading.md#queue"
+    prepend: true
+  - match:
+      template: meeting
+      keywords: [agenda]
+    to: "work.md#meetings"
+`
+
+func TestParseAndFind(t *testing.T) {
+	set, err := Parse([]byte(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		item Item
+		want string
+	}{
+		{"heading regex", Item{Heading: "Daily Standup"}, "standups"},
+		{"tag", Item{Heading: "Go generics", Content: "## Go generics\n\nSaw this #article today"}, "reading"},
+		{"template and keyword", Item{Heading: "Sync", Template: "meeting", Content: "Agenda: plan"}, "rule 3"},
+		{"template without keyword", Item{Heading: "Sync", Template: "meeting", Content: "notes"}, ""},
+		{"no match", Item{Heading: "Groceries", Content: "milk"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if rule := set.Find(tt.item); rule != nil {
+				got = rule.Name
+			}
+			if got != tt.want {
+				t.Errorf("Find() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	invalid := []string{
+		"rules:\n  - match: {heading: x}\n",
+		"rules:\n  - match: {heading: x}\n    to: work.md\n",
+		"rules:\n  - to: work.md#a\n",
+		"rules:\n  - match: {heading: \"(\"}\n    to: work.md#a\n",
+	}
+	for _, data := range invalid {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) expected error", data)
+		}
+	}
+}
+
+func TestExtractTags(t *testing.T) {
+	content := "## Heading #inline\n\nText with #one and (#two), not a#three.\n# Title"
+	want := []string{"inline", "one", "two"}
+	if got := ExtractTags(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractTags() = %v, want %v", got, want)
+	}
+}
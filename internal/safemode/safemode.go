@@ -0,0 +1,88 @@
+// Package safemode keeps jot from running code a workspace brings with it:
+// hook scripts, shell commands in templates, eval blocks and recipes, and
+// commands named in .jot/config.json. It is on with --safe, and for any
+// workspace that is not in the workspace registry, so looking through
+// someone else's notes never runs their code, whatever they approved.
+package safemode
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrSafeMode is wrapped by every error for something safe mode refused
+var ErrSafeMode = errors.New("safe mode")
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	reason  string
+	skipped map[string]bool
+)
+
+// Configure turns safe mode on or off. The reason says why it is on, as
+// "--safe was given", and is shown with everything it refuses.
+func Configure(on bool, why string) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = on
+	reason = why
+	skipped = make(map[string]bool)
+}
+
+// Enabled reports whether safe mode is on
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Reason returns why safe mode is on, or "" when it is off
+func Reason() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return ""
+	}
+	return reason
+}
+
+// Error reports something safe mode refused to run
+type Error struct {
+	What   string // Such as "eval blocks"
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("safe mode does not run %s: %s", e.What, e.Reason)
+}
+
+func (e *Error) Unwrap() error { return ErrSafeMode }
+
+// Check returns an *Error when safe mode is on, for what was about to run
+func Check(what string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return nil
+	}
+	return &Error{What: what, Reason: reason}
+}
+
+// Skip reports whether what must be skipped, warning on stderr the first
+// time it is, for things such as hooks that are left out rather than
+// failing the command
+func Skip(what string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return false
+	}
+	if !skipped[what] {
+		skipped[what] = true
+		fmt.Fprintf(os.Stderr, "Skipped %s, as safe mode is on: %s\n", what, reason)
+	}
+	return true
+}
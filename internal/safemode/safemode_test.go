@@ -0,0 +1,30 @@
+package safemode
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSafeMode(t *testing.T) {
+	defer Configure(false, "")
+
+	Configure(false, "")
+	if Enabled() || Reason() != "" || Check("eval blocks") != nil || Skip("hooks") {
+		t.Fatal("nothing is refused while safe mode is off")
+	}
+
+	Configure(true, "--safe was given")
+	if !Enabled() || Reason() != "--safe was given" {
+		t.Errorf("Enabled() = %v, Reason() = %q", Enabled(), Reason())
+	}
+	err := Check("eval blocks")
+	if !errors.Is(err, ErrSafeMode) {
+		t.Fatalf("Check() = %v, want an ErrSafeMode error", err)
+	}
+	if want := "safe mode does not run eval blocks: --safe was given"; err.Error() != want {
+		t.Errorf("Check() = %q, want %q", err, want)
+	}
+	if !Skip("hooks") || !Skip("hooks") {
+		t.Error("Skip() = false while safe mode is on")
+	}
+}
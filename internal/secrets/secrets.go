@@ -0,0 +1,252 @@
+// Package secrets keeps tokens and other secrets in the OS keyring, so
+// configuration and templates can refer to them by name, as in
+// {{secret github_token}}, instead of holding them in files.
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Service is the keyring service jot's secrets are stored under
+const Service = "jot"
+
+// ErrNotFound is returned for a secret that is not in the keyring
+var ErrNotFound = errors.New("secret not found")
+
+// Keyring stores secrets by name
+type Keyring interface {
+	Set(name, value string) error
+	Get(name string) (string, error)
+	Delete(name string) error
+}
+
+var (
+	mu      sync.Mutex
+	current Keyring
+)
+
+// Use makes keyring the one secrets are kept in, in place of the system's
+func Use(keyring Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = keyring
+}
+
+func keyring() (Keyring, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		return current, nil
+	}
+	return systemKeyring()
+}
+
+// namePattern matches a valid secret name
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidateName checks that name can be stored and referred to
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("secret names may use letters, digits, '_', '.' and '-'")
+	}
+	return nil
+}
+
+// Set stores a secret in the keyring, replacing any with the same name
+func Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	k, err := keyring()
+	if err != nil {
+		return err
+	}
+	return k.Set(name, value)
+}
+
+// Get reads a secret from the keyring
+func Get(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	k, err := keyring()
+	if err != nil {
+		return "", err
+	}
+	value, err := k.Get(name)
+	if errors.Is(err, ErrNotFound) {
+		return "", fmt.Errorf("%w: %s; store it with 'jot secret set %s'", ErrNotFound, name, name)
+	}
+	return value, err
+}
+
+// Delete removes a secret from the keyring
+func Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	k, err := keyring()
+	if err != nil {
+		return err
+	}
+	return k.Delete(name)
+}
+
+// refPattern matches a reference to a secret, "{{secret NAME}}"
+var refPattern = regexp.MustCompile(`\{\{\s*secret\s+([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// HasRef reports whether s refers to any secret
+func HasRef(s string) bool {
+	return refPattern.MatchString(s)
+}
+
+// ParseRef returns the name of the secret value refers to, when value is a
+// single reference and nothing else
+func ParseRef(value string) (string, bool) {
+	match := refPattern.FindStringSubmatchIndex(strings.TrimSpace(value))
+	if match == nil || match[0] != 0 || match[1] != len(strings.TrimSpace(value)) {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	return value[match[2]:match[3]], true
+}
+
+// EnvName returns the environment variable a secret is passed to commands
+// in, such as JOT_SECRET_GITHUB_TOKEN
+func EnvName(name string) string {
+	return "JOT_SECRET_" + strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' {
+			return '_'
+		}
+		return r
+	}, strings.ToUpper(name))
+}
+
+// ForCommand replaces each secret reference in a shell command with the
+// quoted variable holding it, and returns the variables to run it with.
+// Secrets never appear in the command line itself.
+func ForCommand(command string) (string, []string, error) {
+	var env []string
+	var failed error
+	seen := map[string]bool{}
+	replaced := refPattern.ReplaceAllStringFunc(command, func(ref string) string {
+		name := refPattern.FindStringSubmatch(ref)[1]
+		variable := EnvName(name)
+		if !seen[name] && failed == nil {
+			seen[name] = true
+			value, err := Get(name)
+			if err != nil {
+				failed = err
+			}
+			env = append(env, variable+"="+value)
+		}
+		return `"$` + variable + `"`
+	})
+	if failed != nil {
+		return "", nil, failed
+	}
+	return replaced, env, nil
+}
+
+// systemKeyring returns the keyring of the OS: the login keychain on macOS
+// and the Secret Service (GNOME Keyring, KWallet) on Linux, through their
+// command line tools
+func systemKeyring() (Keyring, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return keychain{}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, fmt.Errorf("no keyring available: install secret-tool (libsecret-tools) to store secrets")
+		}
+		return secretService{}, nil
+	default:
+		return nil, fmt.Errorf("no keyring available on %s", runtime.GOOS)
+	}
+}
+
+// keychain keeps secrets in the macOS login keychain
+type keychain struct{}
+
+func (keychain) Set(name, value string) error {
+	// Commands go to "security -i" on stdin, keeping the value out of the
+	// process list
+	script := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(Service), quote(name), quote(value))
+	_, err := run(script, "security", "-i")
+	return err
+}
+
+func (keychain) Get(name string) (string, error) {
+	out, err := run("", "security", "find-generic-password", "-s", Service, "-a", name, "-w")
+	if err != nil {
+		if strings.Contains(err.Error(), "could not be found") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (keychain) Delete(name string) error {
+	_, err := run("", "security", "delete-generic-password", "-s", Service, "-a", name)
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		return ErrNotFound
+	}
+	return err
+}
+
+// quote quotes s for the "security -i" command line
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// secretService keeps secrets in the Secret Service through secret-tool
+type secretService struct{}
+
+func (secretService) Set(name, value string) error {
+	_, err := run(value, "secret-tool", "store", "--label", "jot: "+name, "service", Service, "account", name)
+	return err
+}
+
+func (secretService) Get(name string) (string, error) {
+	out, err := run("", "secret-tool", "lookup", "service", Service, "account", name)
+	if err != nil {
+		// lookup fails silently when there is no such secret
+		if strings.HasSuffix(err.Error(), "exit status 1") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+func (s secretService) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	_, err := run("", "secret-tool", "clear", "service", Service, "account", name)
+	return err
+}
+
+// run runs a keyring tool with stdin, returning its output
+func run(stdin string, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %s: %w", name, msg, err)
+		}
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+// memoryKeyring keeps secrets in a map, for tests
+type memoryKeyring map[string]string
+
+func (k memoryKeyring) Set(name, value string) error {
+	k[name] = value
+	return nil
+}
+
+func (k memoryKeyring) Get(name string) (string, error) {
+	value, ok := k[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (k memoryKeyring) Delete(name string) error {
+	if _, ok := k[name]; !ok {
+		return ErrNotFound
+	}
+	delete(k, name)
+	return nil
+}
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		value, name string
+		ok          bool
+	}{
+		{"{{secret github_token}}", "github_token", true},
+		{" {{ secret jira.token }} ", "jira.token", true},
+		{"ghp_abc123", "", false},
+		{"Bearer {{secret github_token}}", "", false},
+		{"{{secret a}}{{secret b}}", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := ParseRef(tt.value)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("ParseRef(%q) = %q, %v; want %q, %v", tt.value, name, ok, tt.name, tt.ok)
+		}
+	}
+}
+
+func TestForCommand(t *testing.T) {
+	Use(memoryKeyring{"github_token": "ghp_abc", "jira-token": "xyz"})
+	defer Use(nil)
+
+	command, env, err := ForCommand(`curl -H "Bearer {{secret github_token}}" -u {{secret jira-token}}:{{secret github_token}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `curl -H "Bearer "$JOT_SECRET_GITHUB_TOKEN"" -u "$JOT_SECRET_JIRA_TOKEN":"$JOT_SECRET_GITHUB_TOKEN"`
+	if command != want {
+		t.Errorf("command = %s, want %s", command, want)
+	}
+	if len(env) != 2 || env[0] != "JOT_SECRET_GITHUB_TOKEN=ghp_abc" || env[1] != "JOT_SECRET_JIRA_TOKEN=xyz" {
+		t.Errorf("env = %v", env)
+	}
+
+	if _, _, err := ForCommand("echo {{secret missing}}"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing secret: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestSetGetDelete(t *testing.T) {
+	keyring := memoryKeyring{}
+	Use(keyring)
+	defer Use(nil)
+
+	if err := Set("bad name", "x"); err == nil {
+		t.Error("Set accepted a name with a space")
+	}
+	if err := Set("token", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := Get("token"); err != nil || value != "s3cret" {
+		t.Errorf("Get = %q, %v", value, err)
+	}
+	if err := Delete("token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+	}
+}
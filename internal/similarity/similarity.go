@@ -0,0 +1,233 @@
+// Package similarity ranks text documents by how similar they are to a query,
+// using TF-IDF by default or vectors from an external embedding command.
+package similarity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Backend names
+const (
+	BackendTFIDF     = "tfidf"
+	BackendEmbedding = "embedding"
+)
+
+// embedTimeout bounds how long an embedding command may run
+const embedTimeout = 120 * time.Second
+
+// Document is a unit of text that can be ranked
+type Document struct {
+	ID   string
+	Text string
+}
+
+// Match is a document ranked against a query
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// Vector is a sparse term-weight vector
+type Vector map[string]float64
+
+// stopWords are common English words that carry little topical meaning
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "their": true, "then": true, "there": true,
+	"these": true, "this": true, "to": true, "was": true, "were": true, "will": true,
+	"with": true, "we": true, "you": true, "i": true, "not": true, "so": true, "if": true,
+}
+
+// Tokenize splits text into lowercase terms, dropping stop words and
+// single-character tokens
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if len([]rune(field)) < 2 || stopWords[field] {
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// TFIDF holds inverse document frequencies for a corpus
+type TFIDF struct {
+	idf  map[string]float64
+	docs int
+}
+
+// NewTFIDF builds TF-IDF weights from a corpus of documents
+func NewTFIDF(docs []Document) *TFIDF {
+	frequency := make(map[string]int)
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		for _, term := range Tokenize(doc.Text) {
+			if !seen[term] {
+				seen[term] = true
+				frequency[term]++
+			}
+		}
+	}
+
+	idf := make(map[string]float64, len(frequency))
+	for term, count := range frequency {
+		// Smoothed so terms in every document still carry a little weight
+		idf[term] = math.Log(float64(1+len(docs))/float64(1+count)) + 1
+	}
+	return &TFIDF{idf: idf, docs: len(docs)}
+}
+
+// Vector returns the TF-IDF vector for text. Terms that are not in the corpus
+// get the weight of a term seen in no documents.
+func (t *TFIDF) Vector(text string) Vector {
+	counts := make(map[string]int)
+	terms := Tokenize(text)
+	for _, term := range terms {
+		counts[term]++
+	}
+
+	unseen := math.Log(float64(1+t.docs)) + 1
+	vector := make(Vector, len(counts))
+	for term, count := range counts {
+		weight, ok := t.idf[term]
+		if !ok {
+			weight = unseen
+		}
+		vector[term] = float64(count) / float64(len(terms)) * weight
+	}
+	return vector
+}
+
+// Cosine returns the cosine similarity of two sparse vectors
+func Cosine(a, b Vector) float64 {
+	var dot, normA, normB float64
+	for term, weight := range a {
+		normA += weight * weight
+		if other, ok := b[term]; ok {
+			dot += weight * other
+		}
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// CosineDense returns the cosine similarity of two dense vectors
+func CosineDense(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// RankTFIDF ranks documents against a query using TF-IDF cosine similarity
+func RankTFIDF(query string, docs []Document) []Match {
+	index := NewTFIDF(docs)
+	queryVector := index.Vector(query)
+
+	matches := make([]Match, 0, len(docs))
+	for _, doc := range docs {
+		matches = append(matches, Match{ID: doc.ID, Score: Cosine(queryVector, index.Vector(doc.Text))})
+	}
+	return sortMatches(matches)
+}
+
+// Embedder turns texts into dense vectors
+type Embedder interface {
+	Embed(texts []string) ([][]float64, error)
+}
+
+// RankEmbedding ranks documents against a query using vectors from an embedder
+func RankEmbedding(embedder Embedder, query string, docs []Document) ([]Match, error) {
+	texts := make([]string, 0, len(docs)+1)
+	texts = append(texts, query)
+	for _, doc := range docs {
+		texts = append(texts, doc.Text)
+	}
+
+	vectors, err := embedder.Embed(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+
+	matches := make([]Match, 0, len(docs))
+	for i, doc := range docs {
+		matches = append(matches, Match{ID: doc.ID, Score: CosineDense(vectors[0], vectors[i+1])})
+	}
+	return sortMatches(matches), nil
+}
+
+// CommandEmbedder runs a shell command that reads {"texts": [...]} on stdin
+// and writes {"embeddings": [[...], ...]} to stdout
+type CommandEmbedder struct {
+	Command string
+}
+
+// Embed runs the embedding command
+func (e *CommandEmbedder) Embed(texts []string) ([][]float64, error) {
+	payload, err := json.Marshal(map[string][]string{"texts": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), embedTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", e.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("embedding command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding output: %w", err)
+	}
+	return result.Embeddings, nil
+}
+
+// sortMatches orders matches by descending score, then by ID for stable output
+func sortMatches(matches []Match) []Match {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
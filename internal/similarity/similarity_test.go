@@ -0,0 +1,59 @@
+package similarity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("The Kubernetes cluster is DOWN, and a pod-restart fixed it!")
+	want := []string{"kubernetes", "cluster", "down", "pod", "restart", "fixed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestRankTFIDF(t *testing.T) {
+	docs := []Document{
+		{ID: "cooking", Text: "Sourdough bread recipe with flour, water and starter"},
+		{ID: "k8s", Text: "Kubernetes cluster upgrade notes: drain nodes before the upgrade"},
+		{ID: "empty", Text: ""},
+		{ID: "deploy", Text: "Deploy checklist for the cluster"},
+	}
+
+	matches := RankTFIDF("Planning the next Kubernetes cluster upgrade", docs)
+
+	if len(matches) != len(docs) {
+		t.Fatalf("got %d matches, want %d", len(matches), len(docs))
+	}
+	if matches[0].ID != "k8s" || matches[1].ID != "deploy" {
+		t.Errorf("unexpected ranking: %+v", matches)
+	}
+	for _, match := range matches[2:] {
+		if match.Score != 0 {
+			t.Errorf("%s score = %v, want 0", match.ID, match.Score)
+		}
+	}
+}
+
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Embed(texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float64{float64(len(text)), 1}
+	}
+	return vectors, nil
+}
+
+func TestRankEmbedding(t *testing.T) {
+	docs := []Document{{ID: "long", Text: "a much longer document"}, {ID: "short", Text: "abc"}}
+
+	matches, err := RankEmbedding(fakeEmbedder{}, "abcd", docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches[0].ID != "short" {
+		t.Errorf("unexpected ranking: %+v", matches)
+	}
+}
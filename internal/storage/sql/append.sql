@@ -0,0 +1,2 @@
+INSERT INTO notes (path, content, modified) VALUES (:path, :content, :modified)
+ON CONFLICT (path) DO UPDATE SET content = CAST(content || excluded.content AS BLOB), modified = excluded.modified;
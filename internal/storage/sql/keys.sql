@@ -0,0 +1 @@
+SELECT hex(path) FROM notes;
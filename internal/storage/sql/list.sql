@@ -0,0 +1 @@
+SELECT hex(path), length(content), modified FROM notes;
@@ -0,0 +1,2 @@
+-- The leading 'x' keeps an empty note apart from a missing one
+SELECT 'x' || hex(content) FROM notes WHERE path = :path;
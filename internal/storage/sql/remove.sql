@@ -0,0 +1,2 @@
+DELETE FROM notes WHERE path = :path;
+SELECT changes();
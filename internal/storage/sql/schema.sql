@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS notes (
+    path TEXT PRIMARY KEY,
+    content BLOB NOT NULL,
+    modified INTEGER NOT NULL
+);
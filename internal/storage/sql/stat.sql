@@ -0,0 +1 @@
+SELECT length(content), modified FROM notes WHERE path = :path;
@@ -0,0 +1,2 @@
+-- A directory exists while any note lies beneath it
+SELECT count(*) FROM notes WHERE substr(path, 1, length(:prefix)) = :prefix;
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLite keeps the notes under a workspace root in one SQLite database. It
+// talks to the database through the sqlite3 command-line shell, so jot
+// needs no database driver, and is experimental. Files outside the root,
+// and everything under .jot, stay on the filesystem.
+//
+// Its statements live in sql/ and take their values as named parameters,
+// bound with the shell's .parameter command, so no value is ever spliced
+// into SQL.
+type SQLite struct {
+	Database string // Path of the database file
+	Root     string // Workspace root; notes are keyed by their path below it
+	Command  string // The sqlite3 executable, found on PATH when empty
+
+	fs Filesystem
+}
+
+var (
+	//go:embed sql/schema.sql
+	schemaSQL string
+	//go:embed sql/read.sql
+	readSQL string
+	//go:embed sql/write.sql
+	writeSQL string
+	//go:embed sql/append.sql
+	appendSQL string
+	//go:embed sql/stat.sql
+	statSQL string
+	//go:embed sql/stat_dir.sql
+	statDirSQL string
+	//go:embed sql/remove.sql
+	removeSQL string
+	//go:embed sql/list.sql
+	listSQL string
+	//go:embed sql/keys.sql
+	keysSQL string
+)
+
+// NewSQLite opens the database at database for the notes under root,
+// creating its table if needed
+func NewSQLite(database, root string) (*SQLite, error) {
+	command, err := exec.LookPath("sqlite3")
+	if err != nil {
+		return nil, fmt.Errorf("the sqlite storage backend needs the sqlite3 command on PATH: %w", err)
+	}
+	s := &SQLite{Database: database, Root: root, Command: command}
+	if err := os.MkdirAll(filepath.Dir(database), 0755); err != nil {
+		return nil, err
+	}
+	_, err = s.query(schemaSQL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns "sqlite"
+func (s *SQLite) Name() string { return "sqlite" }
+
+// key returns the note key for a path, or false for paths kept on the
+// filesystem
+func (s *SQLite) key(p string) (string, bool) {
+	rel, err := filepath.Rel(s.Root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".jot" || strings.HasPrefix(rel, ".jot/") {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return rel, true
+}
+
+func (s *SQLite) ReadFile(p string) ([]byte, error) {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.ReadFile(p)
+	}
+	out, err := s.query(readSQL, params{"path": key})
+	if err != nil {
+		return nil, err
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	return hex.DecodeString(string(out[1:]))
+}
+
+func (s *SQLite) WriteFile(p string, data []byte, perm os.FileMode) error {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.WriteFile(p, data, perm)
+	}
+	_, err := s.query(writeSQL, params{"path": key, "content": data, "modified": time.Now().UnixNano()})
+	return err
+}
+
+func (s *SQLite) AppendFile(p string, data []byte, perm os.FileMode) error {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.AppendFile(p, data, perm)
+	}
+	_, err := s.query(appendSQL, params{"path": key, "content": data, "modified": time.Now().UnixNano()})
+	return err
+}
+
+func (s *SQLite) Stat(p string) (os.FileInfo, error) {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.Stat(p)
+	}
+	if key == "" {
+		return dirInfo(s.Root), nil
+	}
+
+	out, err := s.query(statSQL, params{"path": key})
+	if err != nil {
+		return nil, err
+	}
+	if fields := strings.Split(strings.TrimSpace(string(out)), "|"); len(fields) == 2 {
+		size, _ := strconv.ParseInt(fields[0], 10, 64)
+		modified, _ := strconv.ParseInt(fields[1], 10, 64)
+		return &fileInfo{name: path.Base(key), size: size, modTime: time.Unix(0, modified)}, nil
+	}
+
+	// A directory exists while any note lies beneath it
+	out, err = s.query(statDirSQL, params{"prefix": key + "/"})
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := strconv.Atoi(strings.TrimSpace(string(out))); n > 0 {
+		return dirInfo(p), nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+}
+
+func (s *SQLite) Remove(p string) error {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.Remove(p)
+	}
+	out, err := s.query(removeSQL, params{"path": key})
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(out)) == "0" {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+// MkdirAll does nothing for directories under the root, which exist while
+// notes lie beneath them
+func (s *SQLite) MkdirAll(p string, perm os.FileMode) error {
+	if _, ok := s.key(p); ok {
+		return nil
+	}
+	return s.fs.MkdirAll(p, perm)
+}
+
+// Walk visits the notes under root in path order, with an entry for each
+// directory that holds them
+func (s *SQLite) Walk(root string, fn filepath.WalkFunc) error {
+	rootKey, ok := s.key(root)
+	if !ok {
+		return s.fs.Walk(root, fn)
+	}
+
+	out, err := s.query(listSQL, nil)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	entries := make(map[string]*fileInfo)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) != 3 {
+			continue
+		}
+		name, err := hex.DecodeString(fields[0])
+		if err != nil {
+			continue
+		}
+		key := string(name)
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		modified, _ := strconv.ParseInt(fields[2], 10, 64)
+		entries[key] = &fileInfo{name: path.Base(key), size: size, modTime: time.Unix(0, modified)}
+		addParents(entries, key)
+	}
+	return walkEntries(s.Root, rootKey, entries, fn)
+}
+
+// Keys returns the path of every note in the database, in order
+func (s *SQLite) Keys() ([]string, error) {
+	out, err := s.query(keysSQL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, line := range strings.Fields(string(out)) {
+		if name, err := hex.DecodeString(line); err == nil {
+			keys = append(keys, string(name))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// query runs SQL statements in the sqlite3 shell, with params bound to their
+// named parameters, and returns their output
+func (s *SQLite) query(sql string, args params) ([]byte, error) {
+	cmd := exec.Command(s.Command, "-batch", "-bail", s.Database)
+	cmd.Stdin = strings.NewReader(args.bind() + sql)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sqlite storage: %s", msg)
+		}
+		return nil, fmt.Errorf("sqlite storage: %w", err)
+	}
+	return out, nil
+}
+
+// params are the values of a query's named parameters, by name without
+// the leading colon
+type params map[string]interface{}
+
+// bind returns the shell commands that set each parameter. The shell reads
+// a .parameter value as a SQL literal, so strings and blobs go in as hex
+// blob literals, which hold nothing but hex digits whatever the value;
+// strings are cast back to text.
+func (p params) bind() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		var value string
+		switch v := p[name].(type) {
+		case string:
+			value = fmt.Sprintf(`"CAST(X'%s' AS TEXT)"`, hex.EncodeToString([]byte(v)))
+		case []byte:
+			value = fmt.Sprintf("X'%s'", hex.EncodeToString(v))
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		default:
+			panic(fmt.Sprintf("sqlite storage: unsupported parameter type %T", v))
+		}
+		fmt.Fprintf(&b, ".parameter set :%s %s\n", name, value)
+	}
+	return b.String()
+}
+
+// fileInfo describes a note or directory that is not a real file
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func dirInfo(p string) *fileInfo {
+	return &fileInfo{name: filepath.Base(p), dir: true}
+}
+
+func (f *fileInfo) Name() string       { return f.name }
+func (f *fileInfo) Size() int64        { return f.size }
+func (f *fileInfo) ModTime() time.Time { return f.modTime }
+func (f *fileInfo) IsDir() bool        { return f.dir }
+func (f *fileInfo) Sys() interface{}   { return nil }
+
+func (f *fileInfo) Mode() os.FileMode {
+	if f.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
@@ -0,0 +1,414 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSH keeps a workspace's notes on another host, reading and writing them
+// over ssh. Root is a local directory that mirrors the workspace: notes
+// read from the host are cached there and fetched again only when the
+// host's copy changes, and .jot is copied down whole so configuration,
+// hooks and templates work as they do locally. Writes go straight to the
+// host. When the host cannot be reached the cache is read instead, and
+// writes fail.
+//
+// The host needs a POSIX shell, GNU find and tar.
+type SSH struct {
+	Host    string   // [user@]host[:port] from the workspace URL
+	Remote  string   // Workspace directory on the host; relative paths start at the home directory
+	Root    string   // Local directory mirroring the workspace
+	Command []string // Runs a shell command on the host when the command is appended
+
+	manifest map[string]*fileInfo
+	offline  error
+	fs       Filesystem
+}
+
+// jotMarker separates the note listing from the .jot archive in Sync's
+// output; no listing line can equal it
+const jotMarker = "--jot--"
+
+// IsSSHURL reports whether a workspace location names a remote workspace
+func IsSSHURL(location string) bool {
+	return strings.HasPrefix(location, "ssh://")
+}
+
+// NewSSH sets up the remote workspace at an ssh://[user@]host[:port]/path
+// URL, mirrored in a directory under cacheDir. Paths starting with /~/
+// are relative to the home directory on the host. Call Sync before use.
+func NewSSH(location, cacheDir string) (*SSH, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme != "ssh" || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote workspace %q (expected ssh://host/path)", location)
+	}
+
+	remote := u.Path
+	switch {
+	case remote == "/~" || remote == "/~/":
+		remote = "."
+	case strings.HasPrefix(remote, "/~/"):
+		remote = strings.TrimPrefix(remote, "/~/")
+	case remote == "" || remote == "/":
+		return nil, fmt.Errorf("remote workspace %q has no path", location)
+	}
+	remote = strings.TrimSuffix(path.Clean(remote), "/")
+
+	host := u.Host
+	if u.User != nil {
+		host = u.User.Username() + "@" + host
+	}
+	root := filepath.Join(cacheDir, cacheName(host), cacheName(remote))
+
+	command := []string{"ssh",
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(cacheDir, "control-%C"),
+		"-o", "ControlPersist=60"}
+	if override := os.Getenv("JOT_SSH"); override != "" {
+		command = strings.Fields(override)
+	}
+	if port := u.Port(); port != "" {
+		command = append(command, "-p", port)
+	}
+	target := u.Hostname()
+	if u.User != nil {
+		target = u.User.Username() + "@" + target
+	}
+
+	return &SSH{Host: host, Remote: remote, Root: root, Command: append(command, target)}, nil
+}
+
+// cacheName turns a host or path into a single directory name
+func cacheName(s string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, s)
+	if name == "." || name == "" {
+		return "_"
+	}
+	return name
+}
+
+// Name returns "ssh"
+func (s *SSH) Name() string { return "ssh" }
+
+// Offline returns why the host could not be reached by Sync, or nil
+func (s *SSH) Offline() error { return s.offline }
+
+// Sync lists the notes on the host and copies its .jot directory into the
+// mirror, in one round trip. If the host cannot be reached but the mirror
+// holds an earlier copy, the backend goes offline and serves the cache.
+func (s *SSH) Sync() error {
+	script := fmt.Sprintf(
+		"cd -- %s && test -d .jot && "+
+			"find . ! -name . -name '.*' -prune -o ! -name . -printf '%%y %%s %%T@ %%P\\n' && "+
+			"echo %s && tar -cf - --exclude=.jot/journal .jot",
+		shellQuote(s.Remote), jotMarker)
+	out, err := s.run(script, nil)
+	if err != nil {
+		if _, statErr := os.Stat(filepath.Join(s.Root, ".jot")); statErr == nil {
+			s.offline = err
+			return nil
+		}
+		return fmt.Errorf("cannot open remote workspace %s:%s: %w", s.Host, s.Remote, err)
+	}
+
+	marker := []byte("\n" + jotMarker + "\n")
+	listing, archive := out, []byte(nil)
+	if i := bytes.Index(out, marker); i >= 0 {
+		listing, archive = out[:i+1], out[i+len(marker):]
+	} else if bytes.HasPrefix(out, marker[1:]) {
+		listing, archive = nil, out[len(marker)-1:]
+	}
+
+	s.manifest = make(map[string]*fileInfo)
+	for _, line := range strings.Split(string(listing), "\n") {
+		key, info, ok := parseListing(line)
+		if ok {
+			s.manifest[key] = info
+		}
+	}
+
+	if err := os.MkdirAll(s.Root, 0755); err != nil {
+		return err
+	}
+	return extractTar(s.Root, archive)
+}
+
+// parseListing reads a "type size mtime path" line printed by find
+func parseListing(line string) (string, *fileInfo, bool) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 || fields[3] == "" {
+		return "", nil, false
+	}
+	size, _ := strconv.ParseInt(fields[1], 10, 64)
+	return fields[3], &fileInfo{
+		name:    path.Base(fields[3]),
+		size:    size,
+		modTime: parseUnixTime(fields[2]),
+		dir:     fields[0] == "d",
+	}, true
+}
+
+// parseUnixTime reads seconds since the epoch with an optional fraction
+func parseUnixTime(s string) time.Time {
+	secs, frac, _ := strings.Cut(s, ".")
+	sec, _ := strconv.ParseInt(secs, 10, 64)
+	frac = (frac + "000000000")[:9]
+	nsec, _ := strconv.ParseInt(frac, 10, 64)
+	return time.Unix(sec, nsec)
+}
+
+// extractTar unpacks the .jot archive into dir, keeping to dir
+func extractTar(dir string, archive []byte) error {
+	r := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading remote .jot: %w", err)
+		}
+		name := filepath.FromSlash(path.Clean(hdr.Name))
+		if name != ".jot" && !strings.HasPrefix(name, ".jot"+string(filepath.Separator)) {
+			continue
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, data, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return err
+			}
+			if err := os.Chmod(target, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// key returns the note key for a path, or false for paths kept locally:
+// those outside the mirror and hidden ones such as .jot
+func (s *SSH) key(p string) (string, bool) {
+	rel, err := filepath.Rel(s.Root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		return "", true
+	}
+	if strings.HasPrefix(rel, ".") {
+		return "", false
+	}
+	return rel, true
+}
+
+// remote returns the quoted path of a note on the host
+func (s *SSH) remote(key string) string {
+	return shellQuote(path.Join(s.Remote, key))
+}
+
+func (s *SSH) ReadFile(p string) ([]byte, error) {
+	key, ok := s.key(p)
+	if !ok || s.offline != nil {
+		return s.fs.ReadFile(p)
+	}
+	info := s.manifest[key]
+	if info == nil || info.dir {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	if cached, err := os.Stat(p); err == nil && cached.Size() == info.size && cached.ModTime().Equal(info.modTime) {
+		return os.ReadFile(p)
+	}
+
+	data, err := s.run("cat -- "+s.remote(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.cache(p, data, info.modTime)
+	return data, nil
+}
+
+// cache keeps a copy of a note in the mirror, stamped with the host's
+// modification time so later reads can tell whether it is current
+func (s *SSH) cache(p string, data []byte, modTime time.Time) {
+	if os.MkdirAll(filepath.Dir(p), 0755) != nil || os.WriteFile(p, data, 0644) != nil {
+		return
+	}
+	os.Chtimes(p, modTime, modTime)
+}
+
+func (s *SSH) WriteFile(p string, data []byte, perm os.FileMode) error {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.WriteFile(p, data, perm)
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	dst := s.remote(key)
+	tmp := shellQuote(path.Join(s.Remote, key) + ".jot-tmp")
+	script := fmt.Sprintf("mkdir -p -- %s && cat > %s && mv -f -- %s %s && find %s -printf '%%y %%s %%T@ %%f\\n'",
+		shellQuote(path.Dir(path.Join(s.Remote, key))), tmp, tmp, dst, dst)
+	info, err := s.update(key, script, data)
+	if err != nil {
+		return err
+	}
+	s.cache(p, data, info.modTime)
+	return nil
+}
+
+func (s *SSH) AppendFile(p string, data []byte, perm os.FileMode) error {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.AppendFile(p, data, perm)
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	dst := s.remote(key)
+	script := fmt.Sprintf("mkdir -p -- %s && cat >> %s && find %s -printf '%%y %%s %%T@ %%f\\n'",
+		shellQuote(path.Dir(path.Join(s.Remote, key))), dst, dst)
+	if _, err := s.update(key, script, data); err != nil {
+		return err
+	}
+	// The next read fetches the whole note again
+	os.Remove(p)
+	return nil
+}
+
+// update runs a script that changes a note and prints its new listing
+// line, and records the note in the manifest
+func (s *SSH) update(key, script string, stdin []byte) (*fileInfo, error) {
+	out, err := s.run(script, stdin)
+	if err != nil {
+		return nil, err
+	}
+	_, info, ok := parseListing(strings.TrimSpace(string(out)))
+	if !ok {
+		return nil, fmt.Errorf("ssh storage: unexpected reply %q", strings.TrimSpace(string(out)))
+	}
+	info.name = path.Base(key)
+	s.manifest[key] = info
+	addParents(s.manifest, key)
+	return info, nil
+}
+
+func (s *SSH) Stat(p string) (os.FileInfo, error) {
+	key, ok := s.key(p)
+	if !ok || s.offline != nil {
+		return s.fs.Stat(p)
+	}
+	if key == "" {
+		return dirInfo(s.Root), nil
+	}
+	if info := s.manifest[key]; info != nil {
+		return info, nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+}
+
+func (s *SSH) Remove(p string) error {
+	key, ok := s.key(p)
+	if !ok {
+		return s.fs.Remove(p)
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if s.manifest[key] == nil {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	if _, err := s.run("rm -- "+s.remote(key), nil); err != nil {
+		return err
+	}
+	delete(s.manifest, key)
+	os.Remove(p)
+	return nil
+}
+
+func (s *SSH) MkdirAll(p string, perm os.FileMode) error {
+	key, ok := s.key(p)
+	if !ok || key == "" {
+		return s.fs.MkdirAll(p, perm)
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if info := s.manifest[key]; info != nil && info.dir {
+		return nil
+	}
+	if _, err := s.run("mkdir -p -- "+s.remote(key), nil); err != nil {
+		return err
+	}
+	s.manifest[key] = dirInfo(key)
+	addParents(s.manifest, key)
+	return nil
+}
+
+// Walk visits the notes on the host, or the cached ones when offline
+func (s *SSH) Walk(root string, fn filepath.WalkFunc) error {
+	rootKey, ok := s.key(root)
+	if !ok || s.offline != nil {
+		return s.fs.Walk(root, fn)
+	}
+	return walkEntries(s.Root, rootKey, s.manifest, fn)
+}
+
+// writable returns an error while the host is unreachable
+func (s *SSH) writable() error {
+	if s.offline != nil {
+		return fmt.Errorf("remote workspace %s:%s is unreachable, so notes are read-only: %w", s.Host, s.Remote, s.offline)
+	}
+	return nil
+}
+
+// run runs a shell script on the host, feeding it stdin
+func (s *SSH) run(script string, stdin []byte) ([]byte, error) {
+	cmd := exec.Command(s.Command[0], append(s.Command[1:], script)...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); msg != "" && errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("ssh storage: %s", msg)
+		}
+		return nil, fmt.Errorf("ssh storage: %w", err)
+	}
+	return out, nil
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// localSSH returns an SSH backend whose "host" is a local shell, for a
+// workspace in a temporary directory
+func localSSH(t *testing.T) (*SSH, string) {
+	t.Helper()
+	if exec.Command("find", ".", "-maxdepth", "0", "-printf", "").Run() != nil {
+		t.Skip("GNU find not installed")
+	}
+	remote := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(remote, ".jot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(remote, ".jot", "config.json"), "{}")
+
+	s, err := NewSSH("ssh://notes.example.com"+filepath.ToSlash(remote), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Command = []string{"sh", "-c"}
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	return s, remote
+}
+
+func TestSSHBackend(t *testing.T) {
+	s, _ := localSSH(t)
+	testBackend(t, s.Root, func(root string) Backend { return s })
+}
+
+func TestSSHSyncCopiesJotDir(t *testing.T) {
+	s, _ := localSSH(t)
+	data, err := os.ReadFile(filepath.Join(s.Root, ".jot", "config.json"))
+	if err != nil || string(data) != "{}" {
+		t.Errorf("mirrored config = %q, %v", data, err)
+	}
+}
+
+func TestSSHRefetchesChangedNotes(t *testing.T) {
+	s, remote := localSSH(t)
+	note := filepath.Join(remote, "inbox.md")
+	writeFile(t, note, "# One\n")
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(s.Root, "inbox.md")
+	if got, err := s.ReadFile(local); err != nil || string(got) != "# One\n" {
+		t.Fatalf("ReadFile = %q, %v", got, err)
+	}
+
+	// A cached copy is served until the host's copy changes
+	writeFile(t, local, "# Cac\n")
+	os.Chtimes(local, s.manifest["inbox.md"].modTime, s.manifest["inbox.md"].modTime)
+	if got, _ := s.ReadFile(local); string(got) != "# Cac\n" {
+		t.Errorf("cached read = %q, want the cached copy", got)
+	}
+
+	writeFile(t, note, "# Two\n")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(note, later, later)
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ReadFile(local); string(got) != "# Two\n" {
+		t.Errorf("read after change = %q, want %q", got, "# Two\n")
+	}
+}
+
+func TestSSHOfflineReadsCache(t *testing.T) {
+	s, remote := localSSH(t)
+	writeFile(t, filepath.Join(remote, "inbox.md"), "# Inbox\n")
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(s.Root, "inbox.md")
+	if _, err := s.ReadFile(local); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Command = []string{"false"}
+	if err := s.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Offline() == nil {
+		t.Fatal("not offline after the host failed")
+	}
+	if got, err := s.ReadFile(local); err != nil || string(got) != "# Inbox\n" {
+		t.Errorf("offline read = %q, %v", got, err)
+	}
+	if err := s.WriteFile(local, []byte("x"), 0644); err == nil {
+		t.Error("offline write succeeded")
+	}
+}
+
+func TestNewSSH(t *testing.T) {
+	tests := []struct {
+		url, remote, target string
+	}{
+		{"ssh://notes.example.com/srv/notes", "/srv/notes", "notes.example.com"},
+		{"ssh://me@notes.example.com:2222/~/notes/", "notes", "me@notes.example.com"},
+		{"ssh://notes.example.com/~", ".", "notes.example.com"},
+	}
+	for _, tt := range tests {
+		s, err := NewSSH(tt.url, "/cache")
+		if err != nil {
+			t.Errorf("NewSSH(%q): %v", tt.url, err)
+			continue
+		}
+		if s.Remote != tt.remote || s.Command[len(s.Command)-1] != tt.target {
+			t.Errorf("NewSSH(%q) = remote %q, target %q; want %q, %q", tt.url, s.Remote, s.Command[len(s.Command)-1], tt.remote, tt.target)
+		}
+	}
+	for _, bad := range []string{"ssh://host", "ssh:///path", "http://host/path"} {
+		if _, err := NewSSH(bad, "/cache"); err == nil {
+			t.Errorf("NewSSH(%q) succeeded", bad)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
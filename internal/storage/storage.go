@@ -0,0 +1,125 @@
+// Package storage puts note files behind a backend, so a workspace's notes
+// can live somewhere other than the filesystem while selectors keep
+// addressing them by path. The filesystem backend is the default. Commands
+// read notes through this package, and write them through dryrun, which
+// passes real writes on to the current backend.
+package storage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/johncoder/jot/internal/opstats"
+)
+
+// Backend stores note files by path
+type Backend interface {
+	// Name identifies the backend in configuration and status output
+	Name() string
+
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	AppendFile(path string, data []byte, perm os.FileMode) error
+	Stat(path string) (os.FileInfo, error)
+	Remove(path string) error
+	MkdirAll(path string, perm os.FileMode) error
+
+	// Walk visits the files under root like filepath.Walk
+	Walk(root string, fn filepath.WalkFunc) error
+}
+
+var (
+	mu      sync.Mutex
+	current Backend = Filesystem{}
+)
+
+// Use makes backend the one every read and write goes through
+func Use(backend Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = backend
+}
+
+// Current returns the backend in use
+func Current() Backend {
+	mu.Lock()
+	defer mu.Unlock()
+	return current
+}
+
+// IsFilesystem reports whether notes are plain files, so callers may use
+// the os package directly, as large-file peeking and file watching do
+func IsFilesystem() bool {
+	_, ok := Current().(Filesystem)
+	return ok
+}
+
+// ReadFile reads a note through the current backend
+func ReadFile(path string) ([]byte, error) {
+	content, err := Current().ReadFile(path)
+	if err == nil {
+		opstats.Read(path, len(content))
+	}
+	return content, err
+}
+
+// Open opens a note for reading through the current backend. Plain files
+// are read as they are scanned; other backends read the whole note first.
+func Open(path string) (io.ReadCloser, error) {
+	if IsFilesystem() {
+		return os.Open(path)
+	}
+	content, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// Stat describes a note through the current backend
+func Stat(path string) (os.FileInfo, error) {
+	return Current().Stat(path)
+}
+
+// Remove deletes a note through the current backend
+func Remove(path string) error {
+	return Current().Remove(path)
+}
+
+// Walk visits notes under root through the current backend
+func Walk(root string, fn filepath.WalkFunc) error {
+	return Current().Walk(root, fn)
+}
+
+// Filesystem keeps notes as plain files
+type Filesystem struct{}
+
+// Name returns "filesystem"
+func (Filesystem) Name() string { return "filesystem" }
+
+func (Filesystem) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+func (Filesystem) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(path, data, perm)
+}
+
+func (Filesystem) AppendFile(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(data)
+	return err
+}
+
+func (Filesystem) Stat(path string) (os.FileInfo, error) { return os.Stat(path) }
+
+func (Filesystem) Remove(path string) error { return os.Remove(path) }
+
+func (Filesystem) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+
+func (Filesystem) Walk(root string, fn filepath.WalkFunc) error { return filepath.Walk(root, fn) }
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilesystemBackend(t *testing.T) {
+	testBackend(t, t.TempDir(), func(root string) Backend { return Filesystem{} })
+}
+
+func TestSQLiteBackend(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	testBackend(t, t.TempDir(), func(root string) Backend {
+		backend, err := NewSQLite(filepath.Join(root, ".jot", "notes.db"), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return backend
+	})
+}
+
+func TestSQLiteKeepsJotDirOnFilesystem(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	root := t.TempDir()
+	backend, err := NewSQLite(filepath.Join(root, ".jot", "notes.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := filepath.Join(root, ".jot", "config.json")
+	if err := backend.WriteFile(config, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(config); err != nil {
+		t.Errorf("config not written to the filesystem: %v", err)
+	}
+
+	note := filepath.Join(root, "inbox.md")
+	if err := backend.WriteFile(note, []byte("# Inbox\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(note); !os.IsNotExist(err) {
+		t.Errorf("note written to the filesystem: %v", err)
+	}
+}
+
+func TestSQLiteBindsValues(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	root := t.TempDir()
+	backend, err := NewSQLite(filepath.Join(root, ".jot", "notes.db"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Quotes, statements and shell commands in values stay values
+	note := filepath.Join(root, `it's"; DROP TABLE notes; --.md`)
+	content := []byte("'); DELETE FROM notes; --\n.parameter clear\n\x00end")
+	if err := backend.WriteFile(note, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := backend.ReadFile(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("ReadFile = %q, want %q", got, content)
+	}
+	if keys, err := backend.Keys(); err != nil || len(keys) != 1 {
+		t.Errorf("Keys = %v, %v; want the one note", keys, err)
+	}
+}
+
+func testBackend(t *testing.T, root string, open func(root string) Backend) {
+	backend := open(root)
+	inbox := filepath.Join(root, "inbox.md")
+	work := filepath.Join(root, "lib", "work.md")
+
+	if _, err := backend.ReadFile(inbox); !os.IsNotExist(err) {
+		t.Fatalf("ReadFile of a missing note = %v, want not exist", err)
+	}
+	if _, err := backend.Stat(inbox); !os.IsNotExist(err) {
+		t.Fatalf("Stat of a missing note = %v, want not exist", err)
+	}
+
+	content := []byte("# Inbox\n\n## Task\n\x00binary-safe\n")
+	if err := backend.WriteFile(inbox, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.AppendFile(inbox, []byte("more\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := backend.ReadFile(inbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := string(content) + "more\n"; string(got) != want {
+		t.Errorf("ReadFile = %q, want %q", got, want)
+	}
+
+	if err := backend.MkdirAll(filepath.Dir(work), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.AppendFile(work, []byte("# Work\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := backend.Stat(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.IsDir() || info.Size() != int64(len("# Work\n")) || info.Name() != "work.md" {
+		t.Errorf("Stat = dir %v, size %d, name %q", info.IsDir(), info.Size(), info.Name())
+	}
+	if info, err := backend.Stat(filepath.Dir(work)); err != nil || !info.IsDir() {
+		t.Errorf("Stat of lib = %v, %v; want a directory", info, err)
+	}
+
+	var walked []string
+	err = backend.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".jot" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			walked = append(walked, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"inbox.md", "lib/work.md"}; !reflect.DeepEqual(walked, want) {
+		t.Errorf("Walk = %v, want %v", walked, want)
+	}
+
+	if err := backend.Remove(inbox); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Stat(inbox); !os.IsNotExist(err) {
+		t.Errorf("Stat after Remove = %v, want not exist", err)
+	}
+	if err := backend.Remove(inbox); !os.IsNotExist(err) {
+		t.Errorf("second Remove = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// walkEntries visits the entries under rootKey like filepath.Walk, for
+// backends that know every note and directory by its slash-separated key
+// below base
+func walkEntries(base, rootKey string, entries map[string]*fileInfo, fn filepath.WalkFunc) error {
+	root := filepath.Join(base, filepath.FromSlash(rootKey))
+	if rootKey != "" {
+		info, ok := entries[rootKey]
+		if !ok {
+			return fn(root, nil, &os.PathError{Op: "lstat", Path: root, Err: os.ErrNotExist})
+		}
+		if !info.dir {
+			if err := fn(root, info, nil); err != filepath.SkipDir {
+				return err
+			}
+			return nil
+		}
+	}
+	if err := fn(root, dirInfo(root), nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	prefix := ""
+	if rootKey != "" {
+		prefix = rootKey + "/"
+	}
+	var keys []string
+	for key := range entries {
+		if strings.HasPrefix(key, prefix) && key != rootKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return pathLess(keys[i], keys[j]) })
+
+	var skipped []string
+	for _, key := range keys {
+		if under(key, skipped) {
+			continue
+		}
+		info := entries[key]
+		err := fn(filepath.Join(base, filepath.FromSlash(key)), info, nil)
+		if err == filepath.SkipDir {
+			// Skipping a directory skips its contents; skipping a file
+			// skips the rest of the directory it is in
+			if info.dir {
+				skipped = append(skipped, key+"/")
+				continue
+			}
+			dir := path.Dir(key)
+			if dir == "." || dir == rootKey {
+				return nil
+			}
+			skipped = append(skipped, dir+"/")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addParents adds an entry for every directory above key
+func addParents(entries map[string]*fileInfo, key string) {
+	for dir := path.Dir(key); dir != "." && entries[dir] == nil; dir = path.Dir(dir) {
+		entries[dir] = dirInfo(dir)
+	}
+}
+
+// pathLess orders keys as filepath.Walk visits them, directory by directory
+func pathLess(a, b string) bool {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// under reports whether key lies in one of the skipped directories
+func under(key string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(key, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+// Package suggest asks an external, user-configured backend to rank candidate
+// destinations for a subtree. The backend is either a command that reads a JSON
+// request on stdin and writes a JSON response to stdout, or an HTTP endpoint that
+// accepts the same request as a POST body. jot never talks to a model directly.
+package suggest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/netutil"
+)
+
+// TaskRefile asks the backend to rank refile destinations
+const TaskRefile = "refile"
+
+// TokenEnvVar holds an optional bearer token sent to HTTP endpoints
+const TokenEnvVar = "JOT_SUGGEST_TOKEN"
+
+// DefaultTimeout bounds how long a backend may take to respond
+const DefaultTimeout = 60 * time.Second
+
+// Source describes the subtree suggestions are requested for
+type Source struct {
+	Selector string `json:"selector"`
+	Heading  string `json:"heading"`
+	Content  string `json:"content"`
+}
+
+// Candidate is a destination the backend may rank
+type Candidate struct {
+	Selector string `json:"selector"`
+	File     string `json:"file"`
+	Heading  string `json:"heading"`
+	Path     string `json:"path"`
+	Level    int    `json:"level"`
+}
+
+// Request is sent to the backend
+type Request struct {
+	Task       string      `json:"task"`
+	Source     Source      `json:"source"`
+	Candidates []Candidate `json:"candidates"`
+	Limit      int         `json:"limit"`
+}
+
+// Suggestion is a ranked destination returned by the backend
+type Suggestion struct {
+	Selector string  `json:"selector"`
+	Score    float64 `json:"score"`
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// Response is returned by the backend
+type Response struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Backend produces suggestions for a request
+type Backend interface {
+	Name() string
+	Suggest(req *Request) (*Response, error)
+}
+
+// NewBackend returns the backend for a configured command or endpoint.
+// A command takes precedence when both are set.
+func NewBackend(command, endpoint string) (Backend, error) {
+	switch {
+	case strings.TrimSpace(command) != "":
+		return &commandBackend{command: command}, nil
+	case strings.TrimSpace(endpoint) != "":
+		return &httpBackend{endpoint: endpoint, client: netutil.NewClient(DefaultTimeout)}, nil
+	default:
+		return nil, fmt.Errorf("no suggestion backend configured (set suggest_command or suggest_endpoint in .jot/config.json)")
+	}
+}
+
+// Rank validates a backend response against the candidate list, dropping
+// unknown or duplicate selectors, and returns suggestions sorted by score.
+// Unknown selectors are returned separately so callers can report them.
+func Rank(resp *Response, candidates []Candidate, limit int) (ranked []Suggestion, unknown []string) {
+	known := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		known[candidate.Selector] = true
+	}
+
+	seen := make(map[string]bool)
+	for _, suggestion := range resp.Suggestions {
+		if !known[suggestion.Selector] {
+			unknown = append(unknown, suggestion.Selector)
+			continue
+		}
+		if seen[suggestion.Selector] {
+			continue
+		}
+		seen[suggestion.Selector] = true
+		ranked = append(ranked, suggestion)
+	}
+
+	// Stable so backends that return an ordered list without scores keep their order
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, unknown
+}
+
+// commandBackend runs a shell command with the request on stdin
+type commandBackend struct {
+	command string
+}
+
+func (b *commandBackend) Name() string { return b.command }
+
+func (b *commandBackend) Suggest(req *Request) (*Response, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", b.command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("suggestion command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return decodeResponse(stdout.Bytes())
+}
+
+// httpBackend posts the request to an HTTP endpoint
+type httpBackend struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (b *httpBackend) Name() string { return b.endpoint }
+
+func (b *httpBackend) Suggest(req *Request) (*Response, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, b.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid suggestion endpoint: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv(TokenEnvVar); token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("suggestion request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suggestion response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("suggestion endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return decodeResponse(body)
+}
+
+// decodeResponse parses a backend response
+func decodeResponse(data []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse suggestion response: %w", err)
+	}
+	return &resp, nil
+}
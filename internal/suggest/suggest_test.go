@@ -0,0 +1,90 @@
+package suggest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	candidates := []Candidate{
+		{Selector: "work.md#Projects"},
+		{Selector: "work.md#Projects/Frontend"},
+		{Selector: "notes.md#Reading"},
+	}
+	resp := &Response{Suggestions: []Suggestion{
+		{Selector: "notes.md#Reading", Score: 0.2},
+		{Selector: "missing.md#Nope", Score: 0.9},
+		{Selector: "work.md#Projects/Frontend", Score: 0.8},
+		{Selector: "work.md#Projects/Frontend", Score: 0.1},
+		{Selector: "work.md#Projects", Score: 0.5},
+	}}
+
+	ranked, unknown := Rank(resp, candidates, 2)
+
+	if len(unknown) != 1 || unknown[0] != "missing.md#Nope" {
+		t.Errorf("unknown = %v, want [missing.md#Nope]", unknown)
+	}
+	want := []string{"work.md#Projects/Frontend", "work.md#Projects"}
+	if len(ranked) != len(want) {
+		t.Fatalf("got %d suggestions, want %d: %+v", len(ranked), len(want), ranked)
+	}
+	for i, selector := range want {
+		if ranked[i].Selector != selector {
+			t.Errorf("ranked[%d] = %q, want %q", i, ranked[i].Selector, selector)
+		}
+	}
+}
+
+func TestCommandBackend(t *testing.T) {
+	backend, err := NewBackend(`cat >/dev/null; echo '{"suggestions":[{"selector":"a.md#B","score":1}]}'`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := backend.Suggest(&Request{Task: TaskRefile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Suggestions) != 1 || resp.Suggestions[0].Selector != "a.md#B" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestHTTPBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(Response{Suggestions: []Suggestion{
+			{Selector: req.Candidates[0].Selector, Score: 1, Reason: req.Source.Heading},
+		}})
+	}))
+	defer server.Close()
+
+	backend, err := NewBackend("", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := backend.Suggest(&Request{
+		Task:       TaskRefile,
+		Source:     Source{Heading: "Standup"},
+		Candidates: []Candidate{{Selector: "work.md#Meetings"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Suggestions) != 1 || resp.Suggestions[0].Reason != "Standup" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestNewBackendRequiresConfig(t *testing.T) {
+	if _, err := NewBackend("", ""); err == nil {
+		t.Error("expected an error when no backend is configured")
+	}
+}
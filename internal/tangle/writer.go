@@ -2,9 +2,10 @@ package tangle
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/johncoder/jot/internal/dryrun"
 )
 
 // Writer handles writing tangled code blocks to files
@@ -47,7 +48,7 @@ func (w *Writer) writeFile(filePath string, blocks []TangleBlock) error {
 	if w.createDirs {
 		dir := filepath.Dir(filePath)
 		if dir != "." && dir != "" {
-			if err := os.MkdirAll(dir, 0755); err != nil {
+			if err := dryrun.MkdirAll(dir, 0755); err != nil {
 				return fmt.Errorf("failed to create directory %s: %w", dir, err)
 			}
 		}
@@ -63,7 +64,7 @@ func (w *Writer) writeFile(filePath string, blocks []TangleBlock) error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(filePath, []byte(content.String()), 0644); err != nil {
+	if err := dryrun.WriteFile(filePath, []byte(content.String()), 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -0,0 +1,61 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// placeholderPattern matches "{{name}}" and "{{name+N}}" or "{{name-N}}"
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*(?:([+-])\s*(\d+))?\s*\}\}`)
+
+// ExpandPlaceholders replaces date placeholders in content, relative to now,
+// and "{{key}}" for each key in vars. Unlike shell commands in templates,
+// placeholders run nothing, so they are safe in any note.
+//
+//	{{date}}     2006-01-02      {{date+7}} is a week later
+//	{{weekday}}  Monday          offsets in days
+//	{{week}}     2006-W01        ISO week, offsets in weeks
+//	{{month}}    2006-01         offsets in months
+//	{{year}}     2006            offsets in years
+//	{{time}}     15:04
+//
+// Other placeholders, such as {{cursor}}, are left as they are.
+func ExpandPlaceholders(content string, now time.Time, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := placeholderPattern.FindStringSubmatch(match)
+		name, sign, amount := parts[1], parts[2], parts[3]
+
+		if value, ok := vars[name]; ok && sign == "" {
+			return value
+		}
+
+		offset := 0
+		if sign != "" {
+			offset, _ = strconv.Atoi(amount)
+			if sign == "-" {
+				offset = -offset
+			}
+		}
+
+		switch name {
+		case "date":
+			return now.AddDate(0, 0, offset).Format("2006-01-02")
+		case "weekday":
+			return now.AddDate(0, 0, offset).Format("Monday")
+		case "week":
+			year, week := now.AddDate(0, 0, 7*offset).ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		case "month":
+			return now.AddDate(0, offset, 0).Format("2006-01")
+		case "year":
+			return now.AddDate(offset, 0, 0).Format("2006")
+		case "time":
+			if sign == "" {
+				return now.Format("15:04")
+			}
+		}
+		return match
+	})
+}
@@ -0,0 +1,26 @@
+package template
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandPlaceholders(t *testing.T) {
+	now := time.Date(2025, 12, 29, 9, 5, 0, 0, time.UTC) // Monday of ISO week 2026-W01
+	vars := map[string]string{"owner": "sam"}
+
+	tests := map[string]string{
+		"# Week of {{date}}":              "# Week of 2025-12-29",
+		"Due {{date+4}} ({{weekday+4}})":  "Due 2026-01-02 (Friday)",
+		"{{ date - 1 }}":                  "2025-12-28",
+		"{{week}} / {{week-1}}":           "2026-W01 / 2025-W52",
+		"{{month+1}} {{year}}":            "2026-01 2025",
+		"at {{time}}":                     "at 09:05",
+		"{{owner}} {{cursor}} {{time+1}}": "sam {{cursor}} {{time+1}}",
+	}
+	for input, want := range tests {
+		if got := ExpandPlaceholders(input, now, vars); got != want {
+			t.Errorf("ExpandPlaceholders(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
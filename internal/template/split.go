@@ -0,0 +1,122 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SplitDestination is a named section of a split template and where its
+// content is captured to
+type SplitDestination struct {
+	Name        string
+	Destination string
+}
+
+// Section is a part of captured content and where it goes. The main section,
+// the content before any marker, has no name and no destination of its own.
+type Section struct {
+	Name        string
+	Destination string
+	Content     string
+}
+
+// splitMarker matches a line such as "<!-- split: actions -->" that starts a
+// section, or "<!-- split -->" that returns to the main destination
+var splitMarker = regexp.MustCompile(`^\s*<!--\s*split(?:\s*:\s*([\w.-]+))?\s*-->\s*$`)
+
+// parseSplit reads the split sections from a template's frontmatter, in the
+// order they are given:
+//
+//	split:
+//	  actions: todo.md#inbox
+//	  decisions: decisions.md#log
+func parseSplit(content string) ([]SplitDestination, error) {
+	if !strings.HasPrefix(content, "---\n") {
+		return nil, nil
+	}
+	parts := strings.SplitN(content, "\n---\n", 2)
+	if len(parts) < 2 {
+		return nil, nil
+	}
+
+	var frontmatter struct {
+		Split yaml.Node `yaml:"split"`
+	}
+	if err := yaml.Unmarshal([]byte(parts[0][4:]), &frontmatter); err != nil || frontmatter.Split.Kind == 0 {
+		return nil, nil
+	}
+	if frontmatter.Split.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("split must map section names to destinations")
+	}
+
+	var splits []SplitDestination
+	nodes := frontmatter.Split.Content
+	for i := 0; i+1 < len(nodes); i += 2 {
+		name, destination := nodes[i].Value, strings.TrimSpace(nodes[i+1].Value)
+		if nodes[i+1].Kind != yaml.ScalarNode || destination == "" {
+			return nil, fmt.Errorf("split section '%s' needs a destination, such as todo.md#inbox", name)
+		}
+		splits = append(splits, SplitDestination{Name: name, Destination: destination})
+	}
+	return splits, nil
+}
+
+// Split divides captured content into sections at split markers. The main
+// section comes first, then one per named section in frontmatter order;
+// sections a marker names more than once are joined. Markers are dropped,
+// and so are sections left empty. Shell commands in the destinations are
+// expanded, as for the main destination.
+func (m *Manager) Split(template *Template, content string) ([]Section, error) {
+	index := map[string]int{}
+	sections := []Section{{}}
+	for _, split := range template.Splits {
+		index[split.Name] = len(sections)
+		sections = append(sections, Section{Name: split.Name, Destination: split.Destination})
+	}
+
+	bodies := make([][]string, len(sections))
+	current := 0
+	for _, line := range strings.Split(content, "\n") {
+		match := splitMarker.FindStringSubmatch(line)
+		if match == nil {
+			bodies[current] = append(bodies[current], line)
+			continue
+		}
+		if match[1] == "" {
+			current = 0
+			continue
+		}
+		i, ok := index[match[1]]
+		if !ok {
+			return nil, fmt.Errorf("template '%s' has no split section '%s'", template.Name, match[1])
+		}
+		current = i
+		// Separate the parts of a section named more than once
+		if len(bodies[i]) > 0 {
+			bodies[i] = append(bodies[i], "")
+		}
+	}
+
+	var kept []Section
+	for i, section := range sections {
+		section.Content = strings.TrimSpace(strings.Join(bodies[i], "\n"))
+		if section.Content == "" {
+			continue
+		}
+		if strings.Contains(section.Destination, "$(") {
+			if !template.Approved {
+				return nil, fmt.Errorf("template '%s' requires approval before use. Run: jot template approve %s", template.Name, template.Name)
+			}
+			expanded, err := m.executeShellCommands(section.Destination)
+			if err != nil {
+				return nil, err
+			}
+			section.Destination = expanded
+		}
+		kept = append(kept, section)
+	}
+	return kept, nil
+}
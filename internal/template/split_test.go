@@ -0,0 +1,50 @@
+package template
+
+import "testing"
+
+func TestSplit(t *testing.T) {
+	source := "---\ndestination: work.md#Meetings\nsplit:\n  actions: todo.md#Inbox\n  decisions: decisions.md#Log\n---\n"
+	splits, err := parseSplit(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(splits) != 2 || splits[0].Name != "actions" || splits[1].Destination != "decisions.md#Log" {
+		t.Fatalf("got splits %+v", splits)
+	}
+
+	template := &Template{Name: "meeting", Splits: splits}
+	content := "## Meeting\n\n<!-- split: decisions -->\n- Use Go\n<!-- split: actions -->\n- [ ] Ship it\n<!--split-->\nWrap up\n<!-- split: decisions -->\n- Keep it small\n"
+	sections, err := (&Manager{}).Split(template, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Section{
+		{Content: "## Meeting\n\nWrap up"},
+		{Name: "actions", Destination: "todo.md#Inbox", Content: "- [ ] Ship it"},
+		{Name: "decisions", Destination: "decisions.md#Log", Content: "- Use Go\n\n- Keep it small"},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %d sections, want %d: %+v", len(sections), len(want), sections)
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("section %d: got %+v, want %+v", i, sections[i], want[i])
+		}
+	}
+
+	// Empty sections are dropped, the main one too
+	sections, err = (&Manager{}).Split(template, "<!-- split: actions -->\n- [ ] Ship it\n")
+	if err != nil || len(sections) != 1 || sections[0].Name != "actions" {
+		t.Errorf("got %+v, %v", sections, err)
+	}
+
+	if _, err := (&Manager{}).Split(template, "<!-- split: notes -->\nx\n"); err == nil {
+		t.Error("expected an error for an unknown section")
+	}
+	if _, err := parseSplit("---\nsplit: todo.md\n---\n"); err == nil {
+		t.Error("expected an error for a split that is not a map")
+	}
+	if _, err := parseSplit("---\nsplit:\n  actions:\n---\n"); err == nil {
+		t.Error("expected an error for a section without a destination")
+	}
+}
@@ -9,9 +9,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/johncoder/jot/internal/cmdutil"
+	"github.com/johncoder/jot/internal/dryrun"
 	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/secrets"
+	"github.com/johncoder/jot/internal/storage"
 	"github.com/johncoder/jot/internal/workspace"
 	"gopkg.in/yaml.v3"
 )
@@ -25,7 +30,9 @@ type Template struct {
 	Hash            string
 	Approved        bool
 	DestinationFile string
-	RefileMode      string // "append" (default) or "prepend"
+	RefileMode      string             // "append" (default) or "prepend"
+	FileTemplate    string             // Template for creating a missing destination file
+	Splits          []SplitDestination // Sections captured to destinations of their own
 }
 
 // Manager handles template operations
@@ -43,7 +50,7 @@ func (m *Manager) List() ([]Template, error) {
 	templatesDir := filepath.Join(m.ws.JotDir, "templates")
 
 	// Create templates directory if it doesn't exist
-	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+	if err := dryrun.MkdirAll(templatesDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create templates directory: %w", err)
 	}
 
@@ -85,7 +92,7 @@ func (m *Manager) Get(name string) (*Template, error) {
 	templatePath := filepath.Join(m.ws.JotDir, "templates", name+".md")
 
 	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("template '%s' not found", name)
+		return nil, fmt.Errorf("template '%s' %w", name, cmdutil.ErrNotFound)
 	}
 
 	content, err := cmdutil.ReadFileContent(templatePath)
@@ -112,6 +119,11 @@ func (m *Manager) Get(name string) (*Template, error) {
 		refileMode = "append"
 	}
 
+	splits, err := parseSplit(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("template '%s': %w", name, err)
+	}
+
 	return &Template{
 		Name:            name,
 		Path:            templatePath,
@@ -120,6 +132,8 @@ func (m *Manager) Get(name string) (*Template, error) {
 		Approved:        approved,
 		DestinationFile: destinationField, // This can now be either a file or selector
 		RefileMode:      refileMode,
+		FileTemplate:    metadata["file_template"],
+		Splits:          splits,
 	}, nil
 }
 
@@ -128,7 +142,7 @@ func (m *Manager) Create(name, content string) error {
 	templatesDir := filepath.Join(m.ws.JotDir, "templates")
 
 	// Create templates directory if it doesn't exist
-	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+	if err := dryrun.MkdirAll(templatesDir, 0755); err != nil {
 		return fmt.Errorf("failed to create templates directory: %w", err)
 	}
 
@@ -148,7 +162,7 @@ func (m *Manager) Create(name, content string) error {
 	// Update the metadata to include the default destination_file
 	metadata["destination_file"] = destinationFile
 
-	return os.WriteFile(templatePath, []byte(content), 0644)
+	return dryrun.WriteFile(templatePath, []byte(content), 0644)
 }
 
 // Approve grants permission for a template to execute shell commands
@@ -188,7 +202,31 @@ func (m *Manager) Approve(name string) error {
 	}
 
 	content := strings.Join(lines, "\n") + "\n"
-	return os.WriteFile(permissionsFile, []byte(content), 0644)
+	if err := dryrun.WriteFile(permissionsFile, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	// Keep what was approved, so a later change to the template can be shown
+	copyPath := m.approvedCopyPath(name)
+	if err := dryrun.MkdirAll(filepath.Dir(copyPath), 0755); err != nil {
+		return err
+	}
+	return dryrun.WriteFile(copyPath, []byte(template.Content), 0644)
+}
+
+// approvedCopyPath is where Approve keeps the content it approved
+func (m *Manager) approvedCopyPath(name string) string {
+	return filepath.Join(m.ws.JotDir, "template_approvals", name+".md")
+}
+
+// ApprovedContent returns the template's content when it was last approved,
+// and false for templates approved before copies were kept
+func (m *Manager) ApprovedContent(name string) (string, bool) {
+	content, err := os.ReadFile(m.approvedCopyPath(name))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
 }
 
 // Render processes a template with shell command execution and content injection
@@ -214,6 +252,9 @@ func (m *Manager) RenderWithOptions(template *Template, appendContent string, in
 	if err != nil {
 		return "", fmt.Errorf("failed to execute shell commands in template: %w", err)
 	}
+	if secrets.HasRef(content) {
+		return "", fmt.Errorf("template '%s' refers to a secret outside a shell command; secrets are only passed to $(...) commands, so they never end up in notes", template.Name)
+	}
 
 	// Append content if provided
 	if appendContent != "" {
@@ -223,18 +264,51 @@ func (m *Manager) RenderWithOptions(template *Template, appendContent string, in
 	return content, nil
 }
 
-// executeShellCommands finds and executes shell commands in the template
+// shellCommandPattern matches a shell command in a template: $(command)
+var shellCommandPattern = regexp.MustCompile(`\$\(([^)]+)\)`)
+
+// ShellCommands returns the shell commands rendering content would run, in
+// order
+func ShellCommands(content string) []string {
+	var commands []string
+	for _, match := range shellCommandPattern.FindAllStringSubmatch(content, -1) {
+		commands = append(commands, match[1])
+	}
+	return commands
+}
+
+// executeShellCommands finds and executes shell commands in the template.
+// Secrets a command refers to, as {{secret NAME}}, are passed to it in
+// environment variables.
 func (m *Manager) executeShellCommands(content string) (string, error) {
-	// Match shell command syntax: $(command)
-	re := regexp.MustCompile(`\$\(([^)]+)\)`)
+	if shellCommandPattern.MatchString(content) {
+		if err := safemode.Check("shell commands in templates"); err != nil {
+			return "", err
+		}
+	}
 
-	result := re.ReplaceAllStringFunc(content, func(match string) string {
+	var secretErr error
+	result := shellCommandPattern.ReplaceAllStringFunc(content, func(match string) string {
 		// Extract command (remove $( and ))
 		command := match[2 : len(match)-1]
 
+		var env []string
+		if secrets.HasRef(command) {
+			var err error
+			if command, env, err = secrets.ForCommand(command); err != nil {
+				if secretErr == nil {
+					secretErr = err
+				}
+				return match
+			}
+		}
+
 		// Execute command
 		cmd := exec.Command("sh", "-c", command)
 		cmd.Dir = m.ws.Root
+		if env != nil {
+			cmd.Env = append(os.Environ(), env...)
+		}
 
 		output, err := cmd.Output()
 		if err != nil {
@@ -244,10 +318,35 @@ func (m *Manager) executeShellCommands(content string) (string, error) {
 
 		return strings.TrimSpace(string(output))
 	})
+	if secretErr != nil {
+		return "", secretErr
+	}
 
 	return result, nil
 }
 
+// ExpandDestination returns the template's destination with shell commands,
+// such as $(date +%F), replaced by their output, so captures can be filed
+// by date
+func (m *Manager) ExpandDestination(template *Template) (string, error) {
+	destination := template.DestinationFile
+	if !strings.Contains(destination, "$(") {
+		return destination, nil
+	}
+	if !template.Approved {
+		return "", fmt.Errorf("template '%s' requires approval before use. Run: jot template approve %s", template.Name, template.Name)
+	}
+
+	expanded, err := m.executeShellCommands(destination)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(expanded, "$(") {
+		return "", fmt.Errorf("failed to expand destination '%s' of template '%s'", destination, template.Name)
+	}
+	return expanded, nil
+}
+
 // isApproved checks if a template hash is approved
 func (m *Manager) isApproved(hash string) bool {
 	permissionsFile := filepath.Join(m.ws.JotDir, "template_permissions")
@@ -268,6 +367,82 @@ func (m *Manager) isApproved(hash string) bool {
 	return false
 }
 
+// ApprovedHashes returns the hashes in the permissions file, in file order
+func (m *Manager) ApprovedHashes() ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(m.ws.JotDir, "template_permissions"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			hashes = append(hashes, line)
+		}
+	}
+	return hashes, nil
+}
+
+// StalePermissions returns approved hashes that match no current template:
+// approvals of templates that have since been edited or deleted
+func (m *Manager) StalePermissions() ([]string, error) {
+	hashes, err := m.ApprovedHashes()
+	if err != nil || len(hashes) == 0 {
+		return nil, err
+	}
+	templates, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		current[t.Hash] = true
+	}
+	var stale []string
+	for _, hash := range hashes {
+		if !current[hash] {
+			stale = append(stale, hash)
+		}
+	}
+	return stale, nil
+}
+
+// PrunePermissions removes stale approvals from the permissions file and
+// returns how many it removed
+func (m *Manager) PrunePermissions() (int, error) {
+	stale, err := m.StalePermissions()
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+	hashes, err := m.ApprovedHashes()
+	if err != nil {
+		return 0, err
+	}
+
+	remove := make(map[string]bool, len(stale))
+	for _, hash := range stale {
+		remove[hash] = true
+	}
+	lines := []string{"# Template permissions - SHA256 hashes of approved templates"}
+	for _, hash := range hashes {
+		if !remove[hash] {
+			lines = append(lines, hash)
+		}
+	}
+
+	content := strings.Join(lines, "\n") + "\n"
+	if err := dryrun.WriteFile(filepath.Join(m.ws.JotDir, "template_permissions"), []byte(content), 0644); err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
 // calculateHash computes SHA256 hash of template content
 func calculateHash(content string) string {
 	hash := sha256.Sum256([]byte(content))
@@ -382,7 +557,7 @@ func (m *Manager) ValidateDestination(destination string) error {
 		filePath = filepath.Join(m.ws.Root, destInfo.File)
 	}
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	if _, err := storage.Stat(filePath); os.IsNotExist(err) {
 		return fmt.Errorf("destination file does not exist: %s", destInfo.File)
 	}
 
@@ -410,3 +585,94 @@ func stripFrontmatter(content string) string {
 	// No frontmatter found, return original content
 	return content
 }
+
+// captureOnlyKeys are frontmatter keys that control capture and refile, and
+// have no meaning in a file created from a template
+var captureOnlyKeys = regexp.MustCompile(`^(destination|destination_file|refile_mode|file_template|split)\s*:`)
+
+// RenderFile renders a template as the initial content of a new file.
+// "{{title}}" is replaced with title, cursor markers and capture-only
+// frontmatter keys are removed; other frontmatter is kept.
+func (m *Manager) RenderFile(template *Template, title string) (string, error) {
+	content, err := m.RenderWithOptions(template, "", true)
+	if err != nil {
+		return "", err
+	}
+	content = strings.ReplaceAll(content, "{{title}}", title)
+	content = strings.ReplaceAll(content, CursorMarker, "")
+
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return content, nil
+	}
+
+	for end := 1; end < len(lines); end++ {
+		if strings.TrimSpace(lines[end]) != "---" {
+			continue
+		}
+
+		// Indented lines under a removed key, such as split's sections, go too
+		var kept []string
+		removing := false
+		for _, line := range lines[1:end] {
+			if removing && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+				continue
+			}
+			removing = captureOnlyKeys.MatchString(line)
+			if !removing {
+				kept = append(kept, line)
+			}
+		}
+
+		body := strings.TrimLeft(strings.Join(lines[end+1:], "\n"), "\n")
+		if len(kept) == 0 || strings.TrimSpace(strings.Join(kept, "")) == "" {
+			return body, nil
+		}
+		return "---\n" + strings.Join(kept, "\n") + "\n---\n\n" + body, nil
+	}
+	return content, nil
+}
+
+// CursorMarker marks where an editor should place the cursor in captured content
+const CursorMarker = "{{cursor}}"
+
+// Position is a 1-based line and column; columns count characters, not bytes
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ExtractCursor removes every cursor marker from content and returns the
+// position of the first one, or nil when there is none
+func ExtractCursor(content string) (string, *Position) {
+	index := strings.Index(content, CursorMarker)
+	if index < 0 {
+		return content, nil
+	}
+
+	before := content[:index]
+	position := &Position{Line: strings.Count(before, "\n") + 1}
+	lineStart := strings.LastIndex(before, "\n") + 1
+	position.Column = utf8.RuneCountInString(before[lineStart:]) + 1
+
+	return strings.ReplaceAll(content, CursorMarker, ""), position
+}
+
+// OffsetPosition converts a position within content appended to prefix into a
+// position within the combined text
+func OffsetPosition(prefix []byte, position *Position) *Position {
+	if position == nil {
+		return nil
+	}
+
+	text := string(prefix)
+	result := &Position{
+		Line:   strings.Count(text, "\n") + position.Line,
+		Column: position.Column,
+	}
+	if position.Line == 1 {
+		lineStart := strings.LastIndex(text, "\n") + 1
+		result.Column += utf8.RuneCountInString(text[lineStart:])
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johncoder/jot/internal/safemode"
+	"github.com/johncoder/jot/internal/secrets"
+	"github.com/johncoder/jot/internal/workspace"
+)
+
+func TestPrunePermissions(t *testing.T) {
+	dir := t.TempDir()
+	ws := &workspace.Workspace{Root: dir, JotDir: filepath.Join(dir, ".jot")}
+	templates := filepath.Join(ws.JotDir, "templates")
+	if err := os.MkdirAll(templates, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(ws)
+
+	for _, name := range []string{"daily", "meeting", "scratch"} {
+		if err := os.WriteFile(filepath.Join(templates, name+".md"), []byte("# "+name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Approve(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(templates, "daily.md"), []byte("# daily $(date)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(templates, "scratch.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := m.StalePermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 2 {
+		t.Fatalf("got %d stale approvals, want 2", len(stale))
+	}
+
+	removed, err := m.PrunePermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("pruned %d approvals, want 2", removed)
+	}
+	if hashes, _ := m.ApprovedHashes(); len(hashes) != 1 || hashes[0] != calculateHash("# meeting\n") {
+		t.Errorf("approvals after pruning = %v, want only meeting's", hashes)
+	}
+	if meeting, err := m.Get("meeting"); err != nil || !meeting.Approved {
+		t.Errorf("meeting lost its approval")
+	}
+}
+
+func TestSecretsInShellCommands(t *testing.T) {
+	secrets.Use(testKeyring{"api_token": "s3cret"})
+	defer secrets.Use(nil)
+
+	m := NewManager(&workspace.Workspace{Root: t.TempDir()})
+	got, err := m.executeShellCommands("token: $(printf %s {{secret api_token}} | wc -c)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "token: 6" {
+		t.Errorf("got %q, want the secret's length", got)
+	}
+
+	if _, err := m.executeShellCommands("$(echo {{secret missing}})"); err == nil {
+		t.Error("a missing secret did not fail the render")
+	}
+
+	template := &Template{Name: "leak", Content: "token: {{secret api_token}}\n", Approved: true}
+	if _, err := m.RenderWithOptions(template, "", false); err == nil || !strings.Contains(err.Error(), "outside a shell command") {
+		t.Errorf("a secret outside a command: got %v", err)
+	}
+}
+
+func TestSafeModeRefusesShellCommands(t *testing.T) {
+	safemode.Configure(true, "testing")
+	defer safemode.Configure(false, "")
+
+	m := NewManager(&workspace.Workspace{Root: t.TempDir()})
+	template := &Template{Name: "daily", Content: "# $(date +%Y)\n", Approved: true}
+	if _, err := m.RenderWithOptions(template, "", false); !errors.Is(err, safemode.ErrSafeMode) {
+		t.Errorf("rendering shell commands in safe mode: got %v", err)
+	}
+	template.Content = "# {{date}}\n"
+	if _, err := m.RenderWithOptions(template, "", false); err != nil {
+		t.Errorf("a template without shell commands failed in safe mode: %v", err)
+	}
+}
+
+// testKeyring keeps secrets in a map
+type testKeyring map[string]string
+
+func (k testKeyring) Set(name, value string) error { k[name] = value; return nil }
+func (k testKeyring) Delete(name string) error     { delete(k, name); return nil }
+func (k testKeyring) Get(name string) (string, error) {
+	if value, ok := k[name]; ok {
+		return value, nil
+	}
+	return "", secrets.ErrNotFound
+}
+
+func TestApprovedContent(t *testing.T) {
+	dir := t.TempDir()
+	ws := &workspace.Workspace{Root: dir, JotDir: filepath.Join(dir, ".jot")}
+	templates := filepath.Join(ws.JotDir, "templates")
+	if err := os.MkdirAll(templates, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(ws)
+
+	original := "---\ndestination: log/$(date +%F).md\n---\n# Standup\n"
+	if err := os.WriteFile(filepath.Join(templates, "standup.md"), []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, kept := m.ApprovedContent("standup"); kept {
+		t.Error("unapproved template has approved content")
+	}
+	if err := m.Approve("standup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templates, "standup.md"), []byte(original+"$(whoami)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	approved, kept := m.ApprovedContent("standup")
+	if !kept || approved != original {
+		t.Errorf("approved content = %q, %v, want the content approved", approved, kept)
+	}
+	current, err := m.Get("standup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Approved {
+		t.Error("edited template is still approved")
+	}
+	commands := ShellCommands(current.Content)
+	if len(commands) != 2 || commands[0] != "date +%F" || commands[1] != "whoami" {
+		t.Errorf("ShellCommands() = %q", commands)
+	}
+}
+
+func TestExtractCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		pos     *Position
+	}{
+		{"no marker", "## Note\n\ntext\n", "## Note\n\ntext\n", nil},
+		{"marker", "## Note\n\n{{cursor}}\n", "## Note\n\n\n", &Position{Line: 3, Column: 1}},
+		{"first of several markers", "## {{cursor}}Note\n{{cursor}}\n", "## Note\n\n", &Position{Line: 1, Column: 4}},
+		{"multi-byte text before the marker", "## Café — ünïcode {{cursor}}\n", "## Café — ünïcode \n", &Position{Line: 1, Column: 19}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, pos := ExtractCursor(tt.content)
+			if got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+			if (pos == nil) != (tt.pos == nil) || pos != nil && *pos != *tt.pos {
+				t.Errorf("position = %+v, want %+v", pos, tt.pos)
+			}
+		})
+	}
+}
+
+func TestOffsetPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		position *Position
+		want     *Position
+	}{
+		{"no position", "# Inbox\n", nil, nil},
+		{"after a full line", "# Inbox\n\n", &Position{Line: 2, Column: 3}, &Position{Line: 4, Column: 3}},
+		{"continuing the last line", "# Inbox\nnoté: ", &Position{Line: 1, Column: 2}, &Position{Line: 2, Column: 8}},
+		{"empty prefix", "", &Position{Line: 1, Column: 5}, &Position{Line: 1, Column: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OffsetPosition([]byte(tt.prefix), tt.position)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("OffsetPosition = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
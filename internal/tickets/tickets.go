@@ -0,0 +1,245 @@
+// Package tickets turns ticket references such as ABC-123 into links to the
+// tracker that owns them, titled from Jira or Linear when a lookup is set up.
+package tickets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/markdown"
+	"github.com/johncoder/jot/internal/netutil"
+)
+
+// Title lookups
+const (
+	Jira   = "jira"
+	Linear = "linear"
+)
+
+// DefaultLinearAPI is Linear's GraphQL endpoint
+const DefaultLinearAPI = "https://api.linear.app/graphql"
+
+// DefaultTimeout bounds each title lookup
+const DefaultTimeout = 15 * time.Second
+
+// Rule links the tickets of some projects to one tracker
+type Rule struct {
+	Prefixes []string // Project keys, such as "ABC" for ABC-123
+	URL      string   // Link template; {{id}} is replaced with the ticket ID
+	Lookup   string   // "jira", "linear", or empty for links without titles
+	APIURL   string   // Jira's base URL, or Linear's GraphQL endpoint
+	Token    string   // For Jira, "email:token" for basic auth or a bearer token
+}
+
+// Link is a ticket reference that was expanded
+type Link struct {
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	Error string `json:"error,omitempty"` // Why the title lookup failed
+}
+
+// Resolver expands ticket references using a set of rules, looking each
+// ticket's title up once
+type Resolver struct {
+	rules   []Rule
+	pattern *regexp.Regexp
+	byKey   map[string]int
+	links   map[string]Link
+	HTTP    *http.Client
+}
+
+// prefixPattern is what a project key may look like
+var prefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// NewResolver checks rules and returns a resolver for them
+func NewResolver(rules []Rule) (*Resolver, error) {
+	r := &Resolver{
+		rules: rules,
+		byKey: map[string]int{},
+		links: map[string]Link{},
+		HTTP:  netutil.NewClient(DefaultTimeout),
+	}
+	var keys []string
+	for i, rule := range rules {
+		if len(rule.Prefixes) == 0 {
+			return nil, fmt.Errorf("ticket rule %d has no prefixes", i+1)
+		}
+		if !strings.Contains(rule.URL, "{{id}}") {
+			return nil, fmt.Errorf("ticket rule %d: url must contain {{id}}", i+1)
+		}
+		switch rule.Lookup {
+		case "", Linear:
+		case Jira:
+			if rule.APIURL == "" {
+				return nil, fmt.Errorf("ticket rule %d: jira lookups need api_url", i+1)
+			}
+		default:
+			return nil, fmt.Errorf("ticket rule %d: unknown lookup %q (use jira or linear)", i+1, rule.Lookup)
+		}
+		for _, prefix := range rule.Prefixes {
+			if !prefixPattern.MatchString(prefix) {
+				return nil, fmt.Errorf("ticket rule %d: %q is not a project key like ABC", i+1, prefix)
+			}
+			if _, ok := r.byKey[prefix]; !ok {
+				r.byKey[prefix] = i
+				keys = append(keys, prefix)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no ticket rules are configured")
+	}
+	// Longer keys first, so OPSX-1 is not read as OPS
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	r.pattern = regexp.MustCompile(`\b(?:` + strings.Join(keys, "|") + `)-[0-9]+\b`)
+	return r, nil
+}
+
+// Expand rewrites the ticket references in content's prose as links,
+// "[ABC-123: Title](url)", or "[ABC-123](url)" when the title is unknown.
+// References already inside links, code and headings are left alone. It
+// returns the new content and the links made, in order.
+func (r *Resolver) Expand(content []byte) ([]byte, []Link) {
+	var made []Link
+	out, _ := markdown.RewriteText(content, r.pattern, func(id string) (string, bool) {
+		link := r.Resolve(id)
+		made = append(made, link)
+		return Format(link), true
+	})
+	return out, made
+}
+
+// Resolve returns the link for a ticket ID, looking its title up the first
+// time it is seen
+func (r *Resolver) Resolve(id string) Link {
+	if link, ok := r.links[id]; ok {
+		return link
+	}
+	prefix, _, _ := strings.Cut(id, "-")
+	rule := r.rules[r.byKey[prefix]]
+	link := Link{ID: id, URL: strings.ReplaceAll(rule.URL, "{{id}}", url.PathEscape(id))}
+
+	var err error
+	switch rule.Lookup {
+	case Jira:
+		link.Title, err = r.jiraTitle(rule, id)
+	case Linear:
+		link.Title, err = r.linearTitle(rule, id)
+	}
+	if err != nil {
+		link.Error = err.Error()
+	}
+	r.links[id] = link
+	return link
+}
+
+// Format writes a link as markdown
+func Format(link Link) string {
+	text := link.ID
+	if link.Title != "" {
+		text += ": " + escapeLinkText(link.Title)
+	}
+	return fmt.Sprintf("[%s](%s)", text, link.URL)
+}
+
+// escapeLinkText keeps a title from closing the link text early
+func escapeLinkText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(text)
+}
+
+func (r *Resolver) jiraTitle(rule Rule, id string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(rule.APIURL, "/")+"/rest/api/2/issue/"+url.PathEscape(id)+"?fields=summary", nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid jira api_url: %w", err)
+	}
+	if user, token, ok := strings.Cut(rule.Token, ":"); ok {
+		req.SetBasicAuth(user, token)
+	} else if rule.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+rule.Token)
+	}
+
+	var resp struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := r.do(req, "jira", &resp); err != nil {
+		return "", err
+	}
+	return resp.Fields.Summary, nil
+}
+
+func (r *Resolver) linearTitle(rule Rule, id string) (string, error) {
+	apiURL := rule.APIURL
+	if apiURL == "" {
+		apiURL = DefaultLinearAPI
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     "query($id: String!) { issue(id: $id) { title } }",
+		"variables": map[string]string{"id": id},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("invalid linear api_url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if rule.Token != "" {
+		req.Header.Set("Authorization", rule.Token)
+	}
+
+	var resp struct {
+		Data struct {
+			Issue *struct {
+				Title string `json:"title"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := r.do(req, "linear", &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Errors) > 0 {
+		return "", fmt.Errorf("linear: %s", resp.Errors[0].Message)
+	}
+	if resp.Data.Issue == nil {
+		return "", fmt.Errorf("linear: %s not found", id)
+	}
+	return resp.Data.Issue.Title, nil
+}
+
+// do sends a lookup request and decodes its JSON response into out
+func (r *Resolver) do(req *http.Request, tracker string, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := r.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", tracker, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", tracker, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", tracker, resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", tracker, err)
+	}
+	return nil
+}
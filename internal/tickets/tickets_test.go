@@ -0,0 +1,88 @@
+package tickets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExpandJira(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/rest/api/2/issue/ABC-12" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			t.Errorf("basic auth = %q %q", user, token)
+		}
+		w.Write([]byte(`{"fields": {"summary": "Fix [login] crash"}}`))
+	}))
+	defer server.Close()
+
+	resolver, err := NewResolver([]Rule{
+		{Prefixes: []string{"ABC"}, URL: "https://jira.example.com/browse/{{id}}", Lookup: Jira, APIURL: server.URL, Token: "me@example.com:secret"},
+		{Prefixes: []string{"OPS"}, URL: "https://ops.example.com/{{id}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "Blocked on ABC-12 and OPS-3.\nStill ABC-12, already [ABC-12](https://jira.example.com/browse/ABC-12).\n"
+	got, links := resolver.Expand([]byte(content))
+	want := "Blocked on [ABC-12: Fix \\[login\\] crash](https://jira.example.com/browse/ABC-12) and [OPS-3](https://ops.example.com/OPS-3).\n" +
+		"Still [ABC-12: Fix \\[login\\] crash](https://jira.example.com/browse/ABC-12), already [ABC-12](https://jira.example.com/browse/ABC-12).\n"
+	if string(got) != want {
+		t.Errorf("Expand() =\n%s\nwant\n%s", got, want)
+	}
+	if len(links) != 3 || links[1].ID != "OPS-3" || links[1].Title != "" {
+		t.Errorf("links = %+v", links)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestLinearLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Variables["id"] == "ENG-2" {
+			w.Write([]byte(`{"data": {"issue": null}, "errors": [{"message": "Entity not found"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"issue": {"title": "Ship it"}}}`))
+	}))
+	defer server.Close()
+
+	resolver, err := NewResolver([]Rule{{Prefixes: []string{"ENG"}, URL: "https://linear.app/acme/issue/{{id}}", Lookup: Linear, APIURL: server.URL, Token: "lin_api_key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link := resolver.Resolve("ENG-1"); link.Title != "Ship it" || link.Error != "" {
+		t.Errorf("ENG-1 = %+v", link)
+	}
+	if link := resolver.Resolve("ENG-2"); link.Title != "" || link.Error == "" || Format(link) != "[ENG-2](https://linear.app/acme/issue/ENG-2)" {
+		t.Errorf("ENG-2 = %+v", link)
+	}
+}
+
+func TestNewResolverChecksRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{URL: "https://x/{{id}}"},
+		{Prefixes: []string{"ABC"}, URL: "https://x/"},
+		{Prefixes: []string{"abc"}, URL: "https://x/{{id}}"},
+		{Prefixes: []string{"ABC"}, URL: "https://x/{{id}}", Lookup: Jira},
+		{Prefixes: []string{"ABC"}, URL: "https://x/{{id}}", Lookup: "trello"},
+	} {
+		if _, err := NewResolver([]Rule{rule}); err == nil {
+			t.Errorf("NewResolver(%+v) succeeded", rule)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package workspace
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AliasPrefix marks a selector argument as an alias reference ("@meetings")
+const AliasPrefix = "@"
+
+// aliasNamePattern restricts alias names to characters that are safe on a command line
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateAliasName checks that an alias name can be referenced as "@name"
+func ValidateAliasName(name string) error {
+	if !aliasNamePattern.MatchString(name) {
+		return fmt.Errorf("alias names must start with a letter or digit and contain only letters, digits, '-' and '_'")
+	}
+	return nil
+}
+
+// Aliases returns the configured aliases
+func (ws *Workspace) Aliases() map[string]string {
+	if ws == nil || ws.Config == nil || ws.Config.Aliases == nil {
+		return map[string]string{}
+	}
+	return ws.Config.Aliases
+}
+
+// AliasNames returns the configured alias names in sorted order
+func (ws *Workspace) AliasNames() []string {
+	aliases := ws.Aliases()
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetAlias adds or replaces an alias and saves the workspace configuration
+func (ws *Workspace) SetAlias(name, selector string) error {
+	if err := ValidateAliasName(name); err != nil {
+		return err
+	}
+	if ws.Config == nil {
+		ws.Config = &WorkspaceConfig{}
+	}
+	if ws.Config.Aliases == nil {
+		ws.Config.Aliases = make(map[string]string)
+	}
+	ws.Config.Aliases[name] = selector
+	return ws.SaveWorkspaceConfig()
+}
+
+// RemoveAlias deletes an alias and saves the workspace configuration
+func (ws *Workspace) RemoveAlias(name string) error {
+	if _, exists := ws.Aliases()[name]; !exists {
+		return fmt.Errorf("alias %q does not exist", name)
+	}
+	delete(ws.Config.Aliases, name)
+	return ws.SaveWorkspaceConfig()
+}
+
+// ExpandAlias replaces an "@name" or "@name/more/path" selector with the
+// selector the alias refers to. Extra path segments are appended to the
+// alias's heading path. Selectors that are not aliases are returned unchanged.
+func (ws *Workspace) ExpandAlias(selector string) (string, bool) {
+	if !strings.HasPrefix(selector, AliasPrefix) {
+		return selector, false
+	}
+
+	name, rest, _ := strings.Cut(strings.TrimPrefix(selector, AliasPrefix), "/")
+	target, exists := ws.Aliases()[name]
+	if !exists {
+		return selector, false
+	}
+	if rest == "" {
+		return target, true
+	}
+
+	if strings.Contains(target, "#") {
+		return strings.TrimSuffix(target, "/") + "/" + rest, true
+	}
+	return target + "#" + rest, true
+}
@@ -0,0 +1,45 @@
+package workspace
+
+import "testing"
+
+func TestExpandAlias(t *testing.T) {
+	ws := &Workspace{Config: &WorkspaceConfig{Aliases: map[string]string{
+		"meet":  "inbox.md#meetings",
+		"work":  "work.md",
+		"slash": "notes.md#ideas/",
+	}}}
+
+	tests := []struct {
+		selector string
+		want     string
+		expanded bool
+	}{
+		{"@meet", "inbox.md#meetings", true},
+		{"@meet/standup", "inbox.md#meetings/standup", true},
+		{"@work", "work.md", true},
+		{"@work/projects", "work.md#projects", true},
+		{"@slash/new", "notes.md#ideas/new", true},
+		{"@missing", "@missing", false},
+		{"inbox.md#meet", "inbox.md#meet", false},
+	}
+
+	for _, tt := range tests {
+		got, expanded := ws.ExpandAlias(tt.selector)
+		if got != tt.want || expanded != tt.expanded {
+			t.Errorf("ExpandAlias(%q) = %q, %v; want %q, %v", tt.selector, got, expanded, tt.want, tt.expanded)
+		}
+	}
+}
+
+func TestValidateAliasName(t *testing.T) {
+	for _, name := range []string{"meet", "inbox-meet", "q3_goals", "2025"} {
+		if err := ValidateAliasName(name); err != nil {
+			t.Errorf("ValidateAliasName(%q) returned error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "@meet", "a/b", "-x", "has space", "a#b"} {
+		if err := ValidateAliasName(name); err == nil {
+			t.Errorf("ValidateAliasName(%q) expected error", name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// bookmarksFile holds named bookmarks inside the .jot directory
+const bookmarksFile = "bookmarks.json"
+
+// Bookmark is a named selector kept for reading and navigation
+type Bookmark struct {
+	Name     string    `json:"name"`
+	Selector string    `json:"selector"`
+	Created  time.Time `json:"created"`
+}
+
+// ValidateBookmarkName checks that a bookmark name is safe to type on a
+// command line. Names follow the same rules as alias names.
+func ValidateBookmarkName(name string) error {
+	if !aliasNamePattern.MatchString(name) {
+		return fmt.Errorf("bookmark names must start with a letter or digit and contain only letters, digits, '-' and '_'")
+	}
+	return nil
+}
+
+// BookmarkName derives a bookmark name from a selector: the last heading
+// segment, or the file name without its extension
+func BookmarkName(selector string) string {
+	file, headings, _ := strings.Cut(selector, "#")
+	source := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if headings = strings.Trim(headings, "/"); headings != "" {
+		source = headings[strings.LastIndex(headings, "/")+1:]
+	}
+
+	var name strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(source) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			if dash && name.Len() > 0 {
+				name.WriteByte('-')
+			}
+			name.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return name.String()
+}
+
+// LoadBookmarks returns the workspace's bookmarks sorted by name
+func (ws *Workspace) LoadBookmarks() ([]Bookmark, error) {
+	data, err := os.ReadFile(filepath.Join(ws.JotDir, bookmarksFile))
+	if os.IsNotExist(err) {
+		return []Bookmark{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+
+	bookmarks := []Bookmark{}
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", bookmarksFile, err)
+	}
+	sort.Slice(bookmarks, func(i, j int) bool { return bookmarks[i].Name < bookmarks[j].Name })
+	return bookmarks, nil
+}
+
+// FindBookmark returns the bookmark with the given name
+func (ws *Workspace) FindBookmark(name string) (Bookmark, error) {
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		return Bookmark{}, err
+	}
+	for _, bookmark := range bookmarks {
+		if bookmark.Name == name {
+			return bookmark, nil
+		}
+	}
+	return Bookmark{}, fmt.Errorf("bookmark %q does not exist", name)
+}
+
+// SetBookmark adds or replaces a bookmark. It returns the new bookmark and
+// the selector the name pointed to before, if any.
+func (ws *Workspace) SetBookmark(name, selector string) (Bookmark, string, error) {
+	if err := ValidateBookmarkName(name); err != nil {
+		return Bookmark{}, "", err
+	}
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		return Bookmark{}, "", err
+	}
+
+	previous := ""
+	kept := bookmarks[:0]
+	for _, bookmark := range bookmarks {
+		if bookmark.Name == name {
+			previous = bookmark.Selector
+			continue
+		}
+		kept = append(kept, bookmark)
+	}
+	bookmark := Bookmark{Name: name, Selector: selector, Created: time.Now()}
+	return bookmark, previous, ws.saveBookmarks(append(kept, bookmark))
+}
+
+// RemoveBookmark deletes a bookmark and returns it
+func (ws *Workspace) RemoveBookmark(name string) (Bookmark, error) {
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		return Bookmark{}, err
+	}
+	for i, bookmark := range bookmarks {
+		if bookmark.Name == name {
+			return bookmark, ws.saveBookmarks(append(bookmarks[:i], bookmarks[i+1:]...))
+		}
+	}
+	return Bookmark{}, fmt.Errorf("bookmark %q does not exist", name)
+}
+
+func (ws *Workspace) saveBookmarks(bookmarks []Bookmark) error {
+	sort.Slice(bookmarks, func(i, j int) bool { return bookmarks[i].Name < bookmarks[j].Name })
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+	if err := dryrun.WriteFile(filepath.Join(ws.JotDir, bookmarksFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package workspace
+
+import "testing"
+
+func TestBookmarkName(t *testing.T) {
+	tests := map[string]string{
+		"work.md":                           "work",
+		"lib/Reading List.md":               "reading-list",
+		"work.md#projects/Frontend":         "frontend",
+		"work.md#projects/Q3 Goals (draft)": "q3-goals-draft",
+		"notes.md#ideas/":                   "ideas",
+	}
+	for selector, want := range tests {
+		if got := BookmarkName(selector); got != want {
+			t.Errorf("BookmarkName(%q) = %q, want %q", selector, got, want)
+		}
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+
+	if _, _, err := ws.SetBookmark("meet", "inbox.md#meetings"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ws.SetBookmark("arch", "design.md#architecture"); err != nil {
+		t.Fatal(err)
+	}
+	_, previous, err := ws.SetBookmark("meet", "work.md#meetings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != "inbox.md#meetings" {
+		t.Errorf("previous = %q", previous)
+	}
+
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookmarks) != 2 || bookmarks[0].Name != "arch" || bookmarks[1].Selector != "work.md#meetings" {
+		t.Errorf("bookmarks = %+v", bookmarks)
+	}
+
+	if _, err := ws.RemoveBookmark("arch"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.FindBookmark("arch"); err == nil {
+		t.Error("expected removed bookmark to be missing")
+	}
+	if _, _, err := ws.SetBookmark("a/b", "x.md"); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+}
@@ -0,0 +1,188 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// capturesFile records one JSON line per capture inside the .jot directory
+const capturesFile = "captures.jsonl"
+
+// CaptureExcerptSize bounds the captured text kept in a capture record.
+// Longer captures keep their start, with the length and hash of the whole
+// text to find it again.
+const CaptureExcerptSize = 4096
+
+// maxCaptureLine bounds a line of the capture log that is read. Longer
+// lines, left by captures logged whole, are skipped.
+const maxCaptureLine = 1 << 20
+
+// CaptureRecord describes a single capture
+type CaptureRecord struct {
+	Time     time.Time `json:"time"`
+	Heading  string    `json:"heading,omitempty"`
+	Template string    `json:"template,omitempty"`
+	File     string    `json:"file"`
+	Content  string    `json:"content,omitempty"` // The text as inserted, or its start when Size is larger
+	Size     int       `json:"size,omitempty"`    // Length of the whole text, when Content is an excerpt
+	Hash     string    `json:"sha256,omitempty"`  // Hash of the whole text, when Content is an excerpt
+	Amended  bool      `json:"amended,omitempty"` // Replaces the previous record's content
+}
+
+// SetContent records the captured text, keeping an excerpt of long text
+func (r *CaptureRecord) SetContent(text string) {
+	r.Size, r.Hash = 0, ""
+	if len(text) <= CaptureExcerptSize {
+		r.Content = text
+		return
+	}
+	sum := sha256.Sum256([]byte(text))
+	r.SetExcerpt([]byte(text), len(text), sum[:])
+}
+
+// SetExcerpt records text too long to keep whole by its start, its length
+// and its sha256 sum, for text that is never held in memory
+func (r *CaptureRecord) SetExcerpt(head []byte, size int, sum []byte) {
+	cut := min(len(head), CaptureExcerptSize)
+	if cut < len(head) {
+		for cut > 0 && !utf8.RuneStart(head[cut]) {
+			cut--
+		}
+	}
+	r.Content, r.Size, r.Hash = string(head[:cut]), size, hex.EncodeToString(sum)
+}
+
+// Index returns the offset and length of the first copy of the captured
+// text in content at or after from, or -1 when there is none
+func (r CaptureRecord) Index(content []byte, from int) (int, int) {
+	for r.Content != "" && from <= len(content) {
+		i := bytes.Index(content[from:], []byte(r.Content))
+		if i < 0 {
+			break
+		}
+		if n := r.matchAt(content, from+i); n >= 0 {
+			return from + i, n
+		}
+		from += i + 1
+	}
+	return -1, 0
+}
+
+// LastIndex returns the offset and length of the last copy of the captured
+// text in content, or -1 when there is none
+func (r CaptureRecord) LastIndex(content []byte) (int, int) {
+	end := len(content)
+	for r.Content != "" && end >= 0 {
+		i := bytes.LastIndex(content[:end], []byte(r.Content))
+		if i < 0 {
+			break
+		}
+		if n := r.matchAt(content, i); n >= 0 {
+			return i, n
+		}
+		end = i + len(r.Content) - 1
+	}
+	return -1, 0
+}
+
+// matchAt returns the length of the captured text when it starts at offset
+// in content, or -1
+func (r CaptureRecord) matchAt(content []byte, offset int) int {
+	if r.Size <= len(r.Content) {
+		return len(r.Content)
+	}
+	if offset+r.Size > len(content) {
+		return -1
+	}
+	sum := sha256.Sum256(content[offset : offset+r.Size])
+	if hex.EncodeToString(sum[:]) != r.Hash {
+		return -1
+	}
+	return r.Size
+}
+
+// RecordCapture appends a capture record to the capture log, keeping an
+// excerpt of long captured text
+func (ws *Workspace) RecordCapture(record CaptureRecord) error {
+	if record.Size == 0 {
+		record.SetContent(record.Content)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode capture record: %w", err)
+	}
+
+	if err := dryrun.AppendFile(filepath.Join(ws.JotDir, capturesFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write capture log: %w", err)
+	}
+	return nil
+}
+
+// LastCapture returns the most recent capture record, or nil when nothing
+// has been captured
+func (ws *Workspace) LastCapture() (*CaptureRecord, error) {
+	records, err := ws.LoadCaptures()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[len(records)-1], nil
+}
+
+// LoadCaptures returns all capture records, oldest first. Malformed lines are skipped.
+func (ws *Workspace) LoadCaptures() ([]CaptureRecord, error) {
+	file, err := os.Open(filepath.Join(ws.JotDir, capturesFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture log: %w", err)
+	}
+	defer file.Close()
+
+	var records []CaptureRecord
+	reader := bufio.NewReader(file)
+	for {
+		line, err := readLogLine(reader, maxCaptureLine)
+		var record CaptureRecord
+		if len(line) > 0 && json.Unmarshal(line, &record) == nil {
+			records = append(records, record)
+		}
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read capture log: %w", err)
+		}
+	}
+}
+
+// readLogLine reads a line from r. A line longer than limit is read past and
+// returned empty, so one oversized entry does not stop the rest being read.
+func readLogLine(r *bufio.Reader, limit int) ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLong && len(line)+len(chunk) > limit {
+			tooLong, line = true, nil
+		}
+		if !tooLong {
+			line = append(line, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		return line, err
+	}
+}
@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordCaptureKeepsExcerpt(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+	long := "## Big\n" + strings.Repeat("x", 3*CaptureExcerptSize) + "\n"
+	if err := ws.RecordCapture(CaptureRecord{File: "inbox.md", Content: long}); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := ws.LastCapture()
+	if err != nil || last == nil {
+		t.Fatalf("LastCapture() = %v, %v", last, err)
+	}
+	if len(last.Content) > CaptureExcerptSize || last.Size != len(long) || last.Hash == "" {
+		t.Fatalf("record kept %d bytes, size %d, hash %q", len(last.Content), last.Size, last.Hash)
+	}
+
+	content := []byte("# Inbox\n\n" + long + "\n" + long[:len(long)-1] + "y\n")
+	if offset, size := last.LastIndex(content); offset != len("# Inbox\n\n") || size != len(long) {
+		t.Errorf("LastIndex() = %d, %d; want the first copy, whose hash matches", offset, size)
+	}
+	if offset, _ := last.Index(content, 10); offset != -1 {
+		t.Errorf("Index() past the capture = %d, want -1", offset)
+	}
+}
+
+func TestLoadCapturesSkipsOversizedLines(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+	log := `{"file":"inbox.md","content":"first\n"}` + "\n" +
+		`{"file":"inbox.md","content":"` + strings.Repeat("x", maxCaptureLine) + `"}` + "\n" +
+		`{"file":"inbox.md","content":"last\n"}` + "\n"
+	if err := os.WriteFile(filepath.Join(ws.JotDir, capturesFile), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ws.LoadCaptures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Content != "first\n" || records[1].Content != "last\n" {
+		t.Errorf("LoadCaptures() = %+v", records)
+	}
+}
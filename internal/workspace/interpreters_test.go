@@ -0,0 +1,26 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/johncoder/jot/internal/config"
+)
+
+func TestGetInterpreters(t *testing.T) {
+	var none *Workspace
+	if got := none.GetInterpreters(); len(got) != 0 {
+		t.Errorf("no configuration gave %v", got)
+	}
+
+	ws := &Workspace{Config: &WorkspaceConfig{Eval: &config.EvalConfig{Interpreters: map[string]string{
+		"python": "uv run python",
+		"blank":  " ",
+	}}}}
+	got := ws.GetInterpreters()
+	if len(got) != 1 {
+		t.Fatalf("got %v, want only python", got)
+	}
+	if p := got["python"]; p.Command != "uv run python" || p.Source != "workspace" || p.Language != "python" {
+		t.Errorf("python = %+v", p)
+	}
+}
@@ -0,0 +1,49 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// issuesFile remembers the state of issue items as last synced, inside the
+// .jot directory
+const issuesFile = "issues.json"
+
+// IssueState is an issue item's state when it was last ingested or synced
+type IssueState struct {
+	Done   bool      `json:"done"`
+	Synced time.Time `json:"synced"`
+}
+
+// LoadIssueStates returns the synced state of each issue item, by issue key
+func (ws *Workspace) LoadIssueStates() (map[string]IssueState, error) {
+	states := map[string]IssueState{}
+	data, err := os.ReadFile(filepath.Join(ws.JotDir, issuesFile))
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issue states: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", issuesFile, err)
+	}
+	return states, nil
+}
+
+// SaveIssueStates writes the synced state of each issue item
+func (ws *Workspace) SaveIssueStates(states map[string]IssueState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode issue states: %w", err)
+	}
+	if err := dryrun.WriteFile(filepath.Join(ws.JotDir, issuesFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write issue states: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// lockFile marks the workspace as being changed by a jot command
+const lockFile = "lock"
+
+// LockHolderEnv names the variable a command holding the lock sets for the
+// hooks and commands it runs, so a jot started by one of them works inside
+// the lock rather than waiting for it
+const LockHolderEnv = "JOT_LOCK_HOLDER"
+
+// LockWait is how long Lock waits for another command to finish
+var LockWait = 10 * time.Second
+
+// breakLockAge is how old a break file must be before it counts as left by
+// a command that died while clearing a stale lock
+const breakLockAge = 10 * time.Second
+
+// LockInfo is what the lock file says about the command holding it
+type LockInfo struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+// Lock takes the workspace's write lock for a command, waiting a while for
+// another command holding it to finish. A lock naming a command that is no
+// longer running is taken over; one that cannot be read yet, as one just
+// created and not yet written, is waited on like any other. Dry runs, and
+// commands run by the command holding the lock, do not lock. The returned
+// function releases the lock.
+func (ws *Workspace) Lock(command string) (func(), error) {
+	if ws.JotDir == "" || dryrun.Enabled() {
+		return func() {}, nil
+	}
+	path := filepath.Join(ws.JotDir, lockFile)
+	if holder := os.Getenv(LockHolderEnv); holder != "" {
+		if info, err := readLock(path); err == nil && strconv.Itoa(info.PID) == holder {
+			return func() {}, nil
+		}
+	}
+
+	deadline := time.Now().Add(LockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			info := LockInfo{PID: os.Getpid(), Command: command, Since: time.Now()}
+			err = json.NewEncoder(f).Encode(info)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write workspace lock: %w", err)
+			}
+			os.Setenv(LockHolderEnv, strconv.Itoa(info.PID))
+			return func() {
+				if held, err := readLock(path); err == nil && held.PID == info.PID {
+					os.Remove(path)
+				}
+				os.Unsetenv(LockHolderEnv)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock workspace: %w", err)
+		}
+
+		info, err := readLock(path)
+		if os.IsNotExist(err) {
+			continue // Released since
+		}
+		if err == nil && !processRunning(info.PID) {
+			// Left behind by a command that crashed or was killed
+			cleared, err := clearStaleLock(path, info)
+			if err != nil {
+				return nil, fmt.Errorf("failed to clear stale workspace lock: %w", err)
+			}
+			if cleared {
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			if info == nil {
+				return nil, fmt.Errorf("the workspace lock %s cannot be read; remove it if no jot command is running", path)
+			}
+			return nil, fmt.Errorf("another jot command (pid %d, %q, since %s) is changing this workspace; wait for it to finish, or remove %s if it is stuck",
+				info.PID, info.Command, info.Since.Format("15:04:05"), path)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// clearStaleLock removes the lock at path if it still names stale, a
+// command no longer running, and reports whether it did. Waiters take a
+// second, break lock while they check, so two of them never both clear a
+// lock, one removing the lock the other has just taken.
+func clearStaleLock(path string, stale *LockInfo) (bool, error) {
+	breakPath := path + ".break"
+	f, err := os.OpenFile(breakPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		// Another waiter is clearing it, unless one died doing so long ago
+		if info, err := os.Stat(breakPath); err == nil && time.Since(info.ModTime()) > breakLockAge {
+			os.Remove(breakPath)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	f.Close()
+	defer os.Remove(breakPath)
+
+	current, err := readLock(path)
+	if err != nil || current.PID != stale.PID || !current.Since.Equal(stale.Since) {
+		return false, nil // Cleared and taken by someone else since
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// readLock reads the lock file at path
+func readLock(path string) (*LockInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	if info.PID <= 0 {
+		return nil, errors.New("lock has no pid")
+	}
+	return &info, nil
+}
+
+// processRunning reports whether the process with pid is still running
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess only finds running processes there
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+	ws := &Workspace{Root: dir, JotDir: dir}
+	path := filepath.Join(dir, lockFile)
+	defer func(wait time.Duration) { LockWait = wait }(LockWait)
+	LockWait = 200 * time.Millisecond
+
+	writeLock := func(pid int) {
+		t.Helper()
+		data, _ := json.Marshal(LockInfo{PID: pid, Command: "jot refile", Since: time.Now()})
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Another running command holds the lock
+	writeLock(os.Getppid())
+	if _, err := ws.Lock("jot archive"); err == nil || !strings.Contains(err.Error(), "jot refile") {
+		t.Fatalf("got %v, want the holder named", err)
+	}
+
+	// A lock left by a command that is gone is taken over
+	writeLock(1 << 30)
+	unlock, err := ws.Lock("jot archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := readLock(path); err != nil || info.PID != os.Getpid() {
+		t.Fatalf("lock holder = %+v, %v", info, err)
+	}
+
+	// Commands run inside the lock work without waiting for it
+	nested, err := ws.Lock("jot refile")
+	if err != nil {
+		t.Fatalf("nested lock: %v", err)
+	}
+	nested()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("the nested command released the lock")
+	}
+
+	unlock()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("the lock was not released")
+	}
+	if os.Getenv(LockHolderEnv) != "" {
+		t.Errorf("%s still set after unlocking", LockHolderEnv)
+	}
+}
+
+func TestLockWaitsOnUnreadableLock(t *testing.T) {
+	dir := t.TempDir()
+	ws := &Workspace{Root: dir, JotDir: dir}
+	path := filepath.Join(dir, lockFile)
+	defer func(wait time.Duration) { LockWait = wait }(LockWait)
+	LockWait = 200 * time.Millisecond
+
+	// Just created by another command, which has not written it yet
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.Lock("jot archive"); err == nil || !strings.Contains(err.Error(), "cannot be read") {
+		t.Fatalf("got %v, want the lock waited on", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Fatalf("the unwritten lock was replaced: %q, %v", data, err)
+	}
+
+	// Once the other command writes and releases it, the lock is taken
+	LockWait = 2 * time.Second
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		data, _ := json.Marshal(LockInfo{PID: os.Getppid(), Command: "jot refile", Since: time.Now()})
+		os.WriteFile(path, data, 0644)
+		time.Sleep(150 * time.Millisecond)
+		os.Remove(path)
+	}()
+	unlock, err := ws.Lock("jot archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
+
+func TestClearStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, lockFile)
+	stale := &LockInfo{PID: 1 << 30, Command: "jot refile", Since: time.Now().Truncate(time.Second)}
+	write := func(info *LockInfo) {
+		t.Helper()
+		data, _ := json.Marshal(info)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Another waiter cleared the stale lock and took a new one
+	write(&LockInfo{PID: os.Getpid(), Command: "jot archive", Since: time.Now()})
+	if cleared, err := clearStaleLock(path, stale); err != nil || cleared {
+		t.Fatalf("clearStaleLock = %v, %v; want the new lock kept", cleared, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("the new lock was removed")
+	}
+
+	// Another waiter is clearing it
+	write(stale)
+	if err := os.WriteFile(path+".break", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cleared, _ := clearStaleLock(path, stale); cleared {
+		t.Error("cleared while another waiter held the break lock")
+	}
+	os.Remove(path + ".break")
+
+	if cleared, err := clearStaleLock(path, stale); err != nil || !cleared {
+		t.Fatalf("clearStaleLock = %v, %v; want the stale lock cleared", cleared, err)
+	}
+	if _, err := os.Stat(path + ".break"); !os.IsNotExist(err) {
+		t.Error("the break lock was left behind")
+	}
+}
@@ -0,0 +1,164 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/journal"
+)
+
+// MovedPath maps a workspace-relative, slash-separated path through a move
+// of from to to. Moving a directory carries the paths under it.
+func MovedPath(path, from, to string) (string, bool) {
+	switch {
+	case path == from:
+		return to, true
+	case strings.HasPrefix(path, from+"/"):
+		return to + path[len(from):], true
+	}
+	return path, false
+}
+
+// MovedSelector maps the file of a selector through a move, keeping its
+// heading path
+func MovedSelector(selector, from, to string) (string, bool) {
+	file, headings, hasHeadings := strings.Cut(selector, "#")
+	moved, ok := MovedPath(strings.TrimPrefix(filepath.ToSlash(file), "./"), from, to)
+	if !ok {
+		return selector, false
+	}
+	if hasHeadings {
+		moved += "#" + headings
+	}
+	return moved, true
+}
+
+// MovedState counts the saved selectors a move changes
+type MovedState struct {
+	Bookmarks       int  `json:"bookmarks"`
+	Queue           int  `json:"queue"`
+	Aliases         int  `json:"aliases"`
+	Captures        int  `json:"captures"`
+	ArchiveLocation bool `json:"archive_location"`
+}
+
+// MoveStateWrites returns the writes that point the selectors the workspace
+// saves at a moved path: bookmarks, the reading queue, aliases, the archive
+// location and the capture log. Nothing is written here, so the caller can
+// apply them together with the move itself.
+func (ws *Workspace) MoveStateWrites(from, to string) ([]journal.Write, MovedState, error) {
+	var writes []journal.Write
+	var state MovedState
+
+	bookmarks, err := ws.LoadBookmarks()
+	if err != nil {
+		return nil, state, err
+	}
+	for i := range bookmarks {
+		if moved, ok := MovedSelector(bookmarks[i].Selector, from, to); ok {
+			bookmarks[i].Selector = moved
+			state.Bookmarks++
+		}
+	}
+	if state.Bookmarks > 0 {
+		data, err := json.MarshalIndent(bookmarks, "", "  ")
+		if err != nil {
+			return nil, state, fmt.Errorf("failed to encode bookmarks: %w", err)
+		}
+		writes = append(writes, journal.Write{Path: filepath.Join(ws.JotDir, bookmarksFile), Content: append(data, '\n')})
+	}
+
+	queue, err := ws.LoadQueue()
+	if err != nil {
+		return nil, state, err
+	}
+	for i := range queue {
+		if moved, ok := MovedSelector(queue[i].Selector, from, to); ok {
+			queue[i].Selector = moved
+			state.Queue++
+		}
+	}
+	if state.Queue > 0 {
+		data, err := json.MarshalIndent(queue, "", "  ")
+		if err != nil {
+			return nil, state, fmt.Errorf("failed to encode queue: %w", err)
+		}
+		writes = append(writes, journal.Write{Path: filepath.Join(ws.JotDir, queueFile), Content: append(data, '\n')})
+	}
+
+	if ws.Config != nil {
+		config := *ws.Config
+		config.Aliases = make(map[string]string, len(ws.Config.Aliases))
+		for name, selector := range ws.Config.Aliases {
+			moved, ok := MovedSelector(selector, from, to)
+			if ok {
+				state.Aliases++
+			}
+			config.Aliases[name] = moved
+		}
+		if ws.Config.Aliases == nil {
+			config.Aliases = nil
+		}
+		if config.ArchiveLocation != "" {
+			config.ArchiveLocation, state.ArchiveLocation = MovedSelector(config.ArchiveLocation, from, to)
+		}
+		if state.Aliases > 0 || state.ArchiveLocation {
+			data, err := json.MarshalIndent(&config, "", "  ")
+			if err != nil {
+				return nil, state, fmt.Errorf("failed to marshal workspace config: %w", err)
+			}
+			writes = append(writes, journal.Write{Path: filepath.Join(ws.JotDir, "config.json"), Content: data})
+		}
+	}
+
+	captures, count, err := ws.movedCaptureLog(from, to)
+	if err != nil {
+		return nil, state, err
+	}
+	if count > 0 {
+		state.Captures = count
+		writes = append(writes, journal.Write{Path: filepath.Join(ws.JotDir, capturesFile), Content: captures})
+	}
+	return writes, state, nil
+}
+
+// movedCaptureLog rewrites the destinations in the capture log through a
+// move. Lines that are not capture records are kept as they are.
+func (ws *Workspace) movedCaptureLog(from, to string) ([]byte, int, error) {
+	data, err := os.ReadFile(filepath.Join(ws.JotDir, capturesFile))
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read capture log: %w", err)
+	}
+
+	var out bytes.Buffer
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var record CaptureRecord
+		if json.Unmarshal(line, &record) == nil {
+			if moved, ok := MovedSelector(record.File, from, to); ok {
+				record.File = moved
+				if encoded, err := json.Marshal(record); err == nil {
+					line = encoded
+					count++
+				}
+			}
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read capture log: %w", err)
+	}
+	return out.Bytes(), count, nil
+}
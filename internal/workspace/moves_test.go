@@ -0,0 +1,63 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMovedSelector(t *testing.T) {
+	tests := []struct {
+		selector, want string
+		moved          bool
+	}{
+		{"lib/old.md", "notes/new.md", true},
+		{"lib/old.md#projects/Frontend", "notes/new.md#projects/Frontend", true},
+		{"./lib/old.md#projects", "notes/new.md#projects", true},
+		{"lib/older.md#projects", "lib/older.md#projects", false},
+		{"work.md", "work.md", false},
+	}
+	for _, tt := range tests {
+		got, moved := MovedSelector(tt.selector, "lib/old.md", "notes/new.md")
+		if got != tt.want || moved != tt.moved {
+			t.Errorf("MovedSelector(%q) = %q, %v; want %q, %v", tt.selector, got, moved, tt.want, tt.moved)
+		}
+	}
+
+	if got, _ := MovedSelector("lib/a/b.md#x", "lib/a", "archive/a"); got != "archive/a/b.md#x" {
+		t.Errorf("directory move gave %q", got)
+	}
+}
+
+func TestMoveStateWrites(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir(), Config: &WorkspaceConfig{
+		Aliases: map[string]string{"meet": "lib/old.md#meetings", "arch": "design.md#architecture"},
+	}}
+	if _, _, err := ws.SetBookmark("meet", "lib/old.md#meetings"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.PushQueue("design.md#architecture"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.RecordCapture(CaptureRecord{File: "lib/old.md"}); err != nil {
+		t.Fatal(err)
+	}
+
+	writes, state, err := ws.MoveStateWrites("lib/old.md", "lib/new.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != (MovedState{Bookmarks: 1, Aliases: 1, Captures: 1}) {
+		t.Errorf("state = %+v", state)
+	}
+	if len(writes) != 3 {
+		t.Fatalf("got %d writes, want 3 (the queue is unchanged)", len(writes))
+	}
+	for _, write := range writes {
+		if strings.Contains(string(write.Content), "lib/old.md") || !strings.Contains(string(write.Content), "lib/new.md") {
+			t.Errorf("%s was not moved:\n%s", write.Path, write.Content)
+		}
+	}
+	if ws.Config.Aliases["meet"] != "lib/old.md#meetings" {
+		t.Error("MoveStateWrites changed the loaded configuration")
+	}
+}
@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// queueFile holds the reading queue inside the .jot directory
+const queueFile = "queue.json"
+
+// QueueItem is a selector deferred for reading later
+type QueueItem struct {
+	Selector string    `json:"selector"`
+	Added    time.Time `json:"added"`
+}
+
+// LoadQueue returns the reading queue, oldest first
+func (ws *Workspace) LoadQueue() ([]QueueItem, error) {
+	data, err := os.ReadFile(filepath.Join(ws.JotDir, queueFile))
+	if os.IsNotExist(err) {
+		return []QueueItem{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+
+	items := []QueueItem{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", queueFile, err)
+	}
+	return items, nil
+}
+
+// PushQueue appends selectors to the reading queue. Selectors already queued
+// are left where they are. It returns the items that were added.
+func (ws *Workspace) PushQueue(selectors ...string) ([]QueueItem, error) {
+	items, err := ws.LoadQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make(map[string]bool, len(items))
+	for _, item := range items {
+		queued[item.Selector] = true
+	}
+	added := []QueueItem{}
+	for _, selector := range selectors {
+		if queued[selector] {
+			continue
+		}
+		queued[selector] = true
+		item := QueueItem{Selector: selector, Added: time.Now()}
+		items = append(items, item)
+		added = append(added, item)
+	}
+	if len(added) == 0 {
+		return added, nil
+	}
+	return added, ws.SaveQueue(items)
+}
+
+// SaveQueue replaces the reading queue
+func (ws *Workspace) SaveQueue(items []QueueItem) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queue: %w", err)
+	}
+	if err := dryrun.WriteFile(filepath.Join(ws.JotDir, queueFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package workspace
+
+import "testing"
+
+func TestPushQueue(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+
+	added, err := ws.PushQueue("inbox.md#a", "inbox.md#b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 {
+		t.Errorf("added %d items, want 2", len(added))
+	}
+
+	added, err = ws.PushQueue("inbox.md#a", "work.md#c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || added[0].Selector != "work.md#c" {
+		t.Errorf("added = %+v, want only work.md#c", added)
+	}
+
+	items, err := ws.LoadQueue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"inbox.md#a", "inbox.md#b", "work.md#c"}
+	if len(items) != len(want) {
+		t.Fatalf("queue has %d items, want %d", len(items), len(want))
+	}
+	for i, item := range items {
+		if item.Selector != want[i] {
+			t.Errorf("item %d = %q, want %q", i, item.Selector, want[i])
+		}
+	}
+}
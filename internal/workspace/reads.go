@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// readsDir holds each user's read markers inside the .jot directory
+const readsDir = "reads"
+
+// ReadMarks records what one user has read: for each file, the hash of
+// every section as it was when they read it
+type ReadMarks struct {
+	Files map[string]*FileMarks `json:"files"`
+}
+
+// FileMarks are the sections of one file a user has read, keyed by heading
+// path
+type FileMarks struct {
+	Sections map[string]string `json:"sections"`
+}
+
+// unsafeUserChars are the characters kept out of a read markers file name
+var unsafeUserChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (ws *Workspace) readsPath(user string) string {
+	name := unsafeUserChars.ReplaceAllString(user, "_")
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(ws.JotDir, readsDir, name+".json")
+}
+
+// LoadReadMarks returns user's read markers, empty when they have read
+// nothing yet
+func (ws *Workspace) LoadReadMarks(user string) (*ReadMarks, error) {
+	marks := &ReadMarks{Files: map[string]*FileMarks{}}
+	data, err := os.ReadFile(ws.readsPath(user))
+	if os.IsNotExist(err) {
+		return marks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read read markers: %w", err)
+	}
+	if err := json.Unmarshal(data, marks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ws.readsPath(user), err)
+	}
+	if marks.Files == nil {
+		marks.Files = map[string]*FileMarks{}
+	}
+	return marks, nil
+}
+
+// SaveReadMarks writes user's read markers
+func (ws *Workspace) SaveReadMarks(user string, marks *ReadMarks) error {
+	data, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode read markers: %w", err)
+	}
+	path := ws.readsPath(user)
+	if err := dryrun.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := dryrun.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write read markers: %w", err)
+	}
+	return nil
+}
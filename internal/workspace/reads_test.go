@@ -0,0 +1,45 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMarks(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+
+	marks, err := ws.LoadReadMarks("sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marks.Files) != 0 {
+		t.Fatalf("new user has marks: %+v", marks.Files)
+	}
+
+	marks.Files["work.md"] = &FileMarks{Sections: map[string]string{"Log": "abc"}}
+	if err := ws.SaveReadMarks("sam", marks); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SaveReadMarks("DOMAIN\\alex", &ReadMarks{Files: map[string]*FileMarks{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(ws.JotDir, "reads", "DOMAIN_alex.json")); err != nil {
+		t.Errorf("user name not made safe for a file name: %v", err)
+	}
+
+	loaded, err := ws.LoadReadMarks("sam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Files["work.md"]; got == nil || got.Sections["Log"] != "abc" {
+		t.Errorf("loaded = %+v", loaded.Files)
+	}
+	other, err := ws.LoadReadMarks("DOMAIN\\alex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Files) != 0 {
+		t.Errorf("users share marks: %+v", other.Files)
+	}
+}
@@ -0,0 +1,63 @@
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// refilesFile records one JSON line per refiled subtree inside the .jot
+// directory. The operation journal only holds operations still in progress,
+// so this log is what remembers where subtrees went.
+const refilesFile = "refiles.jsonl"
+
+// RefileRecord describes a subtree moved by refile or archive
+type RefileRecord struct {
+	Time    time.Time `json:"time"`
+	Heading string    `json:"heading"`
+	From    string    `json:"from"` // Selector the subtree was refiled from
+	To      string    `json:"to"`   // Selector of the subtree at its destination
+}
+
+// RecordRefile appends a refile record to the refile log
+func (ws *Workspace) RecordRefile(record RefileRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode refile record: %w", err)
+	}
+
+	if err := dryrun.AppendFile(filepath.Join(ws.JotDir, refilesFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write refile log: %w", err)
+	}
+	return nil
+}
+
+// LoadRefiles returns all refile records, oldest first. Malformed lines are skipped.
+func (ws *Workspace) LoadRefiles() ([]RefileRecord, error) {
+	file, err := os.Open(filepath.Join(ws.JotDir, refilesFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open refile log: %w", err)
+	}
+	defer file.Close()
+
+	var records []RefileRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record RefileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read refile log: %w", err)
+	}
+	return records, nil
+}
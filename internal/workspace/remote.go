@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/johncoder/jot/internal/storage"
+)
+
+var (
+	remoteMu sync.Mutex
+	remotes  = map[string]*Workspace{}
+)
+
+// RemoteWorkspace opens the workspace at an ssh:// URL. Its notes stay on
+// the host and are cached in a local mirror under the user cache
+// directory, which becomes the workspace root; the workspace's Backend
+// reads and writes them over ssh. Each remote is synced once per process.
+func RemoteWorkspace(location string) (*Workspace, error) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	if ws := remotes[location]; ws != nil {
+		return ws, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("no cache directory for remote workspaces: %w", err)
+	}
+	backend, err := storage.NewSSH(location, filepath.Join(cacheDir, "jot", "ssh"))
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Sync(); err != nil {
+		return nil, err
+	}
+	if err := backend.Offline(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot reach %s, using cached notes read-only (%v)\n", location, err)
+	}
+
+	jotDir := filepath.Join(backend.Root, ".jot")
+	cfg, err := LoadWorkspaceConfig(jotDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+	}
+
+	ws := &Workspace{
+		Root:      backend.Root,
+		JotDir:    jotDir,
+		InboxPath: filepath.Join(backend.Root, "inbox.md"),
+		LibDir:    filepath.Join(backend.Root, "lib"),
+		Config:    cfg,
+		Location:  location,
+		Backend:   backend,
+	}
+	remotes[location] = ws
+	return ws, nil
+}
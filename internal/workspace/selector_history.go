@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/dryrun"
+)
+
+// selectorHistoryFile keeps the selectors peek and refile were used with,
+// one per line, oldest first, inside the .jot/state directory
+const selectorHistoryFile = "state/selector_history"
+
+// MaxSelectorHistory is how many selectors the history keeps
+const MaxSelectorHistory = 500
+
+// RecordSelector adds a selector to the end of the selector history,
+// dropping an earlier use of the same selector and the oldest ones beyond
+// MaxSelectorHistory. The history is not part of the notes, so a dry run
+// records nothing.
+func (ws *Workspace) RecordSelector(selector string) error {
+	selector = strings.TrimSpace(selector)
+	if selector == "" || strings.ContainsAny(selector, "\r\n") || dryrun.Enabled() {
+		return nil
+	}
+
+	lines, err := ws.readSelectorHistory()
+	if err != nil {
+		return err
+	}
+	kept := make([]string, 0, len(lines)+1)
+	for _, line := range lines {
+		if line != selector {
+			kept = append(kept, line)
+		}
+	}
+	kept = append(kept, selector)
+	if len(kept) > MaxSelectorHistory {
+		kept = kept[len(kept)-MaxSelectorHistory:]
+	}
+
+	path := filepath.Join(ws.JotDir, selectorHistoryFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// A temp file of its own keeps commands recording at once from
+	// renaming each other's half-written history into place
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".selector_history-*")
+	if err != nil {
+		return fmt.Errorf("failed to write selector history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(strings.Join(kept, "\n") + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write selector history: %w", err)
+	}
+	return nil
+}
+
+// SelectorHistory returns the selectors in the history, most recently used
+// first
+func (ws *Workspace) SelectorHistory() ([]string, error) {
+	lines, err := ws.readSelectorHistory()
+	if err != nil {
+		return nil, err
+	}
+	history := make([]string, 0, len(lines))
+	seen := make(map[string]bool)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if !seen[lines[i]] {
+			seen[lines[i]] = true
+			history = append(history, lines[i])
+		}
+	}
+	return history, nil
+}
+
+// readSelectorHistory returns the history's lines, oldest first
+func (ws *Workspace) readSelectorHistory() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(ws.JotDir, selectorHistoryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selector history: %w", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
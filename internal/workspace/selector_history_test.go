@@ -0,0 +1,83 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSelectorHistory(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+
+	history, err := ws.SelectorHistory()
+	if err != nil || len(history) != 0 {
+		t.Fatalf("SelectorHistory() = %v, %v, want an empty history", history, err)
+	}
+
+	for _, selector := range []string{"inbox.md#meeting", "work.md#projects", " inbox.md#meeting ", "", "a\nb", "work.md"} {
+		if err := ws.RecordSelector(selector); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err = ws.SelectorHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"work.md", "inbox.md#meeting", "work.md#projects"}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("SelectorHistory() = %q, want %q", history, want)
+	}
+
+	for i := 0; i < MaxSelectorHistory+5; i++ {
+		if err := ws.RecordSelector(fmt.Sprintf("log.md#%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err = ws.SelectorHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != MaxSelectorHistory {
+		t.Errorf("history keeps %d selectors, want %d", len(history), MaxSelectorHistory)
+	}
+	if last := fmt.Sprintf("log.md#%d", MaxSelectorHistory+4); history[0] != last {
+		t.Errorf("most recent = %q, want %q", history[0], last)
+	}
+}
+
+func TestRecordSelectorConcurrently(t *testing.T) {
+	ws := &Workspace{JotDir: t.TempDir()}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- ws.RecordSelector(fmt.Sprintf("work.md#%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("RecordSelector() = %v", err)
+		}
+	}
+
+	// Each write renames a whole history into place and leaves no temp file
+	history, err := ws.SelectorHistory()
+	if err != nil || len(history) == 0 {
+		t.Fatalf("SelectorHistory() = %v, %v", history, err)
+	}
+	entries, err := os.ReadDir(filepath.Join(ws.JotDir, "state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("state holds %d files, want only the history", len(entries))
+	}
+}
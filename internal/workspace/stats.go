@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/johncoder/jot/internal/storage"
 )
 
 // Stats contains workspace statistics
@@ -27,18 +29,12 @@ func GetStats(ws *Workspace) Stats {
 // calculateStats computes workspace statistics
 func calculateStats(ws *Workspace) (inboxNotes, libNotes int, lastActivity time.Time) {
 	// Count inbox notes (sections starting with ##)
-	if content, err := os.ReadFile(ws.InboxPath); err == nil {
+	if content, err := storage.ReadFile(ws.InboxPath); err == nil {
 		inboxNotes = strings.Count(string(content), "\n## ")
 	}
 
 	// Count lib notes (markdown files in lib directory)
-	if entries, err := os.ReadDir(ws.LibDir); err == nil {
-		for _, entry := range entries {
-			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-				libNotes++
-			}
-		}
-	}
+	libNotes = len(markdownFilesIn(ws.LibDir))
 
 	// Get last activity time (most recent modification in workspace)
 	lastActivity = GetLastModificationTime(ws.Root)
@@ -51,25 +47,39 @@ func GetLastModificationTime(root string) time.Time {
 	var latest time.Time
 
 	// Check inbox.md
-	if info, err := os.Stat(filepath.Join(root, "inbox.md")); err == nil {
+	if info, err := storage.Stat(filepath.Join(root, "inbox.md")); err == nil {
 		if info.ModTime().After(latest) {
 			latest = info.ModTime()
 		}
 	}
 
 	// Check lib directory
-	libDir := filepath.Join(root, "lib")
-	if entries, err := os.ReadDir(libDir); err == nil {
-		for _, entry := range entries {
-			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
-				if info, err := entry.Info(); err == nil {
-					if info.ModTime().After(latest) {
-						latest = info.ModTime()
-					}
-				}
-			}
+	for _, info := range markdownFilesIn(filepath.Join(root, "lib")) {
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
 		}
 	}
 
 	return latest
 }
+
+// markdownFilesIn returns the markdown files directly inside dir
+func markdownFilesIn(dir string) []os.FileInfo {
+	var files []os.FileInfo
+	storage.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(info.Name(), ".md") {
+			files = append(files, info)
+		}
+		return nil
+	})
+	return files
+}
@@ -0,0 +1,211 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johncoder/jot/internal/storage"
+)
+
+// ScanBoundaries lists the places a workspace scan stops or detours
+type ScanBoundaries struct {
+	NestedWorkspaces []string // Directories with their own .jot that scans skip
+	SymlinkedDirs    []string // Symlinked directories, followed only with follow_symlinks
+	SkippedLinks     []string // Followed symlinks skipped because they loop or repeat files already scanned
+}
+
+// IsNestedWorkspace reports whether dir holds its own .jot directory
+func IsNestedWorkspace(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".jot"))
+	return err == nil && info.IsDir()
+}
+
+// Walk walks the tree at root like filepath.Walk, keeping to this workspace.
+// Directories holding a nested workspace are skipped unless
+// include_nested_workspaces is set. Symlinked directories are descended
+// into when follow_symlinks is set, with paths reported under the link;
+// links that loop or lead back into files already scanned are skipped.
+// Notes kept in another storage backend are walked by the backend.
+func (ws *Workspace) Walk(root string, fn filepath.WalkFunc) error {
+	if !storage.IsFilesystem() {
+		return storage.Walk(root, fn)
+	}
+	w := &walker{ws: ws}
+	return w.walk(root, fn)
+}
+
+// ScanBoundaries walks the workspace and reports nested workspaces and
+// symlinked directories, for doctor to explain what scans leave out
+func (ws *Workspace) ScanBoundaries() (*ScanBoundaries, error) {
+	w := &walker{ws: ws, report: &ScanBoundaries{}}
+	err := w.walk(ws.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && path != ws.Root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return w.report, err
+}
+
+type walker struct {
+	ws       *Workspace
+	realRoot string
+	followed []string
+	report   *ScanBoundaries
+}
+
+func (w *walker) includeNested() bool {
+	return w.ws.Config != nil && w.ws.Config.IncludeNestedWorkspaces
+}
+
+func (w *walker) followSymlinks() bool {
+	return w.ws.Config != nil && w.ws.Config.FollowSymlinks
+}
+
+func (w *walker) walk(root string, fn filepath.WalkFunc) error {
+	if w.followSymlinks() || w.report != nil {
+		if real, err := filepath.EvalSymlinks(w.ws.Root); err == nil {
+			w.realRoot = real
+		} else {
+			w.realRoot = w.ws.Root
+		}
+	}
+	return filepath.Walk(root, w.visit(root, root, fn))
+}
+
+// visit wraps fn for a walk over realBase whose paths are reported under logicalBase
+func (w *walker) visit(realBase, logicalBase string, fn filepath.WalkFunc) filepath.WalkFunc {
+	return func(path string, info os.FileInfo, err error) error {
+		logical := path
+		if realBase != logicalBase {
+			rel, relErr := filepath.Rel(realBase, path)
+			if relErr != nil {
+				return relErr
+			}
+			logical = filepath.Join(logicalBase, rel)
+		}
+		if err != nil {
+			return fn(logical, info, err)
+		}
+
+		if info.IsDir() {
+			if path != realBase && logical != w.ws.Root && IsNestedWorkspace(path) {
+				if w.report != nil {
+					w.report.NestedWorkspaces = append(w.report.NestedWorkspaces, logical)
+				}
+				if !w.includeNested() {
+					return filepath.SkipDir
+				}
+			}
+			return fn(logical, info, nil)
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, statErr := os.Stat(path)
+			if statErr == nil && target.IsDir() {
+				return w.visitLink(path, logical, target, fn)
+			}
+		}
+		return fn(logical, info, nil)
+	}
+}
+
+// visitLink handles a symlink that resolves to a directory
+func (w *walker) visitLink(path, logical string, target os.FileInfo, fn filepath.WalkFunc) error {
+	if w.report != nil {
+		w.report.SymlinkedDirs = append(w.report.SymlinkedDirs, logical)
+	}
+	if !w.followSymlinks() {
+		return nil
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fn(logical, target, err)
+	}
+	if w.seen(real) {
+		if w.report != nil {
+			w.report.SkippedLinks = append(w.report.SkippedLinks, logical)
+		}
+		return nil
+	}
+	if IsNestedWorkspace(real) && !w.includeNested() {
+		if w.report != nil {
+			w.report.NestedWorkspaces = append(w.report.NestedWorkspaces, logical)
+		}
+		return nil
+	}
+
+	if err := fn(logical, target, nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	w.followed = append(w.followed, real)
+	visit := w.visit(real, logical, fn)
+	err = filepath.Walk(real, func(p string, info os.FileInfo, err error) error {
+		if p == real {
+			// fn already saw the link itself
+			return nil
+		}
+		return visit(p, info, err)
+	})
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+// seen reports whether walking real would loop or repeat files already scanned
+func (w *walker) seen(real string) bool {
+	for _, dir := range append([]string{w.realRoot}, w.followed...) {
+		if within(real, dir) || within(dir, real) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or lies beneath it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Contains reports whether path lies inside the workspace once symlinks are
+// resolved, so a link cannot lead out of it. A path that does not exist yet
+// is judged by its nearest existing parent.
+func (ws *Workspace) Contains(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	root, err := filepath.Abs(ws.Root)
+	if err != nil {
+		return false
+	}
+	return within(realPath(abs), realPath(root))
+}
+
+// realPath resolves the symlinks in the longest existing prefix of an
+// absolute, clean path
+func realPath(path string) string {
+	var missing []string
+	for {
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{real}, missing...)...)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, missing...)...)
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
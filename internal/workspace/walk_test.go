@@ -0,0 +1,114 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	mkdir := func(dir string) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path string) {
+		mkdir(filepath.Dir(path))
+		if err := os.WriteFile(path, []byte("# Note\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mkdir(filepath.Join(root, ".jot"))
+	write(filepath.Join(root, "inbox.md"))
+	write(filepath.Join(root, "lib", "work.md"))
+	mkdir(filepath.Join(root, "team", ".jot"))
+	write(filepath.Join(root, "team", "notes.md"))
+	write(filepath.Join(outside, "shared.md"))
+
+	if err := os.Symlink(outside, filepath.Join(root, "shared")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	if err := os.Symlink(root, filepath.Join(root, "lib", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	files := func(cfg *WorkspaceConfig) []string {
+		ws := &Workspace{Root: root, Config: cfg}
+		var got []string
+		err := ws.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && path != root && info.Name() == ".jot" {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() && filepath.Ext(path) == ".md" {
+				rel, _ := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	tests := []struct {
+		name string
+		cfg  *WorkspaceConfig
+		want []string
+	}{
+		{"default", &WorkspaceConfig{}, []string{"inbox.md", "lib/work.md"}},
+		{"nested", &WorkspaceConfig{IncludeNestedWorkspaces: true}, []string{"inbox.md", "lib/work.md", "team/notes.md"}},
+		{"symlinks", &WorkspaceConfig{FollowSymlinks: true}, []string{"inbox.md", "lib/work.md", "shared/shared.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := files(tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk() files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "notes")
+	outside := filepath.Join(dir, "outside")
+	for _, d := range []string{filepath.Join(root, "lib"), outside} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	ws := &Workspace{Root: filepath.Join(dir, "link")}
+
+	tests := map[string]bool{
+		filepath.Join(root, "inbox.md"):               true,
+		filepath.Join(root, "lib", "new", "draft.md"): true,
+		filepath.Join(root, "lib", "..", "inbox.md"):  true,
+		filepath.Join(root, "..", "outside.md"):       false,
+		filepath.Join(outside, "secret.md"):           false,
+		filepath.Join(root, "escape", "secret.md"):    false,
+		filepath.Join(root, "escape", "new", "x.md"):  false,
+	}
+	for path, want := range tests {
+		if got := ws.Contains(path); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
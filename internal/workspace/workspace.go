@@ -2,19 +2,187 @@ package workspace
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/johncoder/jot/internal/config"
+	"github.com/johncoder/jot/internal/dryrun"
+	"github.com/johncoder/jot/internal/storage"
 )
 
 // WorkspaceConfig represents workspace-specific configuration
 type WorkspaceConfig struct {
 	ArchiveLocation string `json:"archive_location,omitempty"`
+	ProofChecker    string `json:"proof_checker,omitempty"`
+	SuggestCommand  string `json:"suggest_command,omitempty"`
+	SuggestEndpoint string `json:"suggest_endpoint,omitempty"`
+
+	RelatedEmbedCommand string `json:"related_embed_command,omitempty"`
+
+	// InboxAging configures when jot warns about untriaged inbox items
+	InboxAging *InboxAgingConfig `json:"inbox_aging,omitempty"`
+
+	// Aliases maps short names to selectors, used as "@name" wherever a selector is accepted
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Protected lists files and subtrees that refile and capture will not modify without --force
+	Protected []string `json:"protected,omitempty"`
+
+	// FoldDiacritics makes selectors ignore accents, so "cafe" matches "Café"
+	FoldDiacritics bool `json:"fold_diacritics,omitempty"`
+
+	// DisableSlugSelectors stops selectors falling back to GitHub-style heading slugs
+	DisableSlugSelectors bool `json:"disable_slug_selectors,omitempty"`
+
+	// DisableMetrics stops jot from recording daily snapshots in .jot/metrics
+	DisableMetrics bool `json:"disable_metrics,omitempty"`
+
+	// Hooks controls which hooks run for which commands, and how
+	Hooks *HooksConfig `json:"hooks,omitempty"`
+
+	// IncludeNestedWorkspaces makes scans descend into subdirectories that have their own .jot
+	IncludeNestedWorkspaces bool `json:"include_nested_workspaces,omitempty"`
+
+	// FollowSymlinks makes scans descend into symlinked directories
+	FollowSymlinks bool `json:"follow_symlinks,omitempty"`
+
+	// Storage selects where notes are kept; plain files when unset
+	Storage *StorageConfig `json:"storage,omitempty"`
+
+	// VerifyWrites checks the files refile, archive, promote and demote wrote, as --verify-writes does
+	VerifyWrites bool `json:"verify_writes,omitempty"`
+
+	// Review configures the documents 'jot review' generates
+	Review *ReviewConfig `json:"review,omitempty"`
+
+	// Issues configures the trackers 'jot ingest' and 'jot todo' talk to, by name
+	Issues map[string]*TrackerConfig `json:"issues,omitempty"`
+
+	// Links configures how 'jot links resolve' expands ticket references, and
+	// how 'jot link' points at headings
+	Links *LinksConfig `json:"links,omitempty"`
+
+	// Eval configures code evaluation, overriding the global configuration
+	Eval *config.EvalConfig `json:"eval,omitempty"`
+
+	// Log configures the entries 'jot log' writes
+	Log *LogConfig `json:"log,omitempty"`
+
+	// RunCapture configures where 'jot run-capture' files command records
+	RunCapture *RunCaptureConfig `json:"run_capture,omitempty"`
+
+	// Network configures the requests integrations make to trackers and endpoints
+	Network *NetworkConfig `json:"network,omitempty"`
+}
+
+// NetworkConfig tunes the HTTP requests integrations make
+type NetworkConfig struct {
+	Offline bool   `json:"offline,omitempty"` // Fail network-using commands at once, as --offline does
+	Proxy   string `json:"proxy,omitempty"`   // Proxy URL; HTTPS_PROXY and NO_PROXY are used when unset
+	Timeout int    `json:"timeout,omitempty"` // Seconds each attempt may take
+	Retries *int   `json:"retries,omitempty"` // Retries after a failed attempt; 3 when unset
+}
+
+// HooksConfig holds hook settings for the workspace
+type HooksConfig struct {
+	Enabled  *bool                        `json:"enabled,omitempty"`   // Set false to turn all hooks off
+	Timeout  int                          `json:"timeout,omitempty"`   // Seconds each hook may run
+	Timeouts map[string]int               `json:"timeouts,omitempty"`  // Seconds, keyed by hook file name
+	Env      map[string]string            `json:"env,omitempty"`       // Extra variables passed to every hook
+	EnvAllow []string                     `json:"env_allow,omitempty"` // Inherited variables hooks may see (all when empty)
+	Commands map[string]HookCommandConfig `json:"commands,omitempty"`  // Keyed by command: capture, refile, archive, eval
+}
+
+// HookCommandConfig limits the hooks run for one command
+type HookCommandConfig struct {
+	Allow   []string `json:"allow,omitempty"`   // Hook file names or patterns; only these run when set
+	Deny    []string `json:"deny,omitempty"`    // Hook file names or patterns that never run
+	Timeout int      `json:"timeout,omitempty"` // Seconds, overriding the workspace timeout
+}
+
+// StorageConfig selects a storage backend for notes
+type StorageConfig struct {
+	Backend string `json:"backend"`        // filesystem or sqlite
+	Path    string `json:"path,omitempty"` // sqlite database, relative to the workspace root
 }
 
+// InboxAgingConfig holds thresholds for inbox triage warnings
+type InboxAgingConfig struct {
+	MaxAgeDays  int  `json:"max_age_days,omitempty"` // Warn about items captured longer ago than this
+	MaxItems    int  `json:"max_items,omitempty"`    // Warn when the inbox holds more items than this
+	CaptureHint bool `json:"capture_hint,omitempty"` // Also warn after each capture
+}
+
+// ReviewConfig configures periodic review documents
+type ReviewConfig struct {
+	Weekly       string   `json:"weekly,omitempty"`        // File for weekly reviews; placeholders such as {{week}} are filled in
+	Monthly      string   `json:"monthly,omitempty"`       // File for monthly reviews
+	Sections     []string `json:"sections,omitempty"`      // Sections to include, in order
+	DeadlineDays int      `json:"deadline_days,omitempty"` // How far ahead to look for deadlines
+}
+
+// LogConfig configures timestamped log entries
+type LogConfig struct {
+	DayHeadings bool `json:"day_headings,omitempty"` // File entries under a heading per day
+	Prepend     bool `json:"prepend,omitempty"`      // Put the newest entry first
+}
+
+// RunCaptureConfig configures the records 'jot run-capture' files
+type RunCaptureConfig struct {
+	To       string `json:"to,omitempty"`        // Destination selector; the inbox when unset
+	MaxLines int    `json:"max_lines,omitempty"` // Output lines kept, the last ones; 200 when unset
+}
+
+// TrackerConfig says where an issue tracker's API is and how to find the
+// token for it. Tokens are never stored in the configuration.
+type TrackerConfig struct {
+	APIURL       string `json:"api_url,omitempty"`       // For GitHub Enterprise or self-hosted GitLab
+	Token        string `json:"token,omitempty"`         // A keyring secret, as "{{secret github_token}}"
+	TokenEnv     string `json:"token_env,omitempty"`     // Variable holding the token; GITHUB_TOKEN or GITLAB_TOKEN by default
+	TokenCommand string `json:"token_command,omitempty"` // Command printing the token, such as "gh auth token"
+}
+
+// LinksConfig holds the rules for expanding ticket references and the style
+// of links 'jot link' prints
+type LinksConfig struct {
+	Tickets []TicketConfig `json:"tickets,omitempty"`
+	Style   string         `json:"style,omitempty"` // 'jot link' anchors: "path" (default), "slug" or "id"
+}
+
+// TicketConfig links the tickets of some projects, such as ABC-123, to a
+// tracker, optionally looking titles up in Jira or Linear
+type TicketConfig struct {
+	Prefixes     []string `json:"prefixes"`                // Project keys, such as "ABC"
+	URL          string   `json:"url"`                     // Link template with {{id}}
+	Lookup       string   `json:"lookup,omitempty"`        // "jira" or "linear", to fetch titles
+	APIURL       string   `json:"api_url,omitempty"`       // Jira base URL, or Linear's GraphQL endpoint
+	Token        string   `json:"token,omitempty"`         // A keyring secret, as "{{secret jira_token}}"
+	TokenEnv     string   `json:"token_env,omitempty"`     // Variable holding the token; JIRA_TOKEN or LINEAR_API_KEY by default
+	TokenCommand string   `json:"token_command,omitempty"` // Command printing the token
+}
+
+// Review defaults
+var DefaultReviewSections = []string{"captured", "completed", "stale", "deadlines"}
+
+const (
+	DefaultWeeklyReview       = "reviews/{{week}}.md"
+	DefaultMonthlyReview      = "reviews/{{month}}.md"
+	DefaultReviewDeadlineDays = 14
+)
+
+// DefaultRunCaptureMaxLines is how many output lines 'jot run-capture'
+// keeps when run_capture sets no max_lines
+const DefaultRunCaptureMaxLines = 200
+
+// Default inbox aging thresholds
+const (
+	DefaultInboxMaxAgeDays = 14
+	DefaultInboxMaxItems   = 25
+)
+
 // Workspace represents a jot workspace
 type Workspace struct {
 	Root      string
@@ -22,6 +190,11 @@ type Workspace struct {
 	InboxPath string
 	LibDir    string
 	Config    *WorkspaceConfig
+
+	// Location is the ssh:// URL of a remote workspace, whose Root is a
+	// local mirror and whose notes go through Backend
+	Location string
+	Backend  storage.Backend
 }
 
 // LoadWorkspaceConfig loads workspace-specific configuration from .jot/config.json
@@ -62,7 +235,7 @@ func (ws *Workspace) SaveWorkspaceConfig() error {
 		return fmt.Errorf("failed to marshal workspace config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := dryrun.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write workspace config: %w", err)
 	}
 
@@ -78,6 +251,144 @@ func (ws *Workspace) GetArchiveLocation() string {
 	return ws.Config.ArchiveLocation
 }
 
+// GetInboxAging returns the inbox aging thresholds with defaults applied
+func (ws *Workspace) GetInboxAging() InboxAgingConfig {
+	cfg := InboxAgingConfig{}
+	if ws.Config != nil && ws.Config.InboxAging != nil {
+		cfg = *ws.Config.InboxAging
+	}
+	if cfg.MaxAgeDays <= 0 {
+		cfg.MaxAgeDays = DefaultInboxMaxAgeDays
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = DefaultInboxMaxItems
+	}
+	return cfg
+}
+
+// GetReview returns the review configuration with defaults applied
+func (ws *Workspace) GetReview() ReviewConfig {
+	cfg := ReviewConfig{}
+	if ws.Config != nil && ws.Config.Review != nil {
+		cfg = *ws.Config.Review
+	}
+	if cfg.Weekly == "" {
+		cfg.Weekly = DefaultWeeklyReview
+	}
+	if cfg.Monthly == "" {
+		cfg.Monthly = DefaultMonthlyReview
+	}
+	if len(cfg.Sections) == 0 {
+		cfg.Sections = DefaultReviewSections
+	}
+	if cfg.DeadlineDays <= 0 {
+		cfg.DeadlineDays = DefaultReviewDeadlineDays
+	}
+	return cfg
+}
+
+// GetLog returns the log entry settings
+func (ws *Workspace) GetLog() LogConfig {
+	if ws.Config == nil || ws.Config.Log == nil {
+		return LogConfig{}
+	}
+	return *ws.Config.Log
+}
+
+// GetRunCapture returns the run-capture settings, with defaults filled in
+func (ws *Workspace) GetRunCapture() RunCaptureConfig {
+	cfg := RunCaptureConfig{}
+	if ws.Config != nil && ws.Config.RunCapture != nil {
+		cfg = *ws.Config.RunCapture
+	}
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = DefaultRunCaptureMaxLines
+	}
+	return cfg
+}
+
+// GetTracker returns the settings for an issue tracker, with the token
+// variable defaulting to GITHUB_TOKEN or GITLAB_TOKEN
+func (ws *Workspace) GetTracker(name string) TrackerConfig {
+	cfg := TrackerConfig{}
+	if ws.Config != nil && ws.Config.Issues[name] != nil {
+		cfg = *ws.Config.Issues[name]
+	}
+	if cfg.TokenEnv == "" {
+		cfg.TokenEnv = strings.ToUpper(name) + "_TOKEN"
+	}
+	return cfg
+}
+
+// GetTicketRules returns the ticket reference rules, with the token
+// variable defaulting by lookup
+func (ws *Workspace) GetTicketRules() []TicketConfig {
+	if ws.Config == nil || ws.Config.Links == nil {
+		return nil
+	}
+	rules := make([]TicketConfig, len(ws.Config.Links.Tickets))
+	copy(rules, ws.Config.Links.Tickets)
+	for i := range rules {
+		if rules[i].TokenEnv != "" {
+			continue
+		}
+		switch rules[i].Lookup {
+		case "jira":
+			rules[i].TokenEnv = "JIRA_TOKEN"
+		case "linear":
+			rules[i].TokenEnv = "LINEAR_API_KEY"
+		}
+	}
+	return rules
+}
+
+// Interpreter is a command configured to run one language's code blocks
+type Interpreter struct {
+	Language string `json:"language"`
+	Command  string `json:"command"` // Command line; the code is passed on standard input
+	Source   string `json:"source"`  // "workspace" or "global"
+}
+
+// GetInterpreters returns the configured interpreters by language, the
+// workspace's overriding the global configuration's. It may be called on a
+// nil workspace for the global ones alone.
+func (ws *Workspace) GetInterpreters() map[string]Interpreter {
+	interpreters := map[string]Interpreter{}
+	add := func(cfg *config.EvalConfig, source string) {
+		if cfg == nil {
+			return
+		}
+		for lang, command := range cfg.Interpreters {
+			if strings.TrimSpace(command) != "" {
+				interpreters[lang] = Interpreter{Language: lang, Command: command, Source: source}
+			}
+		}
+	}
+	add(config.Get().Eval, "global")
+	if ws != nil && ws.Config != nil {
+		add(ws.Config.Eval, "workspace")
+	}
+	return interpreters
+}
+
+// override names the workspace every lookup returns, from --workspace
+var override string
+
+// ErrNoWorkspace is returned when discovery finds no workspace
+var ErrNoWorkspace = errors.New("no workspace found")
+
+// ErrNotRegistered is returned when a workspace name is not in the registry
+var ErrNotRegistered = errors.New("not found in registry")
+
+// ErrConfig is returned when a workspace's configuration cannot be loaded
+var ErrConfig = errors.New("failed to load workspace config")
+
+// SetOverride makes discovery return the named workspace, or an ssh://
+// URL, instead of searching from the current directory
+func SetOverride(name string) {
+	override = name
+}
+
 // FindWorkspace searches for a jot workspace using the enhanced discovery algorithm:
 // 1. Walk up parent directories looking for .jot/ directory or .jotrc file
 // 2. If .jot/ found: Use that workspace
@@ -85,6 +396,10 @@ func (ws *Workspace) GetArchiveLocation() string {
 // 4. If neither found: Check ~/.jotrc for global default workspace
 // 5. If no workspace available: Error with clear guidance
 func FindWorkspace() (*Workspace, error) {
+	if override != "" {
+		return RequireSpecificWorkspace(override)
+	}
+
 	dir, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current directory: %w", err)
@@ -98,7 +413,7 @@ func FindWorkspace() (*Workspace, error) {
 			// Load workspace configuration
 			cfg, err := LoadWorkspaceConfig(jotDir)
 			if err != nil {
-				return nil, fmt.Errorf("failed to load workspace config: %w", err)
+				return nil, fmt.Errorf("%w: %w", ErrConfig, err)
 			}
 
 			return &Workspace{
@@ -145,7 +460,11 @@ func findWorkspaceFromGlobalConfig() (*Workspace, error) {
 
 	defaultName, defaultPath, err := config.GetDefaultWorkspace()
 	if err != nil {
-		return nil, fmt.Errorf("no workspace found. Run 'jot init' from the directory you wish to store your notes")
+		return nil, fmt.Errorf("%w. Run 'jot init' from the directory you wish to store your notes", ErrNoWorkspace)
+	}
+
+	if storage.IsSSHURL(defaultPath) {
+		return RemoteWorkspace(defaultPath)
 	}
 
 	// Verify the workspace directory exists and has .jot/
@@ -157,7 +476,7 @@ func findWorkspaceFromGlobalConfig() (*Workspace, error) {
 	// Load workspace configuration
 	cfg, err := LoadWorkspaceConfig(jotDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load workspace config: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrConfig, err)
 	}
 
 	return &Workspace{
@@ -191,6 +510,10 @@ func RequireWorkspaceWithOverride(workspaceName string) (*Workspace, error) {
 
 // RequireSpecificWorkspace finds a workspace by name from the config registry
 func RequireSpecificWorkspace(name string) (*Workspace, error) {
+	if storage.IsSSHURL(name) {
+		return RemoteWorkspace(name)
+	}
+
 	// Initialize config if not already initialized
 	if err := config.Initialize(""); err != nil {
 		return nil, fmt.Errorf("failed to initialize config: %w", err)
@@ -198,7 +521,10 @@ func RequireSpecificWorkspace(name string) (*Workspace, error) {
 
 	path, err := config.GetWorkspace(name)
 	if err != nil {
-		return nil, fmt.Errorf("workspace '%s' not found in registry: %w\nUse 'jot workspace list' to see available workspaces", name, err)
+		return nil, fmt.Errorf("workspace '%s' %w: %w\nUse 'jot workspace list' to see available workspaces", name, ErrNotRegistered, err)
+	}
+	if storage.IsSSHURL(path) {
+		return RemoteWorkspace(path)
 	}
 
 	// Validate that the path exists and is initialized
@@ -210,7 +536,7 @@ func RequireSpecificWorkspace(name string) (*Workspace, error) {
 	// Load workspace configuration
 	cfg, err := LoadWorkspaceConfig(jotDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load workspace config: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrConfig, err)
 	}
 
 	return &Workspace{
@@ -239,14 +565,7 @@ func (w *Workspace) RelativePath(absolutePath string) string {
 
 // AppendToInbox adds content to the inbox with a timestamp
 func (w *Workspace) AppendToInbox(content string) error {
-	file, err := os.OpenFile(w.InboxPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open inbox: %w", err)
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(content)
-	if err != nil {
+	if err := dryrun.AppendFile(w.InboxPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write to inbox: %w", err)
 	}
 
@@ -255,26 +574,19 @@ func (w *Workspace) AppendToInbox(content string) error {
 
 // InboxExists checks if the inbox file exists
 func (w *Workspace) InboxExists() bool {
-	_, err := os.Stat(w.InboxPath)
+	_, err := storage.Stat(w.InboxPath)
 	return err == nil
 }
 
 // LibExists checks if the lib directory exists
 func (w *Workspace) LibExists() bool {
-	info, err := os.Stat(w.LibDir)
+	info, err := storage.Stat(w.LibDir)
 	return err == nil && info.IsDir()
 }
 
 // AppendToFile appends content to a specified file
 func (w *Workspace) AppendToFile(filePath, content string) error {
-	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(content + "\n\n")
-	if err != nil {
+	if err := dryrun.AppendFile(filePath, []byte(content+"\n\n"), 0644); err != nil {
 		return fmt.Errorf("failed to write to file: %w", err)
 	}
 